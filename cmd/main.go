@@ -4,20 +4,32 @@ import (
 	"context"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/UnknownOlympus/hermes/pkg/redisclient"
 	"github.com/UnknownOlympus/oracle/internal/bot"
+	"github.com/UnknownOlympus/oracle/internal/client/alertmanager"
 	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/UnknownOlympus/oracle/internal/client/mailer"
 	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/errorreport"
+	"github.com/UnknownOlympus/oracle/internal/integrations/github"
+	"github.com/UnknownOlympus/oracle/internal/invalidation"
+	"github.com/UnknownOlympus/oracle/internal/jobqueue"
 	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/UnknownOlympus/oracle/internal/redisclient"
 	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/UnknownOlympus/oracle/internal/scheduler"
 	"github.com/UnknownOlympus/oracle/internal/server"
+	"github.com/UnknownOlympus/oracle/internal/storage"
+	"github.com/UnknownOlympus/oracle/internal/tracing"
+	"github.com/UnknownOlympus/oracle/migrations"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 )
 
 // Constants for different environment types.
@@ -46,32 +58,153 @@ func main() {
 	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 	appMetrics := metrics.NewMetrics(reg)
 
+	// Report handler errors, panics and error logs to Sentry if a DSN is configured.
+	shutdownErrorReporting, err := errorreport.Setup(cfg.Errors, cfg.Env)
+	if err != nil {
+		log.Fatalf("Failed to set up error reporting: %v", err)
+	}
+	if cfg.Errors.DSN != "" {
+		logger = slog.New(errorreport.NewLogHandler(logger.Handler()))
+	}
+
+	// Export traces of handlers, database queries, Redis commands and Hermes calls.
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
 	// Initialize the database connection.
 	dtb, err := repository.NewDatabase(
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.Name,
+		cfg.Database.Pool,
 	)
 	if err != nil {
 		log.Fatalf("Failed to connect to DB: %v", err)
 	}
 
+	// Bring the schema up to date before anything queries it.
+	if cfg.Database.Migrate {
+		if err = repository.Migrate(ctx, logger, dtb, migrations.FS); err != nil {
+			log.Fatalf("Failed to migrate DB: %v", err)
+		}
+	}
+
 	// Initialize the redis client
 	const redisTimeout = 5 * time.Second
-	redisClient, err := redisclient.NewClient(ctx, cfg.RedisAddr, redisTimeout)
+	redisClient, err := redisclient.New(ctx, cfg.Redis, redisTimeout)
 	if err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
+	if err = redisotel.InstrumentTracing(redisClient); err != nil {
+		log.Fatalf("Failed to instrument Redis client: %v", err)
+	}
 
-	// Create a new repository instance using the database connection.
+	// Create a new repository instance using the database connection. Heavy read queries
+	// go to the read replica if one is configured.
 	repo := repository.NewRepository(dtb)
+	pools := map[string]metrics.PoolStater{"primary": dtb}
+	if cfg.Database.ReplicaDSN != "" {
+		replica, replicaErr := repository.NewReplicaDatabase(cfg.Database.ReplicaDSN, cfg.Database.Pool)
+		if replicaErr != nil {
+			log.Fatalf("Failed to connect to DB replica: %v", replicaErr)
+		}
+		defer replica.Close()
+
+		repo = repository.NewRepositoryWithReplica(dtb, replica)
+		pools["replica"] = replica
+	}
+	reg.MustRegister(metrics.NewPoolCollector(pools))
+	if cfg.Database.PostGIS {
+		repo.EnablePostGIS()
+	}
 
 	// create connecton with internal grpc server
-	hermesClient, hermesConn, err := hermes.NewClient(cfg.HermesAddr)
+	hermesClient, hermesConn, err := hermes.NewClient(cfg.Hermes, appMetrics)
 	if err != nil {
 		log.Fatalf("Failed to connect to Hermes service: %v", err)
 	}
 
+	// Reports are generated in the background by a pool of workers.
+	reportQueue := jobqueue.New(logger, cfg.ReportQueue.Workers, cfg.ReportQueue.Size, appMetrics.ReportQueueDepth)
+	reportQueue.Start(ctx)
+
+	// Admins can silence alerts from Telegram if the Alertmanager API is configured.
+	var alertmanagerClient *alertmanager.Client
+	if cfg.Alerts.AlertmanagerURL != "" {
+		const alertmanagerTimeout = 5 * time.Second
+		alertmanagerClient = alertmanager.NewClient(cfg.Alerts.AlertmanagerURL, alertmanagerTimeout)
+	}
+
+	// User feedback is filed as GitHub issues if a token is configured.
+	var githubClient *github.Client
+	if cfg.GitHub.Token != "" {
+		const githubTimeout = 10 * time.Second
+		githubClient = github.NewClient(cfg.GitHub.APIURL, cfg.GitHub.Repository, cfg.GitHub.Token, githubTimeout)
+	}
+
+	// Login codes are emailed through the SMTP server, without it users cannot log in with their email.
+	var mailerClient *mailer.Client
+	if cfg.SMTP.Addr != "" {
+		mailerClient = mailer.NewClient(cfg.SMTP.Addr, cfg.SMTP.Username, cfg.SMTP.Password, cfg.SMTP.From,
+			cfg.SMTP.Timeout)
+	} else {
+		logger.WarnContext(ctx, "SMTP_ADDRESS is not set, users cannot log in with their email")
+	}
+
+	// Generated files are kept in the object storage if it is configured, and in the database otherwise.
+	var fileStorage *storage.Client
+	if cfg.Storage.Endpoint != "" {
+		fileStorage, err = storage.New(ctx, cfg.Storage)
+		if err != nil {
+			log.Fatalf("Failed to connect to storage: %v", err)
+		}
+	}
+
 	// Initialize the bot with logger, repository, token, and poller timeout.
-	radiBot, err := bot.NewBot(logger, repo, repo, redisClient, hermesClient, appMetrics, cfg.Token, cfg.PollerTimeout)
+	radiBot, err := bot.NewBot(bot.Options{
+		Logger:           logger,
+		UserRepo:         repo,
+		TaskRepo:         repo,
+		SubscriptionRepo: repo,
+		BroadcastRepo:    repo,
+		ReportRepo:       repo,
+		TaskLocationRepo: repo,
+		DeliveryRepo:     repo,
+		AuditRepo:        repo,
+		ActivityRepo:     repo,
+		DigestRepo:       repo,
+		OutboxRepo:       repo,
+		ReportArchive:    repo,
+		Storage:          fileStorage,
+		SLARepo:          repo,
+		HandoverRepo:     repo,
+		OnboardingRepo:   repo,
+		FeatureFlagRepo:  repo,
+		ProfileRepo:      repo,
+		QuietHoursRepo:   repo,
+		QuickReplyRepo:   repo,
+		OnCallRepo:       repo,
+		ShiftRepo:        repo,
+		MaterialRepo:     repo,
+		Redis:            redisClient,
+		Hermes:           hermesClient,
+		Metrics:          appMetrics,
+		Token:            cfg.Token,
+		PollerTimeout:    cfg.PollerTimeout,
+		TasksPageSize:    cfg.TasksPageSize,
+		RateLimit:        cfg.RateLimit,
+		Timeouts:         cfg.Timeouts,
+		Digest:           cfg.Digest,
+		ReportQueue:      reportQueue,
+		Alerts:           cfg.Alerts,
+		Alertmanager:     alertmanagerClient,
+		GitHub:           githubClient,
+		Mailer:           mailerClient,
+		CRMTaskURL:       cfg.CRMTaskURL,
+		ParseMode:        cfg.ParseMode,
+		LocalesDir:       cfg.LocalesDir,
+		CommandAliases:   cfg.CommandAliases,
+	})
 	if err != nil {
 		log.Fatalf("Failed to create bot: %v", err)
 	}
@@ -81,11 +214,70 @@ func main() {
 	// Log that the application has started.
 	logger.InfoContext(ctx, "Application started. Press Ctrl+C to stop.")
 
+	// Fill the "/" menu of Telegram clients with the commands of each language and role.
+	if err = radiBot.PublishCommands(ctx); err != nil {
+		logger.WarnContext(ctx, "Failed to publish bot commands", "error", err)
+	}
+
 	// Start the bot in a goroutine to allow main to listen for signals.
 	go radiBot.Start()
 
-	// Start the moniroting server
-	go server.StartMonitoringServer(ctx, logger, reg, dtb, serverPort, hermesConn, radiBot.AlertmanagerWebhookHandler)
+	// Drop cached task details, reports and statistics as soon as Hermes reports a task change.
+	if cfg.InvalidationChannel != "" {
+		invalidator := invalidation.New(logger, redisClient, repo, cfg.InvalidationChannel, appMetrics.CacheOps)
+		go invalidator.Run(ctx)
+	}
+
+	// Corrected translations dropped into the locales directory are picked up without a restart.
+	if cfg.LocalesDir != "" {
+		go radiBot.WatchLocales(ctx)
+	}
+
+	// Schedule the weekly report delivery for subscribed users.
+	sched := scheduler.New(logger)
+	sched.Add("weekly_reports", scheduler.Weekly{
+		Weekday: time.Monday,
+		Hour:    cfg.WeeklyReport.Hour,
+		Minute:  cfg.WeeklyReport.Minute,
+	}, radiBot.SendWeeklyReports)
+	// Subscribers get the morning digest at the digest time of their own time zone.
+	sched.Add("daily_digest", scheduler.Every(bot.DigestCheckInterval), radiBot.SendDailyDigests)
+	// Digests and broadcasts postponed by the quiet hours of users are sent once they end.
+	sched.Add("deferred_messages", scheduler.Every(bot.DeferredDeliveryInterval), radiBot.DeliverDeferredMessages)
+	// Business metrics are refreshed right away, so the gauges do not read zero until the first run.
+	if err = radiBot.RefreshBusinessMetrics(ctx); err != nil {
+		logger.WarnContext(ctx, "Failed to refresh business metrics", "error", err)
+	}
+	sched.Add("business_metrics", scheduler.Every(bot.BusinessMetricsInterval), radiBot.RefreshBusinessMetrics)
+	// Comments which could not be sent to Hermes right away are retried with exponential backoff.
+	sched.Add("comment_outbox", scheduler.Every(bot.OutboxDispatchInterval), radiBot.DispatchCommentOutbox)
+	// Admins learn about texts missing from a locale before users report them.
+	sched.Add("translation_report", scheduler.Every(bot.TranslationReportInterval), radiBot.SendTranslationReport)
+	// Logouts can be undone for a grace period, the users are removed for good after it.
+	sched.Add("unlinked_users_purge", scheduler.Every(bot.UnlinkPurgeInterval), radiBot.PurgeUnlinkedUsers)
+	sched.Start(ctx)
+
+	// Start the moniroting server. The Alertmanager webhook only accepts requests with the configured token.
+	if cfg.Alerts.WebhookToken == "" {
+		logger.WarnContext(ctx, "Alertmanager webhook token is not set, the webhook is unauthenticated")
+	}
+	alertmanagerHandler := server.RequireBearerToken(
+		cfg.Alerts.WebhookToken, appMetrics.WebhookRejected, radiBot.AlertmanagerWebhookHandler,
+	)
+	webhooks, err := registerWebhooks(logger, radiBot, appMetrics, cfg.Webhooks)
+	if err != nil {
+		log.Fatalf("Failed to register webhooks: %v", err)
+	}
+	// The QR login page trusts the email header, so it is only served when a proxy setting it is configured.
+	var qrLoginHandler http.Handler
+	if cfg.QRLoginEmailHeader != "" {
+		qrLoginHandler = server.QRLoginHandler(
+			logger, radiBot, cfg.QRLoginEmailHeader, cfg.QRLoginTrustedProxies, bot.QRLoginTTL,
+		)
+	}
+	go server.StartMonitoringServer(
+		ctx, logger, reg, dtb, redisClient, serverPort, hermesConn, alertmanagerHandler, webhooks, qrLoginHandler,
+	)
 
 	// Wait for the context to be canceled (e.g., by Ctrl+C).
 	<-ctx.Done()
@@ -93,13 +285,67 @@ func main() {
 	// Log that a shutdown signal has been received.
 	logger.InfoContext(ctx, "Shutdown signal received. Stopping application...")
 
-	// Stop the bot gracefully.
-	radiBot.Stop()
+	// Stop receiving updates and give in-flight handlers, broadcasts, running scheduled jobs
+	// and queued reports a chance to finish.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err = radiBot.Shutdown(shutdownCtx); err != nil {
+		logger.ErrorContext(shutdownCtx, "Failed to stop bot gracefully", "error", err)
+	}
+	if err = sched.Stop(shutdownCtx); err != nil {
+		logger.ErrorContext(shutdownCtx, "Failed to stop scheduler gracefully", "error", err)
+	}
+	if err = reportQueue.Stop(shutdownCtx); err != nil {
+		logger.ErrorContext(shutdownCtx, "Failed to stop report queue gracefully", "error", err)
+	}
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		logger.ErrorContext(shutdownCtx, "Failed to flush traces", "error", err)
+	}
+	if err = shutdownErrorReporting(shutdownCtx); err != nil {
+		logger.WarnContext(shutdownCtx, "Failed to flush error reports", "error", err)
+	}
 
 	// Log graceful shutdown completion.
 	logger.InfoContext(ctx, "Application stopped gracefully.")
 }
 
+// registerWebhooks registers the webhooks of the external integrations enabled in the configuration.
+func registerWebhooks(
+	logger *slog.Logger,
+	notifier server.Notifier,
+	appMetrics *metrics.Metrics,
+	cfg config.Webhooks,
+) (*server.WebhookRegistry, error) {
+	webhooks := server.NewWebhookRegistry(logger, notifier, appMetrics.WebhookRejected)
+
+	if cfg.GitHubSecret != "" {
+		err := webhooks.Register(server.Webhook{
+			Name:         "github",
+			Path:         "/webhook/github",
+			Parse:        server.ParseGitHub,
+			Audience:     server.AudienceAdmins,
+			Authenticate: server.GitHubSignature(cfg.GitHubSecret),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.UptimeToken != "" {
+		err := webhooks.Register(server.Webhook{
+			Name:         "uptime",
+			Path:         "/webhook/uptime",
+			Parse:        server.ParseUptime,
+			Audience:     server.AudienceAdmins,
+			Authenticate: server.BearerToken(cfg.UptimeToken),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return webhooks, nil
+}
+
 // setupLogger initializes and returns a logger based on the environment provided.
 func setupLogger(env string) *slog.Logger {
 	var log *slog.Logger
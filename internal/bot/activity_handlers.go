@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// lastSeenInterval limits how often the last interaction of one user is written to the database.
+	lastSeenInterval = time.Minute
+	// inactiveUserDays is how long a user must be silent to be listed as inactive.
+	inactiveUserDays = 60
+	// maxInactiveUsers limits the inactive users list to fit into one Telegram message.
+	maxInactiveUsers = 30
+)
+
+// BusinessMetricsInterval is how often the user and state gauges are refreshed.
+const BusinessMetricsInterval = time.Minute
+
+// lastSeenCache remembers when the last interaction of each user was saved,
+// so a burst of updates results in a single database write.
+type lastSeenCache struct {
+	mu   sync.Mutex
+	seen map[int64]time.Time
+}
+
+func newLastSeenCache() *lastSeenCache {
+	return &lastSeenCache{seen: make(map[int64]time.Time)}
+}
+
+// due reports whether the interaction of the user at now should be saved, and if so,
+// remembers it as saved.
+func (c *lastSeenCache) due(userID int64, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.seen[userID]; ok && now.Sub(last) < lastSeenInterval {
+		return false
+	}
+	c.seen[userID] = now
+	return true
+}
+
+// LastSeenMiddleware records the time of the last interaction of the user sending the update.
+// Failures are logged and never block the update.
+func (b *Bot) LastSeenMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		sender := ctx.Sender()
+		if sender == nil || !b.lastSeen.due(sender.ID, time.Now()) {
+			return next(ctx)
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(traceContext(ctx), time.Second)
+		defer cancel()
+
+		startTime := time.Now()
+		err := b.acrepo.UpdateLastSeen(timeoutCtx, sender.ID)
+		b.metrics.DBQueryDuration.WithLabelValues("update_last_seen").Observe(time.Since(startTime).Seconds())
+		if err != nil {
+			b.log.WarnContext(timeoutCtx, "Failed to update last seen", "user", sender.ID, "error", err)
+		}
+
+		return next(ctx)
+	}
+}
+
+// inactiveUsersHandler lists the users who have not used the bot for inactiveUserDays,
+// to help admins prune stale accounts.
+func (b *Bot) inactiveUsersHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.log.Info("Admin requested inactive users", "user", ctx.Sender().ID)
+	b.metrics.CommandReceived.WithLabelValues("inactive_users").Inc()
+
+	now := time.Now()
+	startTime := time.Now()
+	users, err := b.acrepo.GetInactiveUsers(timeoutCtx, now.AddDate(0, 0, -inactiveUserDays))
+	b.metrics.DBQueryDuration.WithLabelValues("get_inactive_users").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get inactive users", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	days := map[string]interface{}{"days": inactiveUserDays}
+	if len(users) == 0 {
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.inactive.empty", days))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.inactive.header", map[string]interface{}{
+		"days":  inactiveUserDays,
+		"count": len(users),
+	}))
+	builder.WriteString("\n\n")
+
+	for idx, user := range users {
+		if idx >= maxInactiveUsers {
+			builder.WriteString("\n" + b.tWithData(timeoutCtx, ctx, "admin.inactive.truncated", map[string]interface{}{
+				"count": len(users) - maxInactiveUsers,
+			}))
+			break
+		}
+		name := user.ShortName
+		if name == "" {
+			name = strconv.FormatInt(user.TelegramID, 10)
+		}
+		builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.inactive.entry", map[string]interface{}{
+			"num":       idx + 1,
+			"name":      name,
+			"last_seen": b.formatDate(timeoutCtx, ctx, user.LastInteraction.Local()),
+			"days":      int(now.Sub(user.LastInteraction).Hours() / 24),
+		}))
+		builder.WriteString("\n")
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.sendLong(ctx, builder.String())
+}
+
+// RefreshBusinessMetrics updates the gauges of linked users, admins and pending states,
+// so the monitoring server can power the user growth dashboard.
+func (b *Bot) RefreshBusinessMetrics(ctx context.Context) error {
+	b.metrics.PendingStates.Set(float64(b.stateManager.Len()))
+
+	startTime := time.Now()
+	counts, err := b.acrepo.GetUserCounts(ctx)
+	b.metrics.DBQueryDuration.WithLabelValues("get_user_counts").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to get user counts: %w", err)
+	}
+
+	b.metrics.LinkedUsers.Set(float64(counts.Linked))
+	b.metrics.Admins.Set(float64(counts.Admins))
+	return nil
+}
@@ -2,101 +2,22 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/i18n"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
 	"gopkg.in/telebot.v4"
 )
 
-const timeout = 5
-
-// broadcastInitiateHandler starts the broadcast process.
-func (b *Bot) broadcastInitiateHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	userID := ctx.Sender().ID
-	b.log.Info("Admin user initiated a broadcast", "user", userID)
-
-	// 1. Set the user's state to expect a broadcast message
-	b.stateManager.Set(userID, UserState{
-		WaitingFor: stateAwaitingBroadcast,
-	})
-
-	// 2. Ask the admin to send the message
-	return ctx.Send(b.t(timeoutCtx, ctx, "admin.broadcast.prompt"))
-}
-
-// broadcastMessageHandler confirms the broadcast and starts the sending process.
-func (b *Bot) broadcastMessageHandler(ctx context.Context, bCtx telebot.Context, message string) error {
-	adminID := bCtx.Sender().ID
-
-	// 1. Get a list of all users from the database.
-	users, err := b.usrepo.GetAllTgUserIDs(ctx)
-	if err != nil {
-		b.log.ErrorContext(ctx, "Failed to get users for broadcast", "error", err)
-		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
-	}
-
-	// 2. Start the broadcast in a goroutine so the bot doesn't freeze.
-	go b.sendBroadcast(ctx, adminID, message, users)
-
-	// 3. Immediately confirm to the admin that the process has started.
-	numReceivers := len(users) - 1
-	responseText := b.tWithData(ctx, bCtx, "admin.broadcast.started", map[string]interface{}{
-		"count": numReceivers,
-	})
-	return bCtx.Send(responseText)
-}
-
-// sendBroadcast is the background worker that sends the messages.
-func (b *Bot) sendBroadcast(ctx context.Context, adminID int64, message string, userIDs []int64) {
-	b.log.InfoContext(ctx, "Starting broadcast", "from_admin", adminID, "user_count", len(userIDs)-1)
-
-	admin, err := b.tarepo.GetEmployee(ctx, adminID)
-	if err != nil {
-		b.log.WarnContext(ctx, "Failed to get employee data about admin", "user", adminID, "error", err)
-	}
-
-	successfulSends := 0
-	failedSends := 0
-
-	for _, userID := range userIDs {
-		// Don't send the message to the admin who initiated it
-		if userID == adminID {
-			continue
-		}
-
-		// Send the message to one user
-		formattedMessage := fmt.Sprintf("*You received a message from %s:*\n\n%s", admin.ShortName, message)
-		_, err = b.bot.Send(telebot.ChatID(userID), formattedMessage, telebot.ModeMarkdown)
-		if err != nil {
-			// This can happen if a user has blocked the bot
-			b.log.WarnContext(ctx, "Failed to send broadcast message to user", "user", userID, "error", err)
-			failedSends++
-		} else {
-			successfulSends++
-		}
-
-		// IMPORTANT: Wait a bit between messages to avoid Telegram's rate limits
-		const telegramRateTimeout = 100 * time.Millisecond
-		time.Sleep(telegramRateTimeout)
-	}
-
-	// Send a final report back to the admin
-	// Create a temporary telebot.Context for translation
-	reportText := b.tWithData(ctx, nil, "admin.broadcast.finished", map[string]interface{}{
-		"success": successfulSends,
-		"failed":  failedSends,
-	})
-	if _, err = b.bot.Send(telebot.ChatID(adminID), reportText); err != nil {
-		b.log.WarnContext(ctx, "Failed to send result message to admin", "admin", adminID, "error", err)
-	}
-}
-
 // geocodingIssuesHandler displays tasks with geocoding problems for debugging.
 func (b *Bot) geocodingIssuesHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
 	defer cancel()
 
 	userID := ctx.Sender().ID
@@ -115,7 +36,7 @@ func (b *Bot) geocodingIssuesHandler(ctx telebot.Context) error {
 	}
 
 	// Format the response as a structured table
-	// Limit to prevent Telegram message size limits (max 4096 chars)
+	// Limit to keep the message readable, longer messages would be split into several
 	maxIssues := 20
 	if len(issues) > maxIssues {
 		issues = issues[:maxIssues]
@@ -151,8 +72,8 @@ func (b *Bot) geocodingIssuesHandler(ctx telebot.Context) error {
 			"num":      idx + 1,
 			"id":       issue.TaskID,
 			"attempts": issue.GeocodingAttempts,
-			"address":  address,
-			"error":    errorMsg,
+			"address":  telegramfmt.Markdown.Escape(address),
+			"error":    telegramfmt.Markdown.Escape(errorMsg),
 		})
 		responseText += entryText + "\n"
 	}
@@ -162,12 +83,133 @@ func (b *Bot) geocodingIssuesHandler(ctx telebot.Context) error {
 		responseText += "\n" + b.t(timeoutCtx, ctx, "admin.geocoding.issues_truncated")
 	}
 
-	return ctx.Send(responseText, telebot.ModeMarkdown)
+	// A button per issue lets the admin fix the location of the task by sending a pin.
+	const fixButtonsPerRow = 4
+	menu := &telebot.ReplyMarkup{}
+	buttons := make([]telebot.Btn, 0, len(issues))
+	for _, issue := range issues {
+		label := b.tWithData(timeoutCtx, ctx, "admin.geocoding.fix", map[string]interface{}{"id": issue.TaskID})
+		buttons = append(buttons, menu.Data(label, "geocoding_fix", strconv.Itoa(issue.TaskID)))
+	}
+	rows := menu.Split(fixButtonsPerRow, buttons)
+	rows = append(rows, menu.Row(menu.Data(b.t(timeoutCtx, ctx, "admin.geocoding.export"), "geocoding_export")))
+	menu.Inline(rows...)
+
+	return b.sendLong(ctx, responseText, telebot.ModeMarkdown, menu)
+}
+
+// geocodingExportHandler sends every geocoding issue as an Excel file, unlike the view
+// which is limited to fit into a message.
+func (b *Bot) geocodingExportHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("geocoding_export").Inc()
+	b.log.Info("Admin requested geocoding issues export", "user", userID)
+
+	issues, err := b.tarepo.GetGeocodingIssues(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get geocoding issues", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	rows := make([]report.GeocodingIssueRow, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, report.GeocodingIssueRow{
+			TaskID:   issue.TaskID,
+			Address:  issue.Address,
+			Attempts: issue.GeocodingAttempts,
+			Error:    issue.GeocodingError,
+		})
+	}
+
+	buffer, err := report.GenerateGeocodingIssuesReport(rows)
+	if err != nil {
+		if errors.Is(err, report.ErrNoTasks) {
+			// The issues were fixed since the view was sent.
+			_ = ctx.Respond()
+			b.metrics.SentMessages.WithLabelValues("text").Inc()
+			return ctx.Send(b.t(timeoutCtx, ctx, "admin.geocoding.no_issues"), telebot.ModeMarkdown)
+		}
+		b.log.ErrorContext(timeoutCtx, "Failed to generate geocoding issues export", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	_ = ctx.Respond()
+	document := &telebot.Document{
+		File:     telebot.FromReader(buffer),
+		FileName: fmt.Sprintf("geocoding_issues_%s.xlsx", time.Now().Format("2006-01-02")),
+		MIME:     report.FormatXLSX.MIMEType(),
+	}
+	b.metrics.SentMessages.WithLabelValues("file").Inc()
+	return ctx.Send(document)
+}
+
+// geocodingFixHandler asks the admin for the location pin of the task in the callback data.
+func (b *Bot) geocodingFixHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("geocoding_fix").Inc()
+	_ = ctx.Respond()
+
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid task ID in callback", "data", ctx.Data(), "user", userID)
+		return nil
+	}
+
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingTaskLocation, TaskID: taskID})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.geocoding.fix_prompt", map[string]interface{}{"id": taskID}))
+}
+
+// taskLocationHandler writes the location pin sent by the admin to the task and clears its
+// geocoding error.
+func (b *Bot) taskLocationHandler(
+	ctx context.Context,
+	tCtx telebot.Context,
+	userID int64,
+	taskID int,
+	location *telebot.Location,
+) error {
+	lat, lng := float64(location.Lat), float64(location.Lng)
+	err := b.tarepo.SetTaskLocation(ctx, taskID, lat, lng)
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "deeplink.task_not_found", map[string]interface{}{"id": taskID}))
+	}
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to set task location", "error", err, "task", taskID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+
+	if err = b.cache.Del(ctx, fmt.Sprintf("oracle:task_details:%d", taskID)); err != nil {
+		b.log.WarnContext(ctx, "Failed to drop task details from cache", "error", err, "task", taskID)
+	}
+	b.recordAdminAction(ctx, userID, repository.AuditLocationFix, map[string]interface{}{
+		"task_id":   taskID,
+		"latitude":  lat,
+		"longitude": lng,
+	})
+	b.log.InfoContext(ctx, "Admin fixed task location", "user", userID, "task", taskID, "lat", lat, "lng", lng)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.tWithData(ctx, tCtx, "admin.geocoding.fixed", map[string]interface{}{
+		"id":        taskID,
+		"latitude":  fmt.Sprintf("%.6f", lat),
+		"longitude": fmt.Sprintf("%.6f", lng),
+	}))
 }
 
 // geocodingResetHandler resets geocoding errors with confirmation.
 func (b *Bot) geocodingResetHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
 	defer cancel()
 
 	userID := ctx.Sender().ID
@@ -193,7 +235,7 @@ func (b *Bot) geocodingResetHandler(ctx telebot.Context) error {
 
 // geocodingResetConfirmHandler executes the geocoding reset after confirmation.
 func (b *Bot) geocodingResetConfirmHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
 	defer cancel()
 
 	userID := ctx.Sender().ID
@@ -205,6 +247,9 @@ func (b *Bot) geocodingResetConfirmHandler(ctx telebot.Context) error {
 		b.log.ErrorContext(timeoutCtx, "Failed to reset geocoding errors", "error", err)
 		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
 	}
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditGeocodingReset, map[string]interface{}{
+		"rows_affected": rowsAffected,
+	})
 
 	// Send success message with count
 	responseText := b.tWithData(timeoutCtx, ctx, "admin.geocoding.reset.success", map[string]interface{}{
@@ -217,9 +262,226 @@ func (b *Bot) geocodingResetConfirmHandler(ctx telebot.Context) error {
 
 // geocodingResetCancelHandler handles the cancel action for geocoding reset.
 func (b *Bot) geocodingResetCancelHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
 	defer cancel()
 
 	b.log.Info("Admin canceled geocoding errors reset", "user", ctx.Sender().ID)
 	return ctx.Edit(b.t(timeoutCtx, ctx, "admin.geocoding.reset.canceled"), telebot.ModeMarkdown)
 }
+
+// agreementsFlushHandler drops the cached Hermes agreements, so the next reports load
+// contracts and tariffs that changed in the billing.
+func (b *Bot) agreementsFlushHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.log.Info("Admin requested agreements cache flush", "user", userID)
+
+	removed, err := b.cache.DelPrefix(timeoutCtx, agreementsCachePrefix)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to flush agreements cache", "error", err)
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditAgreementsFlush, map[string]interface{}{
+		"removed": removed,
+	})
+
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.agreements.flushed", map[string]interface{}{
+		"count": removed,
+	}))
+}
+
+// teamStatsHandler asks the admin to choose the period for the team leaderboard.
+func (b *Bot) teamStatsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.log.Info("Admin requested team statistics", "user", ctx.Sender().ID)
+	b.metrics.CommandReceived.WithLabelValues("team_stats").Inc()
+
+	menu := b.teamPeriodMenu(timeoutCtx, ctx, "team_stats_period")
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.team_stats.choose_period"), menu)
+}
+
+// teamPeriodMenu returns the inline keyboard with the periods of the team statistics.
+// The buttons trigger the callback with the given unique name and the period as data.
+func (b *Bot) teamPeriodMenu(ctx context.Context, tCtx telebot.Context, unique string) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.last_7_days"), unique, "week")),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.current_month"), unique, "month")),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.last_month"), unique, "last_month")),
+	)
+	return menu
+}
+
+// teamStatsPeriodHandler renders the leaderboard of completed tasks per employee for the selected period.
+func (b *Bot) teamStatsPeriodHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	from, to, ok := teamStatsPeriod(ctx.Data(), time.Now())
+	if !ok {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "report.error.unsupported_period"))
+	}
+
+	startTime := time.Now()
+	summaries, err := b.tarepo.GetTeamTaskSummary(timeoutCtx, from, to)
+	b.metrics.DBQueryDuration.WithLabelValues("get_team_task_summary").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get team task summary", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	period := map[string]interface{}{
+		"from": b.formatDate(timeoutCtx, ctx, from),
+		"to":   b.formatDate(timeoutCtx, ctx, to),
+	}
+	if len(summaries) == 0 {
+		b.metrics.SentMessages.WithLabelValues("edit").Inc()
+		return ctx.Edit(b.tWithData(timeoutCtx, ctx, "admin.team_stats.empty", period))
+	}
+
+	responseText := b.tWithData(timeoutCtx, ctx, "admin.team_stats.header", period) + "\n\n"
+
+	// Limit to keep the message readable, longer messages would be split into several
+	const maxEntries = 30
+	medals := []string{"🥇", "🥈", "🥉"}
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	total := 0
+	for idx, summary := range summaries {
+		total += summary.Count
+		if idx >= maxEntries {
+			continue
+		}
+		place := fmt.Sprintf("%d.", idx+1)
+		if idx < len(medals) {
+			place = medals[idx]
+		}
+		responseText += fmt.Sprintf("%s %s — %s\n", place, summary.ShortName, i18n.FormatNumber(lang, summary.Count))
+	}
+
+	responseText += "\n" + b.tWithData(timeoutCtx, ctx, "admin.team_stats.total", map[string]interface{}{
+		"count":     i18n.FormatNumber(lang, total),
+		"employees": len(summaries),
+	})
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return b.editLong(ctx, responseText)
+}
+
+// teamPerformanceHandler asks the admin to choose the period for the team performance comparison.
+func (b *Bot) teamPerformanceHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.log.Info("Admin requested team performance", "user", ctx.Sender().ID)
+	b.metrics.CommandReceived.WithLabelValues("team_performance").Inc()
+
+	menu := b.teamPeriodMenu(timeoutCtx, ctx, "team_performance_period")
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.team_performance.choose_period"), menu)
+}
+
+// teamPerformancePeriodHandler renders the completed tasks and the average closing time of every
+// employee for the selected period.
+func (b *Bot) teamPerformancePeriodHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	from, to, ok := teamStatsPeriod(ctx.Data(), time.Now())
+	if !ok {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "report.error.unsupported_period"))
+	}
+
+	startTime := time.Now()
+	performance, err := b.tarepo.GetEmployeePerformance(timeoutCtx, from, to)
+	b.metrics.DBQueryDuration.WithLabelValues("get_employee_performance").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get employee performance", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	period := map[string]interface{}{
+		"from": b.formatDate(timeoutCtx, ctx, from),
+		"to":   b.formatDate(timeoutCtx, ctx, to),
+	}
+	if len(performance) == 0 {
+		b.metrics.SentMessages.WithLabelValues("edit").Inc()
+		return ctx.Edit(b.tWithData(timeoutCtx, ctx, "admin.team_stats.empty", period))
+	}
+
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.team_performance.header", period))
+	builder.WriteString("\n\n")
+
+	// Limit to keep the message readable, longer messages would be split into several
+	const maxEntries = 30
+	for idx, entry := range performance {
+		if idx >= maxEntries {
+			builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.team_performance.more", map[string]interface{}{
+				"count": len(performance) - maxEntries,
+			}))
+			break
+		}
+		builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.team_performance.item", map[string]interface{}{
+			"place":    idx + 1,
+			"name":     entry.ShortName,
+			"count":    i18n.FormatNumber(lang, entry.Count),
+			"duration": b.formatDuration(timeoutCtx, ctx, entry.AvgClosingTime),
+		}))
+		builder.WriteString("\n")
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return b.editLong(ctx, builder.String())
+}
+
+// formatDuration formats a duration in the user's language with the two most significant units,
+// e.g. "2d 4h", "3h 15m" or "12m".
+func (b *Bot) formatDuration(ctx context.Context, tCtx telebot.Context, d time.Duration) string {
+	const hoursPerDay = 24
+	days := int(d.Hours()) / hoursPerDay
+	hours := int(d.Hours()) % hoursPerDay
+	minutes := int(d.Minutes()) % 60 //nolint:mnd // minutes in an hour
+
+	switch {
+	case days > 0:
+		return b.tWithData(ctx, tCtx, "duration.days_hours", map[string]interface{}{"days": days, "hours": hours})
+	case hours > 0:
+		return b.tWithData(ctx, tCtx, "duration.hours_minutes", map[string]interface{}{
+			"hours":   hours,
+			"minutes": minutes,
+		})
+	default:
+		return b.tWithData(ctx, tCtx, "duration.minutes", map[string]interface{}{"minutes": minutes})
+	}
+}
+
+// teamStatsPeriod converts the period name from the callback data into a date range.
+func teamStatsPeriod(period string, now time.Time) (time.Time, time.Time, bool) {
+	switch period {
+	case "week":
+		return now.AddDate(0, 0, -7), now, true
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), now, true
+	case "last_month":
+		from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+		return from, from.AddDate(0, 1, 0).Add(-time.Nanosecond), true
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+}
@@ -2,17 +2,36 @@ package bot
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/UnknownOlympus/olympus-protos/gen/go/scraper/olympus"
+	"github.com/UnknownOlympus/oracle/internal/client/hermes"
 	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/UnknownOlympus/oracle/internal/report"
 	"github.com/jackc/pgx/v5"
 )
 
+const (
+	// agreementWorkers is the number of concurrent GetAgreements calls of a report.
+	agreementWorkers = 15
+	// agreementsCachePrefix prefixes the cached agreements of a customer, keyed by the customer ID.
+	agreementsCachePrefix = "oracle:agreements:"
+	agreementsCacheTTL    = 24 * time.Hour
+)
+
+// agreementsLookup identifies a customer whose agreements are looked up, either by the billing ID
+// or, when the ID is unknown, by the full name.
+type agreementsLookup struct {
+	customerID   int64
+	customerName string
+}
+
 func (b *Bot) formatExcelRows(ctx context.Context, userID int64, from, to time.Time) ([]report.ExcelRow, error) {
 	tasks, err := b.tarepo.GetCompletedTasksByExecutor(ctx, userID, from, to)
 	if err != nil {
@@ -21,60 +40,229 @@ func (b *Bot) formatExcelRows(ctx context.Context, userID int64, from, to time.T
 		}
 		return nil, fmt.Errorf("failed to get completed tasks by executor: %w", err)
 	}
+	if excluded := b.excludedReportTypes(ctx, userID); len(excluded) > 0 {
+		tasks = slices.DeleteFunc(tasks, func(task models.TaskDetails) bool { return excluded[task.Type] })
+	}
+	if len(tasks) == 0 {
+		return []report.ExcelRow{}, nil
+	}
+
+	return b.excelRowsForTasks(ctx, tasks)
+}
+
+// excelRowsForTasks builds the report rows of the tasks, one per customer of a task, with the
+// agreements of the customers looked up in Hermes.
+func (b *Bot) excelRowsForTasks(ctx context.Context, tasks []models.TaskDetails) ([]report.ExcelRow, error) {
+	taskIDs := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, int64(task.ID))
+	}
+
+	customersByTask, err := b.tarepo.GetCustomersForTaskIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customers data from database: %w", err)
+	}
+
+	// The same customer usually appears in many tasks of the period, so each one is looked up once.
+	seen := make(map[agreementsLookup]struct{})
+	var queries []agreementsLookup
+	for _, customers := range customersByTask {
+		for _, customer := range customers {
+			query := agreementsQuery(customer)
+			if _, ok := seen[query]; !ok {
+				seen[query] = struct{}{}
+				queries = append(queries, query)
+			}
+		}
+	}
+
+	agreements, failed, err := b.getAgreements(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+
+	// The report is still useful without the materials sheet.
+	materialsByTask, err := b.marepo.GetTaskMaterials(ctx, taskIDs)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get materials of report tasks", "error", err)
+	}
+
+	finalRows := make([]report.ExcelRow, 0, len(tasks))
+	for _, task := range tasks {
+		rows, rowsErr := excelRowsFromTask(task, customersByTask[int64(task.ID)], agreements, failed)
+		if rowsErr != nil {
+			b.log.ErrorContext(ctx, "failed to process task for report", "task_id", task.ID, "error", rowsErr)
+			continue
+		}
+		materials := materialUsage(materialsByTask[int64(task.ID)])
+		for i := range rows {
+			rows[i].Materials = materials
+		}
+		finalRows = append(finalRows, rows...)
+	}
 
-	const numWorkers = 15
-	tasksChan := make(chan models.TaskDetails, len(tasks))
-	resultsChan := make(chan []report.ExcelRow, len(tasks))
-	var wg sync.WaitGroup
+	return finalRows, nil
+}
+
+// getAgreements returns the agreements of the customers. Agreements of customers with a known
+// ID are served from the cache, the others are looked up in Hermes and cached. Customers whose
+// lookup failed are returned with the error, an error is returned only when Hermes is unavailable.
+func (b *Bot) getAgreements(
+	ctx context.Context,
+	queries []agreementsLookup,
+) (map[agreementsLookup][]models.Customer, map[agreementsLookup]error, error) {
+	agreements, missing := b.cachedAgreements(ctx, queries)
+	if len(missing) == 0 {
+		return agreements, nil, nil
+	}
 
-	for range numWorkers {
+	fetched, failed, err := b.fetchAgreements(ctx, missing)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.cacheAgreements(ctx, fetched)
+
+	for query, customers := range fetched {
+		agreements[query] = customers
+	}
+	return agreements, failed, nil
+}
+
+// fetchAgreements looks up the agreements of the customers with concurrent GetAgreements calls.
+func (b *Bot) fetchAgreements(
+	ctx context.Context,
+	queries []agreementsLookup,
+) (map[agreementsLookup][]models.Customer, map[agreementsLookup]error, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu             sync.Mutex
+		wg             sync.WaitGroup
+		errUnavailable error
+	)
+	agreements := make(map[agreementsLookup][]models.Customer, len(queries))
+	failed := make(map[agreementsLookup]error)
+	queriesChan := make(chan agreementsLookup, len(queries))
+	for _, query := range queries {
+		queriesChan <- query
+	}
+	close(queriesChan)
+
+	for range min(agreementWorkers, len(queries)) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for task := range tasksChan {
-				rows, rowsErr := b.getExcelRowsFromTask(ctx, task)
-				if rowsErr != nil {
-					b.log.Error("failed to process task for report", "task_id", task.ID, "error", rowsErr)
-					resultsChan <- nil
-				} else {
-					resultsChan <- rows
+			for query := range queriesChan {
+				resp, errGet := b.hermesClient.GetAgreements(ctx, agreementsRequest(query))
+
+				mu.Lock()
+				switch {
+				case errGet == nil:
+					agreements[query] = convertPbAgreements(resp.GetAgreements())
+				case hermes.IsUnavailable(errGet):
+					// There is no point in waiting for the remaining calls to fail the same way.
+					if errUnavailable == nil {
+						errUnavailable = errGet
+					}
+					cancel()
+				default:
+					failed[query] = errGet
 				}
+				mu.Unlock()
 			}
 		}()
 	}
+	wg.Wait()
 
-	for _, task := range tasks {
-		tasksChan <- task
+	if errUnavailable != nil {
+		return nil, nil, fmt.Errorf("failed to get response from hermes (GetAgreements): %w", errUnavailable)
 	}
-	close(tasksChan)
 
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	return agreements, failed, nil
+}
 
-	var finalRows []report.ExcelRow
-	for rows := range resultsChan {
-		if rows != nil {
-			finalRows = append(finalRows, rows...)
+// cachedAgreements returns the cached agreements of the customers together with the queries
+// which have to be sent to Hermes. Only lookups by customer ID are cached, names are ambiguous.
+func (b *Bot) cachedAgreements(
+	ctx context.Context,
+	queries []agreementsLookup,
+) (map[agreementsLookup][]models.Customer, []agreementsLookup) {
+	agreements := make(map[agreementsLookup][]models.Customer, len(queries))
+	keys := make([]string, 0, len(queries))
+	for _, query := range queries {
+		if query.customerID != 0 {
+			keys = append(keys, agreementsCacheKey(query.customerID))
 		}
 	}
 
-	return finalRows, nil
+	cached, err := b.cache.GetMany(ctx, keys)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to read agreements from cache", "error", err)
+	}
+
+	var missing []agreementsLookup
+	for _, query := range queries {
+		if query.customerID == 0 {
+			missing = append(missing, query)
+			continue
+		}
+
+		var customers []models.Customer
+		if raw, ok := cached[agreementsCacheKey(query.customerID)]; ok {
+			if err = json.Unmarshal(raw, &customers); err == nil {
+				b.metrics.AgreementsCache.WithLabelValues("hit").Inc()
+				agreements[query] = customers
+				continue
+			}
+			b.log.WarnContext(ctx, "Failed to decode cached agreements", "customer", query.customerID, "error", err)
+		}
+
+		b.metrics.AgreementsCache.WithLabelValues("miss").Inc()
+		missing = append(missing, query)
+	}
+
+	return agreements, missing
 }
 
-func (b *Bot) getExcelRowsFromTask(ctx context.Context, task models.TaskDetails) ([]report.ExcelRow, error) {
+// cacheAgreements stores the agreements of customers looked up by ID.
+func (b *Bot) cacheAgreements(ctx context.Context, agreements map[agreementsLookup][]models.Customer) {
+	for query, customers := range agreements {
+		if query.customerID == 0 {
+			continue
+		}
+
+		encoded, err := json.Marshal(customers)
+		if err != nil {
+			b.log.ErrorContext(ctx, "Failed to encode agreements for caching",
+				"customer", query.customerID, "error", err)
+			continue
+		}
+		if err = b.cache.Set(ctx, agreementsCacheKey(query.customerID), encoded, agreementsCacheTTL); err != nil {
+			b.log.WarnContext(ctx, "Failed to cache agreements", "customer", query.customerID, "error", err)
+			return
+		}
+	}
+}
+
+func agreementsCacheKey(customerID int64) string {
+	return agreementsCachePrefix + strconv.FormatInt(customerID, 10)
+}
+
+func excelRowsFromTask(
+	task models.TaskDetails,
+	customers []models.Customer,
+	agreements map[agreementsLookup][]models.Customer,
+	failed map[agreementsLookup]error,
+) ([]report.ExcelRow, error) {
 	defRow := report.ExcelRow{
 		ID:           task.ID,
 		Type:         task.Type,
 		CreationDate: task.CreationDate,
+		ClosingDate:  task.ClosingDate,
 		Description:  task.Description,
 		Address:      task.Address,
-	}
-
-	customers, err := b.GetCustomersByTask(ctx, task)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get customers by task '%d': %w", task.ID, err)
+		Executors:    task.Executors,
 	}
 
 	if len(customers) == 0 {
@@ -85,7 +273,13 @@ func (b *Bot) getExcelRowsFromTask(ctx context.Context, task models.TaskDetails)
 	}
 
 	rows := make([]report.ExcelRow, 0, len(customers))
-	for _, customer := range customers {
+	for _, client := range customers {
+		query := agreementsQuery(client)
+		if err := failed[query]; err != nil {
+			return nil, fmt.Errorf("failed to get response from hermes (GetAgreements): %w", err)
+		}
+
+		customer := pickAgreement(agreements[query], task)
 		defRow.Customer = customer.Fullname
 		defRow.Contract = customer.Contract
 		defRow.Tariff = customer.Tariff
@@ -96,63 +290,66 @@ func (b *Bot) getExcelRowsFromTask(ctx context.Context, task models.TaskDetails)
 	return rows, nil
 }
 
-func (b *Bot) GetCustomersByTask(ctx context.Context, task models.TaskDetails) ([]models.Customer, error) {
-	taskID := int64(task.ID)
-
-	clients, err := b.tarepo.GetCustomersByTaskID(ctx, taskID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return []models.Customer{}, nil
-		}
-		return nil, fmt.Errorf("failed to get customers data from database, task ID '%d': %w", taskID, err)
+// materialUsage converts the materials used on a task into their report rows.
+func materialUsage(materials []models.TaskMaterial) []report.MaterialUsage {
+	if len(materials) == 0 {
+		return nil
 	}
-
-	result := make([]models.Customer, 0, len(clients))
-	for _, client := range clients {
-		perClient, clientErr := b.AddContractToCustomer(ctx, client, task)
-		if clientErr != nil {
-			return nil, fmt.Errorf("failed to generate customer fields: %w", clientErr)
-		}
-		result = append(result, perClient)
+	usage := make([]report.MaterialUsage, 0, len(materials))
+	for _, material := range materials {
+		usage = append(usage, report.MaterialUsage{
+			Name:     material.Name,
+			Unit:     material.Unit,
+			Quantity: material.Quantity,
+		})
 	}
 
-	return result, nil
+	return usage
 }
 
-func (b *Bot) AddContractToCustomer(
-	ctx context.Context,
-	customer models.Customer,
-	task models.TaskDetails,
-) (models.Customer, error) {
-	var req *olympus.GetAgreementsRequest
+func agreementsQuery(customer models.Customer) agreementsLookup {
 	if customer.ID != 0 {
-		req = &olympus.GetAgreementsRequest{
-			Identifier: &olympus.GetAgreementsRequest_CustomerId{CustomerId: customer.ID},
-		}
-	} else {
-		req = &olympus.GetAgreementsRequest{Identifier: &olympus.GetAgreementsRequest_CustomerName{CustomerName: customer.Fullname}}
+		return agreementsLookup{customerID: customer.ID}
 	}
+	return agreementsLookup{customerName: customer.Fullname}
+}
 
-	resp, err := b.hermesClient.GetAgreements(ctx, req)
-	if err != nil {
-		return models.Customer{}, fmt.Errorf("failed to get response from hermes (GetAgreements): %w", err)
+func agreementsRequest(query agreementsLookup) *olympus.GetAgreementsRequest {
+	if query.customerID != 0 {
+		return &olympus.GetAgreementsRequest{
+			Identifier: &olympus.GetAgreementsRequest_CustomerId{CustomerId: query.customerID},
+		}
 	}
+	return &olympus.GetAgreementsRequest{
+		Identifier: &olympus.GetAgreementsRequest_CustomerName{CustomerName: query.customerName},
+	}
+}
 
-	agreements := resp.GetAgreements()
+// pickAgreement chooses the agreement of the customer the task was done for. A customer
+// with several agreements is matched by the task address.
+func pickAgreement(agreements []models.Customer, task models.TaskDetails) models.Customer {
 	switch len(agreements) {
 	case 0:
-		return models.Customer{}, nil
+		return models.Customer{}
 	case 1:
-		return convertPbCustomerToModel(agreements[0]), nil
+		return agreements[0]
 	default:
 		for _, agreement := range agreements {
-			if task.Address == agreement.GetAddress() {
-				return convertPbCustomerToModel(agreement), nil
+			if task.Address == agreement.Address {
+				return agreement
 			}
 		}
 	}
 
-	return models.Customer{}, nil
+	return models.Customer{}
+}
+
+func convertPbAgreements(agreements []*olympus.Agreement) []models.Customer {
+	customers := make([]models.Customer, 0, len(agreements))
+	for _, agreement := range agreements {
+		customers = append(customers, convertPbCustomerToModel(agreement))
+	}
+	return customers
 }
 
 func convertPbCustomerToModel(pbc *olympus.Agreement) models.Customer {
@@ -162,5 +359,7 @@ func convertPbCustomerToModel(pbc *olympus.Agreement) models.Customer {
 		Contract: pbc.GetContract(),
 		Address:  pbc.GetAddress(),
 		Tariff:   pbc.GetTariff(),
+		Balance:  pbc.GetBalance(),
+		Phone:    pbc.GetNumber(),
 	}
 }
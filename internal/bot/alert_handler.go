@@ -2,16 +2,34 @@ package bot
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/client/alertmanager"
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
 	"gopkg.in/telebot.v4"
 )
 
+const (
+	// severityCritical alerts are delivered during the silence hours too.
+	severityCritical = "critical"
+	// alertSilenceDuration is how long the silence button mutes an alert.
+	alertSilenceDuration = 2 * time.Hour
+	// alertLabelsTTL is how long the labels of an alert are kept for the silence button.
+	alertLabelsTTL = 24 * time.Hour
+	// fingerprintLength is the length of fingerprints computed for alerts sent without one.
+	fingerprintLength = 16
+)
+
 // AlertmanagerPayload corresponds to the JSON structure sent by Alertmanager.
 type AlertmanagerPayload struct {
 	Receiver string  `json:"receiver"`
@@ -21,6 +39,7 @@ type AlertmanagerPayload struct {
 
 // Alert contains detail information about the one notification.
 type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
 	Status      string            `json:"status"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
@@ -50,30 +69,62 @@ func (b *Bot) AlertmanagerWebhookHandler(writer http.ResponseWriter, req *http.R
 		return
 	}
 
-	admins, err := b.usrepo.GetAdmins(context.Background())
+	admins, err := b.cachedAdmins(req.Context())
 	if err != nil {
 		b.log.Error("Failed to get admins for alert", "error", err)
 	}
+	onCall, hasOnCall := b.currentOnCall(req.Context())
 
-	if len(admins) == 0 {
+	if len(admins) == 0 && !hasOnCall {
 		b.log.Warn("No admins found to send alerts to.")
 		writer.WriteHeader(http.StatusOK)
 		return
 	}
 
-	go func() {
+	delivering := b.goTracked(func() {
+		ctx := b.baseCtx
+		adminIDs := make([]int64, 0, len(admins)+1)
+		for _, admin := range admins {
+			adminIDs = append(adminIDs, admin.TelegramID)
+		}
+		languages := b.languagesByID(ctx, append(slices.Clone(adminIDs), onCall.TelegramID))
+
 		for _, alert := range payload.Alerts {
-			message := formatAlertMessage(alert)
-			for _, admin := range admins {
-				_, err = b.bot.Send(telebot.ChatID(admin.TelegramID), message, telebot.ModeMarkdown)
+			if !b.shouldDeliverAlert(ctx, alert, time.Now()) {
+				continue
+			}
+			silenceable := b.saveAlertLabels(ctx, alert)
+
+			// The message is formatted once per language of the recipients.
+			messages := make(map[string]string)
+			// The user on duty gets every alert, in addition to the admins.
+			recipients := withOnCall(alertRecipients(alert, admins, b.alerts.Routes), onCall, hasOnCall)
+			for _, admin := range recipients {
+				lang := languages[admin.TelegramID]
+				message, ok := messages[lang]
+				if !ok {
+					message = b.formatAlertMessage(lang, alert)
+					messages[lang] = message
+				}
+
+				options := []interface{}{b.format.Mode()}
+				// Silencing alerts is left to the admins.
+				if silenceable && slices.Contains(adminIDs, admin.TelegramID) {
+					options = append(options, b.alertSilenceMarkup(lang, alert))
+				}
+				_, err = b.bot.Send(telebot.ChatID(admin.TelegramID), message, options...)
 				if err != nil {
 					b.log.Warn("Failed to send alert to admin", "admin_id", admin.TelegramID, "error", err)
+					b.recordSendFailure(ctx, admin.TelegramID, sendSourceAlert, err)
 				}
 				const telegramRateTimeout = 100 * time.Millisecond
 				time.Sleep(telegramRateTimeout)
 			}
 		}
-	}()
+	})
+	if !delivering {
+		b.log.Warn("Bot is shutting down, alerts are not delivered", "alerts", len(payload.Alerts))
+	}
 
 	writer.WriteHeader(http.StatusOK)
 	if _, err = writer.Write([]byte("Alerts received successfully.")); err != nil {
@@ -81,14 +132,17 @@ func (b *Bot) AlertmanagerWebhookHandler(writer http.ResponseWriter, req *http.R
 	}
 }
 
-// formatAlertMessage formats the one alert in readable messsage for Telegram.
-func formatAlertMessage(alert Alert) string {
-	var icon string
-	status := strings.ToUpper(alert.Status)
-	if status == "FIRING" {
-		icon = "🔥"
-	} else {
-		icon = "✅"
+// formatAlertMessage formats the one alert in readable messsage for Telegram in the given language.
+// The annotations are sent as Alertmanager wrote them.
+func (b *Bot) formatAlertMessage(lang string, alert Alert) string {
+	var icon, status string
+	switch strings.ToLower(alert.Status) {
+	case "firing":
+		icon, status = "🔥", b.localizer.Get(lang, "alert.status.firing")
+	case "resolved":
+		icon, status = "✅", b.localizer.Get(lang, "alert.status.resolved")
+	default:
+		icon, status = "✅", strings.ToUpper(alert.Status)
 	}
 
 	summary := alert.Annotations["summary"]
@@ -96,15 +150,162 @@ func formatAlertMessage(alert Alert) string {
 	job := alert.Labels["job"]
 	severity := alert.Labels["severity"]
 
+	format := b.format
 	var messageBuilder strings.Builder
-	messageBuilder.WriteString(fmt.Sprintf("%s **%s** (%s)\n\n", icon, status, severity))
-	messageBuilder.WriteString(fmt.Sprintf("**Summary**: %s\n", summary))
+	messageBuilder.WriteString(fmt.Sprintf("%s %s %s\n\n", icon, format.Bold(status), format.Escape("("+severity+")")))
+	messageBuilder.WriteString(fmt.Sprintf("%s %s\n",
+		format.Bold(b.localizer.Get(lang, "alert.summary")+":"), format.Escape(summary)))
 	if description != "" {
-		messageBuilder.WriteString(fmt.Sprintf("**Description**: %s\n", description))
+		messageBuilder.WriteString(fmt.Sprintf("%s %s\n",
+			format.Bold(b.localizer.Get(lang, "alert.description")+":"), format.Escape(description)))
 	}
 	if job != "" {
-		messageBuilder.WriteString(fmt.Sprintf("**Service**: `%s`\n", job))
+		messageBuilder.WriteString(fmt.Sprintf("%s %s\n",
+			format.Bold(b.localizer.Get(lang, "alert.service")+":"), format.Code(job)))
 	}
 
 	return messageBuilder.String()
 }
+
+// alertRecipients returns the admins receiving the alert. Alerts of a routed severity go only
+// to the admins listed in its route, other alerts go to all admins.
+func alertRecipients(alert Alert, admins []models.BotUser, routes map[string][]int64) []models.BotUser {
+	route, ok := routes[alert.Labels["severity"]]
+	if !ok {
+		return admins
+	}
+
+	var recipients []models.BotUser
+	for _, admin := range admins {
+		if slices.Contains(route, admin.TelegramID) {
+			recipients = append(recipients, admin)
+		}
+	}
+	return recipients
+}
+
+// shouldDeliverAlert applies the silence hours and the dedup window to the alert.
+// Repeated notifications of the same alert status are dropped within the window.
+// If Redis is unavailable, the alert is delivered.
+func (b *Bot) shouldDeliverAlert(ctx context.Context, alert Alert, now time.Time) bool {
+	fingerprint := alertFingerprint(alert)
+	if b.alerts.SilenceHours.Contains(now) && alert.Labels["severity"] != severityCritical {
+		b.log.InfoContext(ctx, "Alert dropped during silence hours", "fingerprint", fingerprint)
+		return false
+	}
+	if b.alerts.DedupWindow <= 0 {
+		return true
+	}
+
+	key := fmt.Sprintf("oracle:alert:dedup:%s:%s", fingerprint, strings.ToLower(alert.Status))
+	first, err := b.redisClient.SetNX(ctx, key, 1, b.alerts.DedupWindow).Result()
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to check alert dedup window, delivering alert", "error", err)
+		return true
+	}
+	if !first {
+		b.log.InfoContext(ctx, "Duplicate alert dropped", "fingerprint", fingerprint, "status", alert.Status)
+	}
+	return first
+}
+
+// alertFingerprint returns the fingerprint Alertmanager assigned to the alert, or computes one
+// from its labels.
+func alertFingerprint(alert Alert) string {
+	if alert.Fingerprint != "" {
+		return alert.Fingerprint
+	}
+
+	hash := sha256.New()
+	for _, name := range slices.Sorted(maps.Keys(alert.Labels)) {
+		hash.Write([]byte(name + "=" + alert.Labels[name] + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:fingerprintLength]
+}
+
+// saveAlertLabels keeps the labels of a firing alert, so the silence button can match it later.
+// It reports whether the alert can be silenced.
+func (b *Bot) saveAlertLabels(ctx context.Context, alert Alert) bool {
+	if b.alertmanager == nil || !strings.EqualFold(alert.Status, "firing") || len(alert.Labels) == 0 {
+		return false
+	}
+
+	labels, err := json.Marshal(alert.Labels)
+	if err == nil {
+		key := "oracle:alert:labels:" + alertFingerprint(alert)
+		err = b.redisClient.Set(ctx, key, labels, alertLabelsTTL).Err()
+	}
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to save alert labels, silence button hidden", "error", err)
+		return false
+	}
+	return true
+}
+
+// alertSilenceMarkup returns the silence button in the language of the admin.
+func (b *Bot) alertSilenceMarkup(lang string, alert Alert) *telebot.ReplyMarkup {
+	markup := &telebot.ReplyMarkup{}
+	markup.InlineKeyboard = [][]telebot.InlineButton{{{
+		Unique: "alert_silence",
+		Text:   b.localizer.Get(lang, "alert.silence.button"),
+		Data:   alertFingerprint(alert),
+	}}}
+	return markup
+}
+
+// alertSilenceHandler silences the alert in Alertmanager for two hours.
+func (b *Bot) alertSilenceHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("alert_silence").Inc()
+
+	if b.alertmanager == nil {
+		b.log.Warn("Alert silence rejected", "user", userID)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "general.use_buttons")})
+	}
+
+	fingerprint := ctx.Data()
+	rawLabels, err := b.redisClient.Get(timeoutCtx, "oracle:alert:labels:"+fingerprint).Bytes()
+	var labels map[string]string
+	if err == nil {
+		err = json.Unmarshal(rawLabels, &labels)
+	}
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Alert labels not found", "error", err, "fingerprint", fingerprint)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{
+			Text:      b.t(timeoutCtx, ctx, "alert.silence.expired"),
+			ShowAlert: true,
+		})
+	}
+
+	now := time.Now()
+	silenceID, err := b.alertmanager.CreateSilence(timeoutCtx, alertmanager.Silence{
+		Matchers:  alertmanager.MatchLabels(labels),
+		StartsAt:  now,
+		EndsAt:    now.Add(alertSilenceDuration),
+		CreatedBy: fmt.Sprintf("%s (telegram %d)", ctx.Sender().Username, userID),
+		Comment:   "Silenced from Telegram",
+	})
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to create silence", "error", err, "fingerprint", fingerprint)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal"), ShowAlert: true})
+	}
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditAlertSilence, map[string]interface{}{
+		"silence_id": silenceID,
+		"labels":     labels,
+	})
+	b.log.InfoContext(timeoutCtx, "Alert silenced", "admin", userID, "silence_id", silenceID)
+
+	// The button is removed, the alert itself stays in the chat.
+	if _, err = b.bot.EditReplyMarkup(ctx.Message(), nil); err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to remove silence button", "error", err)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("respond").Inc()
+	return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "alert.silence.success")})
+}
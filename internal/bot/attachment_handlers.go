@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"fmt"
+	"io"
+
+	"gopkg.in/telebot.v4"
+)
+
+// maxAttachmentSize limits the size of a file downloaded from Telegram.
+const maxAttachmentSize = 10 << 20
+
+// photoHandler accepts a photo sent by an admin composing a broadcast, or by a user attaching
+// a screenshot to their feedback. Photos are not accepted anywhere else.
+func (b *Bot) photoHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	state, ok := b.stateManager.Get(userID)
+	if ok && state.WaitingFor == stateAwaitingBroadcast {
+		b.log.Debug("User is trying to send broadcast photo to everyone", "user", userID)
+		return b.broadcastMessageHandler(timeoutCtx, ctx, broadcastMessage{
+			Kind:      broadcastPhoto,
+			FileID:    ctx.Message().Photo.FileID,
+			Text:      ctx.Message().Caption,
+			Important: state.Category == broadcastImportant,
+		})
+	}
+	if ok && state.WaitingFor == stateAwaitingFeedbackScreenshot {
+		return b.feedbackScreenshotHandler(timeoutCtx, ctx, ctx.Message().Photo)
+	}
+	b.metrics.SentMessages.WithLabelValues("reply").Inc()
+	return ctx.Reply(b.t(timeoutCtx, ctx, "general.use_buttons"))
+}
+
+// downloadFile reads a file sent to the bot, refusing files larger than maxAttachmentSize.
+func (b *Bot) downloadFile(file *telebot.File) ([]byte, error) {
+	reader, err := b.bot.File(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file from telegram: %w", err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(io.LimitReader(reader, maxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(content) > maxAttachmentSize {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxAttachmentSize)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// auditLogPageSize is the number of audit log entries shown per page.
+const auditLogPageSize = 10
+
+// auditHashLength is the number of payload hash characters shown in the audit log.
+const auditHashLength = 12
+
+// recordAdminAction writes an admin action to the audit log. The payload is hashed by the
+// repository. A failure is logged but does not stop the action itself.
+func (b *Bot) recordAdminAction(ctx context.Context, adminID int64, action string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to encode admin action payload", "action", action, "error", err)
+		return
+	}
+
+	startTime := time.Now()
+	err = b.aurepo.RecordAdminAction(ctx, adminID, action, data)
+	b.metrics.DBQueryDuration.WithLabelValues("record_admin_action").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to record admin action", "admin", adminID, "action", action, "error", err)
+	}
+}
+
+// auditLogHandler shows the first page of the admin audit log.
+func (b *Bot) auditLogHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.log.Info("Admin requested audit log", "user", ctx.Sender().ID)
+	b.metrics.CommandReceived.WithLabelValues("audit_log").Inc()
+
+	text, menu, err := b.auditLogPage(timeoutCtx, ctx, 0)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get admin actions", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// auditLogPageHandler switches the audit log to the page carried in the callback data.
+func (b *Bot) auditLogPageHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	page, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.Error("Invalid audit log page in callback", "error", err, "data", ctx.Data())
+		page = 0
+	}
+
+	text, menu, err := b.auditLogPage(timeoutCtx, ctx, page)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get admin actions", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// auditLogPage renders one page of the audit log, newest entries first, with the navigation row.
+// Pages past the end fall back to the first page.
+func (b *Bot) auditLogPage(
+	ctx context.Context,
+	tCtx telebot.Context,
+	page int,
+) (string, *telebot.ReplyMarkup, error) {
+	page = max(0, page)
+
+	startTime := time.Now()
+	actions, total, err := b.aurepo.GetAdminActions(ctx, auditLogPageSize, page*auditLogPageSize)
+	if err == nil && len(actions) == 0 && page > 0 {
+		page = 0
+		actions, total, err = b.aurepo.GetAdminActions(ctx, auditLogPageSize, 0)
+	}
+	b.metrics.DBQueryDuration.WithLabelValues("get_admin_actions").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return "", nil, err
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	if len(actions) == 0 {
+		return b.t(ctx, tCtx, "admin.audit.empty"), menu, nil
+	}
+
+	pages := (total + auditLogPageSize - 1) / auditLogPageSize
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(ctx, tCtx, "admin.audit.header", map[string]interface{}{
+		"page":  page + 1,
+		"pages": pages,
+	}))
+	builder.WriteString("\n\n")
+
+	for _, action := range actions {
+		admin := action.AdminName
+		if admin == "" {
+			admin = strconv.FormatInt(action.AdminID, 10)
+		}
+		hash := action.PayloadHash
+		if len(hash) > auditHashLength {
+			hash = hash[:auditHashLength]
+		}
+		builder.WriteString(b.tWithData(ctx, tCtx, "admin.audit.entry", map[string]interface{}{
+			"time":   b.formatDateTime(ctx, tCtx, action.CreatedAt.Local()),
+			"admin":  admin,
+			"action": b.t(ctx, tCtx, "admin.audit.action."+action.Action),
+			"hash":   hash,
+		}))
+		builder.WriteString("\n")
+	}
+
+	var navigation []telebot.InlineButton
+	if page > 0 {
+		navigation = append(navigation, telebot.InlineButton{
+			Unique: "audit_log_page",
+			Text:   b.t(ctx, tCtx, "tasks.page.prev"),
+			Data:   strconv.Itoa(page - 1),
+		})
+	}
+	if page < pages-1 {
+		navigation = append(navigation, telebot.InlineButton{
+			Unique: "audit_log_page",
+			Text:   b.t(ctx, tCtx, "tasks.page.next"),
+			Data:   strconv.Itoa(page + 1),
+		})
+	}
+	if len(navigation) > 0 {
+		menu.InlineKeyboard = [][]telebot.InlineButton{navigation}
+	}
+
+	return builder.String(), menu, nil
+}
@@ -6,43 +6,21 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/UnknownOlympus/olympus-protos/gen/go/scraper/olympus"
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/UnknownOlympus/oracle/internal/i18n"
 	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/UnknownOlympus/oracle/internal/report"
-	"github.com/redis/go-redis/v9"
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/telebot.v4"
 )
 
-// logoutHandler handles the logout process for a user. It removes the user's state from
-// the userStates map, logs the logout action, and attempts to delete the user from the
-// repository. If the deletion is successful, it sends a success message; otherwise, it
-// informs the user of a failure. The operation is performed with a timeout of 3 seconds.
-func (b *Bot) logoutHandler(ctx telebot.Context) error {
-	userID := ctx.Sender().ID
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	b.stateManager.Get(userID)
-	b.log.Info("User logged out", "user", userID)
-	b.metrics.CommandReceived.WithLabelValues("logout").Inc()
-
-	startTime := time.Now()
-	err := b.usrepo.DeleteUserByID(timeoutCtx, userID)
-	b.metrics.DBQueryDuration.WithLabelValues("delete_user").Observe(time.Since(startTime).Seconds())
-	if err != nil {
-		b.metrics.SentMessages.WithLabelValues("error").Inc()
-		return ctx.Send(b.t(timeoutCtx, ctx, "logout.error"))
-	}
-
-	menu := b.buildMainMenu(timeoutCtx, ctx)
-	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	return ctx.Send(b.t(timeoutCtx, ctx, "logout.success"), menu)
-}
-
 // infoHandler handles the request for user information. It logs the request, retrieves the employee data
 // from the repository using the user's ID, and sends a formatted response containing the user's name,
 // position, email, and phone number. In case of an error while fetching the employee data, it logs the
@@ -52,48 +30,28 @@ func (b *Bot) infoHandler(ctx telebot.Context) error {
 	b.metrics.CommandReceived.WithLabelValues("info").Inc()
 
 	userID := ctx.Sender().ID
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	cacheKey := fmt.Sprintf("oracle:info:user:%d", userID)
 	const cacheTTL = 12 * time.Hour
 
-	cachedUserJSON, err := b.redisClient.Get(timeoutCtx, cacheKey).Result()
-	if err == nil {
-		b.log.Info("Info found in cache", "user", userID, "key", cacheKey)
-		b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
-		var user models.Employee
-		if json.Unmarshal([]byte(cachedUserJSON), &user) == nil {
-			responseText := b.formatUserInfo(timeoutCtx, ctx, user)
-			b.metrics.SentMessages.WithLabelValues("text_cached").Inc()
-			return ctx.Send(responseText, telebot.ModeMarkdown)
-		}
+	loadUser := func(ctx context.Context) (models.Employee, error) {
+		b.log.InfoContext(ctx, "User info not in cache, fetching from DB", "user", userID)
+		startTime := time.Now()
+		defer func() {
+			b.metrics.DBQueryDuration.WithLabelValues("get_employee").Observe(time.Since(startTime).Seconds())
+		}()
+		return b.tarepo.GetEmployee(ctx, userID)
 	}
 
-	b.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
-	b.log.Info("User info not in cache, fetching from DB", "user", userID)
-	startTime := time.Now()
-	user, err := b.tarepo.GetEmployee(timeoutCtx, userID)
-	b.metrics.DBQueryDuration.WithLabelValues("get_employee").Observe(time.Since(startTime).Seconds())
+	user, err := cache.Fetch(timeoutCtx, b.cache, cacheKey, cacheTTL, loadUser)
 	if err != nil {
 		b.log.Error("Failed to get employee data", "error", err)
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
 		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
 	}
 
-	userJSON, err := json.Marshal(user)
-	if err != nil {
-		b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
-		b.log.Error("Failed to marshal user for caching", "error", err, "user", userID)
-	} else {
-		err = b.redisClient.Set(timeoutCtx, cacheKey, userJSON, cacheTTL).Err()
-		if err != nil {
-			b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
-			b.log.Error("Failed to save user to cache", "error", err, "user", userID)
-		}
-		b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
-	}
-
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
 	responseText := b.formatUserInfo(timeoutCtx, ctx, user)
 
@@ -117,46 +75,51 @@ func (b *Bot) formatUserInfo(ctx context.Context, tCtx telebot.Context, user mod
 
 %s`,
 		b.t(ctx, tCtx, "info.title"),
-		b.tWithData(ctx, tCtx, "info.name", map[string]interface{}{"name": user.FullName}),
-		b.tWithData(ctx, tCtx, "info.position", map[string]interface{}{"position": user.Position}),
-		b.tWithData(ctx, tCtx, "info.email", map[string]interface{}{"email": user.Email}),
-		b.tWithData(ctx, tCtx, "info.phone", map[string]interface{}{"phone": user.Phone}),
+		b.tWithData(ctx, tCtx, "info.name", map[string]interface{}{"name": telegramfmt.Markdown.Escape(user.FullName)}),
+		b.tWithData(ctx, tCtx, "info.position", map[string]interface{}{
+			"position": telegramfmt.Markdown.Escape(user.Position),
+		}),
+		b.tWithData(ctx, tCtx, "info.email", map[string]interface{}{"email": telegramfmt.Markdown.Escape(user.Email)}),
+		b.tWithData(ctx, tCtx, "info.phone", map[string]interface{}{"phone": telegramfmt.Markdown.Escape(user.Phone)}),
 		b.tWithData(ctx, tCtx, "info.admin_privileges", map[string]interface{}{"admin": adminStatus}),
 		b.t(ctx, tCtx, "info.footer"),
 	)
 }
 
-// formatTaskDetails is a helper function for taskDetailsHandler.
-func formatTaskDetails(details *models.TaskDetails) string {
+// formatTaskDetails is a helper function for taskDetailsHandler, dates are formatted for the language.
+// The data of the task is escaped, so descriptions and names with markup characters render as typed.
+func formatTaskDetails(format telegramfmt.Formatter, lang string, details *models.TaskDetails) string {
 	messageText := fmt.Sprintf(
-		"*Task details #%d*\n\n"+
-			"*Type:* %s\n"+
-			"*Created:* %s",
-		details.ID,
-		details.Type,
-		details.CreationDate.Format("02.01.2006"),
+		"%s\n\n"+
+			"%s %s\n"+
+			"%s %s",
+		format.Bold(fmt.Sprintf("Task details #%d", details.ID)),
+		format.Bold("Type:"), format.Escape(details.Type),
+		format.Bold("Created:"), format.Escape(i18n.FormatDate(lang, details.CreationDate)),
 	)
 	if len(details.CustomerNames) > 0 {
-		messageText += fmt.Sprintf("\n*Client Name:* %s", strings.Join(details.CustomerNames, ", "))
+		messageText += fmt.Sprintf("\n%s %s", format.Bold("Client Name:"),
+			format.Escape(strings.Join(details.CustomerNames, ", ")))
 	}
 	suffixText := fmt.Sprintf(
-		"\n*Address:* %s\n"+
-			"*Description:* %s\n"+
-			"*Assigned to:* %s",
-		details.Address,
-		details.Description,
-		strings.Join(details.Executors, ", "),
+		"\n%s %s\n"+
+			"%s %s\n"+
+			"%s %s",
+		format.Bold("Address:"), format.Escape(details.Address),
+		format.Bold("Description:"), format.Escape(details.Description),
+		format.Bold("Assigned to:"), format.Escape(strings.Join(details.Executors, ", ")),
 	)
 	messageText += suffixText
 	if len(details.Comments) > 0 {
-		messageText += fmt.Sprintf("\n*Comments:*\n- %s", strings.Join(details.Comments, ";\n- "))
+		messageText += fmt.Sprintf("\n%s\n%s", format.Bold("Comments:"),
+			format.Escape("- "+strings.Join(details.Comments, ";\n- ")))
 	}
 
 	if details.Latitude.Valid && details.Longitude.Valid {
 		mapURL := fmt.Sprintf("https://maps.google.com/?q=%f,%f", details.Latitude.Float64, details.Longitude.Float64)
-		messageText += fmt.Sprintf("\n\n[📍 Open on map](%s)", mapURL)
+		messageText += "\n\n" + format.Link("📍 Open on map", mapURL)
 	} else {
-		messageText += "\n\n📍 *Location not added yet*"
+		messageText += "\n\n📍 " + format.Bold("Location not added yet")
 	}
 
 	return messageText
@@ -164,7 +127,7 @@ func formatTaskDetails(details *models.TaskDetails) string {
 
 // activeTasksHandler handles the request for active tasks from the user.
 // It retrieves the active tasks assigned to the user and sends a response
-// with the list of tasks. If there are no active tasks, it informs the user.
+// with the first page of tasks. If there are no active tasks, it informs the user.
 // In case of an error while fetching tasks, it sends an internal error message.
 // The function also creates a dynamic inline keyboard for task selection.
 func (b *Bot) activeTasksHandler(ctx telebot.Context) error {
@@ -172,12 +135,10 @@ func (b *Bot) activeTasksHandler(ctx telebot.Context) error {
 	b.log.Info("User requested active tasks", "user", userID)
 	b.metrics.CommandReceived.WithLabelValues("active_tasks").Inc()
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
-	startTime := time.Now()
-	tasks, err := b.tarepo.GetActiveTasksByExecutor(timeoutCtx, userID)
-	b.metrics.DBQueryDuration.WithLabelValues("get_active_tasks").Observe(time.Since(startTime).Seconds())
+	tasks, err := b.getActiveTasks(timeoutCtx, userID, models.ActiveTaskFilter{})
 	if err != nil {
 		b.log.Error("Failed to get active tasks", "error", err, "user", userID)
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
@@ -189,26 +150,176 @@ func (b *Bot) activeTasksHandler(ctx telebot.Context) error {
 		return ctx.Send(b.t(timeoutCtx, ctx, "tasks.active.none"))
 	}
 
+	text, menu := b.buildActiveTasksPage(timeoutCtx, ctx, tasks, 0, models.ActiveTaskFilter{})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// activeTasksPageHandler switches the active tasks list to the page carried in the callback data,
+// followed by the filter of the list.
+func (b *Bot) activeTasksPageHandler(ctx telebot.Context) error {
+	b.metrics.CommandReceived.WithLabelValues("active_tasks_page").Inc()
+	_ = ctx.Respond()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	rawPage, rawFilter, _ := strings.Cut(ctx.Data(), "|")
+	page, err := strconv.Atoi(rawPage)
+	if err != nil {
+		b.log.Error("Invalid page in callback", "error", err, "data", ctx.Data())
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	return b.showActiveTasks(timeoutCtx, ctx, page, decodeTaskFilter(rawFilter))
+}
+
+// showActiveTasks edits the message into the page of active tasks matching the filter.
+func (b *Bot) showActiveTasks(
+	ctx context.Context,
+	tCtx telebot.Context,
+	page int,
+	filter models.ActiveTaskFilter,
+) error {
+	userID := tCtx.Sender().ID
+	tasks, err := b.getActiveTasks(ctx, userID, filter)
+	if err != nil {
+		b.log.Error("Failed to get active tasks", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+
+	// With a filter set the list stays, so the filter can be cleared.
+	if len(tasks) == 0 && filter == (models.ActiveTaskFilter{}) {
+		b.metrics.SentMessages.WithLabelValues("edit").Inc()
+		return tCtx.Edit(b.t(ctx, tCtx, "tasks.active.none"))
+	}
+
+	text, menu := b.buildActiveTasksPage(ctx, tCtx, tasks, page, filter)
+
+	return b.editIfChanged(ctx, tCtx.Message(), text, menu)
+}
+
+// getActiveTasks returns the active tasks of the user matching the filter.
+func (b *Bot) getActiveTasks(
+	ctx context.Context,
+	userID int64,
+	filter models.ActiveTaskFilter,
+) ([]models.ActiveTask, error) {
+	startTime := time.Now()
+	tasks, err := b.tarepo.GetActiveTasksByExecutorFiltered(ctx, userID, filter)
+	b.metrics.DBQueryDuration.WithLabelValues("get_active_tasks").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// buildActiveTasksPage renders one page of active tasks as the row of filter chips, a grid of
+// task buttons and the navigation row. Every task shows its age, tasks open for longer than the
+// SLA of their type are marked with ⚠️. Tasks grouped by type are listed one per row with the
+// name of their type. Out-of-range pages are clamped.
+func (b *Bot) buildActiveTasksPage(
+	ctx context.Context,
+	tCtx telebot.Context,
+	tasks []models.ActiveTask,
+	page int,
+	filter models.ActiveTaskFilter,
+) (string, *telebot.ReplyMarkup) {
+	rows := [][]telebot.InlineButton{b.taskFilterChips(ctx, tCtx, filter)}
+	if len(tasks) == 0 {
+		return b.t(ctx, tCtx, "tasks.filter.none"), &telebot.ReplyMarkup{InlineKeyboard: rows}
+	}
+
+	pageSize := b.pageSize
+	if pageSize <= 0 {
+		pageSize = len(tasks)
+	}
+	pages := (len(tasks) + pageSize - 1) / pageSize
+	page = max(0, min(page, pages-1))
+	pageTasks := tasks[page*pageSize : min((page+1)*pageSize, len(tasks))]
+
 	// creates dynamic inline keyboard
-	var rows [][]telebot.InlineButton
-	buttons := make([]telebot.InlineButton, 0, 3)
+	perRow := 3
+	if filter.ByType {
+		perRow = 1
+	}
+	buttons := make([]telebot.InlineButton, 0, perRow)
+	lang := b.getUserLanguage(ctx, tCtx)
+	now := time.Now()
 
-	for idx, task := range tasks {
+	for idx, task := range pageTasks {
 		btn := telebot.InlineButton{
 			Unique: "task_details",
-			Text:   fmt.Sprintf("#%d", task.ID),
+			Text:   fmt.Sprintf("#%d · %s", task.ID, b.taskAge(lang, now.Sub(task.CreationDate))),
 			Data:   strconv.Itoa(task.ID),
 		}
+		if task.SLABreached(now) {
+			btn.Text = "⚠️ " + btn.Text
+		}
+		if filter.ByType {
+			btn.Text += " · " + task.Type
+		}
 		buttons = append(buttons, btn)
-		if (idx+1)%3 == 0 || idx == len(tasks)-1 {
+		if (idx+1)%perRow == 0 || idx == len(pageTasks)-1 {
 			rows = append(rows, buttons)
 			buttons = nil
 		}
 	}
 
-	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	menu := &telebot.ReplyMarkup{InlineKeyboard: rows}
-	return ctx.Send(b.t(timeoutCtx, ctx, "tasks.active.title"), menu)
+	text := b.localizer.Get(lang, "tasks.active.title")
+	breached := 0
+	for _, task := range tasks {
+		if task.SLABreached(now) {
+			breached++
+		}
+	}
+	if breached > 0 {
+		text += "\n" + b.localizer.GetWithData(lang, "tasks.active.sla_breached", map[string]interface{}{
+			"count": breached,
+		})
+	}
+	if pages > 1 {
+		text += "\n" + b.tWithData(ctx, tCtx, "tasks.active.page", map[string]interface{}{
+			"page":  page + 1,
+			"pages": pages,
+			"count": len(tasks),
+		})
+
+		flags := encodeTaskFilter(filter)
+		var navigation []telebot.InlineButton
+		if page > 0 {
+			navigation = append(navigation, telebot.InlineButton{
+				Unique: "tasks_page",
+				Text:   b.t(ctx, tCtx, "tasks.page.prev"),
+				Data:   strconv.Itoa(page-1) + "|" + flags,
+			})
+		}
+		if page < pages-1 {
+			navigation = append(navigation, telebot.InlineButton{
+				Unique: "tasks_page",
+				Text:   b.t(ctx, tCtx, "tasks.page.next"),
+				Data:   strconv.Itoa(page+1) + "|" + flags,
+			})
+		}
+		rows = append(rows, navigation)
+	}
+
+	return text, &telebot.ReplyMarkup{InlineKeyboard: rows}
+}
+
+// taskAge formats the age of a task compactly for task buttons, in days or, for tasks younger
+// than a day, in hours.
+func (b *Bot) taskAge(lang string, age time.Duration) string {
+	const hoursPerDay = 24
+	hours := int(age.Hours())
+	if hours >= hoursPerDay {
+		return b.localizer.GetWithData(lang, "tasks.age.days", map[string]interface{}{"days": hours / hoursPerDay})
+	}
+	return b.localizer.GetWithData(lang, "tasks.age.hours", map[string]interface{}{"hours": max(hours, 0)})
 }
 
 // taskDetailsHandler now acts as a high-level orchestrator.
@@ -216,7 +327,7 @@ func (b *Bot) taskDetailsHandler(ctx telebot.Context) error {
 	b.metrics.CommandReceived.WithLabelValues("task_details").Inc()
 	taskID, err := strconv.Atoi(ctx.Data())
 	if err != nil {
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 		defer cancel()
 		b.log.Error("Invalid task ID in callback", "error", err, "data", ctx.Data())
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
@@ -226,7 +337,7 @@ func (b *Bot) taskDetailsHandler(ctx telebot.Context) error {
 	userID := ctx.Sender().ID
 	b.log.Info("User requested task details", "user", userID, "taskID", taskID)
 
-	tCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	tCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	// 1. Get the task details (from cache or DB).
@@ -237,15 +348,22 @@ func (b *Bot) taskDetailsHandler(ctx telebot.Context) error {
 	}
 
 	// 2. Build the keyboard for the response.
-	newMarkup := b.buildTaskKeyboard(ctx.Message().ReplyMarkup, taskID)
+	newMarkup := b.buildTaskKeyboard(ctx.Message().ReplyMarkup, taskID, false, b.IsAdminCheck(userID))
 
 	// 3. Format and send the final message.
-	messageText := formatTaskDetails(details)
+	messageText := formatTaskDetails(b.format, b.getUserLanguage(tCtx, ctx), details)
 	return b.sendOrEditMessage(ctx, messageText, newMarkup)
 }
 
 // buildTaskKeyboard encapsulates all logic for creating the keyboard.
-func (b *Bot) buildTaskKeyboard(originalMarkup *telebot.ReplyMarkup, currentTaskID int) *telebot.ReplyMarkup {
+// In the history view the history button is replaced with a button leading back to the details.
+// Admins also get a button opening the task in the CRM, when its URL is configured.
+func (b *Bot) buildTaskKeyboard(
+	originalMarkup *telebot.ReplyMarkup,
+	currentTaskID int,
+	historyView bool,
+	isAdmin bool,
+) *telebot.ReplyMarkup {
 	addCommentButton := telebot.InlineButton{
 		Unique: "leave_comment",
 		Text: "💬 " + b.localizer.Get(
@@ -254,12 +372,45 @@ func (b *Bot) buildTaskKeyboard(originalMarkup *telebot.ReplyMarkup, currentTask
 		), // Use English as fallback since we don't have ctx here
 		Data: strconv.Itoa(currentTaskID),
 	}
-	newRows := [][]telebot.InlineButton{{addCommentButton}}
+	toggleButton := telebot.InlineButton{
+		Unique: "task_history",
+		Text:   b.localizer.Get("en", "task.button.history"),
+		Data:   strconv.Itoa(currentTaskID),
+	}
+	if historyView {
+		toggleButton = telebot.InlineButton{
+			Unique: "task_details",
+			Text:   b.localizer.Get("en", "task.button.details"),
+			Data:   strconv.Itoa(currentTaskID),
+		}
+	}
+	customerButton := telebot.InlineButton{
+		Unique: "task_customer",
+		Text:   b.localizer.Get("en", "task.button.customer"),
+		Data:   strconv.Itoa(currentTaskID),
+	}
+	materialsButton := telebot.InlineButton{
+		Unique: "task_materials",
+		Text:   b.localizer.Get("en", "task.button.materials"),
+		Data:   strconv.Itoa(currentTaskID),
+	}
+	newRows := [][]telebot.InlineButton{
+		{addCommentButton, toggleButton},
+		{customerButton},
+		{materialsButton},
+	}
+	if isAdmin && b.crmTaskURL != "" {
+		newRows = append(newRows, []telebot.InlineButton{{
+			Text: b.localizer.Get("en", "task.button.crm"),
+			URL:  strings.ReplaceAll(b.crmTaskURL, "{id}", strconv.Itoa(currentTaskID)),
+		}})
+	}
 
 	if originalMarkup != nil {
 		b.log.Debug("Received not empty reply keyboard")
 		for _, row := range originalMarkup.InlineKeyboard {
-			if len(row) > 0 && strings.Contains(row[0].Data, "task_details") {
+			if len(row) > 0 &&
+				(strings.Contains(row[0].Data, "task_details") || strings.Contains(row[0].Data, "tasks_page")) {
 				newRows = append(newRows, row)
 			}
 		}
@@ -269,52 +420,101 @@ func (b *Bot) buildTaskKeyboard(originalMarkup *telebot.ReplyMarkup, currentTask
 	return newMarkup
 }
 
+// taskHistoryHandler shows the chronological status and assignment history of the task
+// in place of its details.
+func (b *Bot) taskHistoryHandler(ctx telebot.Context) error {
+	b.metrics.CommandReceived.WithLabelValues("task_history").Inc()
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+		defer cancel()
+		b.log.Error("Invalid task ID in callback", "error", err, "data", ctx.Data())
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.log.Info("User requested task history", "user", ctx.Sender().ID, "taskID", taskID)
+
+	tCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	details, err := b.getTaskDetails(tCtx, taskID)
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: "Error retrieving data."})
+	}
+	_ = ctx.Respond()
+
+	newMarkup := b.buildTaskKeyboard(ctx.Message().ReplyMarkup, taskID, true, b.IsAdminCheck(ctx.Sender().ID))
+	return b.sendOrEditMessage(ctx, formatTaskHistory(b.format, b.getUserLanguage(tCtx, ctx), details), newMarkup)
+}
+
+// formatTaskHistory renders the task history as a chronological list of changes.
+func formatTaskHistory(format telegramfmt.Formatter, lang string, details *models.TaskDetails) string {
+	messageText := format.Bold(fmt.Sprintf("Task history #%d", details.ID)) + "\n"
+	if len(details.History) == 0 {
+		return messageText + "\n" + format.Escape("No status changes recorded yet.")
+	}
+
+	for _, event := range details.History {
+		var change string
+		switch event.Type {
+		case "status":
+			change = "Status → " + event.Value
+		case "assigned":
+			change = "Assigned: " + event.Value
+		case "unassigned":
+			change = "Unassigned: " + event.Value
+		default:
+			change = event.Type + ": " + event.Value
+		}
+		createdAt := format.Code(i18n.FormatDateTime(lang, event.CreatedAt))
+		messageText += fmt.Sprintf("\n%s %s", createdAt, format.Escape(change))
+		if event.Actor != "" {
+			messageText += " " + format.Italic("("+event.Actor+")")
+		}
+	}
+
+	return messageText
+}
+
 // getTaskDetails handles the logic of fetching from cache or the database.
 func (b *Bot) getTaskDetails(ctx context.Context, taskID int) (*models.TaskDetails, error) {
 	cacheKey := fmt.Sprintf("oracle:task_details:%d", taskID)
 	const cacheTTL = 5 * time.Minute
 
-	cachedTaskJSON, err := b.redisClient.Get(ctx, cacheKey).Result()
-	if err == nil {
-		b.log.InfoContext(ctx, "Task found in cache", "task", taskID)
-		b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
-		var details models.TaskDetails
-		if json.Unmarshal([]byte(cachedTaskJSON), &details) == nil {
-			return &details, nil
-		}
+	loadDetails := func(ctx context.Context) (*models.TaskDetails, error) {
+		b.log.InfoContext(ctx, "Task details not in cache, fetching from DB", "task", taskID)
+		return b.tarepo.GetTaskDetailsByID(ctx, taskID)
 	}
 
-	b.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
-	b.log.InfoContext(ctx, "Task details not in cache, fetching from DB", "task", taskID)
-
-	details, err := b.tarepo.GetTaskDetailsByID(ctx, taskID)
+	details, err := cache.Fetch(ctx, b.cache, cacheKey, cacheTTL, loadDetails)
 	if err != nil {
 		b.log.ErrorContext(ctx, "Failed to get task details", "error", err, "taskID", taskID)
 		return nil, fmt.Errorf("failed to get task details: %w", err)
 	}
 
-	taskJSON, err := json.Marshal(details)
-	if err == nil {
-		err = b.redisClient.Set(ctx, cacheKey, taskJSON, cacheTTL).Err()
-		if err != nil {
-			b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
-			b.log.ErrorContext(ctx, "Failed to save task details to cache", "error", err)
-		} else {
-			b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
-		}
-	}
-
 	return details, nil
 }
 
 // sendOrEditMessage handles the final step of sending the response.
+// The message is left as is when it already shows the text and the keyboard.
 func (b *Bot) sendOrEditMessage(ctx telebot.Context, text string, markup *telebot.ReplyMarkup) error {
-	b.metrics.SentMessages.WithLabelValues("edit").Inc()
-	err := ctx.Edit(text, telebot.ModeMarkdown, markup)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	edit := func(text string, opts ...interface{}) error {
+		if len(telegramfmt.Split(text, telegramfmt.MaxMessageLength)) > 1 {
+			b.metrics.SentMessages.WithLabelValues("edit").Inc()
+			return b.editLong(ctx, text, opts...)
+		}
+		return b.editIfChanged(timeoutCtx, ctx.Message(), text, opts...)
+	}
+
+	err := edit(text, b.format.Mode(), markup)
 	if err != nil && !errors.Is(err, telebot.ErrSameMessageContent) {
-		b.log.Error("Failed to edit message with markdown mode", "error", err)
-		text = strings.ReplaceAll(text, "*", "")
-		err = ctx.Edit(text, markup)
+		b.log.Error("Failed to edit message with formatting", "error", err, "mode", b.format.Mode())
+		err = edit(strings.ReplaceAll(text, "*", ""), markup)
 		if err != nil {
 			b.log.Error("Failed to edit message", "error", err)
 		}
@@ -327,34 +527,69 @@ func (b *Bot) sendOrEditMessage(ctx telebot.Context, text string, markup *telebo
 // the last month, and the last 7 days. It sends a message prompting the user to select
 // their desired reporting period along with the corresponding inline keyboard menu.
 func (b *Bot) reportHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	menu := &telebot.ReplyMarkup{}
 	menu.Inline(
-		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.current_month"), "report_period_current_month")),
-		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.last_month"), "report_period_last_month")),
-		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.last_7_days"), "report_period_last_7_days")),
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.current_month"), btnReportPeriodCurrent.Unique)),
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.last_month"), btnReportPeriodLast.Unique)),
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.last_7_days"), btnReportPeriod7Days.Unique)),
 	)
 
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
 	return ctx.Send(b.t(timeoutCtx, ctx, "report.choose_period"), menu)
 }
 
+// reportFormatHandler handles the period selection and asks the user for the report format.
+// The selected period is carried to the format buttons in their callback data.
+func (b *Bot) reportFormatHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	period := ctx.Callback().Unique
+	b.log.Debug("User selected report period", "user", ctx.Sender().ID, "period", period)
+	_ = ctx.Respond()
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "report.choose_format"), b.reportFormatMenu(timeoutCtx, ctx, period))
+}
+
+// reportFormatMenu builds the keyboard with the report formats for the period, followed by
+// the button to choose the task types included in the report. PDF is offered while the
+// pdf_reports feature flag is on for the user.
+func (b *Bot) reportFormatMenu(ctx context.Context, tCtx telebot.Context, period string) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(report.Formats)+1)
+	for _, format := range report.Formats {
+		if format == report.FormatPDF && !b.featureEnabled(ctx, tCtx.Sender().ID, flagPDFReports) {
+			continue
+		}
+		label := b.t(ctx, tCtx, "report.format."+string(format))
+		rows = append(rows, menu.Row(menu.Data(label, "report_generate", period, string(format))))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "report.types.button"), "report_types", period)))
+	menu.Inline(rows...)
+
+	return menu
+}
+
 // generatorReportHandler handles the generation of reports based on the user's request.
-// It responds to the user with a message indicating that the report is being generated,
-// determines the time period for the report based on the callback unique identifier,
-// generates the report in Excel format, and sends the report back to the user.
+// It determines the time period and the format from the callback data and sends a cached
+// report right away. Otherwise the report is put into the generation queue, the user is told
+// that it is queued, and the message is edited once the file is sent.
 //
 // Supported time periods:
 // - Current month
 // - Last month
 // - Last 7 days
 //
+// Supported formats: Excel, PDF and CSV.
+//
 // If the report generation fails or there are no completed tasks for the selected period,
 // an appropriate error message is sent to the user.
 func (b *Bot) generatorReportHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
 	defer cancel()
 
 	b.metrics.CommandReceived.WithLabelValues("report").Inc()
@@ -362,24 +597,52 @@ func (b *Bot) generatorReportHandler(ctx telebot.Context) error {
 	_ = ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "report.generating")})
 
 	userID := ctx.Sender().ID
-	b.log.Info("User requested report", "user", userID, "data", ctx.Callback().Unique)
+	b.log.Info("User requested report", "user", userID, "data", ctx.Data())
 
-	from, to, periodMetric, err := b.parseReportPeriod(ctx)
+	period, rawFormat, _ := strings.Cut(ctx.Data(), "|")
+	from, to, periodMetric, err := parseReportPeriod(period, time.Now())
 	if err != nil {
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
 		return ctx.Edit(b.t(timeoutCtx, ctx, "report.error.unsupported_period"), ctx.Message().ReplyMarkup)
 	}
+	format := report.ParseFormat(rawFormat)
+	if format == report.FormatPDF && !b.featureEnabled(timeoutCtx, userID, flagPDFReports) {
+		// The button may be older than the flag being turned off.
+		format = report.FormatXLSX
+	}
 
-	cacheKey := fmt.Sprintf("oracle:report:user:%d:period:%s", userID, periodMetric)
-	if sent, _ := b.sendCachedReportIfExists(timeoutCtx, ctx, userID, cacheKey, from, to); sent {
+	cacheKey := fmt.Sprintf("oracle:report:user:%d:period:%s:format:%s", userID, periodMetric, format)
+	if sent, _ := b.sendCachedReportIfExists(timeoutCtx, ctx, userID, cacheKey, from, to, format); sent {
 		return nil
 	}
 
-	return b.generateAndSendReport(timeoutCtx, ctx, userID, from, to, periodMetric, cacheKey)
+	job := reportJob{
+		UserID:       userID,
+		Lang:         b.getUserLanguage(timeoutCtx, ctx),
+		Message:      ctx.Message(),
+		From:         from,
+		To:           to,
+		PeriodMetric: periodMetric,
+		CacheKey:     cacheKey,
+		Format:       format,
+		Span:         trace.SpanContextFromContext(timeoutCtx),
+	}
+	waiting, err := b.reports.Enqueue(func(jobCtx context.Context) { b.generateAndSendReport(jobCtx, job) })
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to queue report", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "report.queue_full"), ctx.Message().ReplyMarkup)
+	}
+
+	b.log.InfoContext(timeoutCtx, "Report queued", "user", userID, "waiting", waiting)
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.tWithData(timeoutCtx, ctx, "report.queued", map[string]interface{}{
+		"position": waiting,
+	}), ctx.Message().ReplyMarkup)
 }
 
 func (b *Bot) addCommentHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	b.metrics.CommandReceived.WithLabelValues("leave_comment").Inc()
@@ -399,19 +662,19 @@ func (b *Bot) addCommentHandler(ctx telebot.Context) error {
 	responseText := b.tWithData(timeoutCtx, ctx, "comment.prompt", map[string]interface{}{
 		"id": taskID,
 	})
-	return ctx.Send(responseText)
+	return ctx.Send(responseText, b.commentMenu(timeoutCtx, ctx, taskID))
 }
 
-func (b *Bot) parseReportPeriod(ctx telebot.Context) (time.Time, time.Time, string, error) {
-	now := time.Now()
-	switch ctx.Callback().Unique {
-	case "report_period_current_month":
+// parseReportPeriod converts the period identifier into the report date range and metric label.
+func parseReportPeriod(period string, now time.Time) (time.Time, time.Time, string, error) {
+	switch period {
+	case btnReportPeriodCurrent.Unique:
 		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 		return from, from.AddDate(0, 1, 0).Add(-time.Nanosecond), "current_1m", nil
-	case "report_period_last_month":
+	case btnReportPeriodLast.Unique:
 		from := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
 		return from, from.AddDate(0, 1, 0).Add(-time.Nanosecond), "last_1m", nil
-	case "report_period_last_7_days":
+	case btnReportPeriod7Days.Unique:
 		return now.AddDate(0, 0, -7), now, "last_7d", nil
 	default:
 		return time.Time{}, time.Time{}, "", errors.New("unsupported period")
@@ -424,28 +687,23 @@ func (b *Bot) sendCachedReportIfExists(
 	userID int64,
 	cacheKey string,
 	from, to time.Time,
+	format report.Format,
 ) (bool, error) {
-	cachedReport, err := b.redisClient.Get(ctx, cacheKey).Bytes()
+	cachedReport, err := b.cache.Get(ctx, cacheKey)
 	if err != nil {
-		b.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
 		return false, fmt.Errorf("failed to get report from cache: %w", err)
 	}
 
-	b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
 	b.log.InfoContext(ctx, "Report found in cache", "user", userID, "key", cacheKey)
 
 	responseText := b.tWithData(
 		ctx,
 		tbCtx,
 		"report.ready",
-		map[string]interface{}{"from": from.Format("02.01.2006"), "to": to.Format("02.01.2006")},
+		map[string]interface{}{"from": b.formatDate(ctx, tbCtx, from), "to": b.formatDate(ctx, tbCtx, to)},
 	)
 
-	reportFile := &telebot.Document{
-		File:     telebot.FromReader(bytes.NewReader(cachedReport)),
-		FileName: fmt.Sprintf("report_%s_%s.xlsx", from.Format("2006-01-02"), to.Format("2006-01-02")),
-		MIME:     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
-	}
+	reportFile := newReportDocument(telebot.FromReader(bytes.NewReader(cachedReport)), from, to, format)
 
 	b.metrics.SentMessages.WithLabelValues("edit").Inc()
 	_ = tbCtx.Edit(responseText, tbCtx.Message().ReplyMarkup)
@@ -453,58 +711,167 @@ func (b *Bot) sendCachedReportIfExists(
 	return true, tbCtx.Send(reportFile)
 }
 
-func (b *Bot) generateAndSendReport(
-	ctx context.Context,
-	tbCtx telebot.Context,
-	userID int64,
-	from, to time.Time,
-	periodMetric, cacheKey string,
-) error {
-	b.log.InfoContext(ctx, "Report not found in cache, generating a new one", "user", userID, "key", cacheKey)
+// reportJob is a report waiting in the generation queue. The report is sent to the chat
+// of Message, which is edited once the report is ready.
+type reportJob struct {
+	UserID       int64
+	Lang         string
+	Message      *telebot.Message
+	From, To     time.Time
+	PeriodMetric string
+	CacheKey     string
+	Format       report.Format
+	// Span links the background generation to the trace of the request that queued it.
+	Span trace.SpanContext
+}
+
+// generateAndSendReport generates the queued report, caches it and sends it to the user.
+func (b *Bot) generateAndSendReport(ctx context.Context, job reportJob) {
+	ctx, span := tracer.Start(trace.ContextWithSpanContext(ctx, job.Span), "report.generate")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, b.timeout(opReport))
+	defer cancel()
+
+	b.log.InfoContext(ctx, "Report not found in cache, generating a new one", "user", job.UserID, "key", job.CacheKey)
 
 	startTime := time.Now()
-	excelRows, err := b.formatExcelRows(ctx, userID, from, to)
+	excelRows, err := b.formatExcelRows(ctx, job.UserID, job.From, job.To)
 	if err != nil {
 		b.log.ErrorContext(ctx, "Failed to format excel rows for report generator", "error", err)
+		if hermes.IsUnavailable(err) {
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			b.editReportMessage(ctx, job, b.localizer.Get(job.Lang, "error.hermes_unavailable"))
+			return
+		}
+	}
+	var comparison *report.Comparison
+	if job.Format == report.FormatXLSX {
+		comparison = b.reportComparison(ctx, job.UserID, job.From, job.To)
 	}
-	reportBuffer, err := report.GenerateExcelReport(excelRows)
-	b.metrics.ReportGeneration.WithLabelValues(periodMetric).Observe(time.Since(startTime).Seconds())
+	reportPath, err := writeReportFile(job.Format, excelRows, comparison)
+	b.metrics.ReportGeneration.WithLabelValues(job.PeriodMetric).Observe(time.Since(startTime).Seconds())
 	if err != nil {
 		if errors.Is(err, report.ErrNoTasks) {
 			b.metrics.SentMessages.WithLabelValues("edit").Inc()
-			return tbCtx.Edit(b.t(ctx, tbCtx, "report.no_tasks"), tbCtx.Message().ReplyMarkup)
+			b.editReportMessage(ctx, job, b.localizer.Get(job.Lang, "report.no_tasks"))
+			return
 		}
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
-		b.log.ErrorContext(ctx, "Failed to generate report", "error", err, "user", userID)
-		return tbCtx.Edit(ErrInternal, tbCtx.Message().ReplyMarkup)
+		b.log.ErrorContext(ctx, "Failed to generate report", "error", err, "user", job.UserID)
+		b.editReportMessage(ctx, job, b.localizer.Get(job.Lang, "error.internal"))
+		return
 	}
 
-	const cacheTTL = 1 * time.Hour
-	if err = b.redisClient.Set(ctx, cacheKey, reportBuffer.Bytes(), cacheTTL).Err(); err != nil {
-		b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
-		b.log.ErrorContext(ctx, "Failed to save report to cache", "error", err, "key", cacheKey)
-	} else {
-		b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
+	defer os.Remove(reportPath)
+
+	b.cacheReportFile(ctx, job.CacheKey, reportPath)
+	archived := b.archiveReport(ctx, job.UserID, job.From, job.To, job.Format, reportPath)
+
+	responseText := b.localizer.GetWithData(job.Lang, "report.ready", map[string]interface{}{
+		"from": i18n.FormatDate(job.Lang, job.From),
+		"to":   i18n.FormatDate(job.Lang, job.To),
+	})
+
+	reportFile := newReportDocument(telebot.FromDisk(reportPath), job.From, job.To, job.Format)
+
+	b.log.InfoContext(
+		ctx, "Succesfully generated report", "user", job.UserID, "period", job.PeriodMetric, "format", job.Format,
+	)
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	b.editReportMessage(ctx, job, responseText)
+	msg, err := b.sendReport(ctx, job.Message.Chat, job.Lang, archived, reportFile)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to send report", "error", err, "user", job.UserID)
+		return
 	}
+	b.rememberReportFileID(ctx, archived.ID, msg)
+}
 
-	responseText := b.tWithData(
-		ctx,
-		tbCtx,
-		"report.ready",
-		map[string]interface{}{"from": from.Format("02.01.2006"), "to": to.Format("02.01.2006")},
+// writeReportFile writes the report into a temporary file and returns its path, so the report
+// is streamed to Telegram from disk instead of being held in memory. The caller removes the file.
+func writeReportFile(format report.Format, rows []report.ExcelRow, comparison *report.Comparison) (string, error) {
+	file, err := os.CreateTemp("", "oracle-report-*."+format.Extension())
+	if err != nil {
+		return "", fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	err = report.Write(file, format, rows, comparison)
+	if errClose := file.Close(); err == nil && errClose != nil {
+		err = fmt.Errorf("failed to close report file: %w", errClose)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// cacheReportFile caches the report unless it is too large to be worth keeping in Redis.
+func (b *Bot) cacheReportFile(ctx context.Context, cacheKey, path string) {
+	const (
+		cacheTTL     = 1 * time.Hour
+		maxCacheSize = 5 << 20 // 5 MiB
 	)
 
-	reportFile := &telebot.Document{
-		File:     telebot.FromReader(reportBuffer),
-		FileName: fmt.Sprintf("report_%s_%s.xlsx", from.Format("2006-01-02"), to.Format("2006-01-02")),
-		MIME:     "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > maxCacheSize {
+		b.log.InfoContext(ctx, "Report is not cached", "key", cacheKey, "error", err)
+		return
 	}
 
-	b.log.InfoContext(ctx, "Succesfully generated report", "user", userID, "period", periodMetric)
-	b.metrics.SentMessages.WithLabelValues("edit").Inc()
-	_ = tbCtx.Edit(responseText, tbCtx.Message().ReplyMarkup)
-	b.metrics.SentMessages.WithLabelValues("file").Inc()
-	return tbCtx.Send(reportFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to read report file for caching", "error", err, "key", cacheKey)
+		return
+	}
+	if err = b.cache.Set(ctx, cacheKey, content, cacheTTL); err != nil {
+		b.log.ErrorContext(ctx, "Failed to save report to cache", "error", err, "key", cacheKey)
+	}
+}
+
+// reportComparison loads the task counts of the period preceding [from, to] for the comparison sheet.
+// The report is still useful without the comparison, so on failure it logs the error and returns nil.
+func (b *Bot) reportComparison(ctx context.Context, userID int64, from, to time.Time) *report.Comparison {
+	prevFrom, prevTo := report.PreviousPeriod(from, to)
+
+	startTime := time.Now()
+	counts, err := b.rprepo.GetCompletedTaskCounts(ctx, userID, prevFrom, prevTo)
+	b.metrics.DBQueryDuration.WithLabelValues("get_completed_task_counts").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get task counts for report comparison", "error", err, "user", userID)
+		return nil
+	}
+
+	excluded := b.excludedReportTypes(ctx, userID)
+	previous := make(map[string]int, len(counts))
+	for _, count := range counts {
+		if !excluded[count.Type] {
+			previous[count.Type] = count.Count
+		}
+	}
+
+	return &report.Comparison{From: from, To: to, PreviousFrom: prevFrom, PreviousTo: prevTo, Previous: previous}
+}
+
+// editReportMessage replaces the text of the message the report was requested from.
+func (b *Bot) editReportMessage(ctx context.Context, job reportJob, text string) {
+	if _, err := b.bot.Edit(job.Message, text, job.Message.ReplyMarkup); err != nil {
+		b.log.WarnContext(ctx, "Failed to edit report message", "error", err, "user", job.UserID)
+	}
+}
+
+// newReportDocument wraps the generated report into a Telegram document with a file name and MIME type
+// matching its format.
+func newReportDocument(file telebot.File, from, to time.Time, format report.Format) *telebot.Document {
+	return &telebot.Document{
+		File: file,
+		FileName: fmt.Sprintf(
+			"report_%s_%s.%s", from.Format("2006-01-02"), to.Format("2006-01-02"), format.Extension(),
+		),
+		MIME: format.MIMEType(),
+	}
 }
 
 // nearTasksHandler handles the user's request for nearby tasks.
@@ -513,7 +880,7 @@ func (b *Bot) generateAndSendReport(
 // prompting the user to provide their geolocation.
 // This feature is currently in beta testing, and users are encouraged to report any errors.
 func (b *Bot) nearTasksHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	b.log.Info("User requested near tasks", "user", ctx.Sender().ID)
@@ -535,7 +902,7 @@ func (b *Bot) commentAcceptHandler(ctx telebot.Context) error {
 	b.log.Info("User requested accept comment", "user", ctx.Sender().ID)
 	b.metrics.CommandReceived.WithLabelValues("comment_accept").Inc()
 	_ = ctx.Respond()
-	ctxBack := context.Background()
+	ctxBack := traceContext(ctx)
 
 	parts := strings.Split(ctx.Data(), "|")
 	taskID, err := strconv.ParseInt(parts[0], 10, 64)
@@ -550,7 +917,7 @@ func (b *Bot) commentAcceptHandler(ctx telebot.Context) error {
 	cacheKey := fmt.Sprintf("oracle:comment_confirm:%s", parts[1])
 	commentText, err := b.redisClient.Get(ctxBack, cacheKey).Result()
 	if err != nil {
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 		defer cancel()
 		b.log.Warn("Could not find comment in condirmation cache", "error", err, "key", cacheKey)
 		return ctx.Edit(b.t(timeoutCtx, ctx, "comment.expired"))
@@ -562,36 +929,41 @@ func (b *Bot) commentAcceptHandler(ctx telebot.Context) error {
 	user, err := b.tarepo.GetEmployee(ctxBack, ctx.Sender().ID)
 	b.metrics.DBQueryDuration.WithLabelValues("get_employee").Observe(time.Since(startTime).Seconds())
 	if err != nil {
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 		defer cancel()
 		b.log.Error("Failed to get employee data", "error", err)
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
 		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
 	}
 
-	resp, err := b.hermesClient.AddComment(
-		ctxBack,
-		&olympus.AddCommentRequest{TaskId: taskID, Author: user.ShortName, Text: commentText},
-	)
+	// The comment is persisted first, so it is not lost if Hermes is down.
+	comment := models.OutboxComment{
+		TelegramID: ctx.Sender().ID, TaskID: taskID, Author: user.ShortName, Text: commentText,
+	}
+	comment.ID, err = b.obrepo.EnqueueComment(ctxBack, comment)
 	if err != nil {
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 		defer cancel()
-		b.log.Error("Failed to get response from Hermes", "error", err)
+		b.log.Error("Failed to save comment to outbox", "error", err)
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
 		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
 	}
 
-	go b.updateTaskCommentsInCache(context.Background(), taskID, resp.GetComments())
-
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
+	if _, err = b.deliverOutboxComment(ctxBack, comment); err != nil {
+		b.log.Warn("Failed to deliver comment to Hermes, queued for retry", "error", err, "id", comment.ID)
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "comment.queued"))
+	}
+
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
 	return ctx.Edit(b.t(timeoutCtx, ctx, "comment.success"))
 }
 
 // commentDeclineHandler - cancel.
 func (b *Bot) commentDeclineHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	b.log.Info("User requested decline comment", "user", ctx.Sender().ID)
@@ -604,16 +976,16 @@ func (b *Bot) updateTaskCommentsInCache(ctx context.Context, taskID int64, newCo
 	cacheKey := fmt.Sprintf("oracle:task_details:%d", taskID)
 	log := b.log.With("op", "updateTaskCache", "key", cacheKey)
 
-	cachedTaskJSON, err := b.redisClient.Get(ctx, cacheKey).Result()
+	cachedTaskJSON, err := b.cache.Get(ctx, cacheKey)
 	if err != nil {
-		if !errors.Is(err, redis.Nil) {
+		if !errors.Is(err, cache.ErrMiss) {
 			log.ErrorContext(ctx, "Failed to get task from cache for update", "error", err)
 		}
 		return
 	}
 
 	var taskDetails models.TaskDetails
-	if err = json.Unmarshal([]byte(cachedTaskJSON), &taskDetails); err != nil {
+	if err = json.Unmarshal(cachedTaskJSON, &taskDetails); err != nil {
 		log.ErrorContext(ctx, "Failed to unmarshal cached task for update", "error", err)
 		return
 	}
@@ -627,7 +999,7 @@ func (b *Bot) updateTaskCommentsInCache(ctx context.Context, taskID int64, newCo
 	}
 
 	const cacheTTL = 5 * time.Minute
-	if err = b.redisClient.Set(ctx, cacheKey, updatedTaskJSON, cacheTTL).Err(); err != nil {
+	if err = b.cache.Set(ctx, cacheKey, updatedTaskJSON, cacheTTL); err != nil {
 		log.ErrorContext(ctx, "Failed to write updated task back to cache", "error", err)
 	} else {
 		log.InfoContext(ctx, "Successfully updated task comments in cache")
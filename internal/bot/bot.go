@@ -7,25 +7,82 @@ import (
 	"time"
 
 	"github.com/UnknownOlympus/olympus-protos/gen/go/scraper/olympus"
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/client/alertmanager"
+	"github.com/UnknownOlympus/oracle/internal/client/mailer"
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
 	"github.com/UnknownOlympus/oracle/internal/i18n"
+	"github.com/UnknownOlympus/oracle/internal/integrations/github"
+	"github.com/UnknownOlympus/oracle/internal/jobqueue"
 	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/UnknownOlympus/oracle/internal/storage"
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
 	"github.com/redis/go-redis/v9"
 	"gopkg.in/telebot.v4"
 )
 
 // Bot contains the bot API instance and other information.
 type Bot struct {
-	bot          *telebot.Bot
-	log          *slog.Logger
-	usrepo       repository.BotManager
-	tarepo       repository.TaskManager
-	metrics      *metrics.Metrics
-	redisClient  *redis.Client
-	hermesClient olympus.ScraperServiceClient
-	stateManager *StateManager
-	localizer    *i18n.Localizer
-	menuBuilder  *MenuBuilder
+	bot           *telebot.Bot
+	log           *slog.Logger
+	usrepo        repository.BotManager
+	tarepo        repository.TaskManager
+	subrepo       repository.SubscriptionManager
+	bcrepo        repository.BroadcastManager
+	rprepo        repository.ReportManager
+	tlrepo        repository.TaskLocationManager
+	dlrepo        repository.DeliveryManager
+	aurepo        repository.AuditManager
+	acrepo        repository.ActivityManager
+	direpo        repository.DigestManager
+	obrepo        repository.OutboxManager
+	arrepo        repository.ReportArchiveManager
+	storage       *storage.Client
+	slarepo       repository.SLAManager
+	horepo        repository.HandoverManager
+	onrepo        repository.OnboardingManager
+	prrepo        repository.ProfileManager
+	qhrepo        repository.QuietHoursManager
+	qrrepo        repository.QuickReplyManager
+	ocrepo        repository.OnCallManager
+	shrepo        repository.ShiftManager
+	marepo        repository.MaterialManager
+	flags         *featureflags.Flags
+	metrics       *metrics.Metrics
+	redisClient   redis.UniversalClient
+	cache         *cache.Cache
+	hermesClient  olympus.ScraperServiceClient
+	stateManager  *StateManager
+	broadcasts    *broadcastRegistry
+	liveLocations *liveLocationRegistry
+	lastSeen      *lastSeenCache
+	employees     *cache.Local[int64, models.Employee]
+	admins        *cache.Local[string, []models.BotUser]
+	adminFlags    *cache.Local[int64, bool]
+	inFlight      inFlight
+	reports       *jobqueue.Queue
+	localizer     *i18n.Localizer
+	menuBuilder   *MenuBuilder
+	router        *Router
+	pageSize      int
+	rateLimit     config.RateLimit
+	digest        config.Digest
+	alerts        config.Alerts
+	timeouts      config.Timeouts
+	// baseCtx is the parent of the contexts of all updates and background work, canceled when the bot stops.
+	baseCtx      context.Context
+	stopBase     context.CancelFunc
+	alertmanager *alertmanager.Client
+	github       *github.Client
+	mailer       *mailer.Client
+	crmTaskURL   string
+	format       telegramfmt.Formatter // format builds the messages composed in code
+	localesDir   string
+	// commandAliases maps slash commands to the handler names of the menu buttons they run.
+	commandAliases map[string]string
 }
 
 var (
@@ -38,20 +95,58 @@ var (
 	btnTaskDetails = telebot.InlineButton{Unique: "task_details"}
 )
 
-// NewBot creates a new bot with the given token.
-func NewBot(
-	log *slog.Logger,
-	usrepo repository.BotManager,
-	tarepo repository.TaskManager,
-	redisClient *redis.Client,
-	hermesClient olympus.ScraperServiceClient,
-	metrics *metrics.Metrics,
-	token string,
-	poller time.Duration,
-) (*Bot, error) {
+// Options groups the dependencies required to create a Bot.
+type Options struct {
+	Logger           *slog.Logger
+	UserRepo         repository.BotManager
+	TaskRepo         repository.TaskManager
+	SubscriptionRepo repository.SubscriptionManager
+	BroadcastRepo    repository.BroadcastManager
+	ReportRepo       repository.ReportManager
+	TaskLocationRepo repository.TaskLocationManager
+	DeliveryRepo     repository.DeliveryManager
+	AuditRepo        repository.AuditManager
+	ActivityRepo     repository.ActivityManager
+	DigestRepo       repository.DigestManager
+	OutboxRepo       repository.OutboxManager
+	ReportArchive    repository.ReportArchiveManager
+	Storage          *storage.Client // Storage is optional, without it archived reports are kept in the database
+	SLARepo          repository.SLAManager
+	HandoverRepo     repository.HandoverManager
+	OnboardingRepo   repository.OnboardingManager
+	FeatureFlagRepo  repository.FeatureFlagManager
+	ProfileRepo      repository.ProfileManager
+	QuietHoursRepo   repository.QuietHoursManager
+	QuickReplyRepo   repository.QuickReplyManager
+	OnCallRepo       repository.OnCallManager
+	ShiftRepo        repository.ShiftManager
+	MaterialRepo     repository.MaterialManager
+	Redis            redis.UniversalClient
+	Hermes           olympus.ScraperServiceClient
+	Metrics          *metrics.Metrics
+	Token            string
+	PollerTimeout    time.Duration
+	TasksPageSize    int
+	RateLimit        config.RateLimit
+	Digest           config.Digest
+	ReportQueue      *jobqueue.Queue
+	Alerts           config.Alerts
+	Timeouts         config.Timeouts      // Timeouts of the operation classes, the defaults for unset ones
+	Alertmanager     *alertmanager.Client // Alertmanager is optional, without it alerts cannot be silenced
+	GitHub           *github.Client       // GitHub is optional, without it feedback links to the issues page
+	Mailer           *mailer.Client       // Mailer is optional, without it users cannot log in with their email
+	CRMTaskURL       string               // CRMTaskURL is the task URL template of the CRM, {id} is the task ID
+	ParseMode        string               // ParseMode of the messages composed in code, MarkdownV2 when empty
+	LocalesDir       string               // LocalesDir holds translation overrides, empty uses embedded ones
+	CommandAliases   map[string]string    // CommandAliases maps slash commands to menu button handler names
+}
+
+// NewBot creates a new bot with the given options.
+func NewBot(opts Options) (*Bot, error) {
+	log := opts.Logger
 	bot, err := telebot.NewBot(telebot.Settings{
-		Token:  token,
-		Poller: &telebot.LongPoller{Timeout: poller},
+		Token:  opts.Token,
+		Poller: &telebot.LongPoller{Timeout: opts.PollerTimeout},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Telegram bot: %w", err)
@@ -59,66 +154,186 @@ func NewBot(
 	log.Info("Authorized on account", "account", bot.Me.Username)
 
 	stateManager := NewStateManager()
+	breaker := cache.NewBreaker(cache.DefaultFailureThreshold, cache.DefaultCooldown)
+	timeouts := withDefaultTimeouts(opts.Timeouts)
 
 	localizer, err := i18n.NewLocalizer()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize localizer: %w", err)
 	}
+	localizer.OnMiss(func(lang string, kind i18n.MissKind) {
+		opts.Metrics.TranslationMisses.WithLabelValues(lang, string(kind)).Inc()
+	})
+	if opts.LocalesDir != "" {
+		if err = localizer.Reload(opts.LocalesDir); err != nil {
+			return nil, fmt.Errorf("failed to load locale overrides: %w", err)
+		}
+	}
 
 	botInstance := &Bot{
-		bot:          bot,
-		log:          log,
-		usrepo:       usrepo,
-		tarepo:       tarepo,
-		metrics:      metrics,
-		redisClient:  redisClient,
-		hermesClient: hermesClient,
-		stateManager: stateManager,
-		localizer:    localizer,
+		bot:           bot,
+		log:           log,
+		usrepo:        opts.UserRepo,
+		tarepo:        opts.TaskRepo,
+		subrepo:       opts.SubscriptionRepo,
+		bcrepo:        opts.BroadcastRepo,
+		rprepo:        opts.ReportRepo,
+		tlrepo:        opts.TaskLocationRepo,
+		dlrepo:        opts.DeliveryRepo,
+		aurepo:        opts.AuditRepo,
+		acrepo:        opts.ActivityRepo,
+		direpo:        opts.DigestRepo,
+		obrepo:        opts.OutboxRepo,
+		arrepo:        opts.ReportArchive,
+		storage:       opts.Storage,
+		slarepo:       opts.SLARepo,
+		horepo:        opts.HandoverRepo,
+		onrepo:        opts.OnboardingRepo,
+		prrepo:        opts.ProfileRepo,
+		qhrepo:        opts.QuietHoursRepo,
+		qrrepo:        opts.QuickReplyRepo,
+		ocrepo:        opts.OnCallRepo,
+		shrepo:        opts.ShiftRepo,
+		marepo:        opts.MaterialRepo,
+		flags:         featureflags.New(log, opts.FeatureFlagRepo, featureFlagDefinitions, featureFlagsTTL),
+		metrics:       opts.Metrics,
+		redisClient:   opts.Redis,
+		cache:         cache.New(log, opts.Redis, opts.Metrics, breaker),
+		hermesClient:  opts.Hermes,
+		stateManager:  stateManager,
+		broadcasts:    newBroadcastRegistry(),
+		liveLocations: newLiveLocationRegistry(),
+		lastSeen:      newLastSeenCache(),
+		employees:     cache.NewLocal[int64, models.Employee](employeeCacheTTL, timeouts.Query),
+		admins:        cache.NewLocal[string, []models.BotUser](employeeCacheTTL, timeouts.Query),
+		adminFlags:    cache.NewLocal[int64, bool](employeeCacheTTL, timeouts.Query),
+		reports:       opts.ReportQueue,
+		localizer:     localizer,
+		pageSize:      opts.TasksPageSize,
+		rateLimit:     opts.RateLimit,
+		digest:        opts.Digest,
+		alerts:        opts.Alerts,
+		timeouts:      timeouts,
+		alertmanager:  opts.Alertmanager,
+		github:        opts.GitHub,
+		mailer:        opts.Mailer,
+		crmTaskURL:    opts.CRMTaskURL,
+		format:        telegramfmt.New(telebot.ParseMode(opts.ParseMode)),
+		localesDir:    opts.LocalesDir,
+
+		commandAliases: opts.CommandAliases,
 	}
 
+	botInstance.baseCtx, botInstance.stopBase = context.WithCancel(context.Background())
+
 	// Initialize menu builder after bot instance is created
 	botInstance.menuBuilder = NewMenuBuilder(botInstance)
 
-	botInstance.registerRoutes()
+	if err = botInstance.registerRoutes(); err != nil {
+		return nil, fmt.Errorf("failed to register routes: %w", err)
+	}
 
 	return botInstance, nil
 }
 
+// WatchLocales reloads the translation overrides whenever they change, until the context is canceled.
+func (b *Bot) WatchLocales(ctx context.Context) {
+	if err := b.localizer.Watch(ctx, b.log, b.localesDir); err != nil {
+		b.log.ErrorContext(ctx, "Locale overrides are not reloaded", "error", err)
+	}
+}
+
 // Start launches the bot to listen for updates.
 func (b *Bot) Start() {
 	b.log.Info("Telegram bot is starting...")
 	b.bot.Start()
 }
 
-// Stop gracefully stops the Telegram bot and logs the action.
-func (b *Bot) Stop() {
-	b.log.Info("Telegram bot is stopped...")
-	b.bot.Stop()
+// registerRoutes configures all routes (commands, updates, callbacks and menu buttons).
+// Every update passes the global middlewares, routes add the authentication they require
+// by being registered in the auth or admin group.
+func (b *Bot) registerRoutes() error {
+	b.bot.Use(
+		b.RecoverMiddleware, b.InFlightMiddleware, b.TracingMiddleware, b.ErrorReportMiddleware,
+		b.MetricsMiddleware, b.LastSeenMiddleware,
+	)
+	if b.rateLimit.Rate > 0 {
+		b.bot.Use(b.RateLimitMiddleware)
+	}
+
+	b.router = NewRouter(b.bot)
+	public := b.router
+	auth := public.Group(b.AuthMiddleware)
+	admin := auth.Group(b.AdminMiddleware)
+
+	// Public routes. Text is routed further to menu buttons and inputs; inline queries
+	// answer unauthenticated users with a login button.
+	public.Handle("/start", b.startHandler)
+	public.Handle("/language", b.languageHandler)
+	public.Handle("/help", b.helpHandler)
+	public.Handle("/version", b.versionHandler)
+	public.Handle(telebot.OnText, b.routeTextHandler)
+	public.Handle(telebot.OnQuery, b.inlineQueryHandler)
+
+	// Routes of employees.
+	auth.Handle(telebot.OnLocation, b.locationHandler)
+	auth.Handle(telebot.OnEdited, b.liveLocationHandler)
+	auth.Handle(telebot.OnPhoto, b.photoHandler)
+	auth.Handle(telebot.OnDocument, b.documentHandler)
+	auth.Handle("/oncall", b.onCallHandler)
+
+	// Routes of admins.
+	admin.Handle("/admin", b.adminPanelHandler)
+
+	// Reply keyboard buttons, resolved from the button text by routeTextHandler.
+	b.handleMenuButtons(public, auth, admin)
+
+	// Inline button callbacks.
+	b.handleCallbacks(public, auth, admin, b.callbackRoutes())
+
+	// Slash command aliases of reply keyboard buttons.
+	return b.handleCommandAliases(public)
 }
 
-// registerRoutes configures all routes (commands).
-func (b *Bot) registerRoutes() {
-	// Public routes.
-	b.bot.Handle("/start", b.startHandler)
-	b.bot.Handle("/language", b.languageHandler)
-	b.bot.Handle(telebot.OnText, b.routeTextHandler)
-	b.bot.Handle(&btnTaskDetails, b.taskDetailsHandler)
-	b.bot.Handle(telebot.OnLocation, b.locationHandler)
-
-	// Language selection callbacks
-	b.bot.Handle("\flanguage_en", b.languageChangeHandler)
-	b.bot.Handle("\flanguage_uk", b.languageChangeHandler)
-
-	// Inline button callbacks
-	b.bot.Handle(&btnReportPeriodCurrent, b.generatorReportHandler)
-	b.bot.Handle(&btnReportPeriodLast, b.generatorReportHandler)
-	b.bot.Handle(&btnReportPeriod7Days, b.generatorReportHandler)
-	b.bot.Handle("\fleave_comment", b.addCommentHandler)
-	b.bot.Handle("\fcomment_accept", b.commentAcceptHandler)
-	b.bot.Handle("\fcomment_decline", b.commentDeclineHandler)
-	b.bot.Handle("\fgeocoding_reset_confirm", b.geocodingResetConfirmHandler)
-	b.bot.Handle("\fgeocoding_reset_cancel", b.geocodingResetCancelHandler)
+// handleMenuButtons registers the handlers of the reply keyboard buttons by their handler names.
+func (b *Bot) handleMenuButtons(public, auth, admin *Router) {
+	public.HandleNamed("language", b.languageHandler)
+	public.HandleNamed("report_issue", b.reportIssueHandler)
+
+	auth.HandleNamed("info", b.infoHandler)
+	auth.HandleNamed("active_tasks", b.activeTasksHandler)
+	auth.HandleNamed("near_tasks", b.nearTasksHandler)
+	auth.HandleNamed("tasks_map", b.tasksMapHandler)
+	auth.HandleNamed("statistic_today", b.statisticHandlerToday)
+	auth.HandleNamed("statistic_month", b.statisticHandlerMonth)
+	auth.HandleNamed("statistic_year", b.statisticHandlerYear)
+	auth.HandleNamed("statistic_week", b.statisticHandlerWeek)
+	auth.HandleNamed("statistic_range", b.statisticHandlerRange)
+	auth.HandleNamed("report", b.reportHandler)
+	auth.HandleNamed("my_reports", b.myReportsHandler)
+	auth.HandleNamed("auto_report", b.autoReportHandler)
+	auth.HandleNamed("digest", b.digestHandler)
+	auth.HandleNamed("quiet_hours", b.quietHoursHandler)
+	auth.HandleNamed("logout", b.logoutHandler)
+	auth.HandleNamed("switch_profile", b.switchProfileHandler)
+	auth.HandleNamed("shift_start", b.shiftStartHandler)
+	auth.HandleNamed("shift_end", b.shiftEndHandler)
+
+	admin.HandleNamed("broadcast_initiate", b.broadcastInitiateHandler)
+	admin.HandleNamed("broadcast_templates", b.broadcastTemplatesHandler)
+	admin.HandleNamed("team_stats", b.teamStatsHandler)
+	admin.HandleNamed("team_performance", b.teamPerformanceHandler)
+	admin.HandleNamed("geocoding_issues", b.geocodingIssuesHandler)
+	admin.HandleNamed("geocoding_reset", b.geocodingResetHandler)
+	admin.HandleNamed("sla_config", b.slaConfigHandler)
+	admin.HandleNamed("agreements_flush", b.agreementsFlushHandler)
+	admin.HandleNamed("audit_log", b.auditLogHandler)
+	admin.HandleNamed("inactive_users", b.inactiveUsersHandler)
+	admin.HandleNamed("feature_flags", b.featureFlagsHandler)
+	admin.HandleNamed("profile_access", b.profileAccessHandler)
+	admin.HandleNamed("employee_view", b.employeeViewHandler)
+	admin.HandleNamed("oncall_rota", b.onCallRotaHandler)
+	admin.HandleNamed("timesheet", b.timesheetHandler)
 }
 
 // getUserLanguage retrieves the user's language preference from the database.
@@ -139,7 +354,7 @@ func (b *Bot) getUserLanguage(ctx context.Context, tCtx telebot.Context) string
 		if detectedLang != "en" {
 			// Save detected language asynchronously
 			go func() {
-				saveCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+				saveCtx, cancel := b.updateContext(tCtx, opQuery)
 				defer cancel()
 				if err = b.usrepo.SetUserLanguage(saveCtx, userID, detectedLang); err != nil {
 					b.log.ErrorContext(saveCtx, "Failed to save detected language", "error", err, "userID", userID)
@@ -152,6 +367,34 @@ func (b *Bot) getUserLanguage(ctx context.Context, tCtx telebot.Context) string
 	return lang
 }
 
+// languageByID returns the saved language of a user when no telebot.Context is available,
+// e.g. for messages sent by background jobs. It falls back to English.
+func (b *Bot) languageByID(ctx context.Context, userID int64) string {
+	lang, err := b.usrepo.GetUserLanguage(ctx, userID)
+	if err != nil || lang == "" {
+		return "en"
+	}
+
+	return lang
+}
+
+// languagesByID returns the languages of the users for messages sent without a Telegram context,
+// looked up with a single query. Users without a known language get English.
+func (b *Bot) languagesByID(ctx context.Context, userIDs []int64) map[int64]string {
+	languages, err := b.usrepo.GetUserLanguages(ctx, userIDs)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get user languages, using English", "users", len(userIDs), "error", err)
+		languages = make(map[int64]string, len(userIDs))
+	}
+	for _, userID := range userIDs {
+		if languages[userID] == "" {
+			languages[userID] = "en"
+		}
+	}
+
+	return languages
+}
+
 // t is a shorthand method for getting translations.
 func (b *Bot) t(ctx context.Context, tCtx telebot.Context, key string) string {
 	lang := b.getUserLanguage(ctx, tCtx)
@@ -163,3 +406,19 @@ func (b *Bot) tWithData(ctx context.Context, tCtx telebot.Context, key string, d
 	lang := b.getUserLanguage(ctx, tCtx)
 	return b.localizer.GetWithData(lang, key, data)
 }
+
+// tPlural is a shorthand method for getting the plural form of a translation matching n.
+func (b *Bot) tPlural(ctx context.Context, tCtx telebot.Context, key string, n int) string {
+	lang := b.getUserLanguage(ctx, tCtx)
+	return b.localizer.GetPlural(lang, key, n)
+}
+
+// formatDate is a shorthand method for formatting a date in the user's language.
+func (b *Bot) formatDate(ctx context.Context, tCtx telebot.Context, t time.Time) string {
+	return i18n.FormatDate(b.getUserLanguage(ctx, tCtx), t)
+}
+
+// formatDateTime is a shorthand method for formatting a date with the time of day in the user's language.
+func (b *Bot) formatDateTime(ctx context.Context, tCtx telebot.Context, t time.Time) string {
+	return i18n.FormatDateTime(b.getUserLanguage(ctx, tCtx), t)
+}
@@ -0,0 +1,401 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"gopkg.in/telebot.v4"
+)
+
+// Kinds of broadcast content.
+const (
+	broadcastText     = "text"
+	broadcastPhoto    = "photo"
+	broadcastDocument = "document"
+)
+
+// broadcastProgressStep is the number of processed recipients between updates of the progress message.
+const broadcastProgressStep = 25
+
+// broadcastMessage is the content an admin sends to all users. Media is not downloaded:
+// the Telegram file ID is reused for every recipient, and Text becomes the caption.
+// Important messages are pinned, ask recipients to confirm them and ignore quiet hours.
+type broadcastMessage struct {
+	Kind      string
+	FileID    string
+	Text      string
+	Important bool `json:",omitempty"`
+}
+
+// broadcastJob is a broadcast delivered in the background.
+type broadcastJob struct {
+	ID       int64
+	AdminID  int64
+	Message  broadcastMessage
+	UserIDs  []int64
+	Progress *telebot.Message // Progress is the message with the Stop button, nil if it could not be sent.
+}
+
+// broadcastRegistry keeps the cancel functions of running broadcasts,
+// so they can be stopped from the progress message.
+type broadcastRegistry struct {
+	mu      sync.Mutex
+	cancels map[int64]context.CancelFunc
+}
+
+func newBroadcastRegistry() *broadcastRegistry {
+	return &broadcastRegistry{cancels: make(map[int64]context.CancelFunc)}
+}
+
+// add registers a running broadcast.
+func (r *broadcastRegistry) add(id int64, cancel context.CancelFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cancels[id] = cancel
+}
+
+// remove releases the broadcast context and forgets the broadcast.
+func (r *broadcastRegistry) remove(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cancel, ok := r.cancels[id]; ok {
+		cancel()
+		delete(r.cancels, id)
+	}
+}
+
+// stopAll cancels all running broadcasts and returns how many were running.
+func (r *broadcastRegistry) stopAll() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+	return len(r.cancels)
+}
+
+// stop cancels a running broadcast. It reports false if the broadcast is not running.
+func (r *broadcastRegistry) stop(id int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cancel, ok := r.cancels[id]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// broadcastInitiateHandler starts the broadcast process.
+func (b *Bot) broadcastInitiateHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.log.Info("Admin user initiated a broadcast", "user", userID)
+
+	// 1. Set the user's state to expect a broadcast message
+	b.stateManager.Set(userID, UserState{
+		WaitingFor: stateAwaitingBroadcast,
+	})
+
+	// 2. Ask the admin to send the message or to pick one of the templates
+	menu, hasTemplates := b.broadcastPromptMenu(timeoutCtx, ctx, false)
+	if hasTemplates {
+		return ctx.Send(b.t(timeoutCtx, ctx, "admin.broadcast.prompt_templates"), menu)
+	}
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.broadcast.prompt"), menu)
+}
+
+// documentHandler accepts a document sent by an admin composing a broadcast.
+// Documents are not accepted anywhere else.
+func (b *Bot) documentHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	state, ok := b.stateManager.Get(userID)
+	if ok && state.WaitingFor == stateAwaitingOnCall {
+		return b.onCallDocumentHandler(timeoutCtx, ctx, userID)
+	}
+	if !ok || state.WaitingFor != stateAwaitingBroadcast {
+		b.metrics.SentMessages.WithLabelValues("reply").Inc()
+		return ctx.Reply(b.t(timeoutCtx, ctx, "general.use_buttons"))
+	}
+
+	b.log.Debug("User is trying to send broadcast document to everyone", "user", userID)
+	return b.broadcastMessageHandler(timeoutCtx, ctx, broadcastMessage{
+		Kind:      broadcastDocument,
+		FileID:    ctx.Message().Document.FileID,
+		Text:      ctx.Message().Caption,
+		Important: state.Category == broadcastImportant,
+	})
+}
+
+// broadcastMessageHandler records the broadcast, sends the progress message with a Stop button
+// and starts the sending process.
+func (b *Bot) broadcastMessageHandler(ctx context.Context, bCtx telebot.Context, message broadcastMessage) error {
+	adminID := bCtx.Sender().ID
+
+	// 1. Get a list of all users from the database.
+	users, err := b.usrepo.GetAllTgUserIDs(ctx)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get users for broadcast", "error", err)
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	numReceivers := len(users) - 1
+
+	// 2. Record the broadcast for auditing.
+	startTime := time.Now()
+	broadcastID, err := b.bcrepo.CreateBroadcast(ctx, adminID, message.Kind, numReceivers, message.Important)
+	b.metrics.DBQueryDuration.WithLabelValues("create_broadcast").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to create broadcast", "error", err)
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	b.recordAdminAction(ctx, adminID, repository.AuditBroadcast, map[string]interface{}{
+		"broadcast_id": broadcastID,
+		"kind":         message.Kind,
+		"file_id":      message.FileID,
+		"text":         message.Text,
+		"important":    message.Important,
+		"recipients":   numReceivers,
+	})
+
+	// 3. Confirm to the admin that the process has started. This message shows the progress later.
+	responseText := b.tWithData(ctx, bCtx, "admin.broadcast.started", map[string]interface{}{
+		"count": b.tPlural(ctx, bCtx, "admin.broadcast.recipients", numReceivers),
+	})
+	stopMenu := b.broadcastStopMenu(b.getUserLanguage(ctx, bCtx), broadcastID)
+	progress, err := b.bot.Send(bCtx.Recipient(), responseText, stopMenu)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to send broadcast progress message", "admin", adminID, "error", err)
+	}
+
+	// 4. Start the broadcast in a goroutine so the bot doesn't freeze.
+	// The broadcast outlives the handler, so it must not inherit the handler's timeout.
+	// It is tracked, so a shutdown waits for it or cancels it and saves the progress.
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	b.broadcasts.add(broadcastID, cancel)
+	job := broadcastJob{
+		ID:       broadcastID,
+		AdminID:  adminID,
+		Message:  message,
+		UserIDs:  users,
+		Progress: progress,
+	}
+	if !b.goTracked(func() { b.sendBroadcast(jobCtx, job) }) {
+		b.broadcasts.remove(broadcastID)
+		if err = b.bcrepo.FinishBroadcast(ctx, broadcastID, 0, 0, repository.BroadcastCanceled); err != nil {
+			b.log.ErrorContext(ctx, "Failed to save broadcast results", "id", broadcastID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// broadcastStopMenu returns the keyboard with the button stopping the broadcast.
+func (b *Bot) broadcastStopMenu(lang string, broadcastID int64) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(menu.Data(
+		b.localizer.Get(lang, "admin.broadcast.button.stop"), "broadcast_stop", strconv.FormatInt(broadcastID, 10),
+	)))
+	return menu
+}
+
+// broadcastStopHandler cancels a running broadcast from the Stop button of its progress message.
+func (b *Bot) broadcastStopHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("broadcast_stop").Inc()
+
+	broadcastID, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil || !b.broadcasts.stop(broadcastID) {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "admin.broadcast.not_running")})
+	}
+
+	b.log.InfoContext(timeoutCtx, "Admin stopped broadcast", "user", userID, "broadcast", broadcastID)
+	b.metrics.SentMessages.WithLabelValues("respond").Inc()
+	return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "admin.broadcast.stopping")})
+}
+
+// sendBroadcast is the background worker that sends the messages. It updates the progress
+// message every broadcastProgressStep recipients and stops early when the context is canceled.
+func (b *Bot) sendBroadcast(ctx context.Context, job broadcastJob) {
+	defer b.broadcasts.remove(job.ID)
+
+	total := len(job.UserIDs) - 1
+	b.log.InfoContext(ctx, "Starting broadcast",
+		"id", job.ID, "from_admin", job.AdminID, "user_count", total, "kind", job.Message.Kind)
+
+	admin, err := b.cachedEmployee(ctx, job.AdminID)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get employee data about admin", "user", job.AdminID, "error", err)
+	}
+	lang := b.languageByID(ctx, job.AdminID)
+	languages := b.languagesByID(ctx, job.UserIDs)
+	quietHours := b.quietHoursByID(ctx)
+
+	successfulSends := 0
+	failedSends := 0
+	deferredSends := 0
+	status := repository.BroadcastCompleted
+
+	for _, userID := range job.UserIDs {
+		// Don't send the message to the admin who initiated it
+		if userID == job.AdminID {
+			continue
+		}
+
+		// Send the message to one user in their language
+		formattedMessage := b.localizer.GetWithData(languages[userID], "broadcast.header", map[string]interface{}{
+			"name": telegramfmt.Markdown.Escape(admin.ShortName),
+		}) + "\n\n" + telegramfmt.Markdown.Escape(job.Message.Text)
+		// During the user's quiet hours, the message is postponed and counts as sent.
+		// Important messages are sent right away and pinned.
+		if !job.Message.Important && b.deferIfQuiet(ctx, quietHours[userID], sendSourceBroadcast, deferredMessage{
+			Message:   broadcastMessage{Kind: job.Message.Kind, FileID: job.Message.FileID, Text: formattedMessage},
+			ParseMode: telebot.ModeMarkdown,
+		}) {
+			successfulSends++
+			deferredSends++
+		} else if err = b.sendBroadcastMessage(ctx, job, userID, languages[userID], formattedMessage); err != nil {
+			// This can happen if a user has blocked the bot
+			b.log.WarnContext(ctx, "Failed to send broadcast message to user", "user", userID, "error", err)
+			b.recordSendFailure(context.WithoutCancel(ctx), userID, sendSourceBroadcast, err)
+			failedSends++
+		} else {
+			successfulSends++
+		}
+
+		if processed := successfulSends + failedSends; processed%broadcastProgressStep == 0 && processed < total {
+			b.updateBroadcastProgress(ctx, job, lang, successfulSends, failedSends)
+		}
+
+		// IMPORTANT: Wait a bit between messages to avoid Telegram's rate limits
+		const telegramRateTimeout = 100 * time.Millisecond
+		select {
+		case <-ctx.Done():
+		case <-time.After(telegramRateTimeout):
+		}
+		if ctx.Err() != nil {
+			status = repository.BroadcastCanceled
+			break
+		}
+	}
+
+	// The broadcast context may be canceled by now, the results must be saved anyway.
+	finishCtx := context.WithoutCancel(ctx)
+	if err = b.bcrepo.FinishBroadcast(finishCtx, job.ID, successfulSends, failedSends, status); err != nil {
+		b.log.ErrorContext(finishCtx, "Failed to save broadcast results", "id", job.ID, "error", err)
+	}
+	b.log.InfoContext(finishCtx, "Broadcast finished", "id", job.ID, "status", status,
+		"success", successfulSends, "failed", failedSends, "deferred", deferredSends)
+
+	// Send a final report back to the admin in their language
+	reportKey := "admin.broadcast.finished"
+	if status == repository.BroadcastCanceled {
+		reportKey = "admin.broadcast.canceled"
+	}
+	reportText := b.localizer.GetWithData(lang, reportKey, map[string]interface{}{
+		"success": b.localizer.GetPlural(lang, "admin.broadcast.recipients", successfulSends),
+		"failed":  b.localizer.GetPlural(lang, "admin.broadcast.recipients", failedSends),
+		"skipped": b.localizer.GetPlural(lang, "admin.broadcast.recipients", total-successfulSends-failedSends),
+	})
+	if deferredSends > 0 {
+		reportText += "\n" + b.localizer.GetWithData(lang, "admin.broadcast.deferred", map[string]interface{}{
+			"count": b.localizer.GetPlural(lang, "admin.broadcast.recipients", deferredSends),
+		})
+	}
+	if job.Progress != nil {
+		progressText := b.broadcastProgressText(lang, successfulSends, failedSends, total)
+		if _, err = b.bot.Edit(job.Progress, progressText); err != nil {
+			b.log.WarnContext(finishCtx, "Failed to update broadcast progress message", "id", job.ID, "error", err)
+		}
+	}
+	// The admin of an important broadcast checks later who has not confirmed it.
+	var reportMenu *telebot.ReplyMarkup
+	if job.Message.Important {
+		reportMenu = &telebot.ReplyMarkup{}
+		label := b.localizer.Get(lang, "admin.broadcast.button.receipts")
+		reportMenu.Inline(reportMenu.Row(reportMenu.Data(label, "broadcast_receipts", strconv.FormatInt(job.ID, 10))))
+	}
+	if _, err = b.bot.Send(telebot.ChatID(job.AdminID), reportText, reportMenu); err != nil {
+		b.log.WarnContext(finishCtx, "Failed to send result message to admin", "admin", job.AdminID, "error", err)
+	}
+}
+
+// sendBroadcastMessage sends the broadcast to one user. An important message gets the button
+// confirming it, is pinned and recorded, so the admin sees who has not confirmed it.
+func (b *Bot) sendBroadcastMessage(ctx context.Context, job broadcastJob, userID int64, lang, text string) error {
+	if !job.Message.Important {
+		_, err := b.bot.Send(telebot.ChatID(userID), job.Message.content(text), telebot.ModeMarkdown)
+		return err
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(menu.Data(
+		b.localizer.Get(lang, "broadcast.button.ack"), "broadcast_ack", strconv.FormatInt(job.ID, 10),
+	)))
+	message, err := b.bot.Send(telebot.ChatID(userID), job.Message.content(text), telebot.ModeMarkdown, menu)
+	if err != nil {
+		return err
+	}
+
+	if err = b.bcrepo.AddBroadcastRecipient(ctx, job.ID, userID); err != nil {
+		b.log.WarnContext(ctx, "Failed to record broadcast recipient", "id", job.ID, "user", userID, "error", err)
+	}
+	if err = b.bot.Pin(message, telebot.Silent); err != nil {
+		b.log.WarnContext(ctx, "Failed to pin broadcast message", "id", job.ID, "user", userID, "error", err)
+	}
+	return nil
+}
+
+// updateBroadcastProgress saves the intermediate results and refreshes the progress message.
+func (b *Bot) updateBroadcastProgress(ctx context.Context, job broadcastJob, lang string, sent, failed int) {
+	if err := b.bcrepo.UpdateBroadcastProgress(ctx, job.ID, sent, failed); err != nil {
+		b.log.WarnContext(ctx, "Failed to save broadcast progress", "id", job.ID, "error", err)
+	}
+
+	if job.Progress == nil {
+		return
+	}
+
+	text := b.broadcastProgressText(lang, sent, failed, len(job.UserIDs)-1)
+	if _, err := b.bot.Edit(job.Progress, text, b.broadcastStopMenu(lang, job.ID)); err != nil {
+		b.log.WarnContext(ctx, "Failed to update broadcast progress message", "id", job.ID, "error", err)
+	}
+}
+
+// broadcastProgressText renders the "Sent 120/450…" progress line.
+func (b *Bot) broadcastProgressText(lang string, sent, failed, total int) string {
+	return b.localizer.GetWithData(lang, "admin.broadcast.progress", map[string]interface{}{
+		"processed": sent + failed,
+		"total":     total,
+		"success":   sent,
+		"failed":    failed,
+	})
+}
+
+// content returns what is sent to a recipient: the formatted text itself,
+// or the media with the formatted text as its caption.
+func (m broadcastMessage) content(formatted string) interface{} {
+	switch m.Kind {
+	case broadcastPhoto:
+		return &telebot.Photo{File: telebot.File{FileID: m.FileID}, Caption: formatted}
+	case broadcastDocument:
+		return &telebot.Document{File: telebot.File{FileID: m.FileID}, Caption: formatted}
+	default:
+		return formatted
+	}
+}
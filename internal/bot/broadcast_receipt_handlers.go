@@ -0,0 +1,124 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+// broadcastImportant is the state category of an admin composing an important broadcast.
+const broadcastImportant = "important"
+
+// maxUnconfirmedNames limits the recipients listed as not having confirmed an important broadcast.
+const maxUnconfirmedNames = 50
+
+// broadcastImportantHandler makes the broadcast being composed important ("on") or not ("off").
+func (b *Bot) broadcastImportantHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	important := ctx.Data() == "on"
+
+	state := UserState{WaitingFor: stateAwaitingBroadcast}
+	if important {
+		state.Category = broadcastImportant
+	}
+	b.stateManager.Set(userID, state)
+	b.log.InfoContext(timeoutCtx, "Admin changed broadcast importance", "user", userID, "important", important)
+
+	_ = ctx.Respond()
+	menu, _ := b.broadcastPromptMenu(timeoutCtx, ctx, important)
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	_, err := b.bot.EditReplyMarkup(ctx.Message(), menu)
+	return err
+}
+
+// broadcastAckHandler records that the user confirmed the important broadcast in the callback
+// data. The button is removed and the message unpinned.
+func (b *Bot) broadcastAckHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("broadcast_ack").Inc()
+
+	broadcastID, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid broadcast ID in callback", "data", ctx.Data())
+		return ctx.Respond()
+	}
+
+	if _, err = b.bcrepo.AcknowledgeBroadcast(timeoutCtx, broadcastID, userID); err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to acknowledge broadcast", "error", err, "broadcast", broadcastID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	b.log.InfoContext(timeoutCtx, "User confirmed broadcast", "user", userID, "broadcast", broadcastID)
+
+	if _, err = b.bot.EditReplyMarkup(ctx.Message(), nil); err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to remove broadcast confirmation button", "error", err)
+	}
+	if err = b.bot.Unpin(ctx.Chat(), ctx.Message().ID); err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to unpin broadcast message", "error", err)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("respond").Inc()
+	return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "broadcast.acked")})
+}
+
+// broadcastReceiptsHandler shows the admin how many recipients confirmed the important broadcast
+// in the callback data and who has not confirmed it yet.
+func (b *Bot) broadcastReceiptsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("broadcast_receipts").Inc()
+	_ = ctx.Respond()
+
+	broadcastID, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid broadcast ID in callback", "data", ctx.Data())
+		return nil
+	}
+
+	receipts, err := b.bcrepo.GetBroadcastReceipts(timeoutCtx, broadcastID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get broadcast receipts", "error", err, "broadcast", broadcastID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	var unconfirmed []string
+	for _, receipt := range receipts {
+		if !receipt.AckedAt.IsZero() {
+			continue
+		}
+		name := receipt.Name
+		if name == "" {
+			name = strconv.FormatInt(receipt.TelegramID, 10)
+		}
+		unconfirmed = append(unconfirmed, name)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.broadcast.receipts", map[string]interface{}{
+		"confirmed": len(receipts) - len(unconfirmed),
+		"total":     len(receipts),
+	}))
+	if len(unconfirmed) > 0 {
+		builder.WriteString("\n\n" + b.t(timeoutCtx, ctx, "admin.broadcast.unconfirmed") + "\n")
+		for _, name := range unconfirmed[:min(len(unconfirmed), maxUnconfirmedNames)] {
+			builder.WriteString("• " + name + "\n")
+		}
+		if len(unconfirmed) > maxUnconfirmedNames {
+			builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.broadcast.unconfirmed_more", map[string]interface{}{
+				"count": len(unconfirmed) - maxUnconfirmedNames,
+			}))
+		}
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.sendLong(ctx, builder.String())
+}
@@ -0,0 +1,277 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// maxTemplateName is the maximum length of a broadcast template name, which is shown on buttons.
+const maxTemplateName = 40
+
+// expandTemplate fills in the placeholders of a broadcast template for the time now.
+func expandTemplate(text string, now time.Time) string {
+	return strings.NewReplacer(
+		"{date}", now.Format("02.01.2006"),
+		"{tomorrow}", now.AddDate(0, 0, 1).Format("02.01.2006"),
+		"{time}", now.Format("15:04"),
+	).Replace(text)
+}
+
+// broadcastTemplatesHandler lists the broadcast templates with buttons to delete them and to add a new one.
+func (b *Bot) broadcastTemplatesHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("broadcast_templates").Inc()
+	b.log.Info("Admin requested broadcast templates", "user", ctx.Sender().ID)
+
+	text, menu, err := b.broadcastTemplatesView(timeoutCtx, ctx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get broadcast templates", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// broadcastTemplatesView returns the list of broadcast templates and its keyboard.
+func (b *Bot) broadcastTemplatesView(
+	ctx context.Context,
+	tCtx telebot.Context,
+) (string, *telebot.ReplyMarkup, error) {
+	templates, err := b.bcrepo.GetBroadcastTemplates(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(templates)+1)
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, tCtx, "admin.templates.title"))
+	if len(templates) == 0 {
+		builder.WriteString("\n\n" + b.t(ctx, tCtx, "admin.templates.empty"))
+	}
+	for _, template := range templates {
+		builder.WriteString("\n\n• " + template.Name + "\n" + truncateRunes(template.Text, maxDigestDescription*2))
+		rows = append(rows, menu.Row(menu.Data("🗑 "+template.Name, "template_delete", strconv.Itoa(template.ID))))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "admin.templates.button.new"), "template_new")))
+	menu.Inline(rows...)
+
+	return builder.String(), menu, nil
+}
+
+// templateNewHandler asks the admin for the name and the text of a new broadcast template.
+func (b *Bot) templateNewHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingTemplate})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.templates.prompt"))
+}
+
+// templateInputHandler saves the broadcast template entered by the admin: the first line is its
+// name, the rest is its text. A template with the same name is replaced.
+func (b *Bot) templateInputHandler(ctx context.Context, tCtx telebot.Context, userID int64, input string) error {
+	name, text, _ := strings.Cut(strings.TrimSpace(input), "\n")
+	name, text = strings.TrimSpace(name), strings.TrimSpace(text)
+	if name == "" || text == "" || utf8.RuneCountInString(name) > maxTemplateName {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingTemplate})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.templates.invalid", map[string]interface{}{
+			"max": maxTemplateName,
+		}))
+	}
+
+	id, err := b.bcrepo.SaveBroadcastTemplate(ctx, name, text, userID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to save broadcast template", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	b.log.InfoContext(ctx, "Admin saved broadcast template", "user", userID, "template", id)
+	b.recordAdminAction(ctx, userID, repository.AuditTemplateSave, map[string]interface{}{
+		"template_id": id,
+		"name":        name,
+		"text":        text,
+	})
+
+	view, menu, err := b.broadcastTemplatesView(ctx, tCtx)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get broadcast templates", "error", err)
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.templates.saved", map[string]interface{}{"name": name}))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.tWithData(ctx, tCtx, "admin.templates.saved", map[string]interface{}{"name": name})+
+		"\n\n"+view, menu)
+}
+
+// templateDeleteHandler deletes the broadcast template in the callback data and refreshes the list.
+func (b *Bot) templateDeleteHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	id, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid template ID in callback", "data", ctx.Data())
+		return ctx.Respond()
+	}
+
+	err = b.bcrepo.DeleteBroadcastTemplate(timeoutCtx, id)
+	if err != nil && !errors.Is(err, repository.ErrBroadcastTemplateNotFound) {
+		b.log.ErrorContext(timeoutCtx, "Failed to delete broadcast template", "error", err, "template", id)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	if err == nil {
+		b.log.InfoContext(timeoutCtx, "Admin deleted broadcast template", "user", userID, "template", id)
+		b.recordAdminAction(timeoutCtx, userID, repository.AuditTemplateDelete, map[string]interface{}{
+			"template_id": id,
+		})
+	}
+	_ = ctx.Respond()
+
+	text, menu, err := b.broadcastTemplatesView(timeoutCtx, ctx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get broadcast templates", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// broadcastPromptMenu returns the keyboard of the broadcast prompt: the toggle making the broadcast
+// important and the templates to pick from. It reports whether there are any templates.
+func (b *Bot) broadcastPromptMenu(
+	ctx context.Context,
+	tCtx telebot.Context,
+	important bool,
+) (*telebot.ReplyMarkup, bool) {
+	templates, err := b.bcrepo.GetBroadcastTemplates(ctx)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get broadcast templates", "error", err)
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(templates)+1)
+	if important {
+		rows = append(rows, menu.Row(menu.Data(
+			b.t(ctx, tCtx, "admin.broadcast.button.important_on"), "broadcast_important", "off",
+		)))
+	} else {
+		rows = append(rows, menu.Row(menu.Data(
+			b.t(ctx, tCtx, "admin.broadcast.button.important_off"), "broadcast_important", "on",
+		)))
+	}
+	for _, template := range templates {
+		rows = append(rows, menu.Row(menu.Data("📋 "+template.Name, "broadcast_template", strconv.Itoa(template.ID))))
+	}
+	menu.Inline(rows...)
+	return menu, len(templates) > 0
+}
+
+// broadcastTemplateHandler previews the broadcast template in the callback data, with its
+// placeholders filled in, and asks the admin to confirm the broadcast.
+func (b *Bot) broadcastTemplateHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	template, ok := b.callbackTemplate(timeoutCtx, ctx)
+	if !ok {
+		return nil
+	}
+	_ = ctx.Respond()
+
+	id := strconv.Itoa(template.ID)
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(
+		menu.Data(b.t(timeoutCtx, ctx, "admin.templates.button.send"), "broadcast_template_send", id),
+		menu.Data(b.t(timeoutCtx, ctx, "admin.templates.button.cancel"), "broadcast_template_cancel"),
+	))
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.tWithData(timeoutCtx, ctx, "admin.templates.preview", map[string]interface{}{
+		"name": template.Name,
+		"text": expandTemplate(template.Text, time.Now()),
+	}), menu)
+}
+
+// broadcastTemplateSendHandler broadcasts the template in the callback data as a text message.
+func (b *Bot) broadcastTemplateSendHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	template, ok := b.callbackTemplate(timeoutCtx, ctx)
+	if !ok {
+		return nil
+	}
+	_ = ctx.Respond()
+
+	// The template replaces the message the admin was asked for.
+	state, _ := b.stateManager.Get(ctx.Sender().ID)
+	if err := ctx.Delete(); err != nil {
+		b.log.DebugContext(timeoutCtx, "Failed to delete template preview", "error", err)
+	}
+
+	b.log.InfoContext(timeoutCtx, "Admin broadcasts template", "user", ctx.Sender().ID, "template", template.ID)
+	return b.broadcastMessageHandler(timeoutCtx, ctx, broadcastMessage{
+		Kind:      broadcastText,
+		Text:      expandTemplate(template.Text, time.Now()),
+		Important: state.WaitingFor == stateAwaitingBroadcast && state.Category == broadcastImportant,
+	})
+}
+
+// broadcastTemplateCancelHandler drops the template preview. The admin can still send a message
+// to broadcast.
+func (b *Bot) broadcastTemplateCancelHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "admin.templates.canceled"))
+}
+
+// callbackTemplate loads the broadcast template whose ID is the callback data. It answers the
+// callback itself and reports false if the template cannot be used.
+func (b *Bot) callbackTemplate(ctx context.Context, tCtx telebot.Context) (models.BroadcastTemplate, bool) {
+	id, err := strconv.Atoi(tCtx.Data())
+	if err != nil {
+		b.log.WarnContext(ctx, "Invalid template ID in callback", "data", tCtx.Data())
+		_ = tCtx.Respond()
+		return models.BroadcastTemplate{}, false
+	}
+
+	template, err := b.bcrepo.GetBroadcastTemplate(ctx, id)
+	if errors.Is(err, repository.ErrBroadcastTemplateNotFound) {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		_ = tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "admin.templates.not_found")})
+		return models.BroadcastTemplate{}, false
+	}
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get broadcast template", "error", err, "template", id)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		_ = tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "error.internal")})
+		return models.BroadcastTemplate{}, false
+	}
+
+	return template, true
+}
@@ -0,0 +1,380 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// bulkSelectionTTL is how long the tasks selected by a dispatcher are kept.
+	bulkSelectionTTL = 30 * time.Minute
+	// bulkLabelDescription limits the description shown on the task buttons of the selection.
+	bulkLabelDescription = 30
+)
+
+// bulkSelection holds the tasks of an employee listed to a dispatcher, and those they selected.
+type bulkSelection struct {
+	EmployeeID int                 `json:"employee_id"`
+	Name       string              `json:"name"`
+	Tasks      []models.ActiveTask `json:"tasks"`
+	Selected   []int               `json:"selected"`
+}
+
+// selectedTaskIDs returns the selected tasks in the order they are listed.
+func (s bulkSelection) selectedTaskIDs() []int {
+	ids := make([]int, 0, len(s.Selected))
+	for _, task := range s.Tasks {
+		if slices.Contains(s.Selected, task.ID) {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+func bulkSelectionKey(userID int64) string {
+	return fmt.Sprintf("oracle:bulk:%d", userID)
+}
+
+// loadBulkSelection returns the selection of the dispatcher, false if it expired.
+func (b *Bot) loadBulkSelection(ctx context.Context, userID int64) (bulkSelection, bool) {
+	cached, err := b.redisClient.Get(ctx, bulkSelectionKey(userID)).Bytes()
+	if err != nil {
+		b.log.WarnContext(ctx, "Could not find bulk selection", "error", err, "user", userID)
+		return bulkSelection{}, false
+	}
+
+	var selection bulkSelection
+	if err = json.Unmarshal(cached, &selection); err != nil {
+		b.log.ErrorContext(ctx, "Failed to unmarshal bulk selection", "error", err, "user", userID)
+		return bulkSelection{}, false
+	}
+	return selection, true
+}
+
+// saveBulkSelection stores the selection of the dispatcher, extending its lifetime.
+func (b *Bot) saveBulkSelection(ctx context.Context, userID int64, selection bulkSelection) error {
+	encoded, err := json.Marshal(selection)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bulk selection: %w", err)
+	}
+	if err = b.redisClient.Set(ctx, bulkSelectionKey(userID), encoded, bulkSelectionTTL).Err(); err != nil {
+		return fmt.Errorf("failed to save bulk selection: %w", err)
+	}
+	return nil
+}
+
+// bulkExpired answers a bulk action whose selection is gone.
+func (b *Bot) bulkExpired(ctx context.Context, tCtx telebot.Context) error {
+	b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "admin.bulk.expired"))
+}
+
+// bulkStartHandler lists the active tasks of the employee in the callback data for selection.
+func (b *Bot) bulkStartHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("bulk_start").Inc()
+	_ = ctx.Respond()
+
+	employeeID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid employee ID in callback", "data", ctx.Data())
+		return nil
+	}
+
+	employee, err := b.tarepo.GetEmployeeByID(timeoutCtx, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get employee", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	tasks, err := b.tarepo.GetActiveTasksByEmployee(timeoutCtx, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get active tasks", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if len(tasks) == 0 {
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.employee_view.no_tasks", map[string]interface{}{
+			"name": employee.ShortName,
+		}))
+	}
+
+	selection := bulkSelection{
+		EmployeeID: employeeID,
+		Name:       employee.ShortName,
+		Tasks:      tasks[:min(len(tasks), employeeViewTaskLimit)],
+	}
+	if err = b.saveBulkSelection(timeoutCtx, userID, selection); err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to start bulk selection", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	return b.showBulkSelection(timeoutCtx, ctx, selection)
+}
+
+// bulkToggleHandler selects or unselects the task in the callback data.
+func (b *Bot) bulkToggleHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	_ = ctx.Respond()
+
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid task ID in callback", "data", ctx.Data(), "user", userID)
+		return nil
+	}
+
+	return b.changeBulkSelection(timeoutCtx, ctx, func(selection *bulkSelection) {
+		if idx := slices.Index(selection.Selected, taskID); idx >= 0 {
+			selection.Selected = slices.Delete(selection.Selected, idx, idx+1)
+			return
+		}
+		selection.Selected = append(selection.Selected, taskID)
+	})
+}
+
+// bulkAllHandler selects all listed tasks, or clears the selection if all of them are selected.
+func (b *Bot) bulkAllHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	return b.changeBulkSelection(timeoutCtx, ctx, func(selection *bulkSelection) {
+		if len(selection.selectedTaskIDs()) == len(selection.Tasks) {
+			selection.Selected = nil
+			return
+		}
+		selection.Selected = selection.Selected[:0]
+		for _, task := range selection.Tasks {
+			selection.Selected = append(selection.Selected, task.ID)
+		}
+	})
+}
+
+// changeBulkSelection applies the change to the selection of the dispatcher, saves it and
+// shows it in the message of the callback.
+func (b *Bot) changeBulkSelection(ctx context.Context, tCtx telebot.Context, change func(*bulkSelection)) error {
+	userID := tCtx.Sender().ID
+	selection, ok := b.loadBulkSelection(ctx, userID)
+	if !ok {
+		return b.bulkExpired(ctx, tCtx)
+	}
+
+	change(&selection)
+	if err := b.saveBulkSelection(ctx, userID, selection); err != nil {
+		b.log.ErrorContext(ctx, "Failed to change bulk selection", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+
+	return b.showBulkSelection(ctx, tCtx, selection)
+}
+
+// showBulkSelection shows the listed tasks with checkmarks on the selected ones, together
+// with the bulk actions, in the message of the callback.
+func (b *Bot) showBulkSelection(ctx context.Context, tCtx telebot.Context, selection bulkSelection) error {
+	lang := b.getUserLanguage(ctx, tCtx)
+	now := time.Now()
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(selection.Tasks)+3) //nolint:mnd // three rows of actions
+	for _, task := range selection.Tasks {
+		mark := "▫️"
+		if slices.Contains(selection.Selected, task.ID) {
+			mark = "✅"
+		}
+		label := fmt.Sprintf("%s #%d · %s", mark, task.ID, b.taskAge(lang, now.Sub(task.CreationDate)))
+		if task.SLABreached(now) {
+			label += " ⚠️"
+		}
+		label += " — " + truncateRunes(task.Description, bulkLabelDescription)
+		rows = append(rows, menu.Row(menu.Data(label, "bulk_toggle", strconv.Itoa(task.ID))))
+	}
+
+	allKey := "admin.bulk.button.all"
+	if len(selection.selectedTaskIDs()) == len(selection.Tasks) {
+		allKey = "admin.bulk.button.none"
+	}
+	rows = append(rows,
+		menu.Row(menu.Data(b.t(ctx, tCtx, allKey), "bulk_all")),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "admin.bulk.button.comment"), "bulk_comment")),
+		menu.Row(
+			menu.Data(b.t(ctx, tCtx, "admin.bulk.button.export"), "bulk_export"),
+			menu.Data(b.t(ctx, tCtx, "admin.bulk.button.cancel"), "bulk_cancel"),
+		),
+	)
+	menu.Inline(rows...)
+
+	text := b.tWithData(ctx, tCtx, "admin.bulk.title", map[string]interface{}{
+		"name":     selection.Name,
+		"selected": len(selection.selectedTaskIDs()),
+		"count":    len(selection.Tasks),
+	})
+	if err := b.editIfChanged(ctx, tCtx.Message(), text, menu); err != nil {
+		return fmt.Errorf("failed to show bulk selection: %w", err)
+	}
+	return nil
+}
+
+// selectedForAction returns the selection of the dispatcher if it has any tasks selected,
+// otherwise it answers the callback and reports false.
+func (b *Bot) selectedForAction(ctx context.Context, tCtx telebot.Context) (bulkSelection, bool) {
+	selection, ok := b.loadBulkSelection(ctx, tCtx.Sender().ID)
+	if !ok {
+		_ = tCtx.Respond()
+		_ = b.bulkExpired(ctx, tCtx)
+		return bulkSelection{}, false
+	}
+	if len(selection.selectedTaskIDs()) == 0 {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		_ = tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "admin.bulk.empty"), ShowAlert: true})
+		return bulkSelection{}, false
+	}
+
+	_ = tCtx.Respond()
+	return selection, true
+}
+
+// bulkCommentHandler asks the dispatcher for the comment to add to the selected tasks.
+func (b *Bot) bulkCommentHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("bulk_comment").Inc()
+	selection, ok := b.selectedForAction(timeoutCtx, ctx)
+	if !ok {
+		return nil
+	}
+
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingBulkComment})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.bulk.comment.prompt", map[string]interface{}{
+		"count": len(selection.selectedTaskIDs()),
+	}))
+}
+
+// bulkCommentInputHandler queues the comment entered by the dispatcher for each selected task.
+// The comments are delivered to Hermes in the background, those which fail are retried by the
+// outbox dispatcher like any other comment.
+func (b *Bot) bulkCommentInputHandler(ctx context.Context, tCtx telebot.Context, userID int64, text string) error {
+	selection, ok := b.loadBulkSelection(ctx, userID)
+	if !ok {
+		return b.bulkExpired(ctx, tCtx)
+	}
+
+	admin, err := b.cachedEmployee(ctx, userID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get employee data", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+
+	taskIDs := selection.selectedTaskIDs()
+	comments := make([]models.OutboxComment, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		comment := models.OutboxComment{TelegramID: userID, TaskID: int64(taskID), Author: admin.ShortName, Text: text}
+		comment.ID, err = b.obrepo.EnqueueComment(ctx, comment)
+		if err != nil {
+			b.log.ErrorContext(ctx, "Failed to save comment to outbox", "error", err, "task", taskID)
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	if len(comments) == 0 {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+
+	b.goTracked(func() {
+		for _, comment := range comments {
+			if _, errDeliver := b.deliverOutboxComment(b.baseCtx, comment); errDeliver != nil {
+				b.log.Warn("Failed to deliver comment to Hermes, queued for retry",
+					"error", errDeliver, "id", comment.ID)
+			}
+		}
+	})
+
+	b.recordAdminAction(ctx, userID, repository.AuditBulkComment, map[string]interface{}{
+		"tasks":       taskIDs,
+		"employee_id": selection.EmployeeID,
+	})
+	b.log.InfoContext(ctx, "Comment added in bulk", "admin", userID, "tasks", len(comments))
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.tWithData(ctx, tCtx, "admin.bulk.comment.done", map[string]interface{}{
+		"count": len(comments),
+	}))
+}
+
+// bulkExportHandler sends the selected tasks as an Excel file.
+func (b *Bot) bulkExportHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("bulk_export").Inc()
+	selection, ok := b.selectedForAction(timeoutCtx, ctx)
+	if !ok {
+		return nil
+	}
+
+	taskIDs := selection.selectedTaskIDs()
+	tasks := make([]models.TaskDetails, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		details, err := b.getTaskDetails(timeoutCtx, taskID)
+		if err != nil {
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+		}
+		tasks = append(tasks, *details)
+	}
+
+	rows, err := b.excelRowsForTasks(timeoutCtx, tasks)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to build bulk export rows", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	buffer, err := report.GenerateExcelReport(rows, nil)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to generate bulk export", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	document := &telebot.Document{
+		File:     telebot.FromReader(buffer),
+		FileName: fmt.Sprintf("tasks_%s_%s.xlsx", selection.Name, time.Now().Format("2006-01-02")),
+		MIME:     report.FormatXLSX.MIMEType(),
+	}
+	b.metrics.SentMessages.WithLabelValues("file").Inc()
+	return ctx.Send(document)
+}
+
+// bulkCancelHandler drops the selection of the dispatcher.
+func (b *Bot) bulkCancelHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	_ = ctx.Respond()
+	b.redisClient.Del(timeoutCtx, bulkSelectionKey(ctx.Sender().ID))
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "admin.bulk.cancelled"))
+}
@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"fmt"
+
+	"gopkg.in/telebot.v4"
+)
+
+// CallbackRoute binds the inline buttons with the given unique to the handler of their callbacks.
+type CallbackRoute struct {
+	Unique        string              // Unique of the inline buttons, without the "\f" prefix
+	Handler       telebot.HandlerFunc // Handler serving the callbacks
+	RequiresAuth  bool                // Whether the user must be linked to an employee, see AuthMiddleware
+	RequiresAdmin bool                // Whether the user must be an admin, implies RequiresAuth
+}
+
+// CallbackRegistry holds the routes of all inline button callbacks, analogous to MenuRegistry
+// for reply keyboard buttons.
+type CallbackRegistry struct {
+	routes []CallbackRoute
+	unique map[string]struct{}
+}
+
+// NewCallbackRegistry creates an empty callback registry.
+func NewCallbackRegistry() *CallbackRegistry {
+	return &CallbackRegistry{unique: make(map[string]struct{})}
+}
+
+// Register adds the routes to the registry. A unique registered twice is a programming error
+// and panics, as telebot would silently keep only the last handler.
+func (r *CallbackRegistry) Register(routes ...CallbackRoute) {
+	for _, route := range routes {
+		if _, exists := r.unique[route.Unique]; exists {
+			panic(fmt.Sprintf("callback %q is registered twice", route.Unique))
+		}
+		r.unique[route.Unique] = struct{}{}
+		r.routes = append(r.routes, route)
+	}
+}
+
+// Routes returns the registered routes in the order they were registered.
+func (r *CallbackRegistry) Routes() []CallbackRoute {
+	return r.routes
+}
+
+// handleCallbacks registers the handlers of all routes in the public, auth or admin group,
+// as the routes require.
+func (b *Bot) handleCallbacks(public, auth, admin *Router, registry *CallbackRegistry) {
+	for _, route := range registry.Routes() {
+		group := public
+		switch {
+		case route.RequiresAdmin:
+			group = admin
+		case route.RequiresAuth:
+			group = auth
+		}
+		group.Handle("\f"+route.Unique, route.Handler)
+	}
+}
+
+// callbackRoutes returns the routes of all inline button callbacks of the bot.
+func (b *Bot) callbackRoutes() *CallbackRegistry {
+	registry := NewCallbackRegistry()
+
+	// Language selection is available before logging in.
+	registry.Register(
+		CallbackRoute{Unique: "language_en", Handler: b.languageChangeHandler},
+		CallbackRoute{Unique: "language_uk", Handler: b.languageChangeHandler},
+		CallbackRoute{Unique: "language_pl", Handler: b.languageChangeHandler},
+	)
+
+	// Undoing a logout is available to the logged out user.
+	registry.Register(
+		CallbackRoute{Unique: "logout_undo", Handler: b.logoutUndoHandler},
+	)
+
+	// Feedback is filed as GitHub issues only by logged in users, guests get a link to the issues page.
+	registry.Register(
+		CallbackRoute{Unique: "feedback_category", Handler: b.feedbackCategoryHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "feedback_send", Handler: b.feedbackSendHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "feedback_cancel", Handler: b.feedbackCancelHandler, RequiresAuth: true},
+	)
+
+	// Guided tour after the first login.
+	registry.Register(
+		CallbackRoute{Unique: "onboarding_next", Handler: b.onboardingNextHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "onboarding_skip", Handler: b.onboardingSkipHandler, RequiresAuth: true},
+	)
+
+	// Tasks.
+	registry.Register(
+		CallbackRoute{Unique: btnTaskDetails.Unique, Handler: b.taskDetailsHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "task_history", Handler: b.taskHistoryHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "tasks_page", Handler: b.activeTasksPageHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "tasks_filter", Handler: b.activeTasksFilterHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "tasks_map_export", Handler: b.tasksMapExportHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "near_radius", Handler: b.nearRadiusHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "task_customer", Handler: b.taskCustomerHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "leave_comment", Handler: b.addCommentHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "comment_accept", Handler: b.commentAcceptHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "comment_decline", Handler: b.commentDeclineHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "task_materials", Handler: b.taskMaterialsHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "material_pick", Handler: b.materialPickHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "comment_quick", Handler: b.commentQuickHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "comment_reply_list", Handler: b.commentReplyListHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "comment_reply", Handler: b.commentReplyHandler, RequiresAuth: true},
+	)
+
+	// Reports and statistics.
+	registry.Register(
+		CallbackRoute{Unique: btnReportPeriodCurrent.Unique, Handler: b.reportFormatHandler, RequiresAuth: true},
+		CallbackRoute{Unique: btnReportPeriodLast.Unique, Handler: b.reportFormatHandler, RequiresAuth: true},
+		CallbackRoute{Unique: btnReportPeriod7Days.Unique, Handler: b.reportFormatHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "report_generate", Handler: b.generatorReportHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "report_types", Handler: b.reportTypesHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "report_type_toggle", Handler: b.reportTypeToggleHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "report_types_done", Handler: b.reportTypesDoneHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "report_archive_get", Handler: b.reportArchiveGetHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "statistic_export", Handler: b.statisticExportHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "auto_report_toggle", Handler: b.autoReportToggleHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "digest_toggle", Handler: b.digestToggleHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "digest_timezone", Handler: b.digestTimezoneHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "quiet_hours_set", Handler: b.quietHoursSetHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "profile_switch", Handler: b.profileSwitchHandler, RequiresAuth: true},
+	)
+
+	// Admin panel.
+	registry.Register(
+		CallbackRoute{Unique: "team_stats_period", Handler: b.teamStatsPeriodHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "team_performance_period", Handler: b.teamPerformancePeriodHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "broadcast_stop", Handler: b.broadcastStopHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "broadcast_important", Handler: b.broadcastImportantHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "broadcast_receipts", Handler: b.broadcastReceiptsHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "broadcast_ack", Handler: b.broadcastAckHandler, RequiresAuth: true},
+		CallbackRoute{Unique: "broadcast_template", Handler: b.broadcastTemplateHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "broadcast_template_send", Handler: b.broadcastTemplateSendHandler, RequiresAdmin: true},
+		CallbackRoute{
+			Unique: "broadcast_template_cancel", Handler: b.broadcastTemplateCancelHandler, RequiresAdmin: true,
+		},
+		CallbackRoute{Unique: "template_new", Handler: b.templateNewHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "template_delete", Handler: b.templateDeleteHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "geocoding_fix", Handler: b.geocodingFixHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "geocoding_export", Handler: b.geocodingExportHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "geocoding_reset_confirm", Handler: b.geocodingResetConfirmHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "geocoding_reset_cancel", Handler: b.geocodingResetCancelHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "sla_edit", Handler: b.slaEditHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "audit_log_page", Handler: b.auditLogPageHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "alert_silence", Handler: b.alertSilenceHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "flag_list", Handler: b.featureFlagListHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "flag_view", Handler: b.featureFlagViewHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "flag_set", Handler: b.featureFlagSetHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "profile_lead", Handler: b.profileLeadHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "profile_grant_start", Handler: b.profileGrantStartHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "profile_grant", Handler: b.profileGrantHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "profile_revoke", Handler: b.profileRevokeHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "employee_view", Handler: b.employeeTasksHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "employee_stats", Handler: b.employeeStatsHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_start", Handler: b.bulkStartHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_toggle", Handler: b.bulkToggleHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_all", Handler: b.bulkAllHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_comment", Handler: b.bulkCommentHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_export", Handler: b.bulkExportHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "bulk_cancel", Handler: b.bulkCancelHandler, RequiresAdmin: true},
+		CallbackRoute{Unique: "timesheet_period", Handler: b.timesheetPeriodHandler, RequiresAdmin: true},
+	)
+
+	return registry
+}
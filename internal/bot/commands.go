@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/UnknownOlympus/oracle/internal/i18n"
+	"gopkg.in/telebot.v4"
+)
+
+// botCommand is a built-in slash command of the bot.
+type botCommand struct {
+	Command   string
+	AdminOnly bool
+}
+
+// builtinCommands are the slash commands of the bot, which aliases cannot replace.
+var builtinCommands = []botCommand{
+	{Command: "start"},
+	{Command: "language"},
+	{Command: "help"},
+	{Command: "version"},
+	{Command: "oncall"},
+	{Command: "admin", AdminOnly: true},
+}
+
+// commandList returns the slash commands available to a user with the role, described in the
+// language. Aliases of admin panel buttons are listed to admins only.
+func (b *Bot) commandList(lang string, isAdmin bool) []telebot.Command {
+	commands := make([]telebot.Command, 0, len(builtinCommands)+len(b.commandAliases))
+	for _, command := range builtinCommands {
+		if command.AdminOnly && !isAdmin {
+			continue
+		}
+		commands = append(commands, telebot.Command{
+			Text:        command.Command,
+			Description: b.localizer.Get(lang, "command."+command.Command),
+		})
+	}
+
+	for _, command := range slices.Sorted(maps.Keys(b.commandAliases)) {
+		handlerName := b.commandAliases[command]
+		btn, ok := b.menuBuilder.registry.FindHandler(handlerName)
+		if !ok || (b.menuBuilder.registry.IsAdminHandler(handlerName) && !isAdmin) {
+			continue
+		}
+		commands = append(commands, telebot.Command{
+			Text:        command,
+			Description: b.localizer.Get(lang, btn.TextKey),
+		})
+	}
+	return commands
+}
+
+// PublishCommands sets the "/" menu of Telegram clients. Private chats get the commands of
+// regular users in the language of the client, falling back to English, and every admin gets
+// the admin commands in their own language.
+func (b *Bot) PublishCommands(ctx context.Context) error {
+	scope := telebot.CommandScope{Type: telebot.CommandScopeAllPrivateChats}
+	if err := b.bot.SetCommands(b.commandList("en", false), scope); err != nil {
+		return fmt.Errorf("failed to set default commands: %w", err)
+	}
+	for _, lang := range i18n.Languages() {
+		if err := b.bot.SetCommands(b.commandList(lang, false), scope, lang); err != nil {
+			return fmt.Errorf("failed to set %s commands: %w", lang, err)
+		}
+	}
+
+	admins, err := b.cachedAdmins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get admins: %w", err)
+	}
+	adminIDs := make([]int64, 0, len(admins))
+	for _, admin := range admins {
+		if admin.TelegramID != 0 {
+			adminIDs = append(adminIDs, admin.TelegramID)
+		}
+	}
+	languages := b.languagesByID(ctx, adminIDs)
+	for _, adminID := range adminIDs {
+		b.publishUserCommands(ctx, adminID, languages[adminID], true)
+	}
+
+	b.log.InfoContext(ctx, "Bot commands published", "languages", len(i18n.Languages()), "admins", len(adminIDs))
+	return nil
+}
+
+// publishUserCommands sets the "/" menu of the user's chat in the language the user picked in
+// the bot, which may differ from the language of the client. A failure is only logged, as the
+// commands of private chats remain.
+func (b *Bot) publishUserCommands(ctx context.Context, userID int64, lang string, isAdmin bool) {
+	scope := telebot.CommandScope{Type: telebot.CommandScopeChat, ChatID: userID}
+	if err := b.bot.SetCommands(b.commandList(lang, isAdmin), scope); err != nil {
+		b.log.WarnContext(ctx, "Failed to set user commands", "user", userID, "error", err)
+	}
+}
+
+// resetUserCommands removes the "/" menu of the user's chat, so the commands of private chats apply.
+func (b *Bot) resetUserCommands(ctx context.Context, userID int64) {
+	scope := telebot.CommandScope{Type: telebot.CommandScopeChat, ChatID: userID}
+	if err := b.bot.DeleteCommands(scope); err != nil {
+		b.log.WarnContext(ctx, "Failed to reset user commands", "user", userID, "error", err)
+	}
+}
+
+// adminPanelHandler opens the admin panel, the shortcut of the More > Admin Panel button.
+func (b *Bot) adminPanelHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("admin").Inc()
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.menuBuilder.ShowMenu(timeoutCtx, ctx, MenuAdmin, ctx.Sender().ID, "", true)
+}
@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// commentReplyLimit is the number of the latest comments offered to reply to.
+	commentReplyLimit = 8
+	// commentQuoteLength is the number of characters of the quoted comment kept in a reply.
+	commentQuoteLength = 60
+	// quickRepliesCacheKey is the cache key of the quick replies, which rarely change.
+	quickRepliesCacheKey = "oracle:quick_replies"
+	// quickRepliesCacheTTL is how long changes of the quick replies take to show up.
+	quickRepliesCacheTTL = 10 * time.Minute
+)
+
+// commentMenu returns the buttons shown with the comment prompt: the quick replies, and the
+// button to reply to an existing comment.
+func (b *Bot) commentMenu(ctx context.Context, tCtx telebot.Context, taskID int) *telebot.ReplyMarkup {
+	data := strconv.Itoa(taskID)
+	menu := &telebot.ReplyMarkup{}
+
+	replies, err := b.quickReplies(ctx)
+	if err != nil {
+		// The user can still type the comment.
+		b.log.ErrorContext(ctx, "Failed to get quick replies", "error", err)
+	}
+	rows := make([]telebot.Row, 0, len(replies)+1)
+	for _, reply := range replies {
+		rows = append(rows, menu.Row(menu.Data(reply.Text, "comment_quick", data, strconv.Itoa(reply.ID))))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "comment.button.reply"), "comment_reply_list", data)))
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// quickReplies returns the quick replies configured in the database.
+func (b *Bot) quickReplies(ctx context.Context) ([]models.QuickReply, error) {
+	return cache.Fetch(ctx, b.cache, quickRepliesCacheKey, quickRepliesCacheTTL, b.qrrepo.GetQuickReplies)
+}
+
+// commentQuickHandler takes the picked quick reply as the comment and asks to confirm it.
+func (b *Bot) commentQuickHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("comment_quick").Inc()
+	rawTask, rawReply, _ := strings.Cut(ctx.Data(), "|")
+	taskID, errTask := strconv.Atoi(rawTask)
+	replyID, errReply := strconv.Atoi(rawReply)
+	if errTask != nil || errReply != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid quick reply callback", "data", ctx.Data(), "user", ctx.Sender().ID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	replies, err := b.quickReplies(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get quick replies", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	idx := slices.IndexFunc(replies, func(reply models.QuickReply) bool { return reply.ID == replyID })
+	if idx < 0 {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "comment.quick.gone"), ShowAlert: true})
+	}
+
+	// The comment is no longer typed.
+	b.stateManager.Get(ctx.Sender().ID)
+
+	_ = ctx.Respond()
+	return b.commentConfirmationHandler(ctx, taskID, replies[idx].Text)
+}
+
+// commentReplyListHandler lists the latest comments of the task to pick the one to reply to.
+func (b *Bot) commentReplyListHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("comment_reply_list").Inc()
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.Error("Invalid task ID in callback", "error", err, "data", ctx.Data())
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	details, err := b.getTaskDetails(timeoutCtx, taskID)
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	if len(details.Comments) == 0 {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "comment.reply.none"), ShowAlert: true})
+	}
+
+	// The latest comments are at the end of the list.
+	first := max(len(details.Comments)-commentReplyLimit, 0)
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(details.Comments)-first)
+	for idx := len(details.Comments) - 1; idx >= first; idx-- {
+		label := truncateRunes(details.Comments[idx], commentQuoteLength)
+		rows = append(rows, menu.Row(menu.Data(label, "comment_reply", strconv.Itoa(taskID), strconv.Itoa(idx))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.tWithData(timeoutCtx, ctx, "comment.reply.choose", map[string]interface{}{
+		"id": taskID,
+	}), menu)
+}
+
+// commentReplyHandler waits for the comment replying to the picked one, the reply quotes it.
+func (b *Bot) commentReplyHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("comment_reply").Inc()
+	rawTask, rawIndex, _ := strings.Cut(ctx.Data(), "|")
+	taskID, errTask := strconv.Atoi(rawTask)
+	idx, errIndex := strconv.Atoi(rawIndex)
+	if errTask != nil || errIndex != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid comment reply callback", "data", ctx.Data(), "user", ctx.Sender().ID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	details, err := b.getTaskDetails(timeoutCtx, taskID)
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	// The comments may have changed since the list was shown.
+	if idx < 0 || idx >= len(details.Comments) {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "comment.reply.gone"), ShowAlert: true})
+	}
+
+	quote := truncateRunes(details.Comments[idx], commentQuoteLength)
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateComment, TaskID: taskID, Category: quote})
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.tWithData(timeoutCtx, ctx, "comment.reply.prompt", map[string]interface{}{
+		"id":    taskID,
+		"quote": quote,
+	}))
+}
+
+// quoteComment prefixes the reply with the comment it answers.
+func quoteComment(quote, text string) string {
+	if quote == "" {
+		return text
+	}
+	return fmt.Sprintf("↪️ «%s»\n%s", quote, text)
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"gopkg.in/telebot.v4"
+)
+
+// operation is a class of work done by a handler, each class has its own configurable timeout.
+type operation int
+
+const (
+	opQuery  operation = iota // opQuery is a quick lookup answering a user.
+	opAdmin                   // opAdmin is a query of the admin panel or a handler running several queries.
+	opReport                  // opReport is a report, an export, a file transfer or a bulk action.
+)
+
+// defaultTimeouts are used for the operation classes the options leave unset.
+var defaultTimeouts = config.Timeouts{Query: 3 * time.Second, Admin: 5 * time.Second, Report: 30 * time.Second}
+
+// withDefaultTimeouts fills the unset timeouts with the defaults.
+func withDefaultTimeouts(timeouts config.Timeouts) config.Timeouts {
+	if timeouts.Query <= 0 {
+		timeouts.Query = defaultTimeouts.Query
+	}
+	if timeouts.Admin <= 0 {
+		timeouts.Admin = defaultTimeouts.Admin
+	}
+	if timeouts.Report <= 0 {
+		timeouts.Report = defaultTimeouts.Report
+	}
+	return timeouts
+}
+
+// timeout returns the timeout of the operation class.
+func (b *Bot) timeout(op operation) time.Duration {
+	switch op {
+	case opAdmin:
+		return b.timeouts.Admin
+	case opReport:
+		return b.timeouts.Report
+	default:
+		return b.timeouts.Query
+	}
+}
+
+// updateContext returns the context for the work of the handled update, limited by the timeout
+// of the operation class. It carries the span of the update and is canceled when the bot stops.
+func (b *Bot) updateContext(ctx telebot.Context, op operation) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(traceContext(ctx), b.timeout(op))
+}
+
+// operationContext returns the context for work outside of an update, like the role checks
+// of the menus, limited by the timeout of the operation class and canceled when the bot stops.
+func (b *Bot) operationContext(op operation) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(b.baseCtx, b.timeout(op))
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// taskCustomerHandler reveals the contacts and the agreement of the customers of the task.
+// Every access is written to the audit log, as the message carries personal data.
+func (b *Bot) taskCustomerHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("task_customer").Inc()
+	_ = ctx.Respond()
+
+	userID := ctx.Sender().ID
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid task ID in callback", "data", ctx.Data(), "user", userID)
+		return nil
+	}
+
+	customers, err := b.tarepo.GetCustomersByTaskID(timeoutCtx, int64(taskID))
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get task customers", "error", err, "task", taskID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if len(customers) == 0 {
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(b.tWithData(timeoutCtx, ctx, "task.customer.none", map[string]interface{}{"id": taskID}))
+	}
+
+	details, err := b.getTaskDetails(timeoutCtx, taskID)
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	queries := make([]agreementsLookup, 0, len(customers))
+	for _, customer := range customers {
+		queries = append(queries, agreementsQuery(customer))
+	}
+	agreements, failed, err := b.getAgreements(timeoutCtx, queries)
+	if err != nil {
+		// The customers are still shown with the data known to the database.
+		b.log.WarnContext(timeoutCtx, "Hermes is unavailable, customers are shown without agreements", "error", err)
+	}
+
+	customerIDs := make([]int64, 0, len(customers))
+	for _, customer := range customers {
+		customerIDs = append(customerIDs, customer.ID)
+	}
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditCustomerView, map[string]interface{}{
+		"task_id":      taskID,
+		"customer_ids": customerIDs,
+	})
+	b.log.InfoContext(timeoutCtx, "User viewed task customers", "user", userID, "task", taskID)
+
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	cards := make([]string, 0, len(customers))
+	for _, customer := range customers {
+		query := agreementsQuery(customer)
+		agreement := pickAgreement(agreements[query], *details)
+		_, lookupFailed := failed[query]
+		cards = append(cards, b.formatCustomerCard(lang, customer, agreement, err != nil || lookupFailed))
+	}
+
+	text := b.localizer.GetWithData(lang, "task.customer.title", map[string]interface{}{"id": taskID}) +
+		"\n\n" + strings.Join(cards, "\n\n")
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.sendLong(ctx, text, telebot.NoPreview)
+}
+
+// formatCustomerCard renders the customer with their agreement. Empty fields are left out.
+// The phone number is sent as plain text, which Telegram turns into a tap-to-call link;
+// inline buttons cannot open tel: URLs.
+func (b *Bot) formatCustomerCard(
+	lang string,
+	customer, agreement models.Customer,
+	agreementUnavailable bool,
+) string {
+	name := customer.Fullname
+	if agreement.Fullname != "" {
+		name = agreement.Fullname
+	}
+
+	lines := []string{"👤 " + name}
+	field := func(key, value string) {
+		if value != "" {
+			lines = append(lines, b.localizer.GetWithData(lang, key, map[string]interface{}{"value": value}))
+		}
+	}
+	field("task.customer.phone", agreement.Phone)
+	field("task.customer.login", customer.Login)
+	field("task.customer.contract", agreement.Contract)
+	field("task.customer.tariff", agreement.Tariff)
+	field("task.customer.balance", agreement.Balance)
+	field("task.customer.address", agreement.Address)
+
+	switch {
+	case agreementUnavailable:
+		lines = append(lines, b.localizer.Get(lang, "task.customer.agreement_unavailable"))
+	case agreement.Contract == "" && agreement.Phone == "":
+		lines = append(lines, b.localizer.Get(lang, "task.customer.agreement_missing"))
+	}
+
+	return strings.Join(lines, "\n")
+}
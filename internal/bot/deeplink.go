@@ -0,0 +1,49 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+// taskDeepLinkPrefix prefixes the task ID in /start payloads, e.g. https://t.me/<bot>?start=task_12345.
+const taskDeepLinkPrefix = "task_"
+
+// parseTaskDeepLink returns the task ID of a /start payload opening a task.
+func parseTaskDeepLink(payload string) (int, bool) {
+	rawID, ok := strings.CutPrefix(strings.TrimSpace(payload), taskDeepLinkPrefix)
+	if !ok {
+		return 0, false
+	}
+
+	taskID, err := strconv.Atoi(rawID)
+	if err != nil || taskID <= 0 {
+		return 0, false
+	}
+
+	return taskID, true
+}
+
+// sendTaskDetails sends the details of the task as a new message, with the same keyboard as
+// the details opened from the active tasks list.
+func (b *Bot) sendTaskDetails(ctx context.Context, tCtx telebot.Context, taskID int, isAdmin bool) error {
+	details, err := b.getTaskDetails(ctx, taskID)
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "deeplink.task_not_found", map[string]interface{}{"id": taskID}))
+	}
+
+	markup := b.buildTaskKeyboard(nil, taskID, false, isAdmin)
+	text := formatTaskDetails(b.format, b.getUserLanguage(ctx, tCtx), details)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	err = b.sendLong(tCtx, text, b.format.Mode(), markup)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to send task details with formatting", "error", err)
+		err = b.sendLong(tCtx, strings.ReplaceAll(text, "*", ""), markup)
+	}
+
+	return err
+}
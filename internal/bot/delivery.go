@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+// Sources of messages sent without a user request, used to label send failures.
+const (
+	sendSourceBroadcast    = "broadcast"
+	sendSourceAlert        = "alert"
+	sendSourceWeeklyReport = "weekly_report"
+	sendSourceDigest       = "digest"
+	sendSourceWebhook      = "webhook"
+	sendSourceOutbox       = "comment_outbox"
+)
+
+// sendFailureReason classifies an error returned by the Telegram API. It reports true for
+// permanent failures, after which the user cannot be reached until they write to the bot again.
+func sendFailureReason(err error) (string, bool) {
+	switch {
+	case errors.Is(err, telebot.ErrBlockedByUser), errors.Is(err, telebot.ErrNotStartedByUser):
+		return "blocked", true
+	case errors.Is(err, telebot.ErrUserIsDeactivated):
+		return "deactivated", true
+	case errors.Is(err, telebot.ErrChatNotFound):
+		return "chat_not_found", true
+	default:
+		return "other", false
+	}
+}
+
+// telegramErrorType classifies an error for the Telegram API errors metric. It reports false
+// if the error did not come from the Telegram Bot API, e.g. a failed database query.
+func telegramErrorType(err error) (string, bool) {
+	var (
+		floodErr telebot.FloodError
+		groupErr telebot.GroupError
+		apiErr   *telebot.Error
+		urlErr   *url.Error
+	)
+	switch {
+	case errors.As(err, &floodErr):
+		return "too_many_requests", true
+	case errors.As(err, &groupErr):
+		return "group_migrated", true
+	case errors.Is(err, telebot.ErrSameMessageContent), errors.Is(err, telebot.ErrMessageNotModified):
+		return "message_not_modified", true
+	case errors.Is(err, telebot.ErrQueryTooOld):
+		return "query_too_old", true
+	case errors.As(err, &urlErr):
+		return "network", true
+	case errors.As(err, &apiErr):
+		if reason, _ := sendFailureReason(err); reason != "other" {
+			return reason, true
+		}
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests:
+			return "too_many_requests", true
+		case apiErr.Code == http.StatusBadRequest:
+			return "bad_request", true
+		case apiErr.Code == http.StatusForbidden:
+			return "forbidden", true
+		case apiErr.Code >= http.StatusInternalServerError:
+			return "server_error", true
+		default:
+			return "other", true
+		}
+	case strings.Contains(err.Error(), "telegram: "):
+		// Errors telebot does not know are returned as plain "telegram: <description> (<code>)".
+		return "other", true
+	default:
+		return "", false
+	}
+}
+
+// recordTelegramError counts the error if it was returned by the Telegram Bot API.
+func (b *Bot) recordTelegramError(err error) {
+	if errType, ok := telegramErrorType(err); ok {
+		b.metrics.TelegramAPIErrors.WithLabelValues(errType).Inc()
+	}
+}
+
+// recordSendFailure counts and saves a message that could not be delivered to the user.
+// Users who permanently blocked the bot are unsubscribed from automatic reports.
+func (b *Bot) recordSendFailure(ctx context.Context, userID int64, source string, sendErr error) {
+	reason, permanent := sendFailureReason(sendErr)
+	b.metrics.SendFailures.WithLabelValues(source, reason).Inc()
+	b.recordTelegramError(sendErr)
+
+	if err := b.dlrepo.RecordSendFailure(ctx, userID, source, reason, permanent, sendErr.Error()); err != nil {
+		b.log.ErrorContext(ctx, "Failed to record send failure", "user", userID, "source", source, "error", err)
+		return
+	}
+	if permanent {
+		b.log.InfoContext(ctx, "User is unreachable, unsubscribed from automatic reports",
+			"user", userID, "reason", reason)
+	}
+}
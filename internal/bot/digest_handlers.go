@@ -0,0 +1,361 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// DigestCheckInterval is how often subscribers are checked for a due morning digest.
+	DigestCheckInterval = 5 * time.Minute
+	// digestSendWindow is how long after the digest time a missed digest is still sent,
+	// e.g. after a restart. Later, the digest is skipped until the next workday.
+	digestSendWindow = 2 * time.Hour
+	// maxDigestGroupTasks limits the tasks listed per age group.
+	maxDigestGroupTasks = 10
+	// maxDigestButtons limits the quick links to task details.
+	maxDigestButtons = 12
+	// maxDigestDescription is the number of description characters shown per task.
+	maxDigestDescription = 40
+)
+
+// digestTimezones are the time zones offered to digest subscribers.
+var digestTimezones = []string{"Europe/Kyiv", "Europe/Warsaw", "Europe/London", "UTC"}
+
+// digestAgeGroup is a group of open tasks of similar age. MaxDays is exclusive,
+// 0 means no upper bound.
+type digestAgeGroup struct {
+	Key     string
+	MaxDays int
+}
+
+// digestAgeGroups are the age groups of the digest, from the newest tasks to the oldest.
+var digestAgeGroups = []digestAgeGroup{
+	{Key: "digest.group.today", MaxDays: 1},
+	{Key: "digest.group.recent", MaxDays: 4},
+	{Key: "digest.group.week", MaxDays: 8},
+	{Key: "digest.group.old"},
+}
+
+// digestHandler shows whether the user receives the morning digest and offers
+// inline buttons to toggle it and to choose the time zone.
+func (b *Bot) digestHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("digest").Inc()
+	userID := ctx.Sender().ID
+
+	startTime := time.Now()
+	subscription, subscribed, err := b.direpo.GetDigestSubscription(timeoutCtx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("get_digest_subscription").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get digest subscription", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	text, menu := b.buildDigestMenu(timeoutCtx, ctx, subscribed, subscription.Timezone)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// digestToggleHandler subscribes or unsubscribes the user from the morning digest depending
+// on the callback data ("on" or "off"). New subscribers get the configured default time zone.
+func (b *Bot) digestToggleHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	subscribe := ctx.Data() == "on"
+
+	var err error
+	startTime := time.Now()
+	if subscribe {
+		err = b.direpo.SubscribeToDigest(timeoutCtx, userID, b.digest.Timezone)
+		b.metrics.DBQueryDuration.WithLabelValues("subscribe_to_digest").Observe(time.Since(startTime).Seconds())
+	} else {
+		err = b.direpo.UnsubscribeFromDigest(timeoutCtx, userID)
+		b.metrics.DBQueryDuration.WithLabelValues("unsubscribe_from_digest").Observe(time.Since(startTime).Seconds())
+	}
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to update digest subscription", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	b.log.InfoContext(timeoutCtx, "User changed digest subscription", "user", userID, "subscribed", subscribe)
+	_ = ctx.Respond()
+
+	text, menu := b.buildDigestMenu(timeoutCtx, ctx, subscribe, b.digest.Timezone)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// digestTimezoneHandler changes the time zone of the user's morning digest.
+func (b *Bot) digestTimezoneHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	timezone := ctx.Data()
+	if _, err := time.LoadLocation(timezone); err != nil {
+		b.log.Warn("Invalid digest time zone in callback", "user", userID, "data", timezone)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "general.use_buttons")})
+	}
+
+	startTime := time.Now()
+	err := b.direpo.SubscribeToDigest(timeoutCtx, userID, timezone)
+	b.metrics.DBQueryDuration.WithLabelValues("subscribe_to_digest").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to change digest time zone", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	b.log.InfoContext(timeoutCtx, "User changed digest time zone", "user", userID, "timezone", timezone)
+	_ = ctx.Respond()
+
+	text, menu := b.buildDigestMenu(timeoutCtx, ctx, true, timezone)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// buildDigestMenu returns the digest status text and the keyboard with the toggle button
+// and, for subscribers, the time zone choice.
+func (b *Bot) buildDigestMenu(
+	ctx context.Context,
+	tCtx telebot.Context,
+	subscribed bool,
+	timezone string,
+) (string, *telebot.ReplyMarkup) {
+	menu := &telebot.ReplyMarkup{}
+	if !subscribed {
+		menu.Inline(menu.Row(menu.Data(b.t(ctx, tCtx, "digest.button.enable"), "digest_toggle", "on")))
+		return b.tWithData(ctx, tCtx, "digest.status.disabled", map[string]interface{}{
+			"time": fmt.Sprintf("%02d:%02d", b.digest.Time.Hour, b.digest.Time.Minute),
+		}), menu
+	}
+
+	rows := make([]telebot.Row, 0, len(digestTimezones)+1)
+	for _, zone := range digestTimezones {
+		text := zone
+		if zone == timezone {
+			text = "✅ " + zone
+		}
+		rows = append(rows, menu.Row(menu.Data(text, "digest_timezone", zone)))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "digest.button.disable"), "digest_toggle", "off")))
+	menu.Inline(rows...)
+
+	return b.tWithData(ctx, tCtx, "digest.status.enabled", map[string]interface{}{
+		"time":     fmt.Sprintf("%02d:%02d", b.digest.Time.Hour, b.digest.Time.Minute),
+		"timezone": timezone,
+	}), menu
+}
+
+// SendDailyDigests sends the morning digest to every subscriber whose digest is due.
+// It runs every DigestCheckInterval, so each subscriber gets the digest at the digest time
+// of their own time zone. Failures for a single user are logged and do not stop the delivery
+// to the others.
+func (b *Bot) SendDailyDigests(ctx context.Context) error {
+	subscriptions, err := b.direpo.GetDigestSubscribers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get digest subscribers: %w", err)
+	}
+
+	now := time.Now()
+	sent, failed := 0, 0
+	for _, subscription := range subscriptions {
+		if ctx.Err() != nil {
+			return fmt.Errorf("digest delivery interrupted: %w", ctx.Err())
+		}
+
+		day, due := b.digestDue(subscription, now)
+		if !due {
+			continue
+		}
+
+		if err = b.sendDigest(ctx, subscription.TelegramID, day); err != nil {
+			b.log.WarnContext(ctx, "Failed to send digest", "user", subscription.TelegramID, "error", err)
+			failed++
+		} else {
+			sent++
+		}
+		// The digest is not retried on the same day, even if it failed.
+		if err = b.direpo.MarkDigestSent(ctx, subscription.TelegramID, day); err != nil {
+			b.log.ErrorContext(ctx, "Failed to mark digest as sent", "user", subscription.TelegramID, "error", err)
+		}
+
+		// Wait a bit between messages to avoid Telegram's rate limits
+		const telegramRateTimeout = 100 * time.Millisecond
+		time.Sleep(telegramRateTimeout)
+	}
+
+	if sent+failed > 0 {
+		b.log.InfoContext(ctx, "Daily digests sent", "success", sent, "failed", failed)
+	}
+	return nil
+}
+
+// digestDue reports whether the subscriber should get the digest at now and returns
+// the subscriber's local time. The digest is due on workdays, within digestSendWindow
+// after the digest time, if it has not been sent on that local date yet.
+func (b *Bot) digestDue(subscription models.DigestSubscription, now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(subscription.Timezone)
+	if err != nil {
+		b.log.Warn("Invalid digest time zone, using the default one",
+			"user", subscription.TelegramID, "timezone", subscription.Timezone)
+		loc, _ = time.LoadLocation(b.digest.Timezone)
+	}
+	local := now.In(loc)
+
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return local, false
+	}
+
+	scheduled := time.Date(
+		local.Year(), local.Month(), local.Day(), b.digest.Time.Hour, b.digest.Time.Minute, 0, 0, loc,
+	)
+	if local.Before(scheduled) || local.Sub(scheduled) > digestSendWindow {
+		return local, false
+	}
+
+	last := subscription.LastSentOn
+	if last.Year() == local.Year() && last.YearDay() == local.YearDay() {
+		return local, false
+	}
+
+	return local, true
+}
+
+// sendDigest sends the summary of the user's open tasks grouped by age, with quick links
+// to the details of the oldest tasks. During the user's quiet hours, the digest is postponed.
+func (b *Bot) sendDigest(ctx context.Context, userID int64, now time.Time) error {
+	lang := b.languageByID(ctx, userID)
+	recipient := telebot.ChatID(userID)
+
+	startTime := time.Now()
+	tasks, err := b.direpo.GetDigestTasks(ctx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("get_digest_tasks").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to get digest tasks: %w", err)
+	}
+
+	quietHours := b.userQuietHours(ctx, userID)
+
+	if len(tasks) == 0 {
+		text := b.localizer.Get(lang, "digest.no_tasks")
+		if b.deferIfQuiet(ctx, quietHours, sendSourceDigest, deferredMessage{
+			Message: broadcastMessage{Kind: broadcastText, Text: text},
+		}) {
+			return nil
+		}
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		_, err = b.bot.Send(recipient, text)
+		return b.wrapDigestSendError(ctx, userID, err)
+	}
+
+	text, menu := b.buildDigest(lang, tasks, now)
+	if b.deferIfQuiet(ctx, quietHours, sendSourceDigest, deferredMessage{
+		Message: broadcastMessage{Kind: broadcastText, Text: text},
+		Markup:  menu,
+	}) {
+		return nil
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	_, err = b.bot.Send(recipient, text, menu)
+	return b.wrapDigestSendError(ctx, userID, err)
+}
+
+// buildDigest renders the digest of tasks, which are ordered from the oldest to the newest.
+func (b *Bot) buildDigest(lang string, tasks []models.DigestTask, now time.Time) (string, *telebot.ReplyMarkup) {
+	groups := make([][]models.DigestTask, len(digestAgeGroups))
+	for _, task := range tasks {
+		idx := digestAgeGroupIndex(now.Sub(task.CreationDate))
+		groups[idx] = append(groups[idx], task)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.localizer.GetWithData(lang, "digest.header", map[string]interface{}{
+		"count": b.localizer.GetPlural(lang, "digest.tasks", len(tasks)),
+	}))
+
+	// The oldest tasks need attention first.
+	for idx := len(digestAgeGroups) - 1; idx >= 0; idx-- {
+		group := groups[idx]
+		if len(group) == 0 {
+			continue
+		}
+		builder.WriteString("\n\n" + b.localizer.GetWithData(lang, digestAgeGroups[idx].Key, map[string]interface{}{
+			"count": len(group),
+		}))
+		for taskIdx, task := range group {
+			if taskIdx >= maxDigestGroupTasks {
+				builder.WriteString("\n" + b.localizer.GetWithData(lang, "digest.more", map[string]interface{}{
+					"count": len(group) - maxDigestGroupTasks,
+				}))
+				break
+			}
+			builder.WriteString(fmt.Sprintf("\n#%d %s", task.ID, truncateRunes(task.Description, maxDigestDescription)))
+		}
+	}
+
+	var rows [][]telebot.InlineButton
+	buttons := make([]telebot.InlineButton, 0, 3)
+	links := tasks[:min(len(tasks), maxDigestButtons)]
+	for idx, task := range links {
+		buttons = append(buttons, telebot.InlineButton{
+			Unique: "task_details",
+			Text:   fmt.Sprintf("#%d", task.ID),
+			Data:   strconv.Itoa(task.ID),
+		})
+		if (idx+1)%3 == 0 || idx == len(links)-1 {
+			rows = append(rows, buttons)
+			buttons = nil
+		}
+	}
+
+	return builder.String(), &telebot.ReplyMarkup{InlineKeyboard: rows}
+}
+
+// digestAgeGroupIndex returns the index of the age group for a task of the given age.
+func digestAgeGroupIndex(age time.Duration) int {
+	days := int(age.Hours() / 24)
+	for idx, group := range digestAgeGroups {
+		if group.MaxDays == 0 || days < group.MaxDays {
+			return idx
+		}
+	}
+	return len(digestAgeGroups) - 1
+}
+
+// truncateRunes shortens the text to at most limit characters, adding an ellipsis.
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+// wrapDigestSendError records and annotates an error returned by the Telegram API.
+func (b *Bot) wrapDigestSendError(ctx context.Context, userID int64, err error) error {
+	if err != nil {
+		b.recordSendFailure(ctx, userID, sendSourceDigest, err)
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/telebot.v4"
+)
+
+// editHashTTL is how long the hashes of an edited message are kept.
+// Telegram lets bots edit their messages for 48 hours.
+const editHashTTL = 48 * time.Hour
+
+// editIfChanged edits the message unless it already shows the text with the same options, which
+// Telegram would reject as "message is not modified". Redis keeps the hash of the content of the
+// last edit together with the hash of the message Telegram returned for it; the edit is skipped
+// only when both match, so a message changed by another handler since is still edited.
+// Without Redis the message is always edited. After the edit msg holds the edited message,
+// so a message kept between updates, like the live nearby task list, can be checked again.
+func (b *Bot) editIfChanged(ctx context.Context, msg *telebot.Message, text string, opts ...interface{}) error {
+	key := fmt.Sprintf("oracle:edit_hash:%d:%d", msg.Chat.ID, msg.ID)
+	content := contentHash(text, opts)
+
+	last, err := b.redisClient.Get(ctx, key).Result()
+	switch {
+	case err == nil && last == content+":"+messageHash(msg):
+		b.log.DebugContext(ctx, "Skipping edit of unchanged message", "chat", msg.Chat.ID, "message", msg.ID)
+		return nil
+	case err != nil && !errors.Is(err, redis.Nil):
+		b.log.WarnContext(ctx, "Failed to get hash of edited message", "error", err)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	edited, err := b.bot.Edit(msg, text, opts...)
+	switch {
+	case errors.Is(err, telebot.ErrSameMessageContent):
+		// The message already shows the content, it only was not recorded.
+	case err != nil:
+		return err
+	case edited != nil:
+		*msg = *edited
+	}
+
+	if err = b.redisClient.Set(ctx, key, content+":"+messageHash(msg), editHashTTL).Err(); err != nil {
+		b.log.WarnContext(ctx, "Failed to save hash of edited message", "error", err)
+	}
+	return nil
+}
+
+// contentHash returns the hash of the text and the send options of an edit: the parse mode,
+// the inline keyboard and the other options.
+func contentHash(text string, opts []interface{}) string {
+	parts := []string{text}
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case *telebot.ReplyMarkup:
+			parts = append(parts, keyboardJSON(opt))
+		default:
+			parts = append(parts, fmt.Sprint(opt))
+		}
+	}
+
+	return hashParts(parts)
+}
+
+// messageHash returns the hash of the message as Telegram shows it, an empty string for no message.
+func messageHash(msg *telebot.Message) string {
+	if msg == nil {
+		return ""
+	}
+
+	return hashParts([]string{msg.Text, msg.Caption, keyboardJSON(msg.ReplyMarkup)})
+}
+
+// keyboardJSON returns the inline keyboard of the markup as JSON.
+func keyboardJSON(markup *telebot.ReplyMarkup) string {
+	if markup == nil {
+		return ""
+	}
+
+	keyboard, _ := json.Marshal(markup.InlineKeyboard)
+	return string(keyboard)
+}
+
+// hashParts returns the hex encoded SHA-256 hash of the parts.
+func hashParts(parts []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,41 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// employeeCacheTTL is how long employee and admin lookups are kept in the memory of the process.
+// It is short, so a changed role or a new admin is picked up quickly even without invalidation.
+const employeeCacheTTL = 30 * time.Second
+
+// adminsKey is the key of the list of admins, the only entry of its cache.
+const adminsKey = "admins"
+
+// cachedEmployee returns the employee linked to the Telegram ID, cached for a short time.
+func (b *Bot) cachedEmployee(ctx context.Context, telegramID int64) (models.Employee, error) {
+	return b.employees.Get(ctx, telegramID, func(ctx context.Context) (models.Employee, error) {
+		return b.tarepo.GetEmployee(ctx, telegramID)
+	})
+}
+
+// cachedAdmins returns the admins of the bot, cached for a short time.
+func (b *Bot) cachedAdmins(ctx context.Context) ([]models.BotUser, error) {
+	return b.admins.Get(ctx, adminsKey, b.usrepo.GetAdmins)
+}
+
+// cachedIsAdmin reports whether the user is an admin, cached for a short time.
+func (b *Bot) cachedIsAdmin(ctx context.Context, telegramID int64) (bool, error) {
+	return b.adminFlags.Get(ctx, telegramID, func(ctx context.Context) (bool, error) {
+		return b.usrepo.IsAdmin(ctx, telegramID)
+	})
+}
+
+// forgetUser drops the cached lookups of the user after the account is linked or unlinked.
+func (b *Bot) forgetUser(telegramID int64) {
+	b.employees.Invalidate(telegramID)
+	b.adminFlags.Invalidate(telegramID)
+	b.admins.Invalidate(adminsKey)
+}
@@ -0,0 +1,207 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+// Limits of the read-only task list of an employee, to keep it within a single message.
+const (
+	employeeViewTaskLimit   = 20
+	employeeViewDescription = 60
+)
+
+// employeeViewHandler starts browsing the tasks of an employee, asking the admin for their short name.
+func (b *Bot) employeeViewHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("employee_view").Inc()
+	b.log.Info("Admin requested employee view", "user", userID)
+
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmployeeView})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.employee_view.prompt"))
+}
+
+// employeeViewSearchHandler lists the employees whose short name contains the query entered by the admin.
+func (b *Bot) employeeViewSearchHandler(ctx context.Context, tCtx telebot.Context, userID int64, query string) error {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minTeammateQuery {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmployeeView})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "handover.query_short", map[string]interface{}{
+			"min": minTeammateQuery,
+		}))
+	}
+
+	employees, err := b.prrepo.SearchEmployees(ctx, query, teammateSearchLimit)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to search employees", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	if len(employees) == 0 {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmployeeView})
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.profiles.no_employees", map[string]interface{}{
+			"query": query,
+		}))
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(employees))
+	for _, employee := range employees {
+		label := employee.ShortName + " — " + employee.FullName
+		rows = append(rows, menu.Row(menu.Data(label, "employee_view", strconv.Itoa(employee.EmployeeID))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "admin.employee_view.pick"), menu)
+}
+
+// employeeTasksHandler shows the active tasks of the employee in the callback data.
+func (b *Bot) employeeTasksHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	employeeID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid employee ID in callback", "data", ctx.Data())
+		return nil
+	}
+
+	employee, err := b.tarepo.GetEmployeeByID(timeoutCtx, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get employee", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	tasks, err := b.tarepo.GetActiveTasksByEmployee(timeoutCtx, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get active tasks", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	text := b.employeeTasksText(timeoutCtx, ctx, employee, tasks)
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, b.employeeViewMenu(timeoutCtx, ctx, employeeID))
+}
+
+// employeeTasksText lists the active tasks with their age, marking those past their SLA.
+func (b *Bot) employeeTasksText(
+	ctx context.Context,
+	tCtx telebot.Context,
+	employee models.Employee,
+	tasks []models.ActiveTask,
+) string {
+	if len(tasks) == 0 {
+		return b.tWithData(ctx, tCtx, "admin.employee_view.no_tasks", map[string]interface{}{
+			"name": employee.ShortName,
+		})
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(ctx, tCtx, "admin.employee_view.tasks", map[string]interface{}{
+		"name":  employee.ShortName,
+		"count": len(tasks),
+	}))
+	builder.WriteString("\n\n")
+
+	lang := b.getUserLanguage(ctx, tCtx)
+	now := time.Now()
+	for _, task := range tasks[:min(len(tasks), employeeViewTaskLimit)] {
+		fmt.Fprintf(&builder, "#%d · %s", task.ID, b.taskAge(lang, now.Sub(task.CreationDate)))
+		if task.SLABreached(now) {
+			builder.WriteString(" ⚠️")
+		}
+		builder.WriteString(" — " + truncateRunes(task.Description, employeeViewDescription) + "\n")
+	}
+	if len(tasks) > employeeViewTaskLimit {
+		builder.WriteString(b.tWithData(ctx, tCtx, "admin.employee_view.more", map[string]interface{}{
+			"count": len(tasks) - employeeViewTaskLimit,
+		}))
+	}
+
+	return builder.String()
+}
+
+// employeeStatsHandler shows the completed tasks of the employee for the period, both in the
+// callback data "employee|period".
+func (b *Bot) employeeStatsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	rawEmployee, period, _ := strings.Cut(ctx.Data(), "|")
+	employeeID, err := strconv.Atoi(rawEmployee)
+	from, to, ok := teamStatsPeriod(period, time.Now())
+	if err != nil || !ok {
+		b.log.WarnContext(timeoutCtx, "Invalid employee stats callback", "data", ctx.Data())
+		return nil
+	}
+
+	employee, err := b.tarepo.GetEmployeeByID(timeoutCtx, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get employee", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	summaries, err := b.tarepo.GetTaskSummaryByEmployee(timeoutCtx, employeeID, from, to)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get task summary", "error", err, "employee", employeeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(timeoutCtx, ctx, "admin.employee_view.stats", map[string]interface{}{
+		"name": employee.ShortName,
+		"from": b.formatDate(timeoutCtx, ctx, from),
+		"to":   b.formatDate(timeoutCtx, ctx, to),
+	}))
+	builder.WriteString("\n\n")
+	for _, summary := range summaries {
+		key := "statistic.item"
+		if summary.Type == "Total" {
+			key = "statistic.total"
+			builder.WriteString("\n")
+		}
+		builder.WriteString(b.tWithData(timeoutCtx, ctx, key, map[string]interface{}{
+			"type":  summary.Type,
+			"count": b.tPlural(timeoutCtx, ctx, "statistic.tasks", summary.Count),
+		}))
+		builder.WriteString("\n")
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return b.editLong(ctx, builder.String(), b.employeeViewMenu(timeoutCtx, ctx, employeeID))
+}
+
+// employeeViewMenu returns the inline keyboard switching between the active tasks of the employee
+// and their statistics for the team statistics periods.
+func (b *Bot) employeeViewMenu(ctx context.Context, tCtx telebot.Context, employeeID int) *telebot.ReplyMarkup {
+	employee := strconv.Itoa(employeeID)
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(
+		menu.Row(menu.Data(b.t(ctx, tCtx, "admin.employee_view.active"), "employee_view", employee)),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "admin.bulk.button.select"), "bulk_start", employee)),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.last_7_days"), "employee_stats", employee, "week")),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.current_month"), "employee_stats", employee, "month")),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "report.period.last_month"), "employee_stats", employee, "last_month")),
+	)
+	return menu
+}
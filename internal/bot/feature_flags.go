@@ -0,0 +1,247 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// Feature flags checked by the bot.
+const (
+	flagPDFReports = "pdf_reports"
+	flagOnboarding = "onboarding"
+)
+
+// featureFlagDefinitions declares the flags admins can roll out, in the order they are listed.
+var featureFlagDefinitions = []featureflags.Definition{
+	{Name: flagPDFReports, Default: featureflags.Flag{Enabled: true, Percentage: 100}},
+	{Name: flagOnboarding, Default: featureflags.Flag{Enabled: true, Percentage: 100}},
+}
+
+// featureFlagsTTL is how long the flags are cached, other replicas see a change after it.
+const featureFlagsTTL = 30 * time.Second
+
+// flagPercentages are the rollout percentages offered to admins.
+var flagPercentages = []int{0, 10, 25, 50, 100}
+
+// featureEnabled reports whether the feature flag is on for the user.
+func (b *Bot) featureEnabled(ctx context.Context, userID int64, name string) bool {
+	role := featureflags.RoleEmployee
+	if b.IsAdminCheck(userID) {
+		role = featureflags.RoleAdmin
+	}
+	return b.flags.Enabled(ctx, name, featureflags.User{ID: userID, Role: role})
+}
+
+// featureFlagsHandler lists the feature flags, tapping a flag shows its rollout.
+func (b *Bot) featureFlagsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("feature_flags").Inc()
+	b.log.Info("Admin requested feature flags", "user", ctx.Sender().ID)
+
+	flags, err := b.flags.List(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to list feature flags", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.flags.title"), b.featureFlagListMarkup(flags))
+}
+
+// featureFlagListHandler returns from a flag to the list of flags.
+func (b *Bot) featureFlagListHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	flags, err := b.flags.List(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to list feature flags", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "admin.flags.title"), b.featureFlagListMarkup(flags))
+}
+
+// featureFlagViewHandler shows the rollout of the flag in the callback data.
+func (b *Bot) featureFlagViewHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	flag, err := b.flags.Get(timeoutCtx, ctx.Data())
+	if err != nil {
+		return b.featureFlagError(timeoutCtx, ctx, err)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.featureFlagText(timeoutCtx, ctx, flag), b.featureFlagMarkup(timeoutCtx, ctx, flag))
+}
+
+// featureFlagSetHandler changes one field of the flag. The callback data is "name|field|value",
+// where the field is "enabled", "percentage" or "role", and a role is toggled.
+func (b *Bot) featureFlagSetHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	parts := strings.Split(ctx.Data(), "|")
+	if len(parts) != 3 {
+		b.log.WarnContext(timeoutCtx, "Invalid feature flag callback", "data", ctx.Data(), "user", userID)
+		_ = ctx.Respond()
+		return nil
+	}
+	name, field, value := parts[0], parts[1], parts[2]
+
+	flag, err := b.flags.Get(timeoutCtx, name)
+	if err != nil {
+		_ = ctx.Respond()
+		return b.featureFlagError(timeoutCtx, ctx, err)
+	}
+
+	switch field {
+	case "enabled":
+		flag.Enabled = value == "1"
+	case "percentage":
+		flag.Percentage, err = strconv.Atoi(value)
+	case "role":
+		role := featureflags.Role(value)
+		if i := slices.Index(flag.Roles, role); i >= 0 {
+			flag.Roles = slices.Delete(slices.Clone(flag.Roles), i, i+1)
+		} else {
+			flag.Roles = append(slices.Clone(flag.Roles), role)
+		}
+	default:
+		err = errors.New("unknown field")
+	}
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid feature flag callback", "data", ctx.Data(), "user", userID)
+		_ = ctx.Respond()
+		return nil
+	}
+
+	if err = b.flags.Set(timeoutCtx, flag, userID); err != nil {
+		_ = ctx.Respond()
+		return b.featureFlagError(timeoutCtx, ctx, err)
+	}
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditFeatureFlag, flag)
+	b.log.InfoContext(timeoutCtx, "Feature flag updated", "admin", userID, "flag", flag.Name,
+		"enabled", flag.Enabled, "percentage", flag.Percentage, "roles", flag.Roles)
+
+	b.metrics.SentMessages.WithLabelValues("respond").Inc()
+	_ = ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "admin.flags.saved")})
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.featureFlagText(timeoutCtx, ctx, flag), b.featureFlagMarkup(timeoutCtx, ctx, flag))
+}
+
+// featureFlagError tells the admin that the flag could not be loaded or saved.
+func (b *Bot) featureFlagError(ctx context.Context, tCtx telebot.Context, err error) error {
+	if errors.Is(err, featureflags.ErrUnknownFlag) {
+		b.log.WarnContext(ctx, "Unknown feature flag in callback", "data", tCtx.Data())
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "admin.flags.unknown"))
+	}
+
+	b.log.ErrorContext(ctx, "Failed to update feature flag", "error", err, "data", tCtx.Data())
+	b.metrics.SentMessages.WithLabelValues("error").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+}
+
+// featureFlagListMarkup builds the keyboard with a button per flag, showing whether it is on.
+func (b *Bot) featureFlagListMarkup(flags []featureflags.Flag) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(flags))
+	for _, flag := range flags {
+		label := "⚪️ " + flag.Name
+		if flag.Enabled {
+			label = "🟢 " + flag.Name + " · " + strconv.Itoa(flag.Percentage) + "%"
+		}
+		rows = append(rows, menu.Row(menu.Data(label, "flag_view", flag.Name)))
+	}
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// featureFlagText describes the flag and its rollout.
+func (b *Bot) featureFlagText(ctx context.Context, tCtx telebot.Context, flag featureflags.Flag) string {
+	status := b.t(ctx, tCtx, "admin.flags.status_off")
+	if flag.Enabled {
+		status = b.t(ctx, tCtx, "admin.flags.status_on")
+	}
+
+	roles := make([]string, 0, len(flag.Roles))
+	for _, role := range flag.Roles {
+		roles = append(roles, b.t(ctx, tCtx, "admin.flags.role."+string(role)))
+	}
+	if len(roles) == 0 {
+		roles = append(roles, b.t(ctx, tCtx, "admin.flags.roles_none"))
+	}
+
+	return b.tWithData(ctx, tCtx, "admin.flags.detail", map[string]interface{}{
+		"name":        flag.Name,
+		"description": b.t(ctx, tCtx, "admin.flags.description."+flag.Name),
+		"status":      status,
+		"percentage":  flag.Percentage,
+		"roles":       strings.Join(roles, ", "),
+	})
+}
+
+// featureFlagMarkup builds the keyboard changing the flag: the switch, the rollout percentages,
+// the roles the flag is always on for, and the way back to the list.
+func (b *Bot) featureFlagMarkup(
+	ctx context.Context,
+	tCtx telebot.Context,
+	flag featureflags.Flag,
+) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+
+	toggle := menu.Data(b.t(ctx, tCtx, "admin.flags.enable"), "flag_set", flag.Name, "enabled", "1")
+	if flag.Enabled {
+		toggle = menu.Data(b.t(ctx, tCtx, "admin.flags.disable"), "flag_set", flag.Name, "enabled", "0")
+	}
+
+	percentages := make([]telebot.Btn, 0, len(flagPercentages))
+	for _, percentage := range flagPercentages {
+		label := strconv.Itoa(percentage) + "%"
+		if percentage == flag.Percentage {
+			label = "• " + label
+		}
+		data := []string{flag.Name, "percentage", strconv.Itoa(percentage)}
+		percentages = append(percentages, menu.Data(label, "flag_set", data...))
+	}
+
+	roles := make([]telebot.Btn, 0, len(featureflags.Roles))
+	for _, role := range featureflags.Roles {
+		label := "▫️ " + b.t(ctx, tCtx, "admin.flags.role."+string(role))
+		if slices.Contains(flag.Roles, role) {
+			label = "✅ " + b.t(ctx, tCtx, "admin.flags.role."+string(role))
+		}
+		roles = append(roles, menu.Data(label, "flag_set", flag.Name, "role", string(role)))
+	}
+
+	menu.Inline(
+		menu.Row(toggle),
+		menu.Row(percentages...),
+		menu.Row(roles...),
+		menu.Row(menu.Data(b.t(ctx, tCtx, "admin.flags.back"), "flag_list")),
+	)
+
+	return menu
+}
@@ -1,16 +1,12 @@
 package bot
 
 import (
-	"context"
-	"errors"
 	"fmt"
-	"strconv"
+	"strings"
 	"time"
 
-	"github.com/UnknownOlympus/oracle/internal/repository"
 	"github.com/google/uuid"
 	"gopkg.in/telebot.v4"
-	"gopkg.in/telebot.v4/react"
 )
 
 // var userStates = make(map[int64]string)
@@ -19,36 +15,93 @@ const (
 	// stateAwaitingEmail indicates that the bot is waiting for the user's email input.
 	stateAwaitingEmail = "email"
 
+	// stateAwaitingCode indicates that the bot is waiting for the emailed login verification code.
+	stateAwaitingCode = "code"
+
 	// stateAwaitingLocation indicates that the bot is waiting fot the user's location input.
 	stateAwaitingLocation = "location"
 
+	// stateAwaitingTaskLocation indicates that the bot is waiting for the location pin of a task
+	// an admin fixes after its address failed to geocode.
+	stateAwaitingTaskLocation = "task_location"
+
+	// stateAwaitingStatRange indicates that the bot is waiting for the date range of the statistics.
+	stateAwaitingStatRange = "stat_range"
+
 	// stateComment indicates that the bot is waiting fot the user's text comment input.
 	stateComment = "comment"
 
+	// stateAwaitingOnCall indicates that the bot is waiting for the weeks of the on-call rota, typed
+	// or uploaded as a CSV file.
+	stateAwaitingOnCall = "oncall"
+
+	// stateAwaitingMaterialQuantity indicates that the bot is waiting for the quantity of the material
+	// used on a task.
+	stateAwaitingMaterialQuantity = "material_quantity"
+
+	// stateAwaitingShiftLocation indicates that the bot is waiting for the location of the shift the
+	// user just started or ended.
+	stateAwaitingShiftLocation = "shift_location"
+
 	// stateComment indicates that the bot is waiting fot the user's text broadcast input.
 	stateAwaitingBroadcast = "broadcast"
 
+	// stateAwaitingTemplate indicates that the bot is waiting for the name and the text of a new
+	// broadcast template.
+	stateAwaitingTemplate = "template"
+
+	// stateAwaitingSLA indicates that the bot is waiting for the SLA of a task type in hours.
+	stateAwaitingSLA = "sla"
+
+	// stateAwaitingFeedback indicates that the bot is waiting for the description of a bug or an idea.
+	stateAwaitingFeedback = "feedback"
+
+	// stateAwaitingFeedbackScreenshot indicates that the bot is waiting for the screenshot of the feedback.
+	stateAwaitingFeedbackScreenshot = "feedback_screenshot"
+
+	// stateAwaitingProfileLead indicates that the bot is waiting for a part of the short name of the
+	// team lead whose profiles an admin manages.
+	stateAwaitingProfileLead = "profile_lead"
+
+	// stateAwaitingProfileEmployee indicates that the bot is waiting for a part of the short name of
+	// the employee an admin grants to a team lead.
+	stateAwaitingProfileEmployee = "profile_employee"
+
+	// stateAwaitingEmployeeView indicates that the bot is waiting for a part of the short name of
+	// the employee whose tasks an admin wants to browse.
+	stateAwaitingEmployeeView = "employee_view"
+
+	// stateAwaitingBulkComment indicates that the bot is waiting for the comment a dispatcher adds
+	// to the selected tasks.
+	stateAwaitingBulkComment = "bulk_comment"
+
 	// ErrInternal is the error message returned when there is an internal server error.
 	ErrInternal = "🚫 Internal server error, please try again later"
 )
 
-// startHandler process command /start.
+// startHandler process command /start. A deep link payload opening a task, e.g. /start task_12345,
+// shows the task details after the welcome message, a QR login token links the account instead.
 func (b *Bot) startHandler(ctx telebot.Context) error {
 	var responseText string
 	var selectedMenu *telebot.ReplyMarkup
+	var isAdmin bool
 	userID := ctx.Sender().ID
 	metricLabel := "text"
 
 	b.log.Info("User started the bot", "id", userID, "username", ctx.Sender().Username)
 	b.metrics.CommandReceived.WithLabelValues("start").Inc()
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	startTime := time.Now()
 	isAuth, err := b.usrepo.IsUserAuthenticated(timeoutCtx, userID)
 	b.metrics.DBQueryDuration.WithLabelValues("is_user_authenticated").Observe(time.Since(startTime).Seconds())
 
+	if token, ok := strings.CutPrefix(ctx.Message().Payload, loginDeepLinkPrefix); ok && err == nil && !isAuth {
+		return b.qrLoginHandler(timeoutCtx, ctx, userID, token)
+	}
+
 	switch {
 	case err != nil:
 		responseText = b.t(timeoutCtx, ctx, "error.internal")
@@ -56,7 +109,8 @@ func (b *Bot) startHandler(ctx telebot.Context) error {
 		metricLabel = "error"
 	case isAuth:
 		responseText = b.t(timeoutCtx, ctx, "welcome.authenticated")
-		isAdmin, adminErr := b.usrepo.IsAdmin(timeoutCtx, userID)
+		var adminErr error
+		isAdmin, adminErr = b.usrepo.IsAdmin(timeoutCtx, userID)
 		if adminErr != nil {
 			b.log.ErrorContext(timeoutCtx, "Failed to check admin status", "error", adminErr)
 			responseText = b.t(timeoutCtx, ctx, "error.internal")
@@ -72,8 +126,21 @@ func (b *Bot) startHandler(ctx telebot.Context) error {
 	}
 
 	b.metrics.SentMessages.WithLabelValues(metricLabel).Inc()
+	if err = ctx.Send(responseText, selectedMenu); err != nil {
+		return err
+	}
 
-	return ctx.Send(responseText, selectedMenu)
+	taskID, ok := parseTaskDeepLink(ctx.Message().Payload)
+	switch {
+	case !ok || metricLabel == "error":
+		return nil
+	case !isAuth:
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "deeplink.login_required"))
+	default:
+		b.metrics.CommandReceived.WithLabelValues("start_task").Inc()
+		return b.sendTaskDetails(timeoutCtx, ctx, taskID, isAdmin)
+	}
 }
 
 // authHandler handles the authentication process for the bot.
@@ -81,7 +148,7 @@ func (b *Bot) startHandler(ctx telebot.Context) error {
 // verification in the US system. The user's state is updated to indicate
 // that the bot is awaiting the email input.
 func (b *Bot) authHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingEmail})
@@ -93,7 +160,7 @@ func (b *Bot) authHandler(ctx telebot.Context) error {
 // routeTextHandler routes text messages to appropriate handlers based on button text or state.
 func (b *Bot) routeTextHandler(ctx telebot.Context) error {
 	text := ctx.Text()
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	// Special case: Login button (for unauthenticated users)
@@ -124,41 +191,18 @@ func (b *Bot) routeTextHandler(ctx telebot.Context) error {
 	return b.textHandler(ctx)
 }
 
-// callHandler maps handler names to actual handler functions.
+// callHandler runs the menu button handler registered under the name in the router.
 func (b *Bot) callHandler(handlerName string, ctx telebot.Context) error {
-	switch handlerName {
-	case "info":
-		return b.infoHandler(ctx)
-	case "active_tasks":
-		return b.activeTasksHandler(ctx)
-	case "near_tasks":
-		return b.nearTasksHandler(ctx)
-	case "statistic_today":
-		return b.statisticHandlerToday(ctx)
-	case "statistic_month":
-		return b.statisticHandlerMonth(ctx)
-	case "statistic_year":
-		return b.statisticHandlerYear(ctx)
-	case "report":
-		return b.reportHandler(ctx)
-	case "language":
-		return b.languageHandler(ctx)
-	case "report_issue":
-		return b.reportIssueHandler(ctx)
-	case "logout":
-		return b.logoutHandler(ctx)
-	case "broadcast_initiate":
-		return b.broadcastInitiateHandler(ctx)
-	case "geocoding_issues":
-		return b.geocodingIssuesHandler(ctx)
-	case "geocoding_reset":
-		return b.geocodingResetHandler(ctx)
-	default:
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-		b.log.Warn("Unknown handler requested", "handler", handlerName)
-		return ctx.Send(b.t(timeoutCtx, ctx, "general.use_buttons"))
+	ctx.Set(handlerNameKey, handlerName)
+
+	if handler, ok := b.router.Named(handlerName); ok {
+		return handler(ctx)
 	}
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+	b.log.Warn("Unknown handler requested", "handler", handlerName)
+	return ctx.Send(b.t(timeoutCtx, ctx, "general.use_buttons"))
 }
 
 // textHandler processes incoming text messages from users. It checks the user's state,
@@ -169,28 +213,60 @@ func (b *Bot) textHandler(ctx telebot.Context) error {
 	userID := ctx.Sender().ID
 	state, ok := b.stateManager.Get(userID)
 	if !ok {
-		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 		defer cancel()
 		b.metrics.SentMessages.WithLabelValues("reply").Inc()
 		return ctx.Reply(b.t(timeoutCtx, ctx, "general.use_buttons"))
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
+	ctx.Set(handlerNameKey, "input_"+state.WaitingFor)
 	switch state.WaitingFor {
 	case stateAwaitingEmail:
 		email := ctx.Text()
 		b.log.Debug("User is trying to authenticate", "user", userID, "email", email)
 		return b.loginInputHandler(timeoutCtx, ctx, userID, email)
+	case stateAwaitingCode:
+		b.log.Debug("User is entering the login verification code", "user", userID)
+		return b.loginCodeHandler(timeoutCtx, ctx, userID, ctx.Text())
+	case stateAwaitingStatRange:
+		return b.statisticRangeInputHandler(timeoutCtx, ctx, userID, ctx.Text())
 	case stateComment:
 		comment := ctx.Text()
 		b.log.Debug("User is trying to add comment", "user", userID, "comment_length", len(comment))
-		return b.commentConfirmationHandler(ctx, state.TaskID, comment)
+		return b.commentConfirmationHandler(ctx, state.TaskID, quoteComment(state.Category, comment))
+	case stateAwaitingOnCall:
+		return b.onCallInputHandler(timeoutCtx, ctx, userID, ctx.Text())
+	case stateAwaitingMaterialQuantity:
+		return b.materialQuantityInputHandler(timeoutCtx, ctx, userID, state, ctx.Text())
+	case stateAwaitingSLA:
+		return b.slaInputHandler(timeoutCtx, ctx, userID, state.TypeID, ctx.Text())
+	case stateAwaitingProfileLead:
+		return b.profileLeadSearchHandler(timeoutCtx, ctx, userID, ctx.Text())
+	case stateAwaitingProfileEmployee:
+		return b.profileEmployeeSearchHandler(timeoutCtx, ctx, userID, state.TargetID, ctx.Text())
+	case stateAwaitingEmployeeView:
+		return b.employeeViewSearchHandler(timeoutCtx, ctx, userID, ctx.Text())
+	case stateAwaitingBulkComment:
+		return b.bulkCommentInputHandler(timeoutCtx, ctx, userID, ctx.Text())
+	case stateAwaitingFeedback:
+		return b.feedbackDescriptionHandler(timeoutCtx, ctx, userID, state.Category, ctx.Text())
+	case stateAwaitingFeedbackScreenshot:
+		// Text instead of the screenshot keeps the draft, the buttons still send or cancel it.
+		b.stateManager.Set(userID, state)
+		b.metrics.SentMessages.WithLabelValues("reply").Inc()
+		return ctx.Reply(b.t(timeoutCtx, ctx, "feedback.screenshot"))
+	case stateAwaitingTemplate:
+		return b.templateInputHandler(timeoutCtx, ctx, userID, ctx.Text())
 	case stateAwaitingBroadcast:
-		text := ctx.Text()
 		b.log.Debug("User is trying to send broadcast message to everyone", "user", userID)
-		return b.broadcastMessageHandler(timeoutCtx, ctx, text)
+		return b.broadcastMessageHandler(timeoutCtx, ctx, broadcastMessage{
+			Kind:      broadcastText,
+			Text:      ctx.Text(),
+			Important: state.Category == broadcastImportant,
+		})
 	default:
 		b.log.Error("Get unknown state", "state", state.WaitingFor)
 		b.metrics.SentMessages.WithLabelValues("error").Inc()
@@ -198,56 +274,8 @@ func (b *Bot) textHandler(ctx telebot.Context) error {
 	}
 }
 
-func (b *Bot) loginInputHandler(ctx context.Context, bCtx telebot.Context, userID int64, email string) error {
-	startTime := time.Now()
-	err := b.usrepo.LinkTelegramIDByEmail(ctx, userID, email)
-	b.metrics.DBQueryDuration.WithLabelValues("link_telegram_id").Observe(time.Since(startTime).Seconds())
-	if err != nil {
-		if errors.Is(err, repository.ErrUserAlreadyLinked) {
-			b.log.InfoContext(ctx, "User already linked to another id", "user", userID, "email", email)
-			_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
-			b.metrics.SentMessages.WithLabelValues("reaction").Inc()
-			b.metrics.SentMessages.WithLabelValues("user_error").Inc()
-			return bCtx.Send(b.t(ctx, bCtx, "login.error.already_linked"))
-		}
-		if errors.Is(err, repository.ErrIDExists) {
-			b.log.InfoContext(ctx, "User already has connection with another employee", "user", userID, "email", email)
-			b.metrics.SentMessages.WithLabelValues("reaction").Inc()
-			b.metrics.SentMessages.WithLabelValues("user_error").Inc()
-			_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
-			return bCtx.Send(b.t(ctx, bCtx, "login.error.id_exists"))
-		}
-		if errors.Is(err, repository.ErrUserNotFound) {
-			b.log.InfoContext(ctx, "User with this email not found", "user", userID, "email", email)
-			b.metrics.SentMessages.WithLabelValues("reaction").Inc()
-			b.metrics.SentMessages.WithLabelValues("user_error").Inc()
-			_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
-			b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmail})
-			return bCtx.Send(b.t(ctx, bCtx, "login.error.not_found"))
-		}
-		b.log.ErrorContext(ctx, "Failed to link telegram id with employee", "error", err)
-		b.metrics.SentMessages.WithLabelValues("error").Inc()
-		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
-	}
-
-	isAdmin, err := b.usrepo.IsAdmin(ctx, userID)
-	if err != nil {
-		b.log.ErrorContext(ctx, "Failed to check admin status", "error", err)
-		b.metrics.SentMessages.WithLabelValues("error").Inc()
-		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
-	}
-
-	menu := b.buildAuthMenuWithTranslations(ctx, bCtx, isAdmin)
-
-	b.log.InfoContext(ctx, "User successfully authenticated", "user", userID, "email", email)
-	b.metrics.SentMessages.WithLabelValues("reaction").Inc()
-	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbUp))
-	return bCtx.Send(b.t(ctx, bCtx, "login.success"), menu)
-}
-
 func (b *Bot) commentConfirmationHandler(ctx telebot.Context, taskID int, commentText string) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	startTime := time.Now()
@@ -284,66 +312,5 @@ func (b *Bot) commentConfirmationHandler(ctx telebot.Context, taskID int, commen
 
 	b.log.Debug("Succesfully get comment from user, sending confiramtion request.", "user", ctx.Sender().ID)
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	return ctx.Send(messageText, confirmMenu, telebot.ModeMarkdown)
-}
-
-// locationHandler processes the user's location sent via a message.
-// It retrieves tasks within a specified radius of the user's location
-// and sends back a response with the nearest tasks or an appropriate
-// message if no tasks are found. It also handles user state management
-// and logs relevant information for monitoring purposes.
-func (b *Bot) locationHandler(ctx telebot.Context) error {
-	userID := ctx.Sender().ID
-	latitude := ctx.Message().Location.Lat
-	longitude := ctx.Message().Location.Lng
-	radius := 15
-	state, ok := b.stateManager.Get(userID)
-
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	b.log.Info("User sent geolocation", "user", userID, "latitude", latitude, "longitude", longitude)
-
-	if ok && state.WaitingFor == stateAwaitingLocation {
-		startTime := time.Now()
-		tasks, err := b.tarepo.GetTasksInRadius(timeoutCtx, latitude, longitude, radius)
-		b.metrics.DBQueryDuration.WithLabelValues("get_tasks_in_radius").Observe(time.Since(startTime).Seconds())
-		if err != nil {
-			b.log.Error("Failed to get nearest tasks", "error", err)
-			b.metrics.SentMessages.WithLabelValues("error").Inc()
-			return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
-		}
-
-		if len(tasks) == 0 {
-			b.metrics.SentMessages.WithLabelValues("text").Inc()
-			return ctx.Send(b.t(timeoutCtx, ctx, "tasks.near.none"))
-		}
-
-		// creates dynamic inline keyboard
-		var rows [][]telebot.InlineButton
-		buttons := make([]telebot.InlineButton, 0, 3)
-
-		for idx, task := range tasks {
-			btn := telebot.InlineButton{
-				Unique: "task_details",
-				Text:   fmt.Sprintf("#%d", task.ID),
-				Data:   strconv.Itoa(task.ID),
-			}
-			buttons = append(buttons, btn)
-			if (idx+1)%3 == 0 || idx == len(tasks)-1 {
-				rows = append(rows, buttons)
-				buttons = nil
-			}
-		}
-
-		menu := &telebot.ReplyMarkup{InlineKeyboard: rows}
-		responseText := b.tWithData(timeoutCtx, ctx, "tasks.near.title", map[string]interface{}{
-			"radius": radius,
-		})
-		b.metrics.SentMessages.WithLabelValues("text").Inc()
-		return ctx.Send(responseText, menu)
-	}
-
-	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	return ctx.Send(b.t(timeoutCtx, ctx, "tasks.near.unsolicited"))
+	return b.sendLong(ctx, messageText, confirmMenu, telebot.ModeMarkdown)
 }
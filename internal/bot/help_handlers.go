@@ -0,0 +1,92 @@
+package bot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+// handleCommandAliases registers the slash command aliases of menu buttons. An alias runs the
+// named handler of the button with the middlewares of its group, so /tasks requires a linked
+// account like the "Active tasks" button does.
+func (b *Bot) handleCommandAliases(public *Router) error {
+	for command, handlerName := range b.commandAliases {
+		if slices.ContainsFunc(builtinCommands, func(builtin botCommand) bool { return builtin.Command == command }) {
+			return fmt.Errorf("command alias /%s replaces a built-in command", command)
+		}
+		if _, ok := b.router.Named(handlerName); !ok {
+			return fmt.Errorf("command alias /%s runs unknown handler %q", command, handlerName)
+		}
+		public.Handle("/"+command, func(ctx telebot.Context) error {
+			return b.callHandler(handlerName, ctx)
+		})
+	}
+	return nil
+}
+
+// helpHandler lists the actions of the menus available to the user, followed by the slash commands.
+func (b *Bot) helpHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("help").Inc()
+	userID := ctx.Sender().ID
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+
+	isAuth, err := b.usrepo.IsUserAuthenticated(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to check user authentication", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	var sections []string
+	isAdmin := false
+	if isAuth {
+		isAdmin = b.IsAdminCheck(userID)
+		sections = append(sections, b.localizer.Get(lang, "help.title"))
+		sections = append(sections, strings.Join(b.helpMenuLines(lang, MenuMain, userID, 0), "\n"))
+	} else {
+		sections = append(sections, b.localizer.Get(lang, "help.unauthenticated"))
+	}
+	sections = append(sections, strings.Join(b.helpCommandLines(lang, isAdmin), "\n"))
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(strings.Join(sections, "\n\n"))
+}
+
+// helpMenuLines renders the buttons of the menu visible to the user, one per line and indented
+// by depth. The buttons of a submenu follow the button opening it.
+func (b *Bot) helpMenuLines(lang string, menuType MenuType, userID int64, depth int) []string {
+	menuDef := b.menuBuilder.registry.Get(menuType)
+	if menuDef == nil {
+		return nil
+	}
+
+	indent := strings.Repeat("    ", depth)
+	var lines []string
+	for _, btn := range b.menuBuilder.filterVisibleButtons(menuDef.Buttons, userID) {
+		text := b.localizer.Get(lang, btn.TextKey)
+		if btn.SubMenu == "" {
+			lines = append(lines, indent+"• "+text)
+			continue
+		}
+		lines = append(lines, indent+text)
+		lines = append(lines, b.helpMenuLines(lang, btn.SubMenu, userID, depth+1)...)
+	}
+	return lines
+}
+
+// helpCommandLines renders the slash commands of the user's role, the same the "/" menu of the chat shows.
+func (b *Bot) helpCommandLines(lang string, isAdmin bool) []string {
+	lines := []string{b.localizer.Get(lang, "help.commands")}
+	for _, command := range b.commandList(lang, isAdmin) {
+		lines = append(lines, b.localizer.GetWithData(lang, "help.command", map[string]interface{}{
+			"command":     command.Text,
+			"description": command.Description,
+		}))
+	}
+	return lines
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// maxInlineDescriptionLen limits the task description in the shared card, so the card stays compact.
+const maxInlineDescriptionLen = 300
+
+// inlineQueryHandler handles inline queries like "@oraclebot 12345" and answers with a compact
+// task card that can be shared in any chat. Only authenticated employees can look up tasks;
+// other users get a button that leads them to the bot to log in.
+func (b *Bot) inlineQueryHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("inline_query").Inc()
+
+	startTime := time.Now()
+	isAllowed, err := b.usrepo.IsUserAuthenticated(timeoutCtx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("is_user_authenticated").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to authenticate inline query user", "error", err, "user", userID)
+		return ctx.Answer(&telebot.QueryResponse{IsPersonal: true})
+	}
+
+	if !isAllowed {
+		b.log.InfoContext(timeoutCtx, "Inline query from unauthenticated user", "user", userID)
+		return ctx.Answer(&telebot.QueryResponse{
+			IsPersonal: true,
+			Button: &telebot.QueryResponseButton{
+				Text:  b.t(timeoutCtx, ctx, "inline.login"),
+				Start: "inline",
+			},
+		})
+	}
+
+	query := strings.TrimPrefix(strings.TrimSpace(ctx.Query().Text), "#")
+	taskID, err := strconv.Atoi(query)
+	if err != nil || taskID <= 0 {
+		return ctx.Answer(&telebot.QueryResponse{IsPersonal: true})
+	}
+
+	b.log.InfoContext(timeoutCtx, "User looked up task inline", "user", userID, "task", taskID)
+
+	details, err := b.getTaskDetails(timeoutCtx, taskID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrTaskNotFound) {
+			b.log.ErrorContext(timeoutCtx, "Failed to get task for inline query", "error", err, "task", taskID)
+		}
+		result := &telebot.ArticleResult{
+			Title: b.tWithData(timeoutCtx, ctx, "inline.not_found", map[string]interface{}{"id": taskID}),
+			Text:  b.tWithData(timeoutCtx, ctx, "inline.not_found", map[string]interface{}{"id": taskID}),
+		}
+		result.SetResultID("not_found_" + query)
+		return ctx.Answer(&telebot.QueryResponse{Results: telebot.Results{result}, IsPersonal: true})
+	}
+
+	result := &telebot.ArticleResult{
+		Title:       b.tWithData(timeoutCtx, ctx, "inline.title", map[string]interface{}{"id": details.ID}),
+		Description: details.Type + " · " + details.Address,
+		Text:        b.formatTaskCard(timeoutCtx, ctx, details),
+	}
+	result.SetResultID(strconv.Itoa(details.ID))
+
+	b.metrics.SentMessages.WithLabelValues("inline").Inc()
+	return ctx.Answer(&telebot.QueryResponse{
+		Results:    telebot.Results{result},
+		IsPersonal: true,
+		CacheTime:  int((time.Minute).Seconds()),
+	})
+}
+
+// formatTaskCard renders the compact plain-text card of a task shared through inline mode.
+func (b *Bot) formatTaskCard(ctx context.Context, tCtx telebot.Context, details *models.TaskDetails) string {
+	description := details.Description
+	if utf8.RuneCountInString(description) > maxInlineDescriptionLen {
+		description = string([]rune(description)[:maxInlineDescriptionLen]) + "…"
+	}
+
+	return b.tWithData(ctx, tCtx, "inline.card", map[string]interface{}{
+		"id":          details.ID,
+		"type":        details.Type,
+		"created":     b.formatDate(ctx, tCtx, details.CreationDate),
+		"address":     details.Address,
+		"customer":    strings.Join(details.CustomerNames, ", "),
+		"executors":   strings.Join(details.Executors, ", "),
+		"description": description,
+	})
+}
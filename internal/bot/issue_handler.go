@@ -1,27 +1,342 @@
 package bot
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/integrations/github"
+	"github.com/UnknownOlympus/oracle/internal/version"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"gopkg.in/telebot.v4"
 )
 
-// reportIssueHandler handles the request to report a bug or feature.
-// It displays information about how to submit issues on GitHub with a direct link.
+const (
+	// feedbackDraftTTL is how long the described feedback waits for a screenshot or confirmation.
+	feedbackDraftTTL = 15 * time.Minute
+	// minFeedbackLength and maxFeedbackLength bound the description of the feedback in characters.
+	minFeedbackLength = 10
+	maxFeedbackLength = 4000
+	// feedbackTitleLength limits the part of the description used as the issue title.
+	feedbackTitleLength = 70
+	// maxFeedbackPerDay is how many issues a user may file per day.
+	maxFeedbackPerDay = 5
+)
+
+// feedbackLabels maps the feedback categories to the labels of the created issues.
+var feedbackLabels = map[string]string{
+	"bug":  "bug",
+	"idea": "enhancement",
+}
+
+// feedbackDraft is the feedback described by the user, kept in Redis until it is sent.
+type feedbackDraft struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// feedbackQuotaKey returns the Redis key counting the issues the user filed on the day.
+func feedbackQuotaKey(userID int64, day time.Time) string {
+	return fmt.Sprintf("oracle:feedback_quota:%d:%s", userID, day.Format(time.DateOnly))
+}
+
+// feedbackDraftKey returns the Redis key holding the feedback draft of the user.
+func feedbackDraftKey(userID int64) string {
+	return fmt.Sprintf("oracle:feedback:%d", userID)
+}
+
+// reportIssueHandler starts the feedback flow: the user picks a category, describes it and may
+// attach a screenshot, and the bot files a GitHub issue. Without a GitHub token, and for users
+// who are not logged in, who must not file issues with the token of the bot, the user is
+// pointed to the issues page instead.
 func (b *Bot) reportIssueHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
-	b.log.Info("User requested issue reporting info", "user", ctx.Sender().ID)
+	userID := ctx.Sender().ID
+	b.log.Info("User requested issue reporting info", "user", userID)
 	b.metrics.CommandReceived.WithLabelValues("report_issue").Inc()
 
-	// Build the message with title and description
-	title := b.t(timeoutCtx, ctx, "issue.title")
-	description := b.t(timeoutCtx, ctx, "issue.description")
-	message := fmt.Sprintf("%s\n\n%s", title, description)
+	isAuth := false
+	if b.github != nil {
+		var err error
+		if isAuth, err = b.usrepo.IsUserAuthenticated(timeoutCtx, userID); err != nil {
+			b.log.WarnContext(timeoutCtx, "Failed to check authentication for feedback", "error", err, "user", userID)
+		}
+	}
+
+	if !isAuth {
+		title := b.t(timeoutCtx, ctx, "issue.title")
+		description := b.t(timeoutCtx, ctx, "issue.description")
+		message := fmt.Sprintf("%s\n\n%s", title, description)
+
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(message, telebot.ModeMarkdown)
+	}
+
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(
+		menu.Data(b.localizer.Get(lang, "feedback.category.bug"), "feedback_category", "bug"),
+		menu.Data(b.localizer.Get(lang, "feedback.category.idea"), "feedback_category", "idea"),
+	))
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.localizer.Get(lang, "feedback.pick_category"), menu)
+}
+
+// feedbackCategoryHandler asks the user to describe the feedback of the picked category.
+func (b *Bot) feedbackCategoryHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("feedback_category").Inc()
+	_ = ctx.Respond()
+
+	category := ctx.Data()
+	if _, ok := feedbackLabels[category]; !ok {
+		b.log.WarnContext(timeoutCtx, "Invalid feedback category in callback", "data", category)
+		return nil
+	}
+
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingFeedback, Category: category})
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "feedback.describe."+category))
+}
+
+// feedbackDescriptionHandler keeps the description as a draft and offers to attach a screenshot,
+// which needs the object storage, or to send the feedback right away.
+func (b *Bot) feedbackDescriptionHandler(
+	ctx context.Context,
+	bCtx telebot.Context,
+	userID int64,
+	category, description string,
+) error {
+	description = strings.TrimSpace(description)
+	if length := len([]rune(description)); length < minFeedbackLength || length > maxFeedbackLength {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingFeedback, Category: category})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return bCtx.Send(b.tWithData(ctx, bCtx, "feedback.error.length", map[string]interface{}{
+			"min": minFeedbackLength,
+			"max": maxFeedbackLength,
+		}))
+	}
+
+	draft, err := json.Marshal(feedbackDraft{Category: category, Description: description})
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to marshal feedback draft", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	if err = b.redisClient.Set(ctx, feedbackDraftKey(userID), draft, feedbackDraftTTL).Err(); err != nil {
+		b.log.ErrorContext(ctx, "Failed to save feedback draft", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+
+	lang := b.getUserLanguage(ctx, bCtx)
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(
+		menu.Data(b.localizer.Get(lang, "feedback.button.cancel"), "feedback_cancel"),
+		menu.Data(b.localizer.Get(lang, "feedback.button.send"), "feedback_send"),
+	))
+
+	prompt := "feedback.confirm"
+	if b.storage != nil {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingFeedbackScreenshot})
+		prompt = "feedback.screenshot"
+	}
 
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	return ctx.Send(message, telebot.ModeMarkdown)
+	return bCtx.Send(b.localizer.Get(lang, prompt), menu)
+}
+
+// feedbackScreenshotHandler stores the screenshot of the feedback and sends the feedback with
+// a link to it. GitHub has no API for issue attachments, so the link is a presigned URL of the
+// object storage.
+func (b *Bot) feedbackScreenshotHandler(ctx context.Context, bCtx telebot.Context, photo *telebot.Photo) error {
+	userID := bCtx.Sender().ID
+	content, err := b.downloadFile(&photo.File)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to download feedback screenshot", "error", err, "user", userID)
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingFeedbackScreenshot})
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "feedback.error.screenshot"))
+	}
+
+	key := "feedback/" + uuid.New().String() + ".jpg"
+	if err = b.storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		b.log.ErrorContext(ctx, "Failed to store feedback screenshot", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	link, err := b.storage.PresignedURL(ctx, key, "screenshot.jpg")
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to create feedback screenshot link", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+
+	return b.submitFeedback(ctx, bCtx, link.String())
+}
+
+// feedbackSendHandler sends the feedback without a screenshot.
+func (b *Bot) feedbackSendHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("feedback_send").Inc()
+	_ = ctx.Respond()
+	// Edited without the markup, so the feedback cannot be sent twice.
+	_ = ctx.Edit(ctx.Message().Text)
+	b.stateManager.Get(ctx.Sender().ID)
+
+	return b.submitFeedback(timeoutCtx, ctx, "")
+}
+
+// feedbackCancelHandler drops the feedback draft.
+func (b *Bot) feedbackCancelHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("feedback_cancel").Inc()
+	_ = ctx.Respond()
+
+	userID := ctx.Sender().ID
+	b.stateManager.Get(userID)
+	if err := b.redisClient.Del(timeoutCtx, feedbackDraftKey(userID)).Err(); err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to delete feedback draft", "error", err, "user", userID)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "feedback.canceled"))
+}
+
+// submitFeedback files the feedback draft of the user as a GitHub issue.
+func (b *Bot) submitFeedback(ctx context.Context, bCtx telebot.Context, screenshotURL string) error {
+	userID := bCtx.Sender().ID
+	cached, err := b.redisClient.Get(ctx, feedbackDraftKey(userID)).Bytes()
+	if err != nil {
+		b.log.WarnContext(ctx, "Could not find feedback draft", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "feedback.expired"))
+	}
+
+	var draft feedbackDraft
+	if err = json.Unmarshal(cached, &draft); err != nil {
+		b.log.ErrorContext(ctx, "Failed to unmarshal feedback draft", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+
+	lang := b.getUserLanguage(ctx, bCtx)
+	allowed, err := b.takeFeedbackQuota(ctx, userID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to count filed feedback", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.localizer.Get(lang, "error.internal"))
+	}
+	if !allowed {
+		b.log.InfoContext(ctx, "User reached the daily feedback limit", "user", userID)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return bCtx.Send(b.localizer.GetWithData(lang, "feedback.error.daily_limit", map[string]interface{}{
+			"max": maxFeedbackPerDay,
+		}), telebot.NoPreview)
+	}
+
+	issue := github.Issue{
+		Title:  feedbackTitle(draft),
+		Body:   b.feedbackBody(ctx, bCtx, lang, draft, screenshotURL),
+		Labels: []string{feedbackLabels[draft.Category]},
+	}
+	created, err := b.github.CreateIssue(ctx, issue)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to create feedback issue", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.localizer.Get(lang, "feedback.error.create"), telebot.NoPreview)
+	}
+
+	if err = b.redisClient.Del(ctx, feedbackDraftKey(userID)).Err(); err != nil {
+		b.log.WarnContext(ctx, "Failed to delete feedback draft", "error", err, "user", userID)
+	}
+	b.log.InfoContext(ctx, "User filed feedback", "user", userID, "category", draft.Category, "issue", created.Number)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return bCtx.Send(b.localizer.GetWithData(lang, "feedback.created", map[string]interface{}{
+		"number": created.Number,
+		"url":    created.URL,
+	}), telebot.NoPreview)
+}
+
+// takeFeedbackQuota counts an issue filed by the user today and reports whether it is within
+// maxFeedbackPerDay. Failed attempts count too, so retries cannot flood GitHub either.
+func (b *Bot) takeFeedbackQuota(ctx context.Context, userID int64) (bool, error) {
+	key := feedbackQuotaKey(userID, time.Now())
+	var count *redis.IntCmd
+	_, err := b.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, 24*time.Hour)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return count.Val() <= maxFeedbackPerDay, nil
+}
+
+// feedbackTitle returns the title of the issue: the category and the start of the first line
+// of the description.
+func feedbackTitle(draft feedbackDraft) string {
+	prefix := "[Bug] "
+	if draft.Category == "idea" {
+		prefix = "[Idea] "
+	}
+
+	title, _, _ := strings.Cut(draft.Description, "\n")
+	if runes := []rune(strings.TrimSpace(title)); len(runes) > feedbackTitleLength {
+		title = string(runes[:feedbackTitleLength-1]) + "…"
+	}
+	return prefix + strings.TrimSpace(title)
+}
+
+// feedbackBody renders the description with the screenshot and the environment of the reporter.
+// The repository may be public, so the reporter is described by their role only.
+func (b *Bot) feedbackBody(
+	ctx context.Context,
+	bCtx telebot.Context,
+	lang string,
+	draft feedbackDraft,
+	screenshotURL string,
+) string {
+	role := "guest"
+	if isAuth, err := b.usrepo.IsUserAuthenticated(ctx, bCtx.Sender().ID); err == nil && isAuth {
+		role = "employee"
+		if b.IsAdminCheck(bCtx.Sender().ID) {
+			role = "admin"
+		}
+	}
+
+	var body strings.Builder
+	body.WriteString(draft.Description)
+	body.WriteString("\n\n")
+	if screenshotURL != "" {
+		fmt.Fprintf(&body, "![Screenshot](%s)\n\n", screenshotURL)
+		if b.storage != nil {
+			fmt.Fprintf(&body, "_The screenshot link expires after %s._\n\n", b.storage.PresignExpiry())
+		}
+	}
+	body.WriteString("---\n\n| | |\n|---|---|\n")
+	fmt.Fprintf(&body, "| Bot version | `%s` (%s) |\n", version.Version, version.Commit)
+	fmt.Fprintf(&body, "| Bot language | %s |\n", lang)
+	fmt.Fprintf(&body, "| Telegram language | %s |\n", bCtx.Sender().LanguageCode)
+	fmt.Fprintf(&body, "| Role | %s |\n", role)
+	fmt.Fprintf(&body, "| Reported | %s |\n\n", time.Now().UTC().Format(time.RFC3339))
+	body.WriteString("_Filed from the Telegram bot._\n")
+
+	return body.String()
 }
@@ -1,7 +1,6 @@
 package bot
 
 import (
-	"context"
 	"time"
 
 	"gopkg.in/telebot.v4"
@@ -10,13 +9,14 @@ import (
 // languageHandler handles the language selection request from the user.
 // It presents the user with a menu to choose their preferred language.
 func (b *Bot) languageHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	menu := &telebot.ReplyMarkup{}
 	menu.Inline(
 		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "language.button.english"), "language_en")),
 		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "language.button.ukrainian"), "language_uk")),
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "language.button.polish"), "language_pl")),
 	)
 
 	b.metrics.SentMessages.WithLabelValues("text").Inc()
@@ -26,7 +26,7 @@ func (b *Bot) languageHandler(ctx telebot.Context) error {
 // languageChangeHandler handles the language change request from the user.
 // It updates the user's language preference in the database and sends a confirmation message.
 func (b *Bot) languageChangeHandler(ctx telebot.Context) error {
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
 	userID := ctx.Sender().ID
@@ -39,6 +39,8 @@ func (b *Bot) languageChangeHandler(ctx telebot.Context) error {
 		langCode = "en"
 	case "language_uk":
 		langCode = "uk"
+	case "language_pl":
+		langCode = "pl"
 	default:
 		b.log.Error("Unknown language callback", "data", callbackData)
 		return ctx.Respond(&telebot.CallbackResponse{Text: "Unknown language"})
@@ -77,6 +79,9 @@ func (b *Bot) languageChangeHandler(ctx telebot.Context) error {
 		langCode,
 	)
 
+	// The "/" menu follows the language of the bot rather than the one of the Telegram client.
+	b.publishUserCommands(timeoutCtx, userID, langCode, b.IsAdminCheck(userID))
+
 	// Build menu with new language
 	menu := b.menuBuilder.Build(timeoutCtx, ctx, MenuMore, userID)
 
@@ -0,0 +1,274 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/telebot.v4"
+	"gopkg.in/telebot.v4/react"
+)
+
+const (
+	// loginCodeTTL is how long an emailed login verification code stays valid.
+	loginCodeTTL = 10 * time.Minute
+	// loginCodeDigits is the length of the login verification code.
+	loginCodeDigits = 6
+	// maxLoginCodeAttempts is the number of wrong codes after which the code is discarded.
+	maxLoginCodeAttempts = 5
+	// QRLoginTTL is how long a login token shown as a QR code stays valid.
+	QRLoginTTL = 5 * time.Minute
+	// loginDeepLinkPrefix prefixes the QR login token in /start payloads.
+	loginDeepLinkPrefix = "login_"
+)
+
+// loginCodeKey returns the Redis key holding the pending login verification of the user.
+func loginCodeKey(userID int64) string {
+	return fmt.Sprintf("oracle:login_code:%d", userID)
+}
+
+// qrLoginKey returns the Redis key holding the email a QR login token links.
+func qrLoginKey(token string) string {
+	return "oracle:qr_login:" + token
+}
+
+// IssueLoginToken creates a one-time token linking the account of the employee with the email,
+// valid for QRLoginTTL. It returns the Telegram deep link carrying the token, shown as a QR code
+// on the login page of the monitoring server.
+func (b *Bot) IssueLoginToken(ctx context.Context, email string) (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate login token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	if err := b.redisClient.Set(ctx, qrLoginKey(token), strings.TrimSpace(email), QRLoginTTL).Err(); err != nil {
+		b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
+		return "", fmt.Errorf("failed to save login token: %w", err)
+	}
+	b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
+
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", b.bot.Me.Username, loginDeepLinkPrefix, token), nil
+}
+
+// qrLoginHandler links the account of the employee the scanned QR login token was issued for.
+// The token is single-use.
+func (b *Bot) qrLoginHandler(ctx context.Context, bCtx telebot.Context, userID int64, token string) error {
+	b.metrics.CommandReceived.WithLabelValues("qr_login").Inc()
+
+	email, err := b.redisClient.GetDel(ctx, qrLoginKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			b.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
+			b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+			return bCtx.Send(b.t(ctx, bCtx, "login.qr.expired"), b.buildMainMenu(ctx, bCtx))
+		}
+		b.log.ErrorContext(ctx, "Failed to get login token", "error", err, "user", userID)
+		b.metrics.CacheOps.WithLabelValues("get", "error").Inc()
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
+
+	startTime := time.Now()
+	err = b.usrepo.CheckEmailLink(ctx, userID, email)
+	b.metrics.DBQueryDuration.WithLabelValues("check_email_link").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return b.loginErrorHandler(ctx, bCtx, userID, email, err)
+	}
+
+	return b.linkAccount(ctx, bCtx, userID, email)
+}
+
+// newLoginCode generates a random numeric login verification code.
+func newLoginCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", loginCodeDigits, n.Int64()), nil
+}
+
+// loginInputHandler checks that the entered email can be linked to the user and emails a
+// one-time code to it. The account is linked only after the code is entered.
+func (b *Bot) loginInputHandler(ctx context.Context, bCtx telebot.Context, userID int64, email string) error {
+	email = strings.TrimSpace(email)
+
+	startTime := time.Now()
+	err := b.usrepo.CheckEmailLink(ctx, userID, email)
+	b.metrics.DBQueryDuration.WithLabelValues("check_email_link").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return b.loginErrorHandler(ctx, bCtx, userID, email, err)
+	}
+
+	if b.mailer == nil {
+		b.log.WarnContext(ctx, "SMTP is not configured, login code is not sent", "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "login.error.verification_unavailable"))
+	}
+
+	code, err := newLoginCode()
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to generate login code", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+
+	key := loginCodeKey(userID)
+	_, err = b.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "email", email, "code", code, "attempts", 0)
+		pipe.Expire(ctx, key, loginCodeTTL)
+		return nil
+	})
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to save login code", "error", err, "user", userID)
+		b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
+
+	body := b.tWithData(ctx, bCtx, "login.email.body", map[string]interface{}{
+		"code":    code,
+		"minutes": int(loginCodeTTL.Minutes()),
+	})
+	// Sending is limited by SMTP_TIMEOUT only, mail servers are often slower than the query timeout.
+	err = b.mailer.Send(context.WithoutCancel(ctx), email, b.t(ctx, bCtx, "login.email.subject"), body)
+	if err != nil {
+		_ = b.redisClient.Del(context.WithoutCancel(ctx), key).Err()
+		b.log.ErrorContext(ctx, "Failed to send login code email", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "login.error.verification_unavailable"))
+	}
+
+	b.log.InfoContext(ctx, "Login verification code sent", "user", userID, "email", email)
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingCode})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return bCtx.Send(b.tWithData(ctx, bCtx, "login.code.sent", map[string]interface{}{
+		"email":   email,
+		"minutes": int(loginCodeTTL.Minutes()),
+	}))
+}
+
+// loginCodeHandler checks the entered verification code and links the account if it matches.
+// After maxLoginCodeAttempts wrong codes, or once the code expires, the user has to start over.
+func (b *Bot) loginCodeHandler(ctx context.Context, bCtx telebot.Context, userID int64, input string) error {
+	key := loginCodeKey(userID)
+	pending, err := b.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get login code", "error", err, "user", userID)
+		b.metrics.CacheOps.WithLabelValues("get", "error").Inc()
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+	if len(pending) == 0 {
+		b.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmail})
+		return bCtx.Send(b.t(ctx, bCtx, "login.code.expired"))
+	}
+	b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
+
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(input)), []byte(pending["code"])) != 1 {
+		attempts, incrErr := b.redisClient.HIncrBy(ctx, key, "attempts", 1).Result()
+		if incrErr != nil {
+			b.log.WarnContext(ctx, "Failed to count login code attempt", "error", incrErr, "user", userID)
+		}
+		b.log.InfoContext(ctx, "Wrong login verification code", "user", userID, "attempts", attempts)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
+
+		if incrErr != nil || attempts >= maxLoginCodeAttempts {
+			_ = b.redisClient.Del(ctx, key).Err()
+			b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmail})
+			return bCtx.Send(b.t(ctx, bCtx, "login.code.too_many_attempts"))
+		}
+
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingCode})
+		return bCtx.Send(b.tWithData(ctx, bCtx, "login.code.wrong", map[string]interface{}{
+			"attempts": maxLoginCodeAttempts - attempts,
+		}))
+	}
+
+	// The code is single-use.
+	if err = b.redisClient.Del(ctx, key).Err(); err != nil {
+		b.log.WarnContext(ctx, "Failed to delete used login code", "error", err, "user", userID)
+	}
+
+	return b.linkAccount(ctx, bCtx, userID, pending["email"])
+}
+
+// linkAccount links the Telegram ID to the employee with the verified email.
+func (b *Bot) linkAccount(ctx context.Context, bCtx telebot.Context, userID int64, email string) error {
+	startTime := time.Now()
+	err := b.usrepo.LinkTelegramIDByEmail(ctx, userID, email)
+	b.metrics.DBQueryDuration.WithLabelValues("link_telegram_id").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return b.loginErrorHandler(ctx, bCtx, userID, email, err)
+	}
+	b.forgetUser(userID)
+
+	isAdmin, err := b.usrepo.IsAdmin(ctx, userID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to check admin status", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+	}
+
+	menu := b.buildAuthMenuWithTranslations(ctx, bCtx, isAdmin)
+	b.publishUserCommands(ctx, userID, b.getUserLanguage(ctx, bCtx), isAdmin)
+
+	b.log.InfoContext(ctx, "User successfully authenticated", "user", userID, "email", email)
+	b.metrics.SentMessages.WithLabelValues("reaction").Inc()
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbUp))
+	if err = bCtx.Send(b.t(ctx, bCtx, "login.success"), menu); err != nil {
+		return err
+	}
+
+	b.startOnboarding(ctx, bCtx, userID)
+	return nil
+}
+
+// loginErrorHandler tells the user why the email cannot be linked to their Telegram account.
+func (b *Bot) loginErrorHandler(
+	ctx context.Context,
+	bCtx telebot.Context,
+	userID int64,
+	email string,
+	err error,
+) error {
+	if errors.Is(err, repository.ErrUserAlreadyLinked) {
+		b.log.InfoContext(ctx, "User already linked to another id", "user", userID, "email", email)
+		_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
+		b.metrics.SentMessages.WithLabelValues("reaction").Inc()
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return bCtx.Send(b.t(ctx, bCtx, "login.error.already_linked"))
+	}
+	if errors.Is(err, repository.ErrIDExists) {
+		b.log.InfoContext(ctx, "User already has connection with another employee", "user", userID, "email", email)
+		b.metrics.SentMessages.WithLabelValues("reaction").Inc()
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
+		return bCtx.Send(b.t(ctx, bCtx, "login.error.id_exists"))
+	}
+	if errors.Is(err, repository.ErrUserNotFound) {
+		b.log.InfoContext(ctx, "User with this email not found", "user", userID, "email", email)
+		b.metrics.SentMessages.WithLabelValues("reaction").Inc()
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		_ = bCtx.Bot().React(bCtx.Recipient(), bCtx.Message(), react.React(react.ThumbDown))
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingEmail})
+		return bCtx.Send(b.t(ctx, bCtx, "login.error.not_found"))
+	}
+	b.log.ErrorContext(ctx, "Failed to link telegram id with employee", "error", err)
+	b.metrics.SentMessages.WithLabelValues("error").Inc()
+	return bCtx.Send(b.t(ctx, bCtx, "error.internal"))
+}
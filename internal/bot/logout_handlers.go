@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// UnlinkGracePeriod is how long a logout can be undone. The unlinked users are purged after it.
+const UnlinkGracePeriod = 7 * 24 * time.Hour
+
+// UnlinkPurgeInterval is how often the users unlinked for longer than the grace period are purged.
+const UnlinkPurgeInterval = time.Hour
+
+// logoutHandler handles the logout process for a user. The user is unlinked from their employee
+// right away, but their subscriptions and settings are kept for the grace period, during which
+// the logout can be undone with the button sent after the success message.
+func (b *Bot) logoutHandler(ctx telebot.Context) error {
+	userID := ctx.Sender().ID
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.stateManager.Get(userID)
+	b.log.Info("User logged out", "user", userID)
+	b.metrics.CommandReceived.WithLabelValues("logout").Inc()
+
+	startTime := time.Now()
+	err := b.usrepo.UnlinkUser(timeoutCtx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("unlink_user").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to unlink user", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "logout.error"))
+	}
+	b.forgetUser(userID)
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditAccountUnlink, map[string]interface{}{
+		"telegram_id": userID,
+	})
+	b.resetUserCommands(timeoutCtx, userID)
+
+	menu := b.buildMainMenu(timeoutCtx, ctx)
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	if err = ctx.Send(b.t(timeoutCtx, ctx, "logout.success"), menu); err != nil {
+		return err
+	}
+
+	undo := &telebot.ReplyMarkup{}
+	undo.Inline(undo.Row(undo.Data(b.t(timeoutCtx, ctx, "logout.undo_button"), "logout_undo")))
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "logout.undo_hint", map[string]interface{}{
+		"days": int(UnlinkGracePeriod.Hours() / 24),
+	}), undo)
+}
+
+// logoutUndoHandler links the user to their employee again, if the grace period of the logout
+// is not over. Otherwise the user has to log in again.
+func (b *Bot) logoutUndoHandler(ctx telebot.Context) error {
+	userID := ctx.Sender().ID
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("logout_undo").Inc()
+
+	restored, err := b.usrepo.RestoreUser(timeoutCtx, userID, time.Now().Add(-UnlinkGracePeriod))
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to restore user", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal"), ShowAlert: true})
+	}
+	if !restored {
+		b.log.InfoContext(timeoutCtx, "Logout cannot be undone", "user", userID)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		text := b.t(timeoutCtx, ctx, "logout.undo_expired")
+		return ctx.Respond(&telebot.CallbackResponse{Text: text, ShowAlert: true})
+	}
+	b.forgetUser(userID)
+	_ = ctx.Respond()
+
+	b.recordAdminAction(timeoutCtx, userID, repository.AuditAccountRestore, map[string]interface{}{
+		"telegram_id": userID,
+	})
+	b.log.InfoContext(timeoutCtx, "User undid logout", "user", userID)
+
+	isAdmin := b.IsAdminCheck(userID)
+	b.publishUserCommands(timeoutCtx, userID, b.getUserLanguage(timeoutCtx, ctx), isAdmin)
+
+	if err = ctx.Delete(); err != nil {
+		b.log.DebugContext(timeoutCtx, "Failed to delete undo message", "error", err, "user", userID)
+	}
+
+	menu := b.buildAuthMenuWithTranslations(timeoutCtx, ctx, isAdmin)
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "logout.undone"), menu)
+}
+
+// PurgeUnlinkedUsers removes the users whose logout can no longer be undone, together with
+// their subscriptions and settings.
+func (b *Bot) PurgeUnlinkedUsers(ctx context.Context) error {
+	purged, err := b.usrepo.PurgeUnlinkedUsers(ctx, time.Now().Add(-UnlinkGracePeriod))
+	if err != nil {
+		return fmt.Errorf("failed to purge unlinked users: %w", err)
+	}
+
+	if purged > 0 {
+		b.log.InfoContext(ctx, "Purged unlinked users", "count", purged)
+	}
+	return nil
+}
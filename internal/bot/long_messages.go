@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"gopkg.in/telebot.v4"
+)
+
+// sendLong sends the text, split into several messages when it is longer than Telegram allows.
+// The options apply to every message, except the keyboard, which is attached to the last one.
+func (b *Bot) sendLong(ctx telebot.Context, text string, opts ...interface{}) error {
+	chunks := telegramfmt.Split(text, telegramfmt.MaxMessageLength)
+	return b.sendChunks(ctx, chunks, opts)
+}
+
+// editLong edits the message to the text. When the text is longer than Telegram allows, the message
+// is edited to the first part and the rest is sent as new messages, the last one with the keyboard.
+func (b *Bot) editLong(ctx telebot.Context, text string, opts ...interface{}) error {
+	chunks := telegramfmt.Split(text, telegramfmt.MaxMessageLength)
+	if len(chunks) == 1 {
+		return ctx.Edit(text, opts...)
+	}
+
+	if err := ctx.Edit(chunks[0], withoutMarkup(opts)...); err != nil {
+		return err
+	}
+	return b.sendChunks(ctx, chunks[1:], opts)
+}
+
+// sendChunks sends the parts of a long text in order, the keyboard goes with the last part.
+func (b *Bot) sendChunks(ctx telebot.Context, chunks []string, opts []interface{}) error {
+	if len(chunks) == 1 {
+		return ctx.Send(chunks[0], opts...)
+	}
+
+	b.log.Debug("Splitting long message", "user", ctx.Sender().ID, "parts", len(chunks))
+
+	plain := withoutMarkup(opts)
+	for idx, chunk := range chunks {
+		partOpts := plain
+		if idx == len(chunks)-1 {
+			partOpts = opts
+		}
+		if err := ctx.Send(chunk, partOpts...); err != nil {
+			return fmt.Errorf("failed to send part %d of %d: %w", idx+1, len(chunks), err)
+		}
+	}
+
+	return nil
+}
+
+// withoutMarkup returns the send options without the keyboard.
+func withoutMarkup(opts []interface{}) []interface{} {
+	plain := make([]interface{}, 0, len(opts))
+	for _, opt := range opts {
+		switch opt := opt.(type) {
+		case *telebot.ReplyMarkup:
+			continue
+		case *telebot.SendOptions:
+			withoutKeyboard := *opt
+			withoutKeyboard.ReplyMarkup = nil
+			plain = append(plain, &withoutKeyboard)
+		default:
+			plain = append(plain, opt)
+		}
+	}
+
+	return plain
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/geo"
+	"gopkg.in/telebot.v4"
+)
+
+// tasksMapHandler asks the user for the file format of the map of their active tasks.
+func (b *Bot) tasksMapHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.log.Info("User requested tasks map", "user", ctx.Sender().ID)
+	b.metrics.CommandReceived.WithLabelValues("tasks_map").Inc()
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(geo.Formats))
+	for _, format := range geo.Formats {
+		label := b.t(timeoutCtx, ctx, "tasks.map.format."+string(format))
+		rows = append(rows, menu.Row(menu.Data(label, "tasks_map_export", string(format))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "tasks.map.choose_format"), menu)
+}
+
+// tasksMapExportHandler sends the user's active tasks with known coordinates as a map file
+// in the format carried in the callback data.
+func (b *Bot) tasksMapExportHandler(ctx telebot.Context) error {
+	userID := ctx.Sender().ID
+	format := geo.ParseFormat(ctx.Data())
+	b.metrics.CommandReceived.WithLabelValues("tasks_map_export").Inc()
+	_ = ctx.Respond()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	startTime := time.Now()
+	locations, err := b.tlrepo.GetActiveTaskLocations(timeoutCtx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("get_active_task_locations").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.Error("Failed to get active task locations", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	if len(locations) == 0 {
+		b.metrics.SentMessages.WithLabelValues("edit").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "tasks.map.none"))
+	}
+
+	points := make([]geo.Point, 0, len(locations))
+	for _, location := range locations {
+		description := location.Description
+		if location.Address != "" {
+			description = location.Address + "\n" + description
+		}
+		points = append(points, geo.Point{
+			Name:        fmt.Sprintf("#%d", location.ID),
+			Description: description,
+			Latitude:    location.Latitude,
+			Longitude:   location.Longitude,
+		})
+	}
+
+	buffer, err := geo.Export(format, points)
+	if err != nil {
+		b.log.Error("Failed to export tasks map", "error", err, "user", userID, "format", format)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Edit(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	document := &telebot.Document{
+		File:     telebot.FromReader(buffer),
+		FileName: fmt.Sprintf("tasks_%s.%s", time.Now().Format("2006-01-02"), format.Extension()),
+		MIME:     format.MIMEType(),
+		Caption:  b.tPlural(timeoutCtx, ctx, "tasks.map.caption", len(points)),
+	}
+
+	_ = ctx.Delete()
+	b.metrics.SentMessages.WithLabelValues("document").Inc()
+	return ctx.Send(document)
+}
@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// materialsCacheKey caches the catalog of materials offered when logging materials.
+	materialsCacheKey = "oracle:materials"
+	// materialsCacheTTL is how long changes of the catalog take to show up.
+	materialsCacheTTL = 10 * time.Minute
+	// maxMaterialQuantity is the largest quantity logged at once, to catch typos.
+	maxMaterialQuantity = 10000
+)
+
+// materials returns the catalog of materials, cached for materialsCacheTTL.
+func (b *Bot) materials(ctx context.Context) ([]models.Material, error) {
+	return cache.Fetch(ctx, b.cache, materialsCacheKey, materialsCacheTTL, b.marepo.GetMaterials)
+}
+
+// taskMaterialsHandler shows the materials logged on the task and the catalog to log another one.
+func (b *Bot) taskMaterialsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("task_materials").Inc()
+	taskID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.Error("Invalid task ID in callback", "error", err, "data", ctx.Data())
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	_ = ctx.Respond()
+
+	text, menu, err := b.materialsView(timeoutCtx, ctx, taskID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get task materials", "error", err, "task", taskID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// materialsView lists the materials logged on the task, with a button per material of the catalog.
+func (b *Bot) materialsView(
+	ctx context.Context,
+	tCtx telebot.Context,
+	taskID int,
+) (string, *telebot.ReplyMarkup, error) {
+	catalog, err := b.materials(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get materials: %w", err)
+	}
+	used, err := b.marepo.GetTaskMaterials(ctx, []int64{int64(taskID)})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get materials of task %d: %w", taskID, err)
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.tWithData(ctx, tCtx, "material.title", map[string]interface{}{"id": taskID}))
+	if len(used[int64(taskID)]) == 0 {
+		builder.WriteString("\n" + b.t(ctx, tCtx, "material.none"))
+	}
+	for _, material := range used[int64(taskID)] {
+		quantity := formatQuantity(material.Quantity)
+		builder.WriteString(fmt.Sprintf("\n- %s: %s %s", material.Name, quantity, material.Unit))
+	}
+	if len(catalog) == 0 {
+		return builder.String(), &telebot.ReplyMarkup{}, nil
+	}
+	builder.WriteString("\n\n" + b.t(ctx, tCtx, "material.choose"))
+
+	menu := &telebot.ReplyMarkup{}
+	data := strconv.Itoa(taskID)
+	rows := make([]telebot.Row, 0, len(catalog))
+	for _, material := range catalog {
+		label := fmt.Sprintf("%s, %s", material.Name, material.Unit)
+		rows = append(rows, menu.Row(menu.Data(label, "material_pick", data, strconv.Itoa(material.ID))))
+	}
+	menu.Inline(rows...)
+
+	return builder.String(), menu, nil
+}
+
+// materialPickHandler asks for the quantity of the picked material.
+func (b *Bot) materialPickHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("material_pick").Inc()
+	userID := ctx.Sender().ID
+	rawTask, rawMaterial, _ := strings.Cut(ctx.Data(), "|")
+	taskID, errTask := strconv.Atoi(rawTask)
+	materialID, errMaterial := strconv.Atoi(rawMaterial)
+	if errTask != nil || errMaterial != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid material callback", "data", ctx.Data(), "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	material, ok, err := b.findMaterial(timeoutCtx, materialID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get materials", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	if !ok {
+		// The material was taken out of the catalog since the buttons were sent.
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "material.gone")})
+	}
+	_ = ctx.Respond()
+
+	b.stateManager.Set(userID, UserState{
+		WaitingFor: stateAwaitingMaterialQuantity,
+		TaskID:     taskID,
+		TargetID:   int64(materialID),
+	})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "material.quantity_prompt", map[string]interface{}{
+		"name": material.Name,
+		"unit": material.Unit,
+	}))
+}
+
+// findMaterial returns the material of the catalog. The second value is false if it is not
+// in the catalog anymore.
+func (b *Bot) findMaterial(ctx context.Context, materialID int) (models.Material, bool, error) {
+	catalog, err := b.materials(ctx)
+	if err != nil {
+		return models.Material{}, false, err
+	}
+	idx := slices.IndexFunc(catalog, func(material models.Material) bool { return material.ID == materialID })
+	if idx < 0 {
+		return models.Material{}, false, nil
+	}
+
+	return catalog[idx], true, nil
+}
+
+// materialQuantityInputHandler logs the quantity typed by the user and shows the materials of the
+// task again, so the next one can be logged right away.
+func (b *Bot) materialQuantityInputHandler(
+	ctx context.Context,
+	tCtx telebot.Context,
+	userID int64,
+	state UserState,
+	input string,
+) error {
+	quantity, err := parseQuantity(input)
+	if err != nil {
+		b.stateManager.Set(userID, state)
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "material.invalid_quantity", map[string]interface{}{
+			"max": maxMaterialQuantity,
+		}))
+	}
+
+	materialID := int(state.TargetID)
+	if err = b.marepo.AddTaskMaterial(ctx, state.TaskID, materialID, quantity, userID); err != nil {
+		b.log.ErrorContext(ctx, "Failed to add task material", "error", err, "task", state.TaskID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	b.log.InfoContext(ctx, "User logged material", "user", userID, "task", state.TaskID,
+		"material", materialID, "quantity", quantity)
+
+	text, menu, err := b.materialsView(ctx, tCtx, state.TaskID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get task materials", "error", err, "task", state.TaskID)
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "material.saved"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "material.saved")+"\n\n"+text, menu)
+}
+
+// parseQuantity parses a positive quantity of up to maxMaterialQuantity, with a dot or a comma
+// as the decimal separator.
+func parseQuantity(input string) (float64, error) {
+	quantity, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(input), ",", "."), 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse quantity: %w", err)
+	}
+	if quantity <= 0 || quantity > maxMaterialQuantity {
+		return 0, fmt.Errorf("quantity %v is out of range", quantity)
+	}
+
+	return quantity, nil
+}
+
+// formatQuantity formats the quantity without trailing zeros.
+func formatQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
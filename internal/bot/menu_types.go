@@ -1,8 +1,7 @@
 package bot
 
 import (
-	"context"
-	"time"
+	"slices"
 )
 
 // MenuType represents different menu screens in the bot.
@@ -67,6 +66,18 @@ func (r *MenuRegistry) registerMainMenu() {
 		Layout:  []int{1, 1}, // 1 button per row
 		HasBack: false,
 		Buttons: []MenuButton{
+			{
+				TextKey:      "menu.shift_start",
+				Handler:      "shift_start",
+				RequiresAuth: true,
+				RequiresRole: (*Bot).OffShiftCheck,
+			},
+			{
+				TextKey:      "menu.shift_end",
+				Handler:      "shift_end",
+				RequiresAuth: true,
+				RequiresRole: (*Bot).OnShiftCheck,
+			},
 			{
 				TextKey:      "menu.tasks",
 				SubMenu:      MenuTasks,
@@ -95,7 +106,7 @@ func (r *MenuRegistry) registerTasksMenu() {
 	r.menus[MenuTasks] = &MenuDefinition{
 		Type:     MenuTasks,
 		TitleKey: "tasks.title",
-		Layout:   []int{1, 1}, // 1 button per row
+		Layout:   []int{1, 1, 1}, // 1 button per row
 		HasBack:  true,
 		Buttons: []MenuButton{
 			{
@@ -106,6 +117,10 @@ func (r *MenuRegistry) registerTasksMenu() {
 				TextKey: "menu.tasks_near",
 				Handler: "near_tasks",
 			},
+			{
+				TextKey: "menu.tasks_map",
+				Handler: "tasks_map",
+			},
 		},
 	}
 }
@@ -114,7 +129,7 @@ func (r *MenuRegistry) registerProfileMenu() {
 	r.menus[MenuProfile] = &MenuDefinition{
 		Type:     MenuProfile,
 		TitleKey: "profile.title",
-		Layout:   []int{1, 1, 1}, // 1 button per row
+		Layout:   []int{1, 1, 1, 1, 1, 1, 1, 1}, // 1 button per row
 		HasBack:  true,
 		Buttons: []MenuButton{
 			{
@@ -129,6 +144,27 @@ func (r *MenuRegistry) registerProfileMenu() {
 				TextKey: "menu.create_report",
 				Handler: "report",
 			},
+			{
+				TextKey: "menu.my_reports",
+				Handler: "my_reports",
+			},
+			{
+				TextKey: "menu.auto_report",
+				Handler: "auto_report",
+			},
+			{
+				TextKey: "menu.digest",
+				Handler: "digest",
+			},
+			{
+				TextKey: "menu.quiet_hours",
+				Handler: "quiet_hours",
+			},
+			{
+				TextKey:      "menu.switch_profile",
+				Handler:      "switch_profile",
+				RequiresRole: (*Bot).HasProfilesCheck,
+			},
 		},
 	}
 }
@@ -137,13 +173,17 @@ func (r *MenuRegistry) registerStatsMenu() {
 	r.menus[MenuStats] = &MenuDefinition{
 		Type:     MenuStats,
 		TitleKey: "statistic.title",
-		Layout:   []int{1, 1, 1}, // 1 button per row
+		Layout:   []int{1, 1, 1, 1, 1}, // 1 button per row
 		HasBack:  true,
 		Buttons: []MenuButton{
 			{
 				TextKey: "menu.today",
 				Handler: "statistic_today",
 			},
+			{
+				TextKey: "menu.this_week",
+				Handler: "statistic_week",
+			},
 			{
 				TextKey: "menu.this_month",
 				Handler: "statistic_month",
@@ -152,6 +192,10 @@ func (r *MenuRegistry) registerStatsMenu() {
 				TextKey: "menu.this_year",
 				Handler: "statistic_year",
 			},
+			{
+				TextKey: "menu.custom_range",
+				Handler: "statistic_range",
+			},
 		},
 	}
 }
@@ -184,13 +228,25 @@ func (r *MenuRegistry) registerAdminMenu() {
 	r.menus[MenuAdmin] = &MenuDefinition{
 		Type:     MenuAdmin,
 		TitleKey: "admin.panel.title",
-		Layout:   []int{1, 1, 1}, // 1 button per row
+		Layout:   []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, // 1 button per row
 		HasBack:  true,
 		Buttons: []MenuButton{
 			{
 				TextKey: "menu.broadcast",
 				Handler: "broadcast_initiate",
 			},
+			{
+				TextKey: "menu.broadcast_templates",
+				Handler: "broadcast_templates",
+			},
+			{
+				TextKey: "menu.team_stats",
+				Handler: "team_stats",
+			},
+			{
+				TextKey: "menu.team_performance",
+				Handler: "team_performance",
+			},
 			{
 				TextKey: "menu.geocoding_issues",
 				Handler: "geocoding_issues",
@@ -199,6 +255,42 @@ func (r *MenuRegistry) registerAdminMenu() {
 				TextKey: "menu.geocoding_reset",
 				Handler: "geocoding_reset",
 			},
+			{
+				TextKey: "menu.agreements_flush",
+				Handler: "agreements_flush",
+			},
+			{
+				TextKey: "menu.sla_config",
+				Handler: "sla_config",
+			},
+			{
+				TextKey: "menu.inactive_users",
+				Handler: "inactive_users",
+			},
+			{
+				TextKey: "menu.audit_log",
+				Handler: "audit_log",
+			},
+			{
+				TextKey: "menu.feature_flags",
+				Handler: "feature_flags",
+			},
+			{
+				TextKey: "menu.profile_access",
+				Handler: "profile_access",
+			},
+			{
+				TextKey: "menu.employee_view",
+				Handler: "employee_view",
+			},
+			{
+				TextKey: "menu.oncall_rota",
+				Handler: "oncall_rota",
+			},
+			{
+				TextKey: "menu.timesheet",
+				Handler: "timesheet",
+			},
 		},
 	}
 }
@@ -224,10 +316,10 @@ func (r *MenuRegistry) Get(menuType MenuType) *MenuDefinition {
 
 // IsAdminCheck is a helper method to check if user is admin.
 func (b *Bot) IsAdminCheck(userID int64) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := b.operationContext(opQuery)
 	defer cancel()
 
-	isAdmin, err := b.usrepo.IsAdmin(ctx, userID)
+	isAdmin, err := b.cachedIsAdmin(ctx, userID)
 	if err != nil {
 		b.log.Error("Failed to check admin status", "error", err, "userID", userID)
 		return false
@@ -236,3 +328,23 @@ func (b *Bot) IsAdminCheck(userID int64) bool {
 	b.log.Debug("Admin check result", "userID", userID, "isAdmin", isAdmin)
 	return isAdmin
 }
+
+// FindHandler returns the button running the handler with the given name, searching all menus.
+func (r *MenuRegistry) FindHandler(handler string) (MenuButton, bool) {
+	for _, menuDef := range r.menus {
+		for _, btn := range menuDef.Buttons {
+			if btn.Handler == handler {
+				return btn, true
+			}
+		}
+	}
+	return MenuButton{}, false
+}
+
+// IsAdminHandler reports whether the handler with the given name belongs to the admin panel.
+func (r *MenuRegistry) IsAdminHandler(handler string) bool {
+	menuDef := r.Get(MenuAdmin)
+	return menuDef != nil && slices.ContainsFunc(menuDef.Buttons, func(btn MenuButton) bool {
+		return btn.Handler == handler
+	})
+}
@@ -2,46 +2,270 @@ package bot
 
 import (
 	"context"
-	"log/slog"
+	"fmt"
+	"runtime/debug"
+	"strings"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/errorreport"
+	"github.com/redis/go-redis/v9"
 	"gopkg.in/telebot.v4"
 )
 
-// AuthMiddleware check if Telegram ID is linked to permitted user.
+// handlerNameKey is the telebot context key holding the name of the handler serving the update.
+const handlerNameKey = "handler_name"
+
+// MetricsMiddleware measures how long every update takes to handle, labeled by the handler,
+// and counts the Telegram API errors returned by handlers.
+func (b *Bot) MetricsMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		startTime := time.Now()
+		err := next(ctx)
+		b.metrics.HandlerDuration.WithLabelValues(handlerName(ctx)).Observe(time.Since(startTime).Seconds())
+
+		if err != nil {
+			b.recordTelegramError(err)
+		}
+		return err
+	}
+}
+
+// handlerName returns the name of the handler that served the update. Menu buttons and
+// text inputs are named by their dispatchers, other updates by the callback or update kind.
+// Free text and commands are not used as names to keep the label set small.
+func handlerName(ctx telebot.Context) string {
+	if name, ok := ctx.Get(handlerNameKey).(string); ok {
+		return name
+	}
+	if callback := ctx.Callback(); callback != nil && callback.Unique != "" {
+		return callback.Unique
+	}
+
+	message := ctx.Message()
+	switch {
+	case ctx.Query() != nil:
+		return "inline_query"
+	case ctx.Update().EditedMessage != nil:
+		return "live_location"
+	case message == nil:
+		return "other"
+	case strings.HasPrefix(message.Text, "/"):
+		return "command"
+	case message.Location != nil:
+		return "location"
+	case message.Photo != nil:
+		return "photo"
+	case message.Document != nil:
+		return "document"
+	default:
+		return "text"
+	}
+}
+
+// AuthMiddleware lets only users linked to an employee through. Callbacks of other users
+// are answered with an alert, other updates with a message asking to log in.
 func (b *Bot) AuthMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
 	return func(ctx telebot.Context) error {
 		userID := ctx.Sender().ID
 
-		b.log.With(
-			slog.String("op", "Bot.AuthMiddleware"),
-		)
+		timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+		defer cancel()
 
 		startTime := time.Now()
-		isAllowed, err := b.usrepo.IsUserAuthenticated(context.Background(), userID)
+		isAllowed, err := b.usrepo.IsUserAuthenticated(timeoutCtx, userID)
 		b.metrics.DBQueryDuration.WithLabelValues("is_user_authenticated").Observe(time.Since(startTime).Seconds())
+
+		text := ""
+		switch {
+		case err != nil:
+			b.log.ErrorContext(timeoutCtx, "Failed to authenticate telegram user from DB", "id", userID, "error", err)
+			text = b.t(timeoutCtx, ctx, "error.internal")
+		case !isAllowed:
+			b.log.InfoContext(timeoutCtx, "Access denied", "username", ctx.Sender().Username, "id", userID)
+			text = b.t(timeoutCtx, ctx, "auth.required")
+		default:
+			b.log.DebugContext(timeoutCtx, "Access granted", "username", ctx.Sender().Username, "id", userID)
+			return next(ctx)
+		}
+
+		if ctx.Callback() != nil {
+			b.metrics.SentMessages.WithLabelValues("respond").Inc()
+			return ctx.Respond(&telebot.CallbackResponse{Text: text, ShowAlert: true})
+		}
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(text)
+	}
+}
+
+// AdminMiddleware lets only admins through. Callbacks of other users are answered with
+// a notice, other updates with a message.
+func (b *Bot) AdminMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		userID := ctx.Sender().ID
+		if b.IsAdminCheck(userID) {
+			return next(ctx)
+		}
+
+		timeoutCtx, cancel := context.WithTimeout(traceContext(ctx), time.Second)
+		defer cancel()
+
+		b.log.WarnContext(timeoutCtx, "Non-admin user requested an admin route",
+			"user", userID, "handler", handlerName(ctx))
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		text := b.t(timeoutCtx, ctx, "general.use_buttons")
+		if ctx.Callback() != nil {
+			return ctx.Respond(&telebot.CallbackResponse{Text: text})
+		}
+		return ctx.Send(text)
+	}
+}
+
+// ErrorReportMiddleware tags the error reports of the update with the Telegram user and the update
+// kind, and reports the errors returned by handlers. Errors caused by the user, e.g. a blocked bot,
+// are not reported. Handlers must derive their contexts from traceContext for the tags to apply.
+func (b *Bot) ErrorReportMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		reportCtx := errorreport.WithTags(traceContext(ctx), map[string]string{"update": updateSpanName(ctx)})
+		if sender := ctx.Sender(); sender != nil {
+			reportCtx = errorreport.WithUser(reportCtx, sender.ID)
+		}
+		ctx.Set(traceContextKey, reportCtx)
+
+		err := next(ctx)
+		if err != nil && reportableError(err) {
+			errorreport.CaptureError(reportCtx, err, map[string]string{"handler": handlerName(ctx)})
+		}
+		return err
+	}
+}
+
+// reportableError reports whether the error returned by a handler points at a problem of the bot.
+func reportableError(err error) bool {
+	errType, _ := telegramErrorType(err)
+	switch errType {
+	case "blocked", "deactivated", "chat_not_found", "message_not_modified", "query_too_old":
+		return false
+	default:
+		return true
+	}
+}
+
+// RecoverMiddleware turns a panic of a handler into an error, so a bug in one handler does not
+// bring the bot down. The panic is logged with its stack and counted by handler.
+func (b *Bot) RecoverMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) (err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			name := handlerName(ctx)
+			b.metrics.HandlerPanics.WithLabelValues(name).Inc()
+			errorreport.CapturePanic(traceContext(ctx), recovered, map[string]string{"handler": name})
+			b.log.ErrorContext(traceContext(ctx), "Handler panicked",
+				"handler", name, "panic", recovered, "stack", string(debug.Stack()))
+			err = fmt.Errorf("handler %s panicked: %v", name, recovered)
+
+			if ctx.Callback() != nil {
+				_ = ctx.Respond()
+			}
+			timeoutCtx, cancel := context.WithTimeout(context.WithoutCancel(traceContext(ctx)), time.Second)
+			defer cancel()
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			_ = ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+		}()
+
+		return next(ctx)
+	}
+}
+
+// rateLimitScript implements a token bucket stored in a Redis hash. Tokens are refilled
+// lazily from the time elapsed since the previous request, so no background job is needed.
+// It returns 1 when the request is allowed and 0 when the bucket is empty.
+var rateLimitScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+
+return allowed
+`)
+
+// RateLimitMiddleware throttles users who send requests faster than the configured
+// token bucket allows. Throttled users get a polite notice at most once per cooldown
+// period, the rest of their requests are silently dropped. If Redis is unavailable,
+// requests are let through.
+func (b *Bot) RateLimitMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		if ctx.Sender() == nil {
+			return next(ctx)
+		}
+		// Live location updates arrive as edited messages every few seconds and are
+		// throttled by liveLocationHandler itself.
+		if ctx.Update().EditedMessage != nil {
+			return next(ctx)
+		}
+		userID := ctx.Sender().ID
+
+		timeoutCtx, cancel := context.WithTimeout(traceContext(ctx), time.Second)
+		defer cancel()
+
+		allowed, err := rateLimitScript.Run(
+			timeoutCtx,
+			b.redisClient,
+			[]string{fmt.Sprintf("oracle:ratelimit:%d", userID)},
+			b.rateLimit.Rate,
+			b.rateLimit.Burst,
+			time.Now().UnixMilli(),
+		).Int()
 		if err != nil {
-			b.log.Error("Failed to authenticate telegram user from DB", "id", userID, "error", err)
-			b.metrics.SentMessages.WithLabelValues("text").Inc()
-			_ = ctx.Send("Access verification error.")
+			b.log.WarnContext(timeoutCtx, "Failed to check rate limit, allowing request", "error", err, "user", userID)
+			return next(ctx)
+		}
+		if allowed == 1 {
+			return next(ctx)
 		}
 
-		if !isAllowed {
-			b.log.Info("Access denied", "username", ctx.Sender().Username, "id", userID)
+		updateType := "message"
+		if ctx.Callback() != nil {
+			updateType = "callback"
+		}
+		b.metrics.Throttled.WithLabelValues(updateType).Inc()
+		b.log.InfoContext(timeoutCtx, "User request throttled", "user", userID, "type", updateType)
+
+		// Notify the user only once per cooldown, otherwise the notices become spam themselves.
+		const noticeCooldown = 10 * time.Second
+		notify, err := b.redisClient.SetNX(
+			timeoutCtx, fmt.Sprintf("oracle:ratelimit:notified:%d", userID), 1, noticeCooldown,
+		).Result()
+		if err != nil || !notify {
 			if ctx.Callback() != nil {
-				b.metrics.SentMessages.WithLabelValues("respond").Inc()
-				_ = ctx.Respond(&telebot.CallbackResponse{
-					Text:      "Access denied. Please log in.",
-					ShowAlert: true,
-				})
-			} else {
-				b.metrics.SentMessages.WithLabelValues("text").Inc()
-				_ = ctx.Send("Access to this function is denied. Please log in via /start.")
+				return ctx.Respond()
 			}
 			return nil
 		}
 
-		b.log.Debug("Access granted", "username", ctx.Sender().Username, "id", userID)
-		return next(ctx)
+		text := b.t(timeoutCtx, ctx, "general.slow_down")
+		if ctx.Callback() != nil {
+			b.metrics.SentMessages.WithLabelValues("respond").Inc()
+			return ctx.Respond(&telebot.CallbackResponse{Text: text})
+		}
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(text)
 	}
 }
@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/telebot.v4"
+)
+
+// defaultNearRadius is the search radius in kilometers used until the user picks another one.
+const defaultNearRadius = 15
+
+// nearRadii are the search radii in kilometers offered below the nearby task list.
+var nearRadii = []int{5, 15, 30, 50}
+
+// liveLocationRefreshInterval is the minimum time between two refreshes of the nearby task list
+// of a user sharing a live location. Telegram sends location updates far more often.
+const liveLocationRefreshInterval = 30 * time.Second
+
+// liveLocation links a shared live location to the nearby task list refreshed from it.
+type liveLocation struct {
+	LocationMessageID int
+	List              *telebot.Message
+	Expires           time.Time
+	Refreshed         time.Time
+}
+
+// liveLocationRegistry keeps the live locations users are sharing, one per user.
+type liveLocationRegistry struct {
+	mu        sync.Mutex
+	locations map[int64]liveLocation
+}
+
+func newLiveLocationRegistry() *liveLocationRegistry {
+	return &liveLocationRegistry{locations: make(map[int64]liveLocation)}
+}
+
+// add starts tracking the user's live location, replacing a previous one.
+func (r *liveLocationRegistry) add(userID int64, location liveLocation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locations[userID] = location
+}
+
+// refresh reports whether the list linked to the live location message should be refreshed now,
+// and if so marks it as refreshed. Expired live locations are forgotten.
+func (r *liveLocationRegistry) refresh(userID int64, messageID int, now time.Time) (*telebot.Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	location, ok := r.locations[userID]
+	if !ok || location.LocationMessageID != messageID {
+		return nil, false
+	}
+	if now.After(location.Expires) {
+		delete(r.locations, userID)
+		return nil, false
+	}
+	if now.Sub(location.Refreshed) < liveLocationRefreshInterval {
+		return nil, false
+	}
+
+	location.Refreshed = now
+	r.locations[userID] = location
+	return location.List, true
+}
+
+// locationHandler processes the user's location sent via a message.
+// It retrieves tasks within the user's last chosen radius of the location
+// and sends back a response with the nearest tasks or an appropriate
+// message if no tasks are found, together with buttons to change the radius.
+func (b *Bot) locationHandler(ctx telebot.Context) error {
+	userID := ctx.Sender().ID
+	latitude := ctx.Message().Location.Lat
+	longitude := ctx.Message().Location.Lng
+	state, ok := b.stateManager.Get(userID)
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.log.Info("User sent geolocation", "user", userID, "latitude", latitude, "longitude", longitude)
+
+	if ok && state.WaitingFor == stateAwaitingTaskLocation {
+		// Only admins can get into this state, from the geocoding issues view.
+		return b.taskLocationHandler(timeoutCtx, ctx, userID, state.TaskID, ctx.Message().Location)
+	}
+
+	if ok && state.WaitingFor == stateAwaitingShiftLocation {
+		return b.shiftLocationHandler(timeoutCtx, ctx, userID, state, ctx.Message().Location)
+	}
+
+	if ok && state.WaitingFor == stateAwaitingLocation {
+		radius := b.nearRadius(timeoutCtx, userID)
+
+		text, menu, err := b.nearTasksView(timeoutCtx, ctx, latitude, longitude, radius)
+		if err != nil {
+			b.log.Error("Failed to get nearest tasks", "error", err)
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+		}
+
+		livePeriod := ctx.Message().Location.LivePeriod
+		if livePeriod == 0 {
+			b.metrics.SentMessages.WithLabelValues("text").Inc()
+			return ctx.Send(text, menu)
+		}
+
+		// The list of a live location is refreshed while the user moves, see liveLocationHandler.
+		text += "\n\n" + b.t(timeoutCtx, ctx, "tasks.near.live")
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		list, err := b.bot.Send(ctx.Chat(), text, menu)
+		if err != nil {
+			return fmt.Errorf("failed to send nearby tasks: %w", err)
+		}
+
+		now := time.Now()
+		b.liveLocations.add(userID, liveLocation{
+			LocationMessageID: ctx.Message().ID,
+			List:              list,
+			Expires:           ctx.Message().Time().Add(time.Duration(livePeriod) * time.Second),
+			Refreshed:         now,
+		})
+		return nil
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "tasks.near.unsolicited"))
+}
+
+// liveLocationHandler refreshes the nearby task list while the user shares a live location.
+// Telegram delivers live location updates as edited messages; updates of other messages and
+// updates arriving sooner than liveLocationRefreshInterval after the last refresh are ignored.
+func (b *Bot) liveLocationHandler(ctx telebot.Context) error {
+	location := ctx.Message().Location
+	if location == nil {
+		return nil
+	}
+
+	userID := ctx.Sender().ID
+	list, ok := b.liveLocations.refresh(userID, ctx.Message().ID, time.Now())
+	if !ok {
+		return nil
+	}
+	b.metrics.CommandReceived.WithLabelValues("live_location").Inc()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	text, menu, err := b.nearTasksView(timeoutCtx, ctx, location.Lat, location.Lng, b.nearRadius(timeoutCtx, userID))
+	if err != nil {
+		b.log.Error("Failed to refresh nearest tasks", "error", err, "user", userID)
+		return nil
+	}
+	text += "\n\n" + b.t(timeoutCtx, ctx, "tasks.near.live")
+
+	// The list often stays the same while the user stands still.
+	if err = b.editIfChanged(timeoutCtx, list, text, menu); err != nil {
+		b.log.Warn("Failed to edit nearest tasks", "error", err, "user", userID)
+	}
+	return nil
+}
+
+// nearRadiusHandler re-runs the nearby task search with the radius chosen by the user
+// and edits the result list. The choice is remembered for the following searches.
+func (b *Bot) nearRadiusHandler(ctx telebot.Context) error {
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("near_radius").Inc()
+	_ = ctx.Respond()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	radius, latitude, longitude, err := parseNearRadiusData(ctx.Data())
+	if err != nil {
+		b.log.Error("Invalid near radius callback", "error", err, "data", ctx.Data())
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	if err = b.redisClient.Set(timeoutCtx, nearRadiusKey(userID), radius, 0).Err(); err != nil {
+		b.log.Warn("Failed to save near radius", "error", err, "user", userID)
+		b.metrics.CacheOps.WithLabelValues("set", "error").Inc()
+	} else {
+		b.metrics.CacheOps.WithLabelValues("set", "success").Inc()
+	}
+
+	text, menu, err := b.nearTasksView(timeoutCtx, ctx, latitude, longitude, radius)
+	if err != nil {
+		b.log.Error("Failed to get nearest tasks", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	return b.editIfChanged(timeoutCtx, ctx.Message(), text, menu)
+}
+
+// nearTasksView finds the tasks within the radius of the location and renders them as
+// a grid of task buttons followed by a row of radius buttons.
+func (b *Bot) nearTasksView(
+	ctx context.Context,
+	tCtx telebot.Context,
+	latitude, longitude float32,
+	radius int,
+) (string, *telebot.ReplyMarkup, error) {
+	startTime := time.Now()
+	tasks, err := b.tarepo.GetTasksInRadius(ctx, latitude, longitude, radius)
+	b.metrics.DBQueryDuration.WithLabelValues("get_tasks_in_radius").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get tasks in radius: %w", err)
+	}
+
+	// creates dynamic inline keyboard
+	var rows [][]telebot.InlineButton
+	buttons := make([]telebot.InlineButton, 0, 3)
+
+	for idx, task := range tasks {
+		btn := telebot.InlineButton{
+			Unique: "task_details",
+			Text:   fmt.Sprintf("#%d", task.ID),
+			Data:   strconv.Itoa(task.ID),
+		}
+		buttons = append(buttons, btn)
+		if (idx+1)%3 == 0 || idx == len(tasks)-1 {
+			rows = append(rows, buttons)
+			buttons = nil
+		}
+	}
+
+	radiusRow := make([]telebot.InlineButton, 0, len(nearRadii))
+	for _, option := range nearRadii {
+		key := "tasks.near.button.radius"
+		if option == radius {
+			key = "tasks.near.button.radius_selected"
+		}
+		radiusRow = append(radiusRow, telebot.InlineButton{
+			Unique: "near_radius",
+			Text:   b.tWithData(ctx, tCtx, key, map[string]interface{}{"radius": option}),
+			Data:   fmt.Sprintf("%d|%f|%f", option, latitude, longitude),
+		})
+	}
+	rows = append(rows, radiusRow)
+	menu := &telebot.ReplyMarkup{InlineKeyboard: rows}
+
+	if len(tasks) == 0 {
+		return b.tWithData(ctx, tCtx, "tasks.near.none", map[string]interface{}{"radius": radius}), menu, nil
+	}
+
+	return b.tWithData(ctx, tCtx, "tasks.near.title", map[string]interface{}{"radius": radius}), menu, nil
+}
+
+// nearRadius returns the user's last chosen search radius, or the default one.
+func (b *Bot) nearRadius(ctx context.Context, userID int64) int {
+	radius, err := b.redisClient.Get(ctx, nearRadiusKey(userID)).Int()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			b.log.Warn("Failed to get near radius", "error", err, "user", userID)
+			b.metrics.CacheOps.WithLabelValues("get", "error").Inc()
+		}
+		return defaultNearRadius
+	}
+
+	b.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
+	return radius
+}
+
+// nearRadiusKey returns the Redis key storing the user's chosen search radius.
+func nearRadiusKey(userID int64) string {
+	return fmt.Sprintf("oracle:near_radius:%d", userID)
+}
+
+// parseNearRadiusData parses the "radius|latitude|longitude" callback data of a radius button.
+func parseNearRadiusData(data string) (int, float32, float32, error) {
+	const parts = 3
+	fields := strings.Split(data, "|")
+	if len(fields) != parts {
+		return 0, 0, 0, fmt.Errorf("unexpected near radius data %q", data)
+	}
+
+	radius, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid radius: %w", err)
+	}
+	latitude, err := strconv.ParseFloat(fields[1], 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid latitude: %w", err)
+	}
+	longitude, err := strconv.ParseFloat(fields[2], 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid longitude: %w", err)
+	}
+
+	return radius, float32(latitude), float32(longitude), nil
+}
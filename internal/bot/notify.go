@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// NotifyAdmins sends the text to every admin in the background. It is used by the webhooks
+// of external integrations.
+func (b *Bot) NotifyAdmins(ctx context.Context, text string) error {
+	admins, err := b.cachedAdmins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get admins: %w", err)
+	}
+
+	userIDs := make([]int64, 0, len(admins))
+	for _, admin := range admins {
+		userIDs = append(userIDs, admin.TelegramID)
+	}
+	b.notify(userIDs, text)
+	return nil
+}
+
+// NotifyUsers sends the text to every linked user in the background.
+func (b *Bot) NotifyUsers(ctx context.Context, text string) error {
+	userIDs, err := b.usrepo.GetAllTgUserIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	b.notify(userIDs, text)
+	return nil
+}
+
+// notify sends the plain text to the users one by one, respecting the Telegram rate limits.
+func (b *Bot) notify(userIDs []int64, text string) {
+	delivering := b.goTracked(func() {
+		ctx := b.baseCtx
+		for _, userID := range userIDs {
+			if _, err := b.bot.Send(telebot.ChatID(userID), text); err != nil {
+				b.log.WarnContext(ctx, "Failed to send webhook message", "user", userID, "error", err)
+				b.recordSendFailure(ctx, userID, sendSourceWebhook, err)
+			}
+			const telegramRateTimeout = 100 * time.Millisecond
+			time.Sleep(telegramRateTimeout)
+		}
+	})
+	if !delivering {
+		b.log.Warn("Bot is shutting down, webhook message is not delivered", "users", len(userIDs))
+	}
+}
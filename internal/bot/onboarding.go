@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"context"
+	"slices"
+
+	"gopkg.in/telebot.v4"
+)
+
+// onboardingStep is a step of the guided tour shown after the first login. The tour moves
+// through onboardingSteps in order, every step is a message edited in place.
+type onboardingStep string
+
+const (
+	onboardingWelcome  onboardingStep = "welcome"
+	onboardingTasks    onboardingStep = "tasks"
+	onboardingNear     onboardingStep = "near"
+	onboardingReports  onboardingStep = "reports"
+	onboardingLanguage onboardingStep = "language"
+	// onboardingDone follows the last step, the tour is completed.
+	onboardingDone onboardingStep = "done"
+)
+
+// onboardingSteps are the steps of the tour in the order they are shown.
+var onboardingSteps = []onboardingStep{
+	onboardingWelcome,
+	onboardingTasks,
+	onboardingNear,
+	onboardingReports,
+	onboardingLanguage,
+}
+
+// next returns the step following s, or onboardingDone after the last step and for unknown steps.
+func (s onboardingStep) next() onboardingStep {
+	i := slices.Index(onboardingSteps, s)
+	if i < 0 || i == len(onboardingSteps)-1 {
+		return onboardingDone
+	}
+	return onboardingSteps[i+1]
+}
+
+// startOnboarding sends the first step of the tour while the onboarding feature flag is on for
+// the user, unless they have seen it before, e.g. before logging out. Failures are only logged,
+// as the user is logged in either way.
+func (b *Bot) startOnboarding(ctx context.Context, bCtx telebot.Context, userID int64) {
+	if !b.featureEnabled(ctx, userID, flagOnboarding) {
+		return
+	}
+
+	started, err := b.onrepo.StartOnboarding(ctx, userID, string(onboardingWelcome))
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to start onboarding", "error", err, "user", userID)
+		return
+	}
+	if !started {
+		return
+	}
+
+	lang := b.getUserLanguage(ctx, bCtx)
+	text, markup := b.onboardingText(lang, onboardingWelcome), b.onboardingMarkup(lang, onboardingWelcome)
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	if err = bCtx.Send(text, markup); err != nil {
+		b.log.WarnContext(ctx, "Failed to send onboarding", "error", err, "user", userID)
+	}
+}
+
+// onboardingNextHandler moves the tour to the step after the one in the callback data. Buttons
+// of a step the user has already left are ignored, so double taps do not skip steps.
+func (b *Bot) onboardingNextHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("onboarding_next").Inc()
+	_ = ctx.Respond()
+
+	userID := ctx.Sender().ID
+	step := onboardingStep(ctx.Data())
+	next := step.next()
+
+	advanced, err := b.onrepo.AdvanceOnboarding(timeoutCtx, userID, string(step), string(next))
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to advance onboarding", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if !advanced {
+		b.log.DebugContext(timeoutCtx, "Onboarding step already left", "user", userID, "step", step)
+		return nil
+	}
+
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	if next == onboardingDone {
+		return b.finishOnboarding(timeoutCtx, ctx, lang, "onboarding.done")
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.onboardingText(lang, next), b.onboardingMarkup(lang, next))
+}
+
+// onboardingSkipHandler ends the tour at any step.
+func (b *Bot) onboardingSkipHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("onboarding_skip").Inc()
+	_ = ctx.Respond()
+
+	return b.finishOnboarding(timeoutCtx, ctx, b.getUserLanguage(timeoutCtx, ctx), "onboarding.skipped")
+}
+
+// finishOnboarding completes the tour and replaces the tour message with the closing text.
+func (b *Bot) finishOnboarding(ctx context.Context, bCtx telebot.Context, lang, key string) error {
+	userID := bCtx.Sender().ID
+	if err := b.onrepo.CompleteOnboarding(ctx, userID); err != nil {
+		b.log.ErrorContext(ctx, "Failed to complete onboarding", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return bCtx.Send(b.localizer.Get(lang, "error.internal"))
+	}
+
+	b.log.InfoContext(ctx, "User completed onboarding", "user", userID, "closing", key)
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return bCtx.Edit(b.localizer.Get(lang, key))
+}
+
+// onboardingText renders the step with the progress of the tour.
+func (b *Bot) onboardingText(lang string, step onboardingStep) string {
+	progress := b.localizer.GetWithData(lang, "onboarding.progress", map[string]interface{}{
+		"current": slices.Index(onboardingSteps, step) + 1,
+		"total":   len(onboardingSteps),
+	})
+	return progress + "\n\n" + b.localizer.Get(lang, "onboarding.step."+string(step))
+}
+
+// onboardingMarkup builds the buttons of the step. The language step also offers the languages,
+// the last step finishes the tour instead of moving on.
+func (b *Bot) onboardingMarkup(lang string, step onboardingStep) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	var rows []telebot.Row
+	if step == onboardingLanguage {
+		rows = append(rows, menu.Row(
+			menu.Data(b.localizer.Get(lang, "language.button.english"), "language_en"),
+			menu.Data(b.localizer.Get(lang, "language.button.ukrainian"), "language_uk"),
+			menu.Data(b.localizer.Get(lang, "language.button.polish"), "language_pl"),
+		))
+	}
+
+	nextKey := "onboarding.button.next"
+	if step.next() == onboardingDone {
+		nextKey = "onboarding.button.finish"
+	}
+	rows = append(rows, menu.Row(
+		menu.Data(b.localizer.Get(lang, "onboarding.button.skip"), "onboarding_skip"),
+		menu.Data(b.localizer.Get(lang, nextKey), "onboarding_next", string(step)),
+	))
+
+	menu.Inline(rows...)
+	return menu
+}
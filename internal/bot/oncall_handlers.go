@@ -0,0 +1,285 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// onCallWeekDays is how many days an on-call week lasts.
+	onCallWeekDays = 7
+	// onCallWeeksShown is the number of weeks of the rota shown to admins.
+	onCallWeeksShown = 8
+	// onCallRemove in place of the name removes the week from the rota.
+	onCallRemove = "-"
+)
+
+// onCallLine is a line of the rota entered by an admin: the first day of the week and the short
+// name of the employee on duty, or onCallRemove.
+type onCallLine struct {
+	Number    int
+	WeekStart time.Time
+	Name      string
+}
+
+// onCallDay returns the day of the rota the moment belongs to, in the default time zone.
+func (b *Bot) onCallDay(now time.Time) time.Time {
+	year, month, day := now.In(b.defaultLocation()).Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// currentOnCall returns the user on duty now. The second value is false if nobody is on duty,
+// the user on duty logged out or the rota cannot be read, alerts are then delivered to the
+// admins only.
+func (b *Bot) currentOnCall(ctx context.Context) (models.OnCallWeek, bool) {
+	week, ok, err := b.ocrepo.GetOnCallWeek(ctx, b.onCallDay(time.Now()))
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get the user on duty", "error", err)
+		return models.OnCallWeek{}, false
+	}
+
+	return week, ok
+}
+
+// onCallHandler shows who is on duty now and who takes over next.
+func (b *Bot) onCallHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("oncall").Inc()
+	day := b.onCallDay(time.Now())
+	weeks, err := b.ocrepo.GetOnCallWeeks(timeoutCtx, day, 2)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get on-call rota", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	var builder strings.Builder
+	if len(weeks) > 0 && !weeks[0].WeekStart.After(day) {
+		builder.WriteString(b.tWithData(timeoutCtx, ctx, "oncall.now", map[string]interface{}{
+			"name":  onCallName(weeks[0]),
+			"until": weeks[0].WeekStart.AddDate(0, 0, onCallWeekDays).Format(time.DateOnly),
+		}))
+		weeks = weeks[1:]
+	} else {
+		builder.WriteString(b.t(timeoutCtx, ctx, "oncall.nobody"))
+	}
+	if len(weeks) > 0 {
+		builder.WriteString("\n" + b.tWithData(timeoutCtx, ctx, "oncall.next", map[string]interface{}{
+			"name": onCallName(weeks[0]),
+			"from": weeks[0].WeekStart.Format(time.DateOnly),
+		}))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.sendLong(ctx, builder.String())
+}
+
+// onCallName returns the name the user on duty is shown with.
+func onCallName(week models.OnCallWeek) string {
+	if week.ShortName != "" {
+		return week.ShortName
+	}
+	return fmt.Sprintf("#%d", week.TelegramID)
+}
+
+// onCallRotaHandler shows the rota to the admin and waits for the weeks to change, typed or
+// uploaded as a CSV file.
+func (b *Bot) onCallRotaHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.log.Info("Admin requested on-call rota", "user", userID)
+
+	rota, err := b.onCallRota(timeoutCtx, ctx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get on-call rota", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingOnCall})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(rota + "\n\n" + b.t(timeoutCtx, ctx, "admin.oncall.prompt"))
+}
+
+// onCallRota lists the weeks of the rota which are not over yet.
+func (b *Bot) onCallRota(ctx context.Context, tCtx telebot.Context) (string, error) {
+	weeks, err := b.ocrepo.GetOnCallWeeks(ctx, b.onCallDay(time.Now()), onCallWeeksShown)
+	if err != nil {
+		return "", fmt.Errorf("failed to get on-call weeks: %w", err)
+	}
+	if len(weeks) == 0 {
+		return b.t(ctx, tCtx, "admin.oncall.empty"), nil
+	}
+
+	var builder strings.Builder
+	builder.WriteString(b.t(ctx, tCtx, "admin.oncall.title"))
+	for _, week := range weeks {
+		builder.WriteString(fmt.Sprintf("\n%s — %s", week.WeekStart.Format(time.DateOnly), onCallName(week)))
+	}
+
+	return builder.String(), nil
+}
+
+// onCallDocumentHandler reads the rota from the CSV file uploaded by the admin.
+func (b *Bot) onCallDocumentHandler(ctx context.Context, tCtx telebot.Context, userID int64) error {
+	content, err := b.downloadFile(&tCtx.Message().Document.File)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to download on-call rota", "error", err, "user", userID)
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingOnCall})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "admin.oncall.file_error"))
+	}
+
+	return b.onCallInputHandler(ctx, tCtx, userID, string(content))
+}
+
+// onCallInputHandler saves the weeks of the rota entered by the admin. Nothing is saved unless
+// every line is valid, the admin gets the invalid lines to fix instead.
+func (b *Bot) onCallInputHandler(ctx context.Context, tCtx telebot.Context, userID int64, input string) error {
+	lines, invalid := parseOnCallLines(input)
+
+	var weeks []models.OnCallWeek
+	var removed []time.Time
+	for _, line := range lines {
+		if line.Name == onCallRemove {
+			removed = append(removed, line.WeekStart)
+			continue
+		}
+		teammate, ok, err := b.findOnCallEmployee(ctx, line.Name)
+		if err != nil {
+			b.log.ErrorContext(ctx, "Failed to search users", "error", err, "user", userID)
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+		}
+		if !ok {
+			invalid = append(invalid, line.Number)
+			continue
+		}
+		weeks = append(weeks, models.OnCallWeek{WeekStart: line.WeekStart, TelegramID: teammate.TelegramID})
+	}
+
+	if len(lines) == 0 && len(invalid) == 0 {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingOnCall})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "admin.oncall.prompt"))
+	}
+	if len(invalid) > 0 {
+		slices.Sort(invalid)
+		numbers := make([]string, 0, len(invalid))
+		for _, number := range invalid {
+			numbers = append(numbers, strconv.Itoa(number))
+		}
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingOnCall})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.oncall.invalid", map[string]interface{}{
+			"lines": strings.Join(numbers, ", "),
+		}))
+	}
+
+	if err := b.ocrepo.SetOnCallWeeks(ctx, weeks, userID); err != nil {
+		b.log.ErrorContext(ctx, "Failed to save on-call weeks", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	for _, weekStart := range removed {
+		if err := b.ocrepo.DeleteOnCallWeek(ctx, weekStart); err != nil {
+			b.log.ErrorContext(ctx, "Failed to delete on-call week", "error", err)
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+		}
+	}
+	b.recordAdminAction(ctx, userID, repository.AuditOnCallUpdate, map[string]interface{}{
+		"weeks":   len(weeks),
+		"removed": len(removed),
+	})
+	b.log.InfoContext(ctx, "On-call rota updated", "admin", userID, "weeks", len(weeks), "removed", len(removed))
+
+	rota, err := b.onCallRota(ctx, tCtx)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get on-call rota", "error", err)
+		rota = ""
+	}
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(strings.TrimSpace(b.t(ctx, tCtx, "admin.oncall.saved") + "\n\n" + rota))
+}
+
+// findOnCallEmployee finds the user by the short name of their employee. The second value is
+// false if no user or more than one matches.
+func (b *Bot) findOnCallEmployee(ctx context.Context, name string) (models.Teammate, bool, error) {
+	// Telegram ID 0 leaves nobody out.
+	teammates, err := b.horepo.SearchTeammates(ctx, 0, name, teammateSearchLimit)
+	if err != nil {
+		return models.Teammate{}, false, fmt.Errorf("failed to search teammates: %w", err)
+	}
+	for _, teammate := range teammates {
+		if strings.EqualFold(teammate.ShortName, name) {
+			return teammate, true, nil
+		}
+	}
+	if len(teammates) == 1 {
+		return teammates[0], true, nil
+	}
+
+	return models.Teammate{}, false, nil
+}
+
+// parseOnCallLines parses the rota lines, e.g. "2025-06-02 Doe J." or "2025-06-02,Doe J.".
+// It returns the valid lines and the numbers of the invalid ones. A first line which is not
+// valid is taken as the header of a CSV file.
+func parseOnCallLines(input string) ([]onCallLine, []int) {
+	var lines []onCallLine
+	var invalid []int
+	for idx, raw := range strings.Split(strings.ReplaceAll(input, "\r\n", "\n"), "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		sep := strings.IndexAny(raw, ",; \t")
+		var weekStart time.Time
+		var err error
+		if sep > 0 {
+			weekStart, err = time.Parse(time.DateOnly, strings.Trim(raw[:sep], `"`))
+		}
+		name := ""
+		if sep > 0 && err == nil {
+			name = strings.Trim(strings.TrimSpace(raw[sep+1:]), `",;`)
+		}
+		if name == "" {
+			if idx > 0 {
+				invalid = append(invalid, idx+1)
+			}
+			continue
+		}
+		lines = append(lines, onCallLine{Number: idx + 1, WeekStart: weekStart, Name: name})
+	}
+
+	return lines, invalid
+}
+
+// withOnCall adds the user on duty to the recipients of an alert, unless they already get it.
+func withOnCall(recipients []models.BotUser, onCall models.OnCallWeek, ok bool) []models.BotUser {
+	if !ok {
+		return recipients
+	}
+	for _, recipient := range recipients {
+		if recipient.TelegramID == onCall.TelegramID {
+			return recipients
+		}
+	}
+
+	return append(recipients, models.BotUser{TelegramID: onCall.TelegramID})
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/olympus-protos/gen/go/scraper/olympus"
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+// OutboxDispatchInterval is how often the outbox is checked for comments due for delivery.
+const OutboxDispatchInterval = 30 * time.Second
+
+const (
+	outboxBatchSize   = 20               // comments delivered per dispatcher run
+	outboxLease       = 2 * time.Minute  // time a claimed comment is hidden from other dispatchers
+	outboxBaseDelay   = 30 * time.Second // delay before the first retry, doubled after each failure
+	outboxMaxDelay    = 30 * time.Minute // upper bound of the delay between retries
+	outboxMaxAttempts = 12               // failed deliveries after which the comment is dropped
+	hermesCallTimeout = 10 * time.Second // timeout of a single AddComment call
+)
+
+// outboxBackoff returns the delay before the next delivery of a comment which has failed
+// the given number of times.
+func outboxBackoff(failures int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < failures && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, outboxMaxDelay)
+}
+
+// deliverOutboxComment sends the queued comment to Hermes. On success the comment is marked
+// as delivered and the cached task details get the new comments, otherwise the next attempt
+// is scheduled, or the comment is dropped once it ran out of attempts. It returns the error
+// of Hermes, and whether the comment was dropped.
+func (b *Bot) deliverOutboxComment(ctx context.Context, comment models.OutboxComment) (bool, error) {
+	callCtx, cancel := context.WithTimeout(ctx, hermesCallTimeout)
+	defer cancel()
+
+	resp, err := b.hermesClient.AddComment(
+		callCtx,
+		&olympus.AddCommentRequest{TaskId: comment.TaskID, Author: comment.Author, Text: comment.Text},
+	)
+	if err != nil {
+		failures := comment.Attempts + 1
+		if failures >= outboxMaxAttempts {
+			b.metrics.CommentOutbox.WithLabelValues("failed").Inc()
+			if errFail := b.obrepo.FailComment(ctx, comment.ID, err.Error()); errFail != nil {
+				b.log.ErrorContext(ctx, "Failed to drop outbox comment", "id", comment.ID, "error", errFail)
+			}
+			return true, err
+		}
+
+		b.metrics.CommentOutbox.WithLabelValues("retried").Inc()
+		next := time.Now().Add(outboxBackoff(failures))
+		if errRetry := b.obrepo.RescheduleComment(ctx, comment.ID, next, err.Error()); errRetry != nil {
+			b.log.ErrorContext(ctx, "Failed to reschedule outbox comment", "id", comment.ID, "error", errRetry)
+		}
+		return false, err
+	}
+
+	b.metrics.CommentOutbox.WithLabelValues("delivered").Inc()
+	// A failure here only means the comment is delivered once more after the lease expires.
+	if err = b.obrepo.MarkCommentDelivered(ctx, comment.ID); err != nil {
+		b.log.ErrorContext(ctx, "Failed to mark outbox comment as delivered", "id", comment.ID, "error", err)
+	}
+	b.goTracked(func() { b.updateTaskCommentsInCache(b.baseCtx, comment.TaskID, resp.GetComments()) })
+
+	return false, nil
+}
+
+// DispatchCommentOutbox retries the delivery of comments which could not be sent to Hermes
+// right away, and lets their authors know once a comment lands or is dropped.
+func (b *Bot) DispatchCommentOutbox(ctx context.Context) error {
+	comments, err := b.obrepo.ClaimDueComments(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return fmt.Errorf("failed to claim outbox comments: %w", err)
+	}
+
+	delivered, failed := 0, 0
+	for _, comment := range comments {
+		if ctx.Err() != nil {
+			return fmt.Errorf("outbox dispatch interrupted: %w", ctx.Err())
+		}
+
+		dropped, errDeliver := b.deliverOutboxComment(ctx, comment)
+		switch {
+		case errDeliver == nil:
+			delivered++
+			b.notifyCommentOutcome(ctx, comment, "comment.delivered_later")
+		case dropped:
+			failed++
+			b.log.WarnContext(ctx, "Dropped outbox comment", "id", comment.ID, "error", errDeliver)
+			b.notifyCommentOutcome(ctx, comment, "comment.failed")
+		default:
+			failed++
+			b.log.InfoContext(ctx, "Outbox comment delivery failed, will retry",
+				"id", comment.ID, "attempts", comment.Attempts+1, "error", errDeliver)
+		}
+	}
+
+	if delivered+failed > 0 {
+		b.log.InfoContext(ctx, "Comment outbox dispatched", "delivered", delivered, "failed", failed)
+	}
+	return nil
+}
+
+// notifyCommentOutcome tells the author of a queued comment what happened to it.
+func (b *Bot) notifyCommentOutcome(ctx context.Context, comment models.OutboxComment, key string) {
+	lang := b.languageByID(ctx, comment.TelegramID)
+	text := b.localizer.GetWithData(lang, key, map[string]interface{}{"id": comment.TaskID})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	if _, err := b.bot.Send(telebot.ChatID(comment.TelegramID), text); err != nil {
+		b.log.WarnContext(ctx, "Failed to notify about outbox comment", "user", comment.TelegramID, "error", err)
+		b.recordSendFailure(ctx, comment.TelegramID, sendSourceOutbox, err)
+	}
+}
@@ -0,0 +1,352 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// minTeammateQuery is the shortest part of a short name teammates are searched by.
+	minTeammateQuery = 2
+	// teammateSearchLimit limits the teammates offered for one search.
+	teammateSearchLimit = 10
+)
+
+// HasProfilesCheck reports whether the user was granted other employees to act as, which shows
+// the profile switch in their profile menu.
+func (b *Bot) HasProfilesCheck(userID int64) bool {
+	ctx, cancel := b.operationContext(opQuery)
+	defer cancel()
+
+	has, err := b.prrepo.HasProfiles(ctx, userID)
+	if err != nil {
+		b.log.Error("Failed to check profiles", "error", err, "userID", userID)
+		return false
+	}
+
+	return has
+}
+
+// switchProfileHandler lists the employees the user can act as, tapping one switches to it.
+func (b *Bot) switchProfileHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("switch_profile").Inc()
+
+	profiles, err := b.prrepo.GetProfiles(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get profiles", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "profile.switch.title"), b.profileSwitchMarkup(timeoutCtx, ctx, profiles))
+}
+
+// profileSwitchHandler makes the user act as the employee in the callback data.
+func (b *Bot) profileSwitchHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	employeeID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid employee ID in callback", "data", ctx.Data(), "user", userID)
+		_ = ctx.Respond()
+		return nil
+	}
+
+	switched, err := b.prrepo.SwitchProfile(timeoutCtx, userID, employeeID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to switch profile", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	if !switched {
+		// The profile was revoked after the list was sent.
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		text := b.t(timeoutCtx, ctx, "profile.switch.denied")
+		return ctx.Respond(&telebot.CallbackResponse{Text: text, ShowAlert: true})
+	}
+	_ = ctx.Respond()
+	b.dropProfileCaches(timeoutCtx, userID)
+	b.log.InfoContext(timeoutCtx, "User switched profile", "user", userID, "employee", employeeID)
+
+	profiles, err := b.prrepo.GetProfiles(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get profiles", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	name := ""
+	for _, profile := range profiles {
+		if profile.Active {
+			name = profile.ShortName
+		}
+	}
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.tWithData(timeoutCtx, ctx, "profile.switch.done", map[string]interface{}{
+		"name": name,
+	}), b.profileSwitchMarkup(timeoutCtx, ctx, profiles))
+}
+
+// profileSwitchMarkup builds the keyboard with a button per profile, marking the active one.
+func (b *Bot) profileSwitchMarkup(
+	ctx context.Context,
+	tCtx telebot.Context,
+	profiles []models.Profile,
+) *telebot.ReplyMarkup {
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(profiles))
+	for _, profile := range profiles {
+		label := profile.ShortName
+		if profile.Own {
+			label = b.tWithData(ctx, tCtx, "profile.switch.own", map[string]interface{}{"name": profile.ShortName})
+		}
+		if profile.Active {
+			label = "✅ " + label
+		}
+		rows = append(rows, menu.Row(menu.Data(label, "profile_switch", strconv.Itoa(profile.EmployeeID))))
+	}
+	menu.Inline(rows...)
+
+	return menu
+}
+
+// dropProfileCaches removes the cached data of the user which depends on the employee they act as.
+func (b *Bot) dropProfileCaches(ctx context.Context, userID int64) {
+	b.employees.Invalidate(userID)
+	if err := b.cache.Del(ctx, fmt.Sprintf("oracle:info:user:%d", userID)); err != nil {
+		b.log.WarnContext(ctx, "Failed to drop cached user info", "error", err, "user", userID)
+	}
+	if _, err := b.cache.DelPrefix(ctx, fmt.Sprintf("oracle:report:user:%d:", userID)); err != nil {
+		b.log.WarnContext(ctx, "Failed to drop cached reports", "error", err, "user", userID)
+	}
+	if _, err := b.cache.DelPrefix(ctx, fmt.Sprintf("oracle:statistic:%d:", userID)); err != nil {
+		b.log.WarnContext(ctx, "Failed to drop cached statistics", "error", err, "user", userID)
+	}
+}
+
+// profileAccessHandler starts managing the profiles of a team lead, asking for their short name.
+func (b *Bot) profileAccessHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("profile_access").Inc()
+	b.log.Info("Admin requested profile access", "user", userID)
+
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingProfileLead})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.profiles.lead_prompt"))
+}
+
+// profileLeadSearchHandler lists the linked users whose short name contains the query entered by
+// the admin.
+func (b *Bot) profileLeadSearchHandler(ctx context.Context, tCtx telebot.Context, userID int64, query string) error {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minTeammateQuery {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingProfileLead})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "handover.query_short", map[string]interface{}{
+			"min": minTeammateQuery,
+		}))
+	}
+
+	// Telegram ID 0 leaves nobody out, the admin may manage their own profiles too.
+	users, err := b.horepo.SearchTeammates(ctx, 0, query, teammateSearchLimit)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to search users", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	if len(users) == 0 {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingProfileLead})
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.profiles.no_users", map[string]interface{}{"query": query}))
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(users))
+	for _, user := range users {
+		label := user.ShortName + " — " + user.FullName
+		rows = append(rows, menu.Row(menu.Data(label, "profile_lead", strconv.FormatInt(user.TelegramID, 10))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "admin.profiles.pick_lead"), menu)
+}
+
+// profileLeadHandler shows the profiles of the team lead in the callback data.
+func (b *Bot) profileLeadHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	leadID, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid Telegram ID in callback", "data", ctx.Data())
+		return nil
+	}
+
+	return b.showLeadProfiles(timeoutCtx, ctx, leadID)
+}
+
+// profileGrantStartHandler asks the admin for the short name of the employee to grant to the team lead.
+func (b *Bot) profileGrantStartHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	_ = ctx.Respond()
+
+	leadID, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid Telegram ID in callback", "data", ctx.Data())
+		return nil
+	}
+
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingProfileEmployee, TargetID: leadID})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.profiles.employee_prompt"))
+}
+
+// profileEmployeeSearchHandler lists the employees whose short name contains the query entered by
+// the admin, to be granted to the team lead.
+func (b *Bot) profileEmployeeSearchHandler(
+	ctx context.Context,
+	tCtx telebot.Context,
+	userID int64,
+	leadID int64,
+	query string,
+) error {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minTeammateQuery {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingProfileEmployee, TargetID: leadID})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "handover.query_short", map[string]interface{}{
+			"min": minTeammateQuery,
+		}))
+	}
+
+	employees, err := b.prrepo.SearchEmployees(ctx, query, teammateSearchLimit)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to search employees", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	if len(employees) == 0 {
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingProfileEmployee, TargetID: leadID})
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.profiles.no_employees", map[string]interface{}{
+			"query": query,
+		}))
+	}
+
+	lead := strconv.FormatInt(leadID, 10)
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(employees))
+	for _, employee := range employees {
+		label := employee.ShortName + " — " + employee.FullName
+		rows = append(rows, menu.Row(menu.Data(label, "profile_grant", lead, strconv.Itoa(employee.EmployeeID))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "admin.profiles.pick_employee"), menu)
+}
+
+// profileGrantHandler lets the team lead act as the employee, both in the callback data "lead|employee".
+func (b *Bot) profileGrantHandler(ctx telebot.Context) error {
+	return b.changeLeadProfile(ctx, repository.AuditProfileGrant)
+}
+
+// profileRevokeHandler stops the team lead from acting as the employee, both in the callback
+// data "lead|employee".
+func (b *Bot) profileRevokeHandler(ctx telebot.Context) error {
+	return b.changeLeadProfile(ctx, repository.AuditProfileRevoke)
+}
+
+// changeLeadProfile grants or revokes the employee in the callback data, as the audit action says.
+func (b *Bot) changeLeadProfile(ctx telebot.Context, action string) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	adminID := ctx.Sender().ID
+	_ = ctx.Respond()
+
+	rawLead, rawEmployee, _ := strings.Cut(ctx.Data(), "|")
+	leadID, errLead := strconv.ParseInt(rawLead, 10, 64)
+	employeeID, errEmployee := strconv.Atoi(rawEmployee)
+	if errLead != nil || errEmployee != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid profile callback", "data", ctx.Data(), "user", adminID)
+		return nil
+	}
+
+	var err error
+	if action == repository.AuditProfileGrant {
+		err = b.prrepo.GrantProfile(timeoutCtx, leadID, employeeID, adminID)
+	} else {
+		err = b.prrepo.RevokeProfile(timeoutCtx, leadID, employeeID)
+		// The team lead may have acted as the employee, so their cached data is stale.
+		b.dropProfileCaches(timeoutCtx, leadID)
+	}
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to change profiles", "error", err, "action", action, "lead", leadID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	b.recordAdminAction(timeoutCtx, adminID, action, map[string]interface{}{
+		"telegram_id": leadID,
+		"employee_id": employeeID,
+	})
+	b.log.InfoContext(timeoutCtx, "Profiles changed", "admin", adminID, "action", action,
+		"lead", leadID, "employee", employeeID)
+
+	return b.showLeadProfiles(timeoutCtx, ctx, leadID)
+}
+
+// showLeadProfiles shows the employees the team lead can act as in the message of the callback,
+// with buttons to revoke each of them and to grant another one.
+func (b *Bot) showLeadProfiles(ctx context.Context, tCtx telebot.Context, leadID int64) error {
+	profiles, err := b.prrepo.GetProfiles(ctx, leadID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get profiles", "error", err, "lead", leadID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	if len(profiles) == 0 {
+		// The team lead logged out in the meantime.
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "admin.profiles.lead_gone"))
+	}
+
+	lead := strconv.FormatInt(leadID, 10)
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(profiles))
+	for _, profile := range profiles[1:] {
+		label := "❌ " + profile.ShortName
+		rows = append(rows, menu.Row(menu.Data(label, "profile_revoke", lead, strconv.Itoa(profile.EmployeeID))))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "admin.profiles.grant"), "profile_grant_start", lead)))
+	menu.Inline(rows...)
+
+	text := b.tWithData(ctx, tCtx, "admin.profiles.lead", map[string]interface{}{
+		"name":  profiles[0].ShortName,
+		"count": len(profiles) - 1,
+	})
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return tCtx.Edit(text, menu)
+}
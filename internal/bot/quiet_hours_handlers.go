@@ -0,0 +1,283 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// DeferredDeliveryInterval is how often messages postponed by quiet hours are checked for delivery.
+	DeferredDeliveryInterval = time.Minute
+	// deferredDeliveryBatch is the number of postponed messages claimed at once.
+	deferredDeliveryBatch = 100
+)
+
+// quietHoursPeriod is a choice of quiet hours offered to users, in minutes after midnight.
+type quietHoursPeriod struct {
+	Start int
+	End   int
+}
+
+// quietHoursPeriods are the quiet hours offered to users.
+var quietHoursPeriods = []quietHoursPeriod{
+	{Start: 20 * 60, End: 8 * 60},
+	{Start: 21 * 60, End: 7 * 60},
+	{Start: 22 * 60, End: 8 * 60},
+	{Start: 18 * 60, End: 9 * 60},
+}
+
+// deferredMessage is the content of a message postponed by quiet hours. The text of Message is
+// already formatted in the language of the recipient.
+type deferredMessage struct {
+	Message   broadcastMessage
+	ParseMode telebot.ParseMode    `json:",omitempty"`
+	Markup    *telebot.ReplyMarkup `json:",omitempty"`
+}
+
+// quietHoursHandler shows the quiet hours of the user and offers inline buttons to change them.
+func (b *Bot) quietHoursHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("quiet_hours").Inc()
+	userID := ctx.Sender().ID
+
+	quietHours, enabled, err := b.qhrepo.GetQuietHours(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get quiet hours", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	text, menu := b.buildQuietHoursMenu(timeoutCtx, ctx, quietHours, enabled)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// quietHoursSetHandler changes the quiet hours of the user to the period in the callback data
+// "start|end", or turns them off for "off".
+func (b *Bot) quietHoursSetHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+
+	var err error
+	if ctx.Data() == "off" {
+		err = b.qhrepo.ClearQuietHours(timeoutCtx, userID)
+	} else {
+		period, ok := parseQuietHoursPeriod(ctx.Data())
+		if !ok {
+			b.log.Warn("Invalid quiet hours in callback", "user", userID, "data", ctx.Data())
+			b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+			return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "general.use_buttons")})
+		}
+		err = b.qhrepo.SetQuietHours(timeoutCtx, userID, period.Start, period.End)
+	}
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to update quiet hours", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	b.log.InfoContext(timeoutCtx, "User changed quiet hours", "user", userID, "period", ctx.Data())
+
+	quietHours, enabled, err := b.qhrepo.GetQuietHours(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get quiet hours", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	_ = ctx.Respond()
+
+	text, menu := b.buildQuietHoursMenu(timeoutCtx, ctx, quietHours, enabled)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// parseQuietHoursPeriod parses the callback data "start|end", accepting only the offered periods.
+func parseQuietHoursPeriod(data string) (quietHoursPeriod, bool) {
+	rawStart, rawEnd, _ := strings.Cut(data, "|")
+	start, errStart := strconv.Atoi(rawStart)
+	end, errEnd := strconv.Atoi(rawEnd)
+	if errStart != nil || errEnd != nil {
+		return quietHoursPeriod{}, false
+	}
+
+	for _, period := range quietHoursPeriods {
+		if period.Start == start && period.End == end {
+			return period, true
+		}
+	}
+	return quietHoursPeriod{}, false
+}
+
+// buildQuietHoursMenu returns the quiet hours status text and the keyboard with the offered
+// periods and, if quiet hours are on, the button turning them off.
+func (b *Bot) buildQuietHoursMenu(
+	ctx context.Context,
+	tCtx telebot.Context,
+	quietHours models.QuietHours,
+	enabled bool,
+) (string, *telebot.ReplyMarkup) {
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(quietHoursPeriods)+1)
+	for _, period := range quietHoursPeriods {
+		text := formatMinutes(period.Start) + "–" + formatMinutes(period.End)
+		if enabled && period.Start == quietHours.Start && period.End == quietHours.End {
+			text = "✅ " + text
+		}
+		start, end := strconv.Itoa(period.Start), strconv.Itoa(period.End)
+		rows = append(rows, menu.Row(menu.Data(text, "quiet_hours_set", start, end)))
+	}
+	if !enabled {
+		menu.Inline(rows...)
+		return b.t(ctx, tCtx, "quiet_hours.status.disabled"), menu
+	}
+
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "quiet_hours.button.disable"), "quiet_hours_set", "off")))
+	menu.Inline(rows...)
+
+	return b.tWithData(ctx, tCtx, "quiet_hours.status.enabled", map[string]interface{}{
+		"from":     formatMinutes(quietHours.Start),
+		"to":       formatMinutes(quietHours.End),
+		"timezone": b.quietHoursLocation(quietHours).String(),
+	}), menu
+}
+
+// formatMinutes formats minutes after midnight as a clock time, e.g. 20:00.
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// quietHoursLocation returns the time zone of the quiet hours, which is the time zone of the
+// user's digest, or the default digest time zone if the user is not subscribed to it.
+func (b *Bot) quietHoursLocation(quietHours models.QuietHours) *time.Location {
+	for _, name := range []string{quietHours.Timezone, b.digest.Timezone} {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// userQuietHours returns the quiet hours of the user, or none if they cannot be loaded,
+// so messages are rather sent than lost.
+func (b *Bot) userQuietHours(ctx context.Context, userID int64) models.QuietHours {
+	quietHours, _, err := b.qhrepo.GetQuietHours(ctx, userID)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get quiet hours, sending right away", "user", userID, "error", err)
+	}
+	return quietHours
+}
+
+// quietHoursByID returns the quiet hours of all users who set them, keyed by Telegram ID.
+// Users are treated as without quiet hours if they cannot be loaded.
+func (b *Bot) quietHoursByID(ctx context.Context) map[int64]models.QuietHours {
+	all, err := b.qhrepo.GetAllQuietHours(ctx)
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to get quiet hours, sending right away", "error", err)
+	}
+
+	byID := make(map[int64]models.QuietHours, len(all))
+	for _, quietHours := range all {
+		byID[quietHours.TelegramID] = quietHours
+	}
+	return byID
+}
+
+// deferIfQuiet postpones the message until the end of the quiet hours if they are on now.
+// It reports false if the message should be sent right away, also when it could not be postponed.
+func (b *Bot) deferIfQuiet(
+	ctx context.Context,
+	quietHours models.QuietHours,
+	source string,
+	message deferredMessage,
+) bool {
+	end, quiet := quietHours.EndsAt(time.Now(), b.quietHoursLocation(quietHours))
+	if !quiet {
+		return false
+	}
+
+	payload, err := json.Marshal(message)
+	if err == nil {
+		err = b.qhrepo.DeferMessage(ctx, quietHours.TelegramID, source, payload, end)
+	}
+	if err != nil {
+		b.log.WarnContext(ctx, "Failed to postpone message, sending right away",
+			"user", quietHours.TelegramID, "source", source, "error", err)
+		return false
+	}
+
+	b.log.DebugContext(ctx, "Message postponed by quiet hours", "user", quietHours.TelegramID, "source", source)
+	return true
+}
+
+// DeliverDeferredMessages sends the messages postponed by quiet hours which are due. It runs
+// every DeferredDeliveryInterval. Claimed messages are not retried, like the digest and broadcasts
+// they were postponed from.
+func (b *Bot) DeliverDeferredMessages(ctx context.Context) error {
+	sent, failed := 0, 0
+	for {
+		messages, err := b.qhrepo.ClaimDueMessages(ctx, deferredDeliveryBatch)
+		if err != nil {
+			return fmt.Errorf("failed to claim deferred messages: %w", err)
+		}
+
+		for _, message := range messages {
+			if err = b.sendDeferredMessage(ctx, message); err != nil {
+				b.log.WarnContext(ctx, "Failed to send deferred message",
+					"user", message.TelegramID, "source", message.Source, "error", err)
+				failed++
+			} else {
+				sent++
+			}
+
+			// Wait a bit between messages to avoid Telegram's rate limits
+			const telegramRateTimeout = 100 * time.Millisecond
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(telegramRateTimeout):
+			}
+		}
+
+		if len(messages) < deferredDeliveryBatch || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if sent+failed > 0 {
+		b.log.InfoContext(ctx, "Deferred messages sent", "success", sent, "failed", failed)
+	}
+	return nil
+}
+
+// sendDeferredMessage decodes and sends a message postponed by quiet hours.
+func (b *Bot) sendDeferredMessage(ctx context.Context, message models.DeferredMessage) error {
+	var content deferredMessage
+	if err := json.Unmarshal(message.Payload, &content); err != nil {
+		return fmt.Errorf("failed to decode deferred message %d: %w", message.ID, err)
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	_, err := b.bot.Send(telebot.ChatID(message.TelegramID), content.Message.content(content.Message.Text),
+		&telebot.SendOptions{ParseMode: content.ParseMode, ReplyMarkup: content.Markup})
+	if err != nil {
+		b.recordSendFailure(ctx, message.TelegramID, message.Source, err)
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/google/uuid"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	reportArchiveSize = 10 // reports kept per user
+	// maxArchivedReportSize limits reports archived in the database, 20 MiB. Without the object
+	// storage larger reports are sent but not archived.
+	maxArchivedReportSize = 20 << 20
+	// telegramUploadLimit is the largest file a bot can send, 50 MB. Larger reports kept in the
+	// object storage are sent as a download link.
+	telegramUploadLimit = 50 << 20
+)
+
+// archiveReport keeps the report file for downloading it again from "My reports". The file goes
+// to the object storage when it is configured, and to the database otherwise. It returns the
+// archived report, with a zero ID if the report was not archived.
+func (b *Bot) archiveReport(
+	ctx context.Context,
+	userID int64,
+	from, to time.Time,
+	format report.Format,
+	path string,
+) models.ArchivedReport {
+	size, hash, err := hashFile(path)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to hash report file for archiving", "error", err, "user", userID)
+		return models.ArchivedReport{}
+	}
+
+	archived := models.ArchivedReport{
+		TelegramID: userID, From: from, To: to, Format: string(format), Size: size, SHA256: hash,
+	}
+	var content []byte
+	if b.storage != nil {
+		archived.ObjectKey = fmt.Sprintf("reports/%d/%s.%s", userID, uuid.NewString(), format.Extension())
+		if err = b.storage.PutFile(ctx, archived.ObjectKey, path, format.MIMEType()); err != nil {
+			b.log.ErrorContext(ctx, "Failed to upload report to storage", "error", err, "user", userID)
+			return models.ArchivedReport{}
+		}
+	} else {
+		if size > maxArchivedReportSize {
+			b.log.InfoContext(ctx, "Report is too large to be archived", "user", userID, "size", size)
+			return models.ArchivedReport{}
+		}
+		if content, err = os.ReadFile(path); err != nil {
+			b.log.ErrorContext(ctx, "Failed to read report file for archiving", "error", err, "user", userID)
+			return models.ArchivedReport{}
+		}
+	}
+
+	id, pruned, err := b.arrepo.ArchiveReport(ctx, archived, content, reportArchiveSize)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to archive report", "error", err, "user", userID)
+		b.deleteStoredReports(ctx, archived.ObjectKey)
+		return models.ArchivedReport{}
+	}
+	archived.ID = id
+	b.deleteStoredReports(ctx, pruned...)
+
+	return archived
+}
+
+// deleteStoredReports removes the files of reports dropped from the archive from the object storage.
+func (b *Bot) deleteStoredReports(ctx context.Context, keys ...string) {
+	keys = slices.DeleteFunc(keys, func(key string) bool { return key == "" })
+	if len(keys) == 0 {
+		return
+	}
+	if b.storage == nil {
+		b.log.WarnContext(ctx, "Storage is disabled, archived report files are left in the bucket", "keys", keys)
+		return
+	}
+
+	if err := b.storage.Delete(ctx, keys...); err != nil {
+		b.log.WarnContext(ctx, "Failed to delete archived reports from storage", "error", err, "keys", keys)
+	}
+}
+
+// sendReport sends the report document. Reports over the Telegram upload limit which are kept in
+// the object storage are sent as a download link instead, together with the document caption.
+func (b *Bot) sendReport(
+	ctx context.Context,
+	recipient telebot.Recipient,
+	lang string,
+	archived models.ArchivedReport,
+	document *telebot.Document,
+) (*telebot.Message, error) {
+	if !b.sendsReportLink(archived) {
+		b.metrics.SentMessages.WithLabelValues("file").Inc()
+		return b.bot.Send(recipient, document)
+	}
+
+	link, err := b.storage.PresignedURL(ctx, archived.ObjectKey, document.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report download link: %w", err)
+	}
+
+	text := b.localizer.GetWithData(lang, "report.download_link", map[string]interface{}{
+		"size":  formatFileSize(archived.Size),
+		"hours": int(b.storage.PresignExpiry().Hours()),
+	})
+	if document.Caption != "" {
+		text = document.Caption + "\n\n" + text
+	}
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(menu.Row(menu.URL(b.localizer.Get(lang, "report.download_button"), link.String())))
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return b.bot.Send(recipient, text, menu)
+}
+
+// rememberReportFileID stores the Telegram file ID of the sent report, so downloading it again
+// does not upload the file.
+func (b *Bot) rememberReportFileID(ctx context.Context, id int64, msg *telebot.Message) {
+	if id == 0 || msg == nil || msg.Document == nil || msg.Document.FileID == "" {
+		return
+	}
+
+	if err := b.arrepo.SetArchivedReportFileID(ctx, id, msg.Document.FileID); err != nil {
+		b.log.WarnContext(ctx, "Failed to remember file ID of archived report", "error", err, "id", id)
+	}
+}
+
+// myReportsHandler lists the last reports of the user with a button to download each of them again.
+func (b *Bot) myReportsHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("my_reports").Inc()
+
+	reports, err := b.arrepo.GetArchivedReports(timeoutCtx, userID, reportArchiveSize)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get archived reports", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if len(reports) == 0 {
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "report.archive.empty"))
+	}
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(reports))
+	for _, archived := range reports {
+		label := b.tWithData(timeoutCtx, ctx, "report.archive.entry", map[string]interface{}{
+			"from":   b.formatDate(timeoutCtx, ctx, archived.From),
+			"to":     b.formatDate(timeoutCtx, ctx, archived.To),
+			"format": report.Format(archived.Format).Extension(),
+			"size":   formatFileSize(archived.Size),
+		})
+		rows = append(rows, menu.Row(menu.Data(label, "report_archive_get", strconv.FormatInt(archived.ID, 10))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "report.archive.title"), menu)
+}
+
+// reportArchiveGetHandler sends the archived report again. The file is resent by its Telegram
+// file ID when it is known, and uploaded from the archive otherwise.
+func (b *Bot) reportArchiveGetHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	id, err := strconv.ParseInt(ctx.Data(), 10, 64)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid archived report ID in callback", "data", ctx.Data(), "user", userID)
+		return ctx.Respond()
+	}
+
+	archived, err := b.arrepo.GetArchivedReport(timeoutCtx, userID, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrReportNotFound) {
+			b.log.ErrorContext(timeoutCtx, "Failed to get archived report", "error", err, "id", id)
+		}
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "report.archive.missing")})
+	}
+	_ = ctx.Respond()
+
+	format := report.Format(archived.Format)
+	if archived.FileID != "" {
+		document := newReportDocument(telebot.File{FileID: archived.FileID}, archived.From, archived.To, format)
+		b.metrics.SentMessages.WithLabelValues("file").Inc()
+		if err = ctx.Send(document); err == nil {
+			return nil
+		}
+		b.log.WarnContext(timeoutCtx, "Failed to resend report by file ID, uploading it", "error", err, "id", id)
+	}
+
+	document := newReportDocument(telebot.File{}, archived.From, archived.To, format)
+	if !b.sendsReportLink(archived) {
+		content, errContent := b.archivedReportContent(timeoutCtx, userID, archived)
+		if errContent != nil {
+			b.log.ErrorContext(timeoutCtx, "Failed to get archived report content", "error", errContent, "id", id)
+			b.metrics.SentMessages.WithLabelValues("error").Inc()
+			return ctx.Send(b.t(timeoutCtx, ctx, "report.archive.missing"))
+		}
+		defer content.Close()
+		document.File = telebot.FromReader(content)
+	}
+
+	msg, err := b.sendReport(timeoutCtx, ctx.Chat(), b.getUserLanguage(timeoutCtx, ctx), archived, document)
+	if err != nil {
+		return fmt.Errorf("failed to send archived report: %w", err)
+	}
+	b.rememberReportFileID(timeoutCtx, id, msg)
+
+	return nil
+}
+
+// sendsReportLink reports whether the report is sent as a download link, because it is over the
+// Telegram upload limit and kept in the object storage.
+func (b *Bot) sendsReportLink(archived models.ArchivedReport) bool {
+	return archived.Size > telegramUploadLimit && archived.ObjectKey != "" && b.storage != nil
+}
+
+// archivedReportContent opens the file of the archived report, from the object storage or the database.
+func (b *Bot) archivedReportContent(
+	ctx context.Context,
+	userID int64,
+	archived models.ArchivedReport,
+) (io.ReadCloser, error) {
+	if archived.ObjectKey == "" {
+		content, err := b.arrepo.GetArchivedReportContent(ctx, userID, archived.ID)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	if b.storage == nil {
+		return nil, errors.New("report is kept in the object storage, but the storage is disabled")
+	}
+	return b.storage.Get(ctx, archived.ObjectKey)
+}
+
+// hashFile returns the size and the hex encoded SHA-256 hash of the file.
+func hashFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// formatFileSize formats the size in bytes for display, e.g. 1.5 MB.
+func formatFileSize(size int64) string {
+	const unit = 1024
+	switch {
+	case size < unit:
+		return fmt.Sprintf("%d B", size)
+	case size < unit*unit:
+		return fmt.Sprintf("%.1f KB", float64(size)/unit)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(size)/(unit*unit))
+	}
+}
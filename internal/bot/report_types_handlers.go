@@ -0,0 +1,112 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// excludedReportTypes returns the names of the task types the user left out of reports.
+// If they cannot be loaded, nothing is excluded, a full report is better than none.
+func (b *Bot) excludedReportTypes(ctx context.Context, userID int64) map[string]bool {
+	names, err := b.rprepo.GetExcludedReportTypes(ctx, userID)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get excluded report types", "error", err, "user", userID)
+		return nil
+	}
+
+	excluded := make(map[string]bool, len(names))
+	for _, name := range names {
+		excluded[name] = true
+	}
+	return excluded
+}
+
+// reportTypesHandler shows the task types of the selected period as a checklist, so the
+// user can choose which of them the report includes.
+func (b *Bot) reportTypesHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	return b.showReportTypes(timeoutCtx, ctx, ctx.Data())
+}
+
+// reportTypeToggleHandler includes or excludes the task type and redraws the checklist.
+func (b *Bot) reportTypeToggleHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	period, rawTypeID, _ := strings.Cut(ctx.Data(), "|")
+	typeID, err := strconv.Atoi(rawTypeID)
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid task type in callback", "data", ctx.Data(), "user", userID)
+		return ctx.Respond()
+	}
+
+	if err = b.rprepo.ToggleReportType(timeoutCtx, userID, typeID); err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to toggle report type", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	// Cached reports were generated with the previous selection of task types.
+	if _, err = b.cache.DelPrefix(timeoutCtx, fmt.Sprintf("oracle:report:user:%d:", userID)); err != nil {
+		b.log.WarnContext(timeoutCtx, "Failed to drop cached reports", "error", err, "user", userID)
+	}
+
+	return b.showReportTypes(timeoutCtx, ctx, period)
+}
+
+// reportTypesDoneHandler returns from the checklist to the choice of the report format.
+func (b *Bot) reportTypesDoneHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	_ = ctx.Respond()
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(b.t(timeoutCtx, ctx, "report.choose_format"), b.reportFormatMenu(timeoutCtx, ctx, ctx.Data()))
+}
+
+// showReportTypes edits the message into the checklist of task types closed by the user within
+// the period. Included types are checked, tapping a type toggles it.
+func (b *Bot) showReportTypes(ctx context.Context, tCtx telebot.Context, period string) error {
+	userID := tCtx.Sender().ID
+	from, to, _, err := parseReportPeriod(period, time.Now())
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "report.error.unsupported_period")})
+	}
+
+	types, err := b.rprepo.GetReportTaskTypes(ctx, userID, from, to)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to get report task types", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "error.internal")})
+	}
+	if len(types) == 0 {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return tCtx.Respond(&telebot.CallbackResponse{Text: b.t(ctx, tCtx, "report.no_tasks")})
+	}
+	_ = tCtx.Respond()
+
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(types)+1)
+	for _, taskType := range types {
+		mark := "✅"
+		if taskType.Excluded {
+			mark = "⬜"
+		}
+		label := mark + " " + taskType.Name
+		rows = append(rows, menu.Row(menu.Data(label, "report_type_toggle", period, strconv.Itoa(taskType.ID))))
+	}
+	rows = append(rows, menu.Row(menu.Data(b.t(ctx, tCtx, "report.types.done"), "report_types_done", period)))
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return tCtx.Edit(b.t(ctx, tCtx, "report.types.choose"), menu)
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"slices"
+
+	"gopkg.in/telebot.v4"
+)
+
+// Router registers handlers under an ordered chain of middlewares, the first middleware of the
+// chain runs first. Groups extend the chain of their parent, so the admin group authenticates
+// the user before checking the admin role. The middlewares set with telebot's Use run before
+// any chain.
+//
+// Besides telebot endpoints, the router holds named handlers of reply keyboard buttons,
+// which are resolved from the button text by routeTextHandler and run with the chain
+// of the group they were registered in.
+type Router struct {
+	bot         *telebot.Bot
+	middlewares []telebot.MiddlewareFunc
+	named       map[string]telebot.HandlerFunc
+}
+
+// NewRouter creates a router without middlewares registering handlers with the bot.
+func NewRouter(bot *telebot.Bot) *Router {
+	return &Router{bot: bot, named: make(map[string]telebot.HandlerFunc)}
+}
+
+// Group returns a router whose chain is the chain of r followed by the middlewares.
+// The named handlers are shared with r.
+func (r *Router) Group(middlewares ...telebot.MiddlewareFunc) *Router {
+	return &Router{
+		bot:         r.bot,
+		middlewares: append(slices.Clone(r.middlewares), middlewares...),
+		named:       r.named,
+	}
+}
+
+// Handle registers the handler of the telebot endpoint, e.g. a command or "\f<unique>" of
+// a callback, wrapped in the chain of the router followed by the route's own middlewares.
+func (r *Router) Handle(endpoint interface{}, handler telebot.HandlerFunc, middlewares ...telebot.MiddlewareFunc) {
+	r.bot.Handle(endpoint, r.wrap(handler, middlewares))
+}
+
+// HandleNamed registers the handler of the reply keyboard button with the given handler name,
+// wrapped like in Handle.
+func (r *Router) HandleNamed(name string, handler telebot.HandlerFunc, middlewares ...telebot.MiddlewareFunc) {
+	r.named[name] = r.wrap(handler, middlewares)
+}
+
+// Named returns the wrapped handler registered under the name.
+func (r *Router) Named(name string) (telebot.HandlerFunc, bool) {
+	handler, ok := r.named[name]
+	return handler, ok
+}
+
+// wrap applies the chain of the router and the extra middlewares to the handler.
+func (r *Router) wrap(handler telebot.HandlerFunc, extra []telebot.MiddlewareFunc) telebot.HandlerFunc {
+	chain := append(slices.Clone(r.middlewares), extra...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	return handler
+}
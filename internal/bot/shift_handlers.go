@@ -0,0 +1,269 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// shiftLocationStart and shiftLocationEnd tell which location of the shift the user is asked for.
+	shiftLocationStart = "start"
+	shiftLocationEnd   = "end"
+)
+
+// defaultLocation returns the default time zone of the bot, UTC if it cannot be loaded.
+func (b *Bot) defaultLocation() *time.Location {
+	loc, err := time.LoadLocation(b.digest.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// OnShiftCheck is a helper method to check if the user has an open shift.
+func (b *Bot) OnShiftCheck(userID int64) bool {
+	ctx, cancel := b.operationContext(opQuery)
+	defer cancel()
+
+	_, open, err := b.shrepo.GetOpenShift(ctx, userID)
+	if err != nil {
+		b.log.Error("Failed to check open shift", "error", err, "userID", userID)
+		return false
+	}
+
+	return open
+}
+
+// OffShiftCheck is a helper method to check if the user can start a shift. It is false when the
+// shift cannot be checked, so neither shift button is shown.
+func (b *Bot) OffShiftCheck(userID int64) bool {
+	ctx, cancel := b.operationContext(opQuery)
+	defer cancel()
+
+	_, open, err := b.shrepo.GetOpenShift(ctx, userID)
+	if err != nil {
+		b.log.Error("Failed to check open shift", "error", err, "userID", userID)
+		return false
+	}
+
+	return !open
+}
+
+// shiftStartHandler starts a shift of the user and offers to share where it started.
+func (b *Bot) shiftStartHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("shift_start").Inc()
+	userID := ctx.Sender().ID
+
+	shift, open, err := b.shrepo.GetOpenShift(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get open shift", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if open {
+		// The button is older than the shift started elsewhere.
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Send(b.tWithData(timeoutCtx, ctx, "shift.already_started", map[string]interface{}{
+			"time": shift.StartedAt.In(b.defaultLocation()).Format("15:04"),
+		}), b.menuBuilder.Build(timeoutCtx, ctx, MenuMain, userID))
+	}
+
+	shiftID, err := b.shrepo.StartShift(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to start shift", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	b.log.InfoContext(timeoutCtx, "User started shift", "user", userID, "shift", shiftID)
+	b.stateManager.Set(userID, UserState{
+		WaitingFor: stateAwaitingShiftLocation,
+		Category:   shiftLocationStart,
+		TargetID:   shiftID,
+	})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "shift.started", map[string]interface{}{
+		"time": time.Now().In(b.defaultLocation()).Format("15:04"),
+	}), b.shiftMenu(timeoutCtx, ctx, userID))
+}
+
+// shiftEndHandler ends the open shift of the user and offers to share where it ended.
+func (b *Bot) shiftEndHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("shift_end").Inc()
+	userID := ctx.Sender().ID
+
+	shift, open, err := b.shrepo.GetOpenShift(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get open shift", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if !open {
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "shift.not_started"),
+			b.menuBuilder.Build(timeoutCtx, ctx, MenuMain, userID))
+	}
+
+	shiftID, ended, err := b.shrepo.EndShift(timeoutCtx, userID)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to end shift", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if !ended {
+		// The shift was ended from another device in the meantime.
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "shift.not_started"),
+			b.menuBuilder.Build(timeoutCtx, ctx, MenuMain, userID))
+	}
+	b.log.InfoContext(timeoutCtx, "User ended shift", "user", userID, "shift", shiftID)
+	b.stateManager.Set(userID, UserState{
+		WaitingFor: stateAwaitingShiftLocation,
+		Category:   shiftLocationEnd,
+		TargetID:   shiftID,
+	})
+
+	now := time.Now()
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "shift.ended", map[string]interface{}{
+		"time":  now.In(b.defaultLocation()).Format("15:04"),
+		"hours": fmt.Sprintf("%.2f", now.Sub(shift.StartedAt).Hours()),
+	}), b.shiftMenu(timeoutCtx, ctx, userID))
+}
+
+// shiftMenu returns the main menu with the button to share the location of the shift on top.
+func (b *Bot) shiftMenu(ctx context.Context, tCtx telebot.Context, userID int64) *telebot.ReplyMarkup {
+	menu := b.menuBuilder.Build(ctx, tCtx, MenuMain, userID)
+	locationRow := []telebot.ReplyButton{{Text: b.t(ctx, tCtx, "shift.button.location"), Location: true}}
+	menu.ReplyKeyboard = append([][]telebot.ReplyButton{locationRow}, menu.ReplyKeyboard...)
+
+	return menu
+}
+
+// shiftLocationHandler records the location shared after the shift started or ended.
+func (b *Bot) shiftLocationHandler(
+	ctx context.Context,
+	tCtx telebot.Context,
+	userID int64,
+	state UserState,
+	location *telebot.Location,
+) error {
+	end := state.Category == shiftLocationEnd
+	lat, lng := float64(location.Lat), float64(location.Lng)
+	if err := b.shrepo.SetShiftLocation(ctx, state.TargetID, end, lat, lng); err != nil {
+		b.log.ErrorContext(ctx, "Failed to set shift location", "error", err, "shift", state.TargetID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	b.log.InfoContext(ctx, "User shared shift location", "user", userID, "shift", state.TargetID, "end", end)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return tCtx.Send(b.t(ctx, tCtx, "shift.location_saved"), b.menuBuilder.Build(ctx, tCtx, MenuMain, userID))
+}
+
+// timesheetHandler asks the admin for the month of the timesheet.
+func (b *Bot) timesheetHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	b.log.Info("Admin requested timesheet", "user", ctx.Sender().ID)
+
+	menu := &telebot.ReplyMarkup{}
+	menu.Inline(
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.current_month"),
+			"timesheet_period", btnReportPeriodCurrent.Unique)),
+		menu.Row(menu.Data(b.t(timeoutCtx, ctx, "report.period.last_month"),
+			"timesheet_period", btnReportPeriodLast.Unique)),
+	)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "admin.timesheet.choose_period"), menu)
+}
+
+// timesheetPeriodHandler sends the timesheet of the month in the callback data as an Excel file.
+func (b *Bot) timesheetPeriodHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("timesheet").Inc()
+
+	loc := b.defaultLocation()
+	from, to, _, err := parseReportPeriod(ctx.Data(), time.Now().In(loc))
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "report.error.unsupported_period")})
+	}
+
+	shifts, err := b.shrepo.GetShifts(timeoutCtx, from, to)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get shifts", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	rows := make([]report.TimesheetRow, 0, len(shifts))
+	for _, shift := range shifts {
+		rows = append(rows, timesheetRow(shift, loc))
+	}
+
+	buffer, err := report.GenerateTimesheet(rows)
+	if err != nil {
+		if errors.Is(err, report.ErrNoTasks) {
+			_ = ctx.Respond()
+			b.metrics.SentMessages.WithLabelValues("text").Inc()
+			return ctx.Send(b.t(timeoutCtx, ctx, "admin.timesheet.empty"))
+		}
+		b.log.ErrorContext(timeoutCtx, "Failed to generate timesheet", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+	b.log.InfoContext(timeoutCtx, "Admin exported timesheet", "user", userID, "shifts", len(rows))
+
+	_ = ctx.Respond()
+	document := &telebot.Document{
+		File:     telebot.FromReader(buffer),
+		FileName: fmt.Sprintf("timesheet_%s.xlsx", from.Format("2006-01")),
+		MIME:     report.FormatXLSX.MIMEType(),
+	}
+	b.metrics.SentMessages.WithLabelValues("file").Inc()
+	return ctx.Send(document)
+}
+
+// timesheetRow converts the shift into a timesheet row with the times in loc.
+func timesheetRow(shift models.Shift, loc *time.Location) report.TimesheetRow {
+	row := report.TimesheetRow{
+		Employee:      shift.ShortName,
+		Start:         shift.StartedAt.In(loc),
+		StartLocation: shiftPlace(shift.StartLatitude.Float64, shift.StartLongitude.Float64, shift.StartLatitude.Valid),
+		EndLocation:   shiftPlace(shift.EndLatitude.Float64, shift.EndLongitude.Float64, shift.EndLatitude.Valid),
+	}
+	if row.Employee == "" {
+		row.Employee = fmt.Sprintf("#%d", shift.TelegramID)
+	}
+	if !shift.EndedAt.IsZero() {
+		row.End = shift.EndedAt.In(loc)
+	}
+
+	return row
+}
+
+// shiftPlace formats the coordinates of the shift, empty if they were not shared.
+func shiftPlace(latitude, longitude float64, valid bool) string {
+	if !valid {
+		return ""
+	}
+	return fmt.Sprintf("%.5f, %.5f", latitude, longitude)
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// ErrShutdownTimeout is returned by Shutdown when in-flight work did not finish before the deadline.
+var ErrShutdownTimeout = errors.New("in-flight handlers did not finish before shutdown deadline")
+
+// broadcastPersistTimeout is how long Shutdown waits for canceled broadcasts to save their progress.
+const broadcastPersistTimeout = 5 * time.Second
+
+// inFlight tracks running handlers and background work started by them, so the bot can
+// wait for them on shutdown. Once draining has started, no new work is accepted.
+type inFlight struct {
+	mu       sync.Mutex
+	draining bool
+	wg       sync.WaitGroup
+}
+
+// start registers a new unit of work. It reports false if the bot is shutting down.
+func (f *inFlight) start() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.draining {
+		return false
+	}
+	f.wg.Add(1)
+	return true
+}
+
+// done marks a unit of work registered by start as finished.
+func (f *inFlight) done() {
+	f.wg.Done()
+}
+
+// drain stops accepting new work and waits until the running work finishes or ctx expires.
+func (f *inFlight) drain(ctx context.Context) error {
+	f.mu.Lock()
+	f.draining = true
+	f.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ErrShutdownTimeout
+	}
+}
+
+// InFlightMiddleware tracks running handlers for the graceful shutdown. Updates arriving
+// while the bot is shutting down are dropped.
+func (b *Bot) InFlightMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		if !b.inFlight.start() {
+			return nil
+		}
+		defer b.inFlight.done()
+
+		return next(ctx)
+	}
+}
+
+// goTracked runs fn in a new goroutine that Shutdown waits for. It reports false and does
+// not run fn if the bot is already shutting down.
+func (b *Bot) goTracked(fn func()) bool {
+	if !b.inFlight.start() {
+		return false
+	}
+
+	go func() {
+		defer b.inFlight.done()
+		fn()
+	}()
+	return true
+}
+
+// Shutdown stops receiving updates and waits for running handlers and background work,
+// such as broadcasts and alert delivery, to finish. If ctx expires first, running broadcasts
+// are canceled so their progress is saved, the base context is canceled so handlers still
+// waiting for the database, Redis or Hermes give up, and ErrShutdownTimeout is returned.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	b.log.Info("Telegram bot is shutting down, waiting for in-flight handlers...")
+	b.bot.Stop()
+	defer b.stopBase()
+
+	err := b.inFlight.drain(ctx)
+	if err == nil {
+		b.log.Info("Telegram bot is stopped...")
+		return nil
+	}
+
+	stopped := b.broadcasts.stopAll()
+	b.log.Warn("Shutdown deadline reached, canceling running broadcasts", "broadcasts", stopped)
+	b.stopBase()
+
+	persistCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), broadcastPersistTimeout)
+	defer cancel()
+	if persistErr := b.inFlight.drain(persistCtx); persistErr != nil {
+		b.log.Error("Background work did not finish after shutdown deadline")
+	}
+
+	return err
+}
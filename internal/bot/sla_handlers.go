@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"gopkg.in/telebot.v4"
+)
+
+// maxSLAHours limits the SLA of a task type to a year.
+const maxSLAHours = 365 * 24
+
+// slaConfigHandler lists the task types with their SLA, tapping a type asks the admin for a new one.
+func (b *Bot) slaConfigHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.log.Info("Admin requested SLA configuration", "user", userID)
+
+	config, err := b.slarepo.GetSLAConfig(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get SLA configuration", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	if len(config) == 0 {
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "admin.sla.no_types"))
+	}
+
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+	menu := &telebot.ReplyMarkup{}
+	rows := make([]telebot.Row, 0, len(config))
+	for _, sla := range config {
+		label := sla.TypeName + " — " + b.localizer.Get(lang, "admin.sla.not_set")
+		if sla.Hours > 0 {
+			label = sla.TypeName + " — " + b.localizer.GetWithData(lang, "admin.sla.hours", map[string]interface{}{
+				"hours": sla.Hours,
+			})
+		}
+		rows = append(rows, menu.Row(menu.Data(label, "sla_edit", strconv.Itoa(sla.TypeID))))
+	}
+	menu.Inline(rows...)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.localizer.Get(lang, "admin.sla.title"), menu)
+}
+
+// slaEditHandler asks the admin for the SLA of the task type in the callback data.
+func (b *Bot) slaEditHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opAdmin)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	_ = ctx.Respond()
+
+	typeID, err := strconv.Atoi(ctx.Data())
+	if err != nil {
+		b.log.WarnContext(timeoutCtx, "Invalid task type in callback", "data", ctx.Data(), "user", userID)
+		return nil
+	}
+
+	// The name is looked up instead of being carried in the callback data, which is limited to 64 bytes.
+	config, err := b.slarepo.GetSLAConfig(timeoutCtx)
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get SLA configuration", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+	idx := slices.IndexFunc(config, func(sla models.TaskTypeSLA) bool { return sla.TypeID == typeID })
+	if idx < 0 {
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "admin.sla.no_types"))
+	}
+
+	b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingSLA, TypeID: typeID})
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.tWithData(timeoutCtx, ctx, "admin.sla.prompt", map[string]interface{}{
+		"type":  config[idx].TypeName,
+		"hours": config[idx].Hours,
+	}))
+}
+
+// slaInputHandler saves the SLA in hours entered by the admin for the task type. Zero removes the SLA.
+func (b *Bot) slaInputHandler(
+	ctx context.Context,
+	tCtx telebot.Context,
+	userID int64,
+	typeID int,
+	input string,
+) error {
+	hours, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || hours < 0 || hours > maxSLAHours {
+		// Keep waiting for a valid number.
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingSLA, TypeID: typeID})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+		return tCtx.Send(b.tWithData(ctx, tCtx, "admin.sla.invalid", map[string]interface{}{"max": maxSLAHours}))
+	}
+
+	if err = b.slarepo.SetTaskTypeSLA(ctx, typeID, hours); err != nil {
+		b.log.ErrorContext(ctx, "Failed to set SLA", "error", err, "type", typeID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return tCtx.Send(b.t(ctx, tCtx, "error.internal"))
+	}
+	b.recordAdminAction(ctx, userID, repository.AuditSLAUpdate, map[string]interface{}{
+		"type_id": typeID,
+		"hours":   hours,
+	})
+	b.log.InfoContext(ctx, "SLA updated", "admin", userID, "type", typeID, "hours", hours)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	if hours == 0 {
+		return tCtx.Send(b.t(ctx, tCtx, "admin.sla.removed"))
+	}
+	return tCtx.Send(b.tWithData(ctx, tCtx, "admin.sla.saved", map[string]interface{}{"hours": hours}))
+}
@@ -1,13 +1,23 @@
 package bot
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
-
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/chart"
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"github.com/jackc/pgx/v5"
 	"gopkg.in/telebot.v4"
 )
 
@@ -21,12 +31,12 @@ func (b *Bot) statisticHandlerToday(ctx telebot.Context) error {
 
 	b.log.Info("User requested stats", "user", userID, "period", "day")
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
-	responseText := b.processStatistic(timeoutCtx, ctx, userID, "day")
+	stat := b.processStatistic(timeoutCtx, ctx, userID, "day")
 
-	return ctx.Send(responseText, telebot.ModeMarkdown)
+	return b.sendStatistic(timeoutCtx, ctx, stat)
 }
 
 // statisticHandlerMonth handles the user's request for monthly statistics.
@@ -39,12 +49,12 @@ func (b *Bot) statisticHandlerMonth(ctx telebot.Context) error {
 
 	b.log.Info("User requested stats", "user", userID, "period", "month")
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
-	responseText := b.processStatistic(timeoutCtx, ctx, userID, "month")
+	stat := b.processStatistic(timeoutCtx, ctx, userID, "month")
 
-	return ctx.Send(responseText, telebot.ModeMarkdown)
+	return b.sendStatistic(timeoutCtx, ctx, stat)
 }
 
 // statisticHandlerYear handles the statistics request for the year.
@@ -57,75 +67,307 @@ func (b *Bot) statisticHandlerYear(ctx telebot.Context) error {
 
 	b.log.Info("User requested stats", "user", userID, "period", "year")
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	stat := b.processStatistic(timeoutCtx, ctx, userID, "year")
+
+	return b.sendStatistic(timeoutCtx, ctx, stat)
+}
+
+// statisticHandlerWeek handles the statistics request for the current week, starting on Monday.
+func (b *Bot) statisticHandlerWeek(ctx telebot.Context) error {
+	b.metrics.CommandReceived.WithLabelValues("statistic").Inc()
+
+	userID := ctx.Sender().ID
+
+	b.log.Info("User requested stats", "user", userID, "period", "week")
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	stat := b.processStatistic(timeoutCtx, ctx, userID, "week")
+
+	return b.sendStatistic(timeoutCtx, ctx, stat)
+}
+
+// statisticHandlerRange asks the user for the date range of the statistics.
+// The answer is handled by statisticRangeInputHandler.
+func (b *Bot) statisticHandlerRange(ctx telebot.Context) error {
+	b.metrics.CommandReceived.WithLabelValues("statistic").Inc()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
 	defer cancel()
 
-	responseText := b.processStatistic(timeoutCtx, ctx, userID, "year")
+	b.stateManager.Set(ctx.Sender().ID, UserState{WaitingFor: stateAwaitingStatRange})
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(b.t(timeoutCtx, ctx, "statistic.range.prompt"))
+}
+
+// statisticRangeInputHandler parses the date range entered by the user and sends the statistics
+// for it. On invalid input the user is asked again.
+func (b *Bot) statisticRangeInputHandler(ctx context.Context, bCtx telebot.Context, userID int64, input string) error {
+	from, to, err := parseDateRange(input, time.Now().Location())
+	if err != nil {
+		b.log.InfoContext(ctx, "Invalid statistics date range", "user", userID, "input", input, "error", err)
+		b.stateManager.Set(userID, UserState{WaitingFor: stateAwaitingStatRange})
+		b.metrics.SentMessages.WithLabelValues("user_error").Inc()
+
+		key := "statistic.range.invalid"
+		if errors.Is(err, errDateRangeTooLong) {
+			key = "statistic.range.too_long"
+		}
+		return bCtx.Send(b.tWithData(ctx, bCtx, key, map[string]interface{}{"days": maxStatisticRangeDays}))
+	}
+
+	b.log.InfoContext(ctx, "User requested stats", "user", userID, "period", "range", "from", from, "to", to)
+	stat := b.processStatisticRange(ctx, bCtx, userID, from, to)
+
+	return b.sendStatistic(ctx, bCtx, stat)
+}
+
+// maxCaptionLength is the maximum length of a photo caption allowed by Telegram.
+const maxCaptionLength = 1024
+
+// statistic is the rendered statistics of a user: the text summary and an optional PNG bar chart.
+// The period is the name under which the statistics are cached, it is empty on errors.
+type statistic struct {
+	text   string
+	chart  []byte
+	period string
+}
+
+// sendStatistic sends the statistics as a photo of the chart with the text as its caption.
+// Without a chart, or when the text does not fit into a caption, the text is sent as a message.
+// The message carrying the text gets the Export button.
+func (b *Bot) sendStatistic(ctx context.Context, bCtx telebot.Context, stat statistic) error {
+	options := []interface{}{telebot.ModeMarkdown}
+	if stat.period != "" {
+		menu := &telebot.ReplyMarkup{}
+		menu.Inline(menu.Row(menu.Data(b.t(ctx, bCtx, "statistic.export.button"), "statistic_export", stat.period)))
+		options = append(options, menu)
+	}
+
+	if len(stat.chart) == 0 {
+		return b.sendLong(bCtx, stat.text, options...)
+	}
+
+	photo := &telebot.Photo{File: telebot.FromReader(bytes.NewReader(stat.chart))}
+	if utf8.RuneCountInString(stat.text) <= maxCaptionLength {
+		photo.Caption = stat.text
+		b.metrics.SentMessages.WithLabelValues("photo").Inc()
+		return bCtx.Send(photo, options...)
+	}
 
-	return ctx.Send(responseText, telebot.ModeMarkdown)
+	b.metrics.SentMessages.WithLabelValues("photo").Inc()
+	if err := bCtx.Send(photo); err != nil {
+		return fmt.Errorf("failed to send statistics chart: %w", err)
+	}
+	return b.sendLong(bCtx, stat.text, options...)
 }
 
-// processStatistic handles the request for statistics from the user.
-// It logs the user's request, generates the statistics string for the period time,
-// and sends the response back to the user. In case of an error during the
-// generation of the statistics, it sends an internal error message.
-func (b *Bot) processStatistic(ctx context.Context, bCtx telebot.Context, userID int64, period string) string {
-	// --- 1. Create a unique cache key ---
+// statisticExportHandler sends the statistics of the period from the callback data as an Excel workbook.
+func (b *Bot) statisticExportHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opReport)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	b.metrics.CommandReceived.WithLabelValues("statistic_export").Inc()
+
+	from, to, ok := parseStatisticPeriod(ctx.Data(), time.Now())
+	if !ok {
+		b.metrics.SentMessages.WithLabelValues("respond").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "report.error.unsupported_period")})
+	}
+	b.log.InfoContext(timeoutCtx, "User requested statistics export", "user", userID, "period", ctx.Data())
+
+	startTime := time.Now()
+	tasks, err := b.tarepo.GetCompletedTasksByExecutor(timeoutCtx, userID, from, to)
+	b.metrics.DBQueryDuration.WithLabelValues("get_completed_tasks").Observe(time.Since(startTime).Seconds())
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		b.log.ErrorContext(timeoutCtx, "Failed to get completed tasks for statistics export", "error", err)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	rows := make([]report.ExcelRow, 0, len(tasks))
+	for _, task := range tasks {
+		rows = append(rows, report.ExcelRow{
+			ID:           task.ID,
+			Type:         task.Type,
+			CreationDate: task.CreationDate,
+			ClosingDate:  task.ClosingDate,
+		})
+	}
+
+	buffer, err := report.GenerateStatisticsReport(rows, from, to)
+	if err != nil {
+		if errors.Is(err, report.ErrNoTasks) {
+			b.metrics.SentMessages.WithLabelValues("respond").Inc()
+			return ctx.Respond(&telebot.CallbackResponse{
+				Text:      b.t(timeoutCtx, ctx, "report.no_tasks"),
+				ShowAlert: true,
+			})
+		}
+		b.log.ErrorContext(timeoutCtx, "Failed to generate statistics export", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	_ = ctx.Respond()
+	document := &telebot.Document{
+		File:     telebot.FromReader(buffer),
+		FileName: fmt.Sprintf("statistics_%s_%s.xlsx", from.Format("2006-01-02"), to.Format("2006-01-02")),
+		MIME:     report.FormatXLSX.MIMEType(),
+	}
+	b.metrics.SentMessages.WithLabelValues("file").Inc()
+	return ctx.Send(document)
+}
+
+// processStatistic handles the request for statistics from the user for a named period:
+// day, week, month or year. In case of an error during the generation of the statistics,
+// it returns an internal error message.
+func (b *Bot) processStatistic(ctx context.Context, bCtx telebot.Context, userID int64, period string) statistic {
+	from, to, ok := statisticPeriod(period, time.Now())
+	if !ok {
+		return statistic{text: "Unsupported period."}
+	}
+
 	// The key includes the user ID and the period to keep it unique.
+	return b.cachedStatistic(ctx, bCtx, userID, period, from, to)
+}
+
+// processStatisticRange handles the request for statistics from the user for a custom date range.
+func (b *Bot) processStatisticRange(
+	ctx context.Context,
+	bCtx telebot.Context,
+	userID int64,
+	from, to time.Time,
+) statistic {
+	// The key includes both dates, so every range is cached separately.
+	period := rangePeriodPrefix + from.Format(rangePeriodLayout) + "-" + to.Format(rangePeriodLayout)
+	return b.cachedStatistic(ctx, bCtx, userID, period, from, to)
+}
+
+// statisticEntry is the cached form of the statistics, the text and the chart stored together.
+type statisticEntry struct {
+	Text  string `json:"text"`
+	Chart []byte `json:"chart,omitempty"`
+}
+
+// cachedStatistic returns the statistics for the date range, from the cache if possible.
+// The cache key is built from the user ID and the period name.
+func (b *Bot) cachedStatistic(
+	ctx context.Context,
+	bCtx telebot.Context,
+	userID int64,
+	period string,
+	from, to time.Time,
+) statistic {
 	cacheKey := fmt.Sprintf("oracle:statistic:%d:%s", userID, period)
 	const cacheTTL = 1 * time.Hour // Statistics can be cached for a few hours
 
-	// --- 2. Try to get the statistics from Redis first ---
-	cachedStats, err := b.redisClient.Get(ctx, cacheKey).Result()
-	if err == nil {
-		// Cache HIT!
-		b.log.InfoContext(ctx, "Statistics found in cache", "user", userID, "key", cacheKey)
-		b.metrics.SentMessages.WithLabelValues("text_cached").Inc()
-		return cachedStats
+	entry, err := cache.Fetch(ctx, b.cache, cacheKey, cacheTTL, func(context.Context) (statisticEntry, error) {
+		startTime := time.Now()
+		stat, genErr := generateStatistic(b, bCtx, userID, from, to)
+		b.metrics.DBQueryDuration.WithLabelValues("get_task_summary").Observe(time.Since(startTime).Seconds())
+		return statisticEntry{Text: stat.text, Chart: stat.chart}, genErr
+	})
+	if err != nil {
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return statistic{text: ErrInternal}
 	}
 
-	// --- 3. Cache MISS - Calculate date range ---
-	var from, to time.Time
-	now := time.Now()
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return statistic{text: entry.Text, chart: entry.Chart, period: period}
+}
 
+// statisticPeriod converts the period name into a date range ending now.
+func statisticPeriod(period string, now time.Time) (time.Time, time.Time, bool) {
 	switch period {
 	case "day":
-		from = now
-		to = now
+		return now, now, true
+	case "week":
+		return startOfWeek(now), now, true
 	case "month":
-		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-		to = now
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), now, true
 	case "year":
-		from = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-		to = now
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), now, true
 	default:
-		return "Unsupported period."
+		return time.Time{}, time.Time{}, false
 	}
+}
 
-	// --- 4. Generate the statistics string ---
-	startTime := time.Now()
-	responseText, err := generateStatisticString(b, bCtx, userID, from, to)
-	b.metrics.DBQueryDuration.WithLabelValues("get_task_summary").Observe(time.Since(startTime).Seconds())
+// Custom ranges are named "range:YYYYMMDD-YYYYMMDD" in cache keys and callback data.
+const (
+	rangePeriodPrefix = "range:"
+	rangePeriodLayout = "20060102"
+)
+
+// parseStatisticPeriod converts the period name of cached statistics, either a named period
+// or a custom range, back into a date range.
+func parseStatisticPeriod(period string, now time.Time) (time.Time, time.Time, bool) {
+	dates, isRange := strings.CutPrefix(period, rangePeriodPrefix)
+	if !isRange {
+		return statisticPeriod(period, now)
+	}
+
+	rawFrom, rawTo, found := strings.Cut(dates, "-")
+	if !found {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := time.ParseInLocation(rangePeriodLayout, rawFrom, now.Location())
 	if err != nil {
-		b.metrics.SentMessages.WithLabelValues("error").Inc()
-		return ErrInternal
+		return time.Time{}, time.Time{}, false
+	}
+	to, err := time.ParseInLocation(rangePeriodLayout, rawTo, now.Location())
+	if err != nil || to.Before(from) {
+		return time.Time{}, time.Time{}, false
 	}
 
-	// --- 5. Save the result to Redis ---
-	err = b.redisClient.Set(ctx, cacheKey, responseText, cacheTTL).Err()
+	return from, to.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+}
+
+// maxStatisticRangeDays limits the custom statistics range to keep the query cheap.
+const maxStatisticRangeDays = 366
+
+var (
+	errInvalidDateRange = errors.New("expected a date range in the DD.MM.YYYY - DD.MM.YYYY format")
+	errDateRangeTooLong = fmt.Errorf("date range must not exceed %d days", maxStatisticRangeDays)
+)
+
+// parseDateRange parses a range of two dates in the DD.MM.YYYY format, e.g. "01.05.2025 - 15.05.2025".
+// The range covers both days completely. The dates may come in any order.
+func parseDateRange(input string, loc *time.Location) (time.Time, time.Time, error) {
+	parts := strings.FieldsFunc(input, func(r rune) bool {
+		return r == '-' || r == '–' || r == '—' || unicode.IsSpace(r)
+	})
+	if len(parts) != 2 { //nolint:mnd // a range consists of two dates
+		return time.Time{}, time.Time{}, errInvalidDateRange
+	}
+
+	from, err := time.ParseInLocation("02.01.2006", parts[0], loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %w", errInvalidDateRange, err)
+	}
+	to, err := time.ParseInLocation("02.01.2006", parts[1], loc)
 	if err != nil {
-		// Just log the error, don't block the user
-		b.log.ErrorContext(ctx, "Failed to save statistics to cache", "error", err, "key", cacheKey)
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %w", errInvalidDateRange, err)
+	}
+	if to.Before(from) {
+		from, to = to, from
+	}
+	if to.Sub(from) >= maxStatisticRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errDateRangeTooLong
 	}
 
-	// --- 6. Send the response ---
-	b.metrics.SentMessages.WithLabelValues("text").Inc()
-	return responseText
+	return from, to.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
 }
 
-// generateStatisticString generates a formatted string containing statistics for a user
+// generateStatistic generates a formatted string containing statistics for a user
 // within a specified date range. It retrieves task summaries from the bot's repository,
 // formats them into a human-readable string, and appends a random encouragement phrase.
+// The task types are also rendered as a bar chart; if that fails, the chart is omitted.
 //
 // Parameters:
 // - bot: A pointer to the Bot instance used to access the repository.
@@ -134,32 +376,56 @@ func (b *Bot) processStatistic(ctx context.Context, bCtx telebot.Context, userID
 // - endDate: The end date for the statistics period.
 //
 // Returns:
-// - A formatted string containing the user's statistics and a random encouragement phrase.
+// - The user's statistics text with a random encouragement phrase, and the chart.
 // - An error if the task summary retrieval fails.
-func generateStatisticString(
+func generateStatistic(
 	bot *Bot,
 	bCtx telebot.Context,
 	userID int64,
 	startDate, endDate time.Time,
-) (string, error) {
+) (statistic, error) {
 	var builder strings.Builder
 
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	timeoutCtx, cancel := bot.updateContext(bCtx, opQuery)
 	defer cancel()
 
 	summaries, err := bot.tarepo.GetTaskSummary(timeoutCtx, userID, startDate, endDate)
 	if err != nil {
-		return "", fmt.Errorf("failed to get task summary: %w", err)
+		return statistic{}, fmt.Errorf("failed to get task summary: %w", err)
 	}
 
 	builder.WriteString(bot.t(timeoutCtx, bCtx, "statistic.your_stats"))
 	builder.WriteString("\n\n")
 
 	for _, summary := range summaries {
+		key := "statistic.item"
 		if summary.Type == "Total" {
-			builder.WriteString(fmt.Sprintf("\n👑 %s: %d\n", summary.Type, summary.Count))
-		} else {
-			builder.WriteString(fmt.Sprintf(" • %s: %d\n", summary.Type, summary.Count))
+			key = "statistic.total"
+			builder.WriteString("\n")
+		}
+		builder.WriteString(bot.tWithData(timeoutCtx, bCtx, key, map[string]interface{}{
+			"type":  telegramfmt.Markdown.Escape(summary.Type),
+			"count": bot.tPlural(timeoutCtx, bCtx, "statistic.tasks", summary.Count),
+		}))
+		builder.WriteString("\n")
+	}
+
+	durations, err := bot.tarepo.GetTaskDurations(timeoutCtx, userID, startDate, endDate)
+	if err != nil {
+		// Turnaround times are a supplement, the statistics are still useful without them.
+		bot.log.ErrorContext(timeoutCtx, "Failed to get task durations", "error", err, "user", userID)
+	}
+	if len(durations) > 0 {
+		builder.WriteString("\n")
+		builder.WriteString(bot.t(timeoutCtx, bCtx, "statistic.durations.header"))
+		builder.WriteString("\n")
+		for _, duration := range durations {
+			builder.WriteString(bot.tWithData(timeoutCtx, bCtx, "statistic.durations.item", map[string]interface{}{
+				"type":    telegramfmt.Markdown.Escape(duration.Type),
+				"average": bot.formatDuration(timeoutCtx, bCtx, duration.Average),
+				"median":  bot.formatDuration(timeoutCtx, bCtx, duration.Median),
+			}))
+			builder.WriteString("\n")
 		}
 	}
 
@@ -172,12 +438,35 @@ func generateStatisticString(
 
 	randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(encouragementPhrases))))
 	if err != nil {
-		return "", fmt.Errorf("failed to generate random integer: %w", err)
+		return statistic{}, fmt.Errorf("failed to generate random integer: %w", err)
 	}
 	randomPhrase := encouragementPhrases[randomIndex.Int64()]
 
 	builder.WriteString("\n\\*\\*\\*\n")
 	builder.WriteString(randomPhrase)
 
-	return builder.String(), err
+	image, err := renderStatisticChart(bot.t(timeoutCtx, bCtx, "statistic.chart_title"), summaries)
+	if err != nil && !errors.Is(err, chart.ErrNoData) {
+		bot.log.ErrorContext(timeoutCtx, "Failed to render statistics chart", "error", err, "user", userID)
+	}
+
+	return statistic{text: builder.String(), chart: image}, nil
+}
+
+// renderStatisticChart renders the task types of the summaries as a PNG bar chart.
+// The total row is left out, as it would dwarf the other bars.
+func renderStatisticChart(title string, summaries []models.TaskSummary) ([]byte, error) {
+	bars := make([]chart.Bar, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Type == "Total" {
+			continue
+		}
+		bars = append(bars, chart.Bar{Label: summary.Type, Value: summary.Count})
+	}
+
+	buffer, err := chart.RenderBars(title, bars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render statistics chart: %w", err)
+	}
+	return buffer.Bytes(), nil
 }
@@ -6,6 +6,9 @@ import "sync"
 type UserState struct {
 	WaitingFor string
 	TaskID     int
+	TypeID     int    // TypeID is the task type whose SLA an admin is entering.
+	Category   string // Category is the kind of feedback the user is describing, "bug" or "idea".
+	TargetID   int64  // TargetID is the Telegram user an admin is granting a profile to.
 }
 
 // StateManager manages the states of all users.
@@ -26,6 +29,14 @@ func (sm *StateManager) Set(userID int64, state UserState) {
 	sm.states[userID] = state
 }
 
+// Len returns the number of users with a pending state.
+func (sm *StateManager) Len() int {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return len(sm.states)
+}
+
 // Get gets and immediately delete user state.
 func (sm *StateManager) Get(userID int64) (UserState, bool) {
 	sm.mu.Lock()
@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/UnknownOlympus/oracle/internal/i18n"
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"gopkg.in/telebot.v4"
+)
+
+// autoReportHandler shows whether the user receives the weekly automatic report
+// and offers an inline button to toggle the subscription.
+func (b *Bot) autoReportHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("auto_report").Inc()
+	userID := ctx.Sender().ID
+
+	startTime := time.Now()
+	subscribed, err := b.subrepo.IsSubscribedToReports(timeoutCtx, userID)
+	b.metrics.DBQueryDuration.WithLabelValues("is_subscribed_to_reports").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to get report subscription", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Send(b.t(timeoutCtx, ctx, "error.internal"))
+	}
+
+	text, menu := b.buildAutoReportMenu(timeoutCtx, ctx, subscribed)
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, menu)
+}
+
+// autoReportToggleHandler subscribes or unsubscribes the user from the weekly automatic report
+// depending on the callback data ("on" or "off") and updates the message in place.
+func (b *Bot) autoReportToggleHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	userID := ctx.Sender().ID
+	subscribe := ctx.Data() == "on"
+
+	var err error
+	startTime := time.Now()
+	if subscribe {
+		err = b.subrepo.SubscribeToReports(timeoutCtx, userID)
+		b.metrics.DBQueryDuration.WithLabelValues("subscribe_to_reports").Observe(time.Since(startTime).Seconds())
+	} else {
+		err = b.subrepo.UnsubscribeFromReports(timeoutCtx, userID)
+		b.metrics.DBQueryDuration.WithLabelValues("unsubscribe_from_reports").Observe(time.Since(startTime).Seconds())
+	}
+	if err != nil {
+		b.log.ErrorContext(timeoutCtx, "Failed to update report subscription", "error", err, "user", userID)
+		b.metrics.SentMessages.WithLabelValues("error").Inc()
+		return ctx.Respond(&telebot.CallbackResponse{Text: b.t(timeoutCtx, ctx, "error.internal")})
+	}
+
+	b.log.InfoContext(timeoutCtx, "User changed report subscription", "user", userID, "subscribed", subscribe)
+	_ = ctx.Respond()
+
+	text, menu := b.buildAutoReportMenu(timeoutCtx, ctx, subscribe)
+
+	b.metrics.SentMessages.WithLabelValues("edit").Inc()
+	return ctx.Edit(text, menu)
+}
+
+// buildAutoReportMenu returns the subscription status text and the toggle keyboard.
+func (b *Bot) buildAutoReportMenu(
+	ctx context.Context,
+	tCtx telebot.Context,
+	subscribed bool,
+) (string, *telebot.ReplyMarkup) {
+	menu := &telebot.ReplyMarkup{}
+	if subscribed {
+		menu.Inline(menu.Row(menu.Data(b.t(ctx, tCtx, "auto_report.button.disable"), "auto_report_toggle", "off")))
+		return b.t(ctx, tCtx, "auto_report.status.enabled"), menu
+	}
+
+	menu.Inline(menu.Row(menu.Data(b.t(ctx, tCtx, "auto_report.button.enable"), "auto_report_toggle", "on")))
+	return b.t(ctx, tCtx, "auto_report.status.disabled"), menu
+}
+
+// SendWeeklyReports generates the Excel report for the previous week (Monday to Sunday)
+// and sends it to every subscribed user. Failures for a single user are logged and
+// do not stop the delivery to the others.
+func (b *Bot) SendWeeklyReports(ctx context.Context) error {
+	subscribers, err := b.subrepo.GetReportSubscribers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get report subscribers: %w", err)
+	}
+
+	from, to := previousWeek(time.Now())
+	b.log.InfoContext(ctx, "Sending weekly reports", "subscribers", len(subscribers), "from", from, "to", to)
+
+	failed := 0
+	for _, userID := range subscribers {
+		if ctx.Err() != nil {
+			return fmt.Errorf("weekly report delivery interrupted: %w", ctx.Err())
+		}
+
+		if err = b.sendWeeklyReport(ctx, userID, from, to); err != nil {
+			b.log.WarnContext(ctx, "Failed to send weekly report", "user", userID, "error", err)
+			failed++
+		}
+
+		// Wait a bit between messages to avoid Telegram's rate limits
+		const telegramRateTimeout = 100 * time.Millisecond
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("weekly report delivery interrupted: %w", ctx.Err())
+		case <-time.After(telegramRateTimeout):
+		}
+	}
+
+	b.log.InfoContext(ctx, "Weekly reports sent", "success", len(subscribers)-failed, "failed", failed)
+	return nil
+}
+
+// sendWeeklyReport generates and sends the report for a single subscriber.
+func (b *Bot) sendWeeklyReport(ctx context.Context, userID int64, from, to time.Time) error {
+	lang := b.languageByID(ctx, userID)
+	recipient := telebot.ChatID(userID)
+
+	startTime := time.Now()
+	excelRows, err := b.formatExcelRows(ctx, userID, from, to)
+	if err != nil {
+		b.log.ErrorContext(ctx, "Failed to format excel rows for weekly report", "error", err, "user", userID)
+		// Without Hermes the report would wrongly claim there are no tasks, so it is not sent at all.
+		if hermes.IsUnavailable(err) {
+			return fmt.Errorf("failed to format weekly report: %w", err)
+		}
+	}
+	reportPath, err := writeReportFile(report.FormatXLSX, excelRows, b.reportComparison(ctx, userID, from, to))
+	b.metrics.ReportGeneration.WithLabelValues("weekly").Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		if errors.Is(err, report.ErrNoTasks) {
+			b.metrics.SentMessages.WithLabelValues("text").Inc()
+			_, err = b.bot.Send(recipient, b.localizer.Get(lang, "auto_report.no_tasks"))
+			return b.wrapWeeklyReportSendError(ctx, userID, err)
+		}
+		return fmt.Errorf("failed to generate weekly report: %w", err)
+	}
+	defer os.Remove(reportPath)
+	archived := b.archiveReport(ctx, userID, from, to, report.FormatXLSX, reportPath)
+
+	reportFile := newReportDocument(telebot.FromDisk(reportPath), from, to, report.FormatXLSX)
+	reportFile.Caption = b.localizer.GetWithData(lang, "auto_report.caption", map[string]interface{}{
+		"from": i18n.FormatDate(lang, from),
+		"to":   i18n.FormatDate(lang, to),
+	})
+
+	msg, err := b.sendReport(ctx, recipient, lang, archived, reportFile)
+	if err != nil {
+		return b.wrapWeeklyReportSendError(ctx, userID, err)
+	}
+	b.rememberReportFileID(ctx, archived.ID, msg)
+
+	return nil
+}
+
+// wrapWeeklyReportSendError records and annotates an error returned by the Telegram API.
+func (b *Bot) wrapWeeklyReportSendError(ctx context.Context, userID int64, err error) error {
+	if err != nil {
+		b.recordSendFailure(ctx, userID, sendSourceWeeklyReport, err)
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
+// previousWeek returns the bounds of the full Monday-to-Sunday week preceding now.
+func previousWeek(now time.Time) (time.Time, time.Time) {
+	const daysInWeek = 7
+	thisMonday := startOfWeek(now)
+	from := thisMonday.AddDate(0, 0, -daysInWeek)
+
+	return from, thisMonday.Add(-time.Nanosecond)
+}
+
+// startOfWeek returns the midnight of the Monday of the week containing now.
+func startOfWeek(now time.Time) time.Time {
+	const daysInWeek = 7
+	daysSinceMonday := (int(now.Weekday()) + daysInWeek - int(time.Monday)) % daysInWeek
+	return time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+}
@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"gopkg.in/telebot.v4"
+)
+
+// Flags of the active tasks filter in callback data.
+const (
+	taskFilterOldestFirst     = "o"
+	taskFilterByType          = "t"
+	taskFilterWithCoordinates = "c"
+)
+
+// encodeTaskFilter encodes the filter as a string of flags for callback data.
+func encodeTaskFilter(filter models.ActiveTaskFilter) string {
+	var flags strings.Builder
+	if filter.OldestFirst {
+		flags.WriteString(taskFilterOldestFirst)
+	}
+	if filter.ByType {
+		flags.WriteString(taskFilterByType)
+	}
+	if filter.WithCoordinates {
+		flags.WriteString(taskFilterWithCoordinates)
+	}
+	return flags.String()
+}
+
+// decodeTaskFilter decodes the flags written by encodeTaskFilter. Unknown flags are ignored.
+func decodeTaskFilter(flags string) models.ActiveTaskFilter {
+	return models.ActiveTaskFilter{
+		OldestFirst:     strings.Contains(flags, taskFilterOldestFirst),
+		ByType:          strings.Contains(flags, taskFilterByType),
+		WithCoordinates: strings.Contains(flags, taskFilterWithCoordinates),
+	}
+}
+
+// taskFilterChips returns the row of filter buttons shown above the active tasks. Every button
+// carries the filter with its own option toggled, active options are checked.
+func (b *Bot) taskFilterChips(
+	ctx context.Context,
+	tCtx telebot.Context,
+	filter models.ActiveTaskFilter,
+) []telebot.InlineButton {
+	chip := func(key string, active bool, toggled models.ActiveTaskFilter) telebot.InlineButton {
+		text := b.t(ctx, tCtx, key)
+		if active {
+			text = "✅ " + text
+		}
+		return telebot.InlineButton{Unique: "tasks_filter", Text: text, Data: encodeTaskFilter(toggled)}
+	}
+
+	oldestFirst, byType, withCoordinates := filter, filter, filter
+	oldestFirst.OldestFirst = !filter.OldestFirst
+	byType.ByType = !filter.ByType
+	withCoordinates.WithCoordinates = !filter.WithCoordinates
+
+	return []telebot.InlineButton{
+		chip("tasks.filter.oldest_first", filter.OldestFirst, oldestFirst),
+		chip("tasks.filter.by_type", filter.ByType, byType),
+		chip("tasks.filter.with_coordinates", filter.WithCoordinates, withCoordinates),
+	}
+}
+
+// activeTasksFilterHandler applies the filter carried in the callback data to the active tasks
+// and shows the first page of them in place.
+func (b *Bot) activeTasksFilterHandler(ctx telebot.Context) error {
+	b.metrics.CommandReceived.WithLabelValues("active_tasks_filter").Inc()
+	_ = ctx.Respond()
+
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	return b.showActiveTasks(timeoutCtx, ctx, 0, decodeTaskFilter(ctx.Data()))
+}
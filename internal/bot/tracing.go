@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/telebot.v4"
+)
+
+// traceContextKey is the telebot context key holding the context of the update span.
+const traceContextKey = "trace_ctx"
+
+var tracer = otel.Tracer("github.com/UnknownOlympus/oracle/internal/bot")
+
+// TracingMiddleware starts a span for every handled update. Handlers derive their contexts
+// from traceContext, so database queries, Redis commands and Hermes calls become its children.
+// The span context descends from the base context of the bot, so the work stops with the bot.
+func (b *Bot) TracingMiddleware(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(ctx telebot.Context) error {
+		spanCtx, span := tracer.Start(b.baseCtx, updateSpanName(ctx),
+			trace.WithSpanKind(trace.SpanKindServer),
+		)
+		defer span.End()
+
+		if sender := ctx.Sender(); sender != nil {
+			span.SetAttributes(attribute.Int64("telegram.user_id", sender.ID))
+		}
+		span.SetAttributes(attribute.Int("telegram.update_id", ctx.Update().ID))
+		ctx.Set(traceContextKey, spanCtx)
+
+		err := next(ctx)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// traceContext returns the context carrying the span of the update being handled.
+func traceContext(ctx telebot.Context) context.Context {
+	if spanCtx, ok := ctx.Get(traceContextKey).(context.Context); ok {
+		return spanCtx
+	}
+	return context.Background()
+}
+
+// updateSpanName names the span after the callback or command. Free text is not used,
+// as it may contain personal data.
+func updateSpanName(ctx telebot.Context) string {
+	if callback := ctx.Callback(); callback != nil {
+		return "telegram.callback " + callback.Unique
+	}
+
+	message := ctx.Message()
+	switch {
+	case message == nil:
+		return "telegram.update"
+	case strings.HasPrefix(message.Text, "/"):
+		command, _, _ := strings.Cut(message.Text, " ")
+		return "telegram.command " + command
+	case message.Location != nil:
+		return "telegram.location"
+	case message.Photo != nil:
+		return "telegram.photo"
+	case message.Document != nil:
+		return "telegram.document"
+	default:
+		return "telegram.message"
+	}
+}
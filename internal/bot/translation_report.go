@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/i18n"
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	// TranslationReportInterval is how often admins get the list of missing translations.
+	TranslationReportInterval = 24 * time.Hour
+	// translationReportSize limits the keys listed in the report, the most requested go first.
+	translationReportSize = 30
+)
+
+// SendTranslationReport sends the admins the translations which were missing since the last report,
+// so gaps in the locales are fixed before users report them. Nothing is sent if none were missing.
+func (b *Bot) SendTranslationReport(ctx context.Context) error {
+	misses := b.localizer.TakeMisses()
+	if len(misses) == 0 {
+		return nil
+	}
+
+	admins, err := b.cachedAdmins(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get admins: %w", err)
+	}
+
+	adminIDs := make([]int64, 0, len(admins))
+	for _, admin := range admins {
+		adminIDs = append(adminIDs, admin.TelegramID)
+	}
+	languages := b.languagesByID(ctx, adminIDs)
+
+	b.log.InfoContext(ctx, "Sending missing translations report", "misses", len(misses), "admins", len(admins))
+	for _, adminID := range adminIDs {
+		text := b.formatTranslationReport(languages[adminID], misses)
+		b.metrics.SentMessages.WithLabelValues("text").Inc()
+		if _, err = b.bot.Send(telebot.ChatID(adminID), text); err != nil {
+			b.log.WarnContext(ctx, "Failed to send missing translations report", "admin", adminID, "error", err)
+		}
+
+		// Wait a bit between messages to avoid Telegram's rate limits
+		const telegramRateTimeout = 100 * time.Millisecond
+		time.Sleep(telegramRateTimeout)
+	}
+
+	return nil
+}
+
+// formatTranslationReport lists the missing translations, at most translationReportSize of them.
+// It is sent as plain text, as the keys contain underscores.
+func (b *Bot) formatTranslationReport(lang string, misses []i18n.Miss) string {
+	lines := []string{b.localizer.GetWithData(lang, "admin.translations.title", map[string]interface{}{
+		"count": len(misses),
+	})}
+	for i, miss := range misses {
+		if i == translationReportSize {
+			lines = append(lines, b.localizer.GetWithData(lang, "admin.translations.more", map[string]interface{}{
+				"count": len(misses) - translationReportSize,
+			}))
+			break
+		}
+		lines = append(lines, b.localizer.GetWithData(lang, "admin.translations.entry", map[string]interface{}{
+			"lang":  miss.Lang,
+			"key":   miss.Key,
+			"kind":  b.localizer.Get(lang, "admin.translations.kind."+string(miss.Kind)),
+			"count": miss.Count,
+		}))
+	}
+
+	return strings.Join(lines, "\n")
+}
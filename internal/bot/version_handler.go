@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/version"
+	"gopkg.in/telebot.v4"
+)
+
+// versionHandler shows the build of the bot and the latest changelog entry, so users reporting
+// a bug can tell which version they use.
+func (b *Bot) versionHandler(ctx telebot.Context) error {
+	timeoutCtx, cancel := b.updateContext(ctx, opQuery)
+	defer cancel()
+
+	b.metrics.CommandReceived.WithLabelValues("version").Inc()
+	lang := b.getUserLanguage(timeoutCtx, ctx)
+
+	text := b.localizer.GetWithData(lang, "version.info", map[string]interface{}{
+		"version": version.Version,
+		"commit":  version.Commit,
+		"date":    version.Date,
+	})
+	if releases := version.Changelog(); len(releases) > 0 {
+		text += "\n\n" + b.formatRelease(lang, releases[0])
+	}
+
+	b.metrics.SentMessages.WithLabelValues("text").Inc()
+	return ctx.Send(text, telebot.NoPreview)
+}
+
+// formatRelease renders the changes of the release grouped by section. The changelog is written
+// in English, only the heading is translated.
+func (b *Bot) formatRelease(lang string, release version.Release) string {
+	lines := []string{b.localizer.GetWithData(lang, "version.whats_new", map[string]interface{}{
+		"release": release.Title,
+	})}
+	for _, section := range release.Sections {
+		lines = append(lines, "", section.Title+":")
+		for _, item := range section.Items {
+			lines = append(lines, "• "+item)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
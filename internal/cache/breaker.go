@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Default circuit breaker settings: the cache is bypassed after five consecutive Redis
+// failures and probed again every 30 seconds.
+const (
+	DefaultFailureThreshold = 5
+	DefaultCooldown         = 30 * time.Second
+)
+
+// Breaker is a circuit breaker guarding the calls to Redis, and to Hermes. It opens after a number of
+// consecutive failures, and after the cooldown lets a single probe through: a successful
+// probe closes it, a failed one keeps it open for another cooldown.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	open      bool
+	probing   bool
+	openedAt  time.Time
+}
+
+// NewBreaker creates a closed breaker that opens after threshold consecutive failures.
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown}
+}
+
+// Allow reports whether a call may go to Redis.
+func (b *Breaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.cooldown {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// Success records a successful call. It reports true if the call closed an open breaker.
+func (b *Breaker) Success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recovered := b.open
+	b.failures = 0
+	b.open = false
+	b.probing = false
+	return recovered
+}
+
+// Failure records a failed call. It reports true if the call opened a closed breaker.
+func (b *Breaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		// A failed probe restarts the cooldown.
+		b.probing = false
+		b.openedAt = time.Now()
+		return false
+	}
+
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+
+	b.open = true
+	b.openedAt = time.Now()
+	return true
+}
+
+// Open reports whether the breaker is open, i.e. Redis is considered unavailable.
+func (b *Breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.open
+}
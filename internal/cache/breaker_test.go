@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		t.Parallel()
+		breaker := cache.NewBreaker(3, time.Hour)
+
+		assert.False(t, breaker.Failure())
+		assert.False(t, breaker.Failure())
+		assert.True(t, breaker.Allow())
+		assert.True(t, breaker.Failure())
+
+		assert.True(t, breaker.Open())
+		assert.False(t, breaker.Allow())
+	})
+
+	t.Run("success resets the failure count", func(t *testing.T) {
+		t.Parallel()
+		breaker := cache.NewBreaker(2, time.Hour)
+
+		breaker.Failure()
+		assert.False(t, breaker.Success())
+		assert.False(t, breaker.Failure())
+
+		assert.False(t, breaker.Open())
+	})
+
+	t.Run("lets a single probe through after the cooldown", func(t *testing.T) {
+		t.Parallel()
+		breaker := cache.NewBreaker(1, 10*time.Millisecond)
+		breaker.Failure()
+
+		time.Sleep(20 * time.Millisecond)
+
+		assert.True(t, breaker.Allow())
+		assert.False(t, breaker.Allow(), "only one probe at a time")
+		assert.True(t, breaker.Success())
+		assert.False(t, breaker.Open())
+		assert.True(t, breaker.Allow())
+	})
+
+	t.Run("failed probe restarts the cooldown", func(t *testing.T) {
+		t.Parallel()
+		breaker := cache.NewBreaker(1, 10*time.Millisecond)
+		breaker.Failure()
+
+		time.Sleep(20 * time.Millisecond)
+
+		assert.True(t, breaker.Allow())
+		assert.False(t, breaker.Failure())
+		assert.True(t, breaker.Open())
+		assert.False(t, breaker.Allow())
+	})
+}
@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss is returned by Get when the key is not cached or the cache is bypassed.
+var ErrMiss = errors.New("cache: miss")
+
+// Cache is a Redis-backed cache that degrades gracefully. After repeated Redis failures
+// the circuit breaker opens and the cache is bypassed: reads miss and writes are dropped,
+// so callers fall back to the database without special handling. Redis is probed again
+// after the breaker cooldown and the cache recovers on its own.
+type Cache struct {
+	log     *slog.Logger
+	client  redis.UniversalClient
+	breaker *Breaker
+	metrics *metrics.Metrics
+}
+
+// New creates a cache on top of the Redis client guarded by the breaker.
+func New(log *slog.Logger, client redis.UniversalClient, m *metrics.Metrics, breaker *Breaker) *Cache {
+	return &Cache{
+		log:     log.With(slog.String("component", "cache")),
+		client:  client,
+		breaker: breaker,
+		metrics: m,
+	}
+}
+
+// Get returns the cached value of the key. It returns ErrMiss if the key does not exist
+// or the cache is bypassed.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+	if !c.allow("get") {
+		return nil, ErrMiss
+	}
+
+	value, err := c.client.Get(ctx, key).Bytes()
+	switch {
+	case err == nil:
+		c.success(ctx)
+		c.metrics.CacheOps.WithLabelValues("get", "hit").Inc()
+		return value, nil
+	case errors.Is(err, redis.Nil):
+		c.success(ctx)
+		c.metrics.CacheOps.WithLabelValues("get", "miss").Inc()
+		return nil, ErrMiss
+	default:
+		c.failure(ctx, err)
+		c.metrics.CacheOps.WithLabelValues("get", "error").Inc()
+		return nil, fmt.Errorf("failed to get %q from cache: %w", key, err)
+	}
+}
+
+// Set caches the value under the key for the given time. It does nothing if the cache is bypassed.
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if !c.allow("set") {
+		return nil
+	}
+
+	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+		c.failure(ctx, err)
+		c.metrics.CacheOps.WithLabelValues("set", "error").Inc()
+		return fmt.Errorf("failed to set %q in cache: %w", key, err)
+	}
+
+	c.success(ctx)
+	c.metrics.CacheOps.WithLabelValues("set", "success").Inc()
+	return nil
+}
+
+// Del removes the key from the cache. It does nothing if the cache is bypassed.
+func (c *Cache) Del(ctx context.Context, key string) error {
+	if !c.allow("del") {
+		return nil
+	}
+
+	if err := c.client.Del(ctx, key).Err(); err != nil {
+		c.failure(ctx, err)
+		c.metrics.CacheOps.WithLabelValues("del", "error").Inc()
+		return fmt.Errorf("failed to delete %q from cache: %w", key, err)
+	}
+
+	c.success(ctx)
+	c.metrics.CacheOps.WithLabelValues("del", "success").Inc()
+	return nil
+}
+
+// GetMany returns the cached values of the keys with a single round trip. Keys which are
+// not cached are absent from the result, all of them are when the cache is bypassed.
+func (c *Cache) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 || !c.allow("mget") {
+		return values, nil
+	}
+
+	result, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		c.failure(ctx, err)
+		c.metrics.CacheOps.WithLabelValues("mget", "error").Inc()
+		return values, fmt.Errorf("failed to get %d keys from cache: %w", len(keys), err)
+	}
+
+	c.success(ctx)
+	for i, value := range result {
+		// MGET answers with nil for missing keys and with strings for the rest.
+		if str, ok := value.(string); ok {
+			values[keys[i]] = []byte(str)
+			c.metrics.CacheOps.WithLabelValues("mget", "hit").Inc()
+		} else {
+			c.metrics.CacheOps.WithLabelValues("mget", "miss").Inc()
+		}
+	}
+
+	return values, nil
+}
+
+// DelPrefix removes all keys starting with the prefix and returns how many were removed.
+// Keys are looked up with SCAN, so Redis is not blocked by large key spaces.
+// It does nothing if the cache is bypassed.
+func (c *Cache) DelPrefix(ctx context.Context, prefix string) (int64, error) {
+	if !c.allow("del") {
+		return 0, nil
+	}
+
+	const batchSize = 500
+	var removed int64
+	iter := c.client.Scan(ctx, 0, prefix+"*", batchSize).Iterator()
+	batch := make([]string, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := c.client.Del(ctx, batch...).Result()
+		removed += n
+		batch = batch[:0]
+		return err
+	}
+
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return c.delPrefixFailed(ctx, prefix, removed, err)
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return c.delPrefixFailed(ctx, prefix, removed, err)
+	}
+	if err := flush(); err != nil {
+		return c.delPrefixFailed(ctx, prefix, removed, err)
+	}
+
+	c.success(ctx)
+	c.metrics.CacheOps.WithLabelValues("del", "success").Inc()
+	return removed, nil
+}
+
+func (c *Cache) delPrefixFailed(ctx context.Context, prefix string, removed int64, err error) (int64, error) {
+	c.failure(ctx, err)
+	c.metrics.CacheOps.WithLabelValues("del", "error").Inc()
+	return removed, fmt.Errorf("failed to delete keys with prefix %q from cache: %w", prefix, err)
+}
+
+// allow reports whether the operation may go to Redis, counting it as degraded otherwise.
+func (c *Cache) allow(operation string) bool {
+	if c.breaker.Allow() {
+		return true
+	}
+
+	c.metrics.CacheDegraded.WithLabelValues(operation).Inc()
+	return false
+}
+
+// success records a successful Redis call and reports the recovery of the cache.
+func (c *Cache) success(ctx context.Context) {
+	if c.breaker.Success() {
+		c.metrics.CacheCircuitOpen.Set(0)
+		c.log.InfoContext(ctx, "Redis is available again, cache enabled")
+	}
+}
+
+// failure records a failed Redis call. Calls canceled by the caller say nothing about Redis
+// and are ignored.
+func (c *Cache) failure(ctx context.Context, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if c.breaker.Failure() {
+		c.metrics.CacheCircuitOpen.Set(1)
+		c.log.WarnContext(ctx, "Redis is failing, cache bypassed", "error", err)
+	}
+}
@@ -0,0 +1,95 @@
+package cache_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUnavailableCache returns a cache backed by a Redis server that does not exist.
+func newUnavailableCache(t *testing.T, breaker *cache.Breaker) (*cache.Cache, *metrics.Metrics) {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 50 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	t.Cleanup(func() { _ = client.Close() })
+
+	appMetrics := metrics.NewMetrics(prometheus.NewRegistry())
+	return cache.New(slog.New(slog.DiscardHandler), client, appMetrics, breaker), appMetrics
+}
+
+func TestCache_Degradation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bypasses redis after repeated failures", func(t *testing.T) {
+		t.Parallel()
+		c, appMetrics := newUnavailableCache(t, cache.NewBreaker(2, time.Hour))
+		ctx := t.Context()
+
+		_, err := c.Get(ctx, "key")
+		require.Error(t, err)
+		require.NotErrorIs(t, err, cache.ErrMiss)
+		require.Error(t, c.Set(ctx, "key", "value", time.Minute))
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheCircuitOpen), 0)
+
+		_, err = c.Get(ctx, "key")
+		require.ErrorIs(t, err, cache.ErrMiss)
+		require.NoError(t, c.Set(ctx, "key", "value", time.Minute))
+		require.NoError(t, c.Del(ctx, "key"))
+
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheDegraded.WithLabelValues("get")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheDegraded.WithLabelValues("set")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheDegraded.WithLabelValues("del")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheOps.WithLabelValues("get", "error")), 0)
+	})
+
+	t.Run("bulk operations are bypassed while redis is failing", func(t *testing.T) {
+		t.Parallel()
+		c, appMetrics := newUnavailableCache(t, cache.NewBreaker(2, time.Hour))
+		ctx := t.Context()
+
+		values, err := c.GetMany(ctx, []string{"a", "b"})
+		require.Error(t, err)
+		assert.Empty(t, values)
+		_, err = c.DelPrefix(ctx, "prefix:")
+		require.Error(t, err)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheCircuitOpen), 0)
+
+		values, err = c.GetMany(ctx, []string{"a", "b"})
+		require.NoError(t, err)
+		assert.Empty(t, values)
+		removed, err := c.DelPrefix(ctx, "prefix:")
+		require.NoError(t, err)
+		assert.Zero(t, removed)
+
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheDegraded.WithLabelValues("mget")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheDegraded.WithLabelValues("del")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheOps.WithLabelValues("mget", "error")), 0)
+		assert.InDelta(t, 1, testutil.ToFloat64(appMetrics.CacheOps.WithLabelValues("del", "error")), 0)
+	})
+
+	t.Run("canceled calls do not open the breaker", func(t *testing.T) {
+		t.Parallel()
+		breaker := cache.NewBreaker(1, time.Hour)
+		c, _ := newUnavailableCache(t, breaker)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err := c.Get(ctx, "key")
+		require.Error(t, err)
+		assert.False(t, breaker.Open())
+	})
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Fetch returns the value cached under the key, or loads it and caches it for ttl.
+// Values are stored as JSON. Cache failures only cost a trip to the loader and are logged,
+// the returned error is always the error of the loader.
+func Fetch[T any](
+	ctx context.Context,
+	c *Cache,
+	key string,
+	ttl time.Duration,
+	load func(ctx context.Context) (T, error),
+) (T, error) {
+	cached, err := c.Get(ctx, key)
+	switch {
+	case err == nil:
+		var value T
+		if err = json.Unmarshal(cached, &value); err == nil {
+			return value, nil
+		}
+		c.metrics.CacheOps.WithLabelValues("decode", "error").Inc()
+		c.log.WarnContext(ctx, "Failed to decode cached value", "error", err, "key", key)
+	case !errors.Is(err, ErrMiss):
+		c.log.WarnContext(ctx, "Failed to read from cache", "error", err, "key", key)
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		c.metrics.CacheOps.WithLabelValues("encode", "error").Inc()
+		c.log.ErrorContext(ctx, "Failed to encode value for caching", "error", err, "key", key)
+		return value, nil
+	}
+	if err = c.Set(ctx, key, encoded, ttl); err != nil {
+		c.log.WarnContext(ctx, "Failed to write to cache", "error", err, "key", key)
+	}
+
+	return value, nil
+}
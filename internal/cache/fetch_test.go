@@ -0,0 +1,46 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error - loader fails", func(t *testing.T) {
+		t.Parallel()
+		c, _ := newUnavailableCache(t, cache.NewBreaker(1, time.Hour))
+		loadErr := errors.New("db down")
+
+		_, err := cache.Fetch(t.Context(), c, "key", time.Minute, func(context.Context) (int, error) {
+			return 0, loadErr
+		})
+
+		require.ErrorIs(t, err, loadErr)
+	})
+
+	t.Run("success - loads the value while redis is down", func(t *testing.T) {
+		t.Parallel()
+		c, _ := newUnavailableCache(t, cache.NewBreaker(1, time.Hour))
+		calls := 0
+		load := func(context.Context) ([]string, error) {
+			calls++
+			return []string{"a", "b"}, nil
+		}
+
+		// The first call fails against Redis and opens the breaker, the second one bypasses it.
+		for range 2 {
+			value, err := cache.Fetch(t.Context(), c, "key", time.Minute, load)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"a", "b"}, value)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
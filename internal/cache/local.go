@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Local caches values in the memory of the process for a short time. It takes repeated lookups
+// of hot paths off the database, where a round trip to Redis would not save much. Concurrent
+// loads of the same key share one call of the loader. Errors are not cached.
+type Local[K comparable, V any] struct {
+	ttl     time.Duration
+	timeout time.Duration
+	now     func() time.Time
+	group   singleflight.Group
+
+	mu      sync.Mutex
+	entries map[K]localEntry[V]
+	// generation is bumped by Invalidate. A load started before it is not cached, as it may
+	// have read the data the invalidation was meant to drop.
+	generation uint64
+}
+
+// localEntry is a cached value with the time it expires at.
+type localEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// NewLocal creates a Local cache keeping values for ttl, whose loads may take up to timeout.
+func NewLocal[K comparable, V any](ttl, timeout time.Duration) *Local[K, V] {
+	return &Local[K, V]{ttl: ttl, timeout: timeout, now: time.Now, entries: make(map[K]localEntry[V])}
+}
+
+// Get returns the value cached under the key, or loads and caches it. The load is shared with
+// concurrent callers, so it is not canceled with ctx; it keeps the values of ctx and is limited
+// by the timeout instead. The caller stops waiting for it when ctx is done.
+func (l *Local[K, V]) Get(ctx context.Context, key K, load func(ctx context.Context) (V, error)) (V, error) {
+	if value, ok := l.lookup(key); ok {
+		return value, nil
+	}
+
+	results := l.group.DoChan(fmt.Sprint(key), func() (interface{}, error) {
+		// A load finishing between the lookup and this call may have cached the value.
+		if value, ok := l.lookup(key); ok {
+			return value, nil
+		}
+
+		l.mu.Lock()
+		generation := l.generation
+		l.mu.Unlock()
+
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.timeout)
+		defer cancel()
+
+		value, err := load(loadCtx)
+		if err != nil {
+			return value, err
+		}
+
+		l.mu.Lock()
+		if l.generation == generation {
+			l.entries[key] = localEntry[V]{value: value, expires: l.now().Add(l.ttl)}
+		}
+		l.mu.Unlock()
+		return value, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	case result := <-results:
+		value, _ := result.Val.(V)
+		return value, result.Err
+	}
+}
+
+// Invalidate drops the value cached under the key. A load of the key already running is not
+// shared with later callers, and its value is not cached.
+func (l *Local[K, V]) Invalidate(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+	l.generation++
+	l.group.Forget(fmt.Sprint(key))
+}
+
+// lookup returns the cached value of the key unless it has expired. Expired values are dropped.
+func (l *Local[K, V]) lookup(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !l.now().Before(entry.expires) {
+		delete(l.entries, key)
+		var zero V
+		return zero, false
+	}
+
+	return entry.value, true
+}
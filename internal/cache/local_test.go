@@ -0,0 +1,186 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - caches the value until it expires", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[int64, string](50*time.Millisecond, time.Second)
+		calls := 0
+		load := func(context.Context) (string, error) {
+			calls++
+			return "Ivan", nil
+		}
+
+		for range 2 {
+			value, err := local.Get(t.Context(), 1, load)
+			require.NoError(t, err)
+			assert.Equal(t, "Ivan", value)
+		}
+		assert.Equal(t, 1, calls)
+
+		time.Sleep(60 * time.Millisecond)
+		_, err := local.Get(t.Context(), 1, load)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("success - invalidate drops the value", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[string, int](time.Minute, time.Second)
+		calls := 0
+		load := func(context.Context) (int, error) {
+			calls++
+			return calls, nil
+		}
+
+		first, _ := local.Get(t.Context(), "admins", load)
+		local.Invalidate("admins")
+		second, _ := local.Get(t.Context(), "admins", load)
+
+		assert.Equal(t, 1, first)
+		assert.Equal(t, 2, second)
+	})
+
+	t.Run("success - invalidate drops a running load", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[string, int](time.Minute, time.Second)
+		var calls atomic.Int32
+		release := make(chan struct{})
+		load := func(ctx context.Context) (int, error) {
+			call := calls.Add(1)
+			if call == 1 {
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				}
+			}
+			return int(call), nil
+		}
+
+		stale := make(chan int, 1)
+		go func() {
+			value, _ := local.Get(context.Background(), "admins", load)
+			stale <- value
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		local.Invalidate("admins")
+		fresh, err := local.Get(t.Context(), "admins", load)
+		require.NoError(t, err)
+		close(release)
+		<-stale
+
+		cached, err := local.Get(t.Context(), "admins", load)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fresh)
+		assert.Equal(t, 2, cached)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("success - concurrent loads share one call", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[int64, string](time.Minute, time.Second)
+		var calls atomic.Int32
+		release := make(chan struct{})
+		load := func(context.Context) (string, error) {
+			calls.Add(1)
+			<-release
+			return "Ivan", nil
+		}
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := local.Get(context.Background(), 1, load)
+				assert.NoError(t, err)
+				assert.Equal(t, "Ivan", value)
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("success - a canceled caller does not fail the others", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[int64, string](time.Minute, time.Second)
+		release := make(chan struct{})
+		load := func(ctx context.Context) (string, error) {
+			select {
+			case <-release:
+				return "Ivan", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		canceledCtx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error, 1)
+		go func() {
+			_, err := local.Get(canceledCtx, 1, load)
+			canceled <- err
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		waiting := make(chan string, 1)
+		go func() {
+			value, err := local.Get(context.Background(), 1, load)
+			assert.NoError(t, err)
+			waiting <- value
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-canceled, context.Canceled)
+		close(release)
+		assert.Equal(t, "Ivan", <-waiting)
+	})
+
+	t.Run("error - the load is limited by the timeout", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[int64, string](time.Minute, 20*time.Millisecond)
+		load := func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+
+		_, err := local.Get(context.Background(), 1, load)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("error - failures are not cached", func(t *testing.T) {
+		t.Parallel()
+		local := cache.NewLocal[int64, string](time.Minute, time.Second)
+		loadErr := errors.New("db down")
+		calls := 0
+		load := func(context.Context) (string, error) {
+			calls++
+			return "", loadErr
+		}
+
+		for range 2 {
+			_, err := local.Get(t.Context(), 1, load)
+			require.ErrorIs(t, err, loadErr)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
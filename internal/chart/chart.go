@@ -0,0 +1,84 @@
+package chart
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	gochart "github.com/wcharczuk/go-chart/v2"
+)
+
+// ErrNoData is returned when there is nothing to draw, e.g. all bars are zero.
+var ErrNoData = errors.New("no data to render")
+
+// Bar is a single labeled value of a bar chart.
+type Bar struct {
+	Label string
+	Value int
+}
+
+// Chart dimensions in pixels. The width grows with the number of bars, so long
+// lists of task types stay readable.
+const (
+	height      = 480
+	minWidth    = 640
+	barStep     = 110
+	barWidth    = 70
+	maxYTicks   = 5
+	labelMargin = 60
+)
+
+// RenderBars renders the bars as a PNG image with the given title.
+func RenderBars(title string, bars []Bar) (*bytes.Buffer, error) {
+	maxValue := 0
+	values := make([]gochart.Value, 0, len(bars))
+	for _, bar := range bars {
+		maxValue = max(maxValue, bar.Value)
+		values = append(values, gochart.Value{Label: bar.Label, Value: float64(bar.Value)})
+	}
+	if maxValue == 0 {
+		return nil, ErrNoData
+	}
+
+	ticks := integerTicks(maxValue)
+	chart := gochart.BarChart{
+		Title:    title,
+		Width:    max(minWidth, len(bars)*barStep+labelMargin),
+		Height:   height,
+		BarWidth: barWidth,
+		Background: gochart.Style{
+			Padding: gochart.Box{Top: labelMargin, Left: 20, Right: 20, Bottom: labelMargin},
+		},
+		XAxis: gochart.Style{
+			TextWrap: gochart.TextWrapWord,
+		},
+		YAxis: gochart.YAxis{
+			Range: &gochart.ContinuousRange{Min: 0, Max: ticks[len(ticks)-1].Value},
+			Ticks: ticks,
+		},
+		UseBaseValue: true,
+		BaseValue:    0,
+		Bars:         values,
+	}
+
+	buffer := new(bytes.Buffer)
+	if err := chart.Render(gochart.PNG, buffer); err != nil {
+		return nil, fmt.Errorf("failed to render bar chart: %w", err)
+	}
+
+	return buffer, nil
+}
+
+// integerTicks returns whole-number ticks from zero up to at least maxValue, so counts
+// are never labeled with fractions.
+func integerTicks(maxValue int) []gochart.Tick {
+	step := int(math.Ceil(float64(maxValue) / maxYTicks))
+	ticks := make([]gochart.Tick, 0, maxYTicks+1)
+	for value := 0; ; value += step {
+		ticks = append(ticks, gochart.Tick{Value: float64(value), Label: fmt.Sprintf("%d", value)})
+		if value >= maxValue {
+			return ticks
+		}
+	}
+}
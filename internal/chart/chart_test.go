@@ -0,0 +1,59 @@
+package chart_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/chart"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBars(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		buffer, err := chart.RenderBars("Статистика", []chart.Bar{
+			{Label: "Підключення", Value: 7},
+			{Label: "Ремонт", Value: 3},
+			{Label: "Repair", Value: 0},
+		})
+		require.NoError(t, err)
+
+		img, err := png.Decode(bytes.NewReader(buffer.Bytes()))
+		require.NoError(t, err)
+		assert.Positive(t, img.Bounds().Dx())
+		assert.Positive(t, img.Bounds().Dy())
+	})
+
+	t.Run("single bar", func(t *testing.T) {
+		t.Parallel()
+		_, err := chart.RenderBars("Stats", []chart.Bar{{Label: "Repair", Value: 1}})
+		require.NoError(t, err)
+	})
+
+	t.Run("wide chart for many bars", func(t *testing.T) {
+		t.Parallel()
+		bars := make([]chart.Bar, 12)
+		for i := range bars {
+			bars[i] = chart.Bar{Label: "Type", Value: i + 1}
+		}
+		buffer, err := chart.RenderBars("Stats", bars)
+		require.NoError(t, err)
+
+		cfg, err := png.DecodeConfig(bytes.NewReader(buffer.Bytes()))
+		require.NoError(t, err)
+		assert.Greater(t, cfg.Width, 640)
+	})
+
+	t.Run("error - no data", func(t *testing.T) {
+		t.Parallel()
+		_, err := chart.RenderBars("Stats", nil)
+		require.ErrorIs(t, err, chart.ErrNoData)
+
+		_, err = chart.RenderBars("Stats", []chart.Bar{{Label: "Repair", Value: 0}})
+		require.ErrorIs(t, err, chart.ErrNoData)
+	})
+}
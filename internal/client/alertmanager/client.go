@@ -0,0 +1,89 @@
+package alertmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// maxErrorBody limits how much of an error response is included in the returned error.
+const maxErrorBody = 512
+
+// Matcher selects alerts by a label value.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// Silence mutes the alerts matching all of its matchers between StartsAt and EndsAt.
+type Silence struct {
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// MatchLabels returns matchers selecting the alerts with exactly these label values,
+// sorted by label name.
+func MatchLabels(labels map[string]string) []Matcher {
+	matchers := make([]Matcher, 0, len(labels))
+	for name, value := range labels {
+		matchers = append(matchers, Matcher{Name: name, Value: value, IsEqual: true})
+	}
+	sort.Slice(matchers, func(i, j int) bool { return matchers[i].Name < matchers[j].Name })
+
+	return matchers
+}
+
+// Client talks to the Alertmanager v2 API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client of the Alertmanager at baseURL, e.g. http://alertmanager:9093.
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	return &Client{baseURL: baseURL, http: &http.Client{Timeout: timeout}}
+}
+
+// CreateSilence creates the silence and returns its ID.
+func (c *Client) CreateSilence(ctx context.Context, silence Silence) (string, error) {
+	body, err := json.Marshal(silence)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode silence: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v2/silences", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create silence request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send silence request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("alertmanager rejected silence: %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+
+	var result struct {
+		SilenceID string `json:"silenceID"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode silence response: %w", err)
+	}
+
+	return result.SilenceID, nil
+}
@@ -0,0 +1,69 @@
+package alertmanager_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/client/alertmanager"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLabels(t *testing.T) {
+	t.Parallel()
+
+	matchers := alertmanager.MatchLabels(map[string]string{"job": "oracle", "alertname": "HighLatency"})
+
+	assert.Equal(t, []alertmanager.Matcher{
+		{Name: "alertname", Value: "HighLatency", IsEqual: true},
+		{Name: "job", Value: "oracle", IsEqual: true},
+	}, matchers)
+}
+
+func TestClient_CreateSilence(t *testing.T) {
+	t.Parallel()
+
+	startsAt := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	silence := alertmanager.Silence{
+		Matchers:  alertmanager.MatchLabels(map[string]string{"alertname": "HighLatency"}),
+		StartsAt:  startsAt,
+		EndsAt:    startsAt.Add(2 * time.Hour),
+		CreatedBy: "admin",
+		Comment:   "Silenced from Telegram",
+	}
+
+	t.Run("success - returns the silence id", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/api/v2/silences", r.URL.Path)
+
+			var received alertmanager.Silence
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			assert.Equal(t, silence, received)
+
+			_, _ = w.Write([]byte(`{"silenceID":"7d6b1b5e"}`))
+		}))
+		defer server.Close()
+
+		id, err := alertmanager.NewClient(server.URL, time.Second).CreateSilence(t.Context(), silence)
+
+		require.NoError(t, err)
+		assert.Equal(t, "7d6b1b5e", id)
+	})
+
+	t.Run("error - rejected by alertmanager", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "invalid matchers", http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		_, err := alertmanager.NewClient(server.URL, time.Second).CreateSilence(t.Context(), silence)
+
+		require.ErrorContains(t, err, "alertmanager rejected silence: 400 Bad Request: invalid matchers")
+	})
+}
@@ -0,0 +1,75 @@
+package hermes
+
+import (
+	"context"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnavailable is returned without calling Hermes while the circuit breaker is open.
+// It carries the Unavailable status code, the same one an unreachable Hermes answers with.
+var ErrUnavailable = status.Error(codes.Unavailable, "hermes is temporarily unavailable")
+
+// BreakerInterceptor returns a unary client interceptor that rejects calls with ErrUnavailable
+// while the breaker is open. Only errors meaning Hermes is down or too slow count as failures,
+// errors about the request itself, e.g. NotFound, keep the breaker closed.
+// circuitOpen is set to 1 while the breaker is open.
+func BreakerInterceptor(breaker *cache.Breaker, circuitOpen prometheus.Gauge) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		fullMethod string,
+		req, reply any,
+		conn *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if !breaker.Allow() {
+			return ErrUnavailable
+		}
+
+		err := invoker(ctx, fullMethod, req, reply, conn, opts...)
+		if IsUnavailable(err) {
+			if breaker.Failure() {
+				circuitOpen.Set(1)
+			}
+		} else if breaker.Success() {
+			circuitOpen.Set(0)
+		}
+
+		return err
+	}
+}
+
+// DeadlineInterceptor returns a unary client interceptor that limits every call to timeout.
+// Calls whose context already has an earlier deadline keep it.
+func DeadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		fullMethod string,
+		req, reply any,
+		conn *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return invoker(ctx, fullMethod, req, reply, conn, opts...)
+	}
+}
+
+// IsUnavailable reports whether the error means Hermes is down or did not answer in time,
+// as opposed to rejecting the request.
+func IsUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
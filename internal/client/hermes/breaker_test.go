@@ -0,0 +1,121 @@
+package hermes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreakerInterceptor(t *testing.T) {
+	t.Parallel()
+	const fullMethod = "/scraper.ScraperService/AddComment"
+
+	invokerReturning := func(calls *int, err error) grpc.UnaryInvoker {
+		return func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+			*calls++
+			return err
+		}
+	}
+
+	t.Run("rejects calls once hermes keeps failing", func(t *testing.T) {
+		t.Parallel()
+		circuitOpen := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"})
+		interceptor := hermes.BreakerInterceptor(cache.NewBreaker(2, time.Hour), circuitOpen)
+		calls := 0
+		invoker := invokerReturning(&calls, status.Error(codes.Unavailable, "hermes is down"))
+
+		for range 2 {
+			err := interceptor(t.Context(), fullMethod, nil, nil, nil, invoker)
+			require.True(t, hermes.IsUnavailable(err))
+		}
+		err := interceptor(t.Context(), fullMethod, nil, nil, nil, invoker)
+
+		require.ErrorIs(t, err, hermes.ErrUnavailable)
+		assert.Equal(t, 2, calls)
+		assert.InDelta(t, 1, testutil.ToFloat64(circuitOpen), 0)
+	})
+
+	t.Run("request errors keep the breaker closed", func(t *testing.T) {
+		t.Parallel()
+		circuitOpen := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"})
+		interceptor := hermes.BreakerInterceptor(cache.NewBreaker(1, time.Hour), circuitOpen)
+		calls := 0
+		invoker := invokerReturning(&calls, status.Error(codes.NotFound, "no such task"))
+
+		for range 3 {
+			err := interceptor(t.Context(), fullMethod, nil, nil, nil, invoker)
+			require.False(t, hermes.IsUnavailable(err))
+		}
+
+		assert.Equal(t, 3, calls)
+		assert.InDelta(t, 0, testutil.ToFloat64(circuitOpen), 0)
+	})
+
+	t.Run("successful probe closes the breaker", func(t *testing.T) {
+		t.Parallel()
+		circuitOpen := prometheus.NewGauge(prometheus.GaugeOpts{Name: "test"})
+		interceptor := hermes.BreakerInterceptor(cache.NewBreaker(1, 0), circuitOpen)
+		calls := 0
+
+		err := interceptor(t.Context(), fullMethod, nil, nil, nil,
+			invokerReturning(&calls, status.Error(codes.DeadlineExceeded, "too slow")))
+		require.Error(t, err)
+		assert.InDelta(t, 1, testutil.ToFloat64(circuitOpen), 0)
+
+		err = interceptor(t.Context(), fullMethod, nil, nil, nil, invokerReturning(&calls, nil))
+
+		require.NoError(t, err)
+		assert.InDelta(t, 0, testutil.ToFloat64(circuitOpen), 0)
+	})
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	t.Parallel()
+
+	deadlineOf := func(ctx context.Context, timeout time.Duration) time.Time {
+		var deadline time.Time
+		interceptor := hermes.DeadlineInterceptor(timeout)
+		_ = interceptor(ctx, "/scraper.ScraperService/AddComment", nil, nil, nil,
+			func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				deadline, _ = ctx.Deadline()
+				return nil
+			})
+		return deadline
+	}
+
+	t.Run("adds the deadline", func(t *testing.T) {
+		t.Parallel()
+
+		deadline := deadlineOf(t.Context(), time.Minute)
+
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	})
+
+	t.Run("keeps an earlier deadline", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+		defer cancel()
+		want, _ := ctx.Deadline()
+
+		assert.Equal(t, want, deadlineOf(ctx, time.Minute))
+	})
+}
+
+func TestIsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, hermes.IsUnavailable(hermes.ErrUnavailable))
+	assert.True(t, hermes.IsUnavailable(status.Error(codes.DeadlineExceeded, "too slow")))
+	assert.False(t, hermes.IsUnavailable(status.Error(codes.NotFound, "no such task")))
+	assert.False(t, hermes.IsUnavailable(nil))
+}
@@ -4,11 +4,17 @@ import (
 	"fmt"
 
 	pb "github.com/UnknownOlympus/olympus-protos/gen/go/scraper/olympus"
+	"github.com/UnknownOlympus/oracle/internal/cache"
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
-func NewClient(grpcAddr string) (pb.ScraperServiceClient, *grpc.ClientConn, error) {
+// NewClient connects to Hermes, over TLS and with the bearer token if configured. Every call
+// is traced, measured into appMetrics and limited to the configured timeout, retries included.
+// After repeated failures calls are rejected with ErrUnavailable until Hermes answers a probe again.
+func NewClient(cfg config.Hermes, appMetrics *metrics.Metrics) (pb.ScraperServiceClient, *grpc.ClientConn, error) {
 	retrypolicy := `{
 		"methodConfig": [{
 			"name": [{}],
@@ -22,11 +28,27 @@ func NewClient(grpcAddr string) (pb.ScraperServiceClient, *grpc.ClientConn, erro
 		}]
 	}`
 
-	conn, err := grpc.NewClient(
-		grpcAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	creds, err := transportCredentials(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	breaker := cache.NewBreaker(cache.DefaultFailureThreshold, cache.DefaultCooldown)
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithDefaultServiceConfig(retrypolicy),
-	)
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			BreakerInterceptor(breaker, appMetrics.HermesCircuitOpen),
+			DeadlineInterceptor(cfg.Timeout),
+			MetricsInterceptor(appMetrics),
+		),
+	}
+	if cfg.Token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(tokenCredentials{token: cfg.Token}))
+	}
+
+	conn, err := grpc.NewClient(cfg.Addr, opts...)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create grpc client: %w", err)
 	}
@@ -1,9 +1,15 @@
 package hermes_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,16 +19,34 @@ func TestNewClient(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
-		client, conn, err := hermes.NewClient("bufnet")
+		client, conn, err := hermes.NewClient(
+			config.Hermes{Addr: "bufnet", Timeout: time.Second},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
 
 		require.NoError(t, err)
 		assert.NotNil(t, client)
 		assert.NotNil(t, conn)
 	})
 
+	t.Run("error - token without tls", func(t *testing.T) {
+		t.Parallel()
+		client, conn, err := hermes.NewClient(
+			config.Hermes{Addr: "bufnet", Timeout: time.Second, Token: "secret"},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
+
+		require.ErrorContains(t, err, "failed to create grpc client")
+		assert.Nil(t, client)
+		assert.Nil(t, conn)
+	})
+
 	t.Run("error - failed to create client", func(t *testing.T) {
 		t.Parallel()
-		client, conn, err := hermes.NewClient("Segment%%2815197306101420000%29.ts")
+		client, conn, err := hermes.NewClient(
+			config.Hermes{Addr: "Segment%%2815197306101420000%29.ts", Timeout: time.Second},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
 
 		require.Error(t, err)
 		require.ErrorContains(t, err, "failed to create grpc client")
@@ -30,3 +54,55 @@ func TestNewClient(t *testing.T) {
 		assert.Nil(t, conn)
 	})
 }
+
+func TestNewClient_TLS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - system roots", func(t *testing.T) {
+		t.Parallel()
+		client, conn, err := hermes.NewClient(
+			config.Hermes{Addr: "hermes:50051", Timeout: time.Second, TLS: true, Token: "secret"},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
+
+		require.NoError(t, err)
+		assert.NotNil(t, client)
+		assert.NotNil(t, conn)
+	})
+
+	t.Run("error - missing CA certificate", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := hermes.NewClient(
+			config.Hermes{Addr: "hermes:50051", Timeout: time.Second, TLS: true, CAFile: "/nonexistent/ca.pem"},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
+
+		require.ErrorContains(t, err, "failed to read hermes CA certificate")
+	})
+
+	t.Run("error - invalid CA certificate", func(t *testing.T) {
+		t.Parallel()
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+		_, _, err := hermes.NewClient(
+			config.Hermes{Addr: "hermes:50051", Timeout: time.Second, TLS: true, CAFile: caFile},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
+
+		require.ErrorContains(t, err, "failed to parse hermes CA certificate")
+	})
+
+	t.Run("error - missing client certificate", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := hermes.NewClient(
+			config.Hermes{
+				Addr: "hermes:50051", Timeout: time.Second, TLS: true,
+				CertFile: "/nonexistent/oracle.pem", KeyFile: "/nonexistent/oracle-key.pem",
+			},
+			metrics.NewMetrics(prometheus.NewRegistry()),
+		)
+
+		require.ErrorContains(t, err, "failed to load hermes client certificate")
+	})
+}
@@ -0,0 +1,19 @@
+package hermes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenCredentials(t *testing.T) {
+	t.Parallel()
+
+	creds := tokenCredentials{token: "secret"}
+	metadata, err := creds.GetRequestMetadata(t.Context())
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer secret"}, metadata)
+	assert.True(t, creds.RequireTransportSecurity())
+}
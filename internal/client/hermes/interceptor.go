@@ -0,0 +1,36 @@
+package hermes
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsInterceptor returns a unary client interceptor that records the duration of every
+// Hermes call and counts failed calls by their gRPC status code.
+func MetricsInterceptor(m *metrics.Metrics) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		fullMethod string,
+		req, reply any,
+		conn *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		// "/scraper.ScraperService/AddComment" is reported as "AddComment".
+		method := path.Base(fullMethod)
+
+		startTime := time.Now()
+		err := invoker(ctx, fullMethod, req, reply, conn, opts...)
+		m.HermesDuration.WithLabelValues(method).Observe(time.Since(startTime).Seconds())
+		if err != nil {
+			m.HermesErrors.WithLabelValues(method, status.Code(err).String()).Inc()
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,53 @@
+package hermes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/client/hermes"
+	"github.com/UnknownOlympus/oracle/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsInterceptor(t *testing.T) {
+	t.Parallel()
+	const fullMethod = "/scraper.ScraperService/AddComment"
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		appMetrics := metrics.NewMetrics(prometheus.NewRegistry())
+		interceptor := hermes.MetricsInterceptor(appMetrics)
+
+		err := interceptor(t.Context(), fullMethod, nil, nil, nil,
+			func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				return nil
+			})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, testutil.CollectAndCount(appMetrics.HermesDuration))
+		assert.Equal(t, 0, testutil.CollectAndCount(appMetrics.HermesErrors))
+	})
+
+	t.Run("error is counted by status code", func(t *testing.T) {
+		t.Parallel()
+		appMetrics := metrics.NewMetrics(prometheus.NewRegistry())
+		interceptor := hermes.MetricsInterceptor(appMetrics)
+		callErr := status.Error(codes.Unavailable, "hermes is down")
+
+		err := interceptor(t.Context(), fullMethod, nil, nil, nil,
+			func(_ context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				return callErr
+			})
+
+		require.ErrorIs(t, err, callErr)
+		assert.Equal(t, 1, testutil.CollectAndCount(appMetrics.HermesDuration))
+		errors := appMetrics.HermesErrors.WithLabelValues("AddComment", "Unavailable")
+		assert.InDelta(t, 1, testutil.ToFloat64(errors), 0)
+	})
+}
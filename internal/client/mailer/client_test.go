@@ -0,0 +1,123 @@
+package mailer_test
+
+import (
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/client/mailer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession is what the fake server received in a session.
+type smtpSession struct {
+	commands []string
+	data     string
+}
+
+// serveSMTP accepts a single session on a local port and replies to it as a minimal SMTP server
+// offering PLAIN authentication. When stall is set the server greets the client and goes silent.
+func serveSMTP(t *testing.T, stall bool) (string, <-chan smtpSession) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session smtpSession
+		defer func() { sessions <- session }()
+
+		text := textproto.NewConn(conn)
+		_ = text.PrintfLine("220 localhost ESMTP")
+		if stall {
+			_, _ = io.Copy(io.Discard, conn)
+			return
+		}
+		for {
+			line, readErr := text.ReadLine()
+			if readErr != nil {
+				return
+			}
+			session.commands = append(session.commands, line)
+			verb, _, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO":
+				_ = text.PrintfLine("250-localhost\r\n250 AUTH PLAIN")
+			case "AUTH":
+				_ = text.PrintfLine("235 Authenticated")
+			case "DATA":
+				_ = text.PrintfLine("354 Go ahead")
+				data, _ := text.ReadDotBytes()
+				session.data = string(data)
+				_ = text.PrintfLine("250 Queued")
+			case "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				return
+			default:
+				_ = text.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	return listener.Addr().String(), sessions
+}
+
+func TestClient_Send(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success - delivers the message", func(t *testing.T) {
+		t.Parallel()
+		addr, sessions := serveSMTP(t, false)
+		client := mailer.NewClient(addr, "oracle", "secret", "Oracle <oracle@example.com>", time.Second)
+
+		err := client.Send(context.Background(), "john@example.com", "Kod logowania", "Twój kod: 123456")
+
+		require.NoError(t, err)
+		session := <-sessions
+		assert.Contains(t, session.commands, "MAIL FROM:<oracle@example.com>")
+		assert.Contains(t, session.commands, "RCPT TO:<john@example.com>")
+		assert.Contains(t, session.commands, "AUTH PLAIN AG9yYWNsZQBzZWNyZXQ=")
+
+		headers, body, ok := strings.Cut(session.data, "\n\n")
+		require.True(t, ok)
+		assert.Contains(t, headers, "From: \"Oracle\" <oracle@example.com>")
+		assert.Contains(t, headers, "To: <john@example.com>")
+		assert.Contains(t, headers, "Subject: Kod logowania")
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+		require.NoError(t, err)
+		assert.Equal(t, "Twój kod: 123456\n", string(decoded))
+	})
+
+	t.Run("error - rejects a recipient with headers", func(t *testing.T) {
+		t.Parallel()
+		client := mailer.NewClient("127.0.0.1:1", "", "", "oracle@example.com", time.Second)
+
+		err := client.Send(context.Background(), "john@example.com\r\nBcc: eve@example.com", "Code", "123456")
+
+		assert.ErrorContains(t, err, "invalid recipient address")
+	})
+
+	t.Run("error - gives up on a stalled server", func(t *testing.T) {
+		t.Parallel()
+		addr, _ := serveSMTP(t, true)
+		client := mailer.NewClient(addr, "", "", "oracle@example.com", 100*time.Millisecond)
+
+		err := client.Send(context.Background(), "john@example.com", "Code", "123456")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
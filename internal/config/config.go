@@ -1,8 +1,18 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	_ "time/tzdata" // time zones of digest subscribers must load on hosts without tzdata
 
 	"github.com/joho/godotenv"
 )
@@ -11,12 +21,210 @@ import (
 // It includes the environment type, database configuration,
 // token for authentication, and the timeout duration for polling.
 type Config struct {
-	Env           string         `json:"env"`            // Env is the current environment: local, dev, prod.
-	Database      PostgresConfig `json:"postgres"`       // Database holds the postgres database configuration
-	Token         string         `json:"token"`          // Token is an unique telgram bot token
-	PollerTimeout time.Duration  `json:"poller_timeout"` // PollerTimeout its a time which need to close telegram bot poller
-	RedisAddr     string         `json:"redis_addr"`     // RedisAddr is the redis server address.
-	HermesAddr    string         `json:"hermes_address"` // HermesAddr is the address to grpc server
+	Env           string         `json:"env"`             // Env is the current environment: local, dev, prod.
+	Database      PostgresConfig `json:"postgres"`        // Database holds the postgres database configuration
+	Token         string         `json:"token"`           // Token is an unique telgram bot token
+	PollerTimeout time.Duration  `json:"poller_timeout"`  // PollerTimeout its a time which need to close telegram bot poller
+	Redis         Redis          `json:"redis"`           // Redis holds the redis connection settings
+	Hermes        Hermes         `json:"hermes"`          // Hermes holds the Hermes gRPC connection settings
+	WeeklyReport  ClockTime      `json:"weekly_report"`   // WeeklyReport is the time of the Monday report delivery
+	Digest        Digest         `json:"digest"`          // Digest holds the morning digest settings
+	TasksPageSize int            `json:"tasks_page_size"` // TasksPageSize is the number of tasks shown per page
+	RateLimit     RateLimit      `json:"rate_limit"`      // RateLimit holds the per-user request limits
+	ReportQueue   ReportQueue    `json:"report_queue"`    // ReportQueue holds the report generation queue settings
+	Tracing       Tracing        `json:"tracing"`         // Tracing holds the OpenTelemetry exporter settings
+	Errors        ErrorReporting `json:"errors"`          // Errors holds the Sentry error reporting settings
+	Alerts        Alerts         `json:"alerts"`          // Alerts holds the routing of Alertmanager alerts
+	Webhooks      Webhooks       `json:"webhooks"`        // Webhooks holds the secrets of external integrations
+	Storage       Storage        `json:"storage"`         // Storage holds the object storage of generated files
+	GitHub        GitHub         `json:"github"`          // GitHub holds the repository user feedback is filed in
+	SMTP          SMTP           `json:"smtp"`            // SMTP holds the mail server login codes are sent through
+	// CRMTaskURL is the URL template of a task in the external CRM, {id} is replaced with the task ID.
+	// Empty hides the "Open in CRM" button.
+	CRMTaskURL string `json:"crm_task_url"`
+	// ParseMode is the Telegram parse mode of the messages built in code, "MarkdownV2" or "HTML".
+	// Translated texts keep the legacy Markdown they are written in.
+	ParseMode string `json:"parse_mode"`
+	// QRLoginEmailHeader is the header with the email of the employee signed in to the QR login page,
+	// set by the authenticating reverse proxy. Empty disables the page.
+	QRLoginEmailHeader string `json:"qr_login_email_header"`
+	// QRLoginTrustedProxies are the addresses of the proxies the email header is accepted from,
+	// required when the header is set.
+	QRLoginTrustedProxies []netip.Prefix `json:"qr_login_trusted_proxies"`
+	// LocalesDir holds <lang>.json files overriding the embedded translations, reloaded when they change.
+	// Empty uses the embedded translations only.
+	LocalesDir string `json:"locales_dir"`
+	// InvalidationChannel is the Redis pub/sub channel of task updates. Empty disables cache invalidation.
+	InvalidationChannel string `json:"invalidation_channel"`
+	// ShutdownTimeout is how long in-flight handlers, broadcasts and reports may run after a shutdown signal.
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"`
+	// Timeouts limit the work of the handlers per class of operation.
+	Timeouts Timeouts `json:"timeouts"`
+	// CommandAliases maps a slash command, without the slash, to the handler name of a menu button it runs.
+	CommandAliases map[string]string `json:"command_aliases"`
+}
+
+// Redis holds the Redis connection settings. Sentinel is used when a master name is set,
+// Cluster when cluster addresses are set, and a single server otherwise.
+type Redis struct {
+	Addr           string   `json:"addr"`            // Addr is the redis:// URL of a single server.
+	SentinelMaster string   `json:"sentinel_master"` // SentinelMaster is the name of the master monitored by Sentinel.
+	SentinelAddrs  []string `json:"sentinel_addrs"`  // SentinelAddrs are the host:port addresses of the Sentinels.
+	ClusterAddrs   []string `json:"cluster_addrs"`   // ClusterAddrs are the host:port addresses of the cluster nodes.
+	Username       string   `json:"username"`        // Username authenticates with Sentinel or Cluster nodes.
+	Password       string   `json:"password"`        // Password authenticates with Sentinel or Cluster nodes.
+	DB             int      `json:"db"`              // DB is the database selected on the Sentinel master.
+}
+
+// Hermes holds the settings of the gRPC connection to Hermes. TLS is used when enabled, with
+// a client certificate for mutual TLS when CertFile and KeyFile are set.
+type Hermes struct {
+	Addr       string        `json:"address"`     // Addr is the host:port of the Hermes gRPC server.
+	Timeout    time.Duration `json:"timeout"`     // Timeout is the deadline of a single call.
+	TLS        bool          `json:"tls"`         // TLS enables TLS for the connection.
+	CAFile     string        `json:"ca_file"`     // CAFile verifies the server. Empty uses the system roots.
+	CertFile   string        `json:"cert_file"`   // CertFile is the client certificate for mutual TLS.
+	KeyFile    string        `json:"key_file"`    // KeyFile is the private key of the client certificate.
+	ServerName string        `json:"server_name"` // ServerName overrides the name the certificate is checked for.
+	Token      string        `json:"-"`           // Token is sent as a bearer token with every call, it requires TLS.
+}
+
+// Digest holds the settings of the morning digest of open tasks.
+type Digest struct {
+	Time     ClockTime `json:"time"`     // Time is when the digest is sent in the subscriber's time zone.
+	Timezone string    `json:"timezone"` // Timezone is the IANA time zone offered to new subscribers.
+}
+
+// Tracing holds the settings of the OpenTelemetry trace exporter.
+type Tracing struct {
+	Endpoint    string  `json:"endpoint"`     // Endpoint is the OTLP/gRPC collector address. Empty disables tracing.
+	Insecure    bool    `json:"insecure"`     // Insecure disables TLS for the collector connection.
+	SampleRatio float64 `json:"sample_ratio"` // SampleRatio is the fraction of traces recorded, from 0 to 1.
+}
+
+// ErrorReporting holds the settings of the Sentry (or Glitchtip) error reporting.
+type ErrorReporting struct {
+	DSN        string  `json:"-"`           // DSN of the Sentry project. Empty disables error reporting.
+	SampleRate float64 `json:"sample_rate"` // SampleRate is the fraction of errors reported, from 0 to 1.
+}
+
+// Alerts holds the routing of Alertmanager alerts to admins.
+type Alerts struct {
+	// Routes maps an alert severity to the Telegram IDs of the admins receiving it.
+	// Alerts of other severities go to all admins.
+	Routes map[string][]int64 `json:"routes"`
+	// SilenceHours is when only critical alerts are delivered. An empty range disables it.
+	SilenceHours ClockRange `json:"silence_hours"`
+	// DedupWindow is how long a repeated notification of the same alert is suppressed. Zero disables it.
+	DedupWindow time.Duration `json:"dedup_window"`
+	// AlertmanagerURL is the base URL of the Alertmanager API. Empty hides the silence button.
+	AlertmanagerURL string `json:"alertmanager_url"`
+	// WebhookToken is the bearer token Alertmanager must send to the webhook. Empty disables the check.
+	WebhookToken string `json:"-"`
+}
+
+// Webhooks holds the secrets of the inbound webhooks of external integrations.
+// A webhook is enabled only when its secret is set.
+type Webhooks struct {
+	GitHubSecret string `json:"-"` // GitHubSecret signs the GitHub deliveries to /webhook/github.
+	UptimeToken  string `json:"-"` // UptimeToken is the bearer token of uptime monitors posting to /webhook/uptime.
+}
+
+// GitHub holds the settings of the GitHub repository user feedback is filed in as issues.
+// An empty Token disables it, users are then pointed to the issues page instead.
+type GitHub struct {
+	APIURL     string `json:"api_url"`    // APIURL is the REST API root, e.g. the /api/v3 URL of GitHub Enterprise.
+	Repository string `json:"repository"` // Repository is the "owner/name" of the repository.
+	Token      string `json:"-"`          // Token may create issues in the repository.
+}
+
+// SMTP holds the settings of the mail server the one-time login codes are sent through.
+// An empty Addr disables it, users can then only log in with the QR code.
+type SMTP struct {
+	Addr     string        `json:"address"`  // Addr is the host:port of the server, STARTTLS is used when offered.
+	Username string        `json:"username"` // Username authenticates with the server. Empty skips authentication.
+	Password string        `json:"-"`        // Password authenticates with the server.
+	From     string        `json:"from"`     // From is the sender address of the emails.
+	Timeout  time.Duration `json:"timeout"`  // Timeout is the deadline of sending a single email.
+}
+
+// Storage holds the settings of the S3-compatible object storage (MinIO, AWS S3) of generated files.
+// An empty Endpoint disables it, archived reports are then kept in the database.
+type Storage struct {
+	Endpoint  string `json:"endpoint"`   // Endpoint is the host:port of the storage, e.g. minio:9000.
+	Bucket    string `json:"bucket"`     // Bucket holds the objects, it must exist.
+	Region    string `json:"region"`     // Region of the bucket.
+	AccessKey string `json:"access_key"` // AccessKey identifies the storage account.
+	SecretKey string `json:"-"`          // SecretKey signs the requests.
+	UseSSL    bool   `json:"use_ssl"`    // UseSSL connects to the storage over HTTPS.
+	// PresignExpiry is how long download links of files over the Telegram upload limit stay valid.
+	PresignExpiry time.Duration `json:"presign_expiry"`
+}
+
+// ReportQueue holds the settings of the background report generation queue.
+type ReportQueue struct {
+	Workers int `json:"workers"` // Workers is the number of reports generated concurrently.
+	Size    int `json:"size"`    // Size is the maximum number of reports waiting in the queue.
+}
+
+// RateLimit holds the per-user token bucket settings. A non-positive Rate disables the limiter.
+type RateLimit struct {
+	Rate  float64 `json:"rate"`  // Rate is the number of requests per second a user regains.
+	Burst int     `json:"burst"` // Burst is the maximum number of requests a user can send at once.
+}
+
+// ClockTime is a time of day in the bot's local time zone.
+type ClockTime struct {
+	Hour   int `json:"hour"`
+	Minute int `json:"minute"`
+}
+
+// ParseClockTime parses a time of day in the "HH:MM" 24-hour format.
+func ParseClockTime(value string) (ClockTime, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return ClockTime{}, fmt.Errorf("invalid time of day %q: %w", value, err)
+	}
+
+	return ClockTime{Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
+}
+
+// ClockRange is a daily time range in the bot's local time zone. It may wrap around midnight,
+// e.g. 22:00-07:00. A range starting and ending at the same time is empty.
+type ClockRange struct {
+	From ClockTime `json:"from"`
+	To   ClockTime `json:"to"`
+}
+
+// ParseClockRange parses a time range in the "HH:MM-HH:MM" 24-hour format.
+func ParseClockRange(value string) (ClockRange, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return ClockRange{}, fmt.Errorf("invalid time range %q: expected HH:MM-HH:MM", value)
+	}
+
+	fromTime, err := ParseClockTime(strings.TrimSpace(from))
+	if err != nil {
+		return ClockRange{}, err
+	}
+	toTime, err := ParseClockTime(strings.TrimSpace(to))
+	if err != nil {
+		return ClockRange{}, err
+	}
+
+	return ClockRange{From: fromTime, To: toTime}, nil
+}
+
+// Contains reports whether the time of day of t falls into the range. The end is exclusive.
+func (r ClockRange) Contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	from := r.From.Hour*60 + r.From.Minute
+	to := r.To.Hour*60 + r.To.Minute
+
+	if from <= to {
+		return minutes >= from && minutes < to
+	}
+	return minutes >= from || minutes < to
 }
 
 // PostgresConfig struct holds the configuration details for connecting to a PostgreSQL database.
@@ -26,6 +234,28 @@ type PostgresConfig struct {
 	User     string `json:"user"`     // User is the database user.
 	Password string `json:"password"` // Password is the database user's password.
 	Name     string `json:"db_name"`  // Name is the name of the database.
+	// ReplicaDSN is the connection string of a read replica. When empty, all queries go to the primary.
+	ReplicaDSN string `json:"replica_dsn"`
+	// PostGIS enables the PostGIS-backed near-task search.
+	PostGIS bool `json:"postgis"`
+	// Pool holds the connection pool settings, used for the primary database and the replica alike.
+	Pool Pool `json:"pool"`
+	// Migrate applies the pending schema migrations on startup.
+	Migrate bool `json:"migrate"`
+}
+
+// Pool holds the settings of a database connection pool.
+type Pool struct {
+	MinConns     int32         `json:"min_conns"`     // MinConns is the number of connections kept open.
+	MaxConns     int32         `json:"max_conns"`     // MaxConns is the maximum number of open connections.
+	ConnLifetime time.Duration `json:"conn_lifetime"` // ConnLifetime is how long a connection is reused.
+}
+
+// Timeouts holds how long a handler may take for each class of operation.
+type Timeouts struct {
+	Query  time.Duration `json:"query"`  // Query bounds quick database, Redis and Hermes lookups of users.
+	Admin  time.Duration `json:"admin"`  // Admin bounds the heavier queries of the admin panel.
+	Report time.Duration `json:"report"` // Report bounds reports, exports, file transfers and bulk actions.
 }
 
 // MustLoad loads the configuration from a .env file and returns a Config struct.
@@ -37,6 +267,116 @@ func MustLoad() *Config {
 		panic("failed to parse interval from configuration")
 	}
 
+	weeklyReport, err := ParseClockTime(setDeafultEnv("ORACLE_WEEKLY_REPORT_TIME", "08:00"))
+	if err != nil {
+		panic("failed to parse weekly report time from configuration")
+	}
+
+	pageSize, err := strconv.Atoi(setDeafultEnv("ORACLE_TASKS_PAGE_SIZE", "15"))
+	if err != nil || pageSize <= 0 {
+		panic("failed to parse tasks page size from configuration")
+	}
+
+	rateLimit, err := loadRateLimit()
+	if err != nil {
+		panic("failed to parse rate limit from configuration")
+	}
+
+	reportQueue, err := loadReportQueue()
+	if err != nil {
+		panic("failed to parse report queue from configuration")
+	}
+
+	shutdownTimeout, err := time.ParseDuration(setDeafultEnv("ORACLE_SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil || shutdownTimeout <= 0 {
+		panic("failed to parse shutdown timeout from configuration")
+	}
+
+	timeouts, err := loadTimeouts()
+	if err != nil {
+		panic("failed to parse timeouts from configuration")
+	}
+
+	hermes, err := loadHermes()
+	if err != nil {
+		panic("failed to parse hermes from configuration")
+	}
+
+	digest, err := loadDigest()
+	if err != nil {
+		panic("failed to parse digest from configuration")
+	}
+
+	tracing, err := loadTracing()
+	if err != nil {
+		panic("failed to parse tracing from configuration")
+	}
+
+	errorReporting, err := loadErrorReporting()
+	if err != nil {
+		panic("failed to parse error reporting from configuration")
+	}
+
+	redisConfig, err := loadRedis()
+	if err != nil {
+		panic("failed to parse redis from configuration")
+	}
+
+	alerts, err := loadAlerts()
+	if err != nil {
+		panic("failed to parse alerts from configuration")
+	}
+
+	postgis, err := strconv.ParseBool(setDeafultEnv("DB_POSTGIS_ENABLED", "false"))
+	if err != nil {
+		panic("failed to parse postgis flag from configuration")
+	}
+
+	qrLoginProxies, err := loadQRLoginTrustedProxies()
+	if err != nil {
+		panic("failed to parse qr login trusted proxies from configuration")
+	}
+
+	smtp, err := loadSMTP()
+	if err != nil {
+		panic("failed to parse smtp from configuration")
+	}
+
+	migrate, err := strconv.ParseBool(setDeafultEnv("DB_MIGRATE", "true"))
+	if err != nil {
+		panic("failed to parse migrate flag from configuration")
+	}
+
+	pool, err := loadPool()
+	if err != nil {
+		panic("failed to parse database pool from configuration")
+	}
+
+	storage, err := loadStorage()
+	if err != nil {
+		panic("failed to parse storage from configuration")
+	}
+
+	crmTaskURL, err := loadCRMTaskURL()
+	if err != nil {
+		panic("failed to parse crm task url from configuration")
+	}
+
+	parseMode, err := loadParseMode()
+	if err != nil {
+		panic("failed to parse parse mode from configuration")
+	}
+
+	gitHub, err := loadGitHub()
+	if err != nil {
+		panic("failed to parse github from configuration")
+	}
+
+	commandAliases, err := loadCommandAliases()
+	if err != nil {
+		panic("failed to parse command aliases from configuration")
+	}
+
 	return &Config{
 		Env:           setDeafultEnv("ORACLE_ENV", "production"),
 		Token:         os.Getenv("ORACLE_TELEGRAM_TOKEN"),
@@ -47,10 +387,372 @@ func MustLoad() *Config {
 			User:     os.Getenv("DB_USERNAME"),
 			Password: os.Getenv("DB_PASSWORD"),
 			Name:     os.Getenv("DB_NAME"),
+
+			ReplicaDSN: os.Getenv("DB_REPLICA_DSN"),
+			PostGIS:    postgis,
+			Pool:       pool,
+			Migrate:    migrate,
+		},
+		Redis:         redisConfig,
+		Hermes:        hermes,
+		WeeklyReport:  weeklyReport,
+		Digest:        digest,
+		TasksPageSize: pageSize,
+		RateLimit:     rateLimit,
+		ReportQueue:   reportQueue,
+		Tracing:       tracing,
+		Errors:        errorReporting,
+		Alerts:        alerts,
+		Webhooks: Webhooks{
+			GitHubSecret: os.Getenv("ORACLE_WEBHOOK_GITHUB_SECRET"),
+			UptimeToken:  os.Getenv("ORACLE_WEBHOOK_UPTIME_TOKEN"),
 		},
-		RedisAddr:  os.Getenv("REDIS_ADDRESS"),
-		HermesAddr: os.Getenv("HERMES_ADDRESS"),
+		Storage:    storage,
+		GitHub:     gitHub,
+		SMTP:       smtp,
+		CRMTaskURL: crmTaskURL,
+		ParseMode:  parseMode,
+
+		QRLoginEmailHeader:  strings.TrimSpace(os.Getenv("ORACLE_QR_LOGIN_EMAIL_HEADER")),
+		LocalesDir:          os.Getenv("ORACLE_LOCALES_DIR"),
+		InvalidationChannel: setDeafultEnv("ORACLE_CACHE_INVALIDATION_CHANNEL", "hermes:task_updates"),
+		ShutdownTimeout:     shutdownTimeout,
+		Timeouts:            timeouts,
+		CommandAliases:      commandAliases,
+
+		QRLoginTrustedProxies: qrLoginProxies,
+	}
+}
+
+// loadPool reads the database connection pool settings from the environment.
+func loadPool() (Pool, error) {
+	minConns, err := strconv.ParseInt(setDeafultEnv("DB_POOL_MIN_CONNS", "3"), 10, 32)
+	if err != nil {
+		return Pool{}, fmt.Errorf("invalid pool min conns: %w", err)
+	}
+
+	maxConns, err := strconv.ParseInt(setDeafultEnv("DB_POOL_MAX_CONNS", "10"), 10, 32)
+	if err != nil {
+		return Pool{}, fmt.Errorf("invalid pool max conns: %w", err)
+	}
+
+	lifetime, err := time.ParseDuration(setDeafultEnv("DB_POOL_MAX_CONN_LIFETIME", "1h"))
+	if err != nil {
+		return Pool{}, fmt.Errorf("invalid pool conn lifetime: %w", err)
+	}
+
+	if minConns < 0 || maxConns <= 0 || minConns > maxConns {
+		return Pool{}, fmt.Errorf("pool conns must satisfy 0 <= min <= max, max > 0, got %d and %d",
+			minConns, maxConns)
+	}
+	if lifetime <= 0 {
+		return Pool{}, fmt.Errorf("pool conn lifetime must be positive, got %s", lifetime)
+	}
+
+	return Pool{MinConns: int32(minConns), MaxConns: int32(maxConns), ConnLifetime: lifetime}, nil
+}
+
+// loadRedis reads the Redis connection settings from the environment.
+func loadRedis() (Redis, error) {
+	db, err := strconv.Atoi(setDeafultEnv("REDIS_DB", "0"))
+	if err != nil {
+		return Redis{}, fmt.Errorf("invalid redis db: %w", err)
+	}
+
+	cfg := Redis{
+		Addr:           os.Getenv("REDIS_ADDRESS"),
+		SentinelMaster: os.Getenv("REDIS_SENTINEL_MASTER"),
+		SentinelAddrs:  splitList(os.Getenv("REDIS_SENTINEL_ADDRESSES")),
+		ClusterAddrs:   splitList(os.Getenv("REDIS_CLUSTER_ADDRESSES")),
+		Username:       os.Getenv("REDIS_USERNAME"),
+		Password:       os.Getenv("REDIS_PASSWORD"),
+		DB:             db,
+	}
+
+	if cfg.SentinelMaster != "" && len(cfg.ClusterAddrs) > 0 {
+		return Redis{}, errors.New("redis sentinel and cluster modes are mutually exclusive")
 	}
+	if cfg.SentinelMaster != "" && len(cfg.SentinelAddrs) == 0 {
+		return Redis{}, errors.New("redis sentinel master is set without sentinel addresses")
+	}
+
+	return cfg, nil
+}
+
+// loadHermes reads the Hermes connection settings from the environment.
+func loadHermes() (Hermes, error) {
+	timeout, err := time.ParseDuration(setDeafultEnv("HERMES_TIMEOUT", "5s"))
+	if err != nil {
+		return Hermes{}, fmt.Errorf("invalid hermes timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return Hermes{}, fmt.Errorf("hermes timeout must be positive, got %s", timeout)
+	}
+
+	tls, err := strconv.ParseBool(setDeafultEnv("HERMES_TLS_ENABLED", "false"))
+	if err != nil {
+		return Hermes{}, fmt.Errorf("invalid hermes tls flag: %w", err)
+	}
+
+	cfg := Hermes{
+		Addr:       os.Getenv("HERMES_ADDRESS"),
+		Timeout:    timeout,
+		TLS:        tls,
+		CAFile:     os.Getenv("HERMES_TLS_CA_FILE"),
+		CertFile:   os.Getenv("HERMES_TLS_CERT_FILE"),
+		KeyFile:    os.Getenv("HERMES_TLS_KEY_FILE"),
+		ServerName: os.Getenv("HERMES_TLS_SERVER_NAME"),
+		Token:      os.Getenv("HERMES_TOKEN"),
+	}
+
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return Hermes{}, errors.New("hermes client certificate and key must be set together")
+	}
+	if !cfg.TLS && (cfg.CAFile != "" || cfg.CertFile != "") {
+		return Hermes{}, errors.New("hermes certificates are set but tls is disabled")
+	}
+	if !cfg.TLS && cfg.Token != "" {
+		return Hermes{}, errors.New("hermes token is set but tls is disabled")
+	}
+
+	return cfg, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items and surrounding spaces.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// loadAlerts reads the alert routing settings from the environment. Routes are listed as
+// "severity:id,id;severity:id", e.g. "critical:111,222;warning:333".
+func loadAlerts() (Alerts, error) {
+	routes := make(map[string][]int64)
+	for route := range strings.SplitSeq(os.Getenv("ORACLE_ALERT_ROUTES"), ";") {
+		if strings.TrimSpace(route) == "" {
+			continue
+		}
+		severity, ids, ok := strings.Cut(route, ":")
+		severity = strings.TrimSpace(severity)
+		if !ok || severity == "" {
+			return Alerts{}, fmt.Errorf("invalid alert route %q", route)
+		}
+		for _, id := range splitList(ids) {
+			telegramID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return Alerts{}, fmt.Errorf("invalid admin id in alert route %q: %w", route, err)
+			}
+			routes[severity] = append(routes[severity], telegramID)
+		}
+	}
+
+	var silenceHours ClockRange
+	if value := os.Getenv("ORACLE_ALERT_SILENCE_HOURS"); value != "" {
+		var err error
+		if silenceHours, err = ParseClockRange(value); err != nil {
+			return Alerts{}, err
+		}
+	}
+
+	dedupWindow, err := time.ParseDuration(setDeafultEnv("ORACLE_ALERT_DEDUP_WINDOW", "30m"))
+	if err != nil {
+		return Alerts{}, fmt.Errorf("invalid alert dedup window: %w", err)
+	}
+	if dedupWindow < 0 {
+		return Alerts{}, fmt.Errorf("alert dedup window must not be negative, got %s", dedupWindow)
+	}
+
+	return Alerts{
+		Routes:          routes,
+		SilenceHours:    silenceHours,
+		DedupWindow:     dedupWindow,
+		AlertmanagerURL: strings.TrimSuffix(os.Getenv("ALERTMANAGER_URL"), "/"),
+		WebhookToken:    os.Getenv("ORACLE_ALERT_WEBHOOK_TOKEN"),
+	}, nil
+}
+
+// loadStorage reads the object storage settings from the environment.
+func loadStorage() (Storage, error) {
+	// S3 does not accept presigned URLs valid for longer than a week.
+	const maxPresignExpiry = 7 * 24 * time.Hour
+
+	useSSL, err := strconv.ParseBool(setDeafultEnv("STORAGE_USE_SSL", "true"))
+	if err != nil {
+		return Storage{}, fmt.Errorf("invalid storage ssl flag: %w", err)
+	}
+
+	expiry, err := time.ParseDuration(setDeafultEnv("STORAGE_PRESIGN_EXPIRY", "24h"))
+	if err != nil {
+		return Storage{}, fmt.Errorf("invalid storage presign expiry: %w", err)
+	}
+	if expiry <= 0 || expiry > maxPresignExpiry {
+		return Storage{}, fmt.Errorf("storage presign expiry must be between 0 and %s, got %s",
+			maxPresignExpiry, expiry)
+	}
+
+	cfg := Storage{
+		Endpoint:      os.Getenv("STORAGE_ENDPOINT"),
+		Bucket:        setDeafultEnv("STORAGE_BUCKET", "oracle"),
+		Region:        setDeafultEnv("STORAGE_REGION", "us-east-1"),
+		AccessKey:     os.Getenv("STORAGE_ACCESS_KEY"),
+		SecretKey:     os.Getenv("STORAGE_SECRET_KEY"),
+		UseSSL:        useSSL,
+		PresignExpiry: expiry,
+	}
+
+	if cfg.Endpoint != "" && (cfg.AccessKey == "" || cfg.SecretKey == "") {
+		return Storage{}, errors.New("storage access key and secret key are required")
+	}
+
+	return cfg, nil
+}
+
+// loadDigest reads the morning digest settings from the environment.
+func loadDigest() (Digest, error) {
+	digestTime, err := ParseClockTime(setDeafultEnv("ORACLE_DIGEST_TIME", "08:00"))
+	if err != nil {
+		return Digest{}, err
+	}
+
+	timezone := setDeafultEnv("ORACLE_DIGEST_TIMEZONE", "Europe/Kyiv")
+	if _, err = time.LoadLocation(timezone); err != nil {
+		return Digest{}, fmt.Errorf("invalid digest time zone %q: %w", timezone, err)
+	}
+
+	return Digest{Time: digestTime, Timezone: timezone}, nil
+}
+
+// loadTracing reads the trace exporter settings from the environment.
+func loadTracing() (Tracing, error) {
+	insecure, err := strconv.ParseBool(setDeafultEnv("ORACLE_TRACING_INSECURE", "true"))
+	if err != nil {
+		return Tracing{}, fmt.Errorf("invalid tracing insecure flag: %w", err)
+	}
+
+	ratio, err := strconv.ParseFloat(setDeafultEnv("ORACLE_TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return Tracing{}, fmt.Errorf("invalid tracing sample ratio: %w", err)
+	}
+	if ratio < 0 || ratio > 1 {
+		return Tracing{}, fmt.Errorf("tracing sample ratio must be between 0 and 1, got %v", ratio)
+	}
+
+	return Tracing{
+		Endpoint:    os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		Insecure:    insecure,
+		SampleRatio: ratio,
+	}, nil
+}
+
+// loadGitHub reads the settings of the repository user feedback is filed in from the environment.
+func loadGitHub() (GitHub, error) {
+	repository := setDeafultEnv("ORACLE_GITHUB_REPOSITORY", "UnknownOlympus/oracle")
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return GitHub{}, fmt.Errorf("github repository %q must be owner/name", repository)
+	}
+
+	apiURL := strings.TrimSuffix(setDeafultEnv("ORACLE_GITHUB_API_URL", "https://api.github.com"), "/")
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return GitHub{}, fmt.Errorf("invalid github api url: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return GitHub{}, fmt.Errorf("github api url %q must be an absolute http(s) URL", apiURL)
+	}
+
+	return GitHub{APIURL: apiURL, Repository: repository, Token: os.Getenv("ORACLE_GITHUB_TOKEN")}, nil
+}
+
+// loadSMTP reads the settings of the mail server of login codes from the environment.
+func loadSMTP() (SMTP, error) {
+	timeout, err := time.ParseDuration(setDeafultEnv("SMTP_TIMEOUT", "10s"))
+	if err != nil {
+		return SMTP{}, fmt.Errorf("invalid smtp timeout: %w", err)
+	}
+	if timeout <= 0 {
+		return SMTP{}, fmt.Errorf("smtp timeout must be positive, got %s", timeout)
+	}
+
+	cfg := SMTP{
+		Addr:     os.Getenv("SMTP_ADDRESS"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		Timeout:  timeout,
+	}
+	if cfg.Addr == "" {
+		return cfg, nil
+	}
+
+	if _, _, err = net.SplitHostPort(cfg.Addr); err != nil {
+		return SMTP{}, fmt.Errorf("smtp address %q must be host:port: %w", cfg.Addr, err)
+	}
+	if _, err = mail.ParseAddress(cfg.From); err != nil {
+		return SMTP{}, fmt.Errorf("invalid smtp sender %q: %w", cfg.From, err)
+	}
+	if (cfg.Username == "") != (cfg.Password == "") {
+		return SMTP{}, errors.New("smtp username and password must be set together")
+	}
+
+	return cfg, nil
+}
+
+// loadErrorReporting reads the Sentry error reporting settings from the environment.
+func loadErrorReporting() (ErrorReporting, error) {
+	rate, err := strconv.ParseFloat(setDeafultEnv("SENTRY_SAMPLE_RATE", "1"), 64)
+	if err != nil {
+		return ErrorReporting{}, fmt.Errorf("invalid error reporting sample rate: %w", err)
+	}
+	if rate < 0 || rate > 1 {
+		return ErrorReporting{}, fmt.Errorf("error reporting sample rate must be between 0 and 1, got %v", rate)
+	}
+
+	return ErrorReporting{DSN: os.Getenv("SENTRY_DSN"), SampleRate: rate}, nil
+}
+
+// loadRateLimit reads the rate limiter settings from the environment.
+func loadRateLimit() (RateLimit, error) {
+	rate, err := strconv.ParseFloat(setDeafultEnv("ORACLE_RATE_LIMIT_RATE", "1"), 64)
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid rate limit rate: %w", err)
+	}
+
+	burst, err := strconv.Atoi(setDeafultEnv("ORACLE_RATE_LIMIT_BURST", "5"))
+	if err != nil {
+		return RateLimit{}, fmt.Errorf("invalid rate limit burst: %w", err)
+	}
+	if burst <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit burst must be positive, got %d", burst)
+	}
+
+	return RateLimit{Rate: rate, Burst: burst}, nil
+}
+
+// loadReportQueue reads the report generation queue settings from the environment.
+func loadReportQueue() (ReportQueue, error) {
+	workers, err := strconv.Atoi(setDeafultEnv("ORACLE_REPORT_WORKERS", "2"))
+	if err != nil {
+		return ReportQueue{}, fmt.Errorf("invalid report workers number: %w", err)
+	}
+	if workers <= 0 {
+		return ReportQueue{}, fmt.Errorf("report workers number must be positive, got %d", workers)
+	}
+
+	size, err := strconv.Atoi(setDeafultEnv("ORACLE_REPORT_QUEUE_SIZE", "50"))
+	if err != nil {
+		return ReportQueue{}, fmt.Errorf("invalid report queue size: %w", err)
+	}
+	if size <= 0 {
+		return ReportQueue{}, fmt.Errorf("report queue size must be positive, got %d", size)
+	}
+
+	return ReportQueue{Workers: workers, Size: size}, nil
 }
 
 func setDeafultEnv(key, override string) string {
@@ -61,3 +763,116 @@ func setDeafultEnv(key, override string) string {
 
 	return value
 }
+
+// loadCRMTaskURL reads the URL template of a task in the external CRM. The template must be an
+// absolute http(s) URL containing the {id} placeholder.
+func loadCRMTaskURL() (string, error) {
+	template := strings.TrimSpace(os.Getenv("ORACLE_CRM_TASK_URL"))
+	if template == "" {
+		return "", nil
+	}
+
+	if !strings.Contains(template, "{id}") {
+		return "", fmt.Errorf("crm task url %q has no {id} placeholder", template)
+	}
+	parsed, err := url.Parse(strings.ReplaceAll(template, "{id}", "1"))
+	if err != nil {
+		return "", fmt.Errorf("invalid crm task url: %w", err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("crm task url %q must be an absolute http(s) URL", template)
+	}
+
+	return template, nil
+}
+
+// loadQRLoginTrustedProxies reads the networks of the proxies in front of the QR login page,
+// listed as CIDRs or single addresses. They are required when the page is enabled.
+func loadQRLoginTrustedProxies() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range splitList(os.Getenv("ORACLE_QR_LOGIN_TRUSTED_PROXIES")) {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			addr, errAddr := netip.ParseAddr(item)
+			if errAddr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	if strings.TrimSpace(os.Getenv("ORACLE_QR_LOGIN_EMAIL_HEADER")) != "" && len(prefixes) == 0 {
+		return nil, errors.New("qr login email header requires trusted proxies")
+	}
+
+	return prefixes, nil
+}
+
+// commandPattern matches the commands Telegram accepts: up to 32 lowercase letters, digits and underscores.
+var commandPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// loadCommandAliases reads the slash command aliases of menu buttons from the environment. Aliases
+// are listed as "command:handler", e.g. "tasks:active_tasks,stats:statistic_month". An empty value
+// disables the aliases.
+func loadCommandAliases() (map[string]string, error) {
+	value := setDeafultEnv("ORACLE_COMMAND_ALIASES", "tasks:active_tasks,report:report,stats:statistic_month")
+
+	aliases := make(map[string]string)
+	for _, alias := range splitList(value) {
+		command, handler, ok := strings.Cut(alias, ":")
+		command = strings.TrimPrefix(strings.TrimSpace(command), "/")
+		handler = strings.TrimSpace(handler)
+		if !ok || handler == "" {
+			return nil, fmt.Errorf("invalid command alias %q: expected command:handler", alias)
+		}
+		if !commandPattern.MatchString(command) {
+			return nil, fmt.Errorf("invalid command %q in alias %q", command, alias)
+		}
+		if _, exists := aliases[command]; exists {
+			return nil, fmt.Errorf("command %q is aliased twice", command)
+		}
+		aliases[command] = handler
+	}
+
+	return aliases, nil
+}
+
+// loadParseMode reads the parse mode of the messages built in code, MarkdownV2 by default.
+// The value is case-insensitive and is returned as Telegram expects it.
+func loadParseMode() (string, error) {
+	mode := setDeafultEnv("ORACLE_PARSE_MODE", "MarkdownV2")
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "markdownv2":
+		return "MarkdownV2", nil
+	case "html":
+		return "HTML", nil
+	default:
+		return "", fmt.Errorf("parse mode %q must be MarkdownV2 or HTML", mode)
+	}
+}
+
+// loadTimeouts reads the timeouts of the operation classes, each must be positive.
+func loadTimeouts() (Timeouts, error) {
+	var timeouts Timeouts
+	for _, setting := range []struct {
+		env      string
+		fallback string
+		value    *time.Duration
+	}{
+		{env: "ORACLE_TIMEOUT_QUERY", fallback: "3s", value: &timeouts.Query},
+		{env: "ORACLE_TIMEOUT_ADMIN", fallback: "5s", value: &timeouts.Admin},
+		{env: "ORACLE_TIMEOUT_REPORT", fallback: "30s", value: &timeouts.Report},
+	} {
+		value, err := time.ParseDuration(setDeafultEnv(setting.env, setting.fallback))
+		if err != nil {
+			return Timeouts{}, fmt.Errorf("invalid %s: %w", setting.env, err)
+		}
+		if value <= 0 {
+			return Timeouts{}, fmt.Errorf("%s must be positive, got %s", setting.env, value)
+		}
+		*setting.value = value
+	}
+
+	return timeouts, nil
+}
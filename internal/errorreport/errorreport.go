@@ -0,0 +1,82 @@
+package errorreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/getsentry/sentry-go"
+)
+
+// Shutdown sends the buffered error reports.
+type Shutdown func(ctx context.Context) error
+
+// Setup installs the global Sentry client reporting to the configured DSN, which may also be
+// a Glitchtip project. Without a DSN or with a zero sample rate error reporting stays disabled,
+// every capture is a no-op and the returned Shutdown does nothing.
+func Setup(cfg config.ErrorReporting, env string) (Shutdown, error) {
+	if cfg.DSN == "" || cfg.SampleRate == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	err := sentry.Init(sentry.ClientOptions{
+		Dsn:              cfg.DSN,
+		Environment:      env,
+		SampleRate:       cfg.SampleRate,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Sentry client: %w", err)
+	}
+
+	return func(ctx context.Context) error {
+		if !sentry.FlushWithContext(ctx) {
+			return errors.New("failed to send buffered error reports")
+		}
+		return nil
+	}, nil
+}
+
+// WithUser returns a context whose reports are tagged with the Telegram user.
+func WithUser(ctx context.Context, userID int64) context.Context {
+	hub := hubFromContext(ctx).Clone()
+	hub.Scope().SetUser(sentry.User{ID: strconv.FormatInt(userID, 10)})
+	return sentry.SetHubOnContext(ctx, hub)
+}
+
+// WithTags returns a context whose reports carry the tags, e.g. the handler serving the update.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	hub := hubFromContext(ctx).Clone()
+	hub.Scope().SetTags(tags)
+	return sentry.SetHubOnContext(ctx, hub)
+}
+
+// CaptureError reports the error with the tags of the context and the given ones.
+func CaptureError(ctx context.Context, err error, tags map[string]string) {
+	hub := hubFromContext(ctx)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		hub.CaptureException(err)
+	})
+}
+
+// CapturePanic reports the value recovered from a panic with the tags of the context and
+// the given ones. It must be called from the deferred function which recovered the panic,
+// so the stack trace points at the panic.
+func CapturePanic(ctx context.Context, recovered interface{}, tags map[string]string) {
+	hub := hubFromContext(ctx)
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTags(tags)
+		hub.RecoverWithContext(ctx, recovered)
+	})
+}
+
+// hubFromContext returns the hub of the context, or the global one.
+func hubFromContext(ctx context.Context) *sentry.Hub {
+	if hub := sentry.GetHubFromContext(ctx); hub != nil {
+		return hub
+	}
+	return sentry.CurrentHub()
+}
@@ -0,0 +1,108 @@
+package errorreport_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/errorreport"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTransport keeps the events instead of sending them.
+type recordingTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Close()                                {}
+
+func (t *recordingTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+// reportingContext returns a context with a hub recording its events in the transport.
+func reportingContext(t *testing.T) (context.Context, *recordingTransport) {
+	t.Helper()
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:       "https://key@sentry.example/1",
+		Transport: transport,
+	})
+	require.NoError(t, err)
+
+	return sentry.SetHubOnContext(t.Context(), sentry.NewHub(client, sentry.NewScope())), transport
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("disabled without DSN", func(t *testing.T) {
+		shutdown, err := errorreport.Setup(config.ErrorReporting{SampleRate: 1}, "test")
+
+		require.NoError(t, err)
+		require.NoError(t, shutdown(t.Context()))
+	})
+
+	t.Run("invalid DSN", func(t *testing.T) {
+		_, err := errorreport.Setup(config.ErrorReporting{DSN: "not a dsn", SampleRate: 1}, "test")
+
+		require.ErrorContains(t, err, "failed to initialize Sentry client")
+	})
+}
+
+func TestCaptureError(t *testing.T) {
+	ctx, transport := reportingContext(t)
+	ctx = errorreport.WithTags(ctx, map[string]string{"update": "telegram.callback task_details"})
+	ctx = errorreport.WithUser(ctx, 42)
+
+	errorreport.CaptureError(ctx, assert.AnError, map[string]string{"handler": "task_details"})
+
+	require.Len(t, transport.events, 1)
+	event := transport.events[0]
+	assert.Equal(t, "42", event.User.ID)
+	assert.Equal(t, "task_details", event.Tags["handler"])
+	assert.Equal(t, "telegram.callback task_details", event.Tags["update"])
+	require.NotEmpty(t, event.Exception)
+	assert.Equal(t, assert.AnError.Error(), event.Exception[len(event.Exception)-1].Value)
+}
+
+func TestCapturePanic(t *testing.T) {
+	ctx, transport := reportingContext(t)
+
+	func() {
+		defer func() {
+			errorreport.CapturePanic(ctx, recover(), map[string]string{"handler": "info"})
+		}()
+		panic("boom")
+	}()
+
+	require.Len(t, transport.events, 1)
+	assert.Equal(t, "boom", transport.events[0].Message)
+	assert.Equal(t, "info", transport.events[0].Tags["handler"])
+}
+
+func TestLogHandler(t *testing.T) {
+	ctx, transport := reportingContext(t)
+	logger := slog.New(errorreport.NewLogHandler(slog.NewTextHandler(io.Discard, nil))).With("op", "test")
+
+	logger.InfoContext(ctx, "Not reported", "error", assert.AnError)
+	logger.ErrorContext(ctx, "Failed to get user", "error", assert.AnError, "email", "user@example.com")
+
+	require.Len(t, transport.events, 1)
+	event := transport.events[0]
+	assert.Equal(t, "Failed to get user", event.Message)
+	assert.Equal(t, []string{"Failed to get user"}, event.Fingerprint)
+	assert.Equal(t, assert.AnError.Error(), event.Extra["error"])
+	assert.Equal(t, "test", event.Extra["op"])
+	assert.NotContains(t, event.Extra, "email")
+}
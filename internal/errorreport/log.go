@@ -0,0 +1,78 @@
+package errorreport
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// privateLogKeys are log attributes which may hold personal data and are not reported.
+var privateLogKeys = []string{"email", "phone", "text", "comment", "body"}
+
+// LogHandler reports the records logged at the error level, e.g. failed database queries,
+// and passes every record to the next handler. Reports are grouped by the log message,
+// the attributes, e.g. the error, are attached to them.
+type LogHandler struct {
+	next  slog.Handler
+	attrs []slog.Attr
+}
+
+// NewLogHandler wraps the handler to report the error records.
+func NewLogHandler(next slog.Handler) *LogHandler {
+	return &LogHandler{next: next}
+}
+
+// Enabled reports whether the next handler handles the level, errors are always enabled.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelError || h.next.Enabled(ctx, level)
+}
+
+// Handle reports the record if it is an error and passes it to the next handler.
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		h.report(ctx, record)
+	}
+	if !h.next.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs returns a handler adding the attributes to the records and the reports.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{next: h.next.WithAttrs(attrs), attrs: append(slices.Clone(h.attrs), attrs...)}
+}
+
+// WithGroup returns a handler grouping the attributes of the next handler. Reports are not grouped.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{next: h.next.WithGroup(name), attrs: h.attrs}
+}
+
+// report sends the record as a Sentry event through the hub of the context.
+func (h *LogHandler) report(ctx context.Context, record slog.Record) {
+	hub := hubFromContext(ctx)
+	if hub.Client() == nil {
+		return
+	}
+
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	event.Message = record.Message
+	event.Fingerprint = []string{record.Message}
+
+	addAttr := func(attr slog.Attr) bool {
+		if slices.Contains(privateLogKeys, attr.Key) {
+			return true
+		}
+		event.Extra[attr.Key] = attr.Value.String()
+		return true
+	}
+	for _, attr := range h.attrs {
+		addAttr(attr)
+	}
+	record.Attrs(addAttr)
+
+	hub.CaptureEvent(event)
+}
@@ -0,0 +1,206 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnknownFlag is returned when a flag which is not defined is set.
+var ErrUnknownFlag = errors.New("unknown feature flag")
+
+// Role is the role of a user a flag may be enabled for.
+type Role string
+
+const (
+	RoleEmployee Role = "employee"
+	RoleAdmin    Role = "admin"
+)
+
+// Roles lists all roles in the order they are offered to admins.
+var Roles = []Role{RoleEmployee, RoleAdmin}
+
+// Flag is the rollout of a feature. A disabled flag is off for everyone. An enabled flag is on
+// for users of the listed roles, and for the given percentage of the other users.
+type Flag struct {
+	Name       string `json:"name"`
+	Enabled    bool   `json:"enabled"`
+	Percentage int    `json:"percentage"` // Percentage of users the flag is on for, from 0 to 100.
+	Roles      []Role `json:"roles"`      // Roles the flag is on for regardless of the percentage.
+}
+
+// User is the user a flag is evaluated for.
+type User struct {
+	ID   int64
+	Role Role
+}
+
+// EnabledFor reports whether the flag is on for the user. The percentage buckets users by
+// a hash of the flag name and the user ID, so a user keeps the feature when the percentage grows
+// and the buckets of different flags are independent.
+func (f Flag) EnabledFor(user User) bool {
+	if !f.Enabled {
+		return false
+	}
+	if slices.Contains(f.Roles, user.Role) {
+		return true
+	}
+	return bucket(f.Name, user.ID) < f.Percentage
+}
+
+// bucket returns the rollout bucket of the user for the flag, from 0 to 99.
+func bucket(name string, userID int64) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name + ":" + strconv.FormatInt(userID, 10)))
+	return int(hash.Sum32() % 100)
+}
+
+// Definition declares a flag the code checks. Default applies until an admin sets the flag.
+type Definition struct {
+	Name    string
+	Default Flag
+}
+
+// Store keeps the flags set by admins.
+type Store interface {
+	GetFeatureFlags(ctx context.Context) ([]Flag, error)
+	SetFeatureFlag(ctx context.Context, flag Flag, updatedBy int64) error
+}
+
+// Flags evaluates the defined flags. The flags set by admins are loaded from the store and
+// cached for the TTL, so other replicas see a change within the TTL.
+type Flags struct {
+	log         *slog.Logger
+	store       Store
+	definitions []Definition
+	ttl         time.Duration
+
+	mu       sync.Mutex
+	stored   map[string]Flag
+	loadedAt time.Time // When the flags were loaded last, successfully or not.
+	loadErr  error     // Error of the last load, returned until the TTL passes.
+}
+
+// New creates the flags of the definitions, kept in the store.
+func New(log *slog.Logger, store Store, definitions []Definition, ttl time.Duration) *Flags {
+	return &Flags{log: log, store: store, definitions: definitions, ttl: ttl}
+}
+
+// Enabled reports whether the flag is on for the user. Undefined flags are off. When the store
+// is unavailable, the flags loaded last, or the defaults, apply.
+func (f *Flags) Enabled(ctx context.Context, name string, user User) bool {
+	flag, ok := f.flag(ctx, name)
+	return ok && flag.EnabledFor(user)
+}
+
+// List returns the defined flags in the order of their definitions.
+func (f *Flags) List(ctx context.Context) ([]Flag, error) {
+	stored, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]Flag, 0, len(f.definitions))
+	for _, definition := range f.definitions {
+		flags = append(flags, resolve(definition, stored))
+	}
+	return flags, nil
+}
+
+// Get returns the defined flag with the name.
+func (f *Flags) Get(ctx context.Context, name string) (Flag, error) {
+	i := slices.IndexFunc(f.definitions, func(definition Definition) bool { return definition.Name == name })
+	if i < 0 {
+		return Flag{}, fmt.Errorf("%w: %s", ErrUnknownFlag, name)
+	}
+
+	stored, err := f.load(ctx)
+	if err != nil {
+		return Flag{}, err
+	}
+	return resolve(f.definitions[i], stored), nil
+}
+
+// Set stores the flag set by the admin. The change applies to this replica right away.
+func (f *Flags) Set(ctx context.Context, flag Flag, updatedBy int64) error {
+	if !slices.ContainsFunc(f.definitions, func(definition Definition) bool { return definition.Name == flag.Name }) {
+		return fmt.Errorf("%w: %s", ErrUnknownFlag, flag.Name)
+	}
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return fmt.Errorf("percentage of flag %s must be between 0 and 100, got %d", flag.Name, flag.Percentage)
+	}
+
+	if err := f.store.SetFeatureFlag(ctx, flag, updatedBy); err != nil {
+		return fmt.Errorf("failed to store feature flag %s: %w", flag.Name, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.stored != nil {
+		// Copy the map, as the flags loaded before may still be read.
+		stored := maps.Clone(f.stored)
+		stored[flag.Name] = flag
+		f.stored = stored
+	}
+	return nil
+}
+
+// flag returns the defined flag with the name, using the cached flags on store errors.
+func (f *Flags) flag(ctx context.Context, name string) (Flag, bool) {
+	i := slices.IndexFunc(f.definitions, func(definition Definition) bool { return definition.Name == name })
+	if i < 0 {
+		return Flag{}, false
+	}
+
+	// The flags loaded last are returned with the error, which load has logged.
+	stored, _ := f.load(ctx)
+	return resolve(f.definitions[i], stored), true
+}
+
+// load returns the flags of the store, loading them again once the TTL has passed. A failed load
+// is not retried before the TTL passes either, the flags loaded last are returned with its error
+// meanwhile. The store is called outside of the mutex, so callers don't queue up behind a slow
+// store; during a load, they get the flags loaded last.
+func (f *Flags) load(ctx context.Context) (map[string]Flag, error) {
+	f.mu.Lock()
+	if time.Since(f.loadedAt) < f.ttl {
+		stored, err := f.stored, f.loadErr
+		f.mu.Unlock()
+		return stored, err
+	}
+	f.loadedAt = time.Now()
+	f.mu.Unlock()
+
+	flags, err := f.store.GetFeatureFlags(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err != nil {
+		f.log.WarnContext(ctx, "Failed to load feature flags, using the last known", "error", err)
+		f.loadErr = fmt.Errorf("failed to load feature flags: %w", err)
+		return f.stored, f.loadErr
+	}
+	f.stored = make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		f.stored[flag.Name] = flag
+	}
+	f.loadErr = nil
+	return f.stored, nil
+}
+
+// resolve returns the stored flag of the definition, or its default if it was never set.
+func resolve(definition Definition, stored map[string]Flag) Flag {
+	if flag, ok := stored[definition.Name]; ok {
+		return flag
+	}
+	flag := definition.Default
+	flag.Name = definition.Name
+	return flag
+}
@@ -0,0 +1,189 @@
+package featureflags_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	flags []featureflags.Flag
+	err   error
+	loads int
+}
+
+func (s *memoryStore) GetFeatureFlags(_ context.Context) ([]featureflags.Flag, error) {
+	s.loads++
+	return s.flags, s.err
+}
+
+func (s *memoryStore) SetFeatureFlag(_ context.Context, flag featureflags.Flag, _ int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.flags = append(s.flags, flag)
+	return nil
+}
+
+var definitions = []featureflags.Definition{
+	{Name: "pdf_reports", Default: featureflags.Flag{Enabled: true, Percentage: 100}},
+	{Name: "route_planner"},
+}
+
+func newFlags(store featureflags.Store) *featureflags.Flags {
+	return featureflags.New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, definitions, time.Minute)
+}
+
+func TestFlag_EnabledFor(t *testing.T) {
+	t.Parallel()
+
+	employee := featureflags.User{ID: 1, Role: featureflags.RoleEmployee}
+	admin := featureflags.User{ID: 2, Role: featureflags.RoleAdmin}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+		flag := featureflags.Flag{Name: "f", Percentage: 100, Roles: []featureflags.Role{featureflags.RoleAdmin}}
+		assert.False(t, flag.EnabledFor(admin))
+	})
+
+	t.Run("role", func(t *testing.T) {
+		t.Parallel()
+		flag := featureflags.Flag{Name: "f", Enabled: true, Roles: []featureflags.Role{featureflags.RoleAdmin}}
+		assert.True(t, flag.EnabledFor(admin))
+		assert.False(t, flag.EnabledFor(employee))
+	})
+
+	t.Run("percentage", func(t *testing.T) {
+		t.Parallel()
+		assert.True(t, featureflags.Flag{Name: "f", Enabled: true, Percentage: 100}.EnabledFor(employee))
+		assert.False(t, featureflags.Flag{Name: "f", Enabled: true}.EnabledFor(employee))
+
+		flag := featureflags.Flag{Name: "f", Enabled: true, Percentage: 25}
+		enabled := 0
+		for id := range int64(10000) {
+			if flag.EnabledFor(featureflags.User{ID: id}) {
+				enabled++
+			}
+		}
+		assert.InDelta(t, 2500, enabled, 300)
+	})
+
+	t.Run("percentage is stable when it grows", func(t *testing.T) {
+		t.Parallel()
+		for id := range int64(1000) {
+			user := featureflags.User{ID: id}
+			if (featureflags.Flag{Name: "f", Enabled: true, Percentage: 10}).EnabledFor(user) {
+				assert.True(t, featureflags.Flag{Name: "f", Enabled: true, Percentage: 50}.EnabledFor(user))
+			}
+		}
+	})
+}
+
+func TestFlags_Enabled(t *testing.T) {
+	t.Parallel()
+
+	user := featureflags.User{ID: 1, Role: featureflags.RoleEmployee}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Parallel()
+		flags := newFlags(&memoryStore{})
+		assert.True(t, flags.Enabled(t.Context(), "pdf_reports", user))
+		assert.False(t, flags.Enabled(t.Context(), "route_planner", user))
+		assert.False(t, flags.Enabled(t.Context(), "unknown", user))
+	})
+
+	t.Run("stored", func(t *testing.T) {
+		t.Parallel()
+		flags := newFlags(&memoryStore{flags: []featureflags.Flag{
+			{Name: "pdf_reports"},
+			{Name: "route_planner", Enabled: true, Roles: []featureflags.Role{featureflags.RoleEmployee}},
+		}})
+		assert.False(t, flags.Enabled(t.Context(), "pdf_reports", user))
+		assert.True(t, flags.Enabled(t.Context(), "route_planner", user))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+		store := &memoryStore{}
+		flags := newFlags(store)
+		flags.Enabled(t.Context(), "pdf_reports", user)
+		flags.Enabled(t.Context(), "route_planner", user)
+		assert.Equal(t, 1, store.loads)
+	})
+
+	t.Run("store error uses defaults", func(t *testing.T) {
+		t.Parallel()
+		flags := newFlags(&memoryStore{err: errors.New("db down")})
+		assert.True(t, flags.Enabled(t.Context(), "pdf_reports", user))
+	})
+
+	t.Run("store error is not retried before the TTL", func(t *testing.T) {
+		t.Parallel()
+		store := &memoryStore{err: errors.New("db down")}
+		flags := newFlags(store)
+		flags.Enabled(t.Context(), "pdf_reports", user)
+		flags.Enabled(t.Context(), "route_planner", user)
+		assert.Equal(t, 1, store.loads)
+	})
+}
+
+func TestFlags_List(t *testing.T) {
+	t.Parallel()
+
+	flags := newFlags(&memoryStore{flags: []featureflags.Flag{{Name: "route_planner", Percentage: 10}}})
+
+	list, err := flags.List(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []featureflags.Flag{
+		{Name: "pdf_reports", Enabled: true, Percentage: 100},
+		{Name: "route_planner", Percentage: 10},
+	}, list)
+
+	_, err = newFlags(&memoryStore{err: errors.New("db down")}).List(t.Context())
+	require.Error(t, err)
+}
+
+func TestFlags_Set(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		flags := newFlags(&memoryStore{})
+		user := featureflags.User{ID: 1}
+		require.False(t, flags.Enabled(t.Context(), "route_planner", user))
+
+		flag := featureflags.Flag{Name: "route_planner", Enabled: true, Percentage: 100}
+		require.NoError(t, flags.Set(t.Context(), flag, 7))
+		assert.True(t, flags.Enabled(t.Context(), "route_planner", user))
+
+		stored, err := flags.Get(t.Context(), "route_planner")
+		require.NoError(t, err)
+		assert.Equal(t, 100, stored.Percentage)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		t.Parallel()
+		err := newFlags(&memoryStore{}).Set(t.Context(), featureflags.Flag{Name: "unknown"}, 7)
+		require.ErrorIs(t, err, featureflags.ErrUnknownFlag)
+	})
+
+	t.Run("invalid percentage", func(t *testing.T) {
+		t.Parallel()
+		err := newFlags(&memoryStore{}).Set(t.Context(), featureflags.Flag{Name: "pdf_reports", Percentage: 101}, 7)
+		require.Error(t, err)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		t.Parallel()
+		flags := newFlags(&memoryStore{err: errors.New("db down")})
+		err := flags.Set(t.Context(), featureflags.Flag{Name: "pdf_reports"}, 7)
+		require.Error(t, err)
+	})
+}
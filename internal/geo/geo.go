@@ -0,0 +1,151 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrNoPoints is returned when there is nothing to export.
+var ErrNoPoints = errors.New("no points to export")
+
+// Format identifies the file format of an exported map.
+type Format string
+
+const (
+	// FormatGeoJSON is the GeoJSON format, supported by most web maps and GIS tools.
+	FormatGeoJSON Format = "geojson"
+	// FormatKML is the Keyhole Markup Language, supported by Google Earth and Google My Maps.
+	FormatKML Format = "kml"
+)
+
+// Formats lists all supported map formats in the order they are offered to users.
+var Formats = []Format{FormatGeoJSON, FormatKML}
+
+// ParseFormat converts a raw value (e.g. inline button data) into a Format.
+// Unknown or empty values fall back to FormatGeoJSON.
+func ParseFormat(value string) Format {
+	if Format(value) == FormatKML {
+		return FormatKML
+	}
+	return FormatGeoJSON
+}
+
+// Extension returns the file extension (without a dot) for the format.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// MIMEType returns the MIME type that should be used when sending the file.
+func (f Format) MIMEType() string {
+	switch f {
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml"
+	case FormatGeoJSON:
+		return "application/geo+json"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Point is a single named location on the map.
+type Point struct {
+	Name        string
+	Description string
+	Latitude    float64
+	Longitude   float64
+}
+
+// Export encodes the points in the requested format.
+func Export(format Format, points []Point) (*bytes.Buffer, error) {
+	if len(points) == 0 {
+		return nil, ErrNoPoints
+	}
+
+	switch format {
+	case FormatGeoJSON:
+		return exportGeoJSON(points)
+	case FormatKML:
+		return exportKML(points)
+	default:
+		return nil, fmt.Errorf("unsupported map format '%s'", format)
+	}
+}
+
+type geoJSONCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   geoJSONGeometry   `json:"geometry"`
+	Properties map[string]string `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// exportGeoJSON encodes the points as a GeoJSON feature collection. GeoJSON positions
+// are written as [longitude, latitude].
+func exportGeoJSON(points []Point) (*bytes.Buffer, error) {
+	collection := geoJSONCollection{Type: "FeatureCollection", Features: make([]geoJSONFeature, 0, len(points))}
+	for _, point := range points {
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geoJSONGeometry{Type: "Point", Coordinates: [2]float64{point.Longitude, point.Latitude}},
+			Properties: map[string]string{"name": point.Name, "description": point.Description},
+		})
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(collection); err != nil {
+		return nil, fmt.Errorf("failed to encode geojson: %w", err)
+	}
+
+	return buffer, nil
+}
+
+type kmlDocument struct {
+	XMLName    xml.Name       `xml:"kml"`
+	Namespace  string         `xml:"xmlns,attr"`
+	Placemarks []kmlPlacemark `xml:"Document>Placemark"`
+}
+
+type kmlPlacemark struct {
+	Name        string `xml:"name"`
+	Description string `xml:"description,omitempty"`
+	Coordinates string `xml:"Point>coordinates"`
+}
+
+// exportKML encodes the points as KML placemarks. KML coordinates are written as "longitude,latitude".
+func exportKML(points []Point) (*bytes.Buffer, error) {
+	document := kmlDocument{
+		Namespace:  "http://www.opengis.net/kml/2.2",
+		Placemarks: make([]kmlPlacemark, 0, len(points)),
+	}
+	for _, point := range points {
+		document.Placemarks = append(document.Placemarks, kmlPlacemark{
+			Name:        point.Name,
+			Description: point.Description,
+			Coordinates: strconv.FormatFloat(point.Longitude, 'f', -1, 64) + "," +
+				strconv.FormatFloat(point.Latitude, 'f', -1, 64),
+		})
+	}
+
+	buffer := bytes.NewBufferString(xml.Header)
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return nil, fmt.Errorf("failed to encode kml: %w", err)
+	}
+
+	return buffer, nil
+}
@@ -0,0 +1,74 @@
+package geo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/geo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var points = []geo.Point{
+	{Name: "#1", Description: "Repair & check", Latitude: 50.45, Longitude: 30.52},
+	{Name: "#2", Latitude: 49.84, Longitude: 24.03},
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	buffer, err := geo.Export(geo.FormatGeoJSON, points)
+	require.NoError(t, err)
+
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Geometry struct {
+				Type        string    `json:"type"`
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+			Properties map[string]string `json:"properties"`
+		} `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &collection))
+
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.Len(t, collection.Features, 2)
+	assert.Equal(t, "Point", collection.Features[0].Geometry.Type)
+	assert.Equal(t, []float64{30.52, 50.45}, collection.Features[0].Geometry.Coordinates)
+	assert.Equal(t, "#1", collection.Features[0].Properties["name"])
+	assert.Equal(t, "Repair & check", collection.Features[0].Properties["description"])
+}
+
+func TestExportKML(t *testing.T) {
+	t.Parallel()
+
+	buffer, err := geo.Export(geo.FormatKML, points)
+	require.NoError(t, err)
+
+	kml := buffer.String()
+	assert.Contains(t, kml, `<kml xmlns="http://www.opengis.net/kml/2.2">`)
+	assert.Contains(t, kml, "<name>#1</name>")
+	assert.Contains(t, kml, "<description>Repair &amp; check</description>")
+	assert.Contains(t, kml, "<coordinates>30.52,50.45</coordinates>")
+	assert.Contains(t, kml, "<coordinates>24.03,49.84</coordinates>")
+}
+
+func TestExportErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := geo.Export(geo.FormatKML, nil)
+	require.ErrorIs(t, err, geo.ErrNoPoints)
+
+	_, err = geo.Export(geo.Format("gpx"), points)
+	require.ErrorContains(t, err, "unsupported map format")
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, geo.FormatKML, geo.ParseFormat("kml"))
+	assert.Equal(t, geo.FormatGeoJSON, geo.ParseFormat("geojson"))
+	assert.Equal(t, geo.FormatGeoJSON, geo.ParseFormat(""))
+	assert.Equal(t, "application/vnd.google-earth.kml+xml", geo.FormatKML.MIMEType())
+}
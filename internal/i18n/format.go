@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberFormat describes how integers are grouped in a language.
+type numberFormat struct {
+	// separator is placed between groups of three digits.
+	separator string
+	// minGrouping is the smallest number of digits that gets grouped at all.
+	minGrouping int
+}
+
+// dateLayouts maps a language to its short date layout.
+var dateLayouts = map[string]string{
+	"en": "02/01/2006",
+	"uk": "02.01.2006",
+	"pl": "02.01.2006",
+}
+
+// numberFormats maps a language to its CLDR grouping of integers.
+// Ukrainian and Polish group with a no-break space, so numbers never wrap, and Polish
+// leaves four-digit numbers ungrouped.
+var numberFormats = map[string]numberFormat{
+	"en": {separator: ",", minGrouping: 4},
+	"uk": {separator: "\u00a0", minGrouping: 4},
+	"pl": {separator: "\u00a0", minGrouping: 5},
+}
+
+// FormatDate formats t as a short date in the given language.
+// Unknown languages use the English layout.
+func FormatDate(lang string, t time.Time) string {
+	layout, ok := dateLayouts[lang]
+	if !ok {
+		layout = dateLayouts["en"]
+	}
+
+	return t.Format(layout)
+}
+
+// FormatDateTime formats t as a short date followed by the time of day in the given language.
+func FormatDateTime(lang string, t time.Time) string {
+	return FormatDate(lang, t) + " " + t.Format("15:04")
+}
+
+// FormatNumber formats n with the thousand separator of the given language.
+// Unknown languages use the English separator.
+func FormatNumber(lang string, n int) string {
+	format, ok := numberFormats[lang]
+	if !ok {
+		format = numberFormats["en"]
+	}
+
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) < format.minGrouping {
+		return sign + digits
+	}
+
+	var builder strings.Builder
+	builder.WriteString(sign)
+	head := len(digits) % 3 //nolint:mnd // digits in a group
+	if head > 0 {
+		builder.WriteString(digits[:head])
+	}
+	for idx := head; idx < len(digits); idx += 3 {
+		if idx > 0 {
+			builder.WriteString(format.separator)
+		}
+		builder.WriteString(digits[idx : idx+3])
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDate(t *testing.T) {
+	date := time.Date(2025, time.March, 7, 14, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		lang         string
+		expected     string
+		expectedTime string
+	}{
+		{lang: "en", expected: "07/03/2025", expectedTime: "07/03/2025 14:05"},
+		{lang: "uk", expected: "07.03.2025", expectedTime: "07.03.2025 14:05"},
+		{lang: "pl", expected: "07.03.2025", expectedTime: "07.03.2025 14:05"},
+		{lang: "unknown", expected: "07/03/2025", expectedTime: "07/03/2025 14:05"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatDate(tt.lang, date); result != tt.expected {
+			t.Errorf("FormatDate(%q) = %q, want %q", tt.lang, result, tt.expected)
+		}
+		if result := FormatDateTime(tt.lang, date); result != tt.expectedTime {
+			t.Errorf("FormatDateTime(%q) = %q, want %q", tt.lang, result, tt.expectedTime)
+		}
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		lang     string
+		n        int
+		expected string
+	}{
+		{lang: "en", n: 0, expected: "0"},
+		{lang: "en", n: 999, expected: "999"},
+		{lang: "en", n: 1000, expected: "1,000"},
+		{lang: "en", n: 1234567, expected: "1,234,567"},
+		{lang: "en", n: -12345, expected: "-12,345"},
+		{lang: "uk", n: 1000, expected: "1\u00a0000"},
+		{lang: "uk", n: 123456, expected: "123\u00a0456"},
+		{lang: "pl", n: 1000, expected: "1000"},
+		{lang: "pl", n: 12345, expected: "12\u00a0345"},
+		{lang: "unknown", n: 4321, expected: "4,321"},
+	}
+
+	for _, tt := range tests {
+		if result := FormatNumber(tt.lang, tt.n); result != tt.expected {
+			t.Errorf("FormatNumber(%q, %d) = %q, want %q", tt.lang, tt.n, result, tt.expected)
+		}
+	}
+}
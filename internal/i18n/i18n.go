@@ -3,7 +3,13 @@ package i18n
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 )
 
@@ -14,41 +20,85 @@ var localesFS embed.FS
 type Localizer struct {
 	translations map[string]map[string]string
 	mu           sync.RWMutex
+	misses       missRecorder
+}
+
+// supportedLanguages are the languages with an embedded locale file.
+var supportedLanguages = []string{"en", "uk", "pl"}
+
+// Languages returns the codes of the supported languages.
+func Languages() []string {
+	return slices.Clone(supportedLanguages)
 }
 
 // NewLocalizer creates a new Localizer instance and loads all translations.
 func NewLocalizer() (*Localizer, error) {
-	locale := &Localizer{
-		translations: make(map[string]map[string]string),
+	locale := &Localizer{}
+	if err := locale.Reload(""); err != nil {
+		return nil, err
 	}
 
-	// Load supported languages
-	languages := []string{"en", "uk"}
-	for _, lang := range languages {
-		if err := locale.loadLanguage(lang); err != nil {
-			return nil, fmt.Errorf("failed to load language %s: %w", lang, err)
+	return locale, nil
+}
+
+// Reload loads the embedded translations again and applies the overrides found in dir.
+// A <lang>.json file in dir replaces the embedded translations of its keys, keys it lacks
+// keep the embedded text. An empty dir loads the embedded translations only.
+// On error the translations in use are kept.
+func (l *Localizer) Reload(dir string) error {
+	translations := make(map[string]map[string]string, len(supportedLanguages))
+	for _, lang := range supportedLanguages {
+		embedded, err := loadLanguage(lang)
+		if err != nil {
+			return fmt.Errorf("failed to load language %s: %w", lang, err)
+		}
+		if dir != "" {
+			if err = applyOverrides(embedded, filepath.Join(dir, lang+".json")); err != nil {
+				return fmt.Errorf("failed to override language %s: %w", lang, err)
+			}
 		}
+		translations[lang] = embedded
 	}
 
-	return locale, nil
+	l.mu.Lock()
+	l.translations = translations
+	l.mu.Unlock()
+
+	return nil
 }
 
 // loadLanguage loads translations for a specific language from embedded JSON files.
-func (l *Localizer) loadLanguage(lang string) error {
+func loadLanguage(lang string) (map[string]string, error) {
 	filename := fmt.Sprintf("locales/%s.json", lang)
 	data, err := localesFS.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("failed to read locale file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to read locale file %s: %w", filename, err)
 	}
 
 	var translations map[string]string
 	if err = json.Unmarshal(data, &translations); err != nil {
-		return fmt.Errorf("failed to unmarshal locale file %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to unmarshal locale file %s: %w", filename, err)
 	}
 
-	l.mu.Lock()
-	l.translations[lang] = translations
-	l.mu.Unlock()
+	return translations, nil
+}
+
+// applyOverrides copies the translations of the override file into translations.
+// A missing file overrides nothing.
+func applyOverrides(translations map[string]string, filename string) error {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read override file %s: %w", filename, err)
+	}
+
+	var overrides map[string]string
+	if err = json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("failed to unmarshal override file %s: %w", filename, err)
+	}
+	maps.Copy(translations, overrides)
 
 	return nil
 }
@@ -56,12 +106,22 @@ func (l *Localizer) loadLanguage(lang string) error {
 // Get returns the translation for the given key in the specified language.
 // If the translation is not found, it returns the key itself.
 func (l *Localizer) Get(lang, key string) string {
+	translation, miss := l.lookup(lang, key)
+	if miss != "" {
+		l.recordMiss(lang, key, miss)
+	}
+
+	return translation
+}
+
+// lookup returns the translation of the key and how it was resolved if the language lacks it.
+func (l *Localizer) lookup(lang, key string) (string, MissKind) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
 	if langTranslations, ok := l.translations[lang]; ok {
 		if translation, exists := langTranslations[key]; exists {
-			return translation
+			return translation, ""
 		}
 	}
 
@@ -69,13 +129,13 @@ func (l *Localizer) Get(lang, key string) string {
 	if lang != "en" {
 		if enTranslations, ok := l.translations["en"]; ok {
 			if translation, exists := enTranslations[key]; exists {
-				return translation
+				return translation, MissFallback
 			}
 		}
 	}
 
 	// Return the key itself if no translation found
-	return key
+	return key, MissRaw
 }
 
 // GetWithData returns the translation for the given key with placeholder replacement.
@@ -134,6 +194,8 @@ func NormalizeLanguageCode(telegramLang string) string {
 			return "en"
 		case "uk", "ua": // Both uk and ua map to Ukrainian
 			return "uk"
+		case "pl":
+			return "pl"
 		default:
 			return "en" // Default to English
 		}
@@ -18,7 +18,7 @@ func TestNewLocalizer(t *testing.T) {
 		t.Fatal("No translations loaded")
 	}
 
-	// Check that both languages are loaded
+	// Check that all languages are loaded
 	if _, ok := localizer.translations["en"]; !ok {
 		t.Error("English translations not loaded")
 	}
@@ -26,6 +26,10 @@ func TestNewLocalizer(t *testing.T) {
 	if _, ok := localizer.translations["uk"]; !ok {
 		t.Error("Ukrainian translations not loaded")
 	}
+
+	if _, ok := localizer.translations["pl"]; !ok {
+		t.Error("Polish translations not loaded")
+	}
 }
 
 func TestGet(t *testing.T) {
@@ -155,6 +159,11 @@ func TestNormalizeLanguageCode(t *testing.T) {
 			input:    "ua",
 			expected: "uk",
 		},
+		{
+			name:     "Polish with region",
+			input:    "pl-PL",
+			expected: "pl",
+		},
 		{
 			name:     "Unknown language defaults to English",
 			input:    "de",
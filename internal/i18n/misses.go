@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// MissKind tells how a key missing from the requested language was resolved.
+type MissKind string
+
+const (
+	// MissFallback is a key shown in English because the language lacks it.
+	MissFallback MissKind = "fallback"
+	// MissRaw is a key shown as is because no language has it.
+	MissRaw MissKind = "raw"
+)
+
+// Miss is a key which was missing from a language, with the number of times it was requested
+// since the misses were last taken.
+type Miss struct {
+	Lang  string
+	Key   string
+	Kind  MissKind
+	Count int
+}
+
+// missRecorder counts the missing translations of supported languages.
+type missRecorder struct {
+	mu     sync.Mutex
+	counts map[Miss]int // keyed by the miss without its count
+	onMiss func(lang string, kind MissKind)
+}
+
+// OnMiss sets the function called for every missing translation, e.g. to count it in a metric.
+// Misses of languages without a locale file are not reported.
+func (l *Localizer) OnMiss(fn func(lang string, kind MissKind)) {
+	l.misses.mu.Lock()
+	defer l.misses.mu.Unlock()
+
+	l.misses.onMiss = fn
+}
+
+// TakeMisses returns the missing translations recorded since the last call, the most requested first.
+func (l *Localizer) TakeMisses() []Miss {
+	l.misses.mu.Lock()
+	counts := l.misses.counts
+	l.misses.counts = nil
+	l.misses.mu.Unlock()
+
+	misses := make([]Miss, 0, len(counts))
+	for miss, count := range counts {
+		miss.Count = count
+		misses = append(misses, miss)
+	}
+	slices.SortFunc(misses, func(a, b Miss) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Lang, b.Lang), cmp.Compare(a.Key, b.Key))
+	})
+
+	return misses
+}
+
+// recordMiss counts the missing translation of the key.
+func (l *Localizer) recordMiss(lang, key string, kind MissKind) {
+	if !slices.Contains(supportedLanguages, lang) {
+		return
+	}
+
+	l.misses.mu.Lock()
+	defer l.misses.mu.Unlock()
+
+	if l.misses.counts == nil {
+		l.misses.counts = make(map[Miss]int)
+	}
+	l.misses.counts[Miss{Lang: lang, Key: key, Kind: kind}]++
+	if l.misses.onMiss != nil {
+		l.misses.onMiss(lang, kind)
+	}
+}
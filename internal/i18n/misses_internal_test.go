@@ -0,0 +1,39 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTakeMisses(t *testing.T) {
+	localizer, err := NewLocalizer()
+	if err != nil {
+		t.Fatalf("Failed to create localizer: %v", err)
+	}
+	localizer.translations["uk"] = map[string]string{}
+
+	var reported []MissKind
+	localizer.OnMiss(func(_ string, kind MissKind) { reported = append(reported, kind) })
+
+	localizer.Get("en", "welcome.authenticated")
+	localizer.Get("uk", "welcome.authenticated")
+	localizer.Get("uk", "welcome.authenticated")
+	localizer.Get("pl", "non.existent.key")
+	localizer.Get("unknown", "non.existent.key")
+	localizer.GetPlural("uk", "admin.broadcast.recipients", 3)
+
+	want := []Miss{
+		{Lang: "uk", Key: "welcome.authenticated", Kind: MissFallback, Count: 2},
+		{Lang: "pl", Key: "non.existent.key", Kind: MissRaw, Count: 1},
+		{Lang: "uk", Key: "admin.broadcast.recipients.other", Kind: MissFallback, Count: 1},
+	}
+	if got := localizer.TakeMisses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("TakeMisses() = %+v, want %+v", got, want)
+	}
+	if len(reported) != 4 {
+		t.Errorf("OnMiss called %d times, want 4", len(reported))
+	}
+	if got := localizer.TakeMisses(); len(got) != 0 {
+		t.Errorf("TakeMisses() after taking = %+v, want none", got)
+	}
+}
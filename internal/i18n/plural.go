@@ -0,0 +1,98 @@
+package i18n
+
+// PluralCategory is a CLDR plural category.
+type PluralCategory string
+
+// CLDR plural categories used by the supported languages.
+const (
+	PluralOne   PluralCategory = "one"
+	PluralFew   PluralCategory = "few"
+	PluralMany  PluralCategory = "many"
+	PluralOther PluralCategory = "other"
+)
+
+// pluralRules maps a language to its CLDR cardinal plural rule for integers.
+var pluralRules = map[string]func(n int) PluralCategory{
+	"en": pluralEnglish,
+	"uk": pluralEastSlavic,
+	"pl": pluralPolish,
+}
+
+// PluralCategoryFor returns the CLDR plural category of n in the given language.
+// Unknown languages follow the English rule.
+func PluralCategoryFor(lang string, n int) PluralCategory {
+	if n < 0 {
+		n = -n
+	}
+
+	rule, ok := pluralRules[lang]
+	if !ok {
+		rule = pluralEnglish
+	}
+
+	return rule(n)
+}
+
+// GetPlural returns the plural form of the key matching n, with the {count} placeholder
+// replaced by n formatted for the language. Plural forms are stored as "<key>.<category>" entries,
+// e.g. "tasks.count.few".
+// If the form for the category is missing, the "other" form is used, and if there is
+// no "other" form either, the key itself is returned.
+func (l *Localizer) GetPlural(lang, key string, n int) string {
+	formKey := key + "." + string(PluralCategoryFor(lang, n))
+
+	// Only the form finally used is recorded as missing, a language may lack some categories.
+	translation, miss := l.lookup(lang, formKey)
+	if miss == MissRaw {
+		otherKey := key + "." + string(PluralOther)
+		if translation, miss = l.lookup(lang, otherKey); miss == MissRaw {
+			l.recordMiss(lang, key, MissRaw)
+			return key
+		}
+		formKey = otherKey
+	}
+	if miss != "" {
+		l.recordMiss(lang, formKey, miss)
+	}
+
+	return replaceAll(translation, "{count}", FormatNumber(lang, n))
+}
+
+// pluralEnglish implements the CLDR rule for English: one for 1, other for the rest.
+func pluralEnglish(n int) PluralCategory {
+	if n == 1 {
+		return PluralOne
+	}
+
+	return PluralOther
+}
+
+// pluralEastSlavic implements the CLDR rule for Ukrainian:
+// one for 1, 21, 31…; few for 2-4, 22-24…; many for 0, 5-20, 25-30….
+func pluralEastSlavic(n int) PluralCategory {
+	mod10, mod100 := n%10, n%100
+
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+// pluralPolish implements the CLDR rule for Polish:
+// one for 1 only; few for 2-4, 22-24…; many for 0, 5-21, 25-31….
+func pluralPolish(n int) PluralCategory {
+	mod10, mod100 := n%10, n%100
+
+	switch {
+	case n == 1:
+		return PluralOne
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
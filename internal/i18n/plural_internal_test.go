@@ -0,0 +1,111 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPluralCategoryFor(t *testing.T) {
+	tests := []struct {
+		lang     string
+		n        int
+		expected PluralCategory
+	}{
+		{lang: "en", n: 0, expected: PluralOther},
+		{lang: "en", n: 1, expected: PluralOne},
+		{lang: "en", n: 2, expected: PluralOther},
+		{lang: "en", n: 21, expected: PluralOther},
+		{lang: "uk", n: 0, expected: PluralMany},
+		{lang: "uk", n: 1, expected: PluralOne},
+		{lang: "uk", n: 3, expected: PluralFew},
+		{lang: "uk", n: 5, expected: PluralMany},
+		{lang: "uk", n: 11, expected: PluralMany},
+		{lang: "uk", n: 12, expected: PluralMany},
+		{lang: "uk", n: 21, expected: PluralOne},
+		{lang: "uk", n: 22, expected: PluralFew},
+		{lang: "uk", n: 111, expected: PluralMany},
+		{lang: "uk", n: -2, expected: PluralFew},
+		{lang: "pl", n: 0, expected: PluralMany},
+		{lang: "pl", n: 1, expected: PluralOne},
+		{lang: "pl", n: 4, expected: PluralFew},
+		{lang: "pl", n: 13, expected: PluralMany},
+		{lang: "pl", n: 21, expected: PluralMany},
+		{lang: "pl", n: 24, expected: PluralFew},
+		{lang: "unknown", n: 1, expected: PluralOne},
+		{lang: "unknown", n: 3, expected: PluralOther},
+	}
+
+	for _, tt := range tests {
+		result := PluralCategoryFor(tt.lang, tt.n)
+		if result != tt.expected {
+			t.Errorf("PluralCategoryFor(%q, %d) = %q, want %q", tt.lang, tt.n, result, tt.expected)
+		}
+	}
+}
+
+func TestGetPlural(t *testing.T) {
+	localizer, err := NewLocalizer()
+	if err != nil {
+		t.Fatalf("Failed to create localizer: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lang     string
+		key      string
+		n        int
+		expected string
+	}{
+		{name: "English one", lang: "en", key: "statistic.tasks", n: 1, expected: "1 task"},
+		{name: "English other", lang: "en", key: "statistic.tasks", n: 5, expected: "5 tasks"},
+		{name: "Ukrainian one", lang: "uk", key: "statistic.tasks", n: 21, expected: "21 завдання"},
+		{name: "Ukrainian many", lang: "uk", key: "statistic.tasks", n: 11, expected: "11 завдань"},
+		{name: "Polish few", lang: "pl", key: "statistic.tasks", n: 3, expected: "3 zadania"},
+		{name: "Polish many", lang: "pl", key: "statistic.tasks", n: 25, expected: "25 zadań"},
+		{name: "Fallback to English", lang: "unknown", key: "statistic.tasks", n: 2, expected: "2 tasks"},
+		{name: "Missing key", lang: "en", key: "nonexistent", n: 2, expected: "nonexistent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := localizer.GetPlural(tt.lang, tt.key, tt.n)
+			if result != tt.expected {
+				t.Errorf("GetPlural(%q, %q, %d) = %q, want %q", tt.lang, tt.key, tt.n, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLocalesHaveSameKeys(t *testing.T) {
+	localizer, err := NewLocalizer()
+	if err != nil {
+		t.Fatalf("Failed to create localizer: %v", err)
+	}
+
+	// Plural forms differ between languages, so they are compared by their base key.
+	baseKeys := func(lang string) map[string]bool {
+		keys := make(map[string]bool)
+		for key := range localizer.translations[lang] {
+			for _, category := range []PluralCategory{PluralOne, PluralFew, PluralMany, PluralOther} {
+				key = strings.TrimSuffix(key, "."+string(category))
+			}
+			keys[key] = true
+		}
+		return keys
+	}
+
+	expected := baseKeys("en")
+	for _, lang := range []string{"uk", "pl"} {
+		actual := baseKeys(lang)
+		for key := range expected {
+			if !actual[key] {
+				t.Errorf("Key %q is missing in %s locale", key, lang)
+			}
+		}
+		for key := range actual {
+			if !expected[key] {
+				t.Errorf("Key %q from %s locale is missing in en locale", key, lang)
+			}
+		}
+	}
+}
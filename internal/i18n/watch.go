@@ -0,0 +1,62 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay groups the burst of events of a single file update, e.g. a write followed by
+// a chmod or the symlink swap of a mounted ConfigMap, into one reload.
+const reloadDelay = 500 * time.Millisecond
+
+// Watch applies the overrides in dir and reloads the translations whenever a file in dir changes,
+// until the context is canceled. A reload which fails, e.g. on a file with invalid JSON, is logged
+// and the translations in use are kept.
+func (l *Localizer) Watch(ctx context.Context, log *slog.Logger, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create locale watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err = watcher.Add(dir); err != nil {
+		return fmt.Errorf("failed to watch locale directory %s: %w", dir, err)
+	}
+
+	reload := func() {
+		if reloadErr := l.Reload(dir); reloadErr != nil {
+			log.ErrorContext(ctx, "Failed to reload locale overrides, keeping the previous ones", "error", reloadErr)
+			return
+		}
+		log.InfoContext(ctx, "Locale overrides reloaded", "dir", dir)
+	}
+	reload()
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			log.DebugContext(ctx, "Locale directory changed", "event", event.String())
+			timer.Reset(reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.WarnContext(ctx, "Locale watcher error", "error", err)
+		case <-timer.C:
+			reload()
+		}
+	}
+}
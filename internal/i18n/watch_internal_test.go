@@ -0,0 +1,77 @@
+package i18n
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeOverride(t *testing.T, dir, lang, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, lang+".json"), []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write override file: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	localizer, err := NewLocalizer()
+	if err != nil {
+		t.Fatalf("Failed to create localizer: %v", err)
+	}
+	embedded := localizer.Get("uk", "info.name")
+
+	dir := t.TempDir()
+	writeOverride(t, dir, "en", `{"welcome.authenticated": "Welcome!"}`)
+
+	if err = localizer.Reload(dir); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := localizer.Get("en", "welcome.authenticated"); got != "Welcome!" {
+		t.Errorf("Overridden key = %q, want %q", got, "Welcome!")
+	}
+	if got := localizer.Get("en", "info.name"); got != "*Name:* {name}" {
+		t.Errorf("Key missing from the override = %q, want the embedded text", got)
+	}
+	if got := localizer.Get("uk", "info.name"); got != embedded {
+		t.Errorf("Language without an override = %q, want %q", got, embedded)
+	}
+
+	writeOverride(t, dir, "en", `{"welcome.authenticated": `)
+	if err = localizer.Reload(dir); err == nil {
+		t.Fatal("Reload() with invalid JSON succeeded, want an error")
+	}
+	if got := localizer.Get("en", "welcome.authenticated"); got != "Welcome!" {
+		t.Errorf("Translations after a failed reload = %q, want the previous ones", got)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	localizer, err := NewLocalizer()
+	if err != nil {
+		t.Fatalf("Failed to create localizer: %v", err)
+	}
+	dir := t.TempDir()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	done := make(chan error, 1)
+	go func() { done <- localizer.Watch(t.Context(), log, dir) }()
+
+	// The watcher may not be running yet, so the file is written until the change is picked up.
+	deadline := time.Now().Add(5 * time.Second)
+	for localizer.Get("pl", "welcome.authenticated") != "Witamy!" {
+		if time.Now().After(deadline) {
+			t.Fatal("Override was not reloaded")
+		}
+		writeOverride(t, dir, "pl", `{"welcome.authenticated": "Witamy!"}`)
+		time.Sleep(2 * reloadDelay)
+	}
+
+	select {
+	case err = <-done:
+		t.Fatalf("Watch() returned early: %v", err)
+	default:
+	}
+}
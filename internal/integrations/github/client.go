@@ -0,0 +1,81 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxErrorBody limits how much of an error response is included in the returned error.
+const maxErrorBody = 512
+
+// apiVersion is the version of the GitHub REST API the client is written against.
+const apiVersion = "2022-11-28"
+
+// Issue is a new issue.
+type Issue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+// CreatedIssue identifies an issue created in the repository.
+type CreatedIssue struct {
+	Number int    `json:"number"`
+	URL    string `json:"html_url"`
+}
+
+// Client creates issues in a single repository.
+type Client struct {
+	baseURL    string
+	repository string
+	token      string
+	http       *http.Client
+}
+
+// NewClient creates a client of the repository, given as "owner/name", authenticated with the
+// token. baseURL is the API root, https://api.github.com or the /api/v3 URL of GitHub Enterprise.
+func NewClient(baseURL, repository, token string, timeout time.Duration) *Client {
+	return &Client{baseURL: baseURL, repository: repository, token: token, http: &http.Client{Timeout: timeout}}
+}
+
+// CreateIssue opens the issue in the repository. Labels which do not exist in the repository
+// are created by GitHub.
+func (c *Client) CreateIssue(ctx context.Context, issue Issue) (CreatedIssue, error) {
+	body, err := json.Marshal(issue)
+	if err != nil {
+		return CreatedIssue{}, fmt.Errorf("failed to encode issue: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/issues", c.baseURL, c.repository)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return CreatedIssue{}, fmt.Errorf("failed to create issue request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return CreatedIssue{}, fmt.Errorf("failed to send issue request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return CreatedIssue{}, fmt.Errorf("github rejected issue: %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+
+	var created CreatedIssue
+	if err = json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return CreatedIssue{}, fmt.Errorf("failed to decode issue response: %w", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,59 @@
+package github_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/integrations/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CreateIssue(t *testing.T) {
+	t.Parallel()
+
+	issue := github.Issue{Title: "[Bug] Map is empty", Body: "The tasks map shows no tasks.", Labels: []string{"bug"}}
+
+	t.Run("success - returns the created issue", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/repos/UnknownOlympus/oracle/issues", r.URL.Path)
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+
+			var received github.Issue
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			assert.Equal(t, issue, received)
+
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number":42,"html_url":"https://github.com/UnknownOlympus/oracle/issues/42"}`))
+		}))
+		defer server.Close()
+
+		client := github.NewClient(server.URL, "UnknownOlympus/oracle", "token", time.Second)
+		created, err := client.CreateIssue(t.Context(), issue)
+
+		require.NoError(t, err)
+		assert.Equal(t, github.CreatedIssue{
+			Number: 42,
+			URL:    "https://github.com/UnknownOlympus/oracle/issues/42",
+		}, created)
+	})
+
+	t.Run("error - rejected by github", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		client := github.NewClient(server.URL, "UnknownOlympus/oracle", "token", time.Second)
+		_, err := client.CreateIssue(t.Context(), issue)
+
+		require.ErrorContains(t, err, `github rejected issue: 401 Unauthorized: {"message":"Bad credentials"}`)
+	})
+}
@@ -0,0 +1,204 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidUpdate is returned when a task update message cannot be parsed.
+var ErrInvalidUpdate = errors.New("invalid task update")
+
+// handleTimeout bounds the executor lookup and key deletion of a single update.
+const handleTimeout = 10 * time.Second
+
+// scanCount is the number of keys requested per SCAN call while matching patterns.
+const scanCount = 100
+
+// TaskUpdate is the message published by Hermes when a task is commented, closed or otherwise changed,
+// e.g. {"task_id": 123}.
+type TaskUpdate struct {
+	TaskID int `json:"task_id"`
+}
+
+// ParseTaskUpdate decodes a task update message.
+func ParseTaskUpdate(payload string) (TaskUpdate, error) {
+	var update TaskUpdate
+	if err := json.Unmarshal([]byte(payload), &update); err != nil {
+		return TaskUpdate{}, fmt.Errorf("%w: %w", ErrInvalidUpdate, err)
+	}
+	if update.TaskID <= 0 {
+		return TaskUpdate{}, fmt.Errorf("%w: missing task id", ErrInvalidUpdate)
+	}
+
+	return update, nil
+}
+
+// Targets lists the cache entries affected by a task update.
+type Targets struct {
+	Keys     []string // Keys are deleted as is.
+	Patterns []string // Patterns are matched with SCAN and every matching key is deleted.
+}
+
+// TargetsFor returns the cache entries to delete when the task changes: its details
+// and the reports and statistics of every executor of the task.
+func TargetsFor(taskID int, telegramIDs []int64) Targets {
+	targets := Targets{Keys: []string{fmt.Sprintf("oracle:task_details:%d", taskID)}}
+	for _, telegramID := range telegramIDs {
+		targets.Patterns = append(targets.Patterns,
+			fmt.Sprintf("oracle:report:user:%d:*", telegramID),
+			fmt.Sprintf("oracle:statistic:%d:*", telegramID),
+		)
+	}
+
+	return targets
+}
+
+// ExecutorLookup resolves the executors of a task.
+type ExecutorLookup interface {
+	GetTaskExecutorTelegramIDs(ctx context.Context, taskID int) ([]int64, error)
+}
+
+// Invalidator listens to task updates on a Redis pub/sub channel and deletes the cached
+// entries that went stale.
+type Invalidator struct {
+	log       *slog.Logger
+	client    redis.UniversalClient
+	executors ExecutorLookup
+	channel   string
+	ops       *prometheus.CounterVec
+}
+
+// New creates an invalidator subscribed to the given channel. The results of invalidations
+// are counted in ops with the "invalidate" operation label.
+func New(
+	log *slog.Logger,
+	client redis.UniversalClient,
+	executors ExecutorLookup,
+	channel string,
+	ops *prometheus.CounterVec,
+) *Invalidator {
+	return &Invalidator{
+		log:       log.With(slog.String("component", "invalidation")),
+		client:    client,
+		executors: executors,
+		channel:   channel,
+		ops:       ops,
+	}
+}
+
+// Run handles task updates until the context is canceled. The subscription is restored
+// automatically if the connection to Redis is lost.
+func (i *Invalidator) Run(ctx context.Context) {
+	pubsub := i.client.Subscribe(ctx, i.channel)
+	defer pubsub.Close()
+
+	i.log.InfoContext(ctx, "Listening for task updates", "channel", i.channel)
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if err := i.handle(ctx, msg.Payload); err != nil {
+				i.ops.WithLabelValues("invalidate", "error").Inc()
+				i.log.ErrorContext(ctx, "Failed to invalidate cache", "error", err, "payload", msg.Payload)
+				continue
+			}
+			i.ops.WithLabelValues("invalidate", "success").Inc()
+		}
+	}
+}
+
+// handle deletes the cache entries affected by a single task update.
+func (i *Invalidator) handle(ctx context.Context, payload string) error {
+	update, err := ParseTaskUpdate(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, handleTimeout)
+	defer cancel()
+
+	telegramIDs, err := i.executors.GetTaskExecutorTelegramIDs(ctx, update.TaskID)
+	if err != nil {
+		return fmt.Errorf("failed to get executors of task %d: %w", update.TaskID, err)
+	}
+
+	targets := TargetsFor(update.TaskID, telegramIDs)
+	keys := targets.Keys
+	for _, pattern := range targets.Patterns {
+		matched, scanErr := i.scan(ctx, pattern)
+		if scanErr != nil {
+			return scanErr
+		}
+		keys = append(keys, matched...)
+	}
+
+	// Keys are deleted one by one in a pipeline, as a multi-key DEL fails when the keys
+	// live on different Redis Cluster nodes.
+	pipe := i.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete keys of task %d: %w", update.TaskID, err)
+	}
+
+	i.log.DebugContext(ctx, "Invalidated cache of updated task", "task_id", update.TaskID, "keys", len(keys))
+	return nil
+}
+
+// scan returns the keys matching the pattern. In Redis Cluster every master is scanned,
+// since a single SCAN only walks the keys of one node.
+func (i *Invalidator) scan(ctx context.Context, pattern string) ([]string, error) {
+	cluster, ok := i.client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, i.client, pattern)
+	}
+
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		matched, err := scanNode(ctx, node, pattern)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, matched...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// scanNode returns the keys of a single node matching the pattern.
+func scanNode(ctx context.Context, client redis.Cmdable, pattern string) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, scanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan keys matching %q: %w", pattern, err)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,57 @@
+package invalidation_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/invalidation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTaskUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		update, err := invalidation.ParseTaskUpdate(`{"task_id": 123, "event": "closed"}`)
+		require.NoError(t, err)
+		assert.Equal(t, 123, update.TaskID)
+	})
+
+	t.Run("error - malformed payload", func(t *testing.T) {
+		t.Parallel()
+		_, err := invalidation.ParseTaskUpdate("123")
+		require.ErrorIs(t, err, invalidation.ErrInvalidUpdate)
+	})
+
+	t.Run("error - missing task id", func(t *testing.T) {
+		t.Parallel()
+		_, err := invalidation.ParseTaskUpdate(`{"event": "closed"}`)
+		require.ErrorIs(t, err, invalidation.ErrInvalidUpdate)
+	})
+}
+
+func TestTargetsFor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("task with executors", func(t *testing.T) {
+		t.Parallel()
+		targets := invalidation.TargetsFor(42, []int64{111, 222})
+
+		assert.Equal(t, []string{"oracle:task_details:42"}, targets.Keys)
+		assert.Equal(t, []string{
+			"oracle:report:user:111:*",
+			"oracle:statistic:111:*",
+			"oracle:report:user:222:*",
+			"oracle:statistic:222:*",
+		}, targets.Patterns)
+	})
+
+	t.Run("task without executors", func(t *testing.T) {
+		t.Parallel()
+		targets := invalidation.TargetsFor(42, nil)
+
+		assert.Equal(t, []string{"oracle:task_details:42"}, targets.Keys)
+		assert.Empty(t, targets.Patterns)
+	})
+}
@@ -0,0 +1,139 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ErrQueueFull is returned by Enqueue when the queue has no room for another job.
+	ErrQueueFull = errors.New("jobqueue: queue is full")
+
+	// ErrQueueClosed is returned by Enqueue after the queue has been stopped.
+	ErrQueueClosed = errors.New("jobqueue: queue is closed")
+
+	// ErrShutdownTimeout is returned by Stop when queued jobs did not finish before the deadline.
+	ErrShutdownTimeout = errors.New("jobqueue: queued jobs did not finish before shutdown deadline")
+)
+
+// Job is a unit of work executed by a queue worker.
+type Job func(ctx context.Context)
+
+// Queue is a bounded queue of jobs processed by a fixed pool of worker goroutines.
+// Jobs receive a context that is only canceled if they outlive the shutdown deadline.
+type Queue struct {
+	log       *slog.Logger
+	jobs      chan Job
+	workers   int
+	depth     prometheus.Gauge
+	mu        sync.RWMutex
+	closed    bool
+	wg        sync.WaitGroup
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+}
+
+// New creates a queue holding up to size jobs which are processed by the given number of workers.
+// The depth gauge reflects the number of jobs waiting in the queue.
+func New(log *slog.Logger, workers, size int, depth prometheus.Gauge) *Queue {
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+
+	return &Queue{
+		log:       log.With(slog.String("component", "jobqueue")),
+		jobs:      make(chan Job, size),
+		workers:   workers,
+		depth:     depth,
+		jobCtx:    jobCtx,
+		cancelJob: cancelJob,
+	}
+}
+
+// Start launches the workers.
+func (q *Queue) Start(ctx context.Context) {
+	for range q.workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	q.log.InfoContext(ctx, "Job queue started", "workers", q.workers, "size", cap(q.jobs))
+}
+
+// Enqueue adds the job to the queue without blocking and returns the number of jobs waiting
+// in the queue, including this one.
+func (q *Queue) Enqueue(job Job) (int, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return 0, ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- job:
+		waiting := len(q.jobs)
+		q.depth.Set(float64(waiting))
+		return waiting, nil
+	default:
+		return 0, ErrQueueFull
+	}
+}
+
+// Len returns the number of jobs waiting in the queue.
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Stop stops accepting new jobs and waits for the queued ones to finish. If ctx expires first,
+// the jobs' context is canceled and ErrShutdownTimeout is returned.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancelJob()
+		q.log.InfoContext(ctx, "Job queue stopped")
+		return nil
+	case <-ctx.Done():
+		q.cancelJob()
+		q.log.WarnContext(ctx, "Job queue stopped before queued jobs finished", "waiting", len(q.jobs))
+		return ErrShutdownTimeout
+	}
+}
+
+// work processes jobs until the queue is closed and drained.
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		q.depth.Set(float64(len(q.jobs)))
+		q.run(job)
+	}
+}
+
+// run executes a single job, recovering from panics so one faulty job
+// cannot take the whole bot down.
+func (q *Queue) run(job Job) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			q.log.ErrorContext(q.jobCtx, "Queued job panicked", "panic", fmt.Sprint(rec))
+		}
+	}()
+
+	job(q.jobCtx)
+}
@@ -0,0 +1,113 @@
+package jobqueue_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/jobqueue"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	newGauge := func() prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_queue_depth"})
+	}
+
+	t.Run("runs queued jobs", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+
+		queue := jobqueue.New(logger, 2, 10, newGauge())
+		queue.Start(t.Context())
+
+		for range 5 {
+			_, err := queue.Enqueue(func(_ context.Context) { calls.Add(1) })
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, queue.Stop(t.Context()))
+		assert.Equal(t, int32(5), calls.Load())
+	})
+
+	t.Run("rejects jobs when full", func(t *testing.T) {
+		t.Parallel()
+		depth := newGauge()
+
+		queue := jobqueue.New(logger, 1, 2, depth)
+
+		waiting, err := queue.Enqueue(func(_ context.Context) {})
+		require.NoError(t, err)
+		assert.Equal(t, 1, waiting)
+
+		waiting, err = queue.Enqueue(func(_ context.Context) {})
+		require.NoError(t, err)
+		assert.Equal(t, 2, waiting)
+		assert.InDelta(t, 2, testutil.ToFloat64(depth), 0)
+
+		_, err = queue.Enqueue(func(_ context.Context) {})
+		require.ErrorIs(t, err, jobqueue.ErrQueueFull)
+		assert.Equal(t, 2, queue.Len())
+
+		queue.Start(t.Context())
+		require.NoError(t, queue.Stop(t.Context()))
+		assert.InDelta(t, 0, testutil.ToFloat64(depth), 0)
+	})
+
+	t.Run("rejects jobs after stop", func(t *testing.T) {
+		t.Parallel()
+
+		queue := jobqueue.New(logger, 1, 2, newGauge())
+		queue.Start(t.Context())
+		require.NoError(t, queue.Stop(t.Context()))
+
+		_, err := queue.Enqueue(func(_ context.Context) {})
+		require.ErrorIs(t, err, jobqueue.ErrQueueClosed)
+	})
+
+	t.Run("cancels running job after deadline", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		canceled := make(chan struct{})
+
+		queue := jobqueue.New(logger, 1, 1, newGauge())
+		queue.Start(t.Context())
+		_, err := queue.Enqueue(func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+		})
+		require.NoError(t, err)
+		<-started
+
+		stopCtx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, queue.Stop(stopCtx), jobqueue.ErrShutdownTimeout)
+		<-canceled
+	})
+
+	t.Run("recovers from panicking job", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+
+		queue := jobqueue.New(logger, 1, 2, newGauge())
+		queue.Start(t.Context())
+
+		_, err := queue.Enqueue(func(_ context.Context) { panic("boom") })
+		require.NoError(t, err)
+		_, err = queue.Enqueue(func(_ context.Context) { calls.Add(1) })
+		require.NoError(t, err)
+
+		require.NoError(t, queue.Stop(t.Context()))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -9,12 +9,30 @@ import (
 // It includes counters for commands received, messages sent,
 // new users, and a histogram for database query durations.
 type Metrics struct {
-	CommandReceived  *prometheus.CounterVec   // Counter for received commands
-	CacheOps         *prometheus.CounterVec   // Counter for cache operations
-	SentMessages     *prometheus.CounterVec   // Counter for sent messages
-	NewUsers         prometheus.Counter       // Counter for new users
-	DBQueryDuration  *prometheus.HistogramVec // Histogram for database query durations
-	ReportGeneration *prometheus.HistogramVec // Histogram for report query durations
+	CommandReceived   *prometheus.CounterVec   // Counter for received commands
+	CacheOps          *prometheus.CounterVec   // Counter for cache operations
+	SentMessages      *prometheus.CounterVec   // Counter for sent messages
+	NewUsers          prometheus.Counter       // Counter for new users
+	DBQueryDuration   *prometheus.HistogramVec // Histogram for database query durations
+	ReportGeneration  *prometheus.HistogramVec // Histogram for report query durations
+	Throttled         *prometheus.CounterVec   // Counter for requests rejected by the rate limiter
+	ReportQueueDepth  prometheus.Gauge         // Gauge for reports waiting for generation
+	SendFailures      *prometheus.CounterVec   // Counter for messages Telegram refused to deliver
+	HermesDuration    *prometheus.HistogramVec // Histogram for Hermes gRPC call durations
+	HermesErrors      *prometheus.CounterVec   // Counter for failed Hermes gRPC calls
+	HermesCircuitOpen prometheus.Gauge         // Gauge set to 1 while calls to Hermes are rejected
+	CacheDegraded     *prometheus.CounterVec   // Counter for cache operations skipped while Redis is failing
+	CacheCircuitOpen  prometheus.Gauge         // Gauge set to 1 while the cache is bypassed
+	HandlerDuration   *prometheus.HistogramVec // Histogram for the time handlers take to serve an update
+	HandlerPanics     *prometheus.CounterVec   // Counter for panics recovered from handlers
+	TelegramAPIErrors *prometheus.CounterVec   // Counter for errors returned by the Telegram Bot API
+	LinkedUsers       prometheus.Gauge         // Gauge for Telegram users linked to employees
+	Admins            prometheus.Gauge         // Gauge for linked users with admin privileges
+	PendingStates     prometheus.Gauge         // Gauge for users the bot is waiting for an input from
+	WebhookRejected   *prometheus.CounterVec   // Counter for webhook requests rejected by authentication
+	CommentOutbox     *prometheus.CounterVec   // Counter for delivery attempts of queued comments
+	AgreementsCache   *prometheus.CounterVec   // Counter for cached Hermes agreement lookups
+	TranslationMisses *prometheus.CounterVec   // Counter for texts missing from the user's language
 }
 
 // NewMetrics creates a new Metrics instance with the provided Prometheus Registerer.
@@ -53,5 +71,79 @@ func NewMetrics(reg prometheus.Registerer) *Metrics {
 			Name: "oracle_cache_operations_total",
 			Help: "Total number of cache operations.",
 		}, []string{"operation", "status"}),
+		Throttled: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_throttled_requests_total",
+			Help: "Total number of user requests rejected by the rate limiter.",
+		}, []string{"type"}), // type: message, callback
+		ReportQueueDepth: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_report_queue_depth",
+			Help: "Number of reports waiting in the generation queue.",
+		}),
+		SendFailures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_send_failures_total",
+			Help: "Total number of messages Telegram refused to deliver.",
+		}, []string{"source", "reason"}), // source: broadcast, alert, weekly_report, digest, webhook
+		HermesDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "oracle_hermes_request_duration_seconds",
+			Help:    "Duration of gRPC calls to Hermes.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}), // method: GetAgreements, AddComment
+		HermesErrors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_hermes_errors_total",
+			Help: "Total number of failed gRPC calls to Hermes.",
+		}, []string{"method", "code"}), // code: Unavailable, DeadlineExceeded
+		HermesCircuitOpen: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_hermes_circuit_open",
+			Help: "Set to 1 while calls to Hermes are rejected after repeated failures.",
+		}),
+		CacheDegraded: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_cache_degraded_operations_total",
+			Help: "Total number of cache operations skipped because Redis is failing.",
+		}, []string{"operation"}), // operation: get, mget, set, del
+		CacheCircuitOpen: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_cache_circuit_open",
+			Help: "Whether the cache is bypassed after repeated Redis failures (1) or not (0).",
+		}),
+		HandlerDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "oracle_handler_duration_seconds",
+			Help:    "Duration of handling a Telegram update.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"handler"}), // handler: info, active_tasks, task_details, text
+		TelegramAPIErrors: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_telegram_api_errors_total",
+			Help: "Total number of errors returned by the Telegram Bot API.",
+		}, []string{"type"}), // type: too_many_requests, blocked, message_not_modified, network
+		LinkedUsers: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_linked_users",
+			Help: "Number of Telegram users linked to employees.",
+		}),
+		Admins: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_admins",
+			Help: "Number of linked users with admin privileges.",
+		}),
+		PendingStates: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "oracle_pending_states",
+			Help: "Number of users the bot is waiting for an input from, e.g. a comment or an email.",
+		}),
+		WebhookRejected: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_webhook_rejected_total",
+			Help: "Total number of webhook requests rejected by authentication.",
+		}, []string{"path", "reason"}), // reason: missing, invalid
+		CommentOutbox: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_comment_outbox_total",
+			Help: "Total number of delivery attempts of comments queued for Hermes.",
+		}, []string{"result"}), // result: delivered, retried, failed
+		AgreementsCache: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_agreements_cache_total",
+			Help: "Total number of customer agreement lookups answered from the cache or by Hermes.",
+		}, []string{"result"}), // result: hit, miss
+		HandlerPanics: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_handler_panics_total",
+			Help: "Total number of panics recovered from update handlers.",
+		}, []string{"handler"}),
+		TranslationMisses: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "oracle_translation_misses_total",
+			Help: "Total number of texts shown in English or as a raw key because the user's language lacks them.",
+		}, []string{"lang", "kind"}), // kind: fallback, raw
 	}
 }
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStater is implemented by *pgxpool.Pool.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// PoolCollector exports the statistics of database connection pools, labeled by pool name.
+// The statistics are read from the pools on every scrape.
+type PoolCollector struct {
+	pools map[string]PoolStater
+
+	acquired      *prometheus.Desc
+	idle          *prometheus.Desc
+	total         *prometheus.Desc
+	maxConns      *prometheus.Desc
+	acquires      *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	acquireWait   *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector for the pools, e.g. {"primary": pool, "replica": replica}.
+func NewPoolCollector(pools map[string]PoolStater) *PoolCollector {
+	labels := []string{"pool"}
+	return &PoolCollector{
+		pools: pools,
+		acquired: prometheus.NewDesc("oracle_db_pool_acquired_conns",
+			"Number of connections currently in use.", labels, nil),
+		idle: prometheus.NewDesc("oracle_db_pool_idle_conns",
+			"Number of idle connections in the pool.", labels, nil),
+		total: prometheus.NewDesc("oracle_db_pool_total_conns",
+			"Number of open connections, including the ones being established.", labels, nil),
+		maxConns: prometheus.NewDesc("oracle_db_pool_max_conns",
+			"Maximum number of connections in the pool.", labels, nil),
+		acquires: prometheus.NewDesc("oracle_db_pool_acquires_total",
+			"Total number of connections acquired from the pool.", labels, nil),
+		emptyAcquires: prometheus.NewDesc("oracle_db_pool_empty_acquires_total",
+			"Total number of acquires that had to wait for a connection.", labels, nil),
+		acquireWait: prometheus.NewDesc("oracle_db_pool_acquire_wait_seconds_total",
+			"Total time spent waiting for a connection.", labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.acquireWait
+}
+
+// Collect implements prometheus.Collector.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, pool := range c.pools {
+		stat := pool.Stat()
+		ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(
+			c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()), name,
+		)
+		ch <- prometheus.MustNewConstMetric(
+			c.acquireWait, prometheus.CounterValue, stat.AcquireDuration().Seconds(), name,
+		)
+	}
+}
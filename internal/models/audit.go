@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// AdminAction represents a single entry of the admin audit log.
+type AdminAction struct {
+	ID          int64     `json:"id"`           // Unique identifier for the entry
+	AdminID     int64     `json:"admin_id"`     // Telegram ID of the admin who acted
+	AdminName   string    `json:"admin_name"`   // Short name of the admin, empty if unknown
+	Action      string    `json:"action"`       // Action is what was done: broadcast, geocoding_reset
+	PayloadHash string    `json:"payload_hash"` // SHA-256 hash of the action payload
+	CreatedAt   time.Time `json:"created_at"`   // CreatedAt is when the action was performed
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// BroadcastTemplate represents a reusable broadcast text saved by an admin.
+type BroadcastTemplate struct {
+	ID        int       `json:"id"`         // Unique identifier for the template
+	Name      string    `json:"name"`       // Name shown on the buttons, unique
+	Text      string    `json:"text"`       // Text with placeholders such as {date}
+	CreatedBy int64     `json:"created_by"` // Telegram ID of the admin who saved the template last
+	UpdatedAt time.Time `json:"updated_at"` // UpdatedAt is when the template was saved last
+}
+
+// BroadcastReceipt represents the delivery of an important broadcast to a user, who is asked
+// to confirm it.
+type BroadcastReceipt struct {
+	TelegramID int64     `json:"telegram_id"` // Telegram ID of the recipient
+	Name       string    `json:"name"`        // Short name of the recipient, empty if unknown
+	AckedAt    time.Time `json:"acked_at"`    // AckedAt is when the recipient confirmed, zero if not yet
+}
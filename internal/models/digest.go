@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// DigestSubscription represents a user who receives the morning digest of open tasks.
+type DigestSubscription struct {
+	TelegramID int64     `json:"telegram_id"`  // Telegram ID of the subscriber
+	Timezone   string    `json:"timezone"`     // IANA name of the subscriber's time zone
+	LastSentOn time.Time `json:"last_sent_on"` // Local date of the last digest, zero if never sent
+}
+
+// DigestTask represents an open task listed in the morning digest.
+type DigestTask struct {
+	ID           int       // ID is the unique identifier for the task.
+	Description  string    // Description provides a brief overview of the task.
+	CreationDate time.Time // CreationDate is when the task was created.
+}
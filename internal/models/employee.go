@@ -24,6 +24,8 @@ type Customer struct {
 	Contract string `json:"contract"` // Contract ID og the customer
 	Address  string `json:"address"`  // Address which binded to user
 	Tariff   string `json:"tariff"`   // Tariff is the current tariff of the customer
+	Balance  string `json:"balance"`  // Balance is the account balance of the agreement
+	Phone    string `json:"phone"`    // Phone is the contact phone number of the agreement
 }
 
 // BotUser represents an individual user in the bot.
@@ -31,3 +33,40 @@ type BotUser struct {
 	TelegramID int64 `json:"telegram_id"`
 	EmployeeID int   `json:"employee_id"`
 }
+
+// Teammate is an employee linked to a Telegram account, who can take over a task.
+type Teammate struct {
+	EmployeeID int    `json:"employee_id"`
+	ShortName  string `json:"shortname"`
+	FullName   string `json:"fullname"`
+	TelegramID int64  `json:"telegram_id"`
+}
+
+// UserCounts holds the number of Telegram users linked to employees.
+type UserCounts struct {
+	Linked int `json:"linked"` // Linked is the number of linked users
+	Admins int `json:"admins"` // Admins is the number of linked users with admin privileges
+}
+
+// InactiveUser represents a bot user who has not interacted with the bot for a long time.
+type InactiveUser struct {
+	TelegramID      int64     `json:"telegram_id"`      // Telegram ID of the user
+	ShortName       string    `json:"shortname"`        // Short name of the linked employee
+	LastInteraction time.Time `json:"last_interaction"` // LastInteraction is when the user was last seen
+}
+
+// Profile is an employee a Telegram user can act as: their own one, or one granted by an admin.
+type Profile struct {
+	EmployeeID int    `json:"employee_id"`
+	ShortName  string `json:"shortname"`
+	FullName   string `json:"fullname"`
+	Own        bool   `json:"own"`    // Own is the employee the user logged in as
+	Active     bool   `json:"active"` // Active is the employee the user acts as now
+}
+
+// OnCallWeek is a week of the on-call rota.
+type OnCallWeek struct {
+	WeekStart  time.Time // WeekStart is the first day of the seven days on duty.
+	TelegramID int64     // TelegramID is the user on duty.
+	ShortName  string    // ShortName is the short name of the employee on duty, empty if unknown.
+}
@@ -0,0 +1,17 @@
+package models
+
+// OutboxComment represents a task comment waiting in the outbox to be delivered to Hermes.
+type OutboxComment struct {
+	ID         int64  // ID is the unique identifier of the outbox entry.
+	TelegramID int64  // TelegramID is the user who wrote the comment.
+	TaskID     int64  // TaskID is the task the comment belongs to.
+	Author     string // Author is the short name of the employee shown in Hermes.
+	Text       string // Text is the comment itself.
+	Attempts   int    // Attempts is the number of failed deliveries so far.
+}
+
+// QuickReply represents a frequent comment offered to be sent with one tap.
+type QuickReply struct {
+	ID   int    // ID is the unique identifier of the quick reply.
+	Text string // Text is the comment sent to the task.
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// QuietHours represents the daily period in which a user does not want non-critical messages.
+// Start and End are minutes after midnight in the user's time zone. End may be before Start,
+// then the quiet hours span midnight.
+type QuietHours struct {
+	TelegramID int64  `json:"telegram_id"` // Telegram ID of the user
+	Start      int    `json:"start"`       // Start is the first quiet minute of the day
+	End        int    `json:"end"`         // End is the first minute after the quiet hours
+	Timezone   string `json:"timezone"`    // IANA name of the user's time zone, empty if unknown
+}
+
+// EndsAt reports whether now falls into the quiet hours, evaluated in loc, and returns when
+// they end.
+func (q QuietHours) EndsAt(now time.Time, loc *time.Location) (time.Time, bool) {
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	var quiet bool
+	if q.Start <= q.End {
+		quiet = minute >= q.Start && minute < q.End
+	} else {
+		quiet = minute >= q.Start || minute < q.End
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End/60, q.End%60, 0, 0, loc)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end, true
+}
+
+// DeferredMessage represents a message postponed until the quiet hours of its recipient end.
+type DeferredMessage struct {
+	ID         int64     `json:"id"`
+	TelegramID int64     `json:"telegram_id"` // Telegram ID of the recipient
+	Source     string    `json:"source"`      // Source is the feature which sent the message
+	Payload    []byte    `json:"payload"`     // Payload is the message content encoded by the bot
+	DeliverAt  time.Time `json:"deliver_at"`
+}
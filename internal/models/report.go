@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ArchivedReport represents a report sent to a user and kept for downloading it again.
+type ArchivedReport struct {
+	ID         int64     // ID is the unique identifier of the archived report.
+	TelegramID int64     // TelegramID is the user the report was generated for.
+	From       time.Time // From is the start of the reported period.
+	To         time.Time // To is the end of the reported period.
+	Format     string    // Format is the file format of the report, e.g. xlsx.
+	Size       int64     // Size is the file size in bytes.
+	SHA256     string    // SHA256 is the hex encoded hash of the file.
+	FileID     string    // FileID is the Telegram file ID of the sent document, empty if unknown.
+	ObjectKey  string    // ObjectKey is the key of the file in the object storage, empty if kept in the database.
+	CreatedAt  time.Time // CreatedAt is the time the report was generated.
+}
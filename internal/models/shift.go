@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Shift is a work shift of an employee.
+type Shift struct {
+	ID             int64         // ID is the unique identifier of the shift.
+	TelegramID     int64         // TelegramID is the user who worked the shift.
+	ShortName      string        // ShortName is the short name of the employee, empty if unknown.
+	StartedAt      time.Time     // StartedAt is when the shift started.
+	StartLatitude  pgtype.Float8 // StartLatitude is where the shift started, if shared.
+	StartLongitude pgtype.Float8 // StartLongitude is where the shift started, if shared.
+	EndedAt        time.Time     // EndedAt is when the shift ended, zero while it is open.
+	EndLatitude    pgtype.Float8 // EndLatitude is where the shift ended, if shared.
+	EndLongitude   pgtype.Float8 // EndLongitude is where the shift ended, if shared.
+}
@@ -12,11 +12,65 @@ type TaskSummary struct {
 	Count int    // Count represents the number of times the task has occurred.
 }
 
+// TaskDuration represents how long tasks of a single type took, from creation to closing.
+type TaskDuration struct {
+	Type    string        // Type indicates the type of the task.
+	Average time.Duration // Average is the mean time between creation and closing of a task.
+	Median  time.Duration // Median is the median time between creation and closing of a task.
+}
+
+// EmployeeTaskSummary represents the number of tasks completed by a single employee.
+type EmployeeTaskSummary struct {
+	EmployeeID int    // EmployeeID is the unique identifier of the employee.
+	ShortName  string // ShortName is the display name of the employee.
+	Count      int    // Count represents the number of completed tasks.
+}
+
+// EmployeePerformance represents the completed tasks of a single employee and how long
+// they took on average, from creation to closing.
+type EmployeePerformance struct {
+	EmployeeID     int           // EmployeeID is the unique identifier of the employee.
+	ShortName      string        // ShortName is the display name of the employee.
+	Count          int           // Count represents the number of completed tasks.
+	AvgClosingTime time.Duration // AvgClosingTime is the average time between creation and closing of a task.
+}
+
+// ReportTaskType represents a task type the user can include in or exclude from reports.
+type ReportTaskType struct {
+	ID       int    // ID is the unique identifier of the task type.
+	Name     string // Name is the display name of the task type.
+	Excluded bool   // Excluded reports whether tasks of this type are left out of the user's reports.
+}
+
 // ActiveTask represents a task that is currently active. It contains
 // the unique identifier, a brief description associated with the task.
 type ActiveTask struct {
-	ID          int    // ID is the unique identifier for the task.
-	Description string // Description provides a brief overview of the task.
+	ID           int           // ID is the unique identifier for the task.
+	Description  string        // Description provides a brief overview of the task.
+	Type         string        // Type is the name of the task type, set only by the filtered query.
+	CreationDate time.Time     // CreationDate is when the task was created.
+	SLA          time.Duration // SLA is how long a task of this type may stay open, zero if not set.
+}
+
+// SLABreached reports whether the task is open for longer than the SLA of its type.
+// Tasks of types without an SLA are never breached.
+func (t ActiveTask) SLABreached(now time.Time) bool {
+	return t.SLA > 0 && now.Sub(t.CreationDate) > t.SLA
+}
+
+// TaskTypeSLA is the service level configured for a task type.
+type TaskTypeSLA struct {
+	TypeID   int    // TypeID is the identifier of the task type.
+	TypeName string // TypeName is the name of the task type.
+	Hours    int    // Hours is how long a task of this type may stay open, zero if not set.
+}
+
+// ActiveTaskFilter narrows down and orders the active tasks of an executor.
+// The zero value keeps all tasks, newest first.
+type ActiveTaskFilter struct {
+	OldestFirst     bool // OldestFirst orders the tasks by creation date, oldest first.
+	ByType          bool // ByType groups the tasks by the name of their type.
+	WithCoordinates bool // WithCoordinates keeps only the tasks with a known location.
 }
 
 // TaskDetails represents the details of a task in the system.
@@ -35,6 +89,15 @@ type TaskDetails struct {
 	Comments       []string      `json:"comments"`        // List of comments related to the task
 	Latitude       pgtype.Float8 `json:"latitude"`        // Latitude indicates the geographical latitude of the task.
 	Longitude      pgtype.Float8 `json:"longitude"`       // Longitude indicates the geographical longitude of the task.
+	History        []TaskEvent   `json:"history"`         // Chronological status and assignment changes
+}
+
+// TaskEvent represents a single status or assignment change in the task history.
+type TaskEvent struct {
+	Type      string    `json:"type"`       // Type of the event: status, assigned, unassigned
+	Value     string    `json:"value"`      // Value is the new status or the affected employee
+	Actor     string    `json:"actor"`      // Actor is who made the change, empty if unknown
+	CreatedAt time.Time `json:"created_at"` // CreatedAt is when the change happened
 }
 
 // GeocodingIssue represents a task that has geocoding problems.
@@ -45,3 +108,27 @@ type GeocodingIssue struct {
 	GeocodingError    string // Last geocoding error message
 	GeocodingAttempts int    // Number of failed geocoding attempts
 }
+
+// TaskLocation represents an active task with known coordinates, used to export the
+// user's tasks as a map.
+type TaskLocation struct {
+	ID          int     // ID is the unique identifier for the task.
+	Description string  // Description provides a brief overview of the task.
+	Address     string  // Address is the task address.
+	Latitude    float64 // Latitude indicates the geographical latitude of the task.
+	Longitude   float64 // Longitude indicates the geographical longitude of the task.
+}
+
+// Material is a material of the catalog which can be logged as used on a task.
+type Material struct {
+	ID   int    `json:"id"`   // ID is the unique identifier of the material.
+	Name string `json:"name"` // Name is the name of the material.
+	Unit string `json:"unit"` // Unit is the unit the quantity is entered in, e.g. m or pcs.
+}
+
+// TaskMaterial is the quantity of a material used on a task.
+type TaskMaterial struct {
+	Name     string  `json:"name"`     // Name is the name of the material.
+	Unit     string  `json:"unit"`     // Unit is the unit of the quantity.
+	Quantity float64 `json:"quantity"` // Quantity is the total quantity logged on the task.
+}
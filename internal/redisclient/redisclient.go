@@ -0,0 +1,55 @@
+package redisclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/redis/go-redis/v9"
+)
+
+// New creates a Redis client for the configured mode (Sentinel, Cluster or a single server)
+// and checks the connection with PING.
+func New(ctx context.Context, cfg config.Redis, timeout time.Duration) (redis.UniversalClient, error) {
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err = client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// newClient constructs the client without connecting to Redis.
+func newClient(cfg config.Redis) (redis.UniversalClient, error) {
+	switch {
+	case cfg.SentinelMaster != "":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.SentinelMaster,
+			SentinelAddrs: cfg.SentinelAddrs,
+			Username:      cfg.Username,
+			Password:      cfg.Password,
+			DB:            cfg.DB,
+		}), nil
+	case len(cfg.ClusterAddrs) > 0:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    cfg.ClusterAddrs,
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}), nil
+	default:
+		opts, err := redis.ParseURL(cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis address: %w", err)
+		}
+		return redis.NewClient(opts), nil
+	}
+}
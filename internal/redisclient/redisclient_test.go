@@ -0,0 +1,68 @@
+package redisclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("single server", func(t *testing.T) {
+		t.Parallel()
+		client, err := newClient(config.Redis{Addr: "redis://:secret@localhost:6379/2"})
+		require.NoError(t, err)
+		defer client.Close()
+
+		single, ok := client.(*redis.Client)
+		require.True(t, ok)
+		assert.Equal(t, "localhost:6379", single.Options().Addr)
+		assert.Equal(t, 2, single.Options().DB)
+	})
+
+	t.Run("sentinel", func(t *testing.T) {
+		t.Parallel()
+		client, err := newClient(config.Redis{
+			SentinelMaster: "mymaster",
+			SentinelAddrs:  []string{"sentinel-1:26379", "sentinel-2:26379"},
+			DB:             1,
+		})
+		require.NoError(t, err)
+		defer client.Close()
+
+		// A Sentinel-backed client is a regular client connected to the current master.
+		failover, ok := client.(*redis.Client)
+		require.True(t, ok)
+		assert.Equal(t, 1, failover.Options().DB)
+		assert.Equal(t, "FailoverClient", failover.Options().Addr)
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		t.Parallel()
+		client, err := newClient(config.Redis{ClusterAddrs: []string{"node-1:6379", "node-2:6379"}})
+		require.NoError(t, err)
+		defer client.Close()
+
+		cluster, ok := client.(*redis.ClusterClient)
+		require.True(t, ok)
+		assert.Equal(t, []string{"node-1:6379", "node-2:6379"}, cluster.Options().Addrs)
+	})
+
+	t.Run("error - invalid address", func(t *testing.T) {
+		t.Parallel()
+		_, err := newClient(config.Redis{Addr: "localhost:6379"})
+		require.ErrorContains(t, err, "failed to parse redis address")
+	})
+}
+
+func TestNew_ConnectionError(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(t.Context(), config.Redis{Addr: "redis://127.0.0.1:1"}, 100*time.Millisecond)
+	require.ErrorContains(t, err, "failed to connect to redis")
+}
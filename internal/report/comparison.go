@@ -0,0 +1,168 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// comparisonSheet is the name of the sheet comparing the report period with the previous one.
+const comparisonSheet = "Comparison"
+
+// totalType is the label of the row summing up all task types.
+const totalType = "Total"
+
+// Comparison holds the task counts of the period preceding the report period,
+// used to build the "Comparison" sheet of the Excel report.
+type Comparison struct {
+	From, To                 time.Time      // From and To bound the report period.
+	PreviousFrom, PreviousTo time.Time      // PreviousFrom and PreviousTo bound the preceding period.
+	Previous                 map[string]int // Previous maps a task type to its task count in the preceding period.
+}
+
+// ComparisonRow holds the number of tasks of a single type in both periods.
+type ComparisonRow struct {
+	Type     string
+	Current  int
+	Previous int
+}
+
+// Change returns the relative change of the task count in percent. It reports false
+// when there were no tasks in the previous period, because the change is undefined.
+func (r ComparisonRow) Change() (float64, bool) {
+	if r.Previous == 0 {
+		return 0, false
+	}
+
+	const percent = 100
+	return float64(r.Current-r.Previous) / float64(r.Previous) * percent, true
+}
+
+// PreviousPeriod returns the period of the same length directly preceding [from, to].
+// A period covering whole calendar months is compared with the same number of preceding
+// months, so e.g. February is compared with January regardless of month lengths.
+func PreviousPeriod(from, to time.Time) (time.Time, time.Time) {
+	monthStart := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+	if from.Equal(monthStart) {
+		for months := 1; ; months++ {
+			end := monthStart.AddDate(0, months, 0).Add(-time.Nanosecond)
+			if end.Equal(to) {
+				return monthStart.AddDate(0, -months, 0), monthStart.Add(-time.Nanosecond)
+			}
+			if end.After(to) {
+				break
+			}
+		}
+	}
+
+	return from.Add(-to.Sub(from)), from.Add(-time.Nanosecond)
+}
+
+// CompareByType counts the report rows per task type and matches them with the counts of the
+// previous period. A task with several customers has several rows, so tasks are counted by ID.
+// Rows are sorted by type, and the total of all types comes last.
+func CompareByType(rows []ExcelRow, previous map[string]int) []ComparisonRow {
+	current := countTasksByType(rows)
+
+	types := make([]string, 0, len(current)+len(previous))
+	for taskType := range current {
+		types = append(types, taskType)
+	}
+	for taskType := range previous {
+		if _, ok := current[taskType]; !ok && taskType != totalType {
+			types = append(types, taskType)
+		}
+	}
+	sort.Strings(types)
+
+	total := ComparisonRow{Type: totalType}
+	result := make([]ComparisonRow, 0, len(types)+1)
+	for _, taskType := range types {
+		row := ComparisonRow{Type: taskType, Current: current[taskType], Previous: previous[taskType]}
+		total.Current += row.Current
+		total.Previous += row.Previous
+		result = append(result, row)
+	}
+
+	return append(result, total)
+}
+
+// addComparisonSheet adds the sheet comparing task counts per type with the previous period.
+func (g *Generator) addComparisonSheet(rows []ExcelRow, comparison *Comparison) error {
+	if _, err := g.file.NewSheet(comparisonSheet); err != nil {
+		return fmt.Errorf("failed to generate new sheet '%s': %w", comparisonSheet, err)
+	}
+
+	headerStyle, err := g.file.NewStyle(&excelize.Style{
+		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#4F81BD"}, Pattern: 1},
+		Alignment: &excelize.Alignment{Vertical: "center", Horizontal: "center", WrapText: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create new style: %w", err)
+	}
+	boldStyle, err := g.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create new style: %w", err)
+	}
+	changeFormat := "+0.0%;-0.0%;0.0%"
+	changeStyle, err := g.file.NewStyle(&excelize.Style{
+		CustomNumFmt: &changeFormat,
+		Alignment:    &excelize.Alignment{Horizontal: "right"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create new style: %w", err)
+	}
+
+	period := func(from, to time.Time) string {
+		return from.Format("02.01.2006") + " – " + to.Format("02.01.2006")
+	}
+	headers := []interface{}{
+		"Task type",
+		period(comparison.From, comparison.To),
+		period(comparison.PreviousFrom, comparison.PreviousTo),
+		"Change",
+	}
+	if err = g.file.SetSheetRow(comparisonSheet, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set sheet row for headers: %w", err)
+	}
+	if err = g.file.SetCellStyle(comparisonSheet, "A1", "D1", headerStyle); err != nil {
+		return fmt.Errorf("failed to set cell style for headers: %w", err)
+	}
+
+	widths := map[string]float64{"A": 30, "B": 25, "C": 25, "D": 12} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err = g.file.SetColWidth(comparisonSheet, col, col, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	comparisonRows := CompareByType(rows, comparison.Previous)
+	lastRow := len(comparisonRows) + 1
+	for i, row := range comparisonRows {
+		change := interface{}("—")
+		if value, ok := row.Change(); ok {
+			const percent = 100
+			change = value / percent
+		}
+
+		rowData := []interface{}{row.Type, row.Current, row.Previous, change}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2) //nolint:mnd // the first row is the header
+		if err = g.file.SetSheetRow(comparisonSheet, cell, &rowData); err != nil {
+			return fmt.Errorf("failed to set sheet row: %w", err)
+		}
+	}
+
+	if err = g.file.SetCellStyle(comparisonSheet, "D2", fmt.Sprintf("D%d", lastRow), changeStyle); err != nil {
+		return fmt.Errorf("failed to set cell style for change: %w", err)
+	}
+	if err = g.file.SetCellStyle(
+		comparisonSheet, fmt.Sprintf("A%d", lastRow), fmt.Sprintf("C%d", lastRow), boldStyle,
+	); err != nil {
+		return fmt.Errorf("failed to set cell style for total: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestPreviousPeriod(t *testing.T) {
+	t.Parallel()
+	loc := time.UTC
+
+	tests := []struct {
+		name         string
+		from, to     time.Time
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{
+			name:         "whole month",
+			from:         time.Date(2025, 3, 1, 0, 0, 0, 0, loc),
+			to:           time.Date(2025, 4, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+			expectedFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, loc),
+			expectedTo:   time.Date(2025, 3, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+		},
+		{
+			name:         "whole month across a year",
+			from:         time.Date(2025, 1, 1, 0, 0, 0, 0, loc),
+			to:           time.Date(2025, 2, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+			expectedFrom: time.Date(2024, 12, 1, 0, 0, 0, 0, loc),
+			expectedTo:   time.Date(2025, 1, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+		},
+		{
+			name:         "last 7 days",
+			from:         time.Date(2025, 3, 8, 12, 0, 0, 0, loc),
+			to:           time.Date(2025, 3, 15, 12, 0, 0, 0, loc),
+			expectedFrom: time.Date(2025, 3, 1, 12, 0, 0, 0, loc),
+			expectedTo:   time.Date(2025, 3, 8, 12, 0, 0, 0, loc).Add(-time.Nanosecond),
+		},
+		{
+			name:         "part of a month",
+			from:         time.Date(2025, 3, 1, 0, 0, 0, 0, loc),
+			to:           time.Date(2025, 3, 11, 0, 0, 0, 0, loc),
+			expectedFrom: time.Date(2025, 2, 19, 0, 0, 0, 0, loc),
+			expectedTo:   time.Date(2025, 3, 1, 0, 0, 0, 0, loc).Add(-time.Nanosecond),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			from, to := report.PreviousPeriod(tt.from, tt.to)
+			assert.Equal(t, tt.expectedFrom, from)
+			assert.Equal(t, tt.expectedTo, to)
+		})
+	}
+}
+
+func TestCompareByType(t *testing.T) {
+	t.Parallel()
+	rows := []report.ExcelRow{
+		{ID: 1, Type: "Repair", Customer: "Alice"},
+		{ID: 1, Type: "Repair", Customer: "Bob"},
+		{ID: 2, Type: "Repair"},
+		{ID: 3, Type: "Connection"},
+	}
+	previous := map[string]int{"Repair": 4, "Removal": 1}
+
+	result := report.CompareByType(rows, previous)
+
+	assert.Equal(t, []report.ComparisonRow{
+		{Type: "Connection", Current: 1, Previous: 0},
+		{Type: "Removal", Current: 0, Previous: 1},
+		{Type: "Repair", Current: 2, Previous: 4},
+		{Type: "Total", Current: 3, Previous: 5},
+	}, result)
+
+	change, ok := result[2].Change()
+	assert.True(t, ok)
+	assert.InDelta(t, -50.0, change, 0.001)
+
+	_, ok = result[0].Change()
+	assert.False(t, ok)
+}
+
+func TestGenerateExcelReportWithComparison(t *testing.T) {
+	t.Parallel()
+	rows := []report.ExcelRow{
+		{ID: 1, Type: "Repair", CreationDate: time.Now()},
+		{ID: 2, Type: "Repair", CreationDate: time.Now()},
+	}
+	comparison := &report.Comparison{
+		From:         time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		PreviousFrom: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		PreviousTo:   time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		Previous:     map[string]int{"Repair": 1},
+	}
+
+	buffer, err := report.GenerateExcelReport(rows, comparison)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, []string{"Overview", "Repair", "Comparison"}, f.GetSheetList())
+
+	header, err := f.GetCellValue("Comparison", "B1")
+	require.NoError(t, err)
+	assert.Equal(t, "01.03.2025 – 31.03.2025", header)
+
+	current, err := f.GetCellValue("Comparison", "B2")
+	require.NoError(t, err)
+	assert.Equal(t, "2", current)
+
+	change, err := f.GetCellValue("Comparison", "D2")
+	require.NoError(t, err)
+	assert.Equal(t, "+100.0%", change)
+
+	total, err := f.GetCellValue("Comparison", "A3")
+	require.NoError(t, err)
+	assert.Equal(t, "Total", total)
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// utf8BOM is the UTF-8 byte order mark.
+const utf8BOM = "\ufeff"
+
+// csvHeader mirrors the Excel headers, with the task type as an explicit column
+// since a CSV file cannot be split into sheets.
+var csvHeader = []string{
+	"Task ID", "Task Type", "Creation Date", "Description", "Address", "Customer", "Contract", "Tariff", "Executors",
+}
+
+// GenerateCSVReport generates a CSV version of the completed tasks report, suitable for
+// importing into other tools. Rows are grouped by task type, dates use the ISO 8601 format,
+// and the file starts with a UTF-8 byte order mark so spreadsheet applications detect
+// the encoding of Cyrillic text correctly.
+// It returns ErrNoTasks if no rows are provided.
+func GenerateCSVReport(rows []ExcelRow) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	if err := WriteCSVReport(buffer, rows); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// WriteCSVReport writes the CSV report described in GenerateCSVReport to w.
+func WriteCSVReport(w io.Writer, rows []ExcelRow) error {
+	if len(rows) == 0 {
+		return ErrNoTasks
+	}
+
+	sorted := make([]ExcelRow, len(rows))
+	copy(sorted, rows)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Type < sorted[j].Type })
+
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return fmt.Errorf("failed to write byte order mark: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, row := range sorted {
+		record := []string{
+			strconv.Itoa(row.ID),
+			row.Type,
+			row.CreationDate.Format("2006-01-02"),
+			row.Description,
+			row.Address,
+			row.Customer,
+			row.Contract,
+			row.Tariff,
+			strings.Join(row.Executors, ", "),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write csv row for task %d: %w", row.ID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv writer: %w", err)
+	}
+
+	return nil
+}
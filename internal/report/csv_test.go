@@ -0,0 +1,48 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCSVReport(t *testing.T) {
+	t.Run("successful report generation", func(t *testing.T) {
+		testRows := []report.ExcelRow{
+			{
+				ID:           2,
+				Type:         "Підключення",
+				Description:  "Опис, з комою",
+				CreationDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			},
+			{ID: 1, Type: "Audit", Description: "Task \"quoted\"", Customer: "ACME", Executors: []string{"Ann", "Bob"}},
+		}
+
+		buffer, err := report.GenerateCSVReport(testRows)
+
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(buffer.Bytes(), []byte("\ufeff")))
+
+		content := bytes.TrimPrefix(buffer.Bytes(), []byte("\ufeff"))
+		records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, "Task ID", records[0][0])
+		assert.Equal(t,
+			[]string{"1", "Audit", "0001-01-01", "Task \"quoted\"", "", "ACME", "", "", "Ann, Bob"}, records[1])
+		assert.Equal(t, "2025-10-01", records[2][2])
+		assert.Equal(t, "Опис, з комою", records[2][3])
+	})
+
+	t.Run("no tasks found", func(t *testing.T) {
+		buffer, err := report.GenerateCSVReport(nil)
+
+		require.ErrorIs(t, err, report.ErrNoTasks)
+		assert.Nil(t, buffer)
+	})
+}
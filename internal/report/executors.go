@@ -0,0 +1,80 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+)
+
+// executorsSheet is the name of the sheet with the per-executor breakdown of the report.
+const executorsSheet = "By executor"
+
+// ExecutorSummary holds the number of report tasks an employee worked on.
+type ExecutorSummary struct {
+	Name   string // Short name of the employee
+	Tasks  int    // Distinct tasks the employee worked on
+	Shared int    // Tasks the employee shared with other executors
+}
+
+// SummarizeExecutors returns the number of distinct tasks per executor, most active first.
+// A task with several customers has several rows, so tasks are counted by ID.
+func SummarizeExecutors(rows []ExcelRow) []ExecutorSummary {
+	tasks := make(map[string]map[int]bool)
+	for _, row := range rows {
+		for _, executor := range row.Executors {
+			if tasks[executor] == nil {
+				tasks[executor] = make(map[int]bool)
+			}
+			tasks[executor][row.ID] = len(row.Executors) > 1
+		}
+	}
+
+	summaries := make([]ExecutorSummary, 0, len(tasks))
+	for executor, ids := range tasks {
+		summary := ExecutorSummary{Name: executor, Tasks: len(ids)}
+		for _, shared := range ids {
+			if shared {
+				summary.Shared++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Tasks != summaries[j].Tasks {
+			return summaries[i].Tasks > summaries[j].Tasks
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// addExecutorsSheet adds the sheet with the number of tasks per executor, so the work on
+// shared tasks can be split between the employees. The sheet is left out when the rows
+// carry no executors.
+func (g *Generator) addExecutorsSheet(rows []ExcelRow) error {
+	summaries := SummarizeExecutors(rows)
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	if _, err := g.file.NewSheet(executorsSheet); err != nil {
+		return fmt.Errorf("failed to generate new sheet '%s': %w", executorsSheet, err)
+	}
+
+	data := [][]interface{}{{"Executor", "Tasks", "Shared tasks"}}
+	for _, summary := range summaries {
+		data = append(data, []interface{}{summary.Name, summary.Tasks, summary.Shared})
+	}
+	if err := g.setTable(executorsSheet, 1, data); err != nil {
+		return fmt.Errorf("failed to fill tasks per executor: %w", err)
+	}
+
+	widths := map[string]float64{"A": 30, "B": 10, "C": 14} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err := g.file.SetColWidth(executorsSheet, col, col, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	return nil
+}
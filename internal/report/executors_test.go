@@ -0,0 +1,53 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateExcelReportExecutors(t *testing.T) {
+	t.Parallel()
+	rows := []report.ExcelRow{
+		{ID: 1, Type: "Repair", Executors: []string{"Ann", "Bob"}},
+		{ID: 1, Type: "Repair", Executors: []string{"Ann", "Bob"}}, // second customer of the same task
+		{ID: 2, Type: "Repair", Executors: []string{"Ann"}},
+		{ID: 3, Type: "Connection", Executors: []string{"Carl"}},
+	}
+
+	buffer, err := report.GenerateExcelReport(rows, nil)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	executors, err := f.GetCellValue("Repair", "H2")
+	require.NoError(t, err)
+	assert.Equal(t, "Ann, Bob", executors)
+
+	byExecutor, err := f.GetRows("By executor")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Executor", "Tasks", "Shared tasks"},
+		{"Ann", "2", "1"},
+		{"Bob", "1", "1"},
+		{"Carl", "1", "0"},
+	}, byExecutor)
+}
+
+func TestGenerateExcelReportWithoutExecutors(t *testing.T) {
+	t.Parallel()
+
+	buffer, err := report.GenerateExcelReport([]report.ExcelRow{{ID: 1, Type: "Repair"}}, nil)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.NotContains(t, f.GetSheetList(), "By executor")
+}
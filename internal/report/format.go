@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Format identifies the file format of a generated report.
+type Format string
+
+const (
+	// FormatXLSX is the default Excel workbook format.
+	FormatXLSX Format = "xlsx"
+	// FormatPDF is a printable document format, convenient on mobile devices.
+	FormatPDF Format = "pdf"
+	// FormatCSV is a plain-text format for importing the report into other tools.
+	FormatCSV Format = "csv"
+)
+
+// Formats lists all supported report formats in the order they are offered to users.
+var Formats = []Format{FormatXLSX, FormatPDF, FormatCSV}
+
+// ParseFormat converts a raw value (e.g. inline button data) into a Format.
+// Unknown or empty values fall back to FormatXLSX.
+func ParseFormat(value string) Format {
+	switch Format(value) {
+	case FormatPDF:
+		return FormatPDF
+	case FormatCSV:
+		return FormatCSV
+	case FormatXLSX:
+		return FormatXLSX
+	default:
+		return FormatXLSX
+	}
+}
+
+// Extension returns the file extension (without a dot) for the format.
+func (f Format) Extension() string {
+	return string(f)
+}
+
+// MIMEType returns the MIME type that should be used when sending the file.
+func (f Format) MIMEType() string {
+	switch f {
+	case FormatPDF:
+		return "application/pdf"
+	case FormatCSV:
+		return "text/csv"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Generate builds the report in the requested format. The comparison with the previous period
+// is only included in Excel reports and may be nil.
+func Generate(format Format, rows []ExcelRow, comparison *Comparison) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	if err := Write(buffer, format, rows, comparison); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// Write writes the report in the requested format to w, so large reports can go straight
+// to a file instead of being held in memory.
+func Write(w io.Writer, format Format, rows []ExcelRow, comparison *Comparison) error {
+	switch format {
+	case FormatPDF:
+		return WritePDFReport(w, rows)
+	case FormatCSV:
+		return WriteCSVReport(w, rows)
+	case FormatXLSX:
+		return WriteExcelReport(w, rows, comparison)
+	default:
+		return fmt.Errorf("unsupported report format '%s'", format)
+	}
+}
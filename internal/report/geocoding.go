@@ -0,0 +1,56 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// geocodingSheet is the name of the only sheet of the geocoding issues workbook.
+const geocodingSheet = "Geocoding issues"
+
+// GeocodingIssueRow holds a task whose address could not be geocoded.
+type GeocodingIssueRow struct {
+	TaskID   int    // ID of the task
+	Address  string // Address which failed to geocode
+	Attempts int    // Number of failed geocoding attempts
+	Error    string // Last geocoding error, empty if not known yet
+}
+
+// GenerateGeocodingIssuesReport generates an Excel workbook listing every geocoding issue,
+// so admins can fix the addresses offline.
+//
+// Returns ErrNoTasks if rows is empty.
+func GenerateGeocodingIssuesReport(rows []GeocodingIssueRow) (*bytes.Buffer, error) {
+	if len(rows) == 0 {
+		return nil, ErrNoTasks
+	}
+
+	gen := NewGenerator()
+	defer gen.file.Close()
+
+	if err := gen.file.SetSheetName("Sheet1", geocodingSheet); err != nil {
+		return nil, fmt.Errorf("failed to rename default sheet: %w", err)
+	}
+
+	data := [][]interface{}{{"Task ID", "Address", "Attempts", "Error"}}
+	for _, row := range rows {
+		data = append(data, []interface{}{row.TaskID, row.Address, row.Attempts, row.Error})
+	}
+	if err := gen.setTable(geocodingSheet, 1, data); err != nil {
+		return nil, fmt.Errorf("failed to fill geocoding issues: %w", err)
+	}
+
+	widths := map[string]float64{"A": 12, "B": 60, "C": 10, "D": 60} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err := gen.file.SetColWidth(geocodingSheet, col, col, width); err != nil {
+			return nil, fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	buffer, err := gen.file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write data from saved file: %w", err)
+	}
+
+	return buffer, nil
+}
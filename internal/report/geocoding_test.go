@@ -0,0 +1,45 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateGeocodingIssuesReport(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		rows := []report.GeocodingIssueRow{
+			{TaskID: 1, Address: "Main st. 1", Attempts: 3, Error: "ZERO_RESULTS"},
+			{TaskID: 2, Address: "Unknown", Attempts: 1},
+		}
+
+		buffer, err := report.GenerateGeocodingIssuesReport(rows)
+		require.NoError(t, err)
+
+		f, err := excelize.OpenReader(buffer)
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, []string{"Geocoding issues"}, f.GetSheetList())
+
+		got, err := f.GetRows("Geocoding issues")
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"Task ID", "Address", "Attempts", "Error"},
+			{"1", "Main st. 1", "3", "ZERO_RESULTS"},
+			{"2", "Unknown", "1"},
+		}, got)
+	})
+
+	t.Run("error - no issues", func(t *testing.T) {
+		t.Parallel()
+		_, err := report.GenerateGeocodingIssuesReport(nil)
+		require.ErrorIs(t, err, report.ErrNoTasks)
+	})
+}
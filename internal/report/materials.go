@@ -0,0 +1,91 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// materialsSheet is the name of the sheet with the materials used on the report tasks.
+const materialsSheet = "Materials"
+
+// MaterialUsage holds the quantity of a material used on a task.
+type MaterialUsage struct {
+	Name     string  `json:"name"`     // Name of the material
+	Unit     string  `json:"unit"`     // Unit of the quantity
+	Quantity float64 `json:"quantity"` // Quantity used on the task
+}
+
+// MaterialSummary holds the quantity of a material used on the report tasks.
+type MaterialSummary struct {
+	Name     string  // Name of the material
+	Unit     string  // Unit of the quantity
+	Quantity float64 // Quantity used on all tasks
+	Tasks    int     // Tasks the material was used on
+}
+
+// SummarizeMaterials returns the total quantity per material, ordered by name. A task with
+// several customers has several rows, so the materials of a task are counted once.
+func SummarizeMaterials(rows []ExcelRow) []MaterialSummary {
+	seen := make(map[int]bool)
+	byMaterial := make(map[[2]string]*MaterialSummary)
+	for _, row := range rows {
+		if seen[row.ID] {
+			continue
+		}
+		seen[row.ID] = true
+		for _, material := range row.Materials {
+			key := [2]string{material.Name, material.Unit}
+			summary, ok := byMaterial[key]
+			if !ok {
+				summary = &MaterialSummary{Name: material.Name, Unit: material.Unit}
+				byMaterial[key] = summary
+			}
+			summary.Quantity += material.Quantity
+			summary.Tasks++
+		}
+	}
+
+	summaries := make([]MaterialSummary, 0, len(byMaterial))
+	for _, summary := range byMaterial {
+		summary.Quantity = math.Round(summary.Quantity*100) / 100 //nolint:mnd // hundredths of a unit
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Name != summaries[j].Name {
+			return summaries[i].Name < summaries[j].Name
+		}
+		return summaries[i].Unit < summaries[j].Unit
+	})
+	return summaries
+}
+
+// addMaterialsSheet adds the sheet with the materials used on the report tasks, so the stock
+// can be reconciled with what was installed. The sheet is left out when no materials were logged.
+func (g *Generator) addMaterialsSheet(rows []ExcelRow) error {
+	summaries := SummarizeMaterials(rows)
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	if _, err := g.file.NewSheet(materialsSheet); err != nil {
+		return fmt.Errorf("failed to generate new sheet '%s': %w", materialsSheet, err)
+	}
+
+	data := [][]interface{}{{"Material", "Unit", "Quantity", "Tasks"}}
+	for _, summary := range summaries {
+		data = append(data, []interface{}{summary.Name, summary.Unit, summary.Quantity, summary.Tasks})
+	}
+	if err := g.setTable(materialsSheet, 1, data); err != nil {
+		return fmt.Errorf("failed to fill materials: %w", err)
+	}
+
+	widths := map[string]float64{"A": 30, "B": 8, "C": 10, "D": 8} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err := g.file.SetColWidth(materialsSheet, col, col, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateExcelReportMaterials(t *testing.T) {
+	t.Parallel()
+	cable := report.MaterialUsage{Name: "UTP cable", Unit: "m", Quantity: 20.5}
+	connectors := report.MaterialUsage{Name: "RJ45 connector", Unit: "pcs", Quantity: 2}
+	rows := []report.ExcelRow{
+		{ID: 1, Type: "Repair", Materials: []report.MaterialUsage{cable, connectors}},
+		{ID: 1, Type: "Repair", Materials: []report.MaterialUsage{cable, connectors}}, // second customer
+		{ID: 2, Type: "Connection", Materials: []report.MaterialUsage{{Name: "UTP cable", Unit: "m", Quantity: 4}}},
+		{ID: 3, Type: "Connection"},
+	}
+
+	buffer, err := report.GenerateExcelReport(rows, nil)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	materials, err := f.GetRows("Materials")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Material", "Unit", "Quantity", "Tasks"},
+		{"RJ45 connector", "pcs", "2", "1"},
+		{"UTP cable", "m", "24.5", "2"},
+	}, materials)
+}
+
+func TestGenerateExcelReportWithoutMaterials(t *testing.T) {
+	t.Parallel()
+
+	buffer, err := report.GenerateExcelReport([]report.ExcelRow{{ID: 1, Type: "Repair"}}, nil)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.NotContains(t, f.GetSheetList(), "Materials")
+}
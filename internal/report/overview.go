@@ -0,0 +1,160 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// overviewSheet is the name of the sheet with the visual summary of the report.
+const overviewSheet = "Overview"
+
+// Chart placement on the overview sheet, to the right of the data tables.
+const (
+	chartWidth  = 640
+	chartHeight = 320
+)
+
+// countTasksByType returns the number of distinct tasks of each type. A task with several
+// customers has several rows, so tasks are counted by ID.
+func countTasksByType(rows []ExcelRow) map[string]int {
+	seen := make(map[string]map[int]struct{})
+	for _, row := range rows {
+		if seen[row.Type] == nil {
+			seen[row.Type] = make(map[int]struct{})
+		}
+		seen[row.Type][row.ID] = struct{}{}
+	}
+
+	counts := make(map[string]int, len(seen))
+	for taskType, ids := range seen {
+		counts[taskType] = len(ids)
+	}
+	return counts
+}
+
+// countTasksByDay returns the number of distinct tasks created on each day between the first
+// and the last task of the report. Days without tasks are included with zero, so the line
+// chart has no gaps.
+func countTasksByDay(rows []ExcelRow) ([]time.Time, []int) {
+	seen := make(map[time.Time]map[int]struct{})
+	var first, last time.Time
+	for _, row := range rows {
+		created := row.CreationDate
+		day := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, created.Location())
+		if seen[day] == nil {
+			seen[day] = make(map[int]struct{})
+		}
+		seen[day][row.ID] = struct{}{}
+
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+
+	var days []time.Time
+	var counts []int
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+		counts = append(counts, len(seen[day]))
+	}
+	return days, counts
+}
+
+// addOverviewSheet adds the sheet with a bar chart of tasks per type and a line chart
+// of tasks per day. The charts are built from the data tables placed on the same sheet.
+func (g *Generator) addOverviewSheet(rows []ExcelRow) error {
+	if _, err := g.file.NewSheet(overviewSheet); err != nil {
+		return fmt.Errorf("failed to generate new sheet '%s': %w", overviewSheet, err)
+	}
+
+	byType := countTasksByType(rows)
+	types := make([]string, 0, len(byType))
+	for taskType := range byType {
+		types = append(types, taskType)
+	}
+	sort.Strings(types)
+
+	typeData := [][]interface{}{{"Task type", "Tasks"}}
+	for _, taskType := range types {
+		typeData = append(typeData, []interface{}{taskType, byType[taskType]})
+	}
+	if err := g.setTable(overviewSheet, 1, typeData); err != nil {
+		return fmt.Errorf("failed to fill tasks per type: %w", err)
+	}
+
+	days, dayCounts := countTasksByDay(rows)
+	dayData := [][]interface{}{{"Date", "Tasks"}}
+	for i, day := range days {
+		dayData = append(dayData, []interface{}{day.Format("02.01.2006"), dayCounts[i]})
+	}
+	const dayColumn = 4 // column D, leaving an empty column after the type table
+	if err := g.setTable(overviewSheet, dayColumn, dayData); err != nil {
+		return fmt.Errorf("failed to fill tasks per day: %w", err)
+	}
+
+	widths := map[string]float64{"A": 30, "B": 10, "D": 14, "E": 10} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err := g.file.SetColWidth(overviewSheet, col, col, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	if err := g.file.AddChart(overviewSheet, "G2", &excelize.Chart{
+		Type: excelize.Col,
+		Series: []excelize.ChartSeries{{
+			Name:       "Tasks",
+			Categories: fmt.Sprintf("'%s'!$A$2:$A$%d", overviewSheet, len(typeData)),
+			Values:     fmt.Sprintf("'%s'!$B$2:$B$%d", overviewSheet, len(typeData)),
+		}},
+		Title:     []excelize.RichTextRun{{Text: "Tasks per type"}},
+		Legend:    excelize.ChartLegend{Position: "none"},
+		Dimension: excelize.ChartDimension{Width: chartWidth, Height: chartHeight},
+	}); err != nil {
+		return fmt.Errorf("failed to add tasks per type chart: %w", err)
+	}
+
+	if err := g.file.AddChart(overviewSheet, "G20", &excelize.Chart{
+		Type: excelize.Line,
+		Series: []excelize.ChartSeries{{
+			Name:       "Tasks",
+			Categories: fmt.Sprintf("'%s'!$D$2:$D$%d", overviewSheet, len(dayData)),
+			Values:     fmt.Sprintf("'%s'!$E$2:$E$%d", overviewSheet, len(dayData)),
+		}},
+		Title:     []excelize.RichTextRun{{Text: "Tasks per day"}},
+		Legend:    excelize.ChartLegend{Position: "none"},
+		Dimension: excelize.ChartDimension{Width: chartWidth, Height: chartHeight},
+	}); err != nil {
+		return fmt.Errorf("failed to add tasks per day chart: %w", err)
+	}
+
+	return nil
+}
+
+// setTable writes the rows starting at the first row of the given column and makes the first row bold.
+func (g *Generator) setTable(sheetName string, column int, data [][]interface{}) error {
+	headerStyle, err := g.file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create new style: %w", err)
+	}
+
+	for i, row := range data {
+		cell, _ := excelize.CoordinatesToCellName(column, i+1)
+		if err = g.file.SetSheetRow(sheetName, cell, &row); err != nil {
+			return fmt.Errorf("failed to set sheet row: %w", err)
+		}
+	}
+
+	start, _ := excelize.CoordinatesToCellName(column, 1)
+	end, _ := excelize.CoordinatesToCellName(column+len(data[0])-1, 1)
+	if err = g.file.SetCellStyle(sheetName, start, end, headerStyle); err != nil {
+		return fmt.Errorf("failed to set cell style for headers: %w", err)
+	}
+
+	return nil
+}
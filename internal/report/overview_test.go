@@ -0,0 +1,39 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateExcelReportOverview(t *testing.T) {
+	t.Parallel()
+	day := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	rows := []report.ExcelRow{
+		{ID: 1, Type: "Repair", CreationDate: day},
+		{ID: 1, Type: "Repair", CreationDate: day}, // second customer of the same task
+		{ID: 2, Type: "Connection", CreationDate: day},
+		{ID: 3, Type: "Repair", CreationDate: day.AddDate(0, 0, 2)},
+	}
+
+	buffer, err := report.GenerateExcelReport(rows, nil)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	assert.Equal(t, "Overview", f.GetSheetList()[0])
+	assert.Equal(t, 0, f.GetActiveSheetIndex())
+
+	byType, err := f.GetCols("Overview")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Task type", "Connection", "Repair"}, byType[0])
+	assert.Equal(t, []string{"Tasks", "1", "2"}, byType[1])
+	assert.Equal(t, []string{"Date", "01.03.2025", "02.03.2025", "03.03.2025"}, byType[3])
+	assert.Equal(t, []string{"Tasks", "2", "0", "1"}, byType[4])
+}
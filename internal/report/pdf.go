@@ -0,0 +1,168 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const (
+	pdfFontFamily  = "Go"
+	pdfMargin      = 10.0
+	pdfFontSize    = 8.0
+	pdfTitleSize   = 12.0
+	pdfLineHeight  = 4.0
+	pdfHeaderRowH  = 7.0
+	pdfSectionGapH = 4.0
+)
+
+// pdfColumn describes a single column of the PDF task table.
+type pdfColumn struct {
+	title string
+	width float64
+	value func(row ExcelRow) string
+}
+
+// pdfColumns mirrors the Excel headers, sized to fit an A4 landscape page (277 mm of usable width).
+var pdfColumns = []pdfColumn{
+	{title: "Task ID", width: 18, value: func(r ExcelRow) string { return strconv.Itoa(r.ID) }},
+	{title: "Creation Date", width: 24, value: func(r ExcelRow) string { return r.CreationDate.Format("02.01.2006") }},
+	{title: "Description", width: 60, value: func(r ExcelRow) string { return r.Description }},
+	{title: "Address", width: 45, value: func(r ExcelRow) string { return r.Address }},
+	{title: "Customer", width: 40, value: func(r ExcelRow) string { return r.Customer }},
+	{title: "Contract", width: 25, value: func(r ExcelRow) string { return r.Contract }},
+	{title: "Tariff", width: 35, value: func(r ExcelRow) string { return r.Tariff }},
+	{title: "Executors", width: 30, value: func(r ExcelRow) string { return strings.Join(r.Executors, ", ") }},
+}
+
+// pdfGenerator holds the state for the PDF report generation process.
+type pdfGenerator struct {
+	pdf *fpdf.Fpdf
+}
+
+// GeneratePDFReport generates a PDF version of the completed tasks report. Rows are grouped
+// into one section per task type, and long descriptions and addresses are wrapped inside
+// their cells, so the document stays readable on a phone screen.
+//
+// The embedded Go fonts are used because the core PDF fonts cannot render Cyrillic text.
+// It returns ErrNoTasks if no rows are provided.
+func GeneratePDFReport(rows []ExcelRow) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	if err := WritePDFReport(buffer, rows); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// WritePDFReport writes the PDF report described in GeneratePDFReport to w.
+func WritePDFReport(w io.Writer, rows []ExcelRow) error {
+	if len(rows) == 0 {
+		return ErrNoTasks
+	}
+
+	rowsByType := make(map[string][]ExcelRow)
+	types := make([]string, 0)
+	for _, row := range rows {
+		if _, ok := rowsByType[row.Type]; !ok {
+			types = append(types, row.Type)
+		}
+		rowsByType[row.Type] = append(rowsByType[row.Type], row)
+	}
+	sort.Strings(types)
+
+	gen := newPDFGenerator()
+	for _, taskType := range types {
+		gen.addSection(taskType, rowsByType[taskType])
+	}
+
+	if err := gen.pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to render pdf document: %w", err)
+	}
+
+	return nil
+}
+
+// newPDFGenerator creates an A4 landscape document with UTF-8 fonts registered.
+func newPDFGenerator() *pdfGenerator {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", gobold.TTF)
+	pdf.AddPage()
+
+	return &pdfGenerator{pdf: pdf}
+}
+
+// addSection writes the task type title followed by a table with all its rows.
+func (g *pdfGenerator) addSection(taskType string, rows []ExcelRow) {
+	if g.remainingHeight() < pdfTitleSize+pdfHeaderRowH+pdfLineHeight {
+		g.pdf.AddPage()
+	}
+
+	g.pdf.SetFont(pdfFontFamily, "B", pdfTitleSize)
+	g.pdf.SetTextColor(0, 0, 0)
+	g.pdf.CellFormat(0, pdfTitleSize, fmt.Sprintf("%s (%d)", taskType, len(rows)), "", 1, "L", false, 0, "")
+
+	g.addHeader()
+	for _, row := range rows {
+		g.addRow(row)
+	}
+	g.pdf.Ln(pdfSectionGapH)
+}
+
+// addHeader writes the table header row using the same colors as the Excel report.
+func (g *pdfGenerator) addHeader() {
+	g.pdf.SetFont(pdfFontFamily, "B", pdfFontSize)
+	g.pdf.SetFillColor(0x4F, 0x81, 0xBD)
+	g.pdf.SetTextColor(0xFF, 0xFF, 0xFF)
+	for _, col := range pdfColumns {
+		g.pdf.CellFormat(col.width, pdfHeaderRowH, col.title, "1", 0, "C", true, 0, "")
+	}
+	g.pdf.Ln(-1)
+	g.pdf.SetFont(pdfFontFamily, "", pdfFontSize)
+	g.pdf.SetTextColor(0, 0, 0)
+}
+
+// addRow writes one task row, growing the row height to fit the longest wrapped cell
+// and starting a new page (with a repeated header) when the row does not fit.
+func (g *pdfGenerator) addRow(row ExcelRow) {
+	cells := make([][]string, len(pdfColumns))
+	lines := 1
+	for i, col := range pdfColumns {
+		cells[i] = g.pdf.SplitText(col.value(row), col.width-1)
+		lines = max(lines, len(cells[i]))
+	}
+	height := float64(lines) * pdfLineHeight
+
+	if g.remainingHeight() < height {
+		g.pdf.AddPage()
+		g.addHeader()
+	}
+
+	left, _, _, _ := g.pdf.GetMargins()
+	x, y := left, g.pdf.GetY()
+	for i, col := range pdfColumns {
+		g.pdf.Rect(x, y, col.width, height, "D")
+		for lineIdx, line := range cells[i] {
+			g.pdf.SetXY(x, y+float64(lineIdx)*pdfLineHeight)
+			g.pdf.CellFormat(col.width, pdfLineHeight, line, "", 0, "L", false, 0, "")
+		}
+		x += col.width
+	}
+	g.pdf.SetXY(left, y+height)
+}
+
+// remainingHeight returns the vertical space left on the current page.
+func (g *pdfGenerator) remainingHeight() float64 {
+	_, pageHeight := g.pdf.GetPageSize()
+	return pageHeight - pdfMargin - g.pdf.GetY()
+}
@@ -0,0 +1,61 @@
+package report_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePDFReport(t *testing.T) {
+	t.Run("successful report generation", func(t *testing.T) {
+		testRows := []report.ExcelRow{
+			{ID: 1, Type: "Type 1", Description: "Task 1", CreationDate: time.Now()},
+			{ID: 2, Type: "Підключення", Description: "Опис задачі", Address: "вул. Шевченка, 1"},
+			{ID: 3, Type: "Type 1", Description: strings.Repeat("long description ", 40)},
+		}
+
+		buffer, err := report.GeneratePDFReport(testRows)
+
+		require.NoError(t, err)
+		require.NotNil(t, buffer)
+		assert.True(t, bytes.HasPrefix(buffer.Bytes(), []byte("%PDF-")))
+	})
+
+	t.Run("many rows span several pages", func(t *testing.T) {
+		testRows := make([]report.ExcelRow, 0, 200)
+		for i := range 200 {
+			testRows = append(testRows, report.ExcelRow{ID: i, Type: "Type 1", Description: "Task"})
+		}
+
+		buffer, err := report.GeneratePDFReport(testRows)
+
+		require.NoError(t, err)
+		assert.Greater(t, bytes.Count(buffer.Bytes(), []byte("/Type /Page\n")), 1)
+	})
+
+	t.Run("no tasks found", func(t *testing.T) {
+		buffer, err := report.GeneratePDFReport(nil)
+
+		require.ErrorIs(t, err, report.ErrNoTasks)
+		assert.Nil(t, buffer)
+	})
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, report.FormatPDF, report.ParseFormat("pdf"))
+	assert.Equal(t, report.FormatXLSX, report.ParseFormat("xlsx"))
+	assert.Equal(t, report.FormatCSV, report.ParseFormat("csv"))
+	assert.Equal(t, report.FormatXLSX, report.ParseFormat("unknown"))
+	assert.Equal(t, "application/pdf", report.FormatPDF.MIMEType())
+	assert.Equal(t, "xlsx", report.FormatXLSX.Extension())
+	assert.Equal(t, "text/csv", report.FormatCSV.MIMEType())
+
+	buffer, err := report.Generate(report.Format("doc"), []report.ExcelRow{{ID: 1}}, nil)
+	require.Error(t, err)
+	assert.Nil(t, buffer)
+}
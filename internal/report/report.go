@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -20,14 +21,17 @@ type Generator struct {
 
 // ExcelRow holds the structured row for excel file.
 type ExcelRow struct {
-	ID           int       `json:"id"`            // Unique identifier for the task
-	Type         string    `json:"type"`          // Type of the task
-	CreationDate time.Time `json:"creation_date"` // Date when the task was created
-	Description  string    `json:"description"`   // Description of the task
-	Address      string    `json:"address"`       // Address related to the task
-	Customer     string    `json:"customer"`      // Name of the customer associated with the task
-	Contract     string    `json:"contract"`      // Contract ID of the customer
-	Tariff       string    `json:"tariff"`        // Tariff plan of the customer
+	ID           int             `json:"id"`            // Unique identifier for the task
+	Type         string          `json:"type"`          // Type of the task
+	CreationDate time.Time       `json:"creation_date"` // Date when the task was created
+	ClosingDate  time.Time       `json:"closing_date"`  // Date when the task was closed
+	Description  string          `json:"description"`   // Description of the task
+	Address      string          `json:"address"`       // Address related to the task
+	Customer     string          `json:"customer"`      // Name of the customer associated with the task
+	Contract     string          `json:"contract"`      // Contract ID of the customer
+	Tariff       string          `json:"tariff"`        // Tariff plan of the customer
+	Executors    []string        `json:"executors"`     // Employees who worked on the task
+	Materials    []MaterialUsage `json:"materials"`     // Materials logged as used on the task
 }
 
 // NewGenerator creates a n ew report generator.
@@ -39,25 +43,37 @@ func NewGenerator() *Generator {
 
 // GenerateExcelReport generates an Excel report for completed tasks executed by a specific user
 // within a given date range. It retrieves tasks from the repository, organizes them by type,
-// and formats them into an Excel file with appropriate headers and styles. If no tasks are found,
+// and formats them into an Excel file with appropriate headers and styles. The first "Overview"
+// sheet charts tasks per type and per day. If no tasks are found,
 // it returns nil. The function returns a bytes.Buffer containing the Excel file or an error if
 // any operation fails.
 //
 // Parameters:
-// - ctx: The context for managing cancellation and deadlines.
-// - repo: The repository interface for accessing task data.
-// - telegramID: The ID of the user whose tasks are to be reported.
-// - from: The start date for filtering tasks.
-// - to: The end date for filtering tasks.
+// - rows: The report rows of completed tasks.
+// - comparison: The task counts of the previous period for the "Comparison" sheet,
+// or nil to leave the sheet out.
 //
 // Returns:
 // - A pointer to a bytes.Buffer containing the Excel report, or nil if no tasks are found.
 // - An error if any operation fails during the report generation.
-func GenerateExcelReport(rows []ExcelRow) (*bytes.Buffer, error) {
+func GenerateExcelReport(rows []ExcelRow, comparison *Comparison) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	if err := WriteExcelReport(buffer, rows, comparison); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// WriteExcelReport writes the Excel report described in GenerateExcelReport to w.
+// The sheets with the task rows are written with the excelize stream writer, which keeps
+// the rows as XML on disk instead of building the sheet model in memory, so long periods
+// do not spike the memory usage.
+func WriteExcelReport(w io.Writer, rows []ExcelRow, comparison *Comparison) error {
 	var err error
 
 	if len(rows) == 0 {
-		return nil, ErrNoTasks
+		return ErrNoTasks
 	}
 
 	rowsByType := make(map[string][]ExcelRow)
@@ -68,8 +84,26 @@ func GenerateExcelReport(rows []ExcelRow) (*bytes.Buffer, error) {
 	gen := NewGenerator()
 	defer gen.file.Close()
 
+	if err = gen.addOverviewSheet(rows); err != nil {
+		return fmt.Errorf("failed to add overview sheet: %w", err)
+	}
+
 	if err = gen.addSheets(rowsByType); err != nil {
-		return nil, fmt.Errorf("failed to add sheets: %w", err)
+		return fmt.Errorf("failed to add sheets: %w", err)
+	}
+
+	if err = gen.addExecutorsSheet(rows); err != nil {
+		return fmt.Errorf("failed to add executors sheet: %w", err)
+	}
+
+	if err = gen.addMaterialsSheet(rows); err != nil {
+		return fmt.Errorf("failed to add materials sheet: %w", err)
+	}
+
+	if comparison != nil {
+		if err = gen.addComparisonSheet(rows, comparison); err != nil {
+			return fmt.Errorf("failed to add comparison sheet: %w", err)
+		}
 	}
 
 	// setup first sheet as active
@@ -78,63 +112,31 @@ func GenerateExcelReport(rows []ExcelRow) (*bytes.Buffer, error) {
 	// delete default sheet
 	if sheetIndex, _ := gen.file.GetSheetIndex("Sheet1"); sheetIndex != -1 {
 		if err = gen.file.DeleteSheet("Sheet1"); err != nil {
-			return nil, fmt.Errorf("failed to delete default sheet 'Sheet1': %w", err)
+			return fmt.Errorf("failed to delete default sheet 'Sheet1': %w", err)
 		}
 	}
 
-	buffer, err := gen.file.WriteToBuffer()
-	if err != nil {
-		return nil, fmt.Errorf("failed to write data from saved file: %w", err)
+	if err = gen.file.Write(w); err != nil {
+		return fmt.Errorf("failed to write excel file: %w", err)
 	}
 
-	return buffer, nil
+	return nil
 }
 
+// excelHeaders are the column titles of the sheets with task rows.
+var excelHeaders = []string{
+	"Task ID", "Creation Date", "Description", "Address", "Customer", "Contract", "Tariff", "Executors",
+}
+
+// excelColumnWidths are the widths of the columns of the sheets with task rows, in excelHeaders order.
+var excelColumnWidths = []float64{15, 18, 50, 40, 30, 14, 25, 30} //nolint:mnd // const values for row width
+
 // addSheets adds new sheets to the generator's file based on the provided
 // tasksByType map. Each key in the map represents a task type, and the
 // corresponding value is a slice of TaskDetails. The function creates a
-// new sheet for each task type, sets up the sheet, and populates it with
-// the task details. It returns an error if any operation fails during
-// the process.
+// new sheet for each task type and streams the task details into it.
+// It returns an error if any operation fails during the process.
 func (g *Generator) addSheets(rowsByType map[string][]ExcelRow) error {
-	var err error
-	headerIndex := 2
-
-	for taskType, tasksInType := range rowsByType {
-		sheetName := truncateSheetName(taskType)
-
-		if _, err = g.file.NewSheet(sheetName); err != nil {
-			return fmt.Errorf("failed to generate new sheet '%s': %w", sheetName, err)
-		}
-
-		if err = g.setupSheet(sheetName, len(tasksInType)); err != nil {
-			return fmt.Errorf("failed to setup sheet '%s': %w", sheetName, err)
-		}
-
-		// Fill data
-		for i, task := range tasksInType {
-			if err = g.addRow(sheetName, i+headerIndex, task); err != nil { // i+2, becase the first row - header
-				return fmt.Errorf("failed to add row '%d': %w", i+headerIndex, err)
-			}
-		}
-	}
-	return nil
-}
-
-// setupSheet initializes the specified sheet with headers, styles, and column widths.
-// It creates a header style, sets the row height for the headers, and populates the headers
-// in the first row. It also configures the width for each column and adds a table to the sheet.
-//
-// Parameters:
-// - sheetName: The name of the sheet to set up.
-// - taskCount: The number of tasks to determine the range of the table.
-//
-// Returns:
-// - error: An error if any operation fails, otherwise returns nil.
-func (g *Generator) setupSheet(sheetName string, rowCount int) error {
-	var err error
-
-	// Style creating
 	headerStyle, err := g.file.NewStyle(&excelize.Style{
 		Font:      &excelize.Font{Bold: true, Color: "FFFFFF"},
 		Fill:      excelize.Fill{Type: "pattern", Color: []string{"#4F81BD"}, Pattern: 1},
@@ -150,46 +152,70 @@ func (g *Generator) setupSheet(sheetName string, rowCount int) error {
 		return fmt.Errorf("failed to create new style: %w", err)
 	}
 
-	// Headers creating
-	rowHeighnt := 20
-	headers := []string{"Task ID", "Creation Date", "Description", "Address", "Customer", "Contract", "Tariff"}
-	if err = g.file.SetRowHeight(sheetName, 1, float64(rowHeighnt)); err != nil {
-		return fmt.Errorf("failed to set row height for headers: %w", err)
+	for taskType, tasksInType := range rowsByType {
+		sheetName := truncateSheetName(taskType)
+
+		if _, err = g.file.NewSheet(sheetName); err != nil {
+			return fmt.Errorf("failed to generate new sheet '%s': %w", sheetName, err)
+		}
+
+		if err = g.streamSheet(sheetName, headerStyle, tasksInType); err != nil {
+			return fmt.Errorf("failed to write sheet '%s': %w", sheetName, err)
+		}
 	}
-	if err = g.file.SetSheetRow(sheetName, "A1", &headers); err != nil {
-		return fmt.Errorf("failed to set sheet row for headers: %w", err)
+	return nil
+}
+
+// streamSheet writes the header and the rows into the sheet with a stream writer and turns
+// them into a table. Column widths have to be set before the first row is written.
+func (g *Generator) streamSheet(sheetName string, headerStyle int, rows []ExcelRow) error {
+	stream, err := g.file.NewStreamWriter(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create stream writer: %w", err)
 	}
-	if err = g.file.SetCellStyle(sheetName, "A1", "G1", headerStyle); err != nil {
-		return fmt.Errorf("failed to set cell style for headers: %w", err)
+
+	for i, width := range excelColumnWidths {
+		if err = stream.SetColWidth(i+1, i+1, width); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
 	}
 
-	// Setup width column
-	widths := map[string]float64{
-		"A": 15, "B": 18, "C": 50, "D": 40, "E": 30, "F": 14, "G": 25, //nolint:mnd // const values for row width
+	header := make([]interface{}, 0, len(excelHeaders))
+	for _, title := range excelHeaders {
+		header = append(header, excelize.Cell{StyleID: headerStyle, Value: title})
 	}
-	for col, width := range widths {
-		if err = g.file.SetColWidth(sheetName, col, col, width); err != nil {
-			return fmt.Errorf("failed to set column width: %w", err)
+	const headerHeight = 20
+	if err = stream.SetRow("A1", header, excelize.RowOpts{Height: headerHeight}); err != nil {
+		return fmt.Errorf("failed to set sheet row for headers: %w", err)
+	}
+
+	headerIndex := 2 // the first row is the header
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+headerIndex)
+		if err = stream.SetRow(cell, excelRowValues(row)); err != nil {
+			return fmt.Errorf("failed to add row '%d': %w", i+headerIndex, err)
 		}
 	}
 
-	// Add table
-	if err = g.file.AddTable(sheetName, &excelize.Table{
-		Range:     fmt.Sprintf("A1:G%d", rowCount+1),
+	lastCell, _ := excelize.CoordinatesToCellName(len(excelHeaders), len(rows)+1)
+	if err = stream.AddTable(&excelize.Table{
+		Range:     "A1:" + lastCell,
 		Name:      "table_" + strings.ReplaceAll(sheetName, " ", ""),
 		StyleName: "TableStyleMedium9",
 	}); err != nil {
 		return fmt.Errorf("failed to add table: %w", err)
 	}
 
+	if err = stream.Flush(); err != nil {
+		return fmt.Errorf("failed to flush stream writer: %w", err)
+	}
+
 	return nil
 }
 
-// addRow adds a new row to the specified sheet with the details of the given task.
-// It takes the sheet name, the row number where the data should be added,
-// and the task details as parameters. If the operation fails, it returns an error.
-func (g *Generator) addRow(sheetName string, rowNum int, row ExcelRow) error {
-	rowData := []interface{}{
+// excelRowValues returns the cell values of the task row, in excelHeaders order.
+func excelRowValues(row ExcelRow) []interface{} {
+	return []interface{}{
 		row.ID,
 		row.CreationDate.Format("02.01.2006"),
 		row.Description,
@@ -197,14 +223,8 @@ func (g *Generator) addRow(sheetName string, rowNum int, row ExcelRow) error {
 		row.Customer,
 		row.Contract,
 		row.Tariff,
+		strings.Join(row.Executors, ", "),
 	}
-	cell, _ := excelize.CoordinatesToCellName(1, rowNum)
-
-	if err := g.file.SetSheetRow(sheetName, cell, &rowData); err != nil {
-		return fmt.Errorf("failed to set sheet row: %w", err)
-	}
-
-	return nil
 }
 
 // truncateSheetName truncates the given sheet name to a maximum of 31 runes.
@@ -1,6 +1,9 @@
 package report_test
 
 import (
+	"bytes"
+	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
@@ -18,7 +21,7 @@ func TestGenerateExcelReport(t *testing.T) {
 	}
 
 	t.Run("successful report generation", func(t *testing.T) {
-		buffer, err := report.GenerateExcelReport(testRows)
+		buffer, err := report.GenerateExcelReport(testRows, nil)
 
 		require.NoError(t, err)
 		assert.NotNil(t, buffer)
@@ -28,7 +31,7 @@ func TestGenerateExcelReport(t *testing.T) {
 		defer f.Close()
 
 		sheetList := f.GetSheetList()
-		assert.ElementsMatch(t, []string{"Type 1", "Type 2"}, sheetList)
+		assert.ElementsMatch(t, []string{"Overview", "Type 1", "Type 2"}, sheetList)
 
 		headerVal, err := f.GetCellValue("Type 1", "A1")
 		require.NoError(t, err)
@@ -44,10 +47,33 @@ func TestGenerateExcelReport(t *testing.T) {
 	})
 
 	t.Run("no tasks found", func(t *testing.T) {
-		buffer, err := report.GenerateExcelReport([]report.ExcelRow{})
+		buffer, err := report.GenerateExcelReport([]report.ExcelRow{}, nil)
 
 		require.Error(t, err)
 		assert.Nil(t, buffer)
 		require.ErrorIs(t, err, report.ErrNoTasks)
 	})
+	t.Run("large report is written row by row", func(t *testing.T) {
+		const count = 5000
+		rows := make([]report.ExcelRow, 0, count)
+		for i := range count {
+			rows = append(rows, report.ExcelRow{ID: i + 1, Type: "Repair", Executors: []string{"Ann"}})
+		}
+
+		var buffer bytes.Buffer
+		require.NoError(t, report.WriteExcelReport(&buffer, rows, nil))
+
+		f, err := excelize.OpenReader(&buffer)
+		require.NoError(t, err)
+		defer f.Close()
+
+		lastID, err := f.GetCellValue("Repair", fmt.Sprintf("A%d", count+1))
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(count), lastID)
+
+		tables, err := f.GetTables("Repair")
+		require.NoError(t, err)
+		require.Len(t, tables, 1)
+		assert.Equal(t, fmt.Sprintf("A1:H%d", count+1), tables[0].Range)
+	})
 }
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// statisticsSheet is the name of the only sheet of the personal statistics workbook.
+const statisticsSheet = "Statistics"
+
+// GenerateStatisticsReport generates a small Excel workbook with the number of completed tasks
+// per type and a daily breakdown of the period [from, to]. Tasks are counted on the day they
+// were closed, and days without tasks are included with zero.
+//
+// Returns ErrNoTasks if rows is empty.
+func GenerateStatisticsReport(rows []ExcelRow, from, to time.Time) (*bytes.Buffer, error) {
+	if len(rows) == 0 {
+		return nil, ErrNoTasks
+	}
+
+	gen := NewGenerator()
+	defer gen.file.Close()
+
+	if err := gen.file.SetSheetName("Sheet1", statisticsSheet); err != nil {
+		return nil, fmt.Errorf("failed to rename default sheet: %w", err)
+	}
+
+	byType := countTasksByType(rows)
+	types := make([]string, 0, len(byType))
+	total := 0
+	for taskType, count := range byType {
+		types = append(types, taskType)
+		total += count
+	}
+	sort.Strings(types)
+
+	typeData := [][]interface{}{{"Task type", "Tasks"}}
+	for _, taskType := range types {
+		typeData = append(typeData, []interface{}{taskType, byType[taskType]})
+	}
+	typeData = append(typeData, []interface{}{totalType, total})
+	if err := gen.setTable(statisticsSheet, 1, typeData); err != nil {
+		return nil, fmt.Errorf("failed to fill tasks per type: %w", err)
+	}
+
+	days, dayCounts := countClosedTasksByDay(rows, from, to)
+	dayData := [][]interface{}{{"Date", "Tasks"}}
+	for i, day := range days {
+		dayData = append(dayData, []interface{}{day.Format("02.01.2006"), dayCounts[i]})
+	}
+	const dayColumn = 4 // column D, leaving an empty column after the type table
+	if err := gen.setTable(statisticsSheet, dayColumn, dayData); err != nil {
+		return nil, fmt.Errorf("failed to fill tasks per day: %w", err)
+	}
+
+	widths := map[string]float64{"A": 30, "B": 10, "D": 14, "E": 10} //nolint:mnd // const values for row width
+	for col, width := range widths {
+		if err := gen.file.SetColWidth(statisticsSheet, col, col, width); err != nil {
+			return nil, fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	buffer, err := gen.file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write data from saved file: %w", err)
+	}
+
+	return buffer, nil
+}
+
+// countClosedTasksByDay returns the number of distinct tasks closed on each day of [from, to].
+func countClosedTasksByDay(rows []ExcelRow, from, to time.Time) ([]time.Time, []int) {
+	truncate := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+
+	seen := make(map[time.Time]map[int]struct{})
+	for _, row := range rows {
+		day := truncate(row.ClosingDate.In(from.Location()))
+		if seen[day] == nil {
+			seen[day] = make(map[int]struct{})
+		}
+		seen[day][row.ID] = struct{}{}
+	}
+
+	var days []time.Time
+	var counts []int
+	for day := truncate(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+		counts = append(counts, len(seen[day]))
+	}
+	return days, counts
+}
@@ -0,0 +1,49 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateStatisticsReport(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 3, 23, 59, 59, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		rows := []report.ExcelRow{
+			{ID: 1, Type: "Repair", ClosingDate: from.Add(10 * time.Hour)},
+			{ID: 1, Type: "Repair", ClosingDate: from.Add(10 * time.Hour)}, // second customer of the same task
+			{ID: 2, Type: "Connection", ClosingDate: from.Add(12 * time.Hour)},
+			{ID: 3, Type: "Repair", ClosingDate: from.AddDate(0, 0, 2).Add(9 * time.Hour)},
+		}
+
+		buffer, err := report.GenerateStatisticsReport(rows, from, to)
+		require.NoError(t, err)
+
+		f, err := excelize.OpenReader(buffer)
+		require.NoError(t, err)
+		defer f.Close()
+
+		assert.Equal(t, []string{"Statistics"}, f.GetSheetList())
+
+		cols, err := f.GetCols("Statistics")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Task type", "Connection", "Repair", "Total"}, cols[0])
+		assert.Equal(t, []string{"Tasks", "1", "2", "3"}, cols[1])
+		assert.Equal(t, []string{"Date", "01.03.2025", "02.03.2025", "03.03.2025"}, cols[3])
+		assert.Equal(t, []string{"Tasks", "2", "0", "1"}, cols[4])
+	})
+
+	t.Run("error - no tasks", func(t *testing.T) {
+		t.Parallel()
+		_, err := report.GenerateStatisticsReport(nil, from, to)
+		require.ErrorIs(t, err, report.ErrNoTasks)
+	})
+}
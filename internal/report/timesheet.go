@@ -0,0 +1,131 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	// timesheetSheet is the name of the sheet with the shifts of the timesheet workbook.
+	timesheetSheet = "Timesheet"
+	// timesheetSummarySheet is the name of the sheet with the hours per employee.
+	timesheetSummarySheet = "Summary"
+)
+
+// TimesheetRow holds a work shift of an employee.
+type TimesheetRow struct {
+	Employee      string    // Short name of the employee
+	Start         time.Time // Start of the shift, in the time zone of the report
+	End           time.Time // End of the shift, zero if the shift is still open
+	StartLocation string    // Where the shift started, empty if not shared
+	EndLocation   string    // Where the shift ended, empty if not shared
+}
+
+// Hours returns the length of the shift in hours rounded to hundredths, 0 while it is open.
+func (r TimesheetRow) Hours() float64 {
+	if r.End.IsZero() || r.End.Before(r.Start) {
+		return 0
+	}
+	return math.Round(r.End.Sub(r.Start).Hours()*100) / 100 //nolint:mnd // hundredths of an hour
+}
+
+// TimesheetSummary holds the shifts and hours an employee worked in the period.
+type TimesheetSummary struct {
+	Employee string  // Short name of the employee
+	Shifts   int     // Number of shifts
+	Hours    float64 // Hours of the closed shifts
+}
+
+// SummarizeTimesheet returns the shifts and hours per employee, ordered by name.
+func SummarizeTimesheet(rows []TimesheetRow) []TimesheetSummary {
+	byEmployee := make(map[string]*TimesheetSummary)
+	for _, row := range rows {
+		summary, ok := byEmployee[row.Employee]
+		if !ok {
+			summary = &TimesheetSummary{Employee: row.Employee}
+			byEmployee[row.Employee] = summary
+		}
+		summary.Shifts++
+		summary.Hours += row.Hours()
+	}
+
+	summaries := make([]TimesheetSummary, 0, len(byEmployee))
+	for _, summary := range byEmployee {
+		summary.Hours = math.Round(summary.Hours*100) / 100 //nolint:mnd // hundredths of an hour
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Employee < summaries[j].Employee })
+
+	return summaries
+}
+
+// GenerateTimesheet generates the Excel timesheet for payroll: one row per shift on the first
+// sheet and the hours per employee on the second one. Open shifts are listed without an end
+// and count no hours.
+//
+// Returns ErrNoTasks if rows is empty.
+func GenerateTimesheet(rows []TimesheetRow) (*bytes.Buffer, error) {
+	if len(rows) == 0 {
+		return nil, ErrNoTasks
+	}
+
+	gen := NewGenerator()
+	defer gen.file.Close()
+
+	if err := gen.file.SetSheetName("Sheet1", timesheetSheet); err != nil {
+		return nil, fmt.Errorf("failed to rename default sheet: %w", err)
+	}
+
+	data := [][]interface{}{{"Employee", "Date", "Start", "End", "Hours", "Start location", "End location"}}
+	for _, row := range rows {
+		end := ""
+		if !row.End.IsZero() {
+			end = row.End.Format("02.01.2006 15:04")
+		}
+		data = append(data, []interface{}{
+			row.Employee,
+			row.Start.Format("02.01.2006"),
+			row.Start.Format("15:04"),
+			end,
+			row.Hours(),
+			row.StartLocation,
+			row.EndLocation,
+		})
+	}
+	if err := gen.setTable(timesheetSheet, 1, data); err != nil {
+		return nil, fmt.Errorf("failed to fill shifts: %w", err)
+	}
+
+	if _, err := gen.file.NewSheet(timesheetSummarySheet); err != nil {
+		return nil, fmt.Errorf("failed to generate new sheet '%s': %w", timesheetSummarySheet, err)
+	}
+	summary := [][]interface{}{{"Employee", "Shifts", "Hours"}}
+	for _, row := range SummarizeTimesheet(rows) {
+		summary = append(summary, []interface{}{row.Employee, row.Shifts, row.Hours})
+	}
+	if err := gen.setTable(timesheetSummarySheet, 1, summary); err != nil {
+		return nil, fmt.Errorf("failed to fill hours per employee: %w", err)
+	}
+
+	widths := map[string]map[string]float64{ //nolint:mnd // const values for row width
+		timesheetSheet:        {"A": 30, "B": 12, "C": 8, "D": 18, "E": 8, "F": 24, "G": 24},
+		timesheetSummarySheet: {"A": 30, "B": 10, "C": 10},
+	}
+	for sheet, columns := range widths {
+		for col, width := range columns {
+			if err := gen.file.SetColWidth(sheet, col, col, width); err != nil {
+				return nil, fmt.Errorf("failed to set column width: %w", err)
+			}
+		}
+	}
+
+	buffer, err := gen.file.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write data from saved file: %w", err)
+	}
+
+	return buffer, nil
+}
@@ -0,0 +1,52 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGenerateTimesheet(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	rows := []report.TimesheetRow{
+		{Employee: "Bob", Start: start, End: start.Add(8*time.Hour + 30*time.Minute), StartLocation: "50.45, 30.52"},
+		{Employee: "Ann", Start: start, End: start.Add(7*time.Hour + 20*time.Minute)},
+		{Employee: "Bob", Start: start.AddDate(0, 0, 1), End: start.AddDate(0, 0, 1).Add(4 * time.Hour)},
+		{Employee: "Ann", Start: start.AddDate(0, 0, 1)}, // still open
+	}
+
+	buffer, err := report.GenerateTimesheet(rows)
+	require.NoError(t, err)
+
+	f, err := excelize.OpenReader(buffer)
+	require.NoError(t, err)
+	defer f.Close()
+
+	shifts, err := f.GetRows("Timesheet")
+	require.NoError(t, err)
+	require.Len(t, shifts, 5)
+	assert.Equal(t, []string{"Employee", "Date", "Start", "End", "Hours", "Start location", "End location"}, shifts[0])
+	assert.Equal(t, []string{"Bob", "02.06.2025", "08:00", "02.06.2025 16:30", "8.5", "50.45, 30.52"}, shifts[1])
+	assert.Equal(t, []string{"Ann", "03.06.2025", "08:00", "", "0"}, shifts[4])
+
+	summary, err := f.GetRows("Summary")
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Employee", "Shifts", "Hours"},
+		{"Ann", "2", "7.33"},
+		{"Bob", "2", "12.5"},
+	}, summary)
+}
+
+func TestGenerateTimesheetEmpty(t *testing.T) {
+	t.Parallel()
+
+	_, err := report.GenerateTimesheet(nil)
+
+	require.ErrorIs(t, err, report.ErrNoTasks)
+}
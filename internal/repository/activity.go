@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// UpdateLastSeen records that the user has just interacted with the bot.
+// Telegram users who are not linked to an employee are ignored.
+func (r *Repository) UpdateLastSeen(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, UpdateLastSeenSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to update last seen of user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// GetInactiveUsers returns the users who have not interacted with the bot since the given time,
+// longest inactive first.
+func (r *Repository) GetInactiveUsers(ctx context.Context, since time.Time) ([]models.InactiveUser, error) {
+	rows, err := r.db.Query(ctx, GetInactiveUsersSQL, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inactive users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.InactiveUser
+	for rows.Next() {
+		var user models.InactiveUser
+		if err = rows.Scan(&user.TelegramID, &user.ShortName, &user.LastInteraction); err != nil {
+			return nil, fmt.Errorf("failed to scan inactive user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetUserCounts returns the number of Telegram users linked to employees and how many of them are admins.
+func (r *Repository) GetUserCounts(ctx context.Context) (models.UserCounts, error) {
+	var counts models.UserCounts
+	if err := r.db.QueryRow(ctx, GetUserCountsSQL).Scan(&counts.Linked, &counts.Admins); err != nil {
+		return models.UserCounts{}, fmt.Errorf("error querying user counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,161 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateLastSeen(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpdateLastSeenSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.UpdateLastSeen(ctx, telegramID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update last seen", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpdateLastSeenSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UpdateLastSeen(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to update last seen")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetInactiveUsers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	lastSeen := time.Date(2024, 12, 24, 9, 30, 0, 0, time.UTC)
+	columns := []string{"telegram_id", "shortname", "last_interaction"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetInactiveUsersSQL)).
+			WithArgs(since).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(int64(12345), "Doe J.", lastSeen))
+
+		users, err := repo.GetInactiveUsers(ctx, since)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.InactiveUser{
+			{TelegramID: 12345, ShortName: "Doe J.", LastInteraction: lastSeen},
+		}, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query users", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetInactiveUsersSQL)).
+			WithArgs(since).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetInactiveUsers(ctx, since)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query inactive users")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan users", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetInactiveUsersSQL)).
+			WithArgs(since).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("invalid", "Doe J.", lastSeen))
+
+		_, err = repo.GetInactiveUsers(ctx, since)
+
+		require.ErrorContains(t, err, "failed to scan inactive user")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetUserCounts(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("error - query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetUserCountsSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetUserCounts(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "error querying user counts")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - counts linked users and admins", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetUserCountsSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"linked", "admins"}).AddRow(42, 3))
+
+		counts, err := repo.GetUserCounts(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.UserCounts{Linked: 42, Admins: 3}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrReportNotFound is returned when the archived report does not exist or belongs to another user.
+var ErrReportNotFound = errors.New("archived report not found")
+
+// ArchiveReport stores the report and keeps only the last keep reports of the user. The content
+// is nil when the file is kept in the object storage under report.ObjectKey, the size and hash
+// of the file are set by the caller either way. It returns the ID of the archived report and the
+// storage keys of the removed reports, which the caller deletes from the storage.
+func (r *Repository) ArchiveReport(
+	ctx context.Context,
+	report models.ArchivedReport,
+	content []byte,
+	keep int,
+) (int64, []string, error) {
+	var (
+		id     int64
+		pruned []string
+	)
+	err := r.db.QueryRow(ctx, ArchiveReportSQL,
+		report.TelegramID, report.From, report.To, report.Format, report.Size, report.SHA256,
+		content, report.ObjectKey, keep,
+	).Scan(&id, &pruned)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to archive report of user %d: %w", report.TelegramID, err)
+	}
+
+	return id, pruned, nil
+}
+
+// SetArchivedReportFileID remembers the Telegram file ID of the sent report, so it can be sent
+// again without uploading the file.
+func (r *Repository) SetArchivedReportFileID(ctx context.Context, id int64, fileID string) error {
+	if _, err := r.db.Exec(ctx, SetArchivedReportFileIDSQL, id, fileID); err != nil {
+		return fmt.Errorf("failed to set file ID of archived report %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// GetArchivedReports returns up to limit archived reports of the user, newest first.
+func (r *Repository) GetArchivedReports(
+	ctx context.Context,
+	telegramID int64,
+	limit int,
+) ([]models.ArchivedReport, error) {
+	rows, err := r.db.Query(ctx, GetArchivedReportsSQL, telegramID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query archived reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []models.ArchivedReport
+	for rows.Next() {
+		var report models.ArchivedReport
+		if err = rows.Scan(&report.ID, &report.TelegramID, &report.From, &report.To, &report.Format,
+			&report.Size, &report.SHA256, &report.FileID, &report.ObjectKey, &report.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan archived report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return reports, nil
+}
+
+// GetArchivedReport returns the archived report of the user. It returns ErrReportNotFound
+// if there is no such report.
+func (r *Repository) GetArchivedReport(ctx context.Context, telegramID, id int64) (models.ArchivedReport, error) {
+	var report models.ArchivedReport
+	err := r.db.QueryRow(ctx, GetArchivedReportSQL, id, telegramID).Scan(
+		&report.ID, &report.TelegramID, &report.From, &report.To, &report.Format,
+		&report.Size, &report.SHA256, &report.FileID, &report.ObjectKey, &report.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ArchivedReport{}, ErrReportNotFound
+		}
+		return models.ArchivedReport{}, fmt.Errorf("failed to get archived report %d: %w", id, err)
+	}
+
+	return report, nil
+}
+
+// GetArchivedReportContent returns the file of the archived report of the user, empty if the file
+// is kept in the object storage. It returns ErrReportNotFound if there is no such report.
+func (r *Repository) GetArchivedReportContent(ctx context.Context, telegramID, id int64) ([]byte, error) {
+	var content []byte
+	if err := r.db.QueryRow(ctx, GetArchivedReportContentSQL, id, telegramID).Scan(&content); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		return nil, fmt.Errorf("failed to get content of archived report %d: %w", id, err)
+	}
+
+	return content, nil
+}
@@ -0,0 +1,215 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var archiveColumns = []string{
+	"id", "telegram_id", "period_from", "period_to", "format", "size", "sha256", "telegram_file_id", "object_key",
+	"created_at",
+}
+
+func TestArchiveReport(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	to := time.Now()
+	report := models.ArchivedReport{
+		TelegramID: 12345, From: to.AddDate(0, -1, 0), To: to, Format: "xlsx", Size: 6, SHA256: "abc",
+	}
+	content := []byte("report")
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ArchiveReportSQL)).
+			WithArgs(report.TelegramID, report.From, report.To, "xlsx", int64(6), "abc", content, "", 10).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "array"}).AddRow(int64(7), []string{}))
+
+		id, pruned, err := repo.ArchiveReport(ctx, report, content, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.Empty(t, pruned)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - object storage", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+		stored := report
+		stored.ObjectKey = "reports/12345/new.xlsx"
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ArchiveReportSQL)).
+			WithArgs(report.TelegramID, report.From, report.To, "xlsx", int64(6), "abc", []byte(nil),
+				"reports/12345/new.xlsx", 10).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "array"}).
+				AddRow(int64(8), []string{"reports/12345/old.xlsx"}))
+
+		id, pruned, err := repo.ArchiveReport(ctx, stored, nil, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), id)
+		assert.Equal(t, []string{"reports/12345/old.xlsx"}, pruned)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ArchiveReportSQL)).
+			WithArgs(report.TelegramID, report.From, report.To, "xlsx", int64(6), "abc", content, "", 10).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.ArchiveReport(ctx, report, content, 10)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to archive report of user 12345")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetArchivedReportFileID(t *testing.T) {
+	t.Parallel()
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewRepository(mock)
+
+	mock.ExpectExec(regexp.QuoteMeta(repository.SetArchivedReportFileIDSQL)).
+		WithArgs(int64(7), "file-id").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	require.NoError(t, repo.SetArchivedReportFileID(t.Context(), 7, "file-id"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetArchivedReports(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	now := time.Now()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetArchivedReportsSQL)).
+			WithArgs(int64(12345), 10).
+			WillReturnRows(pgxmock.NewRows(archiveColumns).
+				AddRow(int64(7), int64(12345), now, now, "pdf", int64(2048), "abc", "file-id", "", now))
+
+		reports, err := repo.GetArchivedReports(ctx, 12345, 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.ArchivedReport{{
+			ID: 7, TelegramID: 12345, From: now, To: now, Format: "pdf", Size: 2048, SHA256: "abc",
+			FileID: "file-id", CreatedAt: now,
+		}}, reports)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetArchivedReportsSQL)).
+			WithArgs(int64(12345), 10).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetArchivedReports(ctx, 12345, 10)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetArchivedReport(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("error - not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetArchivedReportSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetArchivedReport(ctx, 12345, 7)
+
+		require.ErrorIs(t, err, repository.ErrReportNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - content", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetArchivedReportContentSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnRows(pgxmock.NewRows([]string{"content"}).AddRow([]byte("report")))
+
+		content, err := repo.GetArchivedReportContent(ctx, 12345, 7)
+
+		require.NoError(t, err)
+		assert.Equal(t, []byte("report"), content)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - content not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetArchivedReportContentSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetArchivedReportContent(ctx, 12345, 7)
+
+		require.ErrorIs(t, err, repository.ErrReportNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// Admin actions stored in the admin_audit table.
+const (
+	AuditBroadcast       = "broadcast"
+	AuditGeocodingReset  = "geocoding_reset"
+	AuditAlertSilence    = "alert_silence"
+	AuditAgreementsFlush = "agreements_flush"
+	AuditSLAUpdate       = "sla_update"
+	AuditFeatureFlag     = "feature_flag"
+	AuditProfileGrant    = "profile_grant"
+	AuditProfileRevoke   = "profile_revoke"
+	AuditBulkComment     = "bulk_comment"
+	AuditLocationFix     = "location_fix"
+	AuditTemplateSave    = "template_save"
+	AuditTemplateDelete  = "template_delete"
+	AuditOnCallUpdate    = "oncall_update"
+)
+
+// Access to personal data, stored in the admin_audit table together with admin actions.
+const (
+	AuditCustomerView = "customer_view"
+)
+
+// Account changes made by users themselves, stored in the admin_audit table as well.
+const (
+	AuditAccountUnlink  = "account_unlink"
+	AuditAccountRestore = "account_restore"
+)
+
+// RecordAdminAction writes an entry to the admin audit log. Only the SHA-256 hash
+// of the payload is stored.
+func (r *Repository) RecordAdminAction(ctx context.Context, adminID int64, action string, payload []byte) error {
+	hash := sha256.Sum256(payload)
+	if _, err := r.db.Exec(ctx, InsertAdminActionSQL, adminID, action, hex.EncodeToString(hash[:])); err != nil {
+		return fmt.Errorf("failed to record admin action %q: %w", action, err)
+	}
+
+	return nil
+}
+
+// GetAdminActions returns a page of the admin audit log, newest first, together with
+// the total number of entries.
+func (r *Repository) GetAdminActions(ctx context.Context, limit, offset int) ([]models.AdminAction, int, error) {
+	rows, err := r.db.Query(ctx, GetAdminActionsSQL, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query admin actions: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		actions []models.AdminAction
+		total   int
+	)
+	for rows.Next() {
+		var action models.AdminAction
+		if err = rows.Scan(
+			&action.ID, &action.AdminID, &action.AdminName, &action.Action, &action.PayloadHash, &action.CreatedAt,
+			&total,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan admin action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return actions, total, nil
+}
@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAdminAction(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	adminID := int64(12345)
+	payload := []byte(`{"rows_affected":3}`)
+	// sha256 of the payload above.
+	payloadHash := "856a83dec042476cd7bf0aabc61b0dd40a8ce9e797aec21db7b5b5ca8f966327"
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertAdminActionSQL)).
+			WithArgs(adminID, repository.AuditGeocodingReset, payloadHash).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.RecordAdminAction(ctx, adminID, repository.AuditGeocodingReset, payload)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert action", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertAdminActionSQL)).
+			WithArgs(adminID, repository.AuditBroadcast, pgxmock.AnyArg()).
+			WillReturnError(assert.AnError)
+
+		err = repo.RecordAdminAction(ctx, adminID, repository.AuditBroadcast, payload)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to record admin action")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAdminActions(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	columns := []string{"id", "admin_id", "admin_name", "action", "payload_hash", "created_at", "total"}
+	createdAt := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminActionsSQL)).
+			WithArgs(10, 20).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(int64(2), int64(12345), "Doe J.", repository.AuditBroadcast, "abc", createdAt, 22).
+				AddRow(int64(1), int64(54321), "", repository.AuditGeocodingReset, "def", createdAt, 22))
+
+		actions, total, err := repo.GetAdminActions(ctx, 10, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, 22, total)
+		assert.Equal(t, []models.AdminAction{
+			{
+				ID: 2, AdminID: 12345, AdminName: "Doe J.", Action: repository.AuditBroadcast,
+				PayloadHash: "abc", CreatedAt: createdAt,
+			},
+			{
+				ID: 1, AdminID: 54321, Action: repository.AuditGeocodingReset,
+				PayloadHash: "def", CreatedAt: createdAt,
+			},
+		}, actions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query actions", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminActionsSQL)).
+			WithArgs(10, 0).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.GetAdminActions(ctx, 10, 0)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query admin actions")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan actions", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminActionsSQL)).
+			WithArgs(10, 0).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("invalid", int64(12345), "", repository.AuditBroadcast, "abc", createdAt, 1))
+
+		_, _, err = repo.GetAdminActions(ctx, 10, 0)
+
+		require.ErrorContains(t, err, "failed to scan admin action")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
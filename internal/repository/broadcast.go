@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrBroadcastTemplateNotFound is returned when the broadcast template does not exist.
+var ErrBroadcastTemplateNotFound = errors.New("broadcast template not found")
+
+// Broadcast statuses stored in the broadcasts table.
+const (
+	BroadcastRunning   = "running"
+	BroadcastCompleted = "completed"
+	BroadcastCanceled  = "canceled"
+)
+
+// CreateBroadcast records the start of a broadcast to total recipients and returns its ID.
+// Recipients of a broadcast requiring acknowledgment are asked to confirm it.
+func (r *Repository) CreateBroadcast(
+	ctx context.Context,
+	adminID int64,
+	kind string,
+	total int,
+	requiresAck bool,
+) (int64, error) {
+	var id int64
+
+	if err := r.db.QueryRow(ctx, CreateBroadcastSQL, adminID, kind, total, requiresAck).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	return id, nil
+}
+
+// UpdateBroadcastProgress stores the number of delivered and failed messages of a running broadcast.
+func (r *Repository) UpdateBroadcastProgress(ctx context.Context, id int64, sent, failed int) error {
+	if _, err := r.db.Exec(ctx, UpdateBroadcastProgressSQL, id, sent, failed); err != nil {
+		return fmt.Errorf("failed to update broadcast %d progress: %w", id, err)
+	}
+
+	return nil
+}
+
+// FinishBroadcast stores the final statistics and status of a broadcast.
+func (r *Repository) FinishBroadcast(ctx context.Context, id int64, sent, failed int, status string) error {
+	if _, err := r.db.Exec(ctx, FinishBroadcastSQL, id, sent, failed, status); err != nil {
+		return fmt.Errorf("failed to finish broadcast %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// AddBroadcastRecipient records that the broadcast requiring acknowledgment was delivered to the user.
+func (r *Repository) AddBroadcastRecipient(ctx context.Context, broadcastID, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, AddBroadcastRecipientSQL, broadcastID, telegramID); err != nil {
+		return fmt.Errorf("failed to add recipient %d of broadcast %d: %w", telegramID, broadcastID, err)
+	}
+
+	return nil
+}
+
+// AcknowledgeBroadcast records that the user confirmed the broadcast. It reports false if the user
+// is not a recipient of the broadcast or has already confirmed it.
+func (r *Repository) AcknowledgeBroadcast(ctx context.Context, broadcastID, telegramID int64) (bool, error) {
+	tag, err := r.db.Exec(ctx, AcknowledgeBroadcastSQL, broadcastID, telegramID)
+	if err != nil {
+		return false, fmt.Errorf("failed to acknowledge broadcast %d: %w", broadcastID, err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetBroadcastReceipts returns the recipients of the broadcast, those who have not confirmed it first.
+func (r *Repository) GetBroadcastReceipts(ctx context.Context, broadcastID int64) ([]models.BroadcastReceipt, error) {
+	rows, err := r.db.Query(ctx, GetBroadcastReceiptsSQL, broadcastID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get receipts of broadcast %d: %w", broadcastID, err)
+	}
+	defer rows.Close()
+
+	var receipts []models.BroadcastReceipt
+	for rows.Next() {
+		var (
+			receipt models.BroadcastReceipt
+			ackedAt *time.Time
+		)
+		if err = rows.Scan(&receipt.TelegramID, &receipt.Name, &ackedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan broadcast receipt row: %w", err)
+		}
+		if ackedAt != nil {
+			receipt.AckedAt = *ackedAt
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return receipts, nil
+}
+
+// SaveBroadcastTemplate stores a broadcast template and returns its ID. A template with the same
+// name is replaced.
+func (r *Repository) SaveBroadcastTemplate(ctx context.Context, name, text string, adminID int64) (int, error) {
+	var id int
+
+	if err := r.db.QueryRow(ctx, SaveBroadcastTemplateSQL, name, text, adminID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to save broadcast template %q: %w", name, err)
+	}
+
+	return id, nil
+}
+
+// GetBroadcastTemplates returns all broadcast templates ordered by name.
+func (r *Repository) GetBroadcastTemplates(ctx context.Context) ([]models.BroadcastTemplate, error) {
+	rows, err := r.db.Query(ctx, GetBroadcastTemplatesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get broadcast templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []models.BroadcastTemplate
+	for rows.Next() {
+		template, errScan := scanBroadcastTemplate(rows)
+		if errScan != nil {
+			return nil, fmt.Errorf("failed to scan broadcast template row: %w", errScan)
+		}
+		templates = append(templates, template)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return templates, nil
+}
+
+// GetBroadcastTemplate returns the broadcast template with the ID, or ErrBroadcastTemplateNotFound.
+func (r *Repository) GetBroadcastTemplate(ctx context.Context, id int) (models.BroadcastTemplate, error) {
+	template, err := scanBroadcastTemplate(r.db.QueryRow(ctx, GetBroadcastTemplateSQL, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.BroadcastTemplate{}, ErrBroadcastTemplateNotFound
+		}
+		return models.BroadcastTemplate{}, fmt.Errorf("failed to get broadcast template %d: %w", id, err)
+	}
+
+	return template, nil
+}
+
+// DeleteBroadcastTemplate removes the broadcast template, or returns ErrBroadcastTemplateNotFound.
+func (r *Repository) DeleteBroadcastTemplate(ctx context.Context, id int) error {
+	tag, err := r.db.Exec(ctx, DeleteBroadcastTemplateSQL, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete broadcast template %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrBroadcastTemplateNotFound
+	}
+
+	return nil
+}
+
+// scanBroadcastTemplate reads a broadcast template from a single row.
+func scanBroadcastTemplate(row pgx.Row) (models.BroadcastTemplate, error) {
+	var template models.BroadcastTemplate
+	err := row.Scan(&template.ID, &template.Name, &template.Text, &template.CreatedBy, &template.UpdatedAt)
+	return template, err
+}
@@ -0,0 +1,507 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBroadcast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	adminID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CreateBroadcastSQL)).
+			WithArgs(adminID, "photo", 42, true).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+		id, err := repo.CreateBroadcast(ctx, adminID, "photo", 42, true)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CreateBroadcastSQL)).
+			WithArgs(adminID, "text", 42, false).WillReturnError(assert.AnError)
+
+		id, err := repo.CreateBroadcast(ctx, adminID, "text", 42, false)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to create broadcast")
+		assert.Zero(t, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateBroadcastProgress(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpdateBroadcastProgressSQL)).
+			WithArgs(int64(7), 40, 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.UpdateBroadcastProgress(ctx, 7, 40, 2)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpdateBroadcastProgressSQL)).
+			WithArgs(int64(7), 40, 2).WillReturnError(assert.AnError)
+
+		err = repo.UpdateBroadcastProgress(ctx, 7, 40, 2)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to update broadcast 7 progress")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFinishBroadcast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.FinishBroadcastSQL)).
+			WithArgs(int64(7), 40, 2, repository.BroadcastCanceled).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.FinishBroadcast(ctx, 7, 40, 2, repository.BroadcastCanceled)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.FinishBroadcastSQL)).
+			WithArgs(int64(7), 42, 0, repository.BroadcastCompleted).
+			WillReturnError(assert.AnError)
+
+		err = repo.FinishBroadcast(ctx, 7, 42, 0, repository.BroadcastCompleted)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to finish broadcast 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSaveBroadcastTemplate(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	adminID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SaveBroadcastTemplateSQL)).
+			WithArgs("Maintenance", "Works on {date}", adminID).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(3))
+
+		id, err := repo.SaveBroadcastTemplate(ctx, "Maintenance", "Works on {date}", adminID)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SaveBroadcastTemplateSQL)).
+			WithArgs("Maintenance", "Works on {date}", adminID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.SaveBroadcastTemplate(ctx, "Maintenance", "Works on {date}", adminID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, `failed to save broadcast template "Maintenance"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetBroadcastTemplates(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	updatedAt := time.Date(2025, 5, 6, 8, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "text", "created_by", "updated_at"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastTemplatesSQL)).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(1, "Holiday", "Office closed on {date}", int64(1), updatedAt).
+				AddRow(2, "Maintenance", "Works at {time}", int64(2), updatedAt))
+
+		templates, err := repo.GetBroadcastTemplates(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.BroadcastTemplate{
+			{ID: 1, Name: "Holiday", Text: "Office closed on {date}", CreatedBy: 1, UpdatedAt: updatedAt},
+			{ID: 2, Name: "Maintenance", Text: "Works at {time}", CreatedBy: 2, UpdatedAt: updatedAt},
+		}, templates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastTemplatesSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetBroadcastTemplates(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get broadcast templates")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetBroadcastTemplate(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	updatedAt := time.Date(2025, 5, 6, 8, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "name", "text", "created_by", "updated_at"}).
+				AddRow(1, "Holiday", "Office closed on {date}", int64(1), updatedAt))
+
+		template, err := repo.GetBroadcastTemplate(ctx, 1)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.BroadcastTemplate{
+			ID: 1, Name: "Holiday", Text: "Office closed on {date}", CreatedBy: 1, UpdatedAt: updatedAt,
+		}, template)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, err = repo.GetBroadcastTemplate(ctx, 1)
+
+		require.ErrorIs(t, err, repository.ErrBroadcastTemplateNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetBroadcastTemplate(ctx, 1)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get broadcast template 1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteBroadcastTemplate(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, repo.DeleteBroadcastTemplate(ctx, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+		err = repo.DeleteBroadcastTemplate(ctx, 1)
+
+		require.ErrorIs(t, err, repository.ErrBroadcastTemplateNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteBroadcastTemplateSQL)).
+			WithArgs(1).
+			WillReturnError(assert.AnError)
+
+		err = repo.DeleteBroadcastTemplate(ctx, 1)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to delete broadcast template 1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAddBroadcastRecipient(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AddBroadcastRecipientSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.AddBroadcastRecipient(ctx, 7, 12345))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AddBroadcastRecipientSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnError(assert.AnError)
+
+		err = repo.AddBroadcastRecipient(ctx, 7, 12345)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to add recipient 12345 of broadcast 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAcknowledgeBroadcast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("acknowledged", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AcknowledgeBroadcastSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		acked, err := repo.AcknowledgeBroadcast(ctx, 7, 12345)
+
+		require.NoError(t, err)
+		assert.True(t, acked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already acknowledged", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AcknowledgeBroadcastSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		acked, err := repo.AcknowledgeBroadcast(ctx, 7, 12345)
+
+		require.NoError(t, err)
+		assert.False(t, acked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AcknowledgeBroadcastSQL)).
+			WithArgs(int64(7), int64(12345)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.AcknowledgeBroadcast(ctx, 7, 12345)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to acknowledge broadcast 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetBroadcastReceipts(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	ackedAt := time.Date(2025, 5, 6, 8, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastReceiptsSQL)).
+			WithArgs(int64(7)).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id", "shortname", "acked_at"}).
+				AddRow(int64(1), "Ivanov I.", nil).
+				AddRow(int64(2), "Petrov P.", &ackedAt))
+
+		receipts, err := repo.GetBroadcastReceipts(ctx, 7)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.BroadcastReceipt{
+			{TelegramID: 1, Name: "Ivanov I."},
+			{TelegramID: 2, Name: "Petrov P.", AckedAt: ackedAt},
+		}, receipts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetBroadcastReceiptsSQL)).
+			WithArgs(int64(7)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetBroadcastReceipts(ctx, 7)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get receipts of broadcast 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/config"
 	"github.com/UnknownOlympus/oracle/internal/repository"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
@@ -12,6 +13,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// testPool is a small connection pool for the database tests.
+var testPool = config.Pool{MinConns: 1, MaxConns: 4, ConnLifetime: time.Hour}
+
 func TestNewDatabase_Success(t *testing.T) {
 	t.Parallel()
 	if testing.Short() {
@@ -51,7 +55,7 @@ func TestNewDatabase_Success(t *testing.T) {
 		t.Fatalf("failed to get mapped port: %v", err)
 	}
 
-	dbpool, err := repository.NewDatabase(host, port.Port(), "testuser", "testpassword", "testdb")
+	dbpool, err := repository.NewDatabase(host, port.Port(), "testuser", "testpassword", "testdb", testPool)
 	if err != nil {
 		t.Fatalf("NewDatabase failed: %v", err)
 	}
@@ -69,7 +73,7 @@ func TestNewDatabase_Success(t *testing.T) {
 
 func TestNewDatabase_ParseConfigError(t *testing.T) {
 	t.Parallel()
-	dbpool, err := repository.NewDatabase("localhost", "invalid-port", "user", "pass", "db")
+	dbpool, err := repository.NewDatabase("localhost", "invalid-port", "user", "pass", "db", testPool)
 
 	require.Error(t, err, "Expected an error for invalid database URL, but got nil")
 	require.Nil(t, dbpool, "Expected nil dbpool, got: %v", dbpool)
@@ -81,7 +85,7 @@ func TestNewDatabase_ParseConfigError(t *testing.T) {
 
 func TestNewDatabase_ConnectionError(t *testing.T) {
 	t.Parallel()
-	dbpool, err := repository.NewDatabase("nonexistent-host", "5432", "user", "pass", "db")
+	dbpool, err := repository.NewDatabase("nonexistent-host", "5432", "user", "pass", "db", testPool)
 
 	require.Error(t, err, "Expected an error for connection failure, but got nil")
 	if dbpool != nil {
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// RecordSendFailure saves a message Telegram refused to deliver to the user. A permanent
+// failure means the user can no longer be reached, so they are also unsubscribed from
+// automatic reports and the morning digest.
+func (r *Repository) RecordSendFailure(
+	ctx context.Context,
+	telegramID int64,
+	source, reason string,
+	permanent bool,
+	sendErr string,
+) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // omitted because checking for errors will not affect the function
+
+	if _, err = tx.Exec(ctx, InsertSendFailureSQL, telegramID, source, reason, permanent, sendErr); err != nil {
+		return fmt.Errorf("failed to record send failure for user %d: %w", telegramID, err)
+	}
+
+	if permanent {
+		if _, err = tx.Exec(ctx, DeleteReportSubscriptionSQL, telegramID); err != nil {
+			return fmt.Errorf("failed to unsubscribe unreachable user %d: %w", telegramID, err)
+		}
+		if _, err = tx.Exec(ctx, DeleteDigestSubscriptionSQL, telegramID); err != nil {
+			return fmt.Errorf("failed to unsubscribe unreachable user %d from digest: %w", telegramID, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit send failure: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSendFailure(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	sendErr := "telebot: Forbidden: bot was blocked by the user"
+
+	t.Run("temporary failure", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertSendFailureSQL)).
+			WithArgs(telegramID, "alert", "other", false, "timeout").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		err = repo.RecordSendFailure(ctx, telegramID, "alert", "other", false, "timeout")
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("permanent failure unsubscribes user", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertSendFailureSQL)).
+			WithArgs(telegramID, "broadcast", "blocked", true, sendErr).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteReportSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectCommit()
+
+		err = repo.RecordSendFailure(ctx, telegramID, "broadcast", "blocked", true, sendErr)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert failure", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertSendFailureSQL)).
+			WithArgs(telegramID, "broadcast", "blocked", true, sendErr).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err = repo.RecordSendFailure(ctx, telegramID, "broadcast", "blocked", true, sendErr)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to record send failure")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - begin transaction", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin().WillReturnError(assert.AnError)
+
+		err = repo.RecordSendFailure(ctx, telegramID, "alert", "other", false, "timeout")
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
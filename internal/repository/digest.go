@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SubscribeToDigest enables the morning digest for the user, sent at the digest time
+// in the given time zone. Subscribing again only changes the time zone.
+func (r *Repository) SubscribeToDigest(ctx context.Context, telegramID int64, timezone string) error {
+	if _, err := r.db.Exec(ctx, UpsertDigestSubscriptionSQL, telegramID, timezone); err != nil {
+		return fmt.Errorf("failed to subscribe user %d to digest: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// UnsubscribeFromDigest disables the morning digest for the user.
+func (r *Repository) UnsubscribeFromDigest(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, DeleteDigestSubscriptionSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to unsubscribe user %d from digest: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// GetDigestSubscription returns the digest subscription of the user.
+// The second value is false if the user is not subscribed.
+func (r *Repository) GetDigestSubscription(
+	ctx context.Context,
+	telegramID int64,
+) (models.DigestSubscription, bool, error) {
+	subscription, err := scanDigestSubscription(r.db.QueryRow(ctx, GetDigestSubscriptionSQL, telegramID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DigestSubscription{}, false, nil
+		}
+		return models.DigestSubscription{}, false, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+
+	return subscription, true, nil
+}
+
+// GetDigestSubscribers returns all users subscribed to the morning digest.
+func (r *Repository) GetDigestSubscribers(ctx context.Context) ([]models.DigestSubscription, error) {
+	rows, err := r.db.Query(ctx, GetDigestSubscribersSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	var subscriptions []models.DigestSubscription
+	for rows.Next() {
+		subscription, errScan := scanDigestSubscription(rows)
+		if errScan != nil {
+			return nil, fmt.Errorf("failed to scan digest subscriber row: %w", errScan)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// MarkDigestSent remembers the local date of the digest sent to the user,
+// so it is not sent twice on the same day.
+func (r *Repository) MarkDigestSent(ctx context.Context, telegramID int64, day time.Time) error {
+	date := pgtype.Date{Time: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), Valid: true}
+	if _, err := r.db.Exec(ctx, MarkDigestSentSQL, telegramID, date); err != nil {
+		return fmt.Errorf("failed to mark digest of user %d as sent: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// GetDigestTasks returns the open tasks of the user, oldest first.
+func (r *Repository) GetDigestTasks(ctx context.Context, telegramID int64) ([]models.DigestTask, error) {
+	rows, err := r.db.Query(ctx, GetDigestTasksSQL, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query digest tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.DigestTask
+	for rows.Next() {
+		var task models.DigestTask
+		if err = rows.Scan(&task.ID, &task.Description, &task.CreationDate); err != nil {
+			return nil, fmt.Errorf("failed to scan digest task row: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// scanDigestSubscription reads a digest subscription from a single row.
+func scanDigestSubscription(row pgx.Row) (models.DigestSubscription, error) {
+	var (
+		subscription models.DigestSubscription
+		lastSentOn   pgtype.Date
+	)
+	if err := row.Scan(&subscription.TelegramID, &subscription.Timezone, &lastSentOn); err != nil {
+		return models.DigestSubscription{}, err
+	}
+	if lastSentOn.Valid {
+		subscription.LastSentOn = lastSentOn.Time
+	}
+
+	return subscription, nil
+}
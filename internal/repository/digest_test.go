@@ -0,0 +1,343 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeToDigest(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertDigestSubscriptionSQL)).
+			WithArgs(telegramID, "Europe/Kyiv").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.SubscribeToDigest(ctx, telegramID, "Europe/Kyiv"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - subscribe", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertDigestSubscriptionSQL)).
+			WithArgs(telegramID, "Europe/Kyiv").
+			WillReturnError(assert.AnError)
+
+		err = repo.SubscribeToDigest(ctx, telegramID, "Europe/Kyiv")
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to subscribe user 12345 to digest")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnsubscribeFromDigest(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, repo.UnsubscribeFromDigest(ctx, telegramID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - unsubscribe", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		err = repo.UnsubscribeFromDigest(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetDigestSubscription(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	columns := []string{"telegram_id", "timezone", "last_sent_on"}
+	lastSentOn := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+
+	t.Run("subscribed", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(telegramID, "Europe/Warsaw", pgtype.Date{Time: lastSentOn, Valid: true}))
+
+		subscription, ok, err := repo.GetDigestSubscription(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, models.DigestSubscription{
+			TelegramID: telegramID, Timezone: "Europe/Warsaw", LastSentOn: lastSentOn,
+		}, subscription)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not subscribed", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, ok, err := repo.GetDigestSubscription(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query subscription", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.GetDigestSubscription(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get digest subscription")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetDigestSubscribers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	columns := []string{"telegram_id", "timezone", "last_sent_on"}
+	lastSentOn := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+
+	t.Run("skips unlinked users", func(t *testing.T) {
+		t.Parallel()
+		// Logged out users keep their subscription during the grace period of the logout.
+		assert.Contains(t, repository.GetDigestSubscribersSQL, "bu.deleted_at IS NULL")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscribersSQL)).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(int64(1), "Europe/Kyiv", pgtype.Date{Time: lastSentOn, Valid: true}).
+				AddRow(int64(2), "UTC", pgtype.Date{}))
+
+		subscriptions, err := repo.GetDigestSubscribers(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.DigestSubscription{
+			{TelegramID: 1, Timezone: "Europe/Kyiv", LastSentOn: lastSentOn},
+			{TelegramID: 2, Timezone: "UTC"},
+		}, subscriptions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query subscribers", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscribersSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetDigestSubscribers(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get digest subscribers")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan subscribers", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestSubscribersSQL)).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("invalid", "UTC", pgtype.Date{}))
+
+		_, err = repo.GetDigestSubscribers(ctx)
+
+		require.ErrorContains(t, err, "failed to scan digest subscriber row")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkDigestSent(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	kyiv := time.FixedZone("EEST", 3*60*60)
+	day := time.Date(2025, 5, 6, 8, 30, 0, 0, kyiv)
+	date := pgtype.Date{Time: time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.MarkDigestSentSQL)).
+			WithArgs(telegramID, date).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.MarkDigestSent(ctx, telegramID, day))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - mark sent", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.MarkDigestSentSQL)).
+			WithArgs(telegramID, date).
+			WillReturnError(assert.AnError)
+
+		err = repo.MarkDigestSent(ctx, telegramID, day)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetDigestTasks(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	columns := []string{"task_id", "description", "creation_date"}
+	created := time.Date(2025, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestTasksSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(101, "Connect fiber", created))
+
+		tasks, err := repo.GetDigestTasks(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.DigestTask{{ID: 101, Description: "Connect fiber", CreationDate: created}}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestTasksSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetDigestTasks(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query digest tasks")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetDigestTasksSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("invalid", "Connect fiber", created))
+
+		_, err = repo.GetDigestTasks(ctx, telegramID)
+
+		require.ErrorContains(t, err, "failed to scan digest task row")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
+)
+
+// GetFeatureFlags returns the feature flags set by admins.
+func (r *Repository) GetFeatureFlags(ctx context.Context) ([]featureflags.Flag, error) {
+	rows, err := r.db.Query(ctx, GetFeatureFlagsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []featureflags.Flag
+	for rows.Next() {
+		var (
+			flag  featureflags.Flag
+			roles []string
+		)
+		if err = rows.Scan(&flag.Name, &flag.Enabled, &flag.Percentage, &roles); err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag row: %w", err)
+		}
+		for _, role := range roles {
+			flag.Roles = append(flag.Roles, featureflags.Role(role))
+		}
+		flags = append(flags, flag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return flags, nil
+}
+
+// SetFeatureFlag stores the feature flag set by the admin.
+func (r *Repository) SetFeatureFlag(ctx context.Context, flag featureflags.Flag, updatedBy int64) error {
+	roles := make([]string, 0, len(flag.Roles))
+	for _, role := range flag.Roles {
+		roles = append(roles, string(role))
+	}
+
+	_, err := r.db.Exec(ctx, UpsertFeatureFlagSQL, flag.Name, flag.Enabled, flag.Percentage, roles, updatedBy)
+	if err != nil {
+		return fmt.Errorf("failed to set feature flag %s: %w", flag.Name, err)
+	}
+
+	return nil
+}
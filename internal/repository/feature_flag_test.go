@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFeatureFlags(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetFeatureFlagsSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"name", "enabled", "percentage", "roles"}).
+				AddRow("pdf_reports", true, 25, []string{"admin"}).
+				AddRow("route_planner", false, 0, []string{}))
+
+		flags, err := repo.GetFeatureFlags(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []featureflags.Flag{
+			{Name: "pdf_reports", Enabled: true, Percentage: 25, Roles: []featureflags.Role{featureflags.RoleAdmin}},
+			{Name: "route_planner"},
+		}, flags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetFeatureFlagsSQL)).
+			WillReturnError(errors.New("db error"))
+
+		flags, err := repo.GetFeatureFlags(ctx)
+
+		require.Error(t, err)
+		assert.Nil(t, flags)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetFeatureFlag(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	flag := featureflags.Flag{
+		Name:       "pdf_reports",
+		Enabled:    true,
+		Percentage: 50,
+		Roles:      []featureflags.Role{featureflags.RoleAdmin},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertFeatureFlagSQL)).
+			WithArgs("pdf_reports", true, 50, []string{"admin"}, int64(7)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.SetFeatureFlag(ctx, flag, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertFeatureFlagSQL)).
+			WithArgs("pdf_reports", true, 50, []string{"admin"}, int64(7)).
+			WillReturnError(errors.New("db error"))
+
+		require.Error(t, repo.SetFeatureFlag(ctx, flag, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
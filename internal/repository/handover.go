@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// likeEscaper escapes the LIKE wildcards in user input, so they match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchTeammates returns up to limit employees linked to Telegram whose short name contains
+// the query, ignoring case. The user with telegramID is left out.
+func (r *Repository) SearchTeammates(
+	ctx context.Context,
+	telegramID int64,
+	query string,
+	limit int,
+) ([]models.Teammate, error) {
+	pattern := "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%"
+	rows, err := r.db.Query(ctx, SearchTeammatesSQL, telegramID, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teammates: %w", err)
+	}
+	defer rows.Close()
+
+	var teammates []models.Teammate
+	for rows.Next() {
+		var teammate models.Teammate
+		if err = rows.Scan(
+			&teammate.EmployeeID, &teammate.ShortName, &teammate.FullName, &teammate.TelegramID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan teammate row: %w", err)
+		}
+		teammates = append(teammates, teammate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return teammates, nil
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchTeammates(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SearchTeammatesSQL)).
+			WithArgs(int64(1), `%ko\_v%`, 10).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "shortname", "fullname", "telegram_id"}).
+				AddRow(7, "Ko_valenko I.", "Ivan Ko_valenko", int64(2)))
+
+		teammates, err := repo.SearchTeammates(ctx, 1, " ko_v ", 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Teammate{
+			{EmployeeID: 7, ShortName: "Ko_valenko I.", FullName: "Ivan Ko_valenko", TelegramID: 2},
+		}, teammates)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SearchTeammatesSQL)).
+			WithArgs(int64(1), "%%", 10).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.SearchTeammates(ctx, 1, "", 10)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,198 @@
+package repository_test
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/UnknownOlympus/oracle/migrations"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// newIntegrationDatabase starts a PostGIS server, creates the tables of the task synchronization
+// service from testdata/schema.sql, applies the migrations of the bot and loads testdata/seed.sql.
+// The server is terminated when the test ends.
+func newIntegrationDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	ctx := t.Context()
+
+	pgContainer, err := postgres.Run(ctx,
+		"postgis/postgis:16-3.4-alpine",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpassword"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute),
+		),
+	)
+	require.NoError(t, err, "failed to start postgres container")
+	t.Cleanup(func() {
+		if errTerminate := testcontainers.TerminateContainer(pgContainer); errTerminate != nil {
+			t.Errorf("failed to terminate postgres container: %v", errTerminate)
+		}
+	})
+
+	host, err := pgContainer.Host(ctx)
+	require.NoError(t, err)
+	port, err := pgContainer.MappedPort(ctx, "5432")
+	require.NoError(t, err)
+
+	pool, err := repository.NewDatabase(host, port.Port(), "testuser", "testpassword", "testdb", testPool)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	execFile(t, pool, "testdata/schema.sql")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	require.NoError(t, repository.Migrate(ctx, logger, pool, migrations.FS))
+	execFile(t, pool, "testdata/seed.sql")
+
+	return pool
+}
+
+// execFile runs the SQL statements of the file.
+func execFile(t *testing.T, pool *pgxpool.Pool, path string) {
+	t.Helper()
+
+	statements, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = pool.Exec(t.Context(), string(statements))
+	require.NoError(t, err, "failed to execute %s", path)
+}
+
+func TestRepositoryIntegration(t *testing.T) {
+	t.Parallel()
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode.")
+	}
+
+	pool := newIntegrationDatabase(t)
+	repo := repository.NewRepository(pool)
+	ctx := t.Context()
+	from := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, time.June, 30, 23, 59, 59, 0, time.UTC)
+
+	t.Run("GetTaskSummary", func(t *testing.T) {
+		summary, err := repo.GetTaskSummary(ctx, 1001, from, to)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []models.TaskSummary{
+			{Type: "Connection", Count: 1},
+			{Type: "Repair", Count: 1},
+			{Type: "Total", Count: 2},
+		}, summary)
+	})
+
+	t.Run("GetTaskSummary - unlinked user", func(t *testing.T) {
+		summary, err := repo.GetTaskSummary(ctx, 1003, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.TaskSummary{{Type: "Total", Count: 0}}, summary)
+	})
+
+	t.Run("GetCompletedTasksByExecutor", func(t *testing.T) {
+		tasks, err := repo.GetCompletedTasksByExecutor(ctx, 1001, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+
+		assert.Equal(t, 101, tasks[0].ID)
+		assert.Equal(t, "Connection", tasks[0].Type)
+		assert.Equal(t, "Main St 1", tasks[0].Address)
+		assert.Equal(t, []string{"Customer One"}, tasks[0].CustomerNames)
+		assert.Equal(t, []string{"Done"}, tasks[0].Comments)
+		assert.Equal(t, []string{"Ivan P.", "Olena K."}, tasks[0].Executors)
+		assert.True(t, tasks[0].ClosingDate.Equal(time.Date(2025, time.June, 5, 12, 0, 0, 0, time.UTC)))
+
+		assert.Equal(t, 102, tasks[1].ID)
+		assert.Equal(t, "Repair", tasks[1].Type)
+		assert.Equal(t, []string{"Customer One", "Customer Two"}, tasks[1].CustomerNames)
+		assert.Empty(t, tasks[1].Comments)
+		assert.Equal(t, []string{"Ivan P."}, tasks[1].Executors)
+	})
+
+	t.Run("GetCompletedTasksByExecutor - no tasks", func(t *testing.T) {
+		tasks, err := repo.GetCompletedTasksByExecutor(ctx, 1001, to, to.AddDate(0, 0, 1))
+
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("GetReportSubscribers - skips unlinked users", func(t *testing.T) {
+		ids, err := repo.GetReportSubscribers(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1001}, ids)
+	})
+
+	t.Run("GetDigestSubscribers - skips unlinked users", func(t *testing.T) {
+		subscriptions, err := repo.GetDigestSubscribers(ctx)
+
+		require.NoError(t, err)
+		require.Len(t, subscriptions, 1)
+		assert.Equal(t, int64(1001), subscriptions[0].TelegramID)
+		assert.Equal(t, "Europe/Kyiv", subscriptions[0].Timezone)
+	})
+
+	t.Run("GetOnCallWeek - skips unlinked users", func(t *testing.T) {
+		_, ok, err := repo.GetOnCallWeek(ctx, time.Date(2025, time.June, 4, 0, 0, 0, 0, time.UTC))
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("GetOnCallWeeks - skips unlinked users", func(t *testing.T) {
+		weeks, err := repo.GetOnCallWeeks(ctx, time.Date(2025, time.June, 4, 0, 0, 0, 0, time.UTC), 5)
+
+		require.NoError(t, err)
+		require.Len(t, weeks, 1)
+		assert.Equal(t, int64(1001), weeks[0].TelegramID)
+		assert.Equal(t, "Ivan P.", weeks[0].ShortName)
+		assert.True(t, weeks[0].WeekStart.Equal(time.Date(2025, time.June, 9, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("GetTasksInRadius", func(t *testing.T) {
+		tasks, err := repo.GetTasksInRadius(ctx, 50.4501, 30.5234, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{104, 105}, taskIDs(tasks))
+	})
+
+	t.Run("GetTasksInRadius - PostGIS", func(t *testing.T) {
+		// The repository falls back to the haversine query silently, make sure there is nothing to fall back from.
+		var hasLocation bool
+		err := pool.QueryRow(ctx, `SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns WHERE table_name = 'tasks' AND column_name = 'location'
+		)`).Scan(&hasLocation)
+		require.NoError(t, err)
+		require.True(t, hasLocation, "the PostGIS migration did not add the location column")
+
+		postgisRepo := repository.NewRepository(pool)
+		postgisRepo.EnablePostGIS()
+
+		tasks, err := postgisRepo.GetTasksInRadius(ctx, 50.4501, 30.5234, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{104, 105}, taskIDs(tasks))
+	})
+}
+
+// taskIDs returns the IDs of the tasks in order.
+func taskIDs(tasks []models.ActiveTask) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	return ids
+}
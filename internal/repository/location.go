@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetActiveTaskLocations returns the user's active tasks that have coordinates,
+// newest first. Tasks which are not geocoded yet are left out.
+func (r *Repository) GetActiveTaskLocations(ctx context.Context, telegramID int64) ([]models.TaskLocation, error) {
+	rows, err := r.db.Query(ctx, GetActiveTaskLocationsSQL, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query active task locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []models.TaskLocation
+	for rows.Next() {
+		var location models.TaskLocation
+		if err = rows.Scan(
+			&location.ID, &location.Description, &location.Address, &location.Latitude, &location.Longitude,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan active task location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return locations, nil
+}
@@ -0,0 +1,77 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetActiveTaskLocations(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	columns := []string{"task_id", "description", "address", "latitude", "longitude"}
+
+	t.Run("error - query locations", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTaskLocationsSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetActiveTaskLocations(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query active task locations")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan locations", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTaskLocationsSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("invalid", "descr", "addr", 50.45, 30.52))
+
+		_, err = repo.GetActiveTaskLocations(ctx, telegramID)
+
+		require.ErrorContains(t, err, "failed to scan active task location")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTaskLocationsSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(7, "Repair", "Main st. 1", 50.45, 30.52))
+
+		locations, err := repo.GetActiveTaskLocations(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.TaskLocation{
+			{ID: 7, Description: "Repair", Address: "Main st. 1", Latitude: 50.45, Longitude: 30.52},
+		}, locations)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
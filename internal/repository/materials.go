@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetMaterials returns the active materials of the catalog in the order they are shown.
+func (r *Repository) GetMaterials(ctx context.Context) ([]models.Material, error) {
+	rows, err := r.db.Query(ctx, GetMaterialsSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get materials: %w", err)
+	}
+	defer rows.Close()
+
+	var materials []models.Material
+	for rows.Next() {
+		var material models.Material
+		if err = rows.Scan(&material.ID, &material.Name, &material.Unit); err != nil {
+			return nil, fmt.Errorf("failed to scan material row: %w", err)
+		}
+		materials = append(materials, material)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return materials, nil
+}
+
+// AddTaskMaterial logs the quantity of the material used on the task by the user.
+func (r *Repository) AddTaskMaterial(
+	ctx context.Context,
+	taskID, materialID int,
+	quantity float64,
+	telegramID int64,
+) error {
+	if _, err := r.db.Exec(ctx, AddTaskMaterialSQL, taskID, materialID, quantity, telegramID); err != nil {
+		return fmt.Errorf("failed to add material %d to task %d: %w", materialID, taskID, err)
+	}
+
+	return nil
+}
+
+// GetTaskMaterials returns the materials used on the tasks, summed per material and keyed by
+// the task ID.
+func (r *Repository) GetTaskMaterials(ctx context.Context, taskIDs []int64) (map[int64][]models.TaskMaterial, error) {
+	materials := make(map[int64][]models.TaskMaterial, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return materials, nil
+	}
+
+	rows, err := r.db.Query(ctx, GetTaskMaterialsSQL, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select materials of %d tasks: %w", len(taskIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var material models.TaskMaterial
+		if err = rows.Scan(&taskID, &material.Name, &material.Unit, &material.Quantity); err != nil {
+			return nil, fmt.Errorf("failed to scan task material row: %w", err)
+		}
+		materials[taskID] = append(materials[taskID], material)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return materials, nil
+}
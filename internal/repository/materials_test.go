@@ -0,0 +1,164 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMaterials(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	columns := []string{"id", "name", "unit"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetMaterialsSQL)).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(1, "UTP cable", "m").
+				AddRow(3, "RJ45 connector", "pcs"))
+
+		materials, err := repo.GetMaterials(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Material{
+			{ID: 1, Name: "UTP cable", Unit: "m"},
+			{ID: 3, Name: "RJ45 connector", Unit: "pcs"},
+		}, materials)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetMaterialsSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetMaterials(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get materials")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAddTaskMaterial(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AddTaskMaterialSQL)).
+			WithArgs(42, 1, 12.5, int64(111)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.AddTaskMaterial(ctx, 42, 1, 12.5, 111))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AddTaskMaterialSQL)).
+			WithArgs(42, 1, 12.5, int64(111)).
+			WillReturnError(assert.AnError)
+
+		err = repo.AddTaskMaterial(ctx, 42, 1, 12.5, 111)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to add material 1 to task 42")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTaskMaterials(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	columns := []string{"task_id", "name", "unit", "sum"}
+	taskIDs := []int64{42, 43}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskMaterialsSQL)).
+			WithArgs(taskIDs).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(int64(42), "UTP cable", "m", 20.0).
+				AddRow(int64(42), "RJ45 connector", "pcs", 2.0).
+				AddRow(int64(43), "UTP cable", "m", 5.5))
+
+		materials, err := repo.GetTaskMaterials(ctx, taskIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64][]models.TaskMaterial{
+			42: {{Name: "UTP cable", Unit: "m", Quantity: 20}, {Name: "RJ45 connector", Unit: "pcs", Quantity: 2}},
+			43: {{Name: "UTP cable", Unit: "m", Quantity: 5.5}},
+		}, materials)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		materials, err := repo.GetTaskMaterials(ctx, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, materials)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskMaterialsSQL)).
+			WithArgs(taskIDs).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTaskMaterials(ctx, taskIDs)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
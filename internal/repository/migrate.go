@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/tern/v2/migrate"
+)
+
+// migrationVersionTable records the sequence number of the last applied migration.
+const migrationVersionTable = "schema_version"
+
+// Migrate applies the migrations in fsys which have not been applied to the database yet.
+// Migrations are named like 0001_description.sql and applied in order, each in a transaction.
+// Concurrent instances wait for each other, so only one of them applies the migrations.
+func Migrate(ctx context.Context, log *slog.Logger, pool *pgxpool.Pool, fsys fs.FS) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migrations: %w", err)
+	}
+	defer conn.Release()
+
+	migrator, err := migrate.NewMigrator(ctx, conn.Conn(), migrationVersionTable)
+	if err != nil {
+		return fmt.Errorf("failed to create migrator: %w", err)
+	}
+	if err = migrator.LoadMigrations(fsys); err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	migrator.OnStart = func(sequence int32, name, _, _ string) {
+		log.InfoContext(ctx, "Applying migration", "sequence", sequence, "name", name)
+	}
+
+	if err = migrator.Migrate(ctx); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	version, err := migrator.GetCurrentVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	log.InfoContext(ctx, "Database schema is up to date", "version", version)
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// StartOnboarding starts the tour of the user at the step. It reports false if the user has
+// started the tour before, whether or not they completed it.
+func (r *Repository) StartOnboarding(ctx context.Context, telegramID int64, step string) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, StartOnboardingSQL, telegramID, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to start onboarding: %w", err)
+	}
+
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// AdvanceOnboarding moves the tour of the user from one step to the next. It reports false if
+// the user is not on the step, e.g. when a button of an old tour message is pressed twice.
+func (r *Repository) AdvanceOnboarding(ctx context.Context, telegramID int64, from, to string) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, AdvanceOnboardingSQL, telegramID, from, to)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance onboarding: %w", err)
+	}
+
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// CompleteOnboarding marks the tour of the user as completed, either finished or skipped.
+func (r *Repository) CompleteOnboarding(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, CompleteOnboardingSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to complete onboarding: %w", err)
+	}
+
+	return nil
+}
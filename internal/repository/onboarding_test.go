@@ -0,0 +1,174 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOnboarding(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success - first time", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.StartOnboardingSQL)).
+			WithArgs(int64(123), "welcome").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		started, err := repo.StartOnboarding(ctx, 123, "welcome")
+
+		require.NoError(t, err)
+		assert.True(t, started)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - started before", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.StartOnboardingSQL)).
+			WithArgs(int64(123), "welcome").
+			WillReturnResult(pgxmock.NewResult("INSERT", 0))
+
+		started, err := repo.StartOnboarding(ctx, 123, "welcome")
+
+		require.NoError(t, err)
+		assert.False(t, started)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.StartOnboardingSQL)).
+			WithArgs(int64(123), "welcome").
+			WillReturnError(assert.AnError)
+
+		_, err = repo.StartOnboarding(ctx, 123, "welcome")
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdvanceOnboarding(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AdvanceOnboardingSQL)).
+			WithArgs(int64(123), "welcome", "tasks").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		advanced, err := repo.AdvanceOnboarding(ctx, 123, "welcome", "tasks")
+
+		require.NoError(t, err)
+		assert.True(t, advanced)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - not on the step", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AdvanceOnboardingSQL)).
+			WithArgs(int64(123), "welcome", "tasks").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		advanced, err := repo.AdvanceOnboarding(ctx, 123, "welcome", "tasks")
+
+		require.NoError(t, err)
+		assert.False(t, advanced)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.AdvanceOnboardingSQL)).
+			WithArgs(int64(123), "welcome", "tasks").
+			WillReturnError(assert.AnError)
+
+		_, err = repo.AdvanceOnboarding(ctx, 123, "welcome", "tasks")
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCompleteOnboarding(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.CompleteOnboardingSQL)).
+			WithArgs(int64(123)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.CompleteOnboarding(ctx, 123)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.CompleteOnboardingSQL)).
+			WithArgs(int64(123)).
+			WillReturnError(assert.AnError)
+
+		err = repo.CompleteOnboarding(ctx, 123)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetOnCallWeeks saves the weeks of the on-call rota at once, replacing the people on duty
+// in weeks already set.
+func (r *Repository) SetOnCallWeeks(ctx context.Context, weeks []models.OnCallWeek, adminID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // omitted because checking for errors will not affect the function
+
+	for _, week := range weeks {
+		if _, err = tx.Exec(ctx, UpsertOnCallWeekSQL, week.WeekStart, week.TelegramID, adminID); err != nil {
+			return fmt.Errorf("failed to set on-call week %s: %w", week.WeekStart.Format(time.DateOnly), err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit on-call weeks: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteOnCallWeek removes the week from the on-call rota.
+func (r *Repository) DeleteOnCallWeek(ctx context.Context, weekStart time.Time) error {
+	if _, err := r.db.Exec(ctx, DeleteOnCallWeekSQL, weekStart); err != nil {
+		return fmt.Errorf("failed to delete on-call week %s: %w", weekStart.Format(time.DateOnly), err)
+	}
+
+	return nil
+}
+
+// GetOnCallWeeks returns the weeks of the on-call rota which are not over on the day, the
+// current week first. Weeks of logged out users are left out.
+func (r *Repository) GetOnCallWeeks(ctx context.Context, day time.Time, limit int) ([]models.OnCallWeek, error) {
+	rows, err := r.db.Query(ctx, GetOnCallWeeksSQL, day, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get on-call weeks: %w", err)
+	}
+	defer rows.Close()
+
+	var weeks []models.OnCallWeek
+	for rows.Next() {
+		var week models.OnCallWeek
+		if err = rows.Scan(&week.WeekStart, &week.TelegramID, &week.ShortName); err != nil {
+			return nil, fmt.Errorf("failed to scan on-call week row: %w", err)
+		}
+		weeks = append(weeks, week)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return weeks, nil
+}
+
+// GetOnCallWeek returns the week of the on-call rota the day belongs to. The second value is
+// false if nobody is on duty that day, or the user on duty logged out.
+func (r *Repository) GetOnCallWeek(ctx context.Context, day time.Time) (models.OnCallWeek, bool, error) {
+	var week models.OnCallWeek
+	err := r.db.QueryRow(ctx, GetOnCallWeekSQL, day).Scan(&week.WeekStart, &week.TelegramID, &week.ShortName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.OnCallWeek{}, false, nil
+		}
+		return models.OnCallWeek{}, false, fmt.Errorf("failed to get on-call week: %w", err)
+	}
+
+	return week, true, nil
+}
@@ -0,0 +1,233 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOnCallWeeks(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	first := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 7)
+	weeks := []models.OnCallWeek{{WeekStart: first, TelegramID: 111}, {WeekStart: second, TelegramID: 222}}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertOnCallWeekSQL)).
+			WithArgs(first, int64(111), int64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertOnCallWeekSQL)).
+			WithArgs(second, int64(222), int64(1)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.SetOnCallWeeks(ctx, weeks, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertOnCallWeekSQL)).
+			WithArgs(first, int64(111), int64(1)).
+			WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		err = repo.SetOnCallWeeks(ctx, weeks, 1)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to set on-call week 2025-06-02")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteOnCallWeek(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	weekStart := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteOnCallWeekSQL)).
+			WithArgs(weekStart).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, repo.DeleteOnCallWeek(ctx, weekStart))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteOnCallWeekSQL)).
+			WithArgs(weekStart).
+			WillReturnError(assert.AnError)
+
+		err = repo.DeleteOnCallWeek(ctx, weekStart)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to delete on-call week 2025-06-02")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOnCallWeeks(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	day := time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC)
+	first := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	columns := []string{"week_start", "telegram_id", "shortname"}
+
+	t.Run("skips unlinked users", func(t *testing.T) {
+		t.Parallel()
+		// Logged out users stay in the rota during the grace period of the logout.
+		assert.Contains(t, repository.GetOnCallWeeksSQL, "bu.deleted_at IS NULL")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOnCallWeeksSQL)).
+			WithArgs(day, 5).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(first, int64(111), "Doe J.").
+				AddRow(first.AddDate(0, 0, 7), int64(222), ""))
+
+		weeks, err := repo.GetOnCallWeeks(ctx, day, 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.OnCallWeek{
+			{WeekStart: first, TelegramID: 111, ShortName: "Doe J."},
+			{WeekStart: first.AddDate(0, 0, 7), TelegramID: 222},
+		}, weeks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOnCallWeeksSQL)).
+			WithArgs(day, 5).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetOnCallWeeks(ctx, day, 5)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOnCallWeek(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	day := time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC)
+	first := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	columns := []string{"week_start", "telegram_id", "shortname"}
+
+	t.Run("skips unlinked users", func(t *testing.T) {
+		t.Parallel()
+		// Logged out users stay in the rota during the grace period of the logout.
+		assert.Contains(t, repository.GetOnCallWeekSQL, "bu.deleted_at IS NULL")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOnCallWeekSQL)).
+			WithArgs(day).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(first, int64(111), "Doe J."))
+
+		week, ok, err := repo.GetOnCallWeek(ctx, day)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, models.OnCallWeek{WeekStart: first, TelegramID: 111, ShortName: "Doe J."}, week)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nobody on duty", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOnCallWeekSQL)).
+			WithArgs(day).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, ok, err := repo.GetOnCallWeek(ctx, day)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOnCallWeekSQL)).
+			WithArgs(day).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.GetOnCallWeek(ctx, day)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get on-call week")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
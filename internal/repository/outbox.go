@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// EnqueueComment persists the comment in the outbox before it is sent to Hermes.
+// It returns the ID of the outbox entry.
+func (r *Repository) EnqueueComment(ctx context.Context, comment models.OutboxComment) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, EnqueueCommentSQL, comment.TelegramID, comment.TaskID, comment.Author, comment.Text).
+		Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue comment for task %d: %w", comment.TaskID, err)
+	}
+
+	return id, nil
+}
+
+// ClaimDueComments returns up to limit comments whose next delivery attempt is due and
+// postpones them by lease, so they are not picked up again while being delivered.
+func (r *Repository) ClaimDueComments(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+) ([]models.OutboxComment, error) {
+	rows, err := r.db.Query(ctx, ClaimDueCommentsSQL, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []models.OutboxComment
+	for rows.Next() {
+		var comment models.OutboxComment
+		err = rows.Scan(
+			&comment.ID, &comment.TelegramID, &comment.TaskID, &comment.Author, &comment.Text, &comment.Attempts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return comments, nil
+}
+
+// MarkCommentDelivered records that Hermes accepted the comment.
+func (r *Repository) MarkCommentDelivered(ctx context.Context, id int64) error {
+	if _, err := r.db.Exec(ctx, MarkCommentDeliveredSQL, id); err != nil {
+		return fmt.Errorf("failed to mark comment %d as delivered: %w", id, err)
+	}
+
+	return nil
+}
+
+// RescheduleComment records a failed delivery and schedules the next attempt.
+func (r *Repository) RescheduleComment(ctx context.Context, id int64, next time.Time, reason string) error {
+	if _, err := r.db.Exec(ctx, RescheduleCommentSQL, id, next, reason); err != nil {
+		return fmt.Errorf("failed to reschedule comment %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// FailComment records the last failed delivery and stops retrying the comment.
+func (r *Repository) FailComment(ctx context.Context, id int64, reason string) error {
+	if _, err := r.db.Exec(ctx, FailCommentSQL, id, reason); err != nil {
+		return fmt.Errorf("failed to give up on comment %d: %w", id, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnqueueComment(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	comment := models.OutboxComment{TelegramID: 12345, TaskID: 42, Author: "Doe J.", Text: "Done"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.EnqueueCommentSQL)).
+			WithArgs(comment.TelegramID, comment.TaskID, comment.Author, comment.Text).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+		id, err := repo.EnqueueComment(ctx, comment)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert comment", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.EnqueueCommentSQL)).
+			WithArgs(comment.TelegramID, comment.TaskID, comment.Author, comment.Text).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.EnqueueComment(ctx, comment)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to enqueue comment")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimDueComments(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	lease := 2 * time.Minute
+	columns := []string{"id", "telegram_id", "task_id", "author", "text", "attempts"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ClaimDueCommentsSQL)).
+			WithArgs(10, lease).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(int64(7), int64(12345), int64(42), "Doe J.", "Done", 2))
+
+		comments, err := repo.ClaimDueComments(ctx, 10, lease)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.OutboxComment{
+			{ID: 7, TelegramID: 12345, TaskID: 42, Author: "Doe J.", Text: "Done", Attempts: 2},
+		}, comments)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ClaimDueCommentsSQL)).
+			WithArgs(10, lease).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.ClaimDueComments(ctx, 10, lease)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to claim due comments")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ClaimDueCommentsSQL)).
+			WithArgs(10, lease).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow("bad", int64(12345), int64(42), "Doe J.", "Done", 2))
+
+		_, err = repo.ClaimDueComments(ctx, 10, lease)
+
+		require.ErrorContains(t, err, "failed to scan outbox comment")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMarkCommentDelivered(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.MarkCommentDeliveredSQL)).
+			WithArgs(int64(7)).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.MarkCommentDelivered(ctx, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.MarkCommentDeliveredSQL)).
+			WithArgs(int64(7)).
+			WillReturnError(assert.AnError)
+
+		err = repo.MarkCommentDelivered(ctx, 7)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to mark comment 7 as delivered")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRescheduleComment(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	next := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RescheduleCommentSQL)).
+			WithArgs(int64(7), next, "unavailable").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.RescheduleComment(ctx, 7, next, "unavailable"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RescheduleCommentSQL)).
+			WithArgs(int64(7), next, "unavailable").
+			WillReturnError(assert.AnError)
+
+		err = repo.RescheduleComment(ctx, 7, next, "unavailable")
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to reschedule comment 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestFailComment(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.FailCommentSQL)).
+			WithArgs(int64(7), "unavailable").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.FailComment(ctx, 7, "unavailable"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - update", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.FailCommentSQL)).
+			WithArgs(int64(7), "unavailable").
+			WillReturnError(assert.AnError)
+
+		err = repo.FailComment(ctx, 7, "unavailable")
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to give up on comment 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetProfiles returns the employees the user can act as, their own one first.
+func (r *Repository) GetProfiles(ctx context.Context, telegramID int64) ([]models.Profile, error) {
+	rows, err := r.db.Query(ctx, GetProfilesSQL, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profiles: %w", err)
+	}
+	defer rows.Close()
+
+	var profiles []models.Profile
+	for rows.Next() {
+		var profile models.Profile
+		if err = rows.Scan(
+			&profile.EmployeeID, &profile.ShortName, &profile.FullName, &profile.Own, &profile.Active,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan profile row: %w", err)
+		}
+		profiles = append(profiles, profile)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// HasProfiles reports whether the user was granted any employee to act as.
+func (r *Repository) HasProfiles(ctx context.Context, telegramID int64) (bool, error) {
+	var exists bool
+
+	if err := r.db.QueryRow(ctx, HasProfilesSQL, telegramID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check profiles: %w", err)
+	}
+
+	return exists, nil
+}
+
+// SwitchProfile makes the user act as the employee. It reports false if the employee is neither
+// the user's own one nor granted to them.
+func (r *Repository) SwitchProfile(ctx context.Context, telegramID int64, employeeID int) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, SwitchProfileSQL, telegramID, employeeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to switch profile: %w", err)
+	}
+
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// GrantProfile lets the user act as the employee. Granting an employee twice is not an error.
+func (r *Repository) GrantProfile(ctx context.Context, telegramID int64, employeeID int, grantedBy int64) error {
+	if _, err := r.db.Exec(ctx, GrantProfileSQL, telegramID, employeeID, grantedBy); err != nil {
+		return fmt.Errorf("failed to grant profile: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeProfile stops the user from acting as the employee, switching them back to their own
+// employee if they act as it.
+func (r *Repository) RevokeProfile(ctx context.Context, telegramID int64, employeeID int) error {
+	if _, err := r.db.Exec(ctx, RevokeProfileSQL, telegramID, employeeID); err != nil {
+		return fmt.Errorf("failed to revoke profile: %w", err)
+	}
+
+	return nil
+}
+
+// SearchEmployees returns up to limit employees whose short name contains the query, ignoring
+// case. TelegramID is zero for employees not linked to Telegram.
+func (r *Repository) SearchEmployees(ctx context.Context, query string, limit int) ([]models.Teammate, error) {
+	pattern := "%" + likeEscaper.Replace(strings.TrimSpace(query)) + "%"
+	rows, err := r.db.Query(ctx, SearchEmployeesSQL, pattern, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query employees: %w", err)
+	}
+	defer rows.Close()
+
+	var employees []models.Teammate
+	for rows.Next() {
+		var employee models.Teammate
+		if err = rows.Scan(
+			&employee.EmployeeID, &employee.ShortName, &employee.FullName, &employee.TelegramID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan employee row: %w", err)
+		}
+		employees = append(employees, employee)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return employees, nil
+}
@@ -0,0 +1,259 @@
+package repository_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProfiles(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetProfilesSQL)).
+			WithArgs(int64(123)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "shortname", "fullname", "own", "active"}).
+				AddRow(1, "lead", "Team Lead", true, false).
+				AddRow(2, "member", "Team Member", false, true))
+
+		profiles, err := repo.GetProfiles(ctx, 123)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Profile{
+			{EmployeeID: 1, ShortName: "lead", FullName: "Team Lead", Own: true},
+			{EmployeeID: 2, ShortName: "member", FullName: "Team Member", Active: true},
+		}, profiles)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetProfilesSQL)).
+			WithArgs(int64(123)).
+			WillReturnError(errors.New("db error"))
+
+		profiles, err := repo.GetProfiles(ctx, 123)
+
+		require.Error(t, err)
+		assert.Nil(t, profiles)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestHasProfiles(t *testing.T) {
+	t.Parallel()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := repository.NewRepository(mock)
+
+	mock.ExpectQuery(regexp.QuoteMeta(repository.HasProfilesSQL)).
+		WithArgs(int64(123)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+	has, err := repo.HasProfiles(t.Context(), 123)
+
+	require.NoError(t, err)
+	assert.True(t, has)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSwitchProfile(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success - switched", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SwitchProfileSQL)).
+			WithArgs(int64(123), 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		switched, err := repo.SwitchProfile(ctx, 123, 2)
+
+		require.NoError(t, err)
+		assert.True(t, switched)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - not granted", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SwitchProfileSQL)).
+			WithArgs(int64(123), 3).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		switched, err := repo.SwitchProfile(ctx, 123, 3)
+
+		require.NoError(t, err)
+		assert.False(t, switched)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SwitchProfileSQL)).
+			WithArgs(int64(123), 2).
+			WillReturnError(errors.New("db error"))
+
+		_, err = repo.SwitchProfile(ctx, 123, 2)
+
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGrantProfile(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.GrantProfileSQL)).
+			WithArgs(int64(123), 2, int64(7)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.GrantProfile(ctx, 123, 2, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.GrantProfileSQL)).
+			WithArgs(int64(123), 2, int64(7)).
+			WillReturnError(errors.New("db error"))
+
+		require.Error(t, repo.GrantProfile(ctx, 123, 2, 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRevokeProfile(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RevokeProfileSQL)).
+			WithArgs(int64(123), 2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.RevokeProfile(ctx, 123, 2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RevokeProfileSQL)).
+			WithArgs(int64(123), 2).
+			WillReturnError(errors.New("db error"))
+
+		require.Error(t, repo.RevokeProfile(ctx, 123, 2))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSearchEmployees(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SearchEmployeesSQL)).
+			WithArgs("%50\\%%", 10).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "shortname", "fullname", "telegram_id"}).
+				AddRow(2, "50% member", "Team Member", int64(0)))
+
+		employees, err := repo.SearchEmployees(ctx, " 50% ", 10)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Teammate{{EmployeeID: 2, ShortName: "50% member", FullName: "Team Member"}}, employees)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.SearchEmployeesSQL)).
+			WithArgs("%member%", 10).
+			WillReturnError(errors.New("db error"))
+
+		_, err = repo.SearchEmployees(ctx, "member", 10)
+
+		require.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetQuickReplies returns the active quick replies in the order they are shown.
+func (r *Repository) GetQuickReplies(ctx context.Context) ([]models.QuickReply, error) {
+	rows, err := r.db.Query(ctx, GetQuickRepliesSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quick replies: %w", err)
+	}
+	defer rows.Close()
+
+	var replies []models.QuickReply
+	for rows.Next() {
+		var reply models.QuickReply
+		if err = rows.Scan(&reply.ID, &reply.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan quick reply row: %w", err)
+		}
+		replies = append(replies, reply)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return replies, nil
+}
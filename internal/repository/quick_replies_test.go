@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetQuickReplies(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	columns := []string{"id", "text"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetQuickRepliesSQL)).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(1, "Customer not home").
+				AddRow(3, "Completed, signal OK"))
+
+		replies, err := repo.GetQuickReplies(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.QuickReply{
+			{ID: 1, Text: "Customer not home"},
+			{ID: 3, Text: "Completed, signal OK"},
+		}, replies)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetQuickRepliesSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetQuickReplies(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get quick replies")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetQuietHours sets the quiet hours of the user, replacing the previous ones.
+func (r *Repository) SetQuietHours(ctx context.Context, telegramID int64, start, end int) error {
+	if _, err := r.db.Exec(ctx, UpsertQuietHoursSQL, telegramID, start, end); err != nil {
+		return fmt.Errorf("failed to set quiet hours of user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// ClearQuietHours turns the quiet hours of the user off.
+func (r *Repository) ClearQuietHours(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, DeleteQuietHoursSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to clear quiet hours of user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// GetQuietHours returns the quiet hours of the user in the time zone of their digest.
+// The second value is false if the user has no quiet hours.
+func (r *Repository) GetQuietHours(ctx context.Context, telegramID int64) (models.QuietHours, bool, error) {
+	quietHours, err := scanQuietHours(r.db.QueryRow(ctx, GetQuietHoursSQL, telegramID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.QuietHours{}, false, nil
+		}
+		return models.QuietHours{}, false, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+
+	return quietHours, true, nil
+}
+
+// GetAllQuietHours returns the quiet hours of all users who set them.
+func (r *Repository) GetAllQuietHours(ctx context.Context) ([]models.QuietHours, error) {
+	rows, err := r.db.Query(ctx, GetAllQuietHoursSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+	defer rows.Close()
+
+	var all []models.QuietHours
+	for rows.Next() {
+		quietHours, errScan := scanQuietHours(rows)
+		if errScan != nil {
+			return nil, fmt.Errorf("failed to scan quiet hours row: %w", errScan)
+		}
+		all = append(all, quietHours)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return all, nil
+}
+
+// DeferMessage stores a message to be delivered to the user at deliverAt.
+func (r *Repository) DeferMessage(
+	ctx context.Context,
+	telegramID int64,
+	source string,
+	payload []byte,
+	deliverAt time.Time,
+) error {
+	if _, err := r.db.Exec(ctx, DeferMessageSQL, telegramID, source, payload, deliverAt); err != nil {
+		return fmt.Errorf("failed to defer message to user %d: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// ClaimDueMessages removes and returns up to limit deferred messages whose delivery time has
+// passed, oldest first. A message is claimed once, so it is not delivered twice by replicas.
+func (r *Repository) ClaimDueMessages(ctx context.Context, limit int) ([]models.DeferredMessage, error) {
+	rows, err := r.db.Query(ctx, ClaimDueMessagesSQL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim deferred messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []models.DeferredMessage
+	for rows.Next() {
+		var message models.DeferredMessage
+		err = rows.Scan(&message.ID, &message.TelegramID, &message.Source, &message.Payload, &message.DeliverAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deferred message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return messages, nil
+}
+
+// scanQuietHours reads quiet hours from a single row.
+func scanQuietHours(row pgx.Row) (models.QuietHours, error) {
+	var quietHours models.QuietHours
+	err := row.Scan(&quietHours.TelegramID, &quietHours.Start, &quietHours.End, &quietHours.Timezone)
+	return quietHours, err
+}
@@ -0,0 +1,298 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetQuietHours(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertQuietHoursSQL)).
+			WithArgs(telegramID, 1200, 480).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.SetQuietHours(ctx, telegramID, 1200, 480))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - set", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.UpsertQuietHoursSQL)).
+			WithArgs(telegramID, 1200, 480).
+			WillReturnError(assert.AnError)
+
+		err = repo.SetQuietHours(ctx, telegramID, 1200, 480)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to set quiet hours of user 12345")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClearQuietHours(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteQuietHoursSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, repo.ClearQuietHours(ctx, telegramID))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - clear", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteQuietHoursSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		err = repo.ClearQuietHours(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to clear quiet hours of user 12345")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetQuietHours(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetQuietHoursSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id", "start_minute", "end_minute", "timezone"}).
+				AddRow(telegramID, 1200, 480, "Europe/Kyiv"))
+
+		quietHours, ok, err := repo.GetQuietHours(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, models.QuietHours{
+			TelegramID: telegramID, Start: 1200, End: 480, Timezone: "Europe/Kyiv",
+		}, quietHours)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetQuietHoursSQL)).
+			WithArgs(telegramID).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, ok, err := repo.GetQuietHours(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetQuietHoursSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.GetQuietHours(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get quiet hours")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetAllQuietHours(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllQuietHoursSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id", "start_minute", "end_minute", "timezone"}).
+				AddRow(int64(1), 1200, 480, "Europe/Kyiv").
+				AddRow(int64(2), 1320, 420, ""))
+
+		all, err := repo.GetAllQuietHours(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.QuietHours{
+			{TelegramID: 1, Start: 1200, End: 480, Timezone: "Europe/Kyiv"},
+			{TelegramID: 2, Start: 1320, End: 420},
+		}, all)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllQuietHoursSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetAllQuietHours(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeferMessage(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	payload := []byte(`{"Text":"hello"}`)
+	deliverAt := time.Date(2025, 5, 6, 8, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeferMessageSQL)).
+			WithArgs(telegramID, "digest", payload, deliverAt).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.DeferMessage(ctx, telegramID, "digest", payload, deliverAt))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - insert", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeferMessageSQL)).
+			WithArgs(telegramID, "digest", payload, deliverAt).
+			WillReturnError(assert.AnError)
+
+		err = repo.DeferMessage(ctx, telegramID, "digest", payload, deliverAt)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to defer message to user 12345")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaimDueMessages(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	deliverAt := time.Date(2025, 5, 6, 8, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ClaimDueMessagesSQL)).
+			WithArgs(50).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "telegram_id", "source", "payload", "deliver_at"}).
+				AddRow(int64(7), int64(12345), "broadcast", []byte(`{}`), deliverAt))
+
+		messages, err := repo.ClaimDueMessages(ctx, 50)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.DeferredMessage{
+			{ID: 7, TelegramID: 12345, Source: "broadcast", Payload: []byte(`{}`), DeliverAt: deliverAt},
+		}, messages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.ClaimDueMessagesSQL)).
+			WithArgs(50).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.ClaimDueMessages(ctx, 50)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to claim deferred messages")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
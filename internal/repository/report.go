@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetCompletedTaskCounts returns the number of tasks of each type completed by the user within
+// the given period. Unlike GetTaskSummary, it only counts closed tasks and has no total row,
+// so the counts match the tasks included in the user's report.
+func (r *Repository) GetCompletedTaskCounts(
+	ctx context.Context,
+	telegramID int64,
+	from, to time.Time,
+) ([]models.TaskSummary, error) {
+	rows, err := r.reader().Query(ctx, GetCompletedTaskCountsSQL, telegramID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed task counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []models.TaskSummary
+	for rows.Next() {
+		var count models.TaskSummary
+		if err = rows.Scan(&count.Type, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan completed task count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetReportTaskTypes returns the types of tasks the user closed within the given period,
+// ordered by name, each marked whether the user excluded it from reports.
+func (r *Repository) GetReportTaskTypes(
+	ctx context.Context,
+	telegramID int64,
+	from, to time.Time,
+) ([]models.ReportTaskType, error) {
+	rows, err := r.reader().Query(ctx, GetReportTaskTypesSQL, telegramID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query report task types: %w", err)
+	}
+	defer rows.Close()
+
+	var types []models.ReportTaskType
+	for rows.Next() {
+		var taskType models.ReportTaskType
+		if err = rows.Scan(&taskType.ID, &taskType.Name, &taskType.Excluded); err != nil {
+			return nil, fmt.Errorf("failed to scan report task type: %w", err)
+		}
+		types = append(types, taskType)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return types, nil
+}
+
+// GetExcludedReportTypes returns the names of the task types the user left out of reports.
+func (r *Repository) GetExcludedReportTypes(ctx context.Context, telegramID int64) ([]string, error) {
+	rows, err := r.db.Query(ctx, GetExcludedReportTypesSQL, telegramID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query excluded report types: %w", err)
+	}
+
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan excluded report type: %w", err)
+		}
+		names = append(names, name)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return names, nil
+}
+
+// ToggleReportType excludes the task type from the user's reports, or includes it again
+// if it was excluded.
+func (r *Repository) ToggleReportType(ctx context.Context, telegramID int64, typeID int) error {
+	if _, err := r.db.Exec(ctx, ToggleReportTypeSQL, telegramID, typeID); err != nil {
+		return fmt.Errorf("failed to toggle report type %d of user %d: %w", typeID, telegramID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCompletedTaskCounts(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("error - query counts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTaskCountsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetCompletedTaskCounts(ctx, telegramID, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query completed task counts")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan counts", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTaskCountsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(pgxmock.NewRows([]string{"task_type", "count"}).AddRow("Repair", "invalid"))
+
+		_, err = repo.GetCompletedTaskCounts(ctx, telegramID, from, to)
+
+		require.ErrorContains(t, err, "failed to scan completed task count")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTaskCountsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_type", "count"}).
+					AddRow("Connection", 4).
+					AddRow("Repair", 9),
+			)
+
+		counts, err := repo.GetCompletedTaskCounts(ctx, telegramID, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.TaskSummary{{Type: "Connection", Count: 4}, {Type: "Repair", Count: 9}}, counts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetReportTaskTypes(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("error - query types", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetReportTaskTypesSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetReportTaskTypes(ctx, telegramID, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query report task types")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetReportTaskTypesSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"type_id", "type_name", "excluded"}).
+					AddRow(2, "Connection", false).
+					AddRow(1, "Repair", true),
+			)
+
+		types, err := repo.GetReportTaskTypes(ctx, telegramID, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.ReportTaskType{
+			{ID: 2, Name: "Connection"},
+			{ID: 1, Name: "Repair", Excluded: true},
+		}, types)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetExcludedReportTypes(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("error - query types", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetExcludedReportTypesSQL)).
+			WithArgs(telegramID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetExcludedReportTypes(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetExcludedReportTypesSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"type_name"}).AddRow("Repair"))
+
+		names, err := repo.GetExcludedReportTypes(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Repair"}, names)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestToggleReportType(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("error - toggle", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.ToggleReportTypeSQL)).
+			WithArgs(telegramID, 3).
+			WillReturnError(assert.AnError)
+
+		err = repo.ToggleReportType(ctx, telegramID, 3)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to toggle report type 3")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.ToggleReportTypeSQL)).
+			WithArgs(telegramID, 3).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.ToggleReportType(ctx, telegramID, 3))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/featureflags"
 	"github.com/UnknownOlympus/oracle/internal/models"
 )
 
+// Repository implements the bot's data access. Writes and most reads go to the primary
+// database, while heavy read queries (reports, statistics and nearby tasks) go to the
+// read replica when one is configured.
 type Repository struct {
-	db Database
+	db      Database
+	replica Database
+	postgis atomic.Bool
 }
 
 // BotManager defines the interface for repository operations related to user authentication
@@ -16,27 +23,218 @@ type Repository struct {
 // authentication status, and deleting a user by their Telegram ID.
 type BotManager interface {
 	LinkTelegramIDByEmail(ctx context.Context, telegramID int64, email string) error
+	CheckEmailLink(ctx context.Context, telegramID int64, email string) error
 	IsUserAuthenticated(ctx context.Context, telegramID int64) (bool, error)
-	DeleteUserByID(ctx context.Context, telegramID int64) error
+	UnlinkUser(ctx context.Context, telegramID int64) error
+	RestoreUser(ctx context.Context, telegramID int64, since time.Time) (bool, error)
+	PurgeUnlinkedUsers(ctx context.Context, before time.Time) (int64, error)
 	IsAdmin(ctx context.Context, telegramID int64) (bool, error)
 	GetAllTgUserIDs(ctx context.Context) ([]int64, error)
 	GetAdmins(ctx context.Context) ([]models.BotUser, error)
 	SetUserLanguage(ctx context.Context, telegramID int64, langCode string) error
 	GetUserLanguage(ctx context.Context, telegramID int64) (string, error)
+	GetUserLanguages(ctx context.Context, telegramIDs []int64) (map[int64]string, error)
 }
 
 // TaskManager defines the interface for repository operations related to task management.
 // It includes methods for get employee, get tasks with different status, etc.
 type TaskManager interface {
 	GetEmployee(ctx context.Context, telegramID int64) (models.Employee, error)
+	GetEmployeeByID(ctx context.Context, employeeID int) (models.Employee, error)
 	GetTaskSummary(ctx context.Context, telegramID int64, startDate, endDate time.Time) ([]models.TaskSummary, error)
+	GetTaskSummaryByEmployee(ctx context.Context, employeeID int, startDate, endDate time.Time) (
+		[]models.TaskSummary, error,
+	)
+	GetTaskDurations(ctx context.Context, telegramID int64, startDate, endDate time.Time) ([]models.TaskDuration, error)
+	GetTeamTaskSummary(ctx context.Context, startDate, endDate time.Time) ([]models.EmployeeTaskSummary, error)
+	GetTaskExecutorTelegramIDs(ctx context.Context, taskID int) ([]int64, error)
+	GetEmployeePerformance(ctx context.Context, startDate, endDate time.Time) ([]models.EmployeePerformance, error)
 	GetActiveTasksByExecutor(ctx context.Context, telegramID int64) ([]models.ActiveTask, error)
+	GetActiveTasksByEmployee(ctx context.Context, employeeID int) ([]models.ActiveTask, error)
+	GetActiveTasksByExecutorFiltered(
+		ctx context.Context,
+		telegramID int64,
+		filter models.ActiveTaskFilter,
+	) ([]models.ActiveTask, error)
 	GetTaskDetailsByID(ctx context.Context, taskID int) (*models.TaskDetails, error)
 	GetCompletedTasksByExecutor(ctx context.Context, telegramID int64, from, to time.Time) ([]models.TaskDetails, error)
 	GetTasksInRadius(ctx context.Context, lat, lng float32, radius int) ([]models.ActiveTask, error)
 	GetCustomersByTaskID(ctx context.Context, taskID int64) ([]models.Customer, error)
+	GetCustomersForTaskIDs(ctx context.Context, taskIDs []int64) (map[int64][]models.Customer, error)
 	GetGeocodingIssues(ctx context.Context) ([]models.GeocodingIssue, error)
 	ResetGeocodingErrors(ctx context.Context) (int64, error)
+	SetTaskLocation(ctx context.Context, taskID int, lat, lng float64) error
+}
+
+// SubscriptionManager defines the interface for repository operations related to automatic
+// report subscriptions.
+type SubscriptionManager interface {
+	SubscribeToReports(ctx context.Context, telegramID int64) error
+	UnsubscribeFromReports(ctx context.Context, telegramID int64) error
+	IsSubscribedToReports(ctx context.Context, telegramID int64) (bool, error)
+	GetReportSubscribers(ctx context.Context) ([]int64, error)
+}
+
+// ReportManager defines the interface for repository operations used to build reports.
+type ReportManager interface {
+	GetCompletedTaskCounts(ctx context.Context, telegramID int64, from, to time.Time) ([]models.TaskSummary, error)
+	GetReportTaskTypes(ctx context.Context, telegramID int64, from, to time.Time) ([]models.ReportTaskType, error)
+	GetExcludedReportTypes(ctx context.Context, telegramID int64) ([]string, error)
+	ToggleReportType(ctx context.Context, telegramID int64, typeID int) error
+}
+
+// ReportArchiveManager defines the interface for repository operations used to keep generated
+// reports for downloading them again.
+type ReportArchiveManager interface {
+	ArchiveReport(ctx context.Context, report models.ArchivedReport, content []byte, keep int) (int64, []string, error)
+	SetArchivedReportFileID(ctx context.Context, id int64, fileID string) error
+	GetArchivedReports(ctx context.Context, telegramID int64, limit int) ([]models.ArchivedReport, error)
+	GetArchivedReport(ctx context.Context, telegramID, id int64) (models.ArchivedReport, error)
+	GetArchivedReportContent(ctx context.Context, telegramID, id int64) ([]byte, error)
+}
+
+// SLAManager defines the interface for repository operations used to configure the service
+// level of task types.
+type SLAManager interface {
+	GetSLAConfig(ctx context.Context) ([]models.TaskTypeSLA, error)
+	SetTaskTypeSLA(ctx context.Context, typeID, hours int) error
+}
+
+// HandoverManager defines the interface for repository operations used to find teammates by
+// their short name.
+type HandoverManager interface {
+	SearchTeammates(ctx context.Context, telegramID int64, query string, limit int) ([]models.Teammate, error)
+}
+
+// OnboardingManager defines the interface for repository operations used to track the guided
+// tour of new users.
+type OnboardingManager interface {
+	StartOnboarding(ctx context.Context, telegramID int64, step string) (bool, error)
+	AdvanceOnboarding(ctx context.Context, telegramID int64, from, to string) (bool, error)
+	CompleteOnboarding(ctx context.Context, telegramID int64) error
+}
+
+// ProfileManager defines the interface for repository operations used to let team leads act as
+// other employees.
+type ProfileManager interface {
+	GetProfiles(ctx context.Context, telegramID int64) ([]models.Profile, error)
+	HasProfiles(ctx context.Context, telegramID int64) (bool, error)
+	SwitchProfile(ctx context.Context, telegramID int64, employeeID int) (bool, error)
+	GrantProfile(ctx context.Context, telegramID int64, employeeID int, grantedBy int64) error
+	RevokeProfile(ctx context.Context, telegramID int64, employeeID int) error
+	SearchEmployees(ctx context.Context, query string, limit int) ([]models.Teammate, error)
+}
+
+// FeatureFlagManager defines the interface for repository operations used to store the feature
+// flags set by admins.
+type FeatureFlagManager interface {
+	featureflags.Store
+}
+
+// TaskLocationManager defines the interface for repository operations used to export
+// task locations as a map.
+type TaskLocationManager interface {
+	GetActiveTaskLocations(ctx context.Context, telegramID int64) ([]models.TaskLocation, error)
+}
+
+// DeliveryManager defines the interface for repository operations related to messages
+// that could not be delivered.
+type DeliveryManager interface {
+	RecordSendFailure(
+		ctx context.Context, telegramID int64, source, reason string, permanent bool, sendErr string,
+	) error
+}
+
+// BroadcastManager defines the interface for repository operations related to auditing
+// admin broadcasts and to their templates.
+type BroadcastManager interface {
+	CreateBroadcast(ctx context.Context, adminID int64, kind string, total int, requiresAck bool) (int64, error)
+	UpdateBroadcastProgress(ctx context.Context, id int64, sent, failed int) error
+	FinishBroadcast(ctx context.Context, id int64, sent, failed int, status string) error
+	AddBroadcastRecipient(ctx context.Context, broadcastID, telegramID int64) error
+	AcknowledgeBroadcast(ctx context.Context, broadcastID, telegramID int64) (bool, error)
+	GetBroadcastReceipts(ctx context.Context, broadcastID int64) ([]models.BroadcastReceipt, error)
+	SaveBroadcastTemplate(ctx context.Context, name, text string, adminID int64) (int, error)
+	GetBroadcastTemplates(ctx context.Context) ([]models.BroadcastTemplate, error)
+	GetBroadcastTemplate(ctx context.Context, id int) (models.BroadcastTemplate, error)
+	DeleteBroadcastTemplate(ctx context.Context, id int) error
+}
+
+// AuditManager defines the interface for repository operations related to the admin audit log.
+type AuditManager interface {
+	RecordAdminAction(ctx context.Context, adminID int64, action string, payload []byte) error
+	GetAdminActions(ctx context.Context, limit, offset int) ([]models.AdminAction, int, error)
+}
+
+// ActivityManager defines the interface for repository operations related to tracking
+// when users last interacted with the bot.
+type ActivityManager interface {
+	UpdateLastSeen(ctx context.Context, telegramID int64) error
+	GetInactiveUsers(ctx context.Context, since time.Time) ([]models.InactiveUser, error)
+	GetUserCounts(ctx context.Context) (models.UserCounts, error)
+}
+
+// DigestManager defines the interface for repository operations related to the morning
+// digest of open tasks.
+type DigestManager interface {
+	SubscribeToDigest(ctx context.Context, telegramID int64, timezone string) error
+	UnsubscribeFromDigest(ctx context.Context, telegramID int64) error
+	GetDigestSubscription(ctx context.Context, telegramID int64) (models.DigestSubscription, bool, error)
+	GetDigestSubscribers(ctx context.Context) ([]models.DigestSubscription, error)
+	MarkDigestSent(ctx context.Context, telegramID int64, day time.Time) error
+	GetDigestTasks(ctx context.Context, telegramID int64) ([]models.DigestTask, error)
+}
+
+// OnCallManager defines the interface for repository operations related to the on-call rota.
+type OnCallManager interface {
+	SetOnCallWeeks(ctx context.Context, weeks []models.OnCallWeek, adminID int64) error
+	DeleteOnCallWeek(ctx context.Context, weekStart time.Time) error
+	GetOnCallWeeks(ctx context.Context, day time.Time, limit int) ([]models.OnCallWeek, error)
+	GetOnCallWeek(ctx context.Context, day time.Time) (models.OnCallWeek, bool, error)
+}
+
+// ShiftManager defines the interface for repository operations related to the work shifts.
+type ShiftManager interface {
+	StartShift(ctx context.Context, telegramID int64) (int64, error)
+	EndShift(ctx context.Context, telegramID int64) (int64, bool, error)
+	SetShiftLocation(ctx context.Context, id int64, end bool, latitude, longitude float64) error
+	GetOpenShift(ctx context.Context, telegramID int64) (models.Shift, bool, error)
+	GetShifts(ctx context.Context, from, to time.Time) ([]models.Shift, error)
+}
+
+// MaterialManager defines the interface for repository operations related to the materials used
+// on tasks.
+type MaterialManager interface {
+	GetMaterials(ctx context.Context) ([]models.Material, error)
+	AddTaskMaterial(ctx context.Context, taskID, materialID int, quantity float64, telegramID int64) error
+	GetTaskMaterials(ctx context.Context, taskIDs []int64) (map[int64][]models.TaskMaterial, error)
+}
+
+// QuickReplyManager defines the interface for repository operations related to the quick replies
+// offered when commenting a task.
+type QuickReplyManager interface {
+	GetQuickReplies(ctx context.Context) ([]models.QuickReply, error)
+}
+
+// QuietHoursManager defines the interface for repository operations related to the quiet hours
+// of users and the messages postponed by them.
+type QuietHoursManager interface {
+	SetQuietHours(ctx context.Context, telegramID int64, start, end int) error
+	ClearQuietHours(ctx context.Context, telegramID int64) error
+	GetQuietHours(ctx context.Context, telegramID int64) (models.QuietHours, bool, error)
+	GetAllQuietHours(ctx context.Context) ([]models.QuietHours, error)
+	DeferMessage(ctx context.Context, telegramID int64, source string, payload []byte, deliverAt time.Time) error
+	ClaimDueMessages(ctx context.Context, limit int) ([]models.DeferredMessage, error)
+}
+
+// OutboxManager defines the interface for repository operations related to the outbox of
+// comments waiting to be delivered to Hermes.
+type OutboxManager interface {
+	EnqueueComment(ctx context.Context, comment models.OutboxComment) (int64, error)
+	ClaimDueComments(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxComment, error)
+	MarkCommentDelivered(ctx context.Context, id int64) error
+	RescheduleComment(ctx context.Context, id int64, next time.Time, reason string) error
+	FailComment(ctx context.Context, id int64, reason string) error
 }
 
 // NewRepository creates a new instance of Repository with the provided Database.
@@ -44,3 +242,25 @@ type TaskManager interface {
 func NewRepository(db Database) *Repository {
 	return &Repository{db: db}
 }
+
+// NewRepositoryWithReplica creates a new instance of Repository which sends heavy read
+// queries to the replica and everything else to the primary database.
+func NewRepositoryWithReplica(primary, replica Database) *Repository {
+	return &Repository{db: primary, replica: replica}
+}
+
+// EnablePostGIS makes GetTasksInRadius use the PostGIS geography index. If PostGIS turns out
+// to be unavailable, the repository falls back to the haversine query.
+func (r *Repository) EnablePostGIS() {
+	r.postgis.Store(true)
+}
+
+// reader returns the database used for heavy read queries: the replica if configured,
+// the primary database otherwise. Replicas may lag behind the primary, so queries which
+// must see the user's latest writes should use r.db instead.
+func (r *Repository) reader() Database {
+	if r.replica != nil {
+		return r.replica
+	}
+	return r.db
+}
@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryWithReplica(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("heavy reads go to the replica", func(t *testing.T) {
+		t.Parallel()
+		primary, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer primary.Close()
+		replica, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer replica.Close()
+
+		repo := repository.NewRepositoryWithReplica(primary, replica)
+
+		replica.ExpectQuery(regexp.QuoteMeta(repository.GetTaskSummarySQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(pgxmock.NewRows([]string{"task_type", "count"}).AddRow("Repair", 2))
+
+		summaries, err := repo.GetTaskSummary(ctx, telegramID, from, to)
+
+		require.NoError(t, err)
+		assert.Len(t, summaries, 1)
+		assert.NoError(t, replica.ExpectationsWereMet())
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+
+	t.Run("writes go to the primary", func(t *testing.T) {
+		t.Parallel()
+		primary, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer primary.Close()
+		replica, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer replica.Close()
+
+		repo := repository.NewRepositoryWithReplica(primary, replica)
+
+		primary.ExpectExec(regexp.QuoteMeta(repository.InsertReportSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.SubscribeToReports(ctx, telegramID))
+		assert.NoError(t, primary.ExpectationsWereMet())
+		assert.NoError(t, replica.ExpectationsWereMet())
+	})
+}
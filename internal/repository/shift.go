@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StartShift starts a shift of the user now and returns its ID.
+func (r *Repository) StartShift(ctx context.Context, telegramID int64) (int64, error) {
+	var id int64
+	if err := r.db.QueryRow(ctx, StartShiftSQL, telegramID).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to start shift of user %d: %w", telegramID, err)
+	}
+
+	return id, nil
+}
+
+// EndShift ends the open shift of the user now and returns its ID. The second value is false
+// if the user has no open shift.
+func (r *Repository) EndShift(ctx context.Context, telegramID int64) (int64, bool, error) {
+	var id int64
+	if err := r.db.QueryRow(ctx, EndShiftSQL, telegramID).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to end shift of user %d: %w", telegramID, err)
+	}
+
+	return id, true, nil
+}
+
+// SetShiftLocation records where the shift started, or where it ended if end is true.
+func (r *Repository) SetShiftLocation(ctx context.Context, id int64, end bool, latitude, longitude float64) error {
+	query := SetShiftStartLocationSQL
+	if end {
+		query = SetShiftEndLocationSQL
+	}
+	if _, err := r.db.Exec(ctx, query, id, latitude, longitude); err != nil {
+		return fmt.Errorf("failed to set location of shift %d: %w", id, err)
+	}
+
+	return nil
+}
+
+// GetOpenShift returns the open shift of the user. The second value is false if the user is
+// not on shift.
+func (r *Repository) GetOpenShift(ctx context.Context, telegramID int64) (models.Shift, bool, error) {
+	shift, err := scanShift(r.db.QueryRow(ctx, GetOpenShiftSQL, telegramID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Shift{}, false, nil
+		}
+		return models.Shift{}, false, fmt.Errorf("failed to get open shift of user %d: %w", telegramID, err)
+	}
+
+	return shift, true, nil
+}
+
+// GetShifts returns the shifts started within the period, ordered by employee and start.
+func (r *Repository) GetShifts(ctx context.Context, from, to time.Time) ([]models.Shift, error) {
+	rows, err := r.db.Query(ctx, GetShiftsSQL, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shifts: %w", err)
+	}
+	defer rows.Close()
+
+	var shifts []models.Shift
+	for rows.Next() {
+		shift, errScan := scanShift(rows)
+		if errScan != nil {
+			return nil, fmt.Errorf("failed to scan shift row: %w", errScan)
+		}
+		shifts = append(shifts, shift)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return shifts, nil
+}
+
+func scanShift(row pgx.Row) (models.Shift, error) {
+	var shift models.Shift
+	var endedAt pgtype.Timestamptz
+	err := row.Scan(
+		&shift.ID, &shift.TelegramID, &shift.ShortName,
+		&shift.StartedAt, &shift.StartLatitude, &shift.StartLongitude,
+		&endedAt, &shift.EndLatitude, &shift.EndLongitude,
+	)
+	if endedAt.Valid {
+		shift.EndedAt = endedAt.Time
+	}
+
+	return shift, err
+}
@@ -0,0 +1,284 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var shiftColumns = []string{
+	"id", "telegram_id", "shortname",
+	"started_at", "start_latitude", "start_longitude", "ended_at", "end_latitude", "end_longitude",
+}
+
+func TestStartShift(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.StartShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+		id, err := repo.StartShift(ctx, 111)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.StartShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.StartShift(ctx, 111)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to start shift of user 111")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestEndShift(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.EndShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+		id, ok, err := repo.EndShift(ctx, 111)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(7), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not on shift", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.EndShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, ok, err := repo.EndShift(ctx, 111)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.EndShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.EndShift(ctx, 111)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetShiftLocation(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("start", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetShiftStartLocationSQL)).
+			WithArgs(int64(7), 50.45, 30.52).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		require.NoError(t, repo.SetShiftLocation(ctx, 7, false, 50.45, 30.52))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("end", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetShiftEndLocationSQL)).
+			WithArgs(int64(7), 50.45, 30.52).
+			WillReturnError(assert.AnError)
+
+		err = repo.SetShiftLocation(ctx, 7, true, 50.45, 30.52)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to set location of shift 7")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOpenShift(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	started := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOpenShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnRows(pgxmock.NewRows(shiftColumns).
+				AddRow(int64(7), int64(111), "Doe J.", started,
+					pgtype.Float8{}, pgtype.Float8{}, pgtype.Timestamptz{}, pgtype.Float8{}, pgtype.Float8{}))
+
+		shift, ok, err := repo.GetOpenShift(ctx, 111)
+
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, models.Shift{ID: 7, TelegramID: 111, ShortName: "Doe J.", StartedAt: started}, shift)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not on shift", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOpenShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnError(pgx.ErrNoRows)
+
+		_, ok, err := repo.GetOpenShift(ctx, 111)
+
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetOpenShiftSQL)).
+			WithArgs(int64(111)).
+			WillReturnError(assert.AnError)
+
+		_, _, err = repo.GetOpenShift(ctx, 111)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetShifts(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	started := time.Date(2025, 6, 2, 8, 0, 0, 0, time.UTC)
+	ended := started.Add(8 * time.Hour)
+	latitude := pgtype.Float8{Float64: 50.45, Valid: true}
+	longitude := pgtype.Float8{Float64: 30.52, Valid: true}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetShiftsSQL)).
+			WithArgs(from, to).
+			WillReturnRows(pgxmock.NewRows(shiftColumns).
+				AddRow(int64(7), int64(111), "Doe J.", started, latitude, longitude,
+					pgtype.Timestamptz{Time: ended, Valid: true}, pgtype.Float8{}, pgtype.Float8{}))
+
+		shifts, err := repo.GetShifts(ctx, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.Shift{{
+			ID: 7, TelegramID: 111, ShortName: "Doe J.", StartedAt: started, EndedAt: ended,
+			StartLatitude: latitude, StartLongitude: longitude,
+		}}, shifts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetShiftsSQL)).
+			WithArgs(from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetShifts(ctx, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get shifts")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+)
+
+// GetSLAConfig returns every task type with its SLA, ordered by the type name.
+func (r *Repository) GetSLAConfig(ctx context.Context) ([]models.TaskTypeSLA, error) {
+	rows, err := r.db.Query(ctx, GetSLAConfigSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sla config: %w", err)
+	}
+	defer rows.Close()
+
+	var config []models.TaskTypeSLA
+	for rows.Next() {
+		var sla models.TaskTypeSLA
+		if err = rows.Scan(&sla.TypeID, &sla.TypeName, &sla.Hours); err != nil {
+			return nil, fmt.Errorf("failed to scan sla config row: %w", err)
+		}
+		config = append(config, sla)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return config, nil
+}
+
+// SetTaskTypeSLA sets the SLA of the task type in hours. Zero hours remove the SLA.
+func (r *Repository) SetTaskTypeSLA(ctx context.Context, typeID, hours int) error {
+	var err error
+	if hours > 0 {
+		_, err = r.db.Exec(ctx, SetTaskTypeSLASQL, typeID, hours)
+	} else {
+		_, err = r.db.Exec(ctx, DeleteTaskTypeSLASQL, typeID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set sla of task type %d: %w", typeID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/models"
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSLAConfig(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetSLAConfigSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"type_id", "type_name", "sla_hours"}).
+				AddRow(1, "Connection", 48).
+				AddRow(2, "Repair", 0))
+
+		config, err := repo.GetSLAConfig(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.TaskTypeSLA{
+			{TypeID: 1, TypeName: "Connection", Hours: 48},
+			{TypeID: 2, TypeName: "Repair"},
+		}, config)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetSLAConfigSQL)).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetSLAConfig(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetTaskTypeSLA(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("success - set", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetTaskTypeSLASQL)).
+			WithArgs(1, 48).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		require.NoError(t, repo.SetTaskTypeSLA(ctx, 1, 48))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - remove", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteTaskTypeSLASQL)).
+			WithArgs(1).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		require.NoError(t, repo.SetTaskTypeSLA(ctx, 1, 0))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - set", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetTaskTypeSLASQL)).
+			WithArgs(1, 48).
+			WillReturnError(assert.AnError)
+
+		err = repo.SetTaskTypeSLA(ctx, 1, 48)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to set sla of task type 1")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -7,7 +7,7 @@ SELECT
 FROM
     task_executors te
 JOIN
-    bot_users bu ON te.executor_id = bu.employee_id
+    bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
 JOIN
     tasks t ON te.task_id = t.task_id
 JOIN
@@ -27,7 +27,7 @@ SELECT
 FROM
     task_executors te
 JOIN
-    bot_users bu ON te.executor_id = bu.employee_id
+    bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
 JOIN
     tasks t ON te.task_id = t.task_id
 WHERE
@@ -37,3 +37,1051 @@ WHERE
 ORDER BY
     "count" ASC;
 `
+
+const GetTaskDurationsSQL = `
+SELECT
+    tt.type_name AS "task_type",
+    avg(d.seconds)::bigint AS "avg_seconds",
+    (percentile_cont(0.5) WITHIN GROUP (ORDER BY d.seconds))::bigint AS "median_seconds"
+FROM (
+    SELECT DISTINCT
+        t.task_id,
+        t.task_type_id,
+        GREATEST(EXTRACT(EPOCH FROM (t.closing_date - t.creation_date)), 0) AS seconds
+    FROM
+        task_executors te
+    JOIN
+        bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+    JOIN
+        tasks t ON te.task_id = t.task_id
+    WHERE
+        bu.telegram_id = $1
+        AND t.closing_date >= $2
+        AND t.closing_date <= $3
+        AND t.is_closed = TRUE
+) d
+JOIN
+    task_types tt ON d.task_type_id = tt.type_id
+GROUP BY
+    tt.type_name
+ORDER BY
+    tt.type_name ASC;
+`
+
+// GetTaskExecutorTelegramIDsSQL returns the users who execute the task $1 themselves or act as
+// one of its executors.
+const GetTaskExecutorTelegramIDsSQL = `
+SELECT DISTINCT
+    bu.telegram_id
+FROM
+    task_executors te
+JOIN
+    bot_users bu ON te.executor_id IN (bu.employee_id, bu.acting_employee_id) AND bu.deleted_at IS NULL
+WHERE
+    te.task_id = $1;
+`
+
+const GetTeamTaskSummarySQL = `
+SELECT
+    e.id AS "employee_id",
+    e.shortname AS "shortname",
+    count(*) AS "count"
+FROM
+    task_executors te
+JOIN
+    employees e ON te.executor_id = e.id
+JOIN
+    tasks t ON te.task_id = t.task_id
+WHERE
+    t.closing_date >= $1
+    AND t.closing_date <= $2
+GROUP BY
+    e.id, e.shortname
+ORDER BY
+    "count" DESC, e.shortname ASC;
+`
+
+const GetEmployeePerformanceSQL = `
+SELECT
+    e.id AS "employee_id",
+    e.shortname AS "shortname",
+    count(DISTINCT t.task_id) AS "count",
+    COALESCE(avg(GREATEST(EXTRACT(EPOCH FROM (t.closing_date - t.creation_date)), 0)), 0)::bigint AS "avg_seconds"
+FROM
+    task_executors te
+JOIN
+    employees e ON te.executor_id = e.id
+JOIN
+    tasks t ON te.task_id = t.task_id
+WHERE
+    t.closing_date >= $1
+    AND t.closing_date <= $2
+    AND t.is_closed = TRUE
+GROUP BY
+    e.id, e.shortname
+ORDER BY
+    "count" DESC, "avg_seconds" ASC, e.shortname ASC;
+`
+
+const GetCompletedTaskCountsSQL = `
+SELECT
+    tt.type_name AS "task_type",
+    count(DISTINCT t.task_id) AS "count"
+FROM
+    task_executors te
+JOIN
+    bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+JOIN
+    tasks t ON te.task_id = t.task_id
+JOIN
+    task_types tt ON t.task_type_id = tt.type_id
+WHERE
+    bu.telegram_id = $1
+    AND t.closing_date >= $2
+    AND t.closing_date <= $3
+    AND t.is_closed = TRUE
+GROUP BY
+    tt.type_name
+ORDER BY
+    tt.type_name;
+`
+
+// GetReportTaskTypesSQL lists the types of tasks the user closed within the period, and
+// whether each type is excluded from the user's reports.
+const GetReportTaskTypesSQL = `
+SELECT
+    tt.type_id,
+    tt.type_name,
+    (x.type_id IS NOT NULL) AS excluded
+FROM
+    task_types tt
+LEFT JOIN
+    report_type_exclusions x ON x.type_id = tt.type_id AND x.telegram_id = $1
+WHERE EXISTS (
+    SELECT 1
+    FROM task_executors te
+    JOIN bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+    JOIN tasks t ON te.task_id = t.task_id
+    WHERE
+        bu.telegram_id = $1
+        AND t.task_type_id = tt.type_id
+        AND t.closing_date >= $2
+        AND t.closing_date <= $3
+        AND t.is_closed = TRUE
+)
+ORDER BY
+    tt.type_name;
+`
+
+const GetExcludedReportTypesSQL = `
+SELECT tt.type_name
+FROM report_type_exclusions x
+JOIN task_types tt ON tt.type_id = x.type_id
+WHERE x.telegram_id = $1;
+`
+
+// ToggleReportTypeSQL removes the exclusion of the task type, or adds it if there was none.
+const ToggleReportTypeSQL = `
+WITH removed AS (
+    DELETE FROM report_type_exclusions WHERE telegram_id = $1 AND type_id = $2 RETURNING type_id
+)
+INSERT INTO report_type_exclusions (telegram_id, type_id)
+SELECT $1, $2
+WHERE NOT EXISTS (SELECT 1 FROM removed);
+`
+
+// ArchiveReportSQL stores the report and removes the oldest reports of the user beyond
+// the archive size ($9). It returns the storage keys of the removed reports kept in the object storage.
+const ArchiveReportSQL = `
+WITH archived AS (
+    INSERT INTO report_archive (telegram_id, period_from, period_to, format, size, sha256, content, object_key)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+    RETURNING id
+), pruned AS (
+    DELETE FROM report_archive
+    WHERE id IN (
+        SELECT id FROM report_archive
+        WHERE telegram_id = $1
+        ORDER BY created_at DESC, id DESC
+        OFFSET $9 - 1
+    )
+    RETURNING object_key
+)
+SELECT id, ARRAY(SELECT object_key FROM pruned WHERE object_key IS NOT NULL) FROM archived;
+`
+
+const SetArchivedReportFileIDSQL = `
+UPDATE report_archive SET telegram_file_id = $2 WHERE id = $1;
+`
+
+const GetArchivedReportsSQL = `
+SELECT id, telegram_id, period_from, period_to, format, size, sha256, COALESCE(telegram_file_id, ''),
+    COALESCE(object_key, ''), created_at
+FROM report_archive
+WHERE telegram_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+`
+
+const GetArchivedReportSQL = `
+SELECT id, telegram_id, period_from, period_to, format, size, sha256, COALESCE(telegram_file_id, ''),
+    COALESCE(object_key, ''), created_at
+FROM report_archive
+WHERE id = $1 AND telegram_id = $2;
+`
+
+const GetArchivedReportContentSQL = `
+SELECT COALESCE(content, '') FROM report_archive WHERE id = $1 AND telegram_id = $2;
+`
+
+const GetTaskHistorySQL = `
+SELECT
+    event_type,
+    value,
+    COALESCE(actor, '') AS "actor",
+    created_at
+FROM
+    task_events
+WHERE
+    task_id = $1
+ORDER BY
+    created_at ASC, id ASC;
+`
+
+// GetTasksInRadiusPostGISSQL finds open tasks within $3 kilometers of the point ($1 latitude,
+// $2 longitude) using the indexed geography column added by the 0004_tasks_postgis migration.
+const GetTasksInRadiusPostGISSQL = `
+SELECT
+    task_id,
+    description
+FROM
+    tasks
+WHERE
+    is_closed = false
+    AND location IS NOT NULL
+    AND ST_DWithin(location, ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography, $3 * 1000.0)
+ORDER BY
+    ST_Distance(location, ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography);
+`
+
+const GetActiveTaskLocationsSQL = `
+SELECT
+    t.task_id,
+    t.description,
+    COALESCE(t.address, '') AS "address",
+    t.latitude,
+    t.longitude
+FROM
+    tasks t
+JOIN
+    task_executors te ON t.task_id = te.task_id
+JOIN
+    bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+WHERE
+    bu.telegram_id = $1
+    AND t.is_closed = FALSE
+    AND t.latitude IS NOT NULL
+    AND t.longitude IS NOT NULL
+ORDER BY
+    t.creation_date DESC;
+`
+
+const InsertSendFailureSQL = `
+INSERT INTO send_failures (telegram_id, source, reason, permanent, error)
+VALUES ($1, $2, $3, $4, $5);
+`
+
+const DeleteReportSubscriptionSQL = `
+DELETE FROM report_subscriptions WHERE telegram_id = $1;
+`
+
+const InsertAdminActionSQL = `
+INSERT INTO admin_audit (admin_id, action, payload_hash)
+VALUES ($1, $2, $3);
+`
+
+const GetAdminActionsSQL = `
+SELECT
+    a.id,
+    a.admin_id,
+    COALESCE(e.shortname, '') AS "admin_name",
+    a.action,
+    a.payload_hash,
+    a.created_at,
+    count(*) OVER () AS "total"
+FROM
+    admin_audit a
+LEFT JOIN
+    bot_users bu ON a.admin_id = bu.telegram_id
+LEFT JOIN
+    employees e ON bu.employee_id = e.id
+ORDER BY
+    a.created_at DESC, a.id DESC
+LIMIT $1 OFFSET $2;
+`
+
+const UpdateLastSeenSQL = `
+UPDATE bot_users SET last_interaction = NOW() WHERE telegram_id = $1;
+`
+
+const GetInactiveUsersSQL = `
+SELECT
+    bu.telegram_id,
+    COALESCE(e.shortname, '') AS "shortname",
+    bu.last_interaction
+FROM
+    bot_users bu
+LEFT JOIN
+    employees e ON bu.employee_id = e.id
+WHERE
+    bu.telegram_id IS NOT NULL
+    AND bu.deleted_at IS NULL
+    AND bu.last_interaction < $1
+ORDER BY
+    bu.last_interaction ASC;
+`
+
+const GetUserCountsSQL = `
+SELECT
+    count(*) AS "linked",
+    count(*) FILTER (WHERE e.is_admin) AS "admins"
+FROM
+    bot_users bu
+JOIN
+    employees e ON bu.employee_id = e.id
+WHERE
+    bu.telegram_id IS NOT NULL
+    AND bu.deleted_at IS NULL;
+`
+
+const CheckEmailLinkSQL = `
+SELECT
+    EXISTS (SELECT 1 FROM bot_users bu WHERE bu.employee_id = e.id AND bu.deleted_at IS NULL) AS "linked"
+FROM
+    employees e
+WHERE
+    e.email = $1;
+`
+
+const UpsertDigestSubscriptionSQL = `
+INSERT INTO digest_subscriptions (telegram_id, timezone)
+VALUES ($1, $2)
+ON CONFLICT (telegram_id) DO UPDATE SET timezone = EXCLUDED.timezone;
+`
+
+const DeleteDigestSubscriptionSQL = `
+DELETE FROM digest_subscriptions WHERE telegram_id = $1;
+`
+
+const GetDigestSubscriptionSQL = `
+SELECT telegram_id, timezone, last_sent_on FROM digest_subscriptions WHERE telegram_id = $1;
+`
+
+// GetDigestSubscribersSQL returns the digest subscriptions of the linked users; users who logged out
+// keep their subscription during the grace period but get no digest.
+const GetDigestSubscribersSQL = `
+SELECT
+    ds.telegram_id,
+    ds.timezone,
+    ds.last_sent_on
+FROM
+    digest_subscriptions ds
+JOIN
+    bot_users bu ON ds.telegram_id = bu.telegram_id AND bu.deleted_at IS NULL
+ORDER BY
+    ds.created_at;
+`
+
+const MarkDigestSentSQL = `
+UPDATE digest_subscriptions SET last_sent_on = $2 WHERE telegram_id = $1;
+`
+
+const GetDigestTasksSQL = `
+SELECT
+    t.task_id,
+    t.description,
+    t.creation_date
+FROM
+    tasks t
+JOIN
+    task_executors te ON t.task_id = te.task_id
+JOIN
+    bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+WHERE
+    bu.telegram_id = $1
+    AND t.is_closed = FALSE
+ORDER BY
+    t.creation_date ASC;
+`
+
+const EnqueueCommentSQL = `
+INSERT INTO comment_outbox (telegram_id, task_id, author, text)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+`
+
+// ClaimDueCommentsSQL leases the due comments by moving their next attempt forward,
+// so that a dispatcher which dies mid-delivery does not lose them and concurrent
+// dispatchers do not deliver them twice.
+const ClaimDueCommentsSQL = `
+UPDATE comment_outbox
+SET next_attempt_at = NOW() + $2::interval
+WHERE id IN (
+    SELECT id FROM comment_outbox
+    WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, telegram_id, task_id, author, text, attempts;
+`
+
+const MarkCommentDeliveredSQL = `
+UPDATE comment_outbox SET delivered_at = NOW(), last_error = NULL WHERE id = $1;
+`
+
+const RescheduleCommentSQL = `
+UPDATE comment_outbox
+SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+WHERE id = $1;
+`
+
+const FailCommentSQL = `
+UPDATE comment_outbox
+SET attempts = attempts + 1, failed_at = NOW(), last_error = $2
+WHERE id = $1;
+`
+
+const GetActiveTasksByExecutorSQL = `
+SELECT t.task_id, t.description, t.creation_date, COALESCE(sc.sla_hours, 0)
+FROM tasks t
+JOIN task_executors te ON t.task_id = te.task_id
+JOIN bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+LEFT JOIN sla_config sc ON sc.type_id = t.task_type_id
+WHERE bu.telegram_id = $1 AND t.is_closed = FALSE
+ORDER BY t.creation_date DESC;
+`
+
+// GetSLAConfigSQL returns every task type with its SLA in hours, 0 if not set.
+const GetSLAConfigSQL = `
+SELECT tt.type_id, tt.type_name, COALESCE(sc.sla_hours, 0)
+FROM task_types tt
+LEFT JOIN sla_config sc ON sc.type_id = tt.type_id
+ORDER BY tt.type_name;
+`
+
+const SetTaskTypeSLASQL = `
+INSERT INTO sla_config (type_id, sla_hours)
+VALUES ($1, $2)
+ON CONFLICT (type_id) DO UPDATE SET sla_hours = EXCLUDED.sla_hours, updated_at = NOW();
+`
+
+const DeleteTaskTypeSLASQL = `
+DELETE FROM sla_config WHERE type_id = $1;
+`
+
+// GetActiveTasksByExecutorFilteredSQL returns the open tasks of the executor, optionally only
+// those with coordinates ($2), grouped by type ($3) and oldest first ($4).
+const GetActiveTasksByExecutorFilteredSQL = `
+SELECT t.task_id, t.description, tt.type_name, t.creation_date, COALESCE(sc.sla_hours, 0)
+FROM tasks t
+JOIN task_executors te ON t.task_id = te.task_id
+JOIN bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+JOIN task_types tt ON t.task_type_id = tt.type_id
+LEFT JOIN sla_config sc ON sc.type_id = t.task_type_id
+WHERE
+    bu.telegram_id = $1
+    AND t.is_closed = FALSE
+    AND (NOT $2::boolean OR (t.latitude IS NOT NULL AND t.longitude IS NOT NULL))
+ORDER BY
+    CASE WHEN $3::boolean THEN tt.type_name END,
+    CASE WHEN $4::boolean THEN t.creation_date END,
+    t.creation_date DESC;
+`
+
+const GetCompletedTasksByExecutorSQL = `
+SELECT
+    t.task_id,
+    tt.type_name,
+    t.creation_date,
+    t.closing_date,
+    t.description,
+    t.address,
+    ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
+    t.comments,
+    COALESCE(ARRAY_AGG(DISTINCT e.shortname) FILTER (WHERE e.shortname IS NOT NULL), '{}') AS executors
+FROM tasks t
+JOIN task_executors te ON t.task_id = te.task_id
+JOIN bot_users bu ON te.executor_id = COALESCE(bu.acting_employee_id, bu.employee_id) AND bu.deleted_at IS NULL
+JOIN task_types tt ON t.task_type_id = tt.type_id
+LEFT JOIN task_customers tc ON t.task_id = tc.task_id
+LEFT JOIN customers c ON tc.customer_id = c.id
+LEFT JOIN task_executors tex ON t.task_id = tex.task_id
+LEFT JOIN employees e ON tex.executor_id = e.id
+WHERE
+    bu.telegram_id = $1
+    AND t.closing_date >= $2
+    AND t.closing_date <= $3
+    AND t.is_closed = TRUE
+GROUP BY t.task_id, tt.type_name
+ORDER BY tt.type_name, t.creation_date;
+`
+
+const GetTaskDetailsByIDSQL = `
+SELECT
+    t.task_id,
+    tt.type_name,
+    t.creation_date,
+    t.description,
+    t.address,
+    ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
+    t.comments,
+    t.latitude,
+    t.longitude,
+    COALESCE(ARRAY_AGG(e.shortname) FILTER (WHERE e.shortname IS NOT NULL), '{}') as executors
+FROM tasks t
+JOIN task_types tt ON t.task_type_id = tt.type_id
+LEFT JOIN task_executors te ON t.task_id = te.task_id
+LEFT JOIN employees e ON te.executor_id = e.id
+LEFT JOIN task_customers tc ON t.task_id = tc.task_id
+LEFT JOIN customers c ON tc.customer_id = c.id
+WHERE t.task_id = $1
+GROUP BY t.task_id, tt.type_name;
+`
+
+// GetTasksInRadiusSQL finds open tasks within $3 kilometers of the point ($1 latitude,
+// $2 longitude) with the haversine formula, prefiltered by a bounding box.
+const GetTasksInRadiusSQL = `
+SELECT
+    task_id,
+    description
+FROM (
+    SELECT
+        *,
+        (
+            6371 * acos(
+                cos(radians($1)) * cos(radians(latitude)) *
+                cos(radians(longitude) - radians($2)) +
+                sin(radians($1)) * sin(radians(latitude))
+            )
+        ) AS distance_km
+    FROM tasks
+    WHERE
+        latitude BETWEEN ($1 - ($3 / 111.0)) AND ($1 + ($3 / 111.0))
+        AND longitude BETWEEN ($2 - ($3 / (111.0 * cos(radians($1)))))
+            AND ($2 + ($3 / (111.0 * cos(radians($1)))))
+        AND is_closed = false
+) AS subquery
+WHERE distance_km <= $3
+ORDER BY distance_km;
+`
+
+const GetCustomersByTaskIDSQL = `
+SELECT external_id, name, login
+FROM customers c
+LEFT JOIN task_customers tc ON tc.customer_id = c.id
+WHERE tc.task_id = $1;
+`
+
+const GetCustomersForTaskIDsSQL = `
+SELECT tc.task_id, c.external_id, c.name, c.login
+FROM task_customers tc
+JOIN customers c ON c.id = tc.customer_id
+WHERE tc.task_id = ANY($1)
+ORDER BY tc.task_id;
+`
+
+const GetGeocodingIssuesSQL = `
+SELECT
+    task_id,
+    address,
+    COALESCE(geocoding_error, '') as geocoding_error,
+    COALESCE(geocoding_attempts, 0) as geocoding_attempts
+FROM tasks
+WHERE
+    (latitude IS NULL OR longitude IS NULL)
+    AND address IS NOT NULL
+    AND address != ''
+    AND is_closed = FALSE
+ORDER BY geocoding_attempts DESC, task_id ASC
+LIMIT 100;
+`
+
+const ResetGeocodingErrorsSQL = `
+UPDATE tasks
+SET
+    geocoding_attempts = 0,
+    geocoding_error = NULL
+WHERE
+    (geocoding_attempts > 0 OR geocoding_error IS NOT NULL);
+`
+
+const GetEmployeeIDByEmailSQL = `
+SELECT id FROM employees WHERE email = $1;
+`
+
+const LinkTelegramIDSQL = `
+INSERT INTO bot_users (telegram_id, employee_id)
+VALUES ($1, $2)
+ON CONFLICT (employee_id) DO NOTHING;
+`
+
+const IsUserAuthenticatedSQL = `
+SELECT EXISTS (SELECT 1 FROM bot_users WHERE telegram_id = $1 AND deleted_at IS NULL);
+`
+
+// DeleteUnlinkedUserSQL removes the unlinked rows of the Telegram ID $1 and the employee $2,
+// so the grace period of an old link does not block a new one.
+const DeleteUnlinkedUserSQL = `
+DELETE FROM bot_users
+WHERE deleted_at IS NOT NULL AND (telegram_id = $1 OR employee_id = $2);
+`
+
+const UnlinkUserSQL = `
+UPDATE bot_users SET deleted_at = NOW() WHERE telegram_id = $1 AND deleted_at IS NULL;
+`
+
+// RestoreUserSQL links the user again, if they were unlinked at or after $2.
+const RestoreUserSQL = `
+UPDATE bot_users SET deleted_at = NULL WHERE telegram_id = $1 AND deleted_at >= $2;
+`
+
+// PurgeUnlinkedUsersSQL removes the users unlinked before $1, together with their subscriptions
+// and other rows referencing bot_users.
+const PurgeUnlinkedUsersSQL = `
+DELETE FROM bot_users WHERE deleted_at < $1;
+`
+
+// GetEmployeeSQL returns the employee the user $1 acts as, which is their own one unless they
+// switched to another profile.
+const GetEmployeeSQL = `
+SELECT id, fullname, shortname, position, email, phone, is_admin FROM employees
+WHERE id = (
+    SELECT COALESCE(acting_employee_id, employee_id) FROM bot_users WHERE telegram_id = $1 AND deleted_at IS NULL
+);
+`
+
+// IsAdminSQL checks the own employee of the user $1, admin rights do not follow profile switches.
+const IsAdminSQL = `
+SELECT is_admin FROM employees
+WHERE id = (SELECT employee_id FROM bot_users WHERE telegram_id = $1 AND deleted_at IS NULL);
+`
+
+const GetAllTgUserIDsSQL = `
+SELECT telegram_id FROM bot_users WHERE deleted_at IS NULL;
+`
+
+const GetAdminsSQL = `
+SELECT telegram_id, employee_id
+FROM bot_users bu
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE e.is_admin = TRUE AND bu.deleted_at IS NULL;
+`
+
+const SetUserLanguageSQL = `
+UPDATE bot_users SET locale = $1 WHERE telegram_id = $2;
+`
+
+const GetUserLanguageSQL = `
+SELECT locale FROM bot_users WHERE telegram_id = $1;
+`
+
+const GetUserLanguagesSQL = `
+SELECT telegram_id, locale FROM bot_users WHERE telegram_id = ANY($1);
+`
+
+const InsertReportSubscriptionSQL = `
+INSERT INTO report_subscriptions (telegram_id) VALUES ($1)
+ON CONFLICT (telegram_id) DO NOTHING;
+`
+
+const IsSubscribedToReportsSQL = `
+SELECT EXISTS (SELECT 1 FROM report_subscriptions WHERE telegram_id = $1);
+`
+
+// GetReportSubscribersSQL returns the linked users subscribed to the weekly report.
+const GetReportSubscribersSQL = `
+SELECT
+    rs.telegram_id
+FROM
+    report_subscriptions rs
+JOIN
+    bot_users bu ON rs.telegram_id = bu.telegram_id AND bu.deleted_at IS NULL
+ORDER BY
+    rs.created_at;
+`
+
+const CreateBroadcastSQL = `
+INSERT INTO broadcasts (admin_id, kind, total, requires_ack) VALUES ($1, $2, $3, $4) RETURNING id;
+`
+
+const UpdateBroadcastProgressSQL = `
+UPDATE broadcasts SET sent = $2, failed = $3 WHERE id = $1;
+`
+
+const FinishBroadcastSQL = `
+UPDATE broadcasts SET sent = $2, failed = $3, status = $4, finished_at = NOW() WHERE id = $1;
+`
+
+// SearchTeammatesSQL returns employees linked to Telegram whose short name contains the LIKE
+// pattern $2, except the user $1.
+const SearchTeammatesSQL = `
+SELECT e.id, e.shortname, e.fullname, bu.telegram_id
+FROM employees e
+JOIN bot_users bu ON bu.employee_id = e.id AND bu.deleted_at IS NULL
+WHERE bu.telegram_id <> $1 AND e.shortname ILIKE $2
+ORDER BY e.shortname
+LIMIT $3;
+`
+
+// StartOnboardingSQL records the first step of the tour, unless the user has already started it.
+const StartOnboardingSQL = `
+INSERT INTO onboarding (telegram_id, step) VALUES ($1, $2)
+ON CONFLICT (telegram_id) DO NOTHING;
+`
+
+// AdvanceOnboardingSQL moves the tour from step $2 to step $3, if the user is still on step $2.
+const AdvanceOnboardingSQL = `
+UPDATE onboarding SET step = $3
+WHERE telegram_id = $1 AND step = $2 AND completed_at IS NULL;
+`
+
+const CompleteOnboardingSQL = `
+UPDATE onboarding SET completed_at = NOW()
+WHERE telegram_id = $1 AND completed_at IS NULL;
+`
+
+const GetFeatureFlagsSQL = `
+SELECT name, enabled, percentage, roles
+FROM feature_flags
+ORDER BY name;
+`
+
+const UpsertFeatureFlagSQL = `
+INSERT INTO feature_flags (name, enabled, percentage, roles, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (name) DO UPDATE SET
+    enabled = EXCLUDED.enabled,
+    percentage = EXCLUDED.percentage,
+    roles = EXCLUDED.roles,
+    updated_at = NOW(),
+    updated_by = EXCLUDED.updated_by;
+`
+
+// GetProfilesSQL returns the own employee of the user $1 followed by the employees they were
+// granted to act as, flagging the one they act as now.
+const GetProfilesSQL = `
+SELECT
+    e.id,
+    e.shortname,
+    e.fullname,
+    e.id = bu.employee_id AS "own",
+    e.id = COALESCE(bu.acting_employee_id, bu.employee_id) AS "active"
+FROM
+    bot_users bu
+JOIN
+    employees e ON e.id = bu.employee_id
+    OR e.id IN (SELECT p.employee_id FROM bot_user_profiles p WHERE p.telegram_id = bu.telegram_id)
+WHERE
+    bu.telegram_id = $1 AND bu.deleted_at IS NULL
+ORDER BY
+    "own" DESC, e.shortname;
+`
+
+// SwitchProfileSQL makes the user $1 act as the employee $2, if it is their own employee or one
+// they were granted.
+const SwitchProfileSQL = `
+UPDATE bot_users bu SET acting_employee_id = NULLIF($2, bu.employee_id)
+WHERE
+    bu.telegram_id = $1
+    AND bu.deleted_at IS NULL
+    AND (
+        bu.employee_id = $2
+        OR EXISTS (SELECT 1 FROM bot_user_profiles p WHERE p.telegram_id = $1 AND p.employee_id = $2)
+    );
+`
+
+const GrantProfileSQL = `
+INSERT INTO bot_user_profiles (telegram_id, employee_id, granted_by) VALUES ($1, $2, $3)
+ON CONFLICT (telegram_id, employee_id) DO NOTHING;
+`
+
+// RevokeProfileSQL removes the employee $2 from the profiles of the user $1, switching the user
+// back to their own employee if they act as it.
+const RevokeProfileSQL = `
+WITH revoked AS (
+    DELETE FROM bot_user_profiles WHERE telegram_id = $1 AND employee_id = $2
+    RETURNING employee_id
+)
+UPDATE bot_users SET acting_employee_id = NULL
+WHERE telegram_id = $1 AND acting_employee_id IN (SELECT employee_id FROM revoked);
+`
+
+// HasProfilesSQL checks whether the user $1 was granted any employee to act as.
+const HasProfilesSQL = `
+SELECT EXISTS (SELECT 1 FROM bot_user_profiles WHERE telegram_id = $1);
+`
+
+// SearchEmployeesSQL returns employees whose short name contains the LIKE pattern $1, whether or
+// not they are linked to Telegram.
+const SearchEmployeesSQL = `
+SELECT e.id, e.shortname, e.fullname, COALESCE(bu.telegram_id, 0)
+FROM employees e
+LEFT JOIN bot_users bu ON bu.employee_id = e.id AND bu.deleted_at IS NULL
+WHERE e.shortname ILIKE $1
+ORDER BY e.shortname
+LIMIT $2;
+`
+
+// GetEmployeeByIDSQL returns the employee $1.
+const GetEmployeeByIDSQL = `
+SELECT id, fullname, shortname, position, email, phone, is_admin FROM employees WHERE id = $1;
+`
+
+// GetActiveTasksByEmployeeSQL is GetActiveTasksByExecutorSQL for the employee $1.
+const GetActiveTasksByEmployeeSQL = `
+SELECT t.task_id, t.description, t.creation_date, COALESCE(sc.sla_hours, 0)
+FROM tasks t
+JOIN task_executors te ON t.task_id = te.task_id
+LEFT JOIN sla_config sc ON sc.type_id = t.task_type_id
+WHERE te.executor_id = $1 AND t.is_closed = FALSE
+ORDER BY t.creation_date DESC;
+`
+
+// GetTaskSummaryByEmployeeSQL is GetTaskSummarySQL for the employee $1.
+const GetTaskSummaryByEmployeeSQL = `
+SELECT
+    tt.type_name AS "task_type",
+    count(*) AS "count"
+FROM
+    task_executors te
+JOIN
+    tasks t ON te.task_id = t.task_id
+JOIN
+    task_types tt ON t.task_type_id = tt.type_id
+WHERE
+    te.executor_id = $1
+    AND t.closing_date >= $2
+    AND t.closing_date <= $3
+GROUP BY
+    tt.type_name
+
+UNION ALL
+
+SELECT
+    'Total' AS "task_type",
+    count(*) AS "count"
+FROM
+    task_executors te
+JOIN
+    tasks t ON te.task_id = t.task_id
+WHERE
+    te.executor_id = $1
+    AND t.closing_date >= $2
+    AND t.closing_date <= $3
+ORDER BY
+    "count" ASC;
+`
+
+// SetTaskLocationSQL sets the coordinates of the task $1 entered by an admin, clearing its
+// geocoding error so the task is no longer reported as an issue.
+const SetTaskLocationSQL = `
+UPDATE tasks
+SET latitude = $2, longitude = $3, geocoding_attempts = 0, geocoding_error = NULL
+WHERE task_id = $1;
+`
+
+const UpsertQuietHoursSQL = `
+INSERT INTO quiet_hours (telegram_id, start_minute, end_minute)
+VALUES ($1, $2, $3)
+ON CONFLICT (telegram_id) DO UPDATE
+SET start_minute = EXCLUDED.start_minute, end_minute = EXCLUDED.end_minute, updated_at = NOW();
+`
+
+const DeleteQuietHoursSQL = `
+DELETE FROM quiet_hours WHERE telegram_id = $1;
+`
+
+// GetQuietHoursSQL returns the quiet hours of the user $1 with the time zone of their digest,
+// empty when they are not subscribed to it.
+const GetQuietHoursSQL = `
+SELECT q.telegram_id, q.start_minute, q.end_minute, COALESCE(d.timezone, '')
+FROM quiet_hours q
+LEFT JOIN digest_subscriptions d ON d.telegram_id = q.telegram_id
+WHERE q.telegram_id = $1;
+`
+
+const GetAllQuietHoursSQL = `
+SELECT q.telegram_id, q.start_minute, q.end_minute, COALESCE(d.timezone, '')
+FROM quiet_hours q
+LEFT JOIN digest_subscriptions d ON d.telegram_id = q.telegram_id;
+`
+
+const DeferMessageSQL = `
+INSERT INTO deferred_messages (telegram_id, source, payload, deliver_at)
+VALUES ($1, $2, $3, $4);
+`
+
+// ClaimDueMessagesSQL deletes up to $1 due deferred messages and returns them. Rows locked by
+// another replica are skipped.
+const ClaimDueMessagesSQL = `
+DELETE FROM deferred_messages
+WHERE id IN (
+    SELECT id FROM deferred_messages
+    WHERE deliver_at <= NOW()
+    ORDER BY deliver_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, telegram_id, source, payload, deliver_at;
+`
+
+const SaveBroadcastTemplateSQL = `
+INSERT INTO broadcast_templates (name, text, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (name) DO UPDATE
+SET text = EXCLUDED.text, created_by = EXCLUDED.created_by, updated_at = NOW()
+RETURNING id;
+`
+
+const GetBroadcastTemplatesSQL = `
+SELECT id, name, text, created_by, updated_at FROM broadcast_templates ORDER BY name;
+`
+
+const GetBroadcastTemplateSQL = `
+SELECT id, name, text, created_by, updated_at FROM broadcast_templates WHERE id = $1;
+`
+
+const DeleteBroadcastTemplateSQL = `
+DELETE FROM broadcast_templates WHERE id = $1;
+`
+
+const AddBroadcastRecipientSQL = `
+INSERT INTO broadcast_receipts (broadcast_id, telegram_id) VALUES ($1, $2)
+ON CONFLICT (broadcast_id, telegram_id) DO NOTHING;
+`
+
+const AcknowledgeBroadcastSQL = `
+UPDATE broadcast_receipts SET acked_at = NOW()
+WHERE broadcast_id = $1 AND telegram_id = $2 AND acked_at IS NULL;
+`
+
+// GetBroadcastReceiptsSQL returns the recipients of the broadcast $1 with their short names,
+// those who have not confirmed it first.
+const GetBroadcastReceiptsSQL = `
+SELECT r.telegram_id, COALESCE(e.shortname, ''), r.acked_at
+FROM broadcast_receipts r
+LEFT JOIN bot_users bu ON bu.telegram_id = r.telegram_id
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE r.broadcast_id = $1
+ORDER BY r.acked_at NULLS FIRST, e.shortname;
+`
+
+// GetQuickRepliesSQL returns the active quick replies in the order of their buttons.
+const GetQuickRepliesSQL = `
+SELECT id, text
+FROM quick_replies
+WHERE active
+ORDER BY position, id;
+`
+
+// UpsertOnCallWeekSQL puts the user $2 on duty in the week starting on $1, set by the admin $3.
+const UpsertOnCallWeekSQL = `
+INSERT INTO oncall_weeks (week_start, telegram_id, created_by)
+VALUES ($1, $2, $3)
+ON CONFLICT (week_start) DO UPDATE
+SET telegram_id = EXCLUDED.telegram_id, created_by = EXCLUDED.created_by, updated_at = NOW();
+`
+
+// DeleteOnCallWeekSQL removes the week starting on $1 from the on-call rota.
+const DeleteOnCallWeekSQL = `
+DELETE FROM oncall_weeks WHERE week_start = $1;
+`
+
+// GetOnCallWeeksSQL returns up to $2 weeks of the on-call rota which are not over on the day $1.
+// Weeks of logged out users are skipped.
+const GetOnCallWeeksSQL = `
+SELECT o.week_start, o.telegram_id, COALESCE(e.shortname, '')
+FROM oncall_weeks o
+JOIN bot_users bu ON bu.telegram_id = o.telegram_id AND bu.deleted_at IS NULL
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE o.week_start > $1::date - 7
+ORDER BY o.week_start
+LIMIT $2;
+`
+
+// GetOnCallWeekSQL returns the week of the on-call rota the day $1 belongs to, unless its user
+// logged out.
+const GetOnCallWeekSQL = `
+SELECT o.week_start, o.telegram_id, COALESCE(e.shortname, '')
+FROM oncall_weeks o
+JOIN bot_users bu ON bu.telegram_id = o.telegram_id AND bu.deleted_at IS NULL
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE o.week_start <= $1::date AND o.week_start > $1::date - 7
+ORDER BY o.week_start DESC
+LIMIT 1;
+`
+
+// StartShiftSQL starts a shift of the user $1 and returns its ID.
+const StartShiftSQL = `
+INSERT INTO shifts (telegram_id) VALUES ($1) RETURNING id;
+`
+
+// EndShiftSQL ends the open shift of the user $1 and returns its ID.
+const EndShiftSQL = `
+UPDATE shifts SET ended_at = NOW() WHERE telegram_id = $1 AND ended_at IS NULL RETURNING id;
+`
+
+// SetShiftStartLocationSQL records where the shift $1 started.
+const SetShiftStartLocationSQL = `
+UPDATE shifts SET start_latitude = $2, start_longitude = $3 WHERE id = $1;
+`
+
+// SetShiftEndLocationSQL records where the shift $1 ended.
+const SetShiftEndLocationSQL = `
+UPDATE shifts SET end_latitude = $2, end_longitude = $3 WHERE id = $1;
+`
+
+// GetOpenShiftSQL returns the open shift of the user $1.
+const GetOpenShiftSQL = `
+SELECT s.id, s.telegram_id, COALESCE(e.shortname, ''),
+       s.started_at, s.start_latitude, s.start_longitude, s.ended_at, s.end_latitude, s.end_longitude
+FROM shifts s
+JOIN bot_users bu ON bu.telegram_id = s.telegram_id
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE s.telegram_id = $1 AND s.ended_at IS NULL;
+`
+
+// GetShiftsSQL returns the shifts started between $1 and $2, ordered by employee and start.
+const GetShiftsSQL = `
+SELECT s.id, s.telegram_id, COALESCE(e.shortname, ''),
+       s.started_at, s.start_latitude, s.start_longitude, s.ended_at, s.end_latitude, s.end_longitude
+FROM shifts s
+JOIN bot_users bu ON bu.telegram_id = s.telegram_id
+LEFT JOIN employees e ON e.id = bu.employee_id
+WHERE s.started_at BETWEEN $1 AND $2
+ORDER BY COALESCE(e.shortname, ''), s.telegram_id, s.started_at;
+`
+
+// GetMaterialsSQL returns the active materials of the catalog in the order of their buttons.
+const GetMaterialsSQL = `
+SELECT id, name, unit
+FROM materials
+WHERE active
+ORDER BY position, id;
+`
+
+// AddTaskMaterialSQL logs the quantity $3 of the material $2 used on the task $1 by the user $4.
+const AddTaskMaterialSQL = `
+INSERT INTO task_materials (task_id, material_id, quantity, telegram_id) VALUES ($1, $2, $3, $4);
+`
+
+// GetTaskMaterialsSQL returns the materials used on the tasks $1, summed per task and material.
+const GetTaskMaterialsSQL = `
+SELECT tm.task_id, m.name, m.unit, SUM(tm.quantity)::float8
+FROM task_materials tm
+JOIN materials m ON m.id = tm.material_id
+WHERE tm.task_id = ANY($1)
+GROUP BY tm.task_id, m.id, m.name, m.unit, m.position
+ORDER BY tm.task_id, m.position, m.id;
+`
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// SubscribeToReports enables the weekly automatic report for the user.
+// Subscribing twice is not an error.
+func (r *Repository) SubscribeToReports(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, InsertReportSubscriptionSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to subscribe user %d to reports: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// UnsubscribeFromReports disables the weekly automatic report for the user.
+func (r *Repository) UnsubscribeFromReports(ctx context.Context, telegramID int64) error {
+	if _, err := r.db.Exec(ctx, DeleteReportSubscriptionSQL, telegramID); err != nil {
+		return fmt.Errorf("failed to unsubscribe user %d from reports: %w", telegramID, err)
+	}
+
+	return nil
+}
+
+// IsSubscribedToReports reports whether the user receives the weekly automatic report.
+func (r *Repository) IsSubscribedToReports(ctx context.Context, telegramID int64) (bool, error) {
+	var exists bool
+
+	if err := r.db.QueryRow(ctx, IsSubscribedToReportsSQL, telegramID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check report subscription: %w", err)
+	}
+
+	return exists, nil
+}
+
+// GetReportSubscribers returns Telegram IDs of all users subscribed to the weekly automatic report.
+func (r *Repository) GetReportSubscribers(ctx context.Context) ([]int64, error) {
+	rows, err := r.db.Query(ctx, GetReportSubscribersSQL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan subscriber row: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return ids, nil
+}
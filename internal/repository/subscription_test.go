@@ -0,0 +1,203 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/repository"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeToReports(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertReportSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		err = repo.SubscribeToReports(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.InsertReportSubscriptionSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
+
+		err = repo.SubscribeToReports(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to subscribe user")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnsubscribeFromReports(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteReportSubscriptionSQL)).
+			WithArgs(telegramID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+		err = repo.UnsubscribeFromReports(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteReportSubscriptionSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
+
+		err = repo.UnsubscribeFromReports(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to unsubscribe user")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIsSubscribedToReports(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+
+	t.Run("subscribed", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsSubscribedToReportsSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+		subscribed, err := repo.IsSubscribedToReports(ctx, telegramID)
+
+		require.NoError(t, err)
+		assert.True(t, subscribed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsSubscribedToReportsSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
+
+		subscribed, err := repo.IsSubscribedToReports(ctx, telegramID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.False(t, subscribed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetReportSubscribers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	t.Run("skips unlinked users", func(t *testing.T) {
+		t.Parallel()
+		// Logged out users keep their subscription during the grace period of the logout.
+		assert.Contains(t, repository.GetReportSubscribersSQL, "bu.deleted_at IS NULL")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetReportSubscribersSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id"}).AddRow(int64(1)).AddRow(int64(2)))
+
+		ids, err := repo.GetReportSubscribers(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1, 2}, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetReportSubscribersSQL)).WillReturnError(assert.AnError)
+
+		ids, err := repo.GetReportSubscribers(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get report subscribers")
+		assert.Nil(t, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("row error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetReportSubscribersSQL)).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id"}).AddRow(int64(1)).RowError(0, assert.AnError))
+
+		ids, err := repo.GetReportSubscribers(ctx)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -8,19 +8,41 @@ import (
 
 	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// ErrTaskNotFound is returned when a task with the requested ID does not exist.
+var ErrTaskNotFound = errors.New("task not found")
+
+// PostgreSQL error codes returned when PostGIS is not installed.
+const (
+	sqlStateUndefinedFunction = "42883"
+	sqlStateUndefinedColumn   = "42703"
+	sqlStateUndefinedObject   = "42704"
+)
+
 // GetTaskSummary retrieves a summary of tasks for a specific user identified by telegramID
 // within the given date range defined by startDate and endDate. It returns a slice of
 // TaskSummary models and an error if any occurs during the database query or scanning process.
 func (r *Repository) GetTaskSummary(ctx context.Context, telegramID int64, startDate, endDate time.Time) (
 	[]models.TaskSummary, error,
 ) {
-	var err error
+	return r.queryTaskSummary(ctx, GetTaskSummarySQL, telegramID, startDate, endDate)
+}
+
+// GetTaskSummaryByEmployee is the variant of GetTaskSummary for the employee identified by
+// employeeID, used by supervisors to look at the statistics of their subordinates.
+func (r *Repository) GetTaskSummaryByEmployee(ctx context.Context, employeeID int, startDate, endDate time.Time) (
+	[]models.TaskSummary, error,
+) {
+	return r.queryTaskSummary(ctx, GetTaskSummaryByEmployeeSQL, employeeID, startDate, endDate)
+}
+
+func (r *Repository) queryTaskSummary(ctx context.Context, query string, args ...any) ([]models.TaskSummary, error) {
 	var summaries []models.TaskSummary
 
-	rows, err := r.db.Query(ctx, GetTaskSummarySQL, telegramID, startDate, endDate)
+	rows, err := r.reader().Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying task summaries: %w", err)
 	}
@@ -42,6 +64,121 @@ func (r *Repository) GetTaskSummary(ctx context.Context, telegramID int64, start
 	return summaries, nil
 }
 
+// GetTaskDurations retrieves the average and median time between creation and closing of the tasks
+// closed by the user within the given period, per task type.
+func (r *Repository) GetTaskDurations(ctx context.Context, telegramID int64, startDate, endDate time.Time) (
+	[]models.TaskDuration, error,
+) {
+	rows, err := r.reader().Query(ctx, GetTaskDurationsSQL, telegramID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []models.TaskDuration
+	for rows.Next() {
+		var (
+			duration      models.TaskDuration
+			avgSeconds    int64
+			medianSeconds int64
+		)
+		if err = rows.Scan(&duration.Type, &avgSeconds, &medianSeconds); err != nil {
+			return nil, fmt.Errorf("error scanning task durations row: %w", err)
+		}
+		duration.Average = time.Duration(avgSeconds) * time.Second
+		duration.Median = time.Duration(medianSeconds) * time.Second
+		durations = append(durations, duration)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterating task durations rows: %w", err)
+	}
+
+	return durations, nil
+}
+
+// GetTaskExecutorTelegramIDs retrieves the Telegram IDs of the bot users who execute the task.
+func (r *Repository) GetTaskExecutorTelegramIDs(ctx context.Context, taskID int) ([]int64, error) {
+	rows, err := r.db.Query(ctx, GetTaskExecutorTelegramIDsSQL, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying task executors: %w", err)
+	}
+	defer rows.Close()
+
+	var telegramIDs []int64
+	for rows.Next() {
+		var telegramID int64
+		if err = rows.Scan(&telegramID); err != nil {
+			return nil, fmt.Errorf("error scanning task executor row: %w", err)
+		}
+		telegramIDs = append(telegramIDs, telegramID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterating task executor rows: %w", err)
+	}
+
+	return telegramIDs, nil
+}
+
+// GetTeamTaskSummary retrieves the number of tasks completed by every employee
+// within the given period, ordered from the most productive employee.
+func (r *Repository) GetTeamTaskSummary(ctx context.Context, startDate, endDate time.Time) (
+	[]models.EmployeeTaskSummary, error,
+) {
+	rows, err := r.reader().Query(ctx, GetTeamTaskSummarySQL, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying team task summary: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []models.EmployeeTaskSummary
+	for rows.Next() {
+		var summary models.EmployeeTaskSummary
+		if err = rows.Scan(&summary.EmployeeID, &summary.ShortName, &summary.Count); err != nil {
+			return nil, fmt.Errorf("error scanning team summary row: %w", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterating team summary rows: %w", err)
+	}
+
+	return summaries, nil
+}
+
+// GetEmployeePerformance retrieves the number of tasks completed by every employee within
+// the given period together with their average closing time, ordered from the most productive employee.
+func (r *Repository) GetEmployeePerformance(ctx context.Context, startDate, endDate time.Time) (
+	[]models.EmployeePerformance, error,
+) {
+	rows, err := r.reader().Query(ctx, GetEmployeePerformanceSQL, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error querying employee performance: %w", err)
+	}
+	defer rows.Close()
+
+	var performance []models.EmployeePerformance
+	for rows.Next() {
+		var (
+			entry      models.EmployeePerformance
+			avgSeconds int64
+		)
+		if err = rows.Scan(&entry.EmployeeID, &entry.ShortName, &entry.Count, &avgSeconds); err != nil {
+			return nil, fmt.Errorf("error scanning employee performance row: %w", err)
+		}
+		entry.AvgClosingTime = time.Duration(avgSeconds) * time.Second
+		performance = append(performance, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterating employee performance rows: %w", err)
+	}
+
+	return performance, nil
+}
+
 // GetActiveTasksByExecutor retrieves a list of active tasks assigned to a specific executor.
 // It queries the database for tasks that are not closed and are associated with the given
 // Telegram ID of the executor. The results are ordered by the task creation date in descending order.
@@ -54,15 +191,17 @@ func (r *Repository) GetTaskSummary(ctx context.Context, telegramID int64, start
 //   - A slice of ActiveTask models representing the active tasks for the specified executor.
 //   - An error if the query fails or if there is an issue scanning the results.
 func (r *Repository) GetActiveTasksByExecutor(ctx context.Context, telegramID int64) ([]models.ActiveTask, error) {
-	query := `
-		SELECT t.task_id, t.description
-		FROM tasks t
-		JOIN task_executors te ON t.task_id = te.task_id
-		JOIN bot_users bu ON te.executor_id = bu.employee_id
-		WHERE bu.telegram_id = $1 AND t.is_closed = FALSE
-		ORDER BY t.creation_date DESC;
-	`
-	rows, err := r.db.Query(ctx, query, telegramID)
+	return r.queryActiveTasks(ctx, GetActiveTasksByExecutorSQL, telegramID)
+}
+
+// GetActiveTasksByEmployee is the variant of GetActiveTasksByExecutor for the employee identified
+// by employeeID, whether or not they are linked to Telegram.
+func (r *Repository) GetActiveTasksByEmployee(ctx context.Context, employeeID int) ([]models.ActiveTask, error) {
+	return r.queryActiveTasks(ctx, GetActiveTasksByEmployeeSQL, employeeID)
+}
+
+func (r *Repository) queryActiveTasks(ctx context.Context, query string, args ...any) ([]models.ActiveTask, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query active tasks: %w", err)
 	}
@@ -71,9 +210,44 @@ func (r *Repository) GetActiveTasksByExecutor(ctx context.Context, telegramID in
 	var tasks []models.ActiveTask
 	for rows.Next() {
 		var task models.ActiveTask
-		if errScan := rows.Scan(&task.ID, &task.Description); errScan != nil {
+		var slaHours int
+		if errScan := rows.Scan(&task.ID, &task.Description, &task.CreationDate, &slaHours); errScan != nil {
+			return nil, fmt.Errorf("failed to scan active task row: %w", errScan)
+		}
+		task.SLA = time.Duration(slaHours) * time.Hour
+		tasks = append(tasks, task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// GetActiveTasksByExecutorFiltered retrieves the active tasks of the executor narrowed down and
+// ordered by the filter. Unlike GetActiveTasksByExecutor, the tasks carry the name of their type.
+func (r *Repository) GetActiveTasksByExecutorFiltered(
+	ctx context.Context,
+	telegramID int64,
+	filter models.ActiveTaskFilter,
+) ([]models.ActiveTask, error) {
+	rows, err := r.db.Query(ctx, GetActiveTasksByExecutorFilteredSQL,
+		telegramID, filter.WithCoordinates, filter.ByType, filter.OldestFirst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filtered active tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []models.ActiveTask
+	for rows.Next() {
+		var task models.ActiveTask
+		var slaHours int
+		errScan := rows.Scan(&task.ID, &task.Description, &task.Type, &task.CreationDate, &slaHours)
+		if errScan != nil {
 			return nil, fmt.Errorf("failed to scan active task row: %w", errScan)
 		}
+		task.SLA = time.Duration(slaHours) * time.Hour
 		tasks = append(tasks, task)
 	}
 
@@ -102,31 +276,7 @@ func (r *Repository) GetCompletedTasksByExecutor(
 	telegramID int64,
 	from, to time.Time,
 ) ([]models.TaskDetails, error) {
-	query := `
-		SELECT
-			t.task_id,
-			tt.type_name,
-			t.creation_date,
-			t.closing_date,
-			t.description,
-			t.address,
-			ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
-			t.comments
-		FROM tasks t
-		JOIN task_executors te ON t.task_id = te.task_id
-		JOIN bot_users bu ON te.executor_id = bu.employee_id
-		JOIN task_types tt ON t.task_type_id = tt.type_id
-		LEFT JOIN task_customers tc ON t.task_id = tc.task_id
-		LEFT JOIN customers c ON tc.customer_id = c.id
-		WHERE
-			bu.telegram_id = $1
-			AND t.closing_date >= $2
-			AND t.closing_date <= $3
-			AND t.is_closed = TRUE
-		GROUP BY t.task_id, tt.type_name
-		ORDER BY tt.type_name, t.creation_date;
-	`
-	rows, err := r.db.Query(ctx, query, telegramID, from, to)
+	rows, err := r.reader().Query(ctx, GetCompletedTasksByExecutorSQL, telegramID, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query completed tasks: %w", err)
 	}
@@ -136,7 +286,7 @@ func (r *Repository) GetCompletedTasksByExecutor(
 	for rows.Next() {
 		var task models.TaskDetails
 		if err = rows.Scan(&task.ID, &task.Type, &task.CreationDate, &task.ClosingDate, &task.Description,
-			&task.Address, &task.CustomerNames, &task.Comments,
+			&task.Address, &task.CustomerNames, &task.Comments, &task.Executors,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan completed task row: %w", err)
 		}
@@ -152,7 +302,7 @@ func (r *Repository) GetCompletedTasksByExecutor(
 
 // GetTaskDetailsByID retrieves the details of a task by its ID.
 // It executes a SQL query to fetch task details including type, creation date,
-// description, address, customer name, and comments. If the task is not found,
+// description, address, customer name, comments and the status history. If the task is not found,
 // it returns an error indicating that the task with the specified ID does not exist.
 // In case of any other query errors, it returns an error with the details of the failure.
 //
@@ -164,29 +314,8 @@ func (r *Repository) GetCompletedTasksByExecutor(
 //   - A pointer to models.TaskDetails containing the task information, or nil if not found.
 //   - An error if the query fails or the task does not exist.
 func (r *Repository) GetTaskDetailsByID(ctx context.Context, taskID int) (*models.TaskDetails, error) {
-	query := `
-		SELECT
-			t.task_id,
-			tt.type_name,
-			t.creation_date,
-			t.description,
-			t.address,
-			ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
-			t.comments,
-			t.latitude,
-			t.longitude,
-			COALESCE(ARRAY_AGG(e.shortname) FILTER (WHERE e.shortname IS NOT NULL), '{}') as executors
-		FROM tasks t
-		JOIN task_types tt ON t.task_type_id = tt.type_id
-		LEFT JOIN task_executors te ON t.task_id = te.task_id
-		LEFT JOIN employees e ON te.executor_id = e.id
-		LEFT JOIN task_customers tc ON t.task_id = tc.task_id
-		LEFT JOIN customers c ON tc.customer_id = c.id
-		WHERE t.task_id = $1
-		GROUP BY t.task_id, tt.type_name;
-	`
 	var details models.TaskDetails
-	err := r.db.QueryRow(ctx, query, taskID).Scan(
+	err := r.db.QueryRow(ctx, GetTaskDetailsByIDSQL, taskID).Scan(
 		&details.ID,
 		&details.Type,
 		&details.CreationDate,
@@ -200,15 +329,46 @@ func (r *Repository) GetTaskDetailsByID(ctx context.Context, taskID int) (*model
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("task with id %d not found", taskID)
+			return nil, fmt.Errorf("task with id %d: %w", taskID, ErrTaskNotFound)
 		}
 		return nil, fmt.Errorf("failed to query task details: %w", err)
 	}
+
+	if details.History, err = r.getTaskHistory(ctx, taskID); err != nil {
+		return nil, err
+	}
+
 	return &details, nil
 }
 
+// getTaskHistory retrieves the chronological status and assignment changes of the task.
+func (r *Repository) getTaskHistory(ctx context.Context, taskID int) ([]models.TaskEvent, error) {
+	rows, err := r.db.Query(ctx, GetTaskHistorySQL, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.TaskEvent
+	for rows.Next() {
+		var event models.TaskEvent
+		if err = rows.Scan(&event.Type, &event.Value, &event.Actor, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task history row: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read task history rows: %w", err)
+	}
+
+	return events, nil
+}
+
 // GetTasksInRadius retrieves a list of active tasks within a specified radius from a given latitude and longitude.
 // It executes a SQL query to find tasks that are not closed and fall within the specified distance.
+// When PostGIS is enabled, the indexed geography column is used; if PostGIS turns out to be unavailable,
+// it is disabled and the haversine approximation is used instead.
 //
 // Parameters:
 // - ctx: The context for the request, allowing for cancellation and timeout.
@@ -220,30 +380,25 @@ func (r *Repository) GetTaskDetailsByID(ctx context.Context, taskID int) (*model
 // - A slice of ActiveTask models representing the tasks found within the radius.
 // - An error if the query fails or if there is an issue scanning the results.
 func (r *Repository) GetTasksInRadius(ctx context.Context, lat, lng float32, radius int) ([]models.ActiveTask, error) {
-	query := `
-		SELECT
-			task_id,
-			description
-		FROM (
-			SELECT
-				*,
-				(
-					6371 * acos(
-						cos(radians($1)) * cos(radians(latitude)) *
-						cos(radians(longitude) - radians($2)) +
-						sin(radians($1)) * sin(radians(latitude))
-					)
-				) AS distance_km
-			FROM tasks
-			WHERE
-				latitude BETWEEN ($1 - ($3 / 111.0)) AND ($1 + ($3 / 111.0))
-				AND longitude BETWEEN ($2 - ($3 / (111.0 * cos(radians($1))))) AND ($2 + ($3 / (111.0 * cos(radians($1)))))
-				AND is_closed = false
-		) AS subquery
-		WHERE distance_km <= $3
-		ORDER BY distance_km;
-	`
-	rows, err := r.db.Query(ctx, query, lat, lng, radius)
+	if r.postgis.Load() {
+		tasks, err := r.queryTasksInRadius(ctx, GetTasksInRadiusPostGISSQL, lat, lng, radius)
+		if !isPostGISUnavailable(err) {
+			return tasks, err
+		}
+		r.postgis.Store(false)
+	}
+
+	return r.queryTasksInRadius(ctx, GetTasksInRadiusSQL, lat, lng, radius)
+}
+
+// queryTasksInRadius runs one of the near-task queries and scans the tasks it returns.
+func (r *Repository) queryTasksInRadius(
+	ctx context.Context,
+	query string,
+	lat, lng float32,
+	radius int,
+) ([]models.ActiveTask, error) {
+	rows, err := r.reader().Query(ctx, query, lat, lng, radius)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query near tasks: %w", err)
 	}
@@ -265,6 +420,22 @@ func (r *Repository) GetTasksInRadius(ctx context.Context, lat, lng float32, rad
 	return tasks, nil
 }
 
+// isPostGISUnavailable reports whether the error means that the PostGIS functions or the
+// geography column are missing, i.e. the extension or its migration is not installed.
+func isPostGISUnavailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case sqlStateUndefinedFunction, sqlStateUndefinedColumn, sqlStateUndefinedObject:
+		return true
+	default:
+		return false
+	}
+}
+
 // GetCustomersByTaskID retrieves a list of customers associated with a specific task ID.
 // It executes a SQL query to select customer details from the database, including
 // external ID, name, and login. If the task ID is valid, it returns a slice of
@@ -278,13 +449,7 @@ func (r *Repository) GetTasksInRadius(ctx context.Context, lat, lng float32, rad
 //   - A slice of models.Customer containing the customer details.
 //   - An error if the operation fails.
 func (r *Repository) GetCustomersByTaskID(ctx context.Context, taskID int64) ([]models.Customer, error) {
-	query := `
-		SELECT external_id, name, login
-		FROM customers c
-		LEFT JOIN task_customers tc ON tc.customer_id = c.id
-		WHERE tc.task_id = $1;
-	`
-	rows, err := r.db.Query(ctx, query, taskID)
+	rows, err := r.db.Query(ctx, GetCustomersByTaskIDSQL, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select customers to assigned task %d: %w", taskID, err)
 	}
@@ -310,26 +475,45 @@ func (r *Repository) GetCustomersByTaskID(ctx context.Context, taskID int64) ([]
 	return customers, nil
 }
 
+// GetCustomersForTaskIDs retrieves the customers of all given tasks with a single query.
+// The result maps a task ID to its customers, tasks without customers are absent from it.
+func (r *Repository) GetCustomersForTaskIDs(ctx context.Context, taskIDs []int64) (map[int64][]models.Customer, error) {
+	customers := make(map[int64][]models.Customer, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return customers, nil
+	}
+
+	rows, err := r.db.Query(ctx, GetCustomersForTaskIDsSQL, taskIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select customers of %d tasks: %w", len(taskIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int64
+		var customer models.Customer
+		var customerID pgtype.Int8
+		if err = rows.Scan(&taskID, &customerID, &customer.Fullname, &customer.Login); err != nil {
+			return nil, fmt.Errorf("failed to scan customer row: %w", err)
+		}
+		if customerID.Valid {
+			customer.ID = customerID.Int64
+		}
+		customers[taskID] = append(customers[taskID], customer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return customers, nil
+}
+
 // GetGeocodingIssues retrieves tasks that have geocoding problems.
 // Returns tasks without coordinates (latitude/longitude NULL) or tasks with geocoding errors.
 // Used by admin panel for debugging the Atlas geocoding service.
 func (r *Repository) GetGeocodingIssues(ctx context.Context) ([]models.GeocodingIssue, error) {
-	query := `
-		SELECT
-			task_id,
-			address,
-			COALESCE(geocoding_error, '') as geocoding_error,
-			COALESCE(geocoding_attempts, 0) as geocoding_attempts
-		FROM tasks
-		WHERE
-			(latitude IS NULL OR longitude IS NULL)
-			AND address IS NOT NULL
-			AND address != ''
-			AND is_closed = FALSE
-		ORDER BY geocoding_attempts DESC, task_id ASC
-		LIMIT 100;
-	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, GetGeocodingIssuesSQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query geocoding issues: %w", err)
 	}
@@ -356,15 +540,7 @@ func (r *Repository) GetGeocodingIssues(ctx context.Context) ([]models.Geocoding
 // This allows the Atlas service to retry geocoding on the next run.
 // Returns the number of tasks that were reset.
 func (r *Repository) ResetGeocodingErrors(ctx context.Context) (int64, error) {
-	query := `
-		UPDATE tasks
-		SET
-			geocoding_attempts = 0,
-			geocoding_error = NULL
-		WHERE
-			(geocoding_attempts > 0 OR geocoding_error IS NOT NULL);
-	`
-	result, err := r.db.Exec(ctx, query)
+	result, err := r.db.Exec(ctx, ResetGeocodingErrorsSQL)
 	if err != nil {
 		return 0, fmt.Errorf("failed to reset geocoding errors: %w", err)
 	}
@@ -372,3 +548,17 @@ func (r *Repository) ResetGeocodingErrors(ctx context.Context) (int64, error) {
 	rowsAffected := result.RowsAffected()
 	return rowsAffected, nil
 }
+
+// SetTaskLocation sets the coordinates of the task and clears its geocoding error.
+// It returns ErrTaskNotFound if the task does not exist.
+func (r *Repository) SetTaskLocation(ctx context.Context, taskID int, lat, lng float64) error {
+	result, err := r.db.Exec(ctx, SetTaskLocationSQL, taskID, lat, lng)
+	if err != nil {
+		return fmt.Errorf("failed to set task location: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("task with id %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	return nil
+}
@@ -5,8 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/UnknownOlympus/oracle/internal/repository"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -109,18 +111,344 @@ func TestGetTaskSummary(t *testing.T) {
 	})
 }
 
+func TestGetTaskSummaryByEmployee(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	employeeID := 42
+	to := time.Now()
+	from := to.AddDate(0, 0, -7)
+
+	t.Run("error - query task summaries", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskSummaryByEmployeeSQL)).
+			WithArgs(employeeID, from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTaskSummaryByEmployee(ctx, employeeID, from, to)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "error querying task")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get task summary", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskSummaryByEmployeeSQL)).
+			WithArgs(employeeID, from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_type", "count"}).AddRow("Repair", 3).AddRow("Total", 3),
+			)
+
+		summ, err := repo.GetTaskSummaryByEmployee(ctx, employeeID, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, summ, 2)
+		assert.Equal(t, "Repair", summ[0].Type)
+		assert.Equal(t, 3, summ[1].Count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTaskDurations(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(123456)
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("error - query task durations", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDurationsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTaskDurations(ctx, telegramID, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "error querying task durations")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan task durations", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDurationsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_type", "avg_seconds", "median_seconds"}).AddRow("Repair", "invalid", 60),
+			)
+
+		_, err = repo.GetTaskDurations(ctx, telegramID, from, to)
+
+		require.ErrorContains(t, err, "error scanning task durations")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get task durations", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDurationsSQL)).
+			WithArgs(telegramID, from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_type", "avg_seconds", "median_seconds"}).
+					AddRow("Connection", int64(7200), int64(3600)).
+					AddRow("Repair", int64(90000), int64(86400)),
+			)
+
+		durations, err := repo.GetTaskDurations(ctx, telegramID, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, durations, 2)
+		assert.Equal(t, "Connection", durations[0].Type)
+		assert.Equal(t, 2*time.Hour, durations[0].Average)
+		assert.Equal(t, time.Hour, durations[0].Median)
+		assert.Equal(t, 24*time.Hour, durations[1].Median)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTaskExecutorTelegramIDs(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	taskID := 42
+
+	t.Run("error - query task executors", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskExecutorTelegramIDsSQL)).
+			WithArgs(taskID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTaskExecutorTelegramIDs(ctx, taskID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "error querying task executors")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan task executor", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskExecutorTelegramIDsSQL)).
+			WithArgs(taskID).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id"}).AddRow("invalid"))
+
+		_, err = repo.GetTaskExecutorTelegramIDs(ctx, taskID)
+
+		require.ErrorContains(t, err, "error scanning task executor row")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get task executors", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskExecutorTelegramIDsSQL)).
+			WithArgs(taskID).
+			WillReturnRows(pgxmock.NewRows([]string{"telegram_id"}).AddRow(int64(111)).AddRow(int64(222)))
+
+		telegramIDs, err := repo.GetTaskExecutorTelegramIDs(ctx, taskID)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int64{111, 222}, telegramIDs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetTeamTaskSummary(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("error - query team summary", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTeamTaskSummarySQL)).
+			WithArgs(from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTeamTaskSummary(ctx, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "error querying team task summary")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan team summary", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTeamTaskSummarySQL)).
+			WithArgs(from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"employee_id", "shortname", "count"}).AddRow(1, "John D.", "invalid"),
+			)
+
+		_, err = repo.GetTeamTaskSummary(ctx, from, to)
+
+		require.ErrorContains(t, err, "error scanning team summary")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get team summary", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTeamTaskSummarySQL)).
+			WithArgs(from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"employee_id", "shortname", "count"}).
+					AddRow(1, "John D.", 12).
+					AddRow(2, "Jane S.", 7),
+			)
+
+		summ, err := repo.GetTeamTaskSummary(ctx, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, summ, 2)
+		assert.Equal(t, "John D.", summ[0].ShortName)
+		assert.Equal(t, 12, summ[0].Count)
+		assert.Equal(t, 2, summ[1].EmployeeID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetEmployeePerformance(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	to := time.Now()
+	from := to.AddDate(0, -1, 0)
+
+	t.Run("error - query employee performance", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeePerformanceSQL)).
+			WithArgs(from, to).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetEmployeePerformance(ctx, from, to)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "error querying employee performance")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan employee performance", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeePerformanceSQL)).
+			WithArgs(from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"employee_id", "shortname", "count", "avg_seconds"}).
+					AddRow(1, "John D.", 3, "invalid"),
+			)
+
+		_, err = repo.GetEmployeePerformance(ctx, from, to)
+
+		require.ErrorContains(t, err, "error scanning employee performance")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get employee performance", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeePerformanceSQL)).
+			WithArgs(from, to).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"employee_id", "shortname", "count", "avg_seconds"}).
+					AddRow(1, "John D.", 12, int64(5400)).
+					AddRow(2, "Jane S.", 7, int64(90000)),
+			)
+
+		performance, err := repo.GetEmployeePerformance(ctx, from, to)
+
+		require.NoError(t, err)
+		require.Len(t, performance, 2)
+		assert.Equal(t, "John D.", performance[0].ShortName)
+		assert.Equal(t, 12, performance[0].Count)
+		assert.Equal(t, 90*time.Minute, performance[0].AvgClosingTime)
+		assert.Equal(t, 25*time.Hour, performance[1].AvgClosingTime)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestGetActiveTasksByExecutor(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
 	telegramID := int64(123456)
-	query := `
-		SELECT t.task_id, t.description
-		FROM tasks t
-		JOIN task_executors te ON t.task_id = te.task_id
-		JOIN bot_users bu ON te.executor_id = bu.employee_id
-		WHERE bu.telegram_id = $1 AND t.is_closed = FALSE
-		ORDER BY t.creation_date DESC;
-	`
+	created := time.Now().Add(-72 * time.Hour)
 
 	t.Run("error - query error", func(t *testing.T) {
 		t.Parallel()
@@ -130,7 +458,7 @@ func TestGetActiveTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorSQL)).
 			WithArgs(telegramID).
 			WillReturnError(assert.AnError)
 
@@ -150,10 +478,11 @@ func TestGetActiveTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(
-				pgxmock.NewRows([]string{"task_id", "description"}).AddRow("invalid_id", "some descr"),
+				pgxmock.NewRows([]string{"task_id", "description", "creation_date", "sla_hours"}).
+					AddRow("invalid_id", "some descr", time.Now(), 0),
 			)
 
 		_, err = repo.GetActiveTasksByExecutor(ctx, telegramID)
@@ -171,10 +500,11 @@ func TestGetActiveTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(
-				pgxmock.NewRows([]string{"task_id", "description"}).AddRow(123, "descr").
+				pgxmock.NewRows([]string{"task_id", "description", "creation_date", "sla_hours"}).
+					AddRow(123, "descr", time.Now(), 0).
 					CloseError(assert.AnError),
 			)
 
@@ -193,10 +523,12 @@ func TestGetActiveTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(
-				pgxmock.NewRows([]string{"task_id", "description"}).AddRow(12345, "12345").AddRow(12346, "12346"),
+				pgxmock.NewRows([]string{"task_id", "description", "creation_date", "sla_hours"}).
+					AddRow(12345, "12345", created, 48).
+					AddRow(12346, "12346", created, 0),
 			)
 
 		tasks, err := repo.GetActiveTasksByExecutor(ctx, telegramID)
@@ -205,9 +537,135 @@ func TestGetActiveTasksByExecutor(t *testing.T) {
 		task1 := tasks[0]
 		assert.Equal(t, 12345, task1.ID)
 		assert.Equal(t, "12345", task1.Description)
+		assert.Equal(t, created, task1.CreationDate)
+		assert.Equal(t, 48*time.Hour, task1.SLA)
 		task2 := tasks[1]
 		assert.Equal(t, 12346, task2.ID)
 		assert.Equal(t, "12346", task2.Description)
+		assert.Zero(t, task2.SLA)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetActiveTasksByEmployee(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	employeeID := 42
+	created := time.Now().Add(-24 * time.Hour)
+
+	t.Run("error - query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByEmployeeSQL)).
+			WithArgs(employeeID).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetActiveTasksByEmployee(ctx, employeeID)
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "failed to query")
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get active tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByEmployeeSQL)).
+			WithArgs(employeeID).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"task_id", "description", "creation_date", "sla_hours"}).
+					AddRow(777, "descr", created, 24),
+			)
+
+		tasks, err := repo.GetActiveTasksByEmployee(ctx, employeeID)
+
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, 777, tasks[0].ID)
+		assert.Equal(t, created, tasks[0].CreationDate)
+		assert.Equal(t, 24*time.Hour, tasks[0].SLA)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetActiveTasksByExecutorFiltered(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(123456)
+	filter := models.ActiveTaskFilter{OldestFirst: true, WithCoordinates: true}
+	created := time.Now().Add(-72 * time.Hour)
+	columns := []string{"task_id", "description", "type_name", "creation_date", "sla_hours"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorFilteredSQL)).
+			WithArgs(telegramID, true, false, true).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow(12345, "oldest", "Connection", created, 48).
+				AddRow(12346, "newest", "Repair", created, 0))
+
+		tasks, err := repo.GetActiveTasksByExecutorFiltered(ctx, telegramID, filter)
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.ActiveTask{
+			{ID: 12345, Description: "oldest", Type: "Connection", CreationDate: created, SLA: 48 * time.Hour},
+			{ID: 12346, Description: "newest", Type: "Repair", CreationDate: created},
+		}, tasks)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorFilteredSQL)).
+			WithArgs(telegramID, true, false, true).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetActiveTasksByExecutorFiltered(ctx, telegramID, filter)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query filtered active tasks")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - scan active tasks", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetActiveTasksByExecutorFilteredSQL)).
+			WithArgs(telegramID, true, false, true).
+			WillReturnRows(pgxmock.NewRows(columns).
+				AddRow("invalid_id", "descr", "Repair", created, 0))
+
+		_, err = repo.GetActiveTasksByExecutorFiltered(ctx, telegramID, filter)
+
+		require.ErrorContains(t, err, "failed to scan")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
@@ -217,30 +675,6 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 	telegramID := int64(123456)
 	to := time.Now()
 	from := to.AddDate(0, -1, 0)
-	query := `
-		SELECT
-			t.task_id,
-			tt.type_name,
-			t.creation_date,
-			t.closing_date,
-			t.description,
-			t.address,
-			ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
-			t.comments
-		FROM tasks t
-		JOIN task_executors te ON t.task_id = te.task_id
-		JOIN bot_users bu ON te.executor_id = bu.employee_id
-		JOIN task_types tt ON t.task_type_id = tt.type_id
-		LEFT JOIN task_customers tc ON t.task_id = tc.task_id
-		LEFT JOIN customers c ON tc.customer_id = c.id
-		WHERE
-			bu.telegram_id = $1
-			AND t.closing_date >= $2
-			AND t.closing_date <= $3
-			AND t.is_closed = TRUE
-		GROUP BY t.task_id, tt.type_name
-		ORDER BY tt.type_name, t.creation_date;
-	`
 
 	t.Run("error - query error", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -249,7 +683,7 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTasksByExecutorSQL)).
 			WithArgs(telegramID, from, to).
 			WillReturnError(assert.AnError)
 
@@ -268,15 +702,15 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTasksByExecutorSQL)).
 			WithArgs(telegramID, from, to).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
 					"task_id", "type_name", "creation_date", "closing_date", "description",
-					"address", "customer_names", "comments",
+					"address", "customer_names", "comments", "executors",
 				}).
 					AddRow("invalid_id", "repair", time.Now(), time.Now(), "descr",
-						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}),
+						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}, []string{"John", "Jane"}),
 			)
 
 		_, err = repo.GetCompletedTasksByExecutor(ctx, telegramID, from, to)
@@ -293,15 +727,15 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTasksByExecutorSQL)).
 			WithArgs(telegramID, from, to).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
 					"task_id", "type_name", "creation_date", "closing_date", "description",
-					"address", "customer_names", "comments",
+					"address", "customer_names", "comments", "executors",
 				}).
 					AddRow(12345, "repair", time.Now(), time.Now(), "descr",
-						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}).
+						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}, []string{"John", "Jane"}).
 					CloseError(assert.AnError),
 			)
 
@@ -321,15 +755,15 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 
 		now := time.Now()
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCompletedTasksByExecutorSQL)).
 			WithArgs(telegramID, from, to).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
 					"task_id", "type_name", "creation_date", "closing_date", "description",
-					"address", "customer_names", "comments",
+					"address", "customer_names", "comments", "executors",
 				}).
 					AddRow(12345, "repair", now, now, "descr",
-						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}),
+						"test addr", []string{"test user"}, []string{"1 comm", "2 comm"}, []string{"John", "Jane"}),
 			)
 
 		tasks, err := repo.GetCompletedTasksByExecutor(ctx, telegramID, from, to)
@@ -344,6 +778,7 @@ func TestGetCompletedTasksByExecutor(t *testing.T) {
 		assert.Equal(t, "test addr", task.Address)
 		assert.Equal(t, []string{"test user"}, task.CustomerNames)
 		assert.Equal(t, []string{"1 comm", "2 comm"}, task.Comments)
+		assert.Equal(t, []string{"John", "Jane"}, task.Executors)
 	})
 }
 
@@ -352,27 +787,6 @@ func TestGetTaskDetailsByID(t *testing.T) {
 	ctx := t.Context()
 	taskID := 12345
 	now := time.Now()
-	query := `
-		SELECT
-			t.task_id,
-			tt.type_name,
-			t.creation_date,
-			t.description,
-			t.address,
-			ARRAY_AGG(DISTINCT c.name) FILTER (WHERE c.name IS NOT NULL) AS customer_names,
-			t.comments,
-			t.latitude,
-			t.longitude,
-			COALESCE(ARRAY_AGG(e.shortname) FILTER (WHERE e.shortname IS NOT NULL), '{}') as executors
-		FROM tasks t
-		JOIN task_types tt ON t.task_type_id = tt.type_id
-		LEFT JOIN task_executors te ON t.task_id = te.task_id
-		LEFT JOIN employees e ON te.executor_id = e.id
-		LEFT JOIN task_customers tc ON t.task_id = tc.task_id
-		LEFT JOIN customers c ON tc.customer_id = c.id
-		WHERE t.task_id = $1
-		GROUP BY t.task_id, tt.type_name;
-	`
 
 	t.Run("error - query task details", func(t *testing.T) {
 		t.Parallel()
@@ -382,7 +796,7 @@ func TestGetTaskDetailsByID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDetailsByIDSQL)).
 			WithArgs(taskID).
 			WillReturnError(assert.AnError)
 
@@ -402,7 +816,7 @@ func TestGetTaskDetailsByID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDetailsByIDSQL)).
 			WithArgs(taskID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -410,6 +824,7 @@ func TestGetTaskDetailsByID(t *testing.T) {
 
 		require.Error(t, err)
 		require.ErrorContains(t, err, "not found")
+		require.ErrorIs(t, err, repository.ErrTaskNotFound)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
@@ -421,7 +836,7 @@ func TestGetTaskDetailsByID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDetailsByIDSQL)).
 			WithArgs(taskID).
 			WillReturnRows(mock.NewRows([]string{
 				"task_id", "type_name", "creation_date", "description",
@@ -429,6 +844,12 @@ func TestGetTaskDetailsByID(t *testing.T) {
 			}).
 				AddRow(123, "type", now, "descr", "addr", []string{"test user"}, []string{"1", "2"}, 12.345, 23.456, []string{"test", "executor 1"}),
 			)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskHistorySQL)).
+			WithArgs(taskID).
+			WillReturnRows(mock.NewRows([]string{"event_type", "value", "actor", "created_at"}).
+				AddRow("status", "In progress", "John D.", now).
+				AddRow("assigned", "Jane S.", "", now),
+			)
 
 		task, err := repo.GetTaskDetailsByID(ctx, taskID)
 
@@ -443,6 +864,40 @@ func TestGetTaskDetailsByID(t *testing.T) {
 		assert.InEpsilon(t, 12.345, task.Latitude.Float64, 0.001)
 		assert.InEpsilon(t, 23.456, task.Longitude.Float64, 0.001)
 		assert.Equal(t, []string{"test", "executor 1"}, task.Executors)
+		require.Len(t, task.History, 2)
+		assert.Equal(t, "status", task.History[0].Type)
+		assert.Equal(t, "In progress", task.History[0].Value)
+		assert.Equal(t, "John D.", task.History[0].Actor)
+		assert.Equal(t, "Jane S.", task.History[1].Value)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query task history", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskDetailsByIDSQL)).
+			WithArgs(taskID).
+			WillReturnRows(mock.NewRows([]string{
+				"task_id", "type_name", "creation_date", "description",
+				"address", "customer_names", "comments", "latitude", "longitude", "executors",
+			}).
+				AddRow(123, "type", now, "descr", "addr", []string{}, []string{}, 12.345, 23.456, []string{}),
+			)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTaskHistorySQL)).
+			WithArgs(taskID).
+			WillReturnError(assert.AnError)
+
+		task, err := repo.GetTaskDetailsByID(ctx, taskID)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query task history")
+		assert.Nil(t, task)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -452,29 +907,6 @@ func TestGetTasksInRadius(t *testing.T) {
 	lat := float32(12.345)
 	lng := float32(23.456)
 	radius := 10
-	query := `
-	SELECT
-		task_id,
-		description
-	FROM (
-		SELECT
-			*,
-			(
-				6371 * acos(
-					cos(radians($1)) * cos(radians(latitude)) *
-					cos(radians(longitude) - radians($2)) +
-					sin(radians($1)) * sin(radians(latitude))
-				)
-			) AS distance_km
-		FROM tasks
-		WHERE
-			latitude BETWEEN ($1 - ($3 / 111.0)) AND ($1 + ($3 / 111.0))
-			AND longitude BETWEEN ($2 - ($3 / (111.0 * cos(radians($1))))) AND ($2 + ($3 / (111.0 * cos(radians($1)))))
-			AND is_closed = false
-	) AS subquery
-	WHERE distance_km <= $3
-	ORDER BY distance_km;
-	`
 
 	t.Run("error - query error", func(t *testing.T) {
 		t.Parallel()
@@ -484,7 +916,7 @@ func TestGetTasksInRadius(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
 			WithArgs(lat, lng, radius).
 			WillReturnError(assert.AnError)
 
@@ -504,7 +936,7 @@ func TestGetTasksInRadius(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
 			WithArgs(lat, lng, radius).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"task_id", "description"}).AddRow("invalid_id", "some descr"),
@@ -525,7 +957,7 @@ func TestGetTasksInRadius(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
 			WithArgs(lat, lng, radius).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"task_id", "description"}).AddRow(123, "descr").
@@ -547,7 +979,7 @@ func TestGetTasksInRadius(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
 			WithArgs(lat, lng, radius).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"task_id", "description"}).AddRow(12345, "12345").AddRow(12346, "12346"),
@@ -564,17 +996,80 @@ func TestGetTasksInRadius(t *testing.T) {
 		assert.Equal(t, "12346", task2.Description)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("success - postgis", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+		repo.EnablePostGIS()
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusPostGISSQL)).
+			WithArgs(lat, lng, radius).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "description"}).AddRow(12345, "12345"))
+
+		tasks, err := repo.GetTasksInRadius(ctx, lat, lng, radius)
+
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("postgis unavailable - falls back to haversine", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+		repo.EnablePostGIS()
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusPostGISSQL)).
+			WithArgs(lat, lng, radius).
+			WillReturnError(&pgconn.PgError{Code: "42883", Message: "function st_dwithin does not exist"})
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
+			WithArgs(lat, lng, radius).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "description"}).AddRow(12345, "12345"))
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusSQL)).
+			WithArgs(lat, lng, radius).
+			WillReturnRows(pgxmock.NewRows([]string{"task_id", "description"}))
+
+		tasks, err := repo.GetTasksInRadius(ctx, lat, lng, radius)
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+
+		// PostGIS is not retried once it turned out to be unavailable.
+		_, err = repo.GetTasksInRadius(ctx, lat, lng, radius)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - postgis query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+		repo.EnablePostGIS()
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetTasksInRadiusPostGISSQL)).
+			WithArgs(lat, lng, radius).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetTasksInRadius(ctx, lat, lng, radius)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to query near tasks")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestGetCustomersByTaskID(t *testing.T) {
 	ctx := t.Context()
 	taskID := int64(123456)
-	query := `
-		SELECT external_id, name, login
-		FROM customers c
-		LEFT JOIN task_customers tc ON tc.customer_id = c.id
-		WHERE tc.task_id = $1;
-	`
 
 	t.Run("error - query error", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -583,7 +1078,7 @@ func TestGetCustomersByTaskID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersByTaskIDSQL)).
 			WithArgs(taskID).
 			WillReturnError(assert.AnError)
 
@@ -602,7 +1097,7 @@ func TestGetCustomersByTaskID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersByTaskIDSQL)).
 			WithArgs(taskID).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
@@ -624,7 +1119,7 @@ func TestGetCustomersByTaskID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(taskID).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersByTaskIDSQL)).WithArgs(taskID).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
 					"external_id", "name", "login",
@@ -647,7 +1142,7 @@ func TestGetCustomersByTaskID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersByTaskIDSQL)).
 			WithArgs(taskID).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
@@ -672,7 +1167,7 @@ func TestGetCustomersByTaskID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersByTaskIDSQL)).
 			WithArgs(taskID).
 			WillReturnRows(
 				pgxmock.NewRows([]string{
@@ -690,3 +1185,149 @@ func TestGetCustomersByTaskID(t *testing.T) {
 		assert.Equal(t, "johnd", customer.Login)
 	})
 }
+
+func TestGetCustomersForTaskIDs(t *testing.T) {
+	ctx := t.Context()
+	taskIDs := []int64{1, 2}
+	columns := []string{"task_id", "external_id", "name", "login"}
+
+	t.Run("success", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersForTaskIDsSQL)).
+			WithArgs(taskIDs).
+			WillReturnRows(
+				pgxmock.NewRows(columns).
+					AddRow(int64(1), int64(10), "John Doe", "johnd").
+					AddRow(int64(1), nil, "Jane Doe", "janed").
+					AddRow(int64(2), int64(10), "John Doe", "johnd"),
+			)
+
+		customers, err := repo.GetCustomersForTaskIDs(ctx, taskIDs)
+
+		require.NoError(t, err)
+		require.Len(t, customers, 2)
+		require.Len(t, customers[1], 2)
+		assert.Equal(t, int64(10), customers[1][0].ID)
+		assert.Equal(t, int64(0), customers[1][1].ID)
+		assert.Equal(t, "Jane Doe", customers[1][1].Fullname)
+		assert.Equal(t, "johnd", customers[2][0].Login)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - no task IDs", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		customers, err := repo.GetCustomersForTaskIDs(ctx, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, customers)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersForTaskIDsSQL)).
+			WithArgs(taskIDs).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetCustomersForTaskIDs(ctx, taskIDs)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to select customers of 2 tasks")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - rows error", func(t *testing.T) {
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetCustomersForTaskIDsSQL)).
+			WithArgs(taskIDs).
+			WillReturnRows(
+				pgxmock.NewRows(columns).
+					AddRow(int64(1), int64(10), "John Doe", "johnd").
+					CloseError(assert.AnError),
+			)
+
+		_, err = repo.GetCustomersForTaskIDs(ctx, taskIDs)
+
+		require.ErrorContains(t, err, "failed to read rows")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetTaskLocation(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	taskID := 12345
+	lat, lng := 50.4501, 30.5234
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetTaskLocationSQL)).
+			WithArgs(taskID, lat, lng).WillReturnError(assert.AnError)
+
+		err = repo.SetTaskLocation(ctx, taskID, lat, lng)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to set task location")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - task not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetTaskLocationSQL)).
+			WithArgs(taskID, lat, lng).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		err = repo.SetTaskLocation(ctx, taskID, lat, lng)
+
+		require.ErrorIs(t, err, repository.ErrTaskNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.SetTaskLocationSQL)).
+			WithArgs(taskID, lat, lng).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err = repo.SetTaskLocation(ctx, taskID, lat, lng)
+
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
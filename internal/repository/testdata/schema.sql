@@ -0,0 +1,56 @@
+-- Tables owned by the task synchronization service, which the migrations of the bot build on.
+-- Only the columns read or written by the bot are kept; update them when the service changes
+-- its schema, so the integration tests catch queries going out of sync.
+CREATE TABLE employees (
+    id         SERIAL PRIMARY KEY,
+    fullname   TEXT        NOT NULL,
+    shortname  TEXT        NOT NULL,
+    position   TEXT        NOT NULL DEFAULT '',
+    email      TEXT        NOT NULL UNIQUE,
+    phone      TEXT        NOT NULL DEFAULT '',
+    is_admin   BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE bot_users (
+    telegram_id BIGINT PRIMARY KEY,
+    employee_id INT    NOT NULL UNIQUE REFERENCES employees (id),
+    locale      TEXT
+);
+
+CREATE TABLE task_types (
+    type_id   SERIAL PRIMARY KEY,
+    type_name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE tasks (
+    task_id       INT PRIMARY KEY,
+    task_type_id  INT         NOT NULL REFERENCES task_types (type_id),
+    creation_date TIMESTAMPTZ NOT NULL,
+    closing_date  TIMESTAMPTZ,
+    description   TEXT        NOT NULL DEFAULT '',
+    address       TEXT,
+    comments      TEXT[],
+    is_closed     BOOLEAN     NOT NULL DEFAULT FALSE,
+    latitude      DOUBLE PRECISION,
+    longitude     DOUBLE PRECISION
+);
+
+CREATE TABLE task_executors (
+    task_id     INT NOT NULL REFERENCES tasks (task_id),
+    executor_id INT NOT NULL REFERENCES employees (id),
+    PRIMARY KEY (task_id, executor_id)
+);
+
+CREATE TABLE customers (
+    id          SERIAL PRIMARY KEY,
+    external_id BIGINT NOT NULL UNIQUE,
+    name        TEXT   NOT NULL,
+    login       TEXT   NOT NULL DEFAULT ''
+);
+
+CREATE TABLE task_customers (
+    task_id     INT NOT NULL REFERENCES tasks (task_id),
+    customer_id INT NOT NULL REFERENCES customers (id),
+    PRIMARY KEY (task_id, customer_id)
+);
@@ -0,0 +1,68 @@
+-- Fixtures of the integration tests, loaded after the migrations.
+INSERT INTO employees (id, fullname, shortname, email, is_admin) VALUES
+    (1, 'Ivan Petrenko', 'Ivan P.', 'ivan@example.com', TRUE),
+    (2, 'Olena Kovalenko', 'Olena K.', 'olena@example.com', FALSE),
+    (3, 'Petro Shevchenko', 'Petro S.', 'petro@example.com', FALSE);
+
+-- Petro logged out, his tasks are not shown to the account anymore.
+INSERT INTO bot_users (telegram_id, employee_id, deleted_at) VALUES
+    (1001, 1, NULL),
+    (1002, 2, NULL),
+    (1003, 3, NOW());
+
+INSERT INTO task_types (type_id, type_name) VALUES
+    (1, 'Connection'),
+    (2, 'Repair');
+
+INSERT INTO customers (id, external_id, name, login) VALUES
+    (1, 5001, 'Customer One', 'one'),
+    (2, 5002, 'Customer Two', 'two');
+
+-- Tasks 101, 102 and 108 are closed in June 2025 and 103 in July. The open tasks 104 and 105
+-- are in the center of Kyiv, 106 is in Kharkiv and the closed 107 is next to 104.
+INSERT INTO tasks (task_id, task_type_id, creation_date, closing_date, description, address, comments,
+    is_closed, latitude, longitude) VALUES
+    (101, 1, '2025-06-01 09:00:00+00', '2025-06-05 12:00:00+00', 'Connect the flat', 'Main St 1',
+        ARRAY['Done'], TRUE, 50.4501, 30.5234),
+    (102, 2, '2025-06-08 10:00:00+00', '2025-06-10 15:00:00+00', 'Replace the router', 'Main St 2',
+        NULL, TRUE, NULL, NULL),
+    (103, 2, '2025-06-28 10:00:00+00', '2025-07-02 11:00:00+00', 'Fix the cable', 'Main St 3',
+        NULL, TRUE, NULL, NULL),
+    (104, 1, '2025-06-20 08:00:00+00', NULL, 'Connect the office', 'Khreshchatyk 1',
+        NULL, FALSE, 50.4501, 30.5234),
+    (105, 2, '2025-06-21 08:00:00+00', NULL, 'Repair the switch', 'Khreshchatyk 20',
+        NULL, FALSE, 50.4600, 30.5300),
+    (106, 1, '2025-06-22 08:00:00+00', NULL, 'Connect the house', 'Sumska 1',
+        NULL, FALSE, 49.9935, 36.2304),
+    (107, 2, '2025-06-02 08:00:00+00', '2025-06-03 08:00:00+00', 'Repair the port', 'Khreshchatyk 2',
+        NULL, TRUE, 50.4502, 30.5235),
+    (108, 1, '2025-06-11 08:00:00+00', '2025-06-12 08:00:00+00', 'Connect the shop', 'Main St 8',
+        NULL, TRUE, NULL, NULL);
+
+INSERT INTO task_executors (task_id, executor_id) VALUES
+    (101, 1),
+    (101, 2),
+    (102, 1),
+    (103, 1),
+    (104, 1),
+    (107, 2),
+    (108, 3);
+
+INSERT INTO task_customers (task_id, customer_id) VALUES
+    (101, 1),
+    (102, 1),
+    (102, 2);
+
+-- Both Ivan and the logged out Petro subscribed to the weekly report and the morning digest.
+INSERT INTO report_subscriptions (telegram_id) VALUES
+    (1001),
+    (1003);
+
+INSERT INTO digest_subscriptions (telegram_id, timezone) VALUES
+    (1001, 'Europe/Kyiv'),
+    (1003, 'Europe/Kyiv');
+
+-- The logged out Petro is on duty in the week of June 2, 2025, Ivan in the week after.
+INSERT INTO oncall_weeks (week_start, telegram_id, created_by) VALUES
+    ('2025-06-02', 1003, 1001),
+    ('2025-06-09', 1001, 1001);
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer records a span for every query executed by the connection pool.
+type queryTracer struct {
+	tracer trace.Tracer
+}
+
+// NewQueryTracer returns a pgx tracer recording query spans with the given tracer provider.
+func NewQueryTracer(provider trace.TracerProvider) pgx.QueryTracer {
+	return &queryTracer{tracer: provider.Tracer("github.com/UnknownOlympus/oracle/internal/repository")}
+}
+
+// TraceQueryStart starts a span named after the SQL operation, e.g. "db.SELECT".
+func (t *queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation, _, _ := strings.Cut(strings.TrimSpace(data.SQL), " ")
+	ctx, _ = t.tracer.Start(ctx, "db."+strings.ToUpper(operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", data.SQL),
+		),
+	)
+	return ctx
+}
+
+// TraceQueryEnd ends the span started by TraceQueryStart.
+func (t *queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
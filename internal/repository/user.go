@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/jackc/pgx/v5"
@@ -22,7 +23,8 @@ var (
 // LinkTelegramIDByEmail links a Telegram ID to an employee's email address in the database.
 // It begins a transaction, checks if the employee exists by the provided email,
 // verifies if the Telegram ID is already authenticated, and attempts to insert the
-// Telegram ID and employee ID into the bot_users table. If the employee does not exist,
+// Telegram ID and employee ID into the bot_users table. Users unlinked from the Telegram ID or
+// the employee within the grace period are removed first. If the employee does not exist,
 // or if the Telegram ID is already linked, appropriate errors are returned.
 // The transaction is committed if the insertion is successful, otherwise it is rolled back.
 func (r *Repository) LinkTelegramIDByEmail(ctx context.Context, telegramID int64, email string) error {
@@ -33,7 +35,7 @@ func (r *Repository) LinkTelegramIDByEmail(ctx context.Context, telegramID int64
 	defer tx.Rollback(ctx) //nolint:errcheck // omitted because checking for errors will not affect the function
 
 	var employeeID int
-	err = tx.QueryRow(ctx, "SELECT id FROM employees WHERE email = $1", email).Scan(&employeeID)
+	err = tx.QueryRow(ctx, GetEmployeeIDByEmailSQL, email).Scan(&employeeID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrUserNotFound
@@ -49,12 +51,11 @@ func (r *Repository) LinkTelegramIDByEmail(ctx context.Context, telegramID int64
 		return ErrIDExists
 	}
 
-	cmdTag, err := tx.Exec(
-		ctx,
-		"INSERT INTO bot_users (telegram_id, employee_id) VALUES ($1, $2) ON CONFLICT (employee_id) DO NOTHING",
-		telegramID,
-		employeeID,
-	)
+	if _, err = tx.Exec(ctx, DeleteUnlinkedUserSQL, telegramID, employeeID); err != nil {
+		return fmt.Errorf("failed to remove unlinked users: %w", err)
+	}
+
+	cmdTag, err := tx.Exec(ctx, LinkTelegramIDSQL, telegramID, employeeID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return ErrUserAlreadyLinked
@@ -69,14 +70,39 @@ func (r *Repository) LinkTelegramIDByEmail(ctx context.Context, telegramID int64
 	return tx.Commit(ctx)
 }
 
+// CheckEmailLink checks whether the Telegram ID could be linked to the employee with the given
+// email without linking it. It returns ErrUserNotFound, ErrUserAlreadyLinked or ErrIDExists
+// in the same cases LinkTelegramIDByEmail would.
+func (r *Repository) CheckEmailLink(ctx context.Context, telegramID int64, email string) error {
+	var linked bool
+	if err := r.db.QueryRow(ctx, CheckEmailLinkSQL, email).Scan(&linked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to find employee by email: %w", err)
+	}
+	if linked {
+		return ErrUserAlreadyLinked
+	}
+
+	isExists, err := r.IsUserAuthenticated(ctx, telegramID)
+	if err != nil {
+		return fmt.Errorf("failed to get user by telegram ID: %w", err)
+	}
+	if isExists {
+		return ErrIDExists
+	}
+
+	return nil
+}
+
 // IsUserAuthenticated checks if a user is authenticated based on their Telegram ID.
 // It returns true if the user exists in the bot_users table, and false otherwise.
 // In case of an error during the database query, it returns false along with the error.
 func (r *Repository) IsUserAuthenticated(ctx context.Context, telegramID int64) (bool, error) {
 	var exists bool
 
-	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM bot_users WHERE telegram_id = $1)", telegramID).
-		Scan(&exists)
+	err := r.db.QueryRow(ctx, IsUserAuthenticatedSQL, telegramID).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check user authentication: %w", err)
 	}
@@ -84,18 +110,39 @@ func (r *Repository) IsUserAuthenticated(ctx context.Context, telegramID int64)
 	return exists, nil
 }
 
-// DeleteUserByID removes a user from the bot_users table by their telegram ID.
-// It takes a context and the telegram ID of the user to be deleted as parameters.
-// If the deletion fails, it returns an error indicating the failure reason.
-func (r *Repository) DeleteUserByID(ctx context.Context, telegramID int64) error {
-	_, err := r.db.Exec(ctx, "DELETE FROM bot_users WHERE telegram_id = $1", telegramID)
+// UnlinkUser soft-deletes the user from the bot_users table by their telegram ID. The user is
+// logged out right away, but keeps their subscriptions and settings until the row is purged,
+// so RestoreUser can undo the logout.
+func (r *Repository) UnlinkUser(ctx context.Context, telegramID int64) error {
+	_, err := r.db.Exec(ctx, UnlinkUserSQL, telegramID)
 	if err != nil {
-		return fmt.Errorf("failed to delete user %d from bot_users: %w", telegramID, err)
+		return fmt.Errorf("failed to unlink user %d from bot_users: %w", telegramID, err)
 	}
 
 	return nil
 }
 
+// RestoreUser undoes the logout of the user, if they were unlinked at or after since. It reports
+// false if the user is not unlinked or the grace period is over.
+func (r *Repository) RestoreUser(ctx context.Context, telegramID int64, since time.Time) (bool, error) {
+	cmdTag, err := r.db.Exec(ctx, RestoreUserSQL, telegramID, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to restore user %d: %w", telegramID, err)
+	}
+
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// PurgeUnlinkedUsers hard-deletes the users unlinked before the given time and returns their count.
+func (r *Repository) PurgeUnlinkedUsers(ctx context.Context, before time.Time) (int64, error) {
+	cmdTag, err := r.db.Exec(ctx, PurgeUnlinkedUsersSQL, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge unlinked users: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
+
 // GetEmployee retrieves an employee's details from the database using their Telegram ID.
 // It returns the employee's information as a models.Employee struct and an error if the operation fails.
 //
@@ -108,12 +155,8 @@ func (r *Repository) DeleteUserByID(ctx context.Context, telegramID int64) error
 //   - error: An error if the retrieval fails.
 func (r *Repository) GetEmployee(ctx context.Context, telegramID int64) (models.Employee, error) {
 	var employee models.Employee
-	query := `
-		SELECT id, fullname, shortname, position, email, phone, is_admin FROM employees
-		WHERE id = (SELECT employee_id FROM bot_users WHERE telegram_id = $1);		
-`
 
-	err := r.db.QueryRow(ctx, query, telegramID).Scan(
+	err := r.db.QueryRow(ctx, GetEmployeeSQL, telegramID).Scan(
 		&employee.ID, &employee.FullName, &employee.ShortName, &employee.Position, &employee.Email, &employee.Phone, &employee.IsAdmin,
 	)
 	if err != nil {
@@ -123,6 +166,21 @@ func (r *Repository) GetEmployee(ctx context.Context, telegramID int64) (models.
 	return employee, nil
 }
 
+// GetEmployeeByID retrieves the details of the employee identified by employeeID.
+func (r *Repository) GetEmployeeByID(ctx context.Context, employeeID int) (models.Employee, error) {
+	var employee models.Employee
+
+	err := r.db.QueryRow(ctx, GetEmployeeByIDSQL, employeeID).Scan(
+		&employee.ID, &employee.FullName, &employee.ShortName, &employee.Position, &employee.Email, &employee.Phone,
+		&employee.IsAdmin,
+	)
+	if err != nil {
+		return models.Employee{}, fmt.Errorf("failed to get employee data: %w", err)
+	}
+
+	return employee, nil
+}
+
 // IsAdmin retrieves a bool value which respond if employee is admin.
 //
 // Parameters:
@@ -134,12 +192,8 @@ func (r *Repository) GetEmployee(ctx context.Context, telegramID int64) (models.
 //   - error: An error if the retrieval fails.
 func (r *Repository) IsAdmin(ctx context.Context, telegramID int64) (bool, error) {
 	var isAdmin bool
-	query := `
-		SELECT is_admin FROM employees
-		WHERE id = (SELECT employee_id FROM bot_users WHERE telegram_id = $1);
-	`
 
-	err := r.db.QueryRow(ctx, query, telegramID).Scan(&isAdmin)
+	err := r.db.QueryRow(ctx, IsAdminSQL, telegramID).Scan(&isAdmin)
 	if err != nil {
 		return false, fmt.Errorf("failed to scan admin from employees: %w", err)
 	}
@@ -148,8 +202,7 @@ func (r *Repository) IsAdmin(ctx context.Context, telegramID int64) (bool, error
 }
 
 func (r *Repository) GetAllTgUserIDs(ctx context.Context) ([]int64, error) {
-	query := "SELECT telegram_id from bot_users"
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, GetAllTgUserIDsSQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all telegram user IDs: %w", err)
 	}
@@ -174,13 +227,7 @@ func (r *Repository) GetAllTgUserIDs(ctx context.Context) ([]int64, error) {
 }
 
 func (r *Repository) GetAdmins(ctx context.Context) ([]models.BotUser, error) {
-	query := `
-		SELECT telegram_id, employee_id 
-		FROM bot_users bu 
-		LEFT JOIN employees e ON e.id = bu.employee_id
-		WHERE e.is_admin = TRUE
-	`
-	rows, err := r.db.Query(ctx, query)
+	rows, err := r.db.Query(ctx, GetAdminsSQL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all bot users with admin privileges: %w", err)
 	}
@@ -210,8 +257,7 @@ func (r *Repository) GetAdmins(ctx context.Context) ([]models.BotUser, error) {
 // It updates the locale column in the bot_users table.
 // If the user doesn't exist, it returns an error.
 func (r *Repository) SetUserLanguage(ctx context.Context, telegramID int64, langCode string) error {
-	query := "UPDATE bot_users SET locale = $1 WHERE telegram_id = $2"
-	cmdTag, err := r.db.Exec(ctx, query, langCode, telegramID)
+	cmdTag, err := r.db.Exec(ctx, SetUserLanguageSQL, langCode, telegramID)
 	if err != nil {
 		return fmt.Errorf("failed to set user language: %w", err)
 	}
@@ -228,9 +274,8 @@ func (r *Repository) SetUserLanguage(ctx context.Context, telegramID int64, lang
 // If the user doesn't exist or language is not set, it returns "en" as default.
 func (r *Repository) GetUserLanguage(ctx context.Context, telegramID int64) (string, error) {
 	var langCode pgtype.Text
-	query := "SELECT locale FROM bot_users WHERE telegram_id = $1"
 
-	err := r.db.QueryRow(ctx, query, telegramID).Scan(&langCode)
+	err := r.db.QueryRow(ctx, GetUserLanguageSQL, telegramID).Scan(&langCode)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return "en", nil
@@ -244,3 +289,35 @@ func (r *Repository) GetUserLanguage(ctx context.Context, telegramID int64) (str
 
 	return langCode.String, nil
 }
+
+// GetUserLanguages retrieves the language preferences of several users at once, keyed by
+// Telegram ID. Users without a preference or not found are left out of the map.
+func (r *Repository) GetUserLanguages(ctx context.Context, telegramIDs []int64) (map[int64]string, error) {
+	languages := make(map[int64]string, len(telegramIDs))
+	if len(telegramIDs) == 0 {
+		return languages, nil
+	}
+
+	rows, err := r.db.Query(ctx, GetUserLanguagesSQL, telegramIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get languages of %d users: %w", len(telegramIDs), err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var telegramID int64
+		var langCode pgtype.Text
+		if err = rows.Scan(&telegramID, &langCode); err != nil {
+			return nil, fmt.Errorf("failed to scan user language row: %w", err)
+		}
+		if langCode.Valid && langCode.String != "" {
+			languages[telegramID] = langCode.String
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	return languages, nil
+}
@@ -3,6 +3,7 @@ package repository_test
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/UnknownOlympus/oracle/internal/models"
 	"github.com/UnknownOlympus/oracle/internal/repository"
@@ -13,22 +14,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-const selectEmployee = "SELECT id FROM employees WHERE email = \\$1"
-
-const selectExistsEmployee = "SELECT EXISTS \\(SELECT 1 FROM bot_users WHERE telegram_id = \\$1\\)"
-
-const deleteUser = "DELETE FROM bot_users WHERE telegram_id = \\$1"
-
-const insertIntoBotUsers = `
-	INSERT INTO bot_users (telegram_id, employee_id)
-	VALUES ($1, $2) ON CONFLICT (employee_id) DO NOTHING
-`
-
-const selectGetEmployee = `
-	SELECT id, fullname, shortname, position, email, phone, is_admin FROM employees
-	WHERE id = (SELECT employee_id FROM bot_users WHERE telegram_id = $1);		
-`
-
 func TestLinkTelegramIDByEmail(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -63,7 +48,8 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).WithArgs(email).WillReturnError(pgx.ErrNoRows)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
+			WithArgs(email).WillReturnError(pgx.ErrNoRows)
 
 		err = repo.LinkTelegramIDByEmail(ctx, telegramID, email)
 
@@ -81,7 +67,8 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).WithArgs(email).WillReturnError(assert.AnError)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
+			WithArgs(email).WillReturnError(assert.AnError)
 
 		err = repo.LinkTelegramIDByEmail(ctx, telegramID, email)
 
@@ -100,10 +87,11 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).WithArgs(telegramID).WillReturnError(assert.AnError)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
 
 		err = repo.LinkTelegramIDByEmail(ctx, telegramID, email)
 
@@ -122,10 +110,10 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
 
@@ -136,6 +124,32 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("error - failed to remove unlinked users", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
+			WithArgs(email).
+			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteUnlinkedUserSQL)).
+			WithArgs(telegramID, employeeID).
+			WillReturnError(assert.AnError)
+
+		err = repo.LinkTelegramIDByEmail(ctx, telegramID, email)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to remove unlinked users")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("error - user already linked", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
@@ -145,13 +159,16 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
-		mock.ExpectExec(regexp.QuoteMeta(insertIntoBotUsers)).
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteUnlinkedUserSQL)).
+			WithArgs(telegramID, employeeID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(regexp.QuoteMeta(repository.LinkTelegramIDSQL)).
 			WithArgs(telegramID, employeeID).
 			WillReturnError(pgx.ErrNoRows)
 
@@ -171,13 +188,16 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		repo := repository.NewRepository(mock)
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
-		mock.ExpectExec(regexp.QuoteMeta(insertIntoBotUsers)).
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteUnlinkedUserSQL)).
+			WithArgs(telegramID, employeeID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(regexp.QuoteMeta(repository.LinkTelegramIDSQL)).
 			WithArgs(telegramID, employeeID).
 			WillReturnError(assert.AnError)
 
@@ -200,13 +220,16 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		cmdTag := pgconn.NewCommandTag("CREATE TABLE")
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
-		mock.ExpectExec(regexp.QuoteMeta(insertIntoBotUsers)).
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteUnlinkedUserSQL)).
+			WithArgs(telegramID, employeeID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(regexp.QuoteMeta(repository.LinkTelegramIDSQL)).
 			WithArgs(telegramID, employeeID).
 			WillReturnResult(cmdTag)
 
@@ -228,13 +251,16 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 		cmdTag := pgconn.NewCommandTag("1")
 
 		mock.ExpectBegin()
-		mock.ExpectQuery(selectEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeIDByEmailSQL)).
 			WithArgs(email).
 			WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(employeeID))
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
-		mock.ExpectExec(regexp.QuoteMeta(insertIntoBotUsers)).
+		mock.ExpectExec(regexp.QuoteMeta(repository.DeleteUnlinkedUserSQL)).
+			WithArgs(telegramID, employeeID).
+			WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		mock.ExpectExec(regexp.QuoteMeta(repository.LinkTelegramIDSQL)).
 			WithArgs(telegramID, employeeID).
 			WillReturnResult(cmdTag)
 		mock.ExpectCommit()
@@ -246,6 +272,108 @@ func TestLinkTelegramIDByEmail(t *testing.T) {
 	})
 }
 
+func TestCheckEmailLink(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	email := "test@test.com"
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CheckEmailLinkSQL)).
+			WithArgs(email).
+			WillReturnRows(pgxmock.NewRows([]string{"linked"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+
+		require.NoError(t, repo.CheckEmailLink(ctx, telegramID, email))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - user not found", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CheckEmailLinkSQL)).
+			WithArgs(email).
+			WillReturnError(pgx.ErrNoRows)
+
+		err = repo.CheckEmailLink(ctx, telegramID, email)
+
+		require.ErrorIs(t, err, repository.ErrUserNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - employee already linked", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CheckEmailLinkSQL)).
+			WithArgs(email).
+			WillReturnRows(pgxmock.NewRows([]string{"linked"}).AddRow(true))
+
+		err = repo.CheckEmailLink(ctx, telegramID, email)
+
+		require.ErrorIs(t, err, repository.ErrUserAlreadyLinked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - telegram id exists", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CheckEmailLinkSQL)).
+			WithArgs(email).
+			WillReturnRows(pgxmock.NewRows([]string{"linked"}).AddRow(false))
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
+			WithArgs(telegramID).
+			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+
+		err = repo.CheckEmailLink(ctx, telegramID, email)
+
+		require.ErrorIs(t, err, repository.ErrIDExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query employee", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.CheckEmailLinkSQL)).
+			WithArgs(email).
+			WillReturnError(assert.AnError)
+
+		err = repo.CheckEmailLink(ctx, telegramID, email)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to find employee by email")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestIsUserAuthenticated(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -259,7 +387,8 @@ func TestIsUserAuthenticated(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(selectExistsEmployee).WithArgs(telegramID).WillReturnError(assert.AnError)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
 
 		_, err = repo.IsUserAuthenticated(ctx, telegramID)
 
@@ -277,7 +406,7 @@ func TestIsUserAuthenticated(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(selectExistsEmployee).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsUserAuthenticatedSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
 
@@ -289,12 +418,12 @@ func TestIsUserAuthenticated(t *testing.T) {
 	})
 }
 
-func TestDeleteUserByID(t *testing.T) {
+func TestUnlinkUser(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
 	telegramID := int64(12345)
 
-	t.Run("error - failed to delete user", func(t *testing.T) {
+	t.Run("error - failed to unlink user", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -302,17 +431,17 @@ func TestDeleteUserByID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectExec(deleteUser).WithArgs(telegramID).WillReturnError(assert.AnError)
+		mock.ExpectExec(regexp.QuoteMeta(repository.UnlinkUserSQL)).WithArgs(telegramID).WillReturnError(assert.AnError)
 
-		err = repo.DeleteUserByID(ctx, telegramID)
+		err = repo.UnlinkUser(ctx, telegramID)
 
 		require.Error(t, err)
 		require.ErrorIs(t, err, assert.AnError)
-		require.ErrorContains(t, err, "failed to delete user")
+		require.ErrorContains(t, err, "failed to unlink user")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
-	t.Run("success - delete user", func(t *testing.T) {
+	t.Run("success - unlink user", func(t *testing.T) {
 		t.Parallel()
 		mock, err := pgxmock.NewPool()
 		require.NoError(t, err)
@@ -320,15 +449,118 @@ func TestDeleteUserByID(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectExec(deleteUser).WithArgs(telegramID).WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		mock.ExpectExec(regexp.QuoteMeta(repository.UnlinkUserSQL)).
+			WithArgs(telegramID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
-		err = repo.DeleteUserByID(ctx, telegramID)
+		err = repo.UnlinkUser(ctx, telegramID)
 
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
+func TestRestoreUser(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramID := int64(12345)
+	since := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success - restored", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RestoreUserSQL)).
+			WithArgs(telegramID, since).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		restored, err := repo.RestoreUser(ctx, telegramID, since)
+
+		require.NoError(t, err)
+		assert.True(t, restored)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - grace period over", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RestoreUserSQL)).
+			WithArgs(telegramID, since).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		restored, err := repo.RestoreUser(ctx, telegramID, since)
+
+		require.NoError(t, err)
+		assert.False(t, restored)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.RestoreUserSQL)).
+			WithArgs(telegramID, since).WillReturnError(assert.AnError)
+
+		restored, err := repo.RestoreUser(ctx, telegramID, since)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.False(t, restored)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPurgeUnlinkedUsers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	before := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.PurgeUnlinkedUsersSQL)).
+			WithArgs(before).WillReturnResult(pgxmock.NewResult("DELETE", 3))
+
+		purged, err := repo.PurgeUnlinkedUsers(ctx, before)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - exec", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectExec(regexp.QuoteMeta(repository.PurgeUnlinkedUsersSQL)).
+			WithArgs(before).WillReturnError(assert.AnError)
+
+		_, err = repo.PurgeUnlinkedUsers(ctx, before)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestGetEmployee(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -342,7 +574,8 @@ func TestGetEmployee(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(selectGetEmployee)).WithArgs(telegramID).WillReturnError(assert.AnError)
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeSQL)).
+			WithArgs(telegramID).WillReturnError(assert.AnError)
 
 		_, err = repo.GetEmployee(ctx, telegramID)
 
@@ -360,7 +593,7 @@ func TestGetEmployee(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(selectGetEmployee)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"id", "fullname", "shortname", "position", "email", "phone", "is_admin"}).
@@ -380,10 +613,58 @@ func TestGetEmployee(t *testing.T) {
 	})
 }
 
+func TestGetEmployeeByID(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	employeeID := 123
+
+	t.Run("error - failed to get employee", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeByIDSQL)).
+			WithArgs(employeeID).WillReturnError(assert.AnError)
+
+		_, err = repo.GetEmployeeByID(ctx, employeeID)
+
+		require.Error(t, err)
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get employee data")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - get employee", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetEmployeeByIDSQL)).
+			WithArgs(employeeID).
+			WillReturnRows(
+				pgxmock.NewRows([]string{"id", "fullname", "shortname", "position", "email", "phone", "is_admin"}).
+					AddRow(employeeID, "testFull", "testShort", "testPos", "testEmail", "testPhone", false),
+			)
+
+		employee, err := repo.GetEmployeeByID(ctx, employeeID)
+
+		require.NoError(t, err)
+		assert.Equal(t, employeeID, employee.ID)
+		assert.Equal(t, "testShort", employee.ShortName)
+		assert.False(t, employee.IsAdmin)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestGetAllTgUserIDs(t *testing.T) {
 	ctx := t.Context()
 	id := int64(12345678)
-	query := "SELECT telegram_id from bot_users"
 
 	t.Run("error - query error", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -392,7 +673,7 @@ func TestGetAllTgUserIDs(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllTgUserIDsSQL)).
 			WillReturnError(assert.AnError)
 
 		_, err = repo.GetAllTgUserIDs(ctx)
@@ -410,7 +691,7 @@ func TestGetAllTgUserIDs(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllTgUserIDsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id"}).
 					AddRow("invalid_id"))
@@ -429,7 +710,7 @@ func TestGetAllTgUserIDs(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllTgUserIDsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id"}).
 					AddRow(id).
@@ -450,7 +731,7 @@ func TestGetAllTgUserIDs(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAllTgUserIDsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id"}).
 					AddRow(id),
@@ -466,12 +747,6 @@ func TestGetAllTgUserIDs(t *testing.T) {
 
 func TestGetAdmins(t *testing.T) {
 	ctx := t.Context()
-	query := `
-		SELECT telegram_id, employee_id 
-		FROM bot_users bu 
-		LEFT JOIN employees e ON e.id = bu.employee_id
-		WHERE e.is_admin = TRUE
-	`
 	botUser := models.BotUser{TelegramID: int64(123456), EmployeeID: 9999}
 
 	t.Run("error - query error", func(t *testing.T) {
@@ -481,7 +756,7 @@ func TestGetAdmins(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminsSQL)).
 			WillReturnError(assert.AnError)
 
 		_, err = repo.GetAdmins(ctx)
@@ -499,7 +774,7 @@ func TestGetAdmins(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id", "employee_id"}).
 					AddRow("invalid_id", "invalid_id"))
@@ -518,7 +793,7 @@ func TestGetAdmins(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id", "employee_id"}).
 					AddRow(botUser.TelegramID, botUser.EmployeeID).
@@ -539,7 +814,7 @@ func TestGetAdmins(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetAdminsSQL)).
 			WillReturnRows(
 				pgxmock.NewRows([]string{"telegram_id", "employee_id"}).
 					AddRow(botUser.TelegramID, botUser.EmployeeID),
@@ -558,10 +833,6 @@ func TestGetAdmins(t *testing.T) {
 func TestIsAdmin(t *testing.T) {
 	ctx := t.Context()
 	telegramID := int64(12345)
-	query := `
-		SELECT is_admin FROM employees
-		WHERE id = (SELECT employee_id FROM bot_users WHERE telegram_id = $1);
-	`
 
 	t.Run("error - query error", func(t *testing.T) {
 		mock, err := pgxmock.NewPool()
@@ -570,7 +841,7 @@ func TestIsAdmin(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsAdminSQL)).
 			WithArgs(telegramID).
 			WillReturnError(assert.AnError)
 
@@ -588,7 +859,7 @@ func TestIsAdmin(t *testing.T) {
 
 		repo := repository.NewRepository(mock)
 
-		mock.ExpectQuery(regexp.QuoteMeta(query)).
+		mock.ExpectQuery(regexp.QuoteMeta(repository.IsAdminSQL)).
 			WithArgs(telegramID).
 			WillReturnRows(pgxmock.NewRows([]string{"is_admin"}).AddRow(true))
 
@@ -599,3 +870,81 @@ func TestIsAdmin(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestGetUserLanguages(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	telegramIDs := []int64{1, 2, 3}
+	columns := []string{"telegram_id", "locale"}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetUserLanguagesSQL)).
+			WithArgs(telegramIDs).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(int64(1), "uk").AddRow(int64(2), nil))
+
+		languages, err := repo.GetUserLanguages(ctx, telegramIDs)
+
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]string{1: "uk"}, languages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - no users", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		languages, err := repo.GetUserLanguages(ctx, nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, languages)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetUserLanguagesSQL)).
+			WithArgs(telegramIDs).
+			WillReturnError(assert.AnError)
+
+		_, err = repo.GetUserLanguages(ctx, telegramIDs)
+
+		require.ErrorIs(t, err, assert.AnError)
+		require.ErrorContains(t, err, "failed to get languages of 3 users")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error - rows error", func(t *testing.T) {
+		t.Parallel()
+		mock, err := pgxmock.NewPool()
+		require.NoError(t, err)
+		defer mock.Close()
+
+		repo := repository.NewRepository(mock)
+
+		mock.ExpectQuery(regexp.QuoteMeta(repository.GetUserLanguagesSQL)).
+			WithArgs(telegramIDs).
+			WillReturnRows(pgxmock.NewRows(columns).AddRow(int64(1), "uk").CloseError(assert.AnError))
+
+		_, err = repo.GetUserLanguages(ctx, telegramIDs)
+
+		require.ErrorContains(t, err, "failed to read rows")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import "time"
+
+const daysInWeek = 7
+
+// Weekly fires once a week on the given weekday at the given time of day.
+// If Location is nil, the location of the reference time is used.
+type Weekly struct {
+	Weekday  time.Weekday
+	Hour     int
+	Minute   int
+	Location *time.Location
+}
+
+// Next returns the first activation of the weekly schedule strictly after the given moment.
+func (w Weekly) Next(after time.Time) time.Time {
+	loc := w.Location
+	if loc == nil {
+		loc = after.Location()
+	}
+	ref := after.In(loc)
+
+	candidate := time.Date(ref.Year(), ref.Month(), ref.Day(), w.Hour, w.Minute, 0, 0, loc)
+	shift := (int(w.Weekday) - int(candidate.Weekday()) + daysInWeek) % daysInWeek
+	candidate = candidate.AddDate(0, 0, shift)
+	if !candidate.After(ref) {
+		candidate = candidate.AddDate(0, 0, daysInWeek)
+	}
+
+	return candidate
+}
+
+// Every fires repeatedly with a fixed interval between activations.
+type Every time.Duration
+
+// Next returns the moment one interval after the given one.
+func (e Every) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(e))
+}
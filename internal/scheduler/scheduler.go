@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrShutdownTimeout is returned by Stop when running jobs did not finish before the deadline.
+var ErrShutdownTimeout = errors.New("scheduler: running jobs did not finish before shutdown deadline")
+
+// Job is a unit of work executed by the Scheduler.
+type Job func(ctx context.Context) error
+
+// Schedule computes the next activation time strictly after the given moment.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// entry binds a named job to its schedule.
+type entry struct {
+	name     string
+	schedule Schedule
+	job      Job
+}
+
+// Scheduler runs registered jobs according to their schedules until it is stopped.
+// Jobs receive a context that is only canceled if they outlive the shutdown deadline,
+// so a report that is being generated during shutdown gets a chance to finish.
+type Scheduler struct {
+	log       *slog.Logger
+	mu        sync.Mutex
+	entries   []entry
+	loops     sync.WaitGroup
+	stopLoops context.CancelFunc
+	jobCtx    context.Context
+	cancelJob context.CancelFunc
+}
+
+// New creates a new scheduler instance.
+func New(log *slog.Logger) *Scheduler {
+	jobCtx, cancelJob := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		log:       log.With(slog.String("component", "scheduler")),
+		jobCtx:    jobCtx,
+		cancelJob: cancelJob,
+	}
+}
+
+// Add registers a job under the given name. Jobs must be added before Start is called.
+func (s *Scheduler) Add(name string, schedule Schedule, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = append(s.entries, entry{name: name, schedule: schedule, job: job})
+}
+
+// Start launches one scheduling loop per registered job. Loops exit when ctx is canceled
+// or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loopCtx, cancel := context.WithCancel(ctx)
+	s.stopLoops = cancel
+
+	for _, e := range s.entries {
+		s.loops.Add(1)
+		go s.loop(loopCtx, e)
+	}
+
+	s.log.InfoContext(ctx, "Scheduler started", "jobs", len(s.entries))
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish. If ctx expires first,
+// the jobs' context is canceled and ErrShutdownTimeout is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopLoops != nil {
+		s.stopLoops()
+	}
+	s.mu.Unlock()
+
+	// Jobs run inline in their loops, so waiting for the loops also waits for running jobs.
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancelJob()
+		s.log.InfoContext(ctx, "Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		s.cancelJob()
+		s.log.WarnContext(ctx, "Scheduler stopped before running jobs finished")
+		return ErrShutdownTimeout
+	}
+}
+
+// loop waits for the next activation of the entry and runs it until ctx is canceled.
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	defer s.loops.Done()
+
+	for {
+		next := e.schedule.Next(time.Now())
+		s.log.DebugContext(ctx, "Next job run scheduled", "job", e.name, "at", next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(e)
+		}
+	}
+}
+
+// run executes a single job invocation, recovering from panics so one faulty job
+// cannot take the whole bot down.
+func (s *Scheduler) run(e entry) {
+	startTime := time.Now()
+	log := s.log.With(slog.String("job", e.name))
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.ErrorContext(s.jobCtx, "Scheduled job panicked", "panic", fmt.Sprint(rec))
+		}
+	}()
+
+	log.InfoContext(s.jobCtx, "Scheduled job started")
+	if err := e.job(s.jobCtx); err != nil {
+		log.ErrorContext(s.jobCtx, "Scheduled job failed", "error", err, "duration", time.Since(startTime))
+		return
+	}
+	log.InfoContext(s.jobCtx, "Scheduled job finished", "duration", time.Since(startTime))
+}
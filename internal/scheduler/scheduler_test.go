@@ -0,0 +1,147 @@
+package scheduler_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeklyNext(t *testing.T) {
+	t.Parallel()
+	loc := time.UTC
+	schedule := scheduler.Weekly{Weekday: time.Monday, Hour: 8, Minute: 30, Location: loc}
+
+	tests := []struct {
+		name     string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			name:     "later in the same week",
+			after:    time.Date(2025, 10, 15, 12, 0, 0, 0, loc), // Wednesday
+			expected: time.Date(2025, 10, 20, 8, 30, 0, 0, loc),
+		},
+		{
+			name:     "monday before the activation time",
+			after:    time.Date(2025, 10, 20, 7, 0, 0, 0, loc),
+			expected: time.Date(2025, 10, 20, 8, 30, 0, 0, loc),
+		},
+		{
+			name:     "exactly at the activation time",
+			after:    time.Date(2025, 10, 20, 8, 30, 0, 0, loc),
+			expected: time.Date(2025, 10, 27, 8, 30, 0, 0, loc),
+		},
+		{
+			name:     "sunday evening",
+			after:    time.Date(2025, 10, 26, 23, 59, 0, 0, loc),
+			expected: time.Date(2025, 10, 27, 8, 30, 0, 0, loc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, schedule.Next(tt.after))
+		})
+	}
+}
+
+func TestEveryNext(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	assert.Equal(t, now.Add(time.Minute), scheduler.Every(time.Minute).Next(now))
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("runs jobs until stopped", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+
+		sched := scheduler.New(logger)
+		sched.Add("counter", scheduler.Every(5*time.Millisecond), func(_ context.Context) error {
+			calls.Add(1)
+			return nil
+		})
+		sched.Start(t.Context())
+
+		require.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, 5*time.Millisecond)
+		require.NoError(t, sched.Stop(t.Context()))
+
+		stopped := calls.Load()
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, stopped, calls.Load())
+	})
+
+	t.Run("waits for running job on stop", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		var finished atomic.Bool
+
+		sched := scheduler.New(logger)
+		sched.Add("slow", scheduler.Every(time.Millisecond), func(ctx context.Context) error {
+			if finished.Load() {
+				return nil
+			}
+			close(started)
+			select {
+			case <-time.After(30 * time.Millisecond):
+				finished.Store(true)
+			case <-ctx.Done():
+			}
+			return nil
+		})
+		sched.Start(t.Context())
+		<-started
+
+		require.NoError(t, sched.Stop(t.Context()))
+		assert.True(t, finished.Load())
+	})
+
+	t.Run("cancels running job after deadline", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		canceled := make(chan struct{})
+
+		sched := scheduler.New(logger)
+		sched.Add("stuck", scheduler.Every(time.Millisecond), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		})
+		sched.Start(t.Context())
+		<-started
+
+		stopCtx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+
+		require.ErrorIs(t, sched.Stop(stopCtx), scheduler.ErrShutdownTimeout)
+		<-canceled
+	})
+
+	t.Run("recovers from panicking job", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+
+		sched := scheduler.New(logger)
+		sched.Add("panics", scheduler.Every(time.Millisecond), func(_ context.Context) error {
+			calls.Add(1)
+			panic("boom")
+		})
+		sched.Start(t.Context())
+
+		require.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+		require.NoError(t, sched.Stop(t.Context()))
+	})
+}
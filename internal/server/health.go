@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -14,20 +15,40 @@ type DBPinger interface {
 	Ping(ctx context.Context) error
 }
 
+// RedisPinger checks that Redis responds, it is implemented by every go-redis client.
+type RedisPinger interface {
+	Ping(ctx context.Context) *redis.StatusCmd
+}
+
+// HealthChecker serves the readiness check of the bot dependencies: the database, Redis
+// and Hermes. Liveness is served by Live and does not depend on them, so an outage of
+// a dependency takes the bot out of service without restarting it.
 type HealthChecker struct {
 	db           DBPinger
+	redis        RedisPinger
 	log          *slog.Logger
 	hermesHealth grpc_health_v1.HealthClient
 }
 
-func NewHealthChecker(log *slog.Logger, db DBPinger, hermesConn *grpc.ClientConn) *HealthChecker {
+func NewHealthChecker(log *slog.Logger, db DBPinger, rdb RedisPinger, hermesConn *grpc.ClientConn) *HealthChecker {
 	return &HealthChecker{
 		db:           db,
+		redis:        rdb,
 		log:          log,
 		hermesHealth: grpc_health_v1.NewHealthClient(hermesConn),
 	}
 }
 
+// Live reports that the process is up and able to serve HTTP requests.
+func (h *HealthChecker) Live(writer http.ResponseWriter, req *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(writer).Encode(map[string]string{"status": "ok"}); err != nil {
+		h.log.ErrorContext(req.Context(), "Failed to write liveness response", "error", err)
+	}
+}
+
+// ServeHTTP reports whether the database, Redis and Hermes are available.
 func (h *HealthChecker) ServeHTTP(writer http.ResponseWriter, req *http.Request) {
 	h.log.DebugContext(req.Context(), "Performing health checks...")
 
@@ -43,6 +64,14 @@ func (h *HealthChecker) ServeHTTP(writer http.ResponseWriter, req *http.Request)
 		status["database"] = "ok"
 	}
 
+	if err = h.redis.Ping(req.Context()).Err(); err != nil {
+		status["redis"] = "unavailable"
+		overallStatus = http.StatusServiceUnavailable
+		h.log.WarnContext(req.Context(), "Health check failed: Redis ping", "error", err)
+	} else {
+		status["redis"] = "ok"
+	}
+
 	healthReq := &grpc_health_v1.HealthCheckRequest{Service: ""}
 	resp, err := h.hermesHealth.Check(req.Context(), healthReq)
 	switch {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/UnknownOlympus/oracle/internal/server"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -30,6 +31,17 @@ func (m *MockDBPinger) Ping(_ context.Context) error {
 	return nil
 }
 
+type MockRedisPinger struct {
+	ShouldFail bool
+}
+
+func (m *MockRedisPinger) Ping(_ context.Context) *redis.StatusCmd {
+	if m.ShouldFail {
+		return redis.NewStatusResult("", errors.New("mock redis error"))
+	}
+	return redis.NewStatusResult("PONG", nil)
+}
+
 func TestHealthChecker(t *testing.T) {
 	t.Parallel()
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
@@ -57,13 +69,13 @@ func TestHealthChecker(t *testing.T) {
 		defer conn.Close()
 
 		mockDB := &MockDBPinger{ShouldFail: false}
-		healthChecker := server.NewHealthChecker(logger, mockDB, conn)
+		healthChecker := server.NewHealthChecker(logger, mockDB, &MockRedisPinger{}, conn)
 		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 		rr := httptest.NewRecorder()
 		healthChecker.ServeHTTP(rr, req)
 
 		require.Equal(t, http.StatusOK, rr.Code)
-		expectedBody := `{"database":"ok", "hermes_service":"ok"}`
+		expectedBody := `{"database":"ok", "redis":"ok", "hermes_service":"ok"}`
 		require.JSONEq(t, expectedBody, rr.Body.String())
 	})
 
@@ -87,13 +99,13 @@ func TestHealthChecker(t *testing.T) {
 		defer conn.Close()
 
 		mockDB := &MockDBPinger{ShouldFail: true}
-		healthChecker := server.NewHealthChecker(logger, mockDB, conn)
+		healthChecker := server.NewHealthChecker(logger, mockDB, &MockRedisPinger{}, conn)
 		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 		rr := httptest.NewRecorder()
 		healthChecker.ServeHTTP(rr, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
-		expectedBody := `{"database":"unavailable", "hermes_service":"ok"}`
+		expectedBody := `{"database":"unavailable", "redis":"ok", "hermes_service":"ok"}`
 		require.JSONEq(t, expectedBody, rr.Body.String())
 	})
 
@@ -117,13 +129,13 @@ func TestHealthChecker(t *testing.T) {
 		defer conn.Close()
 
 		mockDB := &MockDBPinger{ShouldFail: false}
-		healthChecker := server.NewHealthChecker(logger, mockDB, conn)
+		healthChecker := server.NewHealthChecker(logger, mockDB, &MockRedisPinger{}, conn)
 		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 		rr := httptest.NewRecorder()
 		healthChecker.ServeHTTP(rr, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
-		expectedBody := `{"database":"ok", "hermes_service":"degraded"}`
+		expectedBody := `{"database":"ok", "redis":"ok", "hermes_service":"degraded"}`
 		require.JSONEq(t, expectedBody, rr.Body.String())
 	})
 
@@ -139,13 +151,68 @@ func TestHealthChecker(t *testing.T) {
 		defer conn.Close()
 
 		mockDB := &MockDBPinger{ShouldFail: false}
-		healthChecker := server.NewHealthChecker(logger, mockDB, conn)
+		healthChecker := server.NewHealthChecker(logger, mockDB, &MockRedisPinger{}, conn)
 		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 		rr := httptest.NewRecorder()
 		healthChecker.ServeHTTP(rr, req)
 
 		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
-		expectedBody := `{"database":"ok", "hermes_service":"unreachable"}`
+		expectedBody := `{"database":"ok", "redis":"ok", "hermes_service":"unreachable"}`
 		require.JSONEq(t, expectedBody, rr.Body.String())
 	})
+	t.Run("redis unavailable", func(t *testing.T) {
+		t.Parallel()
+
+		lis := bufconn.Listen(1024 * 1024)
+		s := grpc.NewServer()
+		defer s.GracefulStop()
+		healthSrv := health.NewServer()
+		healthSrv.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+		grpc_health_v1.RegisterHealthServer(s, healthSrv)
+		go func() { _ = s.Serve(lis) }()
+
+		conn, err := grpc.NewClient(
+			"passthrough:///bufnet",
+			grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		mockDB := &MockDBPinger{ShouldFail: false}
+		healthChecker := server.NewHealthChecker(logger, mockDB, &MockRedisPinger{ShouldFail: true}, conn)
+		req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		rr := httptest.NewRecorder()
+		healthChecker.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+		expectedBody := `{"database":"ok", "redis":"unavailable", "hermes_service":"ok"}`
+		require.JSONEq(t, expectedBody, rr.Body.String())
+	})
+}
+
+func TestHealthChecker_Live(t *testing.T) {
+	t.Parallel()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	// Liveness does not touch the dependencies, so it succeeds even when all of them are down.
+	lis := bufconn.Listen(1024 * 1024)
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	lis.Close()
+	defer conn.Close()
+
+	healthChecker := server.NewHealthChecker(
+		logger, &MockDBPinger{ShouldFail: true}, &MockRedisPinger{ShouldFail: true}, conn,
+	)
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	rr := httptest.NewRecorder()
+	healthChecker.Live(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
 }
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// qrCodeSize is the width and height of the QR code image in pixels.
+const qrCodeSize = 320
+
+// LoginTokenIssuer creates one-time login tokens for an employee.
+type LoginTokenIssuer interface {
+	// IssueLoginToken returns the Telegram deep link which links the account of the employee
+	// with the email to the Telegram user opening it.
+	IssueLoginToken(ctx context.Context, email string) (string, error)
+}
+
+var qrLoginPage = template.Must(template.New("qr_login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.RefreshSeconds}}">
+<title>Oracle login</title>
+<style>
+body { font-family: sans-serif; text-align: center; margin-top: 3em; }
+img { image-rendering: pixelated; }
+</style>
+</head>
+<body>
+<h1>Link Telegram to {{.Email}}</h1>
+<img src="{{.QRCode}}" width="{{.Size}}" height="{{.Size}}" alt="Login QR code">
+<p>Scan the code with your phone and press Start in Telegram, or open <a href="{{.Link}}">the link</a>.</p>
+<p>The code can be used once and expires in {{.Minutes}} minutes, the page then shows a new one.</p>
+</body>
+</html>
+`))
+
+// QRLoginHandler serves a page with a QR code of a one-time Telegram deep link, which links the
+// account of the signed-in employee without typing the email. The email is read from emailHeader,
+// set by the authenticating reverse proxy in front of the page, e.g. X-Forwarded-Email of oauth2-proxy.
+// The header is trusted only on connections from trustedProxies, other requests are refused, since
+// the monitoring port is reachable around the proxy and anyone could set the header there.
+// The page is refreshed with a new code when the previous one expires after ttl.
+func QRLoginHandler(
+	log *slog.Logger,
+	issuer LoginTokenIssuer,
+	emailHeader string,
+	trustedProxies []netip.Prefix,
+	ttl time.Duration,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !fromTrustedProxy(r, trustedProxies) {
+			log.WarnContext(r.Context(), "QR login requested from outside the trusted proxies", "remote", r.RemoteAddr)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		email := strings.TrimSpace(r.Header.Get(emailHeader))
+		if email == "" {
+			log.WarnContext(r.Context(), "QR login requested without an authenticated email", "header", emailHeader)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		link, err := issuer.IssueLoginToken(r.Context(), email)
+		if err != nil {
+			log.ErrorContext(r.Context(), "Failed to issue login token", "error", err, "email", email)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		png, err := qrcode.Encode(link, qrcode.Medium, qrCodeSize)
+		if err != nil {
+			log.ErrorContext(r.Context(), "Failed to encode login QR code", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		// The page holds a login token, it must not be kept by browsers or proxies.
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = qrLoginPage.Execute(w, map[string]any{
+			"Email": email,
+			"Link":  link,
+			// Data URIs are rejected by html/template unless marked safe, the image is encoded here.
+			"QRCode":         template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png)),
+			"Size":           qrCodeSize,
+			"Minutes":        int(ttl.Minutes()),
+			"RefreshSeconds": int(ttl.Seconds()),
+		})
+		if err != nil {
+			log.ErrorContext(r.Context(), "Failed to render QR login page", "error", err)
+		}
+	})
+}
+
+// fromTrustedProxy reports whether the request comes straight from one of the trusted proxies.
+func fromTrustedProxy(r *http.Request, trustedProxies []netip.Prefix) bool {
+	addrPort, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	addr := addrPort.Addr().Unmap()
+
+	return slices.ContainsFunc(trustedProxies, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
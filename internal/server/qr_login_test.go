@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/server"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeIssuer struct {
+	email string
+	err   error
+}
+
+func (f *fakeIssuer) IssueLoginToken(_ context.Context, email string) (string, error) {
+	f.email = email
+	return "https://t.me/oracle_bot?start=login_token", f.err
+}
+
+func TestQRLoginHandler(t *testing.T) {
+	t.Parallel()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name      string
+		method    string
+		email     string
+		remote    string
+		issuerErr error
+		wantCode  int
+	}{
+		{name: "success", method: http.MethodGet, email: "desk@example.com", wantCode: http.StatusOK},
+		{name: "untrusted proxy", method: http.MethodGet, email: "desk@example.com", remote: "198.51.100.7:40000",
+			wantCode: http.StatusForbidden},
+		{name: "mapped IPv4 proxy", method: http.MethodGet, email: "desk@example.com",
+			remote: "[::ffff:10.0.0.5]:40000", wantCode: http.StatusOK},
+		{name: "missing email", method: http.MethodGet, wantCode: http.StatusUnauthorized},
+		{name: "issuer error", method: http.MethodGet, email: "desk@example.com", issuerErr: assert.AnError,
+			wantCode: http.StatusInternalServerError},
+		{name: "wrong method", method: http.MethodPost, email: "desk@example.com",
+			wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			issuer := &fakeIssuer{err: tt.issuerErr}
+			trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/24")}
+			handler := server.QRLoginHandler(log, issuer, "X-Forwarded-Email", trusted, 5*time.Minute)
+
+			req := httptest.NewRequest(tt.method, "/login/qr", nil)
+			req.RemoteAddr = "10.0.0.2:40000"
+			if tt.remote != "" {
+				req.RemoteAddr = tt.remote
+			}
+			if tt.email != "" {
+				req.Header.Set("X-Forwarded-Email", tt.email)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.email, issuer.email)
+			assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+			body := rr.Body.String()
+			assert.Contains(t, body, `src="data:image/png;base64,`)
+			assert.Contains(t, body, `href="https://t.me/oracle_bot?start=login_token"`)
+			assert.Contains(t, body, `content="300"`)
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"google.golang.org/grpc"
 )
 
@@ -21,22 +22,35 @@ import (
 // - log: A logger for logging server events and errors.
 // - reg: A registry with Prometheus collectors.
 // - dtb: A pgxpool connector for database methods (ping)
+// - redisClient: A Redis client checked by the readiness probe.
 // - port: The port number on which the server will listen.
+// - webhooks: The webhooks of external integrations, mounted next to the Alertmanager webhook.
+// - qrLogin: The QR code login page, mounted on /login/qr. Nil disables the page.
 func StartMonitoringServer(
 	ctx context.Context,
 	log *slog.Logger,
 	reg *prometheus.Registry,
 	dtb *pgxpool.Pool,
+	redisClient redis.UniversalClient,
 	port int,
 	hermesConn *grpc.ClientConn,
 	alertmanagerHandler func(w http.ResponseWriter, r *http.Request),
+	webhooks *WebhookRegistry,
+	qrLogin http.Handler,
 ) {
 	mux := http.NewServeMux()
-	healthChecker := NewHealthChecker(log, dtb, hermesConn)
+	healthChecker := NewHealthChecker(log, dtb, redisClient, hermesConn)
 
+	mux.HandleFunc("/livez", healthChecker.Live)
+	mux.Handle("/readyz", healthChecker)
+	// /healthz is kept as an alias of the readiness probe for existing deployments.
 	mux.Handle("/healthz", healthChecker)
 	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 	mux.HandleFunc("/webhook/alertmanager", alertmanagerHandler)
+	webhooks.Mount(mux)
+	if qrLogin != nil {
+		mux.Handle("/login/qr", qrLogin)
+	}
 
 	log.InfoContext(ctx, "Starting monitoring server", "port", port)
 
@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	t.Parallel()
+
+	const path = "/webhook/alertmanager"
+	next := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantCode      int
+		wantReason    string
+	}{
+		{name: "valid token", token: "secret", authorization: "Bearer secret", wantCode: http.StatusOK},
+		{name: "auth disabled", token: "", authorization: "", wantCode: http.StatusOK},
+		{name: "missing header", token: "secret", wantCode: http.StatusUnauthorized, wantReason: "missing"},
+		{name: "wrong scheme", token: "secret", authorization: "Basic secret", wantCode: http.StatusUnauthorized,
+			wantReason: "missing"},
+		{name: "wrong token", token: "secret", authorization: "Bearer guess", wantCode: http.StatusUnauthorized,
+			wantReason: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rejected := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "rejected"}, []string{"path", "reason"})
+			handler := server.RequireBearerToken(tt.token, rejected, next)
+
+			req := httptest.NewRequest(http.MethodPost, path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			if tt.wantReason != "" {
+				assert.InDelta(t, 1, testutil.ToFloat64(rejected.WithLabelValues(path, tt.wantReason)), 0)
+			} else {
+				assert.Equal(t, 0, testutil.CollectAndCount(rejected))
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GitHubSignature authenticates GitHub deliveries by the HMAC-SHA256 signature of the body
+// in the X-Hub-Signature-256 header.
+func GitHubSignature(secret string) Authenticator {
+	return func(req *http.Request, body []byte) bool {
+		signature, ok := strings.CutPrefix(req.Header.Get("X-Hub-Signature-256"), "sha256=")
+		if !ok {
+			return false
+		}
+		provided, err := hex.DecodeString(signature)
+		if err != nil {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return hmac.Equal(provided, mac.Sum(nil))
+	}
+}
+
+// githubEvent holds the fields of the GitHub issues and workflow_run events used in messages.
+type githubEvent struct {
+	Action     string `json:"action"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Issue struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"issue"`
+	WorkflowRun struct {
+		Name       string `json:"name"`
+		HeadBranch string `json:"head_branch"`
+		Conclusion string `json:"conclusion"`
+		HTMLURL    string `json:"html_url"`
+	} `json:"workflow_run"`
+}
+
+// ParseGitHub reports opened, closed and reopened issues and failed workflow runs.
+// Other events are acknowledged without a message.
+func ParseGitHub(req *http.Request, body []byte) ([]string, error) {
+	eventType := req.Header.Get("X-GitHub-Event")
+	if eventType != "issues" && eventType != "workflow_run" {
+		return nil, nil
+	}
+
+	var event githubEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+
+	switch {
+	case eventType == "issues" &&
+		(event.Action == "opened" || event.Action == "closed" || event.Action == "reopened"):
+		issue := event.Issue
+		return []string{fmt.Sprintf("🐛 Issue %s in %s#%d: %s\nby %s\n%s",
+			event.Action, event.Repository.FullName, issue.Number, issue.Title, issue.User.Login, issue.HTMLURL,
+		)}, nil
+	case eventType == "workflow_run" && event.Action == "completed" && event.WorkflowRun.Conclusion == "failure":
+		run := event.WorkflowRun
+		return []string{fmt.Sprintf("❌ CI failed in %s: %s on %s\n%s",
+			event.Repository.FullName, run.Name, run.HeadBranch, run.HTMLURL,
+		)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// uptimeEvent is the notification of Uptime Kuma and compatible uptime monitors.
+type uptimeEvent struct {
+	Heartbeat *struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+	} `json:"heartbeat"`
+	Monitor struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"monitor"`
+	Msg string `json:"msg"`
+}
+
+// Uptime monitor heartbeat statuses.
+const (
+	uptimeDown = 0
+	uptimeUp   = 1
+)
+
+// ParseUptime reports monitors going down and coming back up. Test notifications without
+// a heartbeat are acknowledged without a message.
+func ParseUptime(_ *http.Request, body []byte) ([]string, error) {
+	var event uptimeEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	if event.Heartbeat == nil {
+		return nil, nil
+	}
+
+	name := event.Monitor.Name
+	if event.Monitor.URL != "" {
+		name += " (" + event.Monitor.URL + ")"
+	}
+
+	switch event.Heartbeat.Status {
+	case uptimeDown:
+		return []string{fmt.Sprintf("🔴 %s is down: %s", name, event.Heartbeat.Msg)}, nil
+	case uptimeUp:
+		return []string{fmt.Sprintf("🟢 %s is up again", name)}, nil
+	default:
+		return nil, nil
+	}
+}
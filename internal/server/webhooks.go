@@ -0,0 +1,143 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxWebhookBody limits the size of webhook payloads read into memory.
+const maxWebhookBody = 1 << 20
+
+// ErrInvalidPayload is returned by webhook parsers when the payload cannot be understood.
+var ErrInvalidPayload = errors.New("invalid webhook payload")
+
+// ErrDuplicateWebhook is returned when a webhook is registered on a path already in use.
+var ErrDuplicateWebhook = errors.New("webhook path already registered")
+
+// Audience selects the bot users a webhook message is sent to.
+type Audience string
+
+const (
+	AudienceAdmins Audience = "admins" // AudienceAdmins are the users with admin privileges.
+	AudienceUsers  Audience = "users"  // AudienceUsers are all linked users.
+)
+
+// Notifier delivers webhook messages to Telegram. Messages are sent as plain text,
+// as payloads of external systems may break Markdown.
+type Notifier interface {
+	NotifyAdmins(ctx context.Context, text string) error
+	NotifyUsers(ctx context.Context, text string) error
+}
+
+// Parser turns a webhook request into the messages to send. Events that need no message,
+// e.g. pings, return none.
+type Parser func(req *http.Request, body []byte) ([]string, error)
+
+// Authenticator reports whether the webhook request comes from the integration.
+type Authenticator func(req *http.Request, body []byte) bool
+
+// Webhook is an integration receiving events from an external system.
+type Webhook struct {
+	Name         string        // Name identifies the integration in logs.
+	Path         string        // Path is where the webhook is mounted, e.g. /webhook/github.
+	Parse        Parser        // Parse formats the payload into messages.
+	Audience     Audience      // Audience receives the messages.
+	Authenticate Authenticator // Authenticate verifies the request. Nil accepts every request.
+}
+
+// WebhookRegistry holds the webhooks of external integrations and serves them.
+type WebhookRegistry struct {
+	log      *slog.Logger
+	notifier Notifier
+	rejected *prometheus.CounterVec
+	webhooks map[string]Webhook
+}
+
+// NewWebhookRegistry creates an empty registry delivering messages through the notifier.
+// Rejected requests are counted in rejected by path and reason.
+func NewWebhookRegistry(log *slog.Logger, notifier Notifier, rejected *prometheus.CounterVec) *WebhookRegistry {
+	return &WebhookRegistry{
+		log:      log.With(slog.String("component", "webhooks")),
+		notifier: notifier,
+		rejected: rejected,
+		webhooks: make(map[string]Webhook),
+	}
+}
+
+// Register adds the webhook to the registry.
+func (r *WebhookRegistry) Register(webhook Webhook) error {
+	if _, ok := r.webhooks[webhook.Path]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateWebhook, webhook.Path)
+	}
+
+	r.webhooks[webhook.Path] = webhook
+	return nil
+}
+
+// Mount registers the handlers of all webhooks on the mux.
+func (r *WebhookRegistry) Mount(mux *http.ServeMux) {
+	for path, webhook := range r.webhooks {
+		mux.HandleFunc(path, r.handler(webhook))
+	}
+}
+
+// handler authenticates and parses the webhook requests and sends the resulting messages.
+func (r *WebhookRegistry) handler(webhook Webhook) http.HandlerFunc {
+	log := r.log.With(slog.String("webhook", webhook.Name))
+
+	return func(writer http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(writer, "Only POST requests are accepted", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(writer, req.Body, maxWebhookBody))
+		if err != nil {
+			log.WarnContext(req.Context(), "Failed to read webhook body", "error", err)
+			http.Error(writer, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+
+		if webhook.Authenticate != nil && !webhook.Authenticate(req, body) {
+			r.rejected.WithLabelValues(req.URL.Path, "invalid").Inc()
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		messages, err := webhook.Parse(req, body)
+		if err != nil {
+			log.WarnContext(req.Context(), "Failed to parse webhook payload", "error", err)
+			http.Error(writer, "Failed to decode payload", http.StatusBadRequest)
+			return
+		}
+
+		for _, message := range messages {
+			if err = r.notify(req.Context(), webhook.Audience, message); err != nil {
+				log.ErrorContext(req.Context(), "Failed to deliver webhook message", "error", err)
+				http.Error(writer, "Failed to deliver message", http.StatusInternalServerError)
+				return
+			}
+		}
+
+		log.InfoContext(req.Context(), "Webhook handled", "messages", len(messages))
+		writer.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// notify sends the message to the audience.
+func (r *WebhookRegistry) notify(ctx context.Context, audience Audience, text string) error {
+	switch audience {
+	case AudienceUsers:
+		return r.notifier.NotifyUsers(ctx, text)
+	case AudienceAdmins:
+		return r.notifier.NotifyAdmins(ctx, text)
+	default:
+		return fmt.Errorf("unknown webhook audience %q", audience)
+	}
+}
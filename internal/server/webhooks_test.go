@@ -0,0 +1,256 @@
+package server_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MockNotifier struct {
+	mu     sync.Mutex
+	admins []string
+	users  []string
+}
+
+func (m *MockNotifier) NotifyAdmins(_ context.Context, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.admins = append(m.admins, text)
+	return nil
+}
+
+func (m *MockNotifier) NotifyUsers(_ context.Context, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.users = append(m.users, text)
+	return nil
+}
+
+func newWebhookMux(
+	t *testing.T,
+	notifier server.Notifier,
+	webhooks ...server.Webhook,
+) (*http.ServeMux, *prometheus.CounterVec) {
+	t.Helper()
+
+	rejected := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "rejected"}, []string{"path", "reason"})
+	registry := server.NewWebhookRegistry(slog.New(slog.DiscardHandler), notifier, rejected)
+	for _, webhook := range webhooks {
+		require.NoError(t, registry.Register(webhook))
+	}
+
+	mux := http.NewServeMux()
+	registry.Mount(mux)
+	return mux, rejected
+}
+
+func echoParser(_ *http.Request, body []byte) ([]string, error) {
+	if len(body) == 0 {
+		return nil, server.ErrInvalidPayload
+	}
+	return []string{string(body)}, nil
+}
+
+func TestWebhookRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error - duplicate path", func(t *testing.T) {
+		t.Parallel()
+		registry := server.NewWebhookRegistry(slog.New(slog.DiscardHandler), &MockNotifier{}, nil)
+		webhook := server.Webhook{Name: "echo", Path: "/webhook/echo", Parse: echoParser}
+
+		require.NoError(t, registry.Register(webhook))
+		require.ErrorIs(t, registry.Register(webhook), server.ErrDuplicateWebhook)
+	})
+
+	t.Run("success - delivers to the audience", func(t *testing.T) {
+		t.Parallel()
+		notifier := &MockNotifier{}
+		mux, _ := newWebhookMux(t, notifier,
+			server.Webhook{Name: "admins", Path: "/webhook/admins", Parse: echoParser, Audience: server.AudienceAdmins},
+			server.Webhook{Name: "users", Path: "/webhook/users", Parse: echoParser, Audience: server.AudienceUsers},
+		)
+
+		for _, path := range []string{"/webhook/admins", "/webhook/users"} {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader("hello "+path)))
+			require.Equal(t, http.StatusAccepted, rr.Code)
+		}
+
+		assert.Equal(t, []string{"hello /webhook/admins"}, notifier.admins)
+		assert.Equal(t, []string{"hello /webhook/users"}, notifier.users)
+	})
+
+	t.Run("error - rejected by authenticator", func(t *testing.T) {
+		t.Parallel()
+		notifier := &MockNotifier{}
+		mux, rejected := newWebhookMux(t, notifier, server.Webhook{
+			Name:         "echo",
+			Path:         "/webhook/echo",
+			Parse:        echoParser,
+			Audience:     server.AudienceAdmins,
+			Authenticate: server.BearerToken("secret"),
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/webhook/echo", strings.NewReader("hello"))
+		req.Header.Set("Authorization", "Bearer guess")
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.InDelta(t, 1, testutil.ToFloat64(rejected.WithLabelValues("/webhook/echo", "invalid")), 0)
+		assert.Empty(t, notifier.admins)
+	})
+
+	t.Run("error - invalid payload and method", func(t *testing.T) {
+		t.Parallel()
+		mux, _ := newWebhookMux(t, &MockNotifier{},
+			server.Webhook{Name: "echo", Path: "/webhook/echo", Parse: echoParser, Audience: server.AudienceAdmins},
+		)
+
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/webhook/echo", nil))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/webhook/echo", nil))
+		assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	})
+}
+
+func TestGitHubSignature(t *testing.T) {
+	t.Parallel()
+
+	body := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	authenticate := server.GitHubSignature("secret")
+
+	for signature, want := range map[string]bool{
+		valid:         true,
+		"":            false,
+		"sha256=00ff": false,
+		"sha256=zz":   false,
+		"sha1=00ff":   false,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+		req.Header.Set("X-Hub-Signature-256", signature)
+		assert.Equal(t, want, authenticate(req, body), "signature %q", signature)
+	}
+}
+
+func TestParseGitHub(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		event string
+		body  string
+		want  []string
+	}{
+		{
+			name:  "issue opened",
+			event: "issues",
+			body: `{"action":"opened","repository":{"full_name":"olympus/oracle"},
+				"issue":{"number":7,"title":"Report is empty","html_url":"https://github.com/i/7",
+				"user":{"login":"zeus"}}}`,
+			want: []string{"🐛 Issue opened in olympus/oracle#7: Report is empty\nby zeus\nhttps://github.com/i/7"},
+		},
+		{
+			name:  "issue labeled is ignored",
+			event: "issues",
+			body:  `{"action":"labeled"}`,
+		},
+		{
+			name:  "workflow run failed",
+			event: "workflow_run",
+			body: `{"action":"completed","repository":{"full_name":"olympus/oracle"},
+				"workflow_run":{"name":"CI","head_branch":"main","conclusion":"failure",
+				"html_url":"https://github.com/r/1"}}`,
+			want: []string{"❌ CI failed in olympus/oracle: CI on main\nhttps://github.com/r/1"},
+		},
+		{
+			name:  "workflow run succeeded is ignored",
+			event: "workflow_run",
+			body:  `{"action":"completed","workflow_run":{"conclusion":"success"}}`,
+		},
+		{
+			name:  "ping is ignored",
+			event: "ping",
+			body:  `{"zen":"Keep it logically awesome."}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+			req.Header.Set("X-GitHub-Event", tt.event)
+
+			messages, err := server.ParseGitHub(req, []byte(tt.body))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+
+	t.Run("error - invalid json", func(t *testing.T) {
+		t.Parallel()
+		req := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+		req.Header.Set("X-GitHub-Event", "issues")
+
+		_, err := server.ParseGitHub(req, []byte("{"))
+
+		require.ErrorIs(t, err, server.ErrInvalidPayload)
+	})
+}
+
+func TestParseUptime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "monitor down",
+			body: `{"heartbeat":{"status":0,"msg":"timeout"},"monitor":{"name":"Hermes","url":"https://hermes"}}`,
+			want: []string{"🔴 Hermes (https://hermes) is down: timeout"},
+		},
+		{
+			name: "monitor up",
+			body: `{"heartbeat":{"status":1,"msg":"200 OK"},"monitor":{"name":"Hermes"}}`,
+			want: []string{"🟢 Hermes is up again"},
+		},
+		{
+			name: "test notification is ignored",
+			body: `{"msg":"Uptime Kuma test"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			messages, err := server.ParseUptime(nil, []byte(tt.body))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, messages)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+// Package storage keeps generated files, such as archived reports, in an S3-compatible
+// object storage like MinIO or AWS S3.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ErrNotFound is returned when the object does not exist.
+var ErrNotFound = errors.New("storage: object not found")
+
+// Client stores objects in a single bucket.
+type Client struct {
+	client        *minio.Client
+	bucket        string
+	presignExpiry time.Duration
+}
+
+// New connects to the object storage and checks that the bucket exists. The bucket is not
+// created, it is expected to be provisioned together with its retention rules.
+func New(ctx context.Context, cfg config.Storage) (*Client, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check storage bucket %q: %w", cfg.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("storage bucket %q does not exist", cfg.Bucket)
+	}
+
+	return &Client{client: client, bucket: cfg.Bucket, presignExpiry: cfg.PresignExpiry}, nil
+}
+
+// PresignExpiry is how long URLs returned by PresignedURL stay valid.
+func (c *Client) PresignExpiry() time.Duration {
+	return c.presignExpiry
+}
+
+// Put stores size bytes read from r under the key.
+func (c *Client) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := c.client.PutObject(ctx, c.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put object %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// PutFile stores the file at path under the key. Large files are uploaded in parts.
+func (c *Client) PutFile(ctx context.Context, key, path, contentType string) error {
+	_, err := c.client.FPutObject(ctx, c.bucket, key, path, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to put file %q as %q: %w", path, key, err)
+	}
+
+	return nil
+}
+
+// Get returns the content of the object. The caller closes the returned reader.
+// It returns ErrNotFound if the object does not exist.
+func (c *Client) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := c.client.GetObject(ctx, c.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object %q: %w", key, err)
+	}
+
+	// GetObject is lazy, the request is made by the first call on the object.
+	if _, err = object.Stat(); err != nil {
+		_ = object.Close()
+		if isNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get object %q: %w", key, err)
+	}
+
+	return object, nil
+}
+
+// Delete removes the objects. Objects which do not exist are skipped.
+func (c *Client) Delete(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := c.client.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete object %q: %w", key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// PresignedURL returns a URL downloading the object without credentials, valid for PresignExpiry.
+// The file is saved under filename. It is used for files over the Telegram upload limit.
+func (c *Client) PresignedURL(ctx context.Context, key, filename string) (*url.URL, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": filename,
+	}))
+
+	presigned, err := c.client.PresignedGetObject(ctx, c.bucket, key, c.presignExpiry, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign object %q: %w", key, err)
+	}
+
+	return presigned, nil
+}
+
+func isNotFound(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.StatusCode == http.StatusNotFound || resp.Code == "NoSuchKey"
+}
@@ -0,0 +1,177 @@
+package storage_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 serves the subset of the S3 API used by the client, with path-style bucket addressing.
+type fakeS3 struct {
+	mu      sync.Mutex
+	bucket  string
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `<Error><Code>NoSuchBucket</Code></Error>`)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			body = decodeChunked(body)
+		}
+		f.objects[key] = body
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		content, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(content)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// decodeChunked strips the aws-chunked framing of a streamed upload.
+func decodeChunked(body []byte) []byte {
+	var content []byte
+	for len(body) > 0 {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := bytes.Cut(header, []byte(";"))
+		size, err := strconv.ParseInt(string(sizeHex), 16, 64)
+		if err != nil || size <= 0 || int(size) > len(rest) {
+			break
+		}
+		content = append(content, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+	return content
+}
+
+func newClient(t *testing.T) (*storage.Client, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{bucket: "oracle", objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := storage.New(t.Context(), config.Storage{
+		Endpoint:      strings.TrimPrefix(server.URL, "http://"),
+		Bucket:        "oracle",
+		Region:        "us-east-1",
+		AccessKey:     "oracle",
+		SecretKey:     "secret",
+		PresignExpiry: time.Hour,
+	})
+	require.NoError(t, err)
+
+	return client, fake
+}
+
+func TestNew_MissingBucket(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(&fakeS3{bucket: "other"})
+	defer server.Close()
+
+	_, err := storage.New(t.Context(), config.Storage{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Bucket:    "oracle",
+		Region:    "us-east-1",
+		AccessKey: "oracle",
+		SecretKey: "secret",
+	})
+
+	require.Error(t, err)
+}
+
+func TestClient_PutGetDelete(t *testing.T) {
+	t.Parallel()
+	client, fake := newClient(t)
+	content := []byte("report content")
+
+	err := client.Put(t.Context(), "reports/1/a.csv", bytes.NewReader(content), int64(len(content)), "text/csv")
+	require.NoError(t, err)
+	assert.Equal(t, content, fake.objects["reports/1/a.csv"])
+
+	object, err := client.Get(t.Context(), "reports/1/a.csv")
+	require.NoError(t, err)
+	got, err := io.ReadAll(object)
+	require.NoError(t, err)
+	require.NoError(t, object.Close())
+	assert.Equal(t, content, got)
+
+	require.NoError(t, client.Delete(t.Context(), "reports/1/a.csv", "reports/1/missing.csv"))
+	assert.Empty(t, fake.objects)
+
+	_, err = client.Get(t.Context(), "reports/1/a.csv")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestClient_PutFile(t *testing.T) {
+	t.Parallel()
+	client, fake := newClient(t)
+
+	path := filepath.Join(t.TempDir(), "report.xlsx")
+	require.NoError(t, os.WriteFile(path, []byte("xlsx"), 0o600))
+
+	require.NoError(t, client.PutFile(t.Context(), "reports/1/b.xlsx", path, "application/octet-stream"))
+	assert.Equal(t, []byte("xlsx"), fake.objects["reports/1/b.xlsx"])
+}
+
+func TestClient_PresignedURL(t *testing.T) {
+	t.Parallel()
+	client, _ := newClient(t)
+
+	presigned, err := client.PresignedURL(t.Context(), "reports/1/a.csv", "report_2025-03-01_2025-03-31.csv")
+
+	require.NoError(t, err)
+	assert.Equal(t, "/oracle/reports/1/a.csv", presigned.Path)
+	query := presigned.Query()
+	assert.Equal(t, "3600", query.Get("X-Amz-Expires"))
+	assert.NotEmpty(t, query.Get("X-Amz-Signature"))
+	assert.Equal(t, `attachment; filename=report_2025-03-01_2025-03-31.csv`,
+		query.Get("response-content-disposition"))
+	assert.Equal(t, time.Hour, client.PresignExpiry())
+}
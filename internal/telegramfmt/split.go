@@ -0,0 +1,78 @@
+package telegramfmt
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// MaxMessageLength is the longest text of a message Telegram accepts, in UTF-16 code units.
+const MaxMessageLength = 4096
+
+// separators are the boundaries text is split on, from the most to the least preferred.
+// Entities of the messages do not span lines, so splitting between lines keeps the formatting.
+var separators = []string{"\n\n", "\n", " "}
+
+// Split splits the text into chunks no longer than limit UTF-16 code units, the way Telegram
+// counts the length. Text is split between paragraphs, then lines, then words, and only cut
+// inside a word when there is no other boundary, without separating an escape from the
+// character it escapes. The separator a chunk ends with is dropped.
+func Split(text string, limit int) []string {
+	var chunks []string
+	for utf16Length(text) > limit {
+		end := prefixEnd(text, limit)
+		chunk, rest := text[:end], text[end:]
+		if idx, sep := lastSeparator(chunk); idx > 0 {
+			chunk, rest = text[:idx], text[idx+len(sep):]
+		} else if trailing := len(chunk) - len(strings.TrimRight(chunk, `\`)); trailing%2 == 1 && len(chunk) > 1 {
+			chunk, rest = text[:end-1], text[end-1:]
+		}
+		chunks = append(chunks, chunk)
+		text = rest
+	}
+
+	return append(chunks, text)
+}
+
+// prefixEnd returns the byte length of the longest prefix of the text fitting into limit
+// UTF-16 code units, which is at least one rune.
+func prefixEnd(text string, limit int) int {
+	length := 0
+	for idx, r := range text {
+		length += utf16.RuneLen(r)
+		if length > limit {
+			if idx == 0 {
+				_, size := utf8.DecodeRuneInString(text)
+				return size
+			}
+			return idx
+		}
+	}
+
+	return len(text)
+}
+
+// lastSeparator returns the index and the separator of the last, most preferred boundary
+// in the text, and -1 when there is none. A boundary in the second half of the text is
+// preferred, so a better separator does not leave a short chunk.
+func lastSeparator(text string) (int, string) {
+	for _, minIdx := range []int{len(text) / 2, 0} {
+		for _, sep := range separators {
+			if idx := strings.LastIndex(text, sep); idx > minIdx {
+				return idx, sep
+			}
+		}
+	}
+
+	return -1, ""
+}
+
+// utf16Length returns the length of the text in UTF-16 code units.
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+
+	return length
+}
@@ -0,0 +1,64 @@
+package telegramfmt_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		limit    int
+		expected []string
+	}{
+		{name: "short text", text: "hello", limit: 10, expected: []string{"hello"}},
+		{name: "empty text", text: "", limit: 10, expected: []string{""}},
+		{
+			name:     "paragraphs",
+			text:     "first line\nsecond\n\nthird paragraph",
+			limit:    20,
+			expected: []string{"first line\nsecond", "third paragraph"},
+		},
+		{
+			name:     "lines before a short paragraph",
+			text:     "ab\n\ncdefgh\nijklmn\nopq",
+			limit:    16,
+			expected: []string{"ab\n\ncdefgh", "ijklmn\nopq"},
+		},
+		{name: "words", text: "one two three four", limit: 9, expected: []string{"one two", "three", "four"}},
+		{name: "cut inside a word", text: "abcdefghij", limit: 4, expected: []string{"abcd", "efgh", "ij"}},
+		{name: "keeps an escape together", text: `abc\.def`, limit: 4, expected: []string{"abc", `\.de`, "f"}},
+		{name: "escaped backslash", text: `ab\\cd`, limit: 4, expected: []string{`ab\\`, "cd"}},
+		{name: "counts utf-16 code units", text: "😀😀😀", limit: 4, expected: []string{"😀😀", "😀"}},
+		{name: "rune longer than the limit", text: "😀a", limit: 1, expected: []string{"😀", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, telegramfmt.Split(tt.text, tt.limit))
+		})
+	}
+}
+
+func TestSplitMaxMessageLength(t *testing.T) {
+	t.Parallel()
+
+	line := strings.Repeat("x", 99) + "\n"
+	text := strings.Repeat(line, 100)
+
+	chunks := telegramfmt.Split(text, telegramfmt.MaxMessageLength)
+
+	assert.Len(t, chunks, 3)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len(utf16.Encode([]rune(chunk))), telegramfmt.MaxMessageLength)
+	}
+	assert.Equal(t, strings.TrimSuffix(text, "\n"), strings.TrimSuffix(strings.Join(chunks, "\n"), "\n"))
+}
@@ -0,0 +1,103 @@
+// Package telegramfmt builds message text that renders safely in a Telegram parse mode.
+// Text coming from users, the CRM or the database is escaped, so characters like _*[]
+// are shown as typed instead of breaking the formatting of the whole message.
+package telegramfmt
+
+import (
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+var (
+	// Markdown formats for the legacy Markdown mode the translations are written in.
+	Markdown = New(telebot.ModeMarkdown)
+
+	// markdownV2Escaper escapes every character reserved in MarkdownV2.
+	markdownV2Escaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`,
+		"`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`,
+		"{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	// markdownEscaper escapes the entity markers of the legacy Markdown outside of entities.
+	markdownEscaper = strings.NewReplacer("_", `\_`, "*", `\*`, "`", "\\`", "[", `\[`)
+	// htmlEscaper escapes the characters Telegram requires to be escaped in HTML.
+	htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+// Formatter builds formatted text for one parse mode.
+type Formatter struct {
+	mode telebot.ParseMode
+}
+
+// New returns a Formatter for the parse mode. Modes other than Markdown and HTML use MarkdownV2.
+func New(mode telebot.ParseMode) Formatter {
+	if mode != telebot.ModeMarkdown && mode != telebot.ModeHTML {
+		mode = telebot.ModeMarkdownV2
+	}
+
+	return Formatter{mode: mode}
+}
+
+// Mode returns the parse mode the text must be sent with.
+func (f Formatter) Mode() telebot.ParseMode {
+	return f.mode
+}
+
+// Escape escapes the text, so it is shown as is.
+func (f Formatter) Escape(text string) string {
+	switch f.mode {
+	case telebot.ModeHTML:
+		return htmlEscaper.Replace(text)
+	case telebot.ModeMarkdown:
+		return markdownEscaper.Replace(text)
+	default:
+		return markdownV2Escaper.Replace(text)
+	}
+}
+
+// Bold returns the text in bold.
+func (f Formatter) Bold(text string) string {
+	return f.entity(text, "*", "b")
+}
+
+// Italic returns the text in italics.
+func (f Formatter) Italic(text string) string {
+	return f.entity(text, "_", "i")
+}
+
+// Code returns the text in a monospace font.
+func (f Formatter) Code(text string) string {
+	if f.mode == telebot.ModeMarkdownV2 {
+		// Only the backtick and the backslash are reserved inside code.
+		return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(text) + "`"
+	}
+
+	return f.entity(text, "`", "code")
+}
+
+// Link returns the text linking to the URL.
+func (f Formatter) Link(text, url string) string {
+	switch f.mode {
+	case telebot.ModeHTML:
+		return `<a href="` + htmlEscaper.Replace(url) + `">` + htmlEscaper.Replace(text) + "</a>"
+	case telebot.ModeMarkdown:
+		return "[" + strings.ReplaceAll(text, "]", "") + "](" + strings.ReplaceAll(url, ")", "%29") + ")"
+	default:
+		url = strings.NewReplacer(`\`, `\\`, ")", `\)`).Replace(url)
+		return "[" + markdownV2Escaper.Replace(text) + "](" + url + ")"
+	}
+}
+
+// entity wraps the text in the Markdown marker or the HTML tag. The legacy Markdown cannot
+// escape inside an entity, so the marker is dropped from the text there.
+func (f Formatter) entity(text, marker, tag string) string {
+	switch f.mode {
+	case telebot.ModeHTML:
+		return "<" + tag + ">" + htmlEscaper.Replace(text) + "</" + tag + ">"
+	case telebot.ModeMarkdown:
+		return marker + strings.ReplaceAll(text, marker, "") + marker
+	default:
+		return marker + markdownV2Escaper.Replace(text) + marker
+	}
+}
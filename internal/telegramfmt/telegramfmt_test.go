@@ -0,0 +1,81 @@
+package telegramfmt_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/telegramfmt"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/telebot.v4"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, telebot.ModeHTML, telegramfmt.New(telebot.ModeHTML).Mode())
+	assert.Equal(t, telebot.ModeMarkdown, telegramfmt.New(telebot.ModeMarkdown).Mode())
+	assert.Equal(t, telebot.ModeMarkdownV2, telegramfmt.New(telebot.ModeMarkdownV2).Mode())
+	assert.Equal(t, telebot.ModeMarkdownV2, telegramfmt.New(telebot.ModeDefault).Mode())
+}
+
+func TestFormatter(t *testing.T) {
+	t.Parallel()
+
+	const text = `Ivan_Petrenko *VIP* [flat 4] a<b & c.d!`
+
+	tests := []struct {
+		name   string
+		mode   telebot.ParseMode
+		escape string
+		bold   string
+		italic string
+		code   string
+		link   string
+	}{
+		{
+			name:   "markdown v2",
+			mode:   telebot.ModeMarkdownV2,
+			escape: `Ivan\_Petrenko \*VIP\* \[flat 4\] a<b & c\.d\!`,
+			bold:   `*Ivan\_Petrenko \*VIP\* \[flat 4\] a<b & c\.d\!*`,
+			italic: `_Ivan\_Petrenko \*VIP\* \[flat 4\] a<b & c\.d\!_`,
+			code:   "`Ivan_Petrenko *VIP* [flat 4] a<b & c.d!`",
+			link:   `[Ivan\_Petrenko \*VIP\* \[flat 4\] a<b & c\.d\!](https://maps.google.com/?q=(1,2\))`,
+		},
+		{
+			name:   "legacy markdown",
+			mode:   telebot.ModeMarkdown,
+			escape: `Ivan\_Petrenko \*VIP\* \[flat 4] a<b & c.d!`,
+			bold:   `*Ivan_Petrenko VIP [flat 4] a<b & c.d!*`,
+			italic: `_IvanPetrenko *VIP* [flat 4] a<b & c.d!_`,
+			code:   "`Ivan_Petrenko *VIP* [flat 4] a<b & c.d!`",
+			link:   `[Ivan_Petrenko *VIP* [flat 4 a<b & c.d!](https://maps.google.com/?q=(1,2%29)`,
+		},
+		{
+			name:   "html",
+			mode:   telebot.ModeHTML,
+			escape: `Ivan_Petrenko *VIP* [flat 4] a&lt;b &amp; c.d!`,
+			bold:   `<b>Ivan_Petrenko *VIP* [flat 4] a&lt;b &amp; c.d!</b>`,
+			italic: `<i>Ivan_Petrenko *VIP* [flat 4] a&lt;b &amp; c.d!</i>`,
+			code:   `<code>Ivan_Petrenko *VIP* [flat 4] a&lt;b &amp; c.d!</code>`,
+			link:   `<a href="https://maps.google.com/?q=(1,2)">Ivan_Petrenko *VIP* [flat 4] a&lt;b &amp; c.d!</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			formatter := telegramfmt.New(tt.mode)
+
+			assert.Equal(t, tt.escape, formatter.Escape(text))
+			assert.Equal(t, tt.bold, formatter.Bold(text))
+			assert.Equal(t, tt.italic, formatter.Italic(text))
+			assert.Equal(t, tt.code, formatter.Code(text))
+			assert.Equal(t, tt.link, formatter.Link(text, "https://maps.google.com/?q=(1,2)"))
+		})
+	}
+}
+
+func TestCodeMarkdownV2(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "`a\\`b\\\\c`", telegramfmt.New(telebot.ModeMarkdownV2).Code("a`b\\c"))
+}
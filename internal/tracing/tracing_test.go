@@ -0,0 +1,30 @@
+package tracing_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/config"
+	"github.com/UnknownOlympus/oracle/internal/tracing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	t.Run("disabled without endpoint", func(t *testing.T) {
+		shutdown, err := tracing.Setup(t.Context(), config.Tracing{})
+
+		require.NoError(t, err)
+		require.NoError(t, shutdown(t.Context()))
+	})
+
+	t.Run("exporter with endpoint", func(t *testing.T) {
+		// The exporter connects lazily, so no collector is needed to set it up.
+		shutdown, err := tracing.Setup(t.Context(), config.Tracing{
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			SampleRatio: 1,
+		})
+
+		require.NoError(t, err)
+		require.NoError(t, shutdown(t.Context()))
+	})
+}
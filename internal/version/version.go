@@ -0,0 +1,75 @@
+package version
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// Build information, set with -ldflags "-X github.com/UnknownOlympus/oracle/internal/version.Version=..."
+// and the same for Commit and Date.
+var (
+	Version = "dev"     // Version is the release, e.g. v1.4.0, or the output of git describe.
+	Commit  = "unknown" // Commit is the hash of the built commit.
+	Date    = "unknown" // Date is when the binary was built, in RFC 3339.
+)
+
+//go:embed CHANGELOG.md
+var changelog string
+
+// Release is an entry of the changelog.
+type Release struct {
+	Title    string    // Title of the release, e.g. "[1.4.0] - 2025-06-01" or "[Unreleased]".
+	Sections []Section // Sections group the changes by kind.
+}
+
+// Section is a group of changes of a release, e.g. Added or Fixed.
+type Section struct {
+	Title string
+	Items []string
+}
+
+// Changelog returns the releases of the embedded changelog, newest first.
+func Changelog() []Release {
+	return ParseChangelog(changelog)
+}
+
+// ParseChangelog parses a changelog in the Keep a Changelog format: a "## " heading per release,
+// "### " headings for the sections and "- " list items. A list item continued on the next,
+// indented line is joined into one item. Other lines are ignored.
+func ParseChangelog(text string) []Release {
+	var releases []Release
+	for line := range strings.SplitSeq(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "### "):
+			if len(releases) == 0 {
+				continue
+			}
+			release := &releases[len(releases)-1]
+			release.Sections = append(release.Sections, Section{Title: strings.TrimPrefix(trimmed, "### ")})
+		case strings.HasPrefix(trimmed, "## "):
+			releases = append(releases, Release{Title: strings.TrimPrefix(trimmed, "## ")})
+		case strings.HasPrefix(trimmed, "- "):
+			if section := lastSection(releases); section != nil {
+				section.Items = append(section.Items, strings.TrimPrefix(trimmed, "- "))
+			}
+		case trimmed != "" && line != trimmed:
+			if section := lastSection(releases); section != nil && len(section.Items) > 0 {
+				section.Items[len(section.Items)-1] += " " + trimmed
+			}
+		}
+	}
+	return releases
+}
+
+// lastSection returns the section list items are added to, nil before the first section.
+func lastSection(releases []Release) *Section {
+	if len(releases) == 0 {
+		return nil
+	}
+	sections := releases[len(releases)-1].Sections
+	if len(sections) == 0 {
+		return nil
+	}
+	return &sections[len(sections)-1]
+}
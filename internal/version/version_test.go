@@ -0,0 +1,57 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/internal/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChangelog(t *testing.T) {
+	t.Parallel()
+
+	releases := version.ParseChangelog(`# Changelog
+
+Intro text.
+
+## [1.1.0] - 2025-06-01
+
+### Added
+- Tasks map
+- Reports in PDF,
+  alongside Excel
+
+### Fixed
+- Statistics of the last month
+
+## [1.0.0] - 2025-05-01
+
+### Added
+- First release
+`)
+
+	assert.Equal(t, []version.Release{
+		{
+			Title: "[1.1.0] - 2025-06-01",
+			Sections: []version.Section{
+				{Title: "Added", Items: []string{"Tasks map", "Reports in PDF, alongside Excel"}},
+				{Title: "Fixed", Items: []string{"Statistics of the last month"}},
+			},
+		},
+		{
+			Title:    "[1.0.0] - 2025-05-01",
+			Sections: []version.Section{{Title: "Added", Items: []string{"First release"}}},
+		},
+	}, releases)
+}
+
+func TestChangelog(t *testing.T) {
+	t.Parallel()
+
+	releases := version.Changelog()
+
+	require.NotEmpty(t, releases, "the embedded changelog must have a release")
+	require.NotEmpty(t, releases[0].Sections, "the latest release must list its changes")
+	assert.NotEmpty(t, releases[0].Sections[0].Items)
+}
@@ -0,0 +1,5 @@
+-- Users who receive their Excel report automatically every Monday morning.
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    telegram_id BIGINT PRIMARY KEY REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
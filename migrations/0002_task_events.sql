@@ -0,0 +1,12 @@
+-- Chronological status and assignment changes of tasks, written by the task synchronization
+-- service and displayed in the task details history view.
+CREATE TABLE IF NOT EXISTS task_events (
+    id         BIGSERIAL PRIMARY KEY,
+    task_id    BIGINT      NOT NULL,
+    event_type TEXT        NOT NULL, -- status, assigned, unassigned
+    value      TEXT        NOT NULL,
+    actor      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task_id_created_at ON task_events (task_id, created_at);
@@ -0,0 +1,14 @@
+-- Audit log of admin broadcasts: who sent what kind of message and how the delivery went.
+CREATE TABLE IF NOT EXISTS broadcasts (
+    id          BIGSERIAL PRIMARY KEY,
+    admin_id    BIGINT      NOT NULL,
+    kind        TEXT        NOT NULL, -- text, photo, document
+    total       INTEGER     NOT NULL,
+    sent        INTEGER     NOT NULL DEFAULT 0,
+    failed      INTEGER     NOT NULL DEFAULT 0,
+    status      TEXT        NOT NULL DEFAULT 'running', -- running, completed, canceled
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_started_at ON broadcasts (started_at);
@@ -0,0 +1,30 @@
+-- Geography column and spatial index for the PostGIS-backed near-task search, enabled with
+-- DB_POSTGIS_ENABLED=true. Requires the PostGIS extension to be available on the server;
+-- without it the migration does nothing and the bot keeps using the haversine query.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'postgis') THEN
+        RAISE NOTICE 'PostGIS is not available, skipping the tasks location column';
+        RETURN;
+    END IF;
+
+    CREATE EXTENSION IF NOT EXISTS postgis;
+
+    -- The PostGIS types only exist once the extension is created, so the statements are
+    -- executed dynamically.
+    EXECUTE $sql$
+        ALTER TABLE tasks
+            ADD COLUMN IF NOT EXISTS location geography(Point, 4326)
+            GENERATED ALWAYS AS (
+                CASE
+                    WHEN latitude IS NOT NULL AND longitude IS NOT NULL
+                    THEN ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography
+                END
+            ) STORED
+    $sql$;
+
+    EXECUTE $sql$
+        CREATE INDEX IF NOT EXISTS idx_tasks_location_open ON tasks USING GIST (location) WHERE is_closed = false
+    $sql$;
+END
+$$;
@@ -0,0 +1,14 @@
+-- Dead-letter log of messages Telegram refused to deliver (broadcasts, alerts, weekly reports).
+-- Permanent failures (bot blocked, user deactivated, chat not found) also unsubscribe the user
+-- from automatic reports.
+CREATE TABLE IF NOT EXISTS send_failures (
+    id          BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT      NOT NULL,
+    source      TEXT        NOT NULL, -- broadcast, alert, weekly_report
+    reason      TEXT        NOT NULL, -- blocked, deactivated, chat_not_found, other
+    permanent   BOOLEAN     NOT NULL,
+    error       TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_send_failures_telegram_id ON send_failures (telegram_id, created_at);
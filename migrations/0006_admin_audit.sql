@@ -0,0 +1,11 @@
+-- Audit log of admin actions (broadcasts, geocoding resets, ...). The payload itself is not
+-- stored, only its SHA-256 hash, so the log proves what was done without keeping message texts.
+CREATE TABLE IF NOT EXISTS admin_audit (
+    id           BIGSERIAL PRIMARY KEY,
+    admin_id     BIGINT      NOT NULL, -- Telegram ID of the admin
+    action       TEXT        NOT NULL, -- broadcast, geocoding_reset
+    payload_hash TEXT        NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_created_at ON admin_audit (created_at DESC);
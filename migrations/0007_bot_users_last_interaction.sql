@@ -0,0 +1,5 @@
+-- Time of the last update handled for the user, used to find stale accounts.
+-- Existing users start counting from the moment the migration is applied.
+ALTER TABLE bot_users ADD COLUMN IF NOT EXISTS last_interaction TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_bot_users_last_interaction ON bot_users (last_interaction);
@@ -0,0 +1,8 @@
+-- Users who receive the morning digest of their open tasks every workday.
+-- The digest is sent at the configured time in the user's time zone.
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    telegram_id  BIGINT PRIMARY KEY REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    timezone     TEXT        NOT NULL, -- IANA time zone name, e.g. Europe/Kyiv
+    last_sent_on DATE,                 -- local date of the last digest, NULL if never sent
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
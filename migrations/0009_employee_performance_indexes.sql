@@ -0,0 +1,6 @@
+-- Indexes for the team performance statistics, which aggregate closed tasks by closing date
+-- and join them with their executors.
+CREATE INDEX IF NOT EXISTS idx_tasks_closed_closing_date
+    ON tasks (closing_date) INCLUDE (creation_date) WHERE is_closed = TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_task_executors_task_executor ON task_executors (task_id, executor_id);
@@ -0,0 +1,19 @@
+-- Comments accepted by users and not yet delivered to Hermes. A dispatcher retries
+-- failed deliveries with exponential backoff until they land or run out of attempts.
+CREATE TABLE IF NOT EXISTS comment_outbox (
+    id              BIGSERIAL PRIMARY KEY,
+    telegram_id     BIGINT      NOT NULL,
+    task_id         BIGINT      NOT NULL,
+    author          TEXT        NOT NULL,
+    text            TEXT        NOT NULL,
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT,
+    delivered_at    TIMESTAMPTZ, -- NULL until Hermes accepted the comment
+    failed_at       TIMESTAMPTZ, -- set when the dispatcher gave up
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS comment_outbox_pending_idx
+    ON comment_outbox (next_attempt_at)
+    WHERE delivered_at IS NULL AND failed_at IS NULL;
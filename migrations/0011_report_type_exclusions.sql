@@ -0,0 +1,7 @@
+-- Task types a user left out of their reports. Types are stored as exclusions,
+-- so task types added later are included in reports by default.
+CREATE TABLE IF NOT EXISTS report_type_exclusions (
+    telegram_id BIGINT  NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    type_id     INTEGER NOT NULL, -- task_types.type_id
+    PRIMARY KEY (telegram_id, type_id)
+);
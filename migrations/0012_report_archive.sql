@@ -0,0 +1,16 @@
+-- Reports sent to users, kept so they can be downloaded again from the "My reports" menu.
+-- Only the last reports of each user are kept, older ones are removed when a new one is archived.
+CREATE TABLE IF NOT EXISTS report_archive (
+    id               BIGSERIAL PRIMARY KEY,
+    telegram_id      BIGINT      NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    period_from      TIMESTAMPTZ NOT NULL,
+    period_to        TIMESTAMPTZ NOT NULL,
+    format           TEXT        NOT NULL, -- xlsx, pdf or csv
+    size             BIGINT      NOT NULL, -- file size in bytes
+    sha256           TEXT        NOT NULL, -- hex encoded hash of the file
+    content          BYTEA       NOT NULL,
+    telegram_file_id TEXT,                 -- file ID of the sent document, resending it needs no upload
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS report_archive_user_idx ON report_archive (telegram_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Archived reports may be kept in the object storage instead of the database. The content
+-- of such reports is empty and object_key points to the file in the storage bucket.
+ALTER TABLE report_archive ALTER COLUMN content DROP NOT NULL;
+ALTER TABLE report_archive ADD COLUMN IF NOT EXISTS object_key TEXT;
+ALTER TABLE report_archive ADD CONSTRAINT report_archive_content_check
+    CHECK (content IS NOT NULL OR object_key IS NOT NULL);
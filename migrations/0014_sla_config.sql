@@ -0,0 +1,7 @@
+-- Service level per task type: open tasks older than sla_hours are marked as overdue in the
+-- active tasks list. Task types without a row have no SLA.
+CREATE TABLE IF NOT EXISTS sla_config (
+    type_id    INTEGER     PRIMARY KEY, -- task_types.type_id
+    sla_hours  INTEGER     NOT NULL CHECK (sla_hours > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
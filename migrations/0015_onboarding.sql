@@ -0,0 +1,8 @@
+-- Progress of the guided tour shown after the first login. Rows are kept when the user logs out,
+-- so the tour runs only once per Telegram account.
+CREATE TABLE IF NOT EXISTS onboarding (
+    telegram_id  BIGINT      PRIMARY KEY,
+    step         TEXT        NOT NULL,
+    started_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
@@ -0,0 +1,9 @@
+-- Feature flags set by admins. Flags without a row use the default declared in the code.
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name       TEXT        PRIMARY KEY,
+    enabled    BOOLEAN     NOT NULL DEFAULT FALSE,
+    percentage INT         NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 100),
+    roles      TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_by BIGINT
+);
@@ -0,0 +1,6 @@
+-- Logging out only marks the user as unlinked. The row, with the subscriptions and settings
+-- referencing it, is kept for a grace period, so the logout can be undone without logging in
+-- again. Rows unlinked for longer are purged by the bot.
+ALTER TABLE bot_users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_bot_users_deleted_at ON bot_users (deleted_at) WHERE deleted_at IS NOT NULL;
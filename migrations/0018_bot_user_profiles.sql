@@ -0,0 +1,13 @@
+-- Employees a Telegram account may act as besides its own, e.g. the members of a team lead's
+-- team, granted by admins. bot_users.employee_id remains the own employee of the account and
+-- decides its admin rights, acting_employee_id is the granted employee it acts as right now,
+-- whose tasks, statistics and reports it sees.
+CREATE TABLE IF NOT EXISTS bot_user_profiles (
+    telegram_id BIGINT      NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    employee_id INT         NOT NULL,
+    granted_by  BIGINT,
+    granted_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (telegram_id, employee_id)
+);
+
+ALTER TABLE bot_users ADD COLUMN IF NOT EXISTS acting_employee_id INT;
@@ -0,0 +1,21 @@
+-- Quiet hours of users, in minutes after midnight of the user's time zone. Digests and
+-- broadcasts falling into them are postponed, alerts are sent anyway. The end may be before
+-- the start, e.g. 20:00–08:00 spans midnight.
+CREATE TABLE IF NOT EXISTS quiet_hours (
+    telegram_id  BIGINT PRIMARY KEY REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    start_minute SMALLINT    NOT NULL CHECK (start_minute BETWEEN 0 AND 1439),
+    end_minute   SMALLINT    NOT NULL CHECK (end_minute BETWEEN 0 AND 1439),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Messages postponed by quiet hours, delivered once deliver_at has passed.
+CREATE TABLE IF NOT EXISTS deferred_messages (
+    id          BIGSERIAL PRIMARY KEY,
+    telegram_id BIGINT      NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    source      TEXT        NOT NULL, -- the feature which sent the message, e.g. digest
+    payload     JSONB       NOT NULL, -- the message content as encoded by the bot
+    deliver_at  TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS deferred_messages_deliver_at_idx ON deferred_messages (deliver_at);
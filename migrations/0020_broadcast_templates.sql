@@ -0,0 +1,9 @@
+-- Reusable broadcast texts saved by admins. Placeholders such as {date} are filled in when
+-- the template is broadcast. Saving a template with an existing name replaces its text.
+CREATE TABLE IF NOT EXISTS broadcast_templates (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL UNIQUE,
+    text       TEXT        NOT NULL,
+    created_by BIGINT      NOT NULL, -- Telegram ID of the admin who saved the template last
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Important broadcasts are pinned and ask every recipient to confirm they read them,
+-- e.g. safety notices. Each delivered message gets a receipt, acked_at is set on confirmation.
+ALTER TABLE broadcasts ADD COLUMN IF NOT EXISTS requires_ack BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS broadcast_receipts (
+    broadcast_id BIGINT NOT NULL REFERENCES broadcasts (id) ON DELETE CASCADE,
+    telegram_id  BIGINT NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    acked_at     TIMESTAMPTZ, -- NULL until the recipient confirmed the broadcast
+    PRIMARY KEY (broadcast_id, telegram_id)
+);
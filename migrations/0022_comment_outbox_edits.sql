@@ -0,0 +1,3 @@
+-- Authors may edit or delete their last comment for a short while after leaving it.
+ALTER TABLE comment_outbox ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+ALTER TABLE comment_outbox ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
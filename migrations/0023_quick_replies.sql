@@ -0,0 +1,13 @@
+-- Frequent comments offered as one-tap quick replies when commenting a task. The company edits
+-- the rows to fit its work; inactive replies are kept but not shown.
+CREATE TABLE IF NOT EXISTS quick_replies (
+    id       SERIAL  PRIMARY KEY,
+    text     TEXT    NOT NULL,
+    position INT     NOT NULL DEFAULT 0, -- Order of the buttons, lowest first
+    active   BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO quick_replies (text, position) VALUES
+    ('Customer not home', 1),
+    ('Waiting for equipment', 2),
+    ('Completed, signal OK', 3);
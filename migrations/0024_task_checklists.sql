@@ -0,0 +1,24 @@
+-- Questions answered when closing tasks of a type. A question with options is answered with a
+-- button, one without options with a free text. Task types without rows are closed right away.
+CREATE TABLE IF NOT EXISTS task_checklists (
+    id       SERIAL  PRIMARY KEY,
+    type_id  INTEGER NOT NULL, -- task_types.type_id
+    position INT     NOT NULL DEFAULT 0, -- Order of the questions, lowest first
+    question TEXT    NOT NULL,
+    options  TEXT[]  NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_checklists_type_id ON task_checklists (type_id);
+
+-- Checklist answers given when closing a task, kept for reporting. The question is copied, so
+-- answers stay readable after the checklist changes.
+CREATE TABLE IF NOT EXISTS task_checklist_answers (
+    id          BIGSERIAL   PRIMARY KEY,
+    task_id     BIGINT      NOT NULL,
+    telegram_id BIGINT      NOT NULL,
+    question    TEXT        NOT NULL,
+    answer      TEXT        NOT NULL,
+    answered_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_checklist_answers_task_id ON task_checklist_answers (task_id);
@@ -0,0 +1,8 @@
+-- Weekly on-call rota. The employee is on duty for seven days from week_start, midnight in the
+-- default time zone, and receives Alertmanager alerts in addition to the admins.
+CREATE TABLE IF NOT EXISTS oncall_weeks (
+    week_start  DATE        PRIMARY KEY,
+    telegram_id BIGINT      NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    created_by  BIGINT      NOT NULL, -- Telegram ID of the admin who set the week
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,16 @@
+-- Work shifts started and ended by employees from the bot, exported as the monthly timesheet.
+-- The location of the start and of the end is optional.
+CREATE TABLE IF NOT EXISTS shifts (
+    id              BIGSERIAL   PRIMARY KEY,
+    telegram_id     BIGINT      NOT NULL REFERENCES bot_users (telegram_id) ON DELETE CASCADE,
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    start_latitude  DOUBLE PRECISION,
+    start_longitude DOUBLE PRECISION,
+    ended_at        TIMESTAMPTZ,
+    end_latitude    DOUBLE PRECISION,
+    end_longitude   DOUBLE PRECISION
+);
+
+-- A user has at most one open shift.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_open ON shifts (telegram_id) WHERE ended_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shifts_started_at ON shifts (started_at);
@@ -0,0 +1,26 @@
+-- Materials employees log as used on a task, for the inventory reconciliation. The company edits
+-- the catalog rows to fit its stock; inactive materials are kept but not offered.
+CREATE TABLE IF NOT EXISTS materials (
+    id       SERIAL  PRIMARY KEY,
+    name     TEXT    NOT NULL,
+    unit     TEXT    NOT NULL,           -- Unit the quantity is entered in, e.g. m or pcs
+    position INT     NOT NULL DEFAULT 0, -- Order of the buttons, lowest first
+    active   BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO materials (name, unit, position) VALUES
+    ('UTP cable', 'm', 1),
+    ('Optical cable', 'm', 2),
+    ('RJ45 connector', 'pcs', 3),
+    ('SC/APC connector', 'pcs', 4);
+
+CREATE TABLE IF NOT EXISTS task_materials (
+    id          BIGSERIAL   PRIMARY KEY,
+    task_id     BIGINT      NOT NULL,
+    material_id INT         NOT NULL REFERENCES materials (id),
+    quantity    NUMERIC(10, 2) NOT NULL CHECK (quantity > 0),
+    telegram_id BIGINT      NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_materials_task_id ON task_materials (task_id);
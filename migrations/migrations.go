@@ -0,0 +1,10 @@
+// Package migrations embeds the SQL migrations of the bot schema, applied in order of their
+// sequence number by the migration runner.
+package migrations
+
+import "embed"
+
+// FS holds the migration files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,21 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"github.com/UnknownOlympus/oracle/migrations"
+	"github.com/jackc/tern/v2/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMigrationsAreSequential catches gaps and duplicate sequence numbers, which make the
+// runner refuse to start.
+func TestMigrationsAreSequential(t *testing.T) {
+	t.Parallel()
+
+	paths, err := migrate.FindMigrations(migrations.FS)
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, paths)
+}